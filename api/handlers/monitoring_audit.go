@@ -13,8 +13,15 @@ const (
 	monitorAuditMonitorCheckNow      = "monitoring.monitor.check_now"
 	monitorAuditMonitorClone         = "monitoring.monitor.clone"
 	monitorAuditMonitorPush          = "monitoring.monitor.push"
+	monitorAuditMonitorPushToken     = "monitoring.monitor.push_token.rotate"
 	monitorAuditMonitorEventsDelete  = "monitoring.monitor.events.delete"
 	monitorAuditMonitorMetricsDelete = "monitoring.monitor.metrics.delete"
+	monitorAuditMonitorContentAccept = "monitoring.monitor.content.accept"
+
+	monitorAuditProbeCreate = "monitoring.probe.create"
+	monitorAuditProbeDelete = "monitoring.probe.delete"
+	monitorAuditProbeToken  = "monitoring.probe.enrol_token.rotate"
+	monitorAuditProbeEnroll = "monitoring.probe.enroll"
 
 	monitorAuditSLAUpdate             = "monitoring.sla.update"
 	monitorAuditSLAPolicyUpdate       = "monitoring.sla.policy.update"
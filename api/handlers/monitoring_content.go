@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/store"
+)
+
+type monitorContentResponse struct {
+	*store.MonitorContent
+	Diff string `json:"diff"`
+}
+
+// GetContent returns the content baseline of a monitor with the diff of the last seen body.
+func (h *MonitoringHandler) GetContent(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(pathParams(r)["id"])
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	item, err := h.store.GetContent(r.Context(), id)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if item == nil {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return
+	}
+	resp := monitorContentResponse{MonitorContent: item}
+	if item.CurrentHash != item.BaselineHash {
+		diff, err := monitoring.ContentDiff(item.Baseline, item.Current)
+		if err != nil {
+			http.Error(w, errServerError, http.StatusInternalServerError)
+			return
+		}
+		resp.Diff = diff
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type contentAcceptPayload struct {
+	Hash string `json:"hash"`
+}
+
+// AcceptContentBaseline makes the last seen body the new baseline of the monitor. The request names
+// the hash the operator reviewed, so a version seen after GET /content is never approved unseen.
+func (h *MonitoringHandler) AcceptContentBaseline(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(pathParams(r)["id"])
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	var payload contentAcceptPayload
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&payload); err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	hash := strings.TrimSpace(payload.Hash)
+	if hash == "" {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	item, err := h.store.GetContent(r.Context(), id)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if item == nil {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return
+	}
+	if item.CurrentHash != hash {
+		http.Error(w, "monitoring.error.contentChanged", http.StatusConflict)
+		return
+	}
+	previous := item.BaselineHash
+	accepted, err := h.store.AcceptContentBaseline(r.Context(), id, hash, currentUsername(r), time.Now().UTC())
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if !accepted {
+		http.Error(w, "monitoring.error.contentChanged", http.StatusConflict)
+		return
+	}
+	updated, err := h.store.GetContent(r.Context(), id)
+	if err != nil || updated == nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, monitorAuditMonitorContentAccept, fmt.Sprintf("%d|%s->%s", id, previous, updated.BaselineHash))
+	writeJSON(w, http.StatusOK, updated)
+}
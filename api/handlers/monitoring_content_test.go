@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func TestAcceptContentBaselineRequiresReviewedHash(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	h := NewMonitoringHandler(nil, ms, nil, nil, nil, nil)
+	id, err := ms.CreateMonitor(ctx, &store.Monitor{Name: "Site", Type: "http", URL: "http://example.com", Method: "GET", IntervalSec: 60, TimeoutSec: 2})
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := ms.CreateContent(ctx, &store.MonitorContent{
+		MonitorID: id, BaselineHash: "a", Baseline: []byte("a"), BaselineAt: now, CurrentHash: "a", Current: []byte("a"), CheckedAt: now,
+	}); err != nil {
+		t.Fatalf("create content: %v", err)
+	}
+	if err := ms.RecordContentCheck(ctx, id, "b", []byte("b"), now); err != nil {
+		t.Fatalf("record check: %v", err)
+	}
+	accept := func(body string) int {
+		req := httptest.NewRequest("POST", "/api/monitoring/monitors/"+strconv.FormatInt(id, 10)+"/content/accept", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.AcceptContentBaseline(rec, withChiURLParam(req, "id", strconv.FormatInt(id, 10)))
+		return rec.Code
+	}
+
+	if code := accept(`{}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a hash, got %d", code)
+	}
+	// The page changed again after the operator reviewed version b.
+	if err := ms.RecordContentCheck(ctx, id, "c", []byte("c"), now.Add(time.Second)); err != nil {
+		t.Fatalf("record check: %v", err)
+	}
+	if code := accept(`{"hash":"b"}`); code != http.StatusConflict {
+		t.Fatalf("expected 409 for a stale hash, got %d", code)
+	}
+	content, _ := ms.GetContent(ctx, id)
+	if content == nil || content.BaselineHash != "a" {
+		t.Fatalf("expected the baseline to be kept, got %+v", content)
+	}
+	if code := accept(`{"hash":"c"}`); code != http.StatusOK {
+		t.Fatalf("expected the reviewed hash to be accepted, got %d", code)
+	}
+	content, _ = ms.GetContent(ctx, id)
+	if content == nil || content.BaselineHash != "c" {
+		t.Fatalf("expected baseline c, got %+v", content)
+	}
+}
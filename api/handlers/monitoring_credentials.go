@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/store"
+)
+
+// applyCredentials encrypts monitor credentials from the payload.
+// A missing object keeps the stored value, an empty one clears it.
+func (h *MonitoringHandler) applyCredentials(m *store.Monitor, payload *monitorCredentialsPayload) error {
+	if m == nil || payload == nil {
+		return nil
+	}
+	username := strings.TrimSpace(payload.Username)
+	if username == "" && payload.Password == "" && payload.Secret == "" && payload.Token == "" && payload.ClientCert == "" && payload.PrivateKey == "" {
+		m.CredentialsEnc = nil
+		m.HasCredentials = false
+		return nil
+	}
+	if h.encryptor == nil {
+		return errors.New("encryptor not configured")
+	}
+	raw, err := json.Marshal(store.MonitorCredentials{
+		Username:   username,
+		Password:   payload.Password,
+		Secret:     payload.Secret,
+		Token:      payload.Token,
+		ClientCert: payload.ClientCert,
+		ClientKey:  payload.ClientKey,
+		PrivateKey: payload.PrivateKey,
+	})
+	if err != nil {
+		return err
+	}
+	enc, err := h.encryptor.EncryptToBlob(raw)
+	if err != nil {
+		return err
+	}
+	m.CredentialsEnc = enc
+	m.HasCredentials = true
+	return nil
+}
+
+// validateCredentials rejects oversized values, client certificates without a matching key
+// and SSH keys that cannot be decrypted with the given password.
+func validateCredentials(payload *monitorCredentialsPayload) error {
+	if payload == nil {
+		return nil
+	}
+	if len(payload.Username) > 256 || len(payload.Password) > 1024 || len(payload.Secret) > 1024 || len(payload.Token) > 8192 {
+		return errors.New("monitoring.error.invalidCredentials")
+	}
+	if payload.PrivateKey != "" {
+		if len(payload.PrivateKey) > 64<<10 || !monitoring.ValidSSHPrivateKey(payload.PrivateKey, payload.Password) {
+			return errors.New("monitoring.error.invalidSSHKey")
+		}
+	}
+	if payload.ClientCert == "" && payload.ClientKey == "" {
+		return nil
+	}
+	if len(payload.ClientCert) > 64<<10 || len(payload.ClientKey) > 64<<10 {
+		return errors.New("monitoring.error.invalidClientCertificate")
+	}
+	if _, err := tls.X509KeyPair([]byte(payload.ClientCert), []byte(payload.ClientKey)); err != nil {
+		return errors.New("monitoring.error.invalidClientCertificate")
+	}
+	return nil
+}
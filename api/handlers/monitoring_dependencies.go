@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/store"
+)
+
+var (
+	errParentNotFound  = errors.New("monitoring.error.parentNotFound")
+	errDependencyCycle = errors.New("monitoring.error.dependencyCycle")
+)
+
+// validateParent checks that the parent of the monitor exists and does not depend on the monitor itself.
+func (h *MonitoringHandler) validateParent(ctx context.Context, m *store.Monitor) error {
+	if m.ParentID == nil {
+		return nil
+	}
+	if m.ID > 0 && *m.ParentID == m.ID {
+		return errDependencyCycle
+	}
+	parent, err := h.store.GetMonitor(ctx, *m.ParentID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return errParentNotFound
+	}
+	if m.ID == 0 {
+		return nil
+	}
+	items, err := h.store.ListMonitors(ctx, store.MonitorFilter{})
+	if err != nil {
+		return err
+	}
+	parents := map[int64]int64{}
+	for _, item := range items {
+		if item.ParentID != nil && item.ID != m.ID {
+			parents[item.ID] = *item.ParentID
+		}
+	}
+	if monitoring.DependencyCycle(parents, m.ID, *m.ParentID) {
+		return errDependencyCycle
+	}
+	return nil
+}
+
+// writeParentError answers a failed parent validation; only store failures are server errors.
+func writeParentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errParentNotFound) || errors.Is(err, errDependencyCycle) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, errServerError, http.StatusInternalServerError)
+}
+
+// ListDependencies returns all monitors arranged as a forest under their parents.
+func (h *MonitoringHandler) ListDependencies(w http.ResponseWriter, r *http.Request) {
+	items, err := h.store.ListMonitors(r.Context(), store.MonitorFilter{})
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	roots := monitoring.BuildDependencyTree(items)
+	if roots == nil {
+		roots = []*monitoring.DependencyNode{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": roots})
+}
+
+// GetMonitorDependencies returns the parent chain of the monitor and the monitors that depend on it.
+func (h *MonitoringHandler) GetMonitorDependencies(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(pathParams(r)["id"])
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	items, err := h.store.ListMonitors(r.Context(), store.MonitorFilter{})
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	var node *monitoring.DependencyNode
+	for _, root := range monitoring.BuildDependencyTree(items) {
+		if node = findDependencyNode(root, id); node != nil {
+			break
+		}
+	}
+	if node == nil {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return
+	}
+	ancestors := monitoring.DependencyAncestors(items, id)
+	if ancestors == nil {
+		ancestors = []*monitoring.DependencyNode{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ancestors": ancestors, "monitor": node})
+}
+
+func findDependencyNode(node *monitoring.DependencyNode, id int64) *monitoring.DependencyNode {
+	if node.MonitorID == id {
+		return node
+	}
+	for _, child := range node.Children {
+		if found := findDependencyNode(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"berkut-scc/config"
 	"berkut-scc/core/monitoring"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
@@ -20,6 +21,7 @@ import (
 )
 
 type MonitoringHandler struct {
+	cfg          *config.AppConfig
 	store        store.MonitoringStore
 	audits       store.AuditStore
 	engine       *monitoring.Engine
@@ -29,8 +31,9 @@ type MonitoringHandler struct {
 	lastCheckNow map[int64]time.Time
 }
 
-func NewMonitoringHandler(store store.MonitoringStore, audits store.AuditStore, engine *monitoring.Engine, policy *rbac.Policy, encryptor *utils.Encryptor) *MonitoringHandler {
+func NewMonitoringHandler(cfg *config.AppConfig, store store.MonitoringStore, audits store.AuditStore, engine *monitoring.Engine, policy *rbac.Policy, encryptor *utils.Encryptor) *MonitoringHandler {
 	return &MonitoringHandler{
+		cfg:          cfg,
 		store:        store,
 		audits:       audits,
 		engine:       engine,
@@ -75,12 +78,22 @@ func (h *MonitoringHandler) CreateMonitor(w http.ResponseWriter, r *http.Request
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := h.validateParent(r.Context(), mon); err != nil {
+		writeParentError(w, err)
+		return
+	}
+	if err := h.applyCredentials(mon, payload.Credentials); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	pushToken := sealPushToken(mon)
 	id, err := h.store.CreateMonitor(r.Context(), mon)
 	if err != nil {
 		http.Error(w, errServerError, http.StatusInternalServerError)
 		return
 	}
 	mon.ID = id
+	mon.RequestBody = pushToken
 	_ = h.store.UpsertMonitorState(r.Context(), &store.MonitorState{
 		MonitorID:        mon.ID,
 		Status:           initialStatus(mon.IsPaused),
@@ -145,10 +158,20 @@ func (h *MonitoringHandler) UpdateMonitor(w http.ResponseWriter, r *http.Request
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := h.validateParent(r.Context(), mon); err != nil {
+		writeParentError(w, err)
+		return
+	}
+	if err := h.applyCredentials(mon, payload.Credentials); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	pushToken := sealPushToken(mon)
 	if err := h.store.UpdateMonitor(r.Context(), mon); err != nil {
 		http.Error(w, errServerError, http.StatusInternalServerError)
 		return
 	}
+	mon.RequestBody = pushToken
 	if existing.IsPaused != mon.IsPaused {
 		_ = h.store.SetMonitorPaused(r.Context(), id, mon.IsPaused)
 	}
@@ -284,6 +307,7 @@ func (h *MonitoringHandler) CloneMonitor(w http.ResponseWriter, r *http.Request)
 	if monitoring.TypeIsPassive(clone.Type) {
 		clone.RequestBody = randomPushToken()
 	}
+	pushToken := sealPushToken(&clone)
 	clone.CreatedBy = sessionUserID(r)
 	clone.CreatedAt = time.Time{}
 	clone.UpdatedAt = time.Time{}
@@ -293,6 +317,7 @@ func (h *MonitoringHandler) CloneMonitor(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	clone.ID = newID
+	clone.RequestBody = pushToken
 	if items, err := h.store.ListMonitorNotifications(r.Context(), id); err == nil && len(items) > 0 {
 		cloneItems := make([]store.MonitorNotification, 0, len(items))
 		for _, item := range items {
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"berkut-scc/core/store"
@@ -42,18 +44,82 @@ func (h *MonitoringHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 	}
 	rng := r.URL.Query().Get("range")
 	since := rangeSince(rng, 24*time.Hour)
+	now := time.Now().UTC()
+	// Ranges longer than a day are downsampled to hourly points: raw points are not kept that long.
+	if now.Sub(since) > 25*time.Hour {
+		rollups, err := h.store.ListMetricRollups(r.Context(), id, store.RollupHourly, since.Truncate(time.Hour), now)
+		if err != nil {
+			http.Error(w, errServerError, http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"items":      rollupPoints(rollups),
+			"resolution": store.RollupHourly,
+			"from":       since,
+			"to":         now,
+		})
+		return
+	}
 	items, err := h.store.ListMetrics(r.Context(), id, since)
 	if err != nil {
 		http.Error(w, errServerError, http.StatusInternalServerError)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{
-		"items": items,
-		"from":  since,
-		"to":    time.Now().UTC(),
+		"items":      items,
+		"resolution": "raw",
+		"from":       since,
+		"to":         now,
+	})
+}
+
+func (h *MonitoringHandler) GetRollups(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(pathParams(r)["id"])
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	resolution := strings.TrimSpace(r.URL.Query().Get("resolution"))
+	if resolution == "" {
+		resolution = store.RollupHourly
+	}
+	if resolution != store.RollupHourly && resolution != store.RollupDaily {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	since := rangeSince(r.URL.Query().Get("range"), 30*24*time.Hour)
+	now := time.Now().UTC()
+	items, err := h.store.ListMetricRollups(r.Context(), id, resolution, since.Truncate(time.Hour), now)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.MonitorMetricRollup{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items":      items,
+		"resolution": resolution,
+		"from":       since,
+		"to":         now,
 	})
 }
 
+// rollupPoints turns hourly buckets into chart points shaped like raw metrics: a bucket is up when any of
+// its checks succeeded, as the client-side aggregation treats its own buckets.
+func rollupPoints(items []store.MonitorMetricRollup) []store.MonitorMetric {
+	out := make([]store.MonitorMetric, 0, len(items))
+	for _, item := range items {
+		out = append(out, store.MonitorMetric{
+			MonitorID: item.MonitorID,
+			TS:        item.BucketStart,
+			LatencyMs: int(math.Round(item.LatencyAvgMs)),
+			OK:        item.OKCount > 0,
+		})
+	}
+	return out
+}
+
 func (h *MonitoringHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(pathParams(r)["id"])
 	if err != nil {
@@ -88,6 +154,10 @@ func rangeSince(raw string, fallback time.Duration) time.Time {
 		return time.Now().UTC().Add(-7 * 24 * time.Hour)
 	case "30d":
 		return time.Now().UTC().Add(-30 * 24 * time.Hour)
+	case "90d":
+		return time.Now().UTC().Add(-90 * 24 * time.Hour)
+	case "365d":
+		return time.Now().UTC().Add(-365 * 24 * time.Hour)
 	default:
 		return time.Now().UTC().Add(-fallback)
 	}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"crypto/x509"
+	"encoding/json"
 	"errors"
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
@@ -11,31 +14,45 @@ import (
 )
 
 type monitorPayload struct {
-	Name              string            `json:"name"`
-	Type              string            `json:"type"`
-	URL               string            `json:"url"`
-	Host              string            `json:"host"`
-	Port              int               `json:"port"`
-	Method            string            `json:"method"`
-	RequestBody       string            `json:"request_body"`
-	RequestBodyType   string            `json:"request_body_type"`
-	Headers           map[string]string `json:"headers"`
-	IntervalSec       int               `json:"interval_sec"`
-	TimeoutSec        int               `json:"timeout_sec"`
-	Retries           int               `json:"retries"`
-	RetryIntervalSec  int               `json:"retry_interval_sec"`
-	AllowedStatus     []string          `json:"allowed_status"`
-	IsActive          *bool             `json:"is_active"`
-	IsPaused          *bool             `json:"is_paused"`
-	Tags              []string          `json:"tags"`
-	GroupID           *int64            `json:"group_id"`
-	SLATargetPct      *float64          `json:"sla_target_pct"`
-	IgnoreTLSErrors   *bool             `json:"ignore_tls_errors"`
-	NotifyTLSExpiring *bool             `json:"notify_tls_expiring"`
-	AutoIncident      *bool             `json:"auto_incident"`
-	AutoTaskOnDown    *bool             `json:"auto_task_on_down"`
-	IncidentSeverity  string            `json:"incident_severity"`
-	IncidentTypeID    string            `json:"incident_type_id"`
+	Name              string                     `json:"name"`
+	Type              string                     `json:"type"`
+	URL               string                     `json:"url"`
+	Host              string                     `json:"host"`
+	Port              int                        `json:"port"`
+	Method            string                     `json:"method"`
+	RequestBody       string                     `json:"request_body"`
+	RequestBodyType   string                     `json:"request_body_type"`
+	Headers           map[string]string          `json:"headers"`
+	IntervalSec       int                        `json:"interval_sec"`
+	TimeoutSec        int                        `json:"timeout_sec"`
+	Retries           int                        `json:"retries"`
+	RetryIntervalSec  int                        `json:"retry_interval_sec"`
+	AllowedStatus     []string                   `json:"allowed_status"`
+	IsActive          *bool                      `json:"is_active"`
+	IsPaused          *bool                      `json:"is_paused"`
+	Tags              []string                   `json:"tags"`
+	GroupID           *int64                     `json:"group_id"`
+	ParentID          *int64                     `json:"parent_id"`
+	SLATargetPct      *float64                   `json:"sla_target_pct"`
+	IgnoreTLSErrors   *bool                      `json:"ignore_tls_errors"`
+	NotifyTLSExpiring *bool                      `json:"notify_tls_expiring"`
+	AutoIncident      *bool                      `json:"auto_incident"`
+	AutoTaskOnDown    *bool                      `json:"auto_task_on_down"`
+	IncidentSeverity  string                     `json:"incident_severity"`
+	IncidentTypeID    string                     `json:"incident_type_id"`
+	PushGraceSec      *int                       `json:"push_grace_sec"`
+	Options           *store.MonitorOptions      `json:"options"`
+	Credentials       *monitorCredentialsPayload `json:"credentials"`
+}
+
+type monitorCredentialsPayload struct {
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Secret     string `json:"secret"`
+	Token      string `json:"token"`
+	ClientCert string `json:"client_cert"`
+	ClientKey  string `json:"client_key"`
+	PrivateKey string `json:"private_key"`
 }
 
 func payloadToMonitor(payload monitorPayload, settings *store.MonitorSettings, createdBy int64) (*store.Monitor, error) {
@@ -61,6 +78,15 @@ func payloadToMonitor(payload monitorPayload, settings *store.MonitorSettings, c
 		IncidentTypeID:   strings.TrimSpace(payload.IncidentTypeID),
 		CreatedBy:        createdBy,
 	}
+	if payload.PushGraceSec != nil {
+		m.PushGraceSec = *payload.PushGraceSec
+	}
+	if payload.ParentID != nil && *payload.ParentID > 0 {
+		m.ParentID = payload.ParentID
+	}
+	if payload.Options != nil {
+		m.Options = *payload.Options
+	}
 	if payload.IsActive != nil {
 		m.IsActive = *payload.IsActive
 	} else {
@@ -90,6 +116,9 @@ func payloadToMonitor(payload monitorPayload, settings *store.MonitorSettings, c
 	if err := validateMonitor(m); err != nil {
 		return nil, err
 	}
+	if err := validateCredentials(payload.Credentials); err != nil {
+		return nil, err
+	}
 	return m, nil
 }
 
@@ -143,6 +172,13 @@ func mergeMonitor(existing *store.Monitor, payload monitorPayload, settings *sto
 	if payload.GroupID != nil {
 		m.GroupID = payload.GroupID
 	}
+	if payload.ParentID != nil {
+		// parent_id 0 detaches the monitor from its parent.
+		m.ParentID = nil
+		if *payload.ParentID > 0 {
+			m.ParentID = payload.ParentID
+		}
+	}
 	if payload.SLATargetPct != nil {
 		m.SLATargetPct = payload.SLATargetPct
 	}
@@ -167,6 +203,12 @@ func mergeMonitor(existing *store.Monitor, payload monitorPayload, settings *sto
 	if payload.IncidentTypeID != "" || payload.AutoIncident != nil {
 		m.IncidentTypeID = strings.TrimSpace(payload.IncidentTypeID)
 	}
+	if payload.PushGraceSec != nil {
+		m.PushGraceSec = *payload.PushGraceSec
+	}
+	if payload.Options != nil {
+		m.Options = *payload.Options
+	}
 	if payload.IsActive != nil {
 		m.IsActive = *payload.IsActive
 	}
@@ -177,6 +219,9 @@ func mergeMonitor(existing *store.Monitor, payload monitorPayload, settings *sto
 	if err := validateMonitor(&m); err != nil {
 		return nil, err
 	}
+	if err := validateCredentials(payload.Credentials); err != nil {
+		return nil, err
+	}
 	return &m, nil
 }
 
@@ -219,9 +264,36 @@ func applyDefaults(m *store.Monitor, settings *store.MonitorSettings) {
 	}
 	switch monitoring.NormalizeType(m.Type) {
 	case monitoring.TypeDNS:
-		if m.Method == "" || m.Method == "GET" {
-			m.Method = "A"
+		if rtype := monitoring.NormalizeDNSRecordType(m.Method); rtype != "" {
+			m.Method = rtype
+		}
+	case monitoring.TypeGameDig:
+		if m.Port <= 0 {
+			protocol := ""
+			if m.Options.Game != nil {
+				protocol = m.Options.Game.Protocol
+			}
+			m.Port = monitoring.DefaultGamePort(protocol)
+		}
+	case monitoring.TypeSMTP, monitoring.TypeIMAP, monitoring.TypePOP3:
+		if m.Port <= 0 {
+			mode := ""
+			if m.Options.Mail != nil {
+				mode = m.Options.Mail.TLS
+			}
+			m.Port = monitoring.DefaultMailPort(m.Type, mode)
+		}
+	case monitoring.TypeLDAP:
+		if m.Port <= 0 {
+			mode := ""
+			if m.Options.LDAP != nil {
+				mode = m.Options.LDAP.TLS
+			}
+			m.Port = monitoring.DefaultLDAPPort(mode)
 		}
+	case monitoring.TypePrometheus:
+		// Scrapes are always plain GET requests.
+		m.Method = "GET"
 	default:
 		if m.Port <= 0 {
 			if p := monitoring.DefaultPortForType(m.Type); p > 0 {
@@ -232,6 +304,72 @@ func applyDefaults(m *store.Monitor, settings *store.MonitorSettings) {
 	if len(m.AllowedStatus) == 0 {
 		m.AllowedStatus = []string{"200-299"}
 	}
+	// Options of other monitor types are dropped so switching the type does not leave stale settings.
+	kind := monitoring.NormalizeType(m.Type)
+	if kind != monitoring.TypePing {
+		m.Options.Ping = nil
+	}
+	if kind != monitoring.TypeMySQL && kind != monitoring.TypeMSSQL {
+		m.Options.Database = nil
+	}
+	if kind != monitoring.TypeRedis {
+		m.Options.Redis = nil
+	}
+	if kind != monitoring.TypeMongoDB {
+		m.Options.MongoDB = nil
+	}
+	if kind != monitoring.TypeRadius {
+		m.Options.Radius = nil
+	}
+	if kind != monitoring.TypeMQTT {
+		m.Options.MQTT = nil
+	}
+	if kind != monitoring.TypeKafkaProducer {
+		m.Options.Kafka = nil
+	}
+	if kind != monitoring.TypeDocker {
+		m.Options.Docker = nil
+	}
+	if kind != monitoring.TypeGRPCKeyword {
+		m.Options.GRPC = nil
+	}
+	if kind != monitoring.TypeSteam && kind != monitoring.TypeGameDig {
+		m.Options.Game = nil
+	}
+	if kind != monitoring.TypeDNS {
+		m.Options.DNS = nil
+	}
+	if kind != monitoring.TypeHTTPJSON {
+		m.Options.JSON = nil
+	}
+	if kind != monitoring.TypeHTTP && kind != monitoring.TypeHTTPKeyword && kind != monitoring.TypeHTTPJSON && kind != monitoring.TypeHTTPTransaction && kind != monitoring.TypePrometheus {
+		m.Options.HTTP = nil
+	}
+	if kind != monitoring.TypeHTTPTransaction {
+		m.Options.Transaction = nil
+	}
+	if kind != monitoring.TypeSMTP && kind != monitoring.TypeIMAP && kind != monitoring.TypePOP3 {
+		m.Options.Mail = nil
+	}
+	if kind != monitoring.TypeSSH {
+		m.Options.SSH = nil
+	}
+	if kind != monitoring.TypeLDAP {
+		m.Options.LDAP = nil
+	}
+	if kind != monitoring.TypeTLS {
+		m.Options.TLSScan = nil
+	}
+	if kind != monitoring.TypePrometheus {
+		m.Options.Prometheus = nil
+	}
+	if kind != monitoring.TypeHTTP && kind != monitoring.TypeHTTPKeyword && kind != monitoring.TypeHTTPJSON {
+		m.Options.Content = nil
+		m.Options.Headers = nil
+	}
+	if monitoring.TypeIsPassive(kind) {
+		m.Options.Probes = nil
+	}
 }
 
 func validateMonitor(m *store.Monitor) error {
@@ -252,9 +390,12 @@ func validateMonitor(m *store.Monitor) error {
 		}
 	case monitoring.TypeIsPassive(m.Type):
 		// Passive monitors are updated externally and do not require target fields.
-		if strings.TrimSpace(m.RequestBody) == "" {
+		if strings.TrimSpace(m.RequestBody) == "" && strings.TrimSpace(m.PushTokenHash) == "" {
 			return errors.New("monitoring.error.pushTokenRequired")
 		}
+		if m.PushGraceSec < 0 || m.PushGraceSec > 86400 {
+			return errors.New("monitoring.error.invalidPushGrace")
+		}
 	}
 	if m.IntervalSec <= 0 {
 		return errors.New("monitoring.error.invalidInterval")
@@ -292,9 +433,389 @@ func validateMonitor(m *store.Monitor) error {
 	if !validateHeaders(m.Headers) {
 		return errors.New("monitoring.error.invalidHeaders")
 	}
+	if !validatePingOptions(m.Options.Ping) {
+		return errors.New("monitoring.error.invalidPingOptions")
+	}
+	if !validateDatabaseOptions(m.Options.Database) {
+		return errors.New("monitoring.error.invalidDatabaseOptions")
+	}
+	if !validateRedisOptions(m.Options.Redis) {
+		return errors.New("monitoring.error.invalidRedisOptions")
+	}
+	if !validateMongoDBOptions(m.Options.MongoDB) {
+		return errors.New("monitoring.error.invalidMongoDBOptions")
+	}
+	if !validateRadiusOptions(m.Options.Radius) {
+		return errors.New("monitoring.error.invalidRadiusOptions")
+	}
+	if !validateMQTTOptions(m.Options.MQTT) {
+		return errors.New("monitoring.error.invalidMQTTOptions")
+	}
+	if !validateKafkaOptions(m.Options.Kafka) {
+		return errors.New("monitoring.error.invalidKafkaOptions")
+	}
+	if !validateDockerOptions(m.Options.Docker) {
+		return errors.New("monitoring.error.invalidDockerOptions")
+	}
+	if !validateGRPCOptions(m.Options.GRPC) {
+		return errors.New("monitoring.error.invalidGRPCOptions")
+	}
+	if m.Options.GRPC != nil && strings.TrimSpace(m.Options.GRPC.Method) != "" && strings.TrimSpace(m.RequestBody) == "" {
+		return errors.New("monitoring.error.keywordRequired")
+	}
+	if !validateGameOptions(m.Options.Game) {
+		return errors.New("monitoring.error.invalidGameOptions")
+	}
+	if strings.EqualFold(m.Type, monitoring.TypeDNS) && monitoring.NormalizeDNSRecordType(m.Method) == "" {
+		return errors.New("monitoring.error.invalidDNSOptions")
+	}
+	if !validateDNSOptions(m.Options.DNS) {
+		return errors.New("monitoring.error.invalidDNSOptions")
+	}
+	if m.Options.JSON != nil && !monitoring.ValidJSONAssertions(m.Options.JSON.Assertions) {
+		return errors.New("monitoring.error.invalidJSONOptions")
+	}
+	if !validateHTTPOptions(m.Options.HTTP) {
+		return errors.New("monitoring.error.invalidHTTPOptions")
+	}
+	if !validateTLSOptions(m.Options.TLS) {
+		return errors.New("monitoring.error.invalidTLSOptions")
+	}
+	if strings.EqualFold(m.Type, monitoring.TypeHTTPTransaction) && !validateTransactionOptions(m.Options.Transaction) {
+		return errors.New("monitoring.error.invalidTransactionOptions")
+	}
+	if !validateMailOptions(monitoring.NormalizeType(m.Type), m.Options.Mail) {
+		return errors.New("monitoring.error.invalidMailOptions")
+	}
+	if !validateSSHOptions(m.Options.SSH) {
+		return errors.New("monitoring.error.invalidSSHOptions")
+	}
+	if !validateLDAPOptions(m.Options.LDAP) {
+		return errors.New("monitoring.error.invalidLDAPOptions")
+	}
+	if !validateTLSScanOptions(m.Options.TLSScan) {
+		return errors.New("monitoring.error.invalidTLSScanOptions")
+	}
+	if strings.EqualFold(m.Type, monitoring.TypePrometheus) && (m.Options.Prometheus == nil || !monitoring.ValidPrometheusExpression(m.Options.Prometheus.Expression)) {
+		return errors.New("monitoring.error.invalidPrometheusOptions")
+	}
+	if m.Options.Content != nil && !monitoring.ValidContentOptions(*m.Options.Content) {
+		return errors.New("monitoring.error.invalidContentOptions")
+	}
+	if !validateHeadersOptions(m.Options.Headers) {
+		return errors.New("monitoring.error.invalidHeadersOptions")
+	}
+	if !validateProbeOptions(m.Options.Probes) {
+		return errors.New("monitoring.error.invalidProbeOptions")
+	}
 	return nil
 }
 
+func validateDatabaseOptions(opts *store.DatabaseOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if strings.TrimSpace(opts.TLSMode) != "" && monitoring.NormalizeDatabaseTLSMode(opts.TLSMode) == "" {
+		return false
+	}
+	return len(opts.Database) <= 128 && len(opts.Query) <= 4096 && len(opts.ExpectedResult) <= 1024
+}
+
+func validateRedisOptions(opts *store.RedisOptions) bool {
+	if opts == nil {
+		return true
+	}
+	return strings.TrimSpace(opts.ExpectedRole) == "" || monitoring.NormalizeRedisRole(opts.ExpectedRole) != ""
+}
+
+func validateMongoDBOptions(opts *store.MongoDBOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if strings.TrimSpace(opts.ExpectedRole) != "" && monitoring.NormalizeMongoRole(opts.ExpectedRole) == "" {
+		return false
+	}
+	return len(opts.AuthSource) <= 64 && len(opts.Database) <= 64
+}
+
+func validateRadiusOptions(opts *store.RadiusOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if monitoring.NormalizeRadiusAuthMethod(opts.AuthMethod) == "" || monitoring.NormalizeRadiusExpectedResult(opts.ExpectedResult) == "" {
+		return false
+	}
+	return len(opts.NASIdentifier) <= 253
+}
+
+func validateMQTTOptions(opts *store.MQTTOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if monitoring.NormalizeMQTTVersion(opts.Version) == "" {
+		return false
+	}
+	// Wildcards would match foreign traffic instead of the probe message.
+	if strings.ContainsAny(opts.Topic, "#+") {
+		return false
+	}
+	return len(opts.ClientID) <= 64 && len(opts.Topic) <= 256
+}
+
+func validateKafkaOptions(opts *store.KafkaOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if _, ok := monitoring.NormalizeKafkaSASLMechanism(opts.SASLMechanism); !ok {
+		return false
+	}
+	if opts.Partition < 0 || len(opts.Topic) > 249 {
+		return false
+	}
+	return !opts.Produce || strings.TrimSpace(opts.Topic) != ""
+}
+
+func validateDockerOptions(opts *store.DockerOptions) bool {
+	if opts == nil {
+		return true
+	}
+	socket := strings.TrimSpace(opts.Socket)
+	if socket != "" && (!strings.HasPrefix(socket, "/") || len(socket) > 256 || opts.TLS) {
+		return false
+	}
+	if strings.TrimSpace(opts.Container) != "" && strings.TrimSpace(opts.LabelSelector) != "" {
+		return false
+	}
+	return len(opts.Container) <= 128 && len(opts.LabelSelector) <= 256
+}
+
+func validateGRPCOptions(opts *store.GRPCOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if _, ok := monitoring.NormalizeGRPCMethod(opts.Method); !ok {
+		return false
+	}
+	if len(opts.Service) > 256 || len(opts.Request) > 64<<10 {
+		return false
+	}
+	if strings.TrimSpace(opts.Request) == "" {
+		return true
+	}
+	var obj map[string]any
+	return json.Unmarshal([]byte(opts.Request), &obj) == nil
+}
+
+func validateGameOptions(opts *store.GameOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if monitoring.NormalizeGameProtocol(opts.Protocol) == "" {
+		return false
+	}
+	return opts.MinPlayers >= 0 && opts.MinPlayers <= 1000 && opts.MinPlayersChecks >= 0 && opts.MinPlayersChecks <= 100
+}
+
+func validateDNSOptions(opts *store.DNSOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if monitoring.NormalizeDNSTransport(opts.Transport) == "" || len(opts.Resolver) > 255 || len(opts.Expected) > 64 {
+		return false
+	}
+	if resolver := strings.TrimSpace(opts.Resolver); resolver != "" {
+		if host, port, err := net.SplitHostPort(resolver); err == nil {
+			if n, convErr := strconv.Atoi(port); host == "" || convErr != nil || n <= 0 || n > 65535 {
+				return false
+			}
+		} else if strings.ContainsAny(resolver, "/ ") {
+			return false
+		}
+	}
+	for _, val := range opts.Expected {
+		if len(val) > 1024 {
+			return false
+		}
+	}
+	return true
+}
+
+func validateHTTPOptions(opts *store.HTTPOptions) bool {
+	if opts == nil {
+		return true
+	}
+	auth := monitoring.NormalizeHTTPAuth(opts.Auth)
+	if auth == "" {
+		return false
+	}
+	if auth == monitoring.HTTPAuthOAuth2 {
+		o := opts.OAuth2
+		if o == nil || strings.TrimSpace(o.ClientID) == "" || len(o.ClientID) > 256 || len(o.Scopes) > 32 {
+			return false
+		}
+		u, err := url.Parse(strings.TrimSpace(o.TokenURL))
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") || len(o.TokenURL) > 2048 {
+			return false
+		}
+		for _, scope := range o.Scopes {
+			if scope == "" || len(scope) > 256 || strings.ContainsAny(scope, " \"") {
+				return false
+			}
+		}
+	}
+	if proxy := strings.TrimSpace(opts.Proxy); proxy != "" {
+		// Proxy credentials would be stored in plain text, so they are not accepted in the URL.
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" || u.User != nil || len(proxy) > 2048 {
+			return false
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func validateTransactionOptions(opts *store.TransactionOptions) bool {
+	if opts == nil || !monitoring.ValidTransactionSteps(opts.Steps) {
+		return false
+	}
+	for _, step := range opts.Steps {
+		if !validateHeaders(step.Headers) || !validateStatusRanges(step.AllowedStatus) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateMailOptions(kind string, opts *store.MailOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if monitoring.NormalizeMailTLS(opts.TLS) == "" {
+		return false
+	}
+	if helo := strings.TrimSpace(opts.Helo); len(helo) > 255 || strings.ContainsAny(helo, " \t\r\n") {
+		return false
+	}
+	from := strings.TrimSpace(opts.MailFrom)
+	rcpt := strings.TrimSpace(opts.RcptTo)
+	if from == "" && rcpt == "" {
+		return true
+	}
+	// The envelope test only exists for SMTP and needs both addresses.
+	if kind != monitoring.TypeSMTP || from == "" || rcpt == "" {
+		return false
+	}
+	return monitoring.ValidMailAddress(from) && monitoring.ValidMailAddress(rcpt)
+}
+
+func validateSSHOptions(opts *store.SSHOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if fp := strings.TrimSpace(opts.HostKeyFingerprint); fp != "" && !monitoring.ValidSSHFingerprint(fp) {
+		return false
+	}
+	return monitoring.ValidSSHCommand(strings.TrimSpace(opts.Command))
+}
+
+func validateLDAPOptions(opts *store.LDAPOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if monitoring.NormalizeMailTLS(opts.TLS) == "" || monitoring.NormalizeLDAPScope(opts.Scope) == "" {
+		return false
+	}
+	if len(opts.BaseDN) > 1024 || opts.MinEntries < 0 || opts.MinEntries > 1000 {
+		return false
+	}
+	if filter := strings.TrimSpace(opts.Filter); filter != "" && !monitoring.ValidLDAPFilter(filter) {
+		return false
+	}
+	return true
+}
+
+func validateTLSScanOptions(opts *store.TLSScanOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if grade := strings.TrimSpace(opts.MinGrade); grade != "" && monitoring.NormalizeTLSGrade(grade) == "" {
+		return false
+	}
+	name := strings.TrimSpace(opts.ServerName)
+	return len(name) <= 253 && !strings.ContainsAny(name, " \t\r\n/:@")
+}
+
+func validateHeadersOptions(opts *store.HeadersOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if opts.MinHSTSMaxAge < 0 || opts.MinHSTSMaxAge > 2*365*24*3600 {
+		return false
+	}
+	return opts.MinScore >= 0 && opts.MinScore <= 100 && opts.ControlID >= 0
+}
+
+func validateProbeOptions(opts *store.ProbeOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if len(opts.ProbeIDs) > monitoring.MaxProbesPerMonitor {
+		return false
+	}
+	seen := map[int64]bool{}
+	for _, id := range opts.ProbeIDs {
+		if id <= 0 || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	total := len(opts.ProbeIDs)
+	if opts.IncludeLocal {
+		total++
+	}
+	return opts.Quorum >= 0 && opts.Quorum <= total
+}
+
+func validateTLSOptions(opts *store.TLSOptions) bool {
+	if opts == nil || strings.TrimSpace(opts.CACert) == "" {
+		return true
+	}
+	if len(opts.CACert) > 64<<10 {
+		return false
+	}
+	return x509.NewCertPool().AppendCertsFromPEM([]byte(opts.CACert))
+}
+
+func validatePingOptions(opts *store.PingOptions) bool {
+	if opts == nil {
+		return true
+	}
+	if opts.Count < 0 || opts.Count > 20 {
+		return false
+	}
+	if opts.IntervalMs < 0 || opts.IntervalMs > 10000 {
+		return false
+	}
+	if opts.PacketSize < 0 || opts.PacketSize > 1472 {
+		return false
+	}
+	for _, pct := range []*float64{opts.MaxLossPct, opts.DegradedLossPct} {
+		if pct != nil && (*pct < 0 || *pct > 100) {
+			return false
+		}
+	}
+	for _, ms := range []*float64{opts.MaxRTTMs, opts.DegradedRTTMs} {
+		if ms != nil && *ms <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func validateHTTPMonitor(m *store.Monitor) error {
 	u, err := url.Parse(strings.TrimSpace(m.URL))
 	if err != nil || u == nil || u.Scheme == "" || u.Host == "" {
@@ -341,6 +862,10 @@ func validateHTTPMonitor(m *store.Monitor) error {
 }
 
 func validateTCPMonitor(m *store.Monitor) error {
+	if strings.EqualFold(m.Type, monitoring.TypeDocker) && m.Options.Docker != nil && strings.TrimSpace(m.Options.Docker.Socket) != "" {
+		// Unix socket monitors talk to the local Engine and have no network target.
+		return nil
+	}
 	m.Host = normalizeMonitorHost(m.Host)
 	if m.Host == "" {
 		return errors.New("monitoring.error.invalidHost")
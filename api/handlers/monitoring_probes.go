@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/store"
+)
+
+const (
+	probeEnrolTokenTTL   = 24 * time.Hour
+	probeLivenessTag     = "probe"
+	errProbeAccessDenied = "common.accessDenied"
+)
+
+type probePayload struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+}
+
+type probeTokenResponse struct {
+	*store.MonitoringProbe
+	Token string `json:"token"`
+}
+
+// ListProbes returns the probe agents with their enrolment and heartbeat state.
+func (h *MonitoringHandler) ListProbes(w http.ResponseWriter, r *http.Request) {
+	items, err := h.store.ListProbes(r.Context())
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.MonitoringProbe{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// CreateProbe registers a probe with its liveness push monitor and returns the one-time enrolment token.
+func (h *MonitoringHandler) CreateProbe(w http.ResponseWriter, r *http.Request) {
+	var payload probePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(payload.Name)
+	location := strings.TrimSpace(payload.Location)
+	if name == "" || len(name) > 100 || len(location) > 100 {
+		http.Error(w, "monitoring.error.invalidProbe", http.StatusBadRequest)
+		return
+	}
+	liveness := &store.Monitor{
+		Name:          "Probe: " + name,
+		Type:          monitoring.TypePush,
+		IntervalSec:   2 * monitoring.DefaultProbePollSec,
+		TimeoutSec:    5,
+		PushGraceSec:  2 * monitoring.DefaultProbePollSec,
+		PushTokenHash: monitoring.HashPushToken(randomPushToken()),
+		AllowedStatus: []string{"200-299"},
+		IsActive:      true,
+		Tags:          []string{probeLivenessTag},
+		CreatedBy:     sessionUserID(r),
+	}
+	monitorID, err := h.store.CreateMonitor(r.Context(), liveness)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	_ = h.store.UpsertMonitorState(r.Context(), &store.MonitorState{
+		MonitorID:        monitorID,
+		Status:           initialStatus(false),
+		LastResultStatus: "down",
+	})
+	token := randomProbeSecret()
+	expires := time.Now().UTC().Add(probeEnrolTokenTTL)
+	probe := &store.MonitoringProbe{
+		Name:           name,
+		Location:       location,
+		MonitorID:      &monitorID,
+		EnrolTokenHash: monitoring.HashProbeToken(token),
+		EnrolExpiresAt: &expires,
+		CreatedBy:      sessionUserID(r),
+	}
+	id, err := h.store.CreateProbe(r.Context(), probe)
+	if err != nil {
+		_ = h.store.DeleteMonitor(r.Context(), monitorID)
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	created, err := h.store.GetProbe(r.Context(), id)
+	if err != nil || created == nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, monitorAuditProbeCreate, fmt.Sprintf("%d|%s", id, name))
+	writeJSON(w, http.StatusCreated, probeTokenResponse{MonitoringProbe: created, Token: token})
+}
+
+// DeleteProbe removes a probe that no monitor uses any more, together with its liveness monitor.
+func (h *MonitoringHandler) DeleteProbe(w http.ResponseWriter, r *http.Request) {
+	probe, ok := h.probeFromPath(w, r)
+	if !ok {
+		return
+	}
+	monitors, err := h.store.ListProbeMonitors(r.Context(), probe.ID)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if len(monitors) > 0 {
+		http.Error(w, "monitoring.error.probeInUse", http.StatusConflict)
+		return
+	}
+	if err := h.store.DeleteProbe(r.Context(), probe.ID); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if probe.MonitorID != nil {
+		_ = h.store.DeleteMonitor(r.Context(), *probe.MonitorID)
+	}
+	h.audit(r, monitorAuditProbeDelete, fmt.Sprintf("%d|%s", probe.ID, probe.Name))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RotateProbeToken issues a new enrolment token and revokes the current secret, so the probe has to enrol again.
+func (h *MonitoringHandler) RotateProbeToken(w http.ResponseWriter, r *http.Request) {
+	probe, ok := h.probeFromPath(w, r)
+	if !ok {
+		return
+	}
+	token := randomProbeSecret()
+	expires := time.Now().UTC().Add(probeEnrolTokenTTL)
+	probe.EnrolTokenHash = monitoring.HashProbeToken(token)
+	probe.EnrolExpiresAt = &expires
+	probe.SecretEnc = nil
+	probe.Enrolled = false
+	probe.EnrolledAt = nil
+	if err := h.store.UpdateProbe(r.Context(), probe); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, monitorAuditProbeToken, strconv.FormatInt(probe.ID, 10))
+	writeJSON(w, http.StatusOK, probeTokenResponse{MonitoringProbe: probe, Token: token})
+}
+
+// GetMonitorProbes returns the last result of every location of a monitor run by probes.
+func (h *MonitoringHandler) GetMonitorProbes(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(pathParams(r)["id"])
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	mon, err := h.store.GetMonitor(r.Context(), id)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if mon == nil {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return
+	}
+	if h.engine == nil {
+		http.Error(w, errServiceUnavailable, http.StatusServiceUnavailable)
+		return
+	}
+	items, err := h.engine.ProbeLocations(r.Context(), *mon, time.Now().UTC())
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if items == nil {
+		items = []store.ProbeLocation{}
+	}
+	quorum := 0
+	if mon.Options.Probes != nil && len(mon.Options.Probes.ProbeIDs) > 0 {
+		quorum = monitoring.ProbeQuorum(*mon.Options.Probes)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "quorum": quorum})
+}
+
+// ProbeEnroll exchanges a one-time enrolment token for the signing secret of the probe.
+func (h *MonitoringHandler) ProbeEnroll(w http.ResponseWriter, r *http.Request) {
+	var payload monitoring.ProbeEnrolRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&payload); err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	token := strings.TrimSpace(payload.Token)
+	if token == "" || len(token) > 256 {
+		http.Error(w, errProbeAccessDenied, http.StatusUnauthorized)
+		return
+	}
+	if h.encryptor == nil || h.engine == nil {
+		http.Error(w, errServiceUnavailable, http.StatusServiceUnavailable)
+		return
+	}
+	tokenHash := monitoring.HashProbeToken(token)
+	probe, err := h.store.GetProbeByEnrolTokenHash(r.Context(), tokenHash)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	now := time.Now().UTC()
+	if probe == nil || probe.EnrolExpiresAt == nil || now.After(*probe.EnrolExpiresAt) {
+		http.Error(w, errProbeAccessDenied, http.StatusUnauthorized)
+		return
+	}
+	secret := randomProbeSecret()
+	enc, err := h.encryptor.EncryptToBlob([]byte(secret))
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	probe.SecretEnc = enc
+	probe.Enrolled = true
+	probe.EnrolTokenHash = ""
+	probe.EnrolExpiresAt = nil
+	probe.EnrolledAt = &now
+	enrolled, err := h.store.EnrollProbe(r.Context(), probe, tokenHash)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	if !enrolled {
+		http.Error(w, errProbeAccessDenied, http.StatusUnauthorized)
+		return
+	}
+	if err := h.engine.ProbeHeartbeat(r.Context(), probe, clientIP(r, h.cfg), payload.Version); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	h.probeAudit(r, probe, monitorAuditProbeEnroll, clientIP(r, h.cfg))
+	writeJSON(w, http.StatusOK, monitoring.ProbeEnrolResponse{ProbeID: probe.ID, Secret: secret, PollSec: monitoring.DefaultProbePollSec})
+}
+
+// ProbeMonitors returns the assignments of a probe; every poll is also its heartbeat.
+func (h *MonitoringHandler) ProbeMonitors(w http.ResponseWriter, r *http.Request) {
+	probe, _, ok := h.authenticateProbe(w, r, monitoring.ProbeEndpointMonitors)
+	if !ok {
+		return
+	}
+	cfg, err := h.engine.ProbeConfig(r.Context(), probe.ID)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, cfg)
+}
+
+// ProbeResults stores the check results a probe ran for its monitors.
+func (h *MonitoringHandler) ProbeResults(w http.ResponseWriter, r *http.Request) {
+	probe, body, ok := h.authenticateProbe(w, r, monitoring.ProbeEndpointResults)
+	if !ok {
+		return
+	}
+	var reports []monitoring.ProbeReport
+	if err := json.Unmarshal(body, &reports); err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	accepted, err := h.engine.RecordProbeReports(r.Context(), probe.ID, reports)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "monitoring.error.") {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"accepted": accepted})
+}
+
+// authenticateProbe verifies the signature of a probe request and records it; the assignment poll is also the
+// heartbeat of the probe.
+func (h *MonitoringHandler) authenticateProbe(w http.ResponseWriter, r *http.Request, endpoint string) (*store.MonitoringProbe, []byte, bool) {
+	if h.encryptor == nil || h.engine == nil {
+		http.Error(w, errServiceUnavailable, http.StatusServiceUnavailable)
+		return nil, nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, monitoring.ProbeMaxPayloadBytes+1))
+	if err != nil || len(body) > monitoring.ProbeMaxPayloadBytes {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return nil, nil, false
+	}
+	id, err := parseID(r.Header.Get(monitoring.ProbeHeaderID))
+	if err != nil {
+		http.Error(w, errProbeAccessDenied, http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	probe, err := h.store.GetProbe(r.Context(), id)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if probe == nil || len(probe.SecretEnc) == 0 {
+		http.Error(w, errProbeAccessDenied, http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	secret, err := h.encryptor.DecryptBlob(probe.SecretEnc)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if !monitoring.VerifyProbeRequest(string(secret), r.Method, endpoint, r.Header.Get(monitoring.ProbeHeaderTimestamp),
+		r.Header.Get(monitoring.ProbeHeaderSignature), body, time.Now().UTC()) {
+		http.Error(w, errProbeAccessDenied, http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	if !h.engine.ClaimProbeRequest(probe.ID, r.Header.Get(monitoring.ProbeHeaderTimestamp), r.Header.Get(monitoring.ProbeHeaderSignature), time.Now().UTC()) {
+		http.Error(w, errProbeAccessDenied, http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	record := h.engine.ProbeSeen
+	if endpoint == monitoring.ProbeEndpointMonitors {
+		record = h.engine.ProbeHeartbeat
+	}
+	if err := record(r.Context(), probe, clientIP(r, h.cfg), r.Header.Get(monitoring.ProbeHeaderVersion)); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return probe, body, true
+}
+
+func (h *MonitoringHandler) probeFromPath(w http.ResponseWriter, r *http.Request) (*store.MonitoringProbe, bool) {
+	id, err := parseID(pathParams(r)["id"])
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return nil, false
+	}
+	probe, err := h.store.GetProbe(r.Context(), id)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return nil, false
+	}
+	if probe == nil {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return nil, false
+	}
+	return probe, true
+}
+
+// probeAudit logs actions of a probe, which has no session, under its own name.
+func (h *MonitoringHandler) probeAudit(r *http.Request, probe *store.MonitoringProbe, action, details string) {
+	if h == nil || h.audits == nil {
+		return
+	}
+	_ = h.audits.Log(r.Context(), "probe:"+probe.Name, action, fmt.Sprintf("%d|%s", probe.ID, details))
+}
+
+func randomProbeSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return randomPushToken() + randomPushToken()
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestProbeEnrolmentAndQuorum(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	settings, _ := ms.GetSettings(ctx)
+	settings.EngineEnabled = true
+	if err := ms.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	enc, err := utils.NewEncryptorFromString("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("encryptor: %v", err)
+	}
+	engine := monitoring.NewEngineWithDeps(ms, nil, nil, "", enc, nil, utils.NewLogger())
+	h := NewMonitoringHandler(nil, ms, nil, engine, nil, enc)
+
+	createProbe := func(name string) (int64, string) {
+		rec := httptest.NewRecorder()
+		h.CreateProbe(rec, httptest.NewRequest("POST", "/api/monitoring/probes", strings.NewReader(`{"name":"`+name+`","location":"eu"}`)))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create probe: %d %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			ID        int64  `json:"id"`
+			MonitorID int64  `json:"monitor_id"`
+			Token     string `json:"token"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		if resp.Token == "" || resp.MonitorID == 0 {
+			t.Fatalf("expected a token and a liveness monitor, got %s", rec.Body.String())
+		}
+		return resp.ID, resp.Token
+	}
+	enroll := func(token string) monitoring.ProbeEnrolResponse {
+		rec := httptest.NewRecorder()
+		h.ProbeEnroll(rec, httptest.NewRequest("POST", "/api/probe/enroll", strings.NewReader(`{"token":"`+token+`","version":"test"}`)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("enroll: %d %s", rec.Code, rec.Body.String())
+		}
+		var resp monitoring.ProbeEnrolResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+	signed := func(p monitoring.ProbeEnrolResponse, method, endpoint string, body []byte) *http.Request {
+		req := httptest.NewRequest(method, "/api"+endpoint, bytes.NewReader(body))
+		ts := time.Now().Unix()
+		req.Header.Set(monitoring.ProbeHeaderID, strconv.FormatInt(p.ProbeID, 10))
+		req.Header.Set(monitoring.ProbeHeaderTimestamp, strconv.FormatInt(ts, 10))
+		req.Header.Set(monitoring.ProbeHeaderSignature, monitoring.SignProbeRequest(p.Secret, method, endpoint, ts, body))
+		return req
+	}
+
+	id1, token1 := createProbe("Frankfurt")
+	id2, token2 := createProbe("Amsterdam")
+	p1, p2 := enroll(token1), enroll(token2)
+	if p1.ProbeID != id1 || p1.Secret == "" {
+		t.Fatalf("unexpected enrolment %+v", p1)
+	}
+	rec := httptest.NewRecorder()
+	h.ProbeEnroll(rec, httptest.NewRequest("POST", "/api/probe/enroll", strings.NewReader(`{"token":"`+token1+`"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the enrolment token to be single-use, got %d", rec.Code)
+	}
+
+	mon := &store.Monitor{Name: "Site", Type: "tcp", Host: "example.com", Port: 443, IntervalSec: 60, TimeoutSec: 2, IsActive: true}
+	mon.Options.Probes = &store.ProbeOptions{ProbeIDs: []int64{id1, id2}}
+	monID, err := ms.CreateMonitor(ctx, mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ProbeMonitors(rec, signed(p1, "GET", monitoring.ProbeEndpointMonitors, nil))
+	var cfg monitoring.ProbeConfig
+	_ = json.Unmarshal(rec.Body.Bytes(), &cfg)
+	if rec.Code != http.StatusOK || len(cfg.Monitors) != 1 || cfg.Monitors[0].Monitor.ID != monID {
+		t.Fatalf("unexpected assignments %d %s", rec.Code, rec.Body.String())
+	}
+	probe, _ := ms.GetProbe(ctx, id1)
+	if probe == nil || probe.LastSeenAt == nil || probe.MonitorID == nil {
+		t.Fatalf("expected the poll to be recorded, got %+v", probe)
+	}
+	if st, _ := ms.GetMonitorState(ctx, *probe.MonitorID); st == nil || st.Status != "up" {
+		t.Fatalf("expected the liveness monitor to be up, got %+v", st)
+	}
+
+	bad := signed(p1, "GET", monitoring.ProbeEndpointMonitors, nil)
+	bad.Header.Set(monitoring.ProbeHeaderSignature, strings.Repeat("0", 64))
+	rec = httptest.NewRecorder()
+	h.ProbeMonitors(rec, bad)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a forged signature to be rejected, got %d", rec.Code)
+	}
+
+	report := func(p monitoring.ProbeEnrolResponse, ok bool) {
+		body, _ := json.Marshal([]monitoring.ProbeReport{
+			{MonitorID: monID, OK: ok, LatencyMs: 12, Error: map[bool]string{false: "monitoring.error.timeout"}[ok], CheckedAt: time.Now().UTC()},
+			{MonitorID: *probe.MonitorID, OK: false},
+		})
+		rec := httptest.NewRecorder()
+		h.ProbeResults(rec, signed(p, "POST", monitoring.ProbeEndpointResults, body))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"accepted":1`) {
+			t.Fatalf("results: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+	report(p1, false)
+	report(p2, true)
+	if err := engine.CheckNow(ctx, monID); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	st, _ := ms.GetMonitorState(ctx, monID)
+	if st == nil || st.Status != "degraded" || st.LastError != "monitoring.error.probePartial: 1/2" {
+		t.Fatalf("expected one failing location to degrade the monitor, got %+v", st)
+	}
+	report(p2, false)
+	if err := engine.CheckNow(ctx, monID); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	st, _ = ms.GetMonitorState(ctx, monID)
+	if st == nil || st.Status != "down" || len(st.Details.Probes) != 2 || st.Details.Probes[0].Name != "Frankfurt" {
+		t.Fatalf("expected the quorum to take the monitor down, got %+v", st)
+	}
+
+	rec = httptest.NewRecorder()
+	h.DeleteProbe(rec, withChiURLParam(httptest.NewRequest("DELETE", "/api/monitoring/probes/1", nil), "id", strconv.FormatInt(id1, 10)))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected a probe in use to be kept, got %d", rec.Code)
+	}
+}
+
+func TestProbeOnlyMonitorDetectsDNSDrift(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	settings, _ := ms.GetSettings(ctx)
+	settings.EngineEnabled = true
+	if err := ms.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	enc, err := utils.NewEncryptorFromString("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("encryptor: %v", err)
+	}
+	engine := monitoring.NewEngineWithDeps(ms, nil, nil, "", enc, nil, utils.NewLogger())
+	h := NewMonitoringHandler(nil, ms, nil, engine, nil, enc)
+
+	rec := httptest.NewRecorder()
+	h.CreateProbe(rec, httptest.NewRequest("POST", "/api/monitoring/probes", strings.NewReader(`{"name":"Frankfurt"}`)))
+	var created struct {
+		ID    int64  `json:"id"`
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	rec = httptest.NewRecorder()
+	h.ProbeEnroll(rec, httptest.NewRequest("POST", "/api/probe/enroll", strings.NewReader(`{"token":"`+created.Token+`"}`)))
+	var p monitoring.ProbeEnrolResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &p)
+	if rec.Code != http.StatusOK || p.Secret == "" {
+		t.Fatalf("enroll: %d %s", rec.Code, rec.Body.String())
+	}
+
+	mon := &store.Monitor{Name: "Zone", Type: "dns", Host: "example.com", Method: "A", IntervalSec: 60, TimeoutSec: 2, IsActive: true}
+	mon.Options.Probes = &store.ProbeOptions{ProbeIDs: []int64{created.ID}}
+	monID, err := ms.CreateMonitor(ctx, mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	report := func(addr string) {
+		details := &store.MonitorDetails{DNS: &store.DNSDetails{Baseline: map[string][]string{"A": {addr}}}}
+		body, _ := json.Marshal([]monitoring.ProbeReport{{MonitorID: monID, OK: true, LatencyMs: 5, CheckedAt: time.Now().UTC(), Details: details}})
+		req := httptest.NewRequest("POST", "/api"+monitoring.ProbeEndpointResults, bytes.NewReader(body))
+		ts := time.Now().Unix()
+		req.Header.Set(monitoring.ProbeHeaderID, strconv.FormatInt(p.ProbeID, 10))
+		req.Header.Set(monitoring.ProbeHeaderTimestamp, strconv.FormatInt(ts, 10))
+		req.Header.Set(monitoring.ProbeHeaderSignature, monitoring.SignProbeRequest(p.Secret, "POST", monitoring.ProbeEndpointResults, ts, body))
+		rec := httptest.NewRecorder()
+		h.ProbeResults(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("results: %d %s", rec.Code, rec.Body.String())
+		}
+		if err := engine.CheckNow(ctx, monID); err != nil {
+			t.Fatalf("check: %v", err)
+		}
+	}
+
+	report("192.0.2.1")
+	st, _ := ms.GetMonitorState(ctx, monID)
+	if st == nil || st.Status != "up" || st.Details == nil || st.Details.DNS == nil || len(st.Details.Probes) != 1 {
+		t.Fatalf("expected the probe details to be kept, got %+v", st)
+	}
+	report("192.0.2.99")
+	st, _ = ms.GetMonitorState(ctx, monID)
+	if st == nil || st.Status != "degraded" || st.LastError != "monitoring.error.dnsDrift" {
+		t.Fatalf("expected DNS drift seen by the probe to degrade the monitor, got %+v", st)
+	}
+	events, _ := ms.ListEvents(ctx, monID, time.Now().Add(-time.Hour))
+	found := false
+	for _, ev := range events {
+		found = found || ev.EventType == "dns_drift"
+	}
+	if !found {
+		t.Fatalf("expected a dns_drift event, got %+v", events)
+	}
+}
+
+func TestProbeEnrolmentTokenRace(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	enc, err := utils.NewEncryptorFromString("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("encryptor: %v", err)
+	}
+	engine := monitoring.NewEngineWithDeps(ms, nil, nil, "", enc, nil, utils.NewLogger())
+	h := NewMonitoringHandler(nil, ms, nil, engine, nil, enc)
+
+	rec := httptest.NewRecorder()
+	h.CreateProbe(rec, httptest.NewRequest("POST", "/api/monitoring/probes", strings.NewReader(`{"name":"Frankfurt"}`)))
+	var created struct {
+		ID    int64  `json:"id"`
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	probe, _ := ms.GetProbe(ctx, created.ID)
+	if probe == nil {
+		t.Fatalf("probe %d not found", created.ID)
+	}
+
+	// The first request read the probe before the second one enrolled it.
+	stale := *probe
+	now := time.Now().UTC()
+	stale.SecretEnc, stale.EnrolledAt = []byte("secret"), &now
+	rec = httptest.NewRecorder()
+	h.ProbeEnroll(rec, httptest.NewRequest("POST", "/api/probe/enroll", strings.NewReader(`{"token":"`+created.Token+`"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enroll: %d %s", rec.Code, rec.Body.String())
+	}
+	ok, err := ms.EnrollProbe(ctx, &stale, monitoring.HashProbeToken(created.Token))
+	if err != nil || ok {
+		t.Fatalf("expected the second enrolment with the same token to lose, got ok=%v err=%v", ok, err)
+	}
+	after, _ := ms.GetProbe(ctx, created.ID)
+	if after == nil || string(after.SecretEnc) == "secret" {
+		t.Fatalf("expected the first secret to be kept")
+	}
+}
+
+func TestProbeHeartbeatKeepsRotatedToken(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	enc, err := utils.NewEncryptorFromString("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("encryptor: %v", err)
+	}
+	engine := monitoring.NewEngineWithDeps(ms, nil, nil, "", enc, nil, utils.NewLogger())
+	h := NewMonitoringHandler(nil, ms, nil, engine, nil, enc)
+
+	now := time.Now().UTC()
+	id, err := ms.CreateProbe(ctx, &store.MonitoringProbe{Name: "Frankfurt", SecretEnc: []byte("old-secret"), EnrolledAt: &now})
+	if err != nil {
+		t.Fatalf("create probe: %v", err)
+	}
+	// The heartbeat loaded the probe before the operator rotated its token.
+	stale, _ := ms.GetProbe(ctx, id)
+	if stale == nil {
+		t.Fatalf("probe %d not found", id)
+	}
+	req := withChiURLParam(httptest.NewRequest("POST", "/api/monitoring/probes/"+strconv.FormatInt(id, 10)+"/token", nil), "id", strconv.FormatInt(id, 10))
+	rec := httptest.NewRecorder()
+	h.RotateProbeToken(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rotate: %d %s", rec.Code, rec.Body.String())
+	}
+	rotated, _ := ms.GetProbe(ctx, id)
+	if err := engine.ProbeHeartbeat(ctx, stale, "198.51.100.7", "1.2.3"); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+	after, _ := ms.GetProbe(ctx, id)
+	if after == nil || len(after.SecretEnc) != 0 || after.EnrolledAt != nil || after.EnrolTokenHash != rotated.EnrolTokenHash || after.EnrolTokenHash == "" {
+		t.Fatalf("expected the rotation to survive the heartbeat, got %+v", after)
+	}
+	if after.LastSeenAt == nil || after.RemoteAddr != "198.51.100.7" || after.Version != "1.2.3" {
+		t.Fatalf("expected the heartbeat fields to be stored, got %+v", after)
+	}
+}
+
+func TestProbeEnrolRecordsClientBehindTrustedProxy(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	enc, err := utils.NewEncryptorFromString("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("encryptor: %v", err)
+	}
+	engine := monitoring.NewEngineWithDeps(ms, nil, nil, "", enc, nil, utils.NewLogger())
+	cfg := &config.AppConfig{}
+	cfg.Security.TrustedProxies = []string{"10.0.0.1"}
+	h := NewMonitoringHandler(cfg, ms, nil, engine, nil, enc)
+
+	rec := httptest.NewRecorder()
+	h.CreateProbe(rec, httptest.NewRequest("POST", "/api/monitoring/probes", strings.NewReader(`{"name":"Frankfurt"}`)))
+	var created struct {
+		ID    int64  `json:"id"`
+		Token string `json:"token"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	req := httptest.NewRequest("POST", "/api/probe/enroll", strings.NewReader(`{"token":"`+created.Token+`"}`))
+	req.RemoteAddr = "10.0.0.1:40000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	rec = httptest.NewRecorder()
+	h.ProbeEnroll(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enroll: %d %s", rec.Code, rec.Body.String())
+	}
+	probe, _ := ms.GetProbe(ctx, created.ID)
+	if probe == nil || probe.RemoteAddr != "203.0.113.9" {
+		t.Fatalf("expected the forwarded client address, got %+v", probe)
+	}
+}
+
+func TestProbeResultsDoNotFeedLiveness(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	enc, err := utils.NewEncryptorFromString("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatalf("encryptor: %v", err)
+	}
+	engine := monitoring.NewEngineWithDeps(ms, nil, nil, "", enc, nil, utils.NewLogger())
+	h := NewMonitoringHandler(nil, ms, nil, engine, nil, enc)
+
+	rec := httptest.NewRecorder()
+	h.CreateProbe(rec, httptest.NewRequest("POST", "/api/monitoring/probes", strings.NewReader(`{"name":"Frankfurt"}`)))
+	var created struct {
+		ID        int64  `json:"id"`
+		MonitorID int64  `json:"monitor_id"`
+		Token     string `json:"token"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	rec = httptest.NewRecorder()
+	h.ProbeEnroll(rec, httptest.NewRequest("POST", "/api/probe/enroll", strings.NewReader(`{"token":"`+created.Token+`"}`)))
+	var enrolled monitoring.ProbeEnrolResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &enrolled)
+	signed := func(method, endpoint string, body []byte) *http.Request {
+		req := httptest.NewRequest(method, "/api"+endpoint, bytes.NewReader(body))
+		ts := time.Now().Unix()
+		req.Header.Set(monitoring.ProbeHeaderID, strconv.FormatInt(enrolled.ProbeID, 10))
+		req.Header.Set(monitoring.ProbeHeaderTimestamp, strconv.FormatInt(ts, 10))
+		req.Header.Set(monitoring.ProbeHeaderSignature, monitoring.SignProbeRequest(enrolled.Secret, method, endpoint, ts, body))
+		return req
+	}
+
+	rec = httptest.NewRecorder()
+	h.ProbeMonitors(rec, signed("GET", monitoring.ProbeEndpointMonitors, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("poll: %d %s", rec.Code, rec.Body.String())
+	}
+	for i := 0; i < 3; i++ {
+		rec = httptest.NewRecorder()
+		h.ProbeResults(rec, signed("POST", monitoring.ProbeEndpointResults, []byte(`[]`+strings.Repeat(" ", i))))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("results: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+	metrics, err := ms.ListMetrics(ctx, created.MonitorID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("expected liveness points for the enrolment and the poll only, got %d", len(metrics))
+	}
+	probe, _ := ms.GetProbe(ctx, created.ID)
+	if probe == nil || probe.LastSeenAt == nil {
+		t.Fatalf("expected result posts to still mark the probe as seen, got %+v", probe)
+	}
+}
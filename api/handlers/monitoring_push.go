@@ -2,21 +2,29 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"berkut-scc/core/monitoring"
 	"berkut-scc/core/store"
 )
 
+const pushPayloadMaxBytes = 64 << 10
+
 type monitorPushPayload struct {
 	OK         *bool  `json:"ok"`
+	Status     string `json:"status"`
 	Error      string `json:"error"`
+	Msg        string `json:"msg"`
 	StatusCode *int   `json:"status_code"`
 	LatencyMS  *int   `json:"latency_ms"`
+	Ping       *int   `json:"ping"`
 }
 
+// PushMonitor records a heartbeat for a push monitor on behalf of a logged-in user.
 func (h *MonitoringHandler) PushMonitor(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(pathParams(r)["id"])
 	if err != nil {
@@ -36,104 +44,173 @@ func (h *MonitoringHandler) PushMonitor(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "monitoring.error.pushOnly", http.StatusBadRequest)
 		return
 	}
-	var payload monitorPushPayload
-	if r.ContentLength > 0 {
-		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-			http.Error(w, errBadRequest, http.StatusBadRequest)
-			return
-		}
+	payload, err := readPushPayload(r)
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
 	}
-	ok := true
-	if payload.OK != nil {
-		ok = *payload.OK
+	if !h.recordPush(w, r, *mon, payload) {
+		return
 	}
-	now := time.Now().UTC()
-	latency := 0
-	if payload.LatencyMS != nil && *payload.LatencyMS > 0 {
-		latency = *payload.LatencyMS
+	h.audit(r, monitorAuditMonitorPush, strconv.FormatInt(mon.ID, 10))
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// PushHeartbeat is the sessionless endpoint used by cron jobs and agents.
+// The monitor is resolved by the hash of the token from the URL.
+func (h *MonitoringHandler) PushHeartbeat(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(pathParams(r)["token"])
+	if token == "" || len(token) > 256 {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return
 	}
-	var errRef *string
-	if payload.Error != "" {
-		msg := payload.Error
-		errRef = &msg
+	mon, err := h.store.GetMonitorByPushTokenHash(r.Context(), monitoring.HashPushToken(token))
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
 	}
-	_, _ = h.store.AddMetric(r.Context(), &store.MonitorMetric{
-		MonitorID:  mon.ID,
-		TS:         now,
-		LatencyMs:  latency,
-		OK:         ok,
-		StatusCode: payload.StatusCode,
-		Error:      errRef,
-	})
-	prev, _ := h.store.GetMonitorState(r.Context(), mon.ID)
-	rawStatus := "down"
-	if ok {
-		rawStatus = "up"
-	}
-	maintenanceActive := false
-	if list, err := h.store.ActiveMaintenanceFor(r.Context(), mon.ID, mon.Tags, now); err == nil && len(list) > 0 {
-		maintenanceActive = true
-	}
-	status := rawStatus
-	if mon.IsPaused {
-		status = "paused"
-	} else if maintenanceActive {
-		status = "maintenance"
-	}
-	lastError := payload.Error
-	if ok {
-		lastError = ""
-	}
-	next := &store.MonitorState{
-		MonitorID:         mon.ID,
-		Status:            status,
-		LastResultStatus:  rawStatus,
-		MaintenanceActive: maintenanceActive,
-		LastCheckedAt:     &now,
-		LastStatusCode:    payload.StatusCode,
-		LastError:         lastError,
-	}
-	if latency > 0 {
-		next.LastLatencyMs = &latency
-	}
-	if rawStatus == "up" {
-		next.LastUpAt = &now
-	} else {
-		next.LastDownAt = &now
+	if mon == nil || !mon.IsActive || !monitoring.TypeIsPassive(mon.Type) {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return
+	}
+	payload, err := readPushPayload(r)
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	if !h.recordPush(w, r, *mon, payload) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// RotatePushToken issues a new push token. The plaintext value is returned once and only its hash is stored.
+func (h *MonitoringHandler) RotatePushToken(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(pathParams(r)["id"])
+	if err != nil {
+		http.Error(w, errBadRequest, http.StatusBadRequest)
+		return
+	}
+	mon, err := h.store.GetMonitor(r.Context(), id)
+	if err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
 	}
-	if prev != nil {
-		if next.LastUpAt == nil {
-			next.LastUpAt = prev.LastUpAt
+	if mon == nil {
+		http.Error(w, errNotFound, http.StatusNotFound)
+		return
+	}
+	if !monitoring.TypeIsPassive(mon.Type) {
+		http.Error(w, "monitoring.error.pushOnly", http.StatusBadRequest)
+		return
+	}
+	token := randomPushToken()
+	mon.RequestBody = token
+	sealPushToken(mon)
+	if err := h.store.UpdateMonitor(r.Context(), mon); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return
+	}
+	h.audit(r, monitorAuditMonitorPushToken, strconv.FormatInt(mon.ID, 10))
+	writeJSON(w, http.StatusOK, map[string]string{"token": token, "push_path": "/api/push/" + token})
+}
+
+func (h *MonitoringHandler) recordPush(w http.ResponseWriter, r *http.Request, mon store.Monitor, payload monitorPushPayload) bool {
+	if h.engine == nil {
+		http.Error(w, errServiceUnavailable, http.StatusServiceUnavailable)
+		return false
+	}
+	if err := h.engine.RecordPush(r.Context(), mon, payload.result()); err != nil {
+		http.Error(w, errServerError, http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// sealPushToken moves a plaintext push token from RequestBody into PushTokenHash
+// and returns the plaintext so it can be shown to the user once.
+func sealPushToken(m *store.Monitor) string {
+	if m == nil {
+		return ""
+	}
+	if !monitoring.TypeIsPassive(m.Type) {
+		m.PushTokenHash = ""
+		return ""
+	}
+	token := strings.TrimSpace(m.RequestBody)
+	m.RequestBody = ""
+	if token == "" {
+		return ""
+	}
+	m.PushTokenHash = monitoring.HashPushToken(token)
+	return token
+}
+
+// readPushPayload accepts a JSON body or Uptime Kuma style query parameters (status, msg, ping).
+func readPushPayload(r *http.Request) (monitorPushPayload, error) {
+	var payload monitorPushPayload
+	if r.Body != nil && r.Method == http.MethodPost {
+		body, err := io.ReadAll(io.LimitReader(r.Body, pushPayloadMaxBytes))
+		if err != nil {
+			return payload, err
 		}
-		if next.LastDownAt == nil {
-			next.LastDownAt = prev.LastDownAt
+		if len(strings.TrimSpace(string(body))) > 0 {
+			if err := json.Unmarshal(body, &payload); err != nil {
+				return payload, err
+			}
 		}
 	}
-	ok24, total24, avg24, _ := h.store.MetricsSummary(r.Context(), mon.ID, now.Add(-24*time.Hour))
-	ok30, total30, _, _ := h.store.MetricsSummary(r.Context(), mon.ID, now.Add(-30*24*time.Hour))
-	if total24 > 0 {
-		next.Uptime24h = (float64(ok24) / float64(total24)) * 100
+	q := r.URL.Query()
+	if payload.OK == nil && payload.Status == "" {
+		payload.Status = strings.TrimSpace(q.Get("status"))
 	}
-	if total30 > 0 {
-		next.Uptime30d = (float64(ok30) / float64(total30)) * 100
+	if payload.Error == "" && payload.Msg == "" {
+		payload.Msg = strings.TrimSpace(q.Get("msg"))
 	}
-	next.AvgLatency24h = avg24
-	if prev == nil || prev.LastResultStatus != rawStatus {
-		msg := payload.Error
-		if msg == "" && payload.StatusCode != nil {
-			msg = "status_" + strconv.Itoa(*payload.StatusCode)
+	if payload.LatencyMS == nil && payload.Ping == nil {
+		if v, err := strconv.Atoi(strings.TrimSpace(q.Get("ping"))); err == nil {
+			payload.Ping = &v
 		}
-		_, _ = h.store.AddEvent(r.Context(), &store.MonitorEvent{
-			MonitorID: mon.ID,
-			TS:        now,
-			EventType: rawStatus,
-			Message:   msg,
-		})
-	}
-	if err := h.store.UpsertMonitorState(r.Context(), next); err != nil {
-		http.Error(w, errServerError, http.StatusInternalServerError)
-		return
 	}
-	h.audit(r, monitorAuditMonitorPush, strconv.FormatInt(mon.ID, 10))
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	if payload.StatusCode == nil {
+		if v, err := strconv.Atoi(strings.TrimSpace(q.Get("code"))); err == nil {
+			payload.StatusCode = &v
+		}
+	}
+	return payload, nil
+}
+
+func (p monitorPushPayload) result() monitoring.CheckResult {
+	ok := true
+	if p.OK != nil {
+		ok = *p.OK
+	} else {
+		switch strings.ToLower(strings.TrimSpace(p.Status)) {
+		case "down", "fail", "failed", "error", "0", "false":
+			ok = false
+		}
+	}
+	latency := 0
+	if p.LatencyMS != nil {
+		latency = *p.LatencyMS
+	} else if p.Ping != nil {
+		latency = *p.Ping
+	}
+	if latency < 0 {
+		latency = 0
+	}
+	msg := strings.TrimSpace(p.Error)
+	if msg == "" {
+		msg = strings.TrimSpace(p.Msg)
+	}
+	if runes := []rune(msg); len(runes) > 500 {
+		msg = string(runes[:500])
+	}
+	return monitoring.CheckResult{
+		OK:         ok,
+		LatencyMs:  latency,
+		StatusCode: p.StatusCode,
+		Error:      msg,
+		CheckedAt:  time.Now().UTC(),
+	}
 }
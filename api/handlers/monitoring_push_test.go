@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestPushHeartbeat_TokenResolvesMonitor(t *testing.T) {
+	ms, cleanup := setupMonitoringHandlerTestDB(t)
+	defer cleanup()
+
+	mon := &store.Monitor{
+		Name:        "Cron",
+		Type:        "push",
+		RequestBody: "secret-token",
+		IntervalSec: 60,
+		TimeoutSec:  2,
+		IsActive:    true,
+		CreatedBy:   1,
+	}
+	sealPushToken(mon)
+	if mon.RequestBody != "" || mon.PushTokenHash == "" {
+		t.Fatalf("expected token to be replaced by hash")
+	}
+	monID, err := ms.CreateMonitor(context.Background(), mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+
+	h := NewMonitoringHandler(nil, ms, nil, monitoring.NewEngine(ms, utils.NewLogger()), nil, nil)
+	req := httptest.NewRequest("GET", "/api/push/secret-token?status=down&msg=backup%20failed&ping=15", nil)
+	req = withChiURLParam(req, "token", "secret-token")
+	rec := httptest.NewRecorder()
+	h.PushHeartbeat(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	st, err := ms.GetMonitorState(context.Background(), monID)
+	if err != nil || st == nil {
+		t.Fatalf("state: %v", err)
+	}
+	if st.LastResultStatus != "down" || st.LastError != "backup failed" {
+		t.Fatalf("unexpected state: %+v", st)
+	}
+
+	req = httptest.NewRequest("POST", "/api/push/secret-token", strings.NewReader(`{"ok":true,"latency_ms":7}`))
+	req = withChiURLParam(req, "token", "secret-token")
+	rec = httptest.NewRecorder()
+	h.PushHeartbeat(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	st, _ = ms.GetMonitorState(context.Background(), monID)
+	if st == nil || st.Status != "up" {
+		t.Fatalf("expected up state, got %+v", st)
+	}
+
+	req = httptest.NewRequest("GET", "/api/push/wrong-token", nil)
+	req = withChiURLParam(req, "token", "wrong-token")
+	rec = httptest.NewRecorder()
+	h.PushHeartbeat(rec, req)
+	if rec.Code != 404 {
+		t.Fatalf("expected 404 for unknown token, got %d", rec.Code)
+	}
+}
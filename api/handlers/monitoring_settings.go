@@ -6,28 +6,34 @@ import (
 	"strconv"
 	"strings"
 
+	"berkut-scc/core/monitoring"
 	"berkut-scc/core/store"
 )
 
+const maxNetworkListSize = 64
+
 type monitoringSettingsPayload struct {
-	RetentionDays           int     `json:"retention_days"`
-	MaxConcurrentChecks     int     `json:"max_concurrent_checks"`
-	DefaultTimeoutSec       int     `json:"default_timeout_sec"`
-	DefaultIntervalSec      int     `json:"default_interval_sec"`
-	DefaultRetries          int     `json:"default_retries"`
-	DefaultRetryIntervalSec int     `json:"default_retry_interval_sec"`
-	DefaultSLATargetPct     float64 `json:"default_sla_target_pct"`
-	EngineEnabled           *bool   `json:"engine_enabled"`
-	AllowPrivateNetworks    *bool   `json:"allow_private_networks"`
-	TLSRefreshHours         int     `json:"tls_refresh_hours"`
-	TLSExpiringDays         int     `json:"tls_expiring_days"`
-	NotifySuppressMinutes   int     `json:"notify_suppress_minutes"`
-	NotifyRepeatDownMinutes int     `json:"notify_repeat_down_minutes"`
-	NotifyMaintenance       *bool   `json:"notify_maintenance"`
-	AutoTaskOnDown          *bool   `json:"auto_task_on_down"`
-	AutoTLSIncident         *bool   `json:"auto_tls_incident"`
-	AutoTLSIncidentDays     int     `json:"auto_tls_incident_days"`
-	AutoIncidentCloseOnUp   *bool   `json:"auto_incident_close_on_up"`
+	RetentionDays           int       `json:"retention_days"`
+	MaxConcurrentChecks     int       `json:"max_concurrent_checks"`
+	DefaultTimeoutSec       int       `json:"default_timeout_sec"`
+	DefaultIntervalSec      int       `json:"default_interval_sec"`
+	DefaultRetries          int       `json:"default_retries"`
+	DefaultRetryIntervalSec int       `json:"default_retry_interval_sec"`
+	DefaultSLATargetPct     float64   `json:"default_sla_target_pct"`
+	RollupRetentionDays     int       `json:"rollup_retention_days"`
+	EngineEnabled           *bool     `json:"engine_enabled"`
+	AllowPrivateNetworks    *bool     `json:"allow_private_networks"`
+	AllowedNetworks         *[]string `json:"allowed_networks"`
+	BlockedNetworks         *[]string `json:"blocked_networks"`
+	TLSRefreshHours         int       `json:"tls_refresh_hours"`
+	TLSExpiringDays         int       `json:"tls_expiring_days"`
+	NotifySuppressMinutes   int       `json:"notify_suppress_minutes"`
+	NotifyRepeatDownMinutes int       `json:"notify_repeat_down_minutes"`
+	NotifyMaintenance       *bool     `json:"notify_maintenance"`
+	AutoTaskOnDown          *bool     `json:"auto_task_on_down"`
+	AutoTLSIncident         *bool     `json:"auto_tls_incident"`
+	AutoTLSIncidentDays     int       `json:"auto_tls_incident_days"`
+	AutoIncidentCloseOnUp   *bool     `json:"auto_incident_close_on_up"`
 }
 
 func (h *MonitoringHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
@@ -73,12 +79,21 @@ func (h *MonitoringHandler) UpdateSettings(w http.ResponseWriter, r *http.Reques
 	if payload.DefaultSLATargetPct > 0 {
 		current.DefaultSLATargetPct = payload.DefaultSLATargetPct
 	}
+	if payload.RollupRetentionDays > 0 {
+		current.RollupRetentionDays = payload.RollupRetentionDays
+	}
 	if payload.EngineEnabled != nil {
 		current.EngineEnabled = *payload.EngineEnabled
 	}
 	if payload.AllowPrivateNetworks != nil {
 		current.AllowPrivateNetworks = *payload.AllowPrivateNetworks
 	}
+	if payload.AllowedNetworks != nil {
+		current.AllowedNetworks = normalizeNetworkList(*payload.AllowedNetworks)
+	}
+	if payload.BlockedNetworks != nil {
+		current.BlockedNetworks = normalizeNetworkList(*payload.BlockedNetworks)
+	}
 	if payload.TLSRefreshHours > 0 {
 		current.TLSRefreshHours = payload.TLSRefreshHours
 	}
@@ -114,6 +129,10 @@ func (h *MonitoringHandler) UpdateSettings(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "monitoring.error.invalidSettings", http.StatusBadRequest)
 		return
 	}
+	if current.RollupRetentionDays < current.RetentionDays || current.RollupRetentionDays < monitoring.MinRollupRetentionDays {
+		http.Error(w, "monitoring.error.invalidSettings", http.StatusBadRequest)
+		return
+	}
 	if current.DefaultSLATargetPct <= 0 || current.DefaultSLATargetPct > 100 {
 		http.Error(w, "monitoring.error.invalidSettings", http.StatusBadRequest)
 		return
@@ -130,6 +149,10 @@ func (h *MonitoringHandler) UpdateSettings(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "monitoring.error.invalidSettings", http.StatusBadRequest)
 		return
 	}
+	if !validNetworkList(current.AllowedNetworks) || !validNetworkList(current.BlockedNetworks) {
+		http.Error(w, "monitoring.error.invalidSettings", http.StatusBadRequest)
+		return
+	}
 	if err := h.store.UpdateSettings(r.Context(), current); err != nil {
 		http.Error(w, errServerError, http.StatusInternalServerError)
 		return
@@ -150,6 +173,7 @@ func settingsDetails(s *store.MonitorSettings) string {
 	}
 	parts := []string{
 		"retention=" + strconv.Itoa(s.RetentionDays),
+		"rollup_retention=" + strconv.Itoa(s.RollupRetentionDays),
 		"max_concurrent=" + strconv.Itoa(s.MaxConcurrentChecks),
 		"default_timeout=" + strconv.Itoa(s.DefaultTimeoutSec),
 		"default_interval=" + strconv.Itoa(s.DefaultIntervalSec),
@@ -158,6 +182,8 @@ func settingsDetails(s *store.MonitorSettings) string {
 		"default_sla=" + strconv.FormatFloat(s.DefaultSLATargetPct, 'f', 1, 64),
 		"engine=" + strconv.FormatBool(s.EngineEnabled),
 		"allow_private=" + strconv.FormatBool(s.AllowPrivateNetworks),
+		"allowed_networks=" + strings.Join(s.AllowedNetworks, ","),
+		"blocked_networks=" + strings.Join(s.BlockedNetworks, ","),
 		"tls_refresh=" + strconv.Itoa(s.TLSRefreshHours),
 		"tls_expiring=" + strconv.Itoa(s.TLSExpiringDays),
 		"notify_suppress=" + strconv.Itoa(s.NotifySuppressMinutes),
@@ -170,3 +196,25 @@ func settingsDetails(s *store.MonitorSettings) string {
 	}
 	return strings.Join(parts, "|")
 }
+
+func normalizeNetworkList(list []string) []string {
+	out := make([]string, 0, len(list))
+	for _, raw := range list {
+		if val := strings.TrimSpace(raw); val != "" {
+			out = append(out, val)
+		}
+	}
+	return out
+}
+
+func validNetworkList(list []string) bool {
+	if len(list) > maxNetworkListSize {
+		return false
+	}
+	for _, raw := range list {
+		if !monitoring.ValidNetwork(raw) {
+			return false
+		}
+	}
+	return true
+}
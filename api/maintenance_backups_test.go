@@ -30,6 +30,9 @@ func (m *maintenanceRepo) DeleteArtifact(ctx context.Context, id int64) error {
 func (m *maintenanceRepo) HasRunningBackupRun(ctx context.Context) (bool, error) {
 	return false, nil
 }
+func (m *maintenanceRepo) RunStats(ctx context.Context) ([]backups.RunStatusStats, error) {
+	return nil, nil
+}
 func (m *maintenanceRepo) HasRunningRestoreRun(ctx context.Context) (bool, error) {
 	return false, nil
 }
@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/appmeta"
+	"berkut-scc/core/backups"
+	"berkut-scc/core/metrics"
+	"berkut-scc/core/store"
+)
+
+var metricsLimiter = newLimiter(60, time.Minute)
+
+var (
+	metricsMonitorStatuses = []string{"up", "down", "degraded", "maintenance", "unreachable", "paused", "unknown"}
+	metricsSLAStatuses     = []string{"ok", "violated", "unknown"}
+	metricsSeverities      = []string{"low", "medium", "high", "critical"}
+	metricsBackupStatuses  = []backups.RunStatus{backups.StatusQueued, backups.StatusRunning, backups.StatusSuccess, backups.StatusFailed, backups.StatusCanceled}
+)
+
+// metricsAuthorized checks the scrape token and the IP allowlist; a configured check has to pass.
+func (s *Server) metricsAuthorized(r *http.Request) bool {
+	cfg := s.cfg.Metrics
+	token := strings.TrimSpace(cfg.Token)
+	allowed := false
+	for _, raw := range cfg.AllowedIPs {
+		if strings.TrimSpace(raw) != "" {
+			allowed = true
+			break
+		}
+	}
+	if token == "" && !allowed {
+		return false
+	}
+	if allowed && !isTrustedProxy(s.clientIP(r), cfg.AllowedIPs) {
+		return false
+	}
+	if token != "" {
+		header := strings.TrimSpace(r.Header.Get("Authorization"))
+		given, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(token)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if !metricsLimiter.allow(strings.ToLower(s.clientIP(r))) {
+		http.Error(w, "too many attempts", http.StatusTooManyRequests)
+		return
+	}
+	if !s.metricsAuthorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	out := metrics.NewWriter(w)
+	out.Family("scc_build_info", metrics.KindGauge, "Build information.")
+	out.Sample("scc_build_info", 1, metrics.L("version", appmeta.AppVersion))
+	s.writeMonitorMetrics(r.Context(), out)
+	s.writeEngineMetrics(out)
+	s.writeIncidentMetrics(r.Context(), out)
+	s.writeBackupMetrics(r.Context(), out)
+	s.httpMetrics.Write(out)
+	if err := out.Flush(); err != nil && s.logger != nil {
+		s.logger.Errorf("metrics write: %v", err)
+	}
+}
+
+func (s *Server) writeMonitorMetrics(ctx context.Context, out *metrics.Writer) {
+	if s.monitoringStore == nil {
+		return
+	}
+	monitors, err := s.monitoringStore.ListMonitors(ctx, store.MonitorFilter{})
+	if err != nil {
+		s.metricsError("monitors", err)
+		return
+	}
+	ids := make([]int64, 0, len(monitors))
+	labels := make(map[int64][]metrics.Label, len(monitors))
+	for _, m := range monitors {
+		ids = append(ids, m.ID)
+		labels[m.ID] = monitorMetricLabels(m.Monitor)
+	}
+	states, err := s.monitoringStore.ListMonitorStates(ctx, ids)
+	if err != nil {
+		s.metricsError("monitor states", err)
+		return
+	}
+	byID := make(map[int64]store.MonitorState, len(states))
+	for _, st := range states {
+		byID[st.MonitorID] = st
+	}
+	with := func(base []metrics.Label, extra ...metrics.Label) []metrics.Label {
+		return append(append(make([]metrics.Label, 0, len(base)+len(extra)), base...), extra...)
+	}
+
+	out.Family("scc_monitor_up", metrics.KindGauge, "Whether the last check of the monitor succeeded.")
+	for _, m := range monitors {
+		if st, ok := byID[m.ID]; ok && st.LastResultStatus != "" {
+			out.Sample("scc_monitor_up", metrics.Bool(st.LastResultStatus == "up"), labels[m.ID]...)
+		}
+	}
+	out.Family("scc_monitor_status", metrics.KindGauge, "Current monitor status, one series per possible status.")
+	for _, m := range monitors {
+		current := "unknown"
+		if st, ok := byID[m.ID]; ok && st.Status != "" {
+			current = st.Status
+		}
+		for _, status := range metricsMonitorStatuses {
+			out.Sample("scc_monitor_status", metrics.Bool(status == current), with(labels[m.ID], metrics.L("status", status))...)
+		}
+	}
+	out.Family("scc_monitor_last_latency_seconds", metrics.KindGauge, "Latency of the last check.")
+	for _, m := range monitors {
+		if st, ok := byID[m.ID]; ok && st.LastLatencyMs != nil {
+			out.Sample("scc_monitor_last_latency_seconds", float64(*st.LastLatencyMs)/1000, labels[m.ID]...)
+		}
+	}
+	out.Family("scc_monitor_uptime_percent", metrics.KindGauge, "Uptime over the 24h and 30d windows.")
+	for _, m := range monitors {
+		if st, ok := byID[m.ID]; ok {
+			out.Sample("scc_monitor_uptime_percent", st.Uptime24h, with(labels[m.ID], metrics.L("window", "24h"))...)
+			out.Sample("scc_monitor_uptime_percent", st.Uptime30d, with(labels[m.ID], metrics.L("window", "30d"))...)
+		}
+	}
+	out.Family("scc_monitor_tls_days_left", metrics.KindGauge, "Days until the monitored TLS certificate expires.")
+	for _, m := range monitors {
+		if st, ok := byID[m.ID]; ok && st.TLSDaysLeft != nil {
+			out.Sample("scc_monitor_tls_days_left", float64(*st.TLSDaysLeft), labels[m.ID]...)
+		}
+	}
+
+	results, err := s.monitoringStore.ListLatestSLAPeriodResults(ctx)
+	if err != nil {
+		s.metricsError("sla results", err)
+		return
+	}
+	out.Family("scc_monitor_sla_status", metrics.KindGauge, "Status of the last closed SLA period, one series per possible status.")
+	for _, item := range results {
+		base, ok := labels[item.MonitorID]
+		if !ok {
+			continue
+		}
+		for _, status := range metricsSLAStatuses {
+			out.Sample("scc_monitor_sla_status", metrics.Bool(status == item.Status),
+				with(base, metrics.L("period", item.PeriodType), metrics.L("status", status))...)
+		}
+	}
+	out.Family("scc_monitor_sla_uptime_percent", metrics.KindGauge, "Uptime of the last closed SLA period.")
+	for _, item := range results {
+		if base, ok := labels[item.MonitorID]; ok {
+			out.Sample("scc_monitor_sla_uptime_percent", item.UptimePct, with(base, metrics.L("period", item.PeriodType))...)
+		}
+	}
+	out.Family("scc_monitor_sla_target_percent", metrics.KindGauge, "SLA target the last closed period was measured against.")
+	for _, item := range results {
+		if base, ok := labels[item.MonitorID]; ok {
+			out.Sample("scc_monitor_sla_target_percent", item.TargetPct, with(base, metrics.L("period", item.PeriodType))...)
+		}
+	}
+}
+
+// monitorMetricLabels identifies a monitor in every series. Tags are joined with surrounding commas,
+// so a single tag can be matched with tags=~".*,prod,.*".
+func monitorMetricLabels(m store.Monitor) []metrics.Label {
+	tags := append([]string(nil), m.Tags...)
+	sort.Strings(tags)
+	joined := ""
+	if len(tags) > 0 {
+		joined = "," + strings.Join(tags, ",") + ","
+	}
+	return []metrics.Label{
+		metrics.L("monitor_id", strconv.FormatInt(m.ID, 10)),
+		metrics.L("name", m.Name),
+		metrics.L("type", m.Type),
+		metrics.L("tags", joined),
+	}
+}
+
+func (s *Server) writeEngineMetrics(out *metrics.Writer) {
+	if s.monitoringEngine == nil {
+		return
+	}
+	stats := s.monitoringEngine.Stats()
+	out.Family("scc_monitoring_engine_running", metrics.KindGauge, "Whether the monitoring scheduler is running.")
+	out.Sample("scc_monitoring_engine_running", metrics.Bool(stats.Running))
+	out.Family("scc_monitoring_queue_depth", metrics.KindGauge, "Due checks the last scheduler tick could not start.")
+	out.Sample("scc_monitoring_queue_depth", float64(stats.QueueDepth))
+	out.Family("scc_monitoring_checks_in_flight", metrics.KindGauge, "Checks currently running.")
+	out.Sample("scc_monitoring_checks_in_flight", float64(stats.InFlight))
+	out.Family("scc_monitoring_semaphore_capacity", metrics.KindGauge, "Maximum number of concurrent checks.")
+	out.Sample("scc_monitoring_semaphore_capacity", float64(stats.SemaphoreCapacity))
+	out.Family("scc_monitoring_semaphore_in_use", metrics.KindGauge, "Concurrency slots currently taken.")
+	out.Sample("scc_monitoring_semaphore_in_use", float64(stats.SemaphoreInUse))
+	out.Family("scc_notification_deliveries_total", metrics.KindCounter, "Notification deliveries since start, by event type and outcome.")
+	for _, item := range stats.Deliveries {
+		out.Sample("scc_notification_deliveries_total", float64(item.Count),
+			metrics.L("event_type", item.EventType), metrics.L("status", item.Status))
+	}
+}
+
+func (s *Server) writeIncidentMetrics(ctx context.Context, out *metrics.Writer) {
+	if s.incidentsStore == nil {
+		return
+	}
+	counts, err := s.incidentsStore.CountOpenIncidentsBySeverity(ctx)
+	if err != nil {
+		s.metricsError("incidents", err)
+		return
+	}
+	out.Family("scc_incidents_open", metrics.KindGauge, "Incidents not closed, by severity.")
+	for _, severity := range metricsSeverities {
+		out.Sample("scc_incidents_open", float64(counts[severity]), metrics.L("severity", severity))
+	}
+}
+
+func (s *Server) writeBackupMetrics(ctx context.Context, out *metrics.Writer) {
+	if s.backupsSvc == nil {
+		return
+	}
+	stats, err := s.backupsSvc.RunStats(ctx)
+	if err != nil {
+		s.metricsError("backups", err)
+		return
+	}
+	counts := map[backups.RunStatus]int{}
+	var lastSuccess, lastFailure float64
+	for _, item := range stats {
+		counts[item.Status] = item.Count
+		if item.LastAt == nil {
+			continue
+		}
+		switch item.Status {
+		case backups.StatusSuccess:
+			lastSuccess = float64(item.LastAt.Unix())
+		case backups.StatusFailed:
+			lastFailure = float64(item.LastAt.Unix())
+		}
+	}
+	out.Family("scc_backup_runs", metrics.KindGauge, "Backup runs on record, by outcome.")
+	for _, status := range metricsBackupStatuses {
+		out.Sample("scc_backup_runs", float64(counts[status]), metrics.L("status", string(status)))
+	}
+	out.Family("scc_backup_last_success_timestamp_seconds", metrics.KindGauge, "Time of the last successful backup run, 0 if none.")
+	out.Sample("scc_backup_last_success_timestamp_seconds", lastSuccess)
+	out.Family("scc_backup_last_failure_timestamp_seconds", metrics.KindGauge, "Time of the last failed backup run, 0 if none.")
+	out.Sample("scc_backup_last_failure_timestamp_seconds", lastFailure)
+}
+
+func (s *Server) metricsError(section string, err error) {
+	if s.logger != nil {
+		s.logger.Errorf("metrics %s: %v", section, err)
+	}
+}
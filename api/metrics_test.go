@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"berkut-scc/config"
+	"berkut-scc/core/metrics"
+	"berkut-scc/core/monitoring"
+	"github.com/go-chi/chi/v5"
+)
+
+func newMetricsTestServer(cfg config.MetricsConfig) *Server {
+	s := &Server{
+		cfg:              &config.AppConfig{Metrics: cfg},
+		router:           chi.NewRouter(),
+		httpMetrics:      metrics.NewHTTPMetrics(),
+		monitoringEngine: monitoring.NewEngine(nil, nil),
+	}
+	s.router.Use(s.loggingMiddleware)
+	s.router.Get("/api/monitoring/monitors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	s.router.Get("/metrics", s.metricsHandler)
+	return s
+}
+
+func scrapeMetrics(s *Server, remoteAddr, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMetricsRequiresScrapeToken(t *testing.T) {
+	s := newMetricsTestServer(config.MetricsConfig{Enabled: true, Token: "scrape-token-0123456789"})
+	if rr := scrapeMetrics(s, "192.0.2.10:5000", ""); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rr.Code)
+	}
+	if rr := scrapeMetrics(s, "192.0.2.10:5000", "scrape-token-wrong"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong token, got %d", rr.Code)
+	}
+	if rr := scrapeMetrics(s, "192.0.2.10:5000", "scrape-token-0123456789"); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for valid token, got %d", rr.Code)
+	}
+}
+
+func TestMetricsAllowlistAndTokenBothApply(t *testing.T) {
+	s := newMetricsTestServer(config.MetricsConfig{
+		Enabled:    true,
+		Token:      "scrape-token-0123456789",
+		AllowedIPs: []string{"10.20.0.0/16"},
+	})
+	if rr := scrapeMetrics(s, "192.0.2.11:5000", "scrape-token-0123456789"); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 outside allowlist, got %d", rr.Code)
+	}
+	if rr := scrapeMetrics(s, "10.20.1.5:5000", ""); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token inside allowlist, got %d", rr.Code)
+	}
+	if rr := scrapeMetrics(s, "10.20.1.5:5000", "scrape-token-0123456789"); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 inside allowlist with token, got %d", rr.Code)
+	}
+	ipOnly := newMetricsTestServer(config.MetricsConfig{Enabled: true, AllowedIPs: []string{"10.20.1.6"}})
+	if rr := scrapeMetrics(ipOnly, "10.20.1.6:5000", ""); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for allowlisted address, got %d", rr.Code)
+	}
+}
+
+func TestMetricsExposesHTTPAndEngineSeries(t *testing.T) {
+	s := newMetricsTestServer(config.MetricsConfig{Enabled: true, AllowedIPs: []string{"127.0.0.1"}})
+	req := httptest.NewRequest(http.MethodGet, "/api/monitoring/monitors/42", nil)
+	s.router.ServeHTTP(httptest.NewRecorder(), req)
+	rr := scrapeMetrics(s, "127.0.0.1:5000", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != metrics.ContentType {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body := rr.Body.String()
+	for _, line := range []string{
+		`scc_http_requests_total{method="GET",route="/api/monitoring/monitors/{id}",code="404"} 1`,
+		`scc_monitoring_engine_running 0`,
+		`# TYPE scc_notification_deliveries_total counter`,
+		`# TYPE scc_http_request_duration_seconds histogram`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("missing %q in output:\n%s", line, body)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"berkut-scc/core/auth"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
+	"github.com/go-chi/chi/v5"
 )
 
 func (s *Server) recoverMiddleware(next http.Handler) http.Handler {
@@ -29,7 +30,7 @@ func (s *Server) recoverMiddleware(next http.Handler) http.Handler {
 		defer func() {
 			if rec := recover(); rec != nil {
 				if s.logger != nil {
-					s.logger.Errorf("PANIC %s %s: %v\n%s", r.Method, r.URL.Path, rec, string(debug.Stack()))
+					s.logger.Errorf("PANIC %s %s: %v\n%s", r.Method, logPath(r), rec, string(debug.Stack()))
 				}
 				http.Error(w, "internal server error", http.StatusInternalServerError)
 			}
@@ -165,7 +166,7 @@ func (s *Server) jsonMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		if s.logger != nil {
-			s.logger.Printf("REQ %s %s", r.Method, r.URL.Path)
+			s.logger.Printf("REQ %s %s", r.Method, logPath(r))
 		}
 		next.ServeHTTP(w, r)
 	})
@@ -207,17 +208,31 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 		start := time.Now()
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(rec, r)
+		route := ""
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+		s.httpMetrics.Observe(r.Method, route, rec.status, time.Since(start))
 		if s.logger != nil {
 			user := "-"
 			if v := r.Context().Value(auth.SessionContextKey); v != nil {
 				sr := v.(*store.SessionRecord)
 				user = sr.Username
 			}
-			s.logger.Printf("RESP %s %s user=%s status=%d dur=%s bytes=%d", r.Method, r.URL.Path, user, rec.status, time.Since(start), rec.size)
+			s.logger.Printf("RESP %s %s user=%s status=%d dur=%s bytes=%d", r.Method, logPath(r), user, rec.status, time.Since(start), rec.size)
 		}
 	})
 }
 
+// logPath returns the request path for the access log. Push URLs carry the monitor
+// token, which is only stored hashed, so they are logged by their route pattern.
+func logPath(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/api/push/") {
+		return "/api/push/{token}"
+	}
+	return r.URL.Path
+}
+
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
@@ -526,6 +541,7 @@ func (s *Server) requirePermissionFromPath(resolver func(string) rbac.Permission
 }
 
 var loginLimiter = newLimiter(5, time.Minute)
+var pushLimiter = newLimiter(120, time.Minute)
 
 func allowedForPasswordChange(path string) bool {
 	if path == "/password-change" {
@@ -572,6 +588,16 @@ func (s *Server) rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+func (s *Server) pushRateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !pushLimiter.allow(strings.ToLower(s.clientIP(r))) {
+			http.Error(w, "too many attempts", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
 func (s *Server) clientIP(r *http.Request) string {
 	ip, _, _ := net.SplitHostPort(r.RemoteAddr)
 	if ip == "" {
@@ -190,3 +190,14 @@ func TestWithSessionKeepsAPIUnauthorizedResponse(t *testing.T) {
 		t.Fatalf("expected unauthorized status for api request, got %d", rr.Code)
 	}
 }
+
+func TestLogPathRedactsPushToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/push/s3cr3t-token", nil)
+	if got := logPath(req); got != "/api/push/{token}" {
+		t.Fatalf("expected push token to be redacted, got %q", got)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/monitoring/monitors/7", nil)
+	if got := logPath(req); got != "/api/monitoring/monitors/7" {
+		t.Fatalf("expected other paths to be kept, got %q", got)
+	}
+}
@@ -16,14 +16,25 @@ func RegisterMonitoring(apiRouter chi.Router, g Guards, monitoring *handlers.Mon
 		monitoringRouter.MethodFunc("POST", "/monitors/{id:[0-9]+}/resume", g.SessionPerm("monitoring.manage", monitoring.ResumeMonitor))
 		monitoringRouter.MethodFunc("POST", "/monitors/{id:[0-9]+}/check-now", g.SessionPerm("monitoring.manage", monitoring.CheckNow))
 		monitoringRouter.MethodFunc("POST", "/monitors/{id:[0-9]+}/push", g.SessionPerm("monitoring.manage", monitoring.PushMonitor))
+		monitoringRouter.MethodFunc("POST", "/monitors/{id:[0-9]+}/push-token", g.SessionPerm("monitoring.manage", monitoring.RotatePushToken))
 		monitoringRouter.MethodFunc("POST", "/monitors/{id:[0-9]+}/clone", g.SessionPerm("monitoring.manage", monitoring.CloneMonitor))
 		monitoringRouter.MethodFunc("PUT", "/monitors/{id:[0-9]+}/sla-policy", g.SessionPerm("monitoring.manage", monitoring.UpdateMonitorSLAPolicy))
 		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/state", g.SessionPerm("monitoring.view", monitoring.GetState))
 		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/metrics", g.SessionPerm("monitoring.view", monitoring.GetMetrics))
+		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/rollups", g.SessionPerm("monitoring.view", monitoring.GetRollups))
 		monitoringRouter.MethodFunc("DELETE", "/monitors/{id:[0-9]+}/metrics", g.SessionPerm("monitoring.manage", monitoring.DeleteMonitorMetrics))
 		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/events", g.SessionPerm("monitoring.events.view", monitoring.GetEvents))
 		monitoringRouter.MethodFunc("DELETE", "/monitors/{id:[0-9]+}/events", g.SessionPerm("monitoring.manage", monitoring.DeleteMonitorEvents))
 		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/tls", g.SessionPerm("monitoring.certs.view", monitoring.GetTLS))
+		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/content", g.SessionPerm("monitoring.view", monitoring.GetContent))
+		monitoringRouter.MethodFunc("POST", "/monitors/{id:[0-9]+}/content/accept", g.SessionPerm("monitoring.manage", monitoring.AcceptContentBaseline))
+		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/probes", g.SessionPerm("monitoring.view", monitoring.GetMonitorProbes))
+		monitoringRouter.MethodFunc("GET", "/monitors/{id:[0-9]+}/dependencies", g.SessionPerm("monitoring.view", monitoring.GetMonitorDependencies))
+		monitoringRouter.MethodFunc("GET", "/dependencies", g.SessionPerm("monitoring.view", monitoring.ListDependencies))
+		monitoringRouter.MethodFunc("GET", "/probes", g.SessionPerm("monitoring.view", monitoring.ListProbes))
+		monitoringRouter.MethodFunc("POST", "/probes", g.SessionPerm("monitoring.manage", monitoring.CreateProbe))
+		monitoringRouter.MethodFunc("DELETE", "/probes/{id:[0-9]+}", g.SessionPerm("monitoring.manage", monitoring.DeleteProbe))
+		monitoringRouter.MethodFunc("POST", "/probes/{id:[0-9]+}/enrolment-token", g.SessionPerm("monitoring.manage", monitoring.RotateProbeToken))
 		monitoringRouter.MethodFunc("GET", "/certs", g.SessionPerm("monitoring.certs.view", monitoring.ListCerts))
 		monitoringRouter.MethodFunc("POST", "/certs/test-notification", g.SessionPerm("monitoring.certs.manage", monitoring.TestCertNotification))
 		monitoringRouter.MethodFunc("GET", "/events", g.SessionPerm("monitoring.events.view", monitoring.EventsFeed))
@@ -14,6 +14,10 @@ func (s *Server) registerRoutes() {
 
 	staticHandler := s.staticHandler()
 	s.router.Handle("/static/*", http.StripPrefix("/static/", staticHandler))
+	// The Prometheus exporter sits outside /api: scrapers authenticate with a token or their address, not a session.
+	if s.cfg.Metrics.Enabled {
+		s.router.Get("/metrics", s.metricsHandler)
+	}
 
 	h := s.newRouteHandlers()
 	appShell := s.withSession(s.requirePermission("app.view")(handlers.ServeStatic("app.html")))
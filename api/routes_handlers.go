@@ -34,6 +34,6 @@ func (s *Server) newRouteHandlers() routeHandlers {
 		incidents:   handlers.NewIncidentsHandler(s.cfg, s.incidentsStore, s.entityLinksStore, s.controlsStore, s.users, s.docsStore, s.policy, s.incidentsSvc, s.docsSvc, s.audits, s.logger),
 		controls:    handlers.NewControlsHandler(s.controlsStore, s.entityLinksStore, s.users, s.docsStore, s.incidentsStore, s.tasksStore, s.audits, s.policy, s.logger),
 		logs:        handlers.NewLogsHandler(s.audits),
-		monitoring:  handlers.NewMonitoringHandler(s.monitoringStore, s.audits, s.monitoringEngine, s.policy, s.incidentsSvc.Encryptor()),
+		monitoring:  handlers.NewMonitoringHandler(s.cfg, s.monitoringStore, s.audits, s.monitoringEngine, s.policy, s.incidentsSvc.Encryptor()),
 	}
 }
@@ -14,6 +14,13 @@ func (s *Server) registerMonitoringRoutes(apiRouter chi.Router, h routeHandlers)
 		WithSession:       s.withSession,
 		RequirePermission: func(p string) func(http.HandlerFunc) http.HandlerFunc { return s.requirePermission(rbac.Permission(p)) },
 	}, h.monitoring)
+	// Push heartbeats are authenticated by the per-monitor token, not by a session.
+	apiRouter.MethodFunc("GET", "/push/{token}", s.pushRateLimitMiddleware(h.monitoring.PushHeartbeat))
+	apiRouter.MethodFunc("POST", "/push/{token}", s.pushRateLimitMiddleware(h.monitoring.PushHeartbeat))
+	// Probe agents enrol with a one-time token and sign every later request with the issued secret.
+	apiRouter.MethodFunc("POST", "/probe/enroll", s.pushRateLimitMiddleware(h.monitoring.ProbeEnroll))
+	apiRouter.MethodFunc("GET", "/probe/monitors", s.pushRateLimitMiddleware(h.monitoring.ProbeMonitors))
+	apiRouter.MethodFunc("POST", "/probe/results", s.pushRateLimitMiddleware(h.monitoring.ProbeResults))
 }
 
 func (s *Server) registerTasksRoutes(apiRouter chi.Router) {
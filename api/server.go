@@ -14,6 +14,7 @@ import (
 	"berkut-scc/core/backups"
 	"berkut-scc/core/docs"
 	"berkut-scc/core/incidents"
+	"berkut-scc/core/metrics"
 	"berkut-scc/core/monitoring"
 	"berkut-scc/core/rbac"
 	"berkut-scc/core/store"
@@ -52,6 +53,7 @@ type Server struct {
 	updateChecker    *appmeta.UpdateChecker
 	monitoringEngine *monitoring.Engine
 	activityTracker  *sessionActivity
+	httpMetrics      *metrics.HTTPMetrics
 }
 
 func NewServer(cfg *config.AppConfig, logger *utils.Logger, deps ServerDeps) *Server {
@@ -84,6 +86,7 @@ func NewServer(cfg *config.AppConfig, logger *utils.Logger, deps ServerDeps) *Se
 		dashboardStore:   deps.DashboardStore,
 		backupsSvc:       deps.BackupsSvc,
 		activityTracker:  newSessionActivity(),
+		httpMetrics:      metrics.NewHTTPMetrics(),
 	}
 	if err := s.bootstrapRoles(context.Background()); err != nil && logger != nil {
 		logger.Errorf("bootstrap roles: %v", err)
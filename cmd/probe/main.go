@@ -0,0 +1,264 @@
+// Command probe is the remote agent of multi-location monitoring: it enrols with an SCC server,
+// runs the checks of the monitors assigned to it and reports the signed results back.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"berkut-scc/core/appmeta"
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/utils"
+)
+
+type probeState struct {
+	Server  string `json:"server"`
+	ProbeID int64  `json:"probe_id"`
+	Secret  string `json:"secret"`
+}
+
+type agent struct {
+	server      string
+	state       probeState
+	client      *http.Client
+	logger      *utils.Logger
+	concurrency int
+	lastRun     map[int64]time.Time
+}
+
+func main() {
+	logger := utils.NewLogger()
+	server := flag.String("server", os.Getenv("SCC_PROBE_SERVER"), "SCC base URL, e.g. https://scc.example.com")
+	token := flag.String("token", os.Getenv("SCC_PROBE_TOKEN"), "one-time enrolment token, needed on the first start only")
+	statePath := flag.String("state", envDefault("SCC_PROBE_STATE", "probe-state.json"), "file keeping the probe id and signing secret")
+	concurrency := flag.Int("concurrency", 4, "checks run in parallel")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a := &agent{
+		server:      strings.TrimRight(strings.TrimSpace(*server), "/"),
+		client:      &http.Client{Timeout: 30 * time.Second},
+		logger:      logger,
+		concurrency: max(*concurrency, 1),
+		lastRun:     map[int64]time.Time{},
+	}
+	state, err := loadState(*statePath)
+	if err != nil {
+		logger.Fatalf("probe state: %v", err)
+	}
+	if state == nil {
+		if a.server == "" || strings.TrimSpace(*token) == "" {
+			logger.Fatalf("probe is not enrolled: -server and -token are required")
+		}
+		state, err = a.enroll(ctx, strings.TrimSpace(*token))
+		if err != nil {
+			logger.Fatalf("probe enrolment: %v", err)
+		}
+		if err := saveState(*statePath, state); err != nil {
+			logger.Fatalf("probe state: %v", err)
+		}
+		logger.Printf("probe enrolled as %d", state.ProbeID)
+	}
+	if a.server == "" {
+		a.server = state.Server
+	}
+	a.state = *state
+	a.run(ctx)
+}
+
+func (a *agent) run(ctx context.Context) {
+	poll := time.Duration(monitoring.DefaultProbePollSec) * time.Second
+	for {
+		cfg, err := a.fetchConfig(ctx)
+		if err != nil {
+			a.logger.Errorf("probe fetch monitors: %v", err)
+		} else {
+			if cfg.PollSec > 0 {
+				poll = time.Duration(cfg.PollSec) * time.Second
+			}
+			if reports := a.runDue(ctx, cfg); len(reports) > 0 {
+				if err := a.sendReports(ctx, reports); err != nil {
+					a.logger.Errorf("probe send results: %v", err)
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(poll):
+		}
+	}
+}
+
+// runDue runs the checks whose interval has elapsed since their last run on this probe.
+func (a *agent) runDue(ctx context.Context, cfg *monitoring.ProbeConfig) []monitoring.ProbeReport {
+	now := time.Now().UTC()
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		reports []monitoring.ProbeReport
+	)
+	sem := make(chan struct{}, a.concurrency)
+	assigned := map[int64]bool{}
+	for _, item := range cfg.Monitors {
+		m := item.Monitor
+		assigned[m.ID] = true
+		interval := time.Duration(max(m.IntervalSec, 10)) * time.Second
+		if last, ok := a.lastRun[m.ID]; ok && now.Sub(last) < interval {
+			continue
+		}
+		a.lastRun[m.ID] = now
+		m.Credentials = item.Credentials
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res := monitoring.CheckMonitor(ctx, m, cfg.Settings)
+			mu.Lock()
+			reports = append(reports, monitoring.NewProbeReport(m.ID, res))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	for id := range a.lastRun {
+		if !assigned[id] {
+			delete(a.lastRun, id)
+		}
+	}
+	return reports
+}
+
+func (a *agent) enroll(ctx context.Context, token string) (*probeState, error) {
+	body, _ := json.Marshal(monitoring.ProbeEnrolRequest{Token: token, Version: appmeta.AppVersion})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.server+"/api"+monitoring.ProbeEndpointEnroll, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp monitoring.ProbeEnrolResponse
+	if err := a.do(req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.ProbeID <= 0 || resp.Secret == "" {
+		return nil, errors.New("empty enrolment response")
+	}
+	return &probeState{Server: a.server, ProbeID: resp.ProbeID, Secret: resp.Secret}, nil
+}
+
+func (a *agent) fetchConfig(ctx context.Context) (*monitoring.ProbeConfig, error) {
+	req, err := a.signedRequest(ctx, http.MethodGet, monitoring.ProbeEndpointMonitors, nil)
+	if err != nil {
+		return nil, err
+	}
+	var cfg monitoring.ProbeConfig
+	if err := a.do(req, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// sendReports posts the results in as few requests as the server payload limit allows.
+func (a *agent) sendReports(ctx context.Context, reports []monitoring.ProbeReport) error {
+	for len(reports) > 0 {
+		n := len(reports)
+		body, err := json.Marshal(reports[:n])
+		for err == nil && len(body) > monitoring.ProbeMaxPayloadBytes && n > 1 {
+			n /= 2
+			body, err = json.Marshal(reports[:n])
+		}
+		if err != nil {
+			return err
+		}
+		req, err := a.signedRequest(ctx, http.MethodPost, monitoring.ProbeEndpointResults, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if err := a.do(req, nil); err != nil {
+			return err
+		}
+		reports = reports[n:]
+	}
+	return nil
+}
+
+func (a *agent) signedRequest(ctx context.Context, method, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, a.server+"/api"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set(monitoring.ProbeHeaderID, strconv.FormatInt(a.state.ProbeID, 10))
+	req.Header.Set(monitoring.ProbeHeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(monitoring.ProbeHeaderSignature, monitoring.SignProbeRequest(a.state.Secret, method, endpoint, ts, body))
+	req.Header.Set(monitoring.ProbeHeaderVersion, appmeta.AppVersion)
+	return req, nil
+}
+
+func (a *agent) do(req *http.Request, out any) error {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(raw)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func loadState(path string) (*probeState, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st probeState
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, err
+	}
+	if st.ProbeID <= 0 || st.Secret == "" {
+		return nil, nil
+	}
+	return &st, nil
+}
+
+func saveState(path string, st *probeState) error {
+	raw, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o600)
+}
+
+func envDefault(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return fallback
+}
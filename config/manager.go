@@ -118,6 +118,7 @@ func normalizeConfig(cfg *AppConfig) {
 	cfg.Docs.OnlyOffice.JWTHeader = strings.TrimSpace(cfg.Docs.OnlyOffice.JWTHeader)
 	cfg.Docs.OnlyOffice.JWTIssuer = strings.TrimSpace(cfg.Docs.OnlyOffice.JWTIssuer)
 	cfg.Docs.OnlyOffice.JWTAudience = strings.TrimSpace(cfg.Docs.OnlyOffice.JWTAudience)
+	cfg.Metrics.Token = strings.TrimSpace(cfg.Metrics.Token)
 	if cfg.Backups.PGDumpBin == "" {
 		cfg.Backups.PGDumpBin = "pg_dump"
 	}
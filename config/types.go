@@ -20,6 +20,7 @@ type AppConfig struct {
 	Security       SecurityConfig  `yaml:"security"`
 	Incidents      IncidentsConfig `yaml:"incidents"`
 	Backups        BackupsConfig   `yaml:"backups"`
+	Metrics        MetricsConfig   `yaml:"metrics"`
 }
 
 func (c *AppConfig) IsHomeMode() bool {
@@ -110,6 +111,14 @@ type BackupsConfig struct {
 	RestoreTestIntervalHours int    `yaml:"restore_test_interval_hours" env:"BERKUT_BACKUP_RESTORE_TEST_INTERVAL_HOURS" env-default:"168"`
 }
 
+// MetricsConfig guards the Prometheus /metrics endpoint. When both a token and an allowlist are set,
+// a scrape has to satisfy both.
+type MetricsConfig struct {
+	Enabled    bool     `yaml:"enabled" env:"BERKUT_METRICS_ENABLED" env-default:"false"`
+	Token      string   `yaml:"token" env:"BERKUT_METRICS_TOKEN"`
+	AllowedIPs []string `yaml:"allowed_ips" env:"BERKUT_METRICS_ALLOWED_IPS" env-separator:","`
+}
+
 const maxUserSessionTTL = 3 * time.Hour
 
 func (c *AppConfig) EffectiveSessionTTL() time.Duration {
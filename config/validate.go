@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"strings"
 )
 
@@ -25,6 +26,9 @@ func Validate(cfg *AppConfig) error {
 	if strings.TrimSpace(cfg.DBURL) == "" {
 		return fmt.Errorf("db_url must be set for postgres driver")
 	}
+	if err := validateMetrics(cfg.Metrics); err != nil {
+		return err
+	}
 	appEnv := strings.ToLower(strings.TrimSpace(cfg.AppEnv))
 	csrk := strings.TrimSpace(cfg.CSRFKey)
 	pep := strings.TrimSpace(cfg.Pepper)
@@ -73,6 +77,35 @@ func Validate(cfg *AppConfig) error {
 	return nil
 }
 
+func validateMetrics(cfg MetricsConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	token := strings.TrimSpace(cfg.Token)
+	allowed := 0
+	for _, raw := range cfg.AllowedIPs {
+		val := strings.TrimSpace(raw)
+		if val == "" {
+			continue
+		}
+		if strings.Contains(val, "/") {
+			if _, _, err := net.ParseCIDR(val); err != nil {
+				return fmt.Errorf("metrics.allowed_ips: invalid cidr %q", val)
+			}
+		} else if net.ParseIP(val) == nil {
+			return fmt.Errorf("metrics.allowed_ips: invalid ip %q", val)
+		}
+		allowed++
+	}
+	if token == "" && allowed == 0 {
+		return fmt.Errorf("metrics.token or metrics.allowed_ips must be set when metrics are enabled")
+	}
+	if token != "" && len(token) < 16 {
+		return fmt.Errorf("metrics.token must be at least 16 characters")
+	}
+	return nil
+}
+
 func isDefaultSecret(val string) bool {
 	switch val {
 	case defaultCSRFKey, defaultPepper, defaultDocsEncryptionKey:
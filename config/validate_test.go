@@ -120,3 +120,38 @@ func TestValidateOnlyOfficeRequiresPublicURLAndSecret(t *testing.T) {
 		t.Fatalf("unexpected error for valid onlyoffice config: %v", err)
 	}
 }
+
+func TestValidateMetricsRequiresTokenOrAllowlist(t *testing.T) {
+	base := func() *AppConfig {
+		return &AppConfig{
+			DBDriver: "postgres",
+			DBURL:    "postgres://localhost/test",
+			AppEnv:   "dev",
+			CSRFKey:  defaultCSRFKey,
+			Pepper:   defaultPepper,
+			Docs: DocsConfig{
+				EncryptionKey: defaultDocsEncryptionKey,
+			},
+			Metrics: MetricsConfig{Enabled: true},
+		}
+	}
+	if err := Validate(base()); err == nil {
+		t.Fatalf("expected error for unprotected metrics endpoint")
+	}
+	cfg := base()
+	cfg.Metrics.Token = "short"
+	if err := Validate(cfg); err == nil {
+		t.Fatalf("expected error for short metrics token")
+	}
+	cfg = base()
+	cfg.Metrics.AllowedIPs = []string{"10.0.0.0/33"}
+	if err := Validate(cfg); err == nil {
+		t.Fatalf("expected error for invalid metrics cidr")
+	}
+	cfg = base()
+	cfg.Metrics.Token = "scrape-token-0123456789"
+	cfg.Metrics.AllowedIPs = []string{"10.0.0.0/8", "127.0.0.1"}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("unexpected error for protected metrics endpoint: %v", err)
+	}
+}
@@ -64,6 +64,7 @@ func composeRuntime(cfg *config.AppConfig, db *sql.DB, logger *utils.Logger) (*r
 		logger,
 	)
 	monitoringEngine.SetTaskStore(tasksStore)
+	monitoringEngine.SetControlsStore(controlsStore)
 
 	return &runtimeComposition{
 		serverDeps: api.ServerDeps{
@@ -11,6 +11,7 @@ type Repository interface {
 	ListSuccessfulArtifacts(ctx context.Context, limit int) ([]BackupArtifact, error)
 	DeleteArtifact(ctx context.Context, id int64) error
 	HasRunningBackupRun(ctx context.Context) (bool, error)
+	RunStats(ctx context.Context) ([]RunStatusStats, error)
 	HasRunningRestoreRun(ctx context.Context) (bool, error)
 	GetRestoreRun(ctx context.Context, id int64) (*RestoreRun, error)
 	CreateRestoreRun(ctx context.Context, run *RestoreRun) (*RestoreRun, error)
@@ -66,6 +66,13 @@ func (s *Service) ListArtifacts(ctx context.Context, filter ListArtifactsFilter)
 	return s.repo.ListArtifacts(ctx, filter)
 }
 
+func (s *Service) RunStats(ctx context.Context) ([]RunStatusStats, error) {
+	if s == nil || s.repo == nil {
+		return nil, nil
+	}
+	return s.repo.RunStats(ctx)
+}
+
 func (s *Service) GetArtifact(ctx context.Context, id int64) (*BackupArtifact, error) {
 	if s == nil || s.repo == nil {
 		return nil, ErrNotFound
@@ -47,6 +47,9 @@ func (r *concurrencyRepo) DeleteArtifact(ctx context.Context, id int64) error {
 }
 func (r *concurrencyRepo) HasRunningBackupRun(ctx context.Context) (bool, error)  { return false, nil }
 func (r *concurrencyRepo) HasRunningRestoreRun(ctx context.Context) (bool, error) { return false, nil }
+func (r *concurrencyRepo) RunStats(ctx context.Context) ([]RunStatusStats, error) {
+	return nil, nil
+}
 func (r *concurrencyRepo) GetRestoreRun(ctx context.Context, id int64) (*RestoreRun, error) {
 	return nil, ErrNotFound
 }
@@ -15,6 +15,28 @@ func (r *Repository) HasRunningBackupRun(ctx context.Context) (bool, error) {
 	return count > 0, nil
 }
 
+func (r *Repository) RunStats(ctx context.Context) ([]backups.RunStatusStats, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(1), MAX(updated_at) FROM backups_runs GROUP BY status ORDER BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []backups.RunStatusStats
+	for rows.Next() {
+		var item backups.RunStatusStats
+		var lastAt sql.NullTime
+		if err := rows.Scan(&item.Status, &item.Count, &lastAt); err != nil {
+			return nil, err
+		}
+		if lastAt.Valid {
+			at := lastAt.Time.UTC()
+			item.LastAt = &at
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
 func (r *Repository) HasRunningRestoreRun(ctx context.Context) (bool, error) {
 	var count int
 	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM backups_restore_runs WHERE status IN ('queued', 'running')`).Scan(&count); err != nil {
@@ -54,6 +54,13 @@ type BackupRun struct {
 	UpdatedAt    time.Time       `json:"updated_at"`
 }
 
+// RunStatusStats aggregates the backup runs that ended up in one status.
+type RunStatusStats struct {
+	Status RunStatus
+	Count  int
+	LastAt *time.Time
+}
+
 type RestoreRun struct {
 	ID           int64           `json:"id"`
 	ArtifactID   int64           `json:"artifact_id"`
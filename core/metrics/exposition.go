@@ -0,0 +1,96 @@
+// Package metrics renders platform and monitoring data in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	KindGauge     = "gauge"
+	KindCounter   = "counter"
+	KindHistogram = "histogram"
+)
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+func L(name, value string) Label {
+	return Label{Name: name, Value: value}
+}
+
+// Writer emits metric families. Callers write a family header once and then all of its samples,
+// as the format requires samples of one family to be grouped.
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) Family(name, kind, help string) {
+	w.w.WriteString("# HELP " + name + " " + escapeHelp(help) + "\n")
+	w.w.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+func (w *Writer) Sample(name string, value float64, labels ...Label) {
+	w.w.WriteString(name)
+	if len(labels) > 0 {
+		w.w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.w.WriteByte(',')
+			}
+			w.w.WriteString(label.Name + `="` + escapeLabel(label.Value) + `"`)
+		}
+		w.w.WriteByte('}')
+	}
+	w.w.WriteByte(' ')
+	w.w.WriteString(formatValue(value))
+	w.w.WriteByte('\n')
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func Bool(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return helpEscaper.Replace(v)
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the request duration buckets in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HTTPMetrics counts served requests by route pattern rather than raw path, so the series stay bounded.
+type HTTPMetrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[requestKey]int64
+	durations map[routeKey]*histogram
+}
+
+type routeKey struct {
+	method string
+	route  string
+}
+
+type requestKey struct {
+	routeKey
+	code int
+}
+
+type histogram struct {
+	counts []int64
+	sum    float64
+	total  int64
+}
+
+func NewHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{
+		buckets:   DefaultBuckets,
+		requests:  map[requestKey]int64{},
+		durations: map[routeKey]*histogram{},
+	}
+}
+
+func (m *HTTPMetrics) Observe(method, route string, code int, dur time.Duration) {
+	if m == nil {
+		return
+	}
+	key := routeKey{method: normalizeMethod(method), route: route}
+	if key.route == "" {
+		key.route = "other"
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{routeKey: key, code: code}]++
+	h := m.durations[key]
+	if h == nil {
+		h = &histogram{counts: make([]int64, len(m.buckets))}
+		m.durations[key] = h
+	}
+	seconds := dur.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.total++
+}
+
+func (m *HTTPMetrics) Write(w *Writer) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	requests := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		requests = append(requests, key)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].routeKey != requests[j].routeKey {
+			return lessRoute(requests[i].routeKey, requests[j].routeKey)
+		}
+		return requests[i].code < requests[j].code
+	})
+	w.Family("scc_http_requests_total", KindCounter, "HTTP requests served, by method, route pattern and status code.")
+	for _, key := range requests {
+		w.Sample("scc_http_requests_total", float64(m.requests[key]),
+			L("method", key.method), L("route", key.route), L("code", strconv.Itoa(key.code)))
+	}
+	routes := make([]routeKey, 0, len(m.durations))
+	for key := range m.durations {
+		routes = append(routes, key)
+	}
+	sort.Slice(routes, func(i, j int) bool { return lessRoute(routes[i], routes[j]) })
+	w.Family("scc_http_request_duration_seconds", KindHistogram, "HTTP request latency, by method and route pattern.")
+	for _, key := range routes {
+		h := m.durations[key]
+		method, route := L("method", key.method), L("route", key.route)
+		for i, bound := range m.buckets {
+			w.Sample("scc_http_request_duration_seconds_bucket", float64(h.counts[i]), method, route, L("le", formatValue(bound)))
+		}
+		w.Sample("scc_http_request_duration_seconds_bucket", float64(h.total), method, route, L("le", "+Inf"))
+		w.Sample("scc_http_request_duration_seconds_sum", h.sum, method, route)
+		w.Sample("scc_http_request_duration_seconds_count", float64(h.total), method, route)
+	}
+}
+
+func lessRoute(a, b routeKey) bool {
+	if a.route != b.route {
+		return a.route < b.route
+	}
+	return a.method < b.method
+}
+
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "OTHER"
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterEscapesLabels(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Family("scc_test", KindGauge, "Test gauge.")
+	w.Sample("scc_test", 1.5, L("name", "a\"b\\c\nd"))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	want := "# HELP scc_test Test gauge.\n# TYPE scc_test gauge\nscc_test{name=\"a\\\"b\\\\c\\nd\"} 1.5\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestHTTPMetricsHistogram(t *testing.T) {
+	m := NewHTTPMetrics()
+	m.Observe("GET", "/api/monitors/{id}", 200, 20*time.Millisecond)
+	m.Observe("GET", "/api/monitors/{id}", 404, 3*time.Second)
+	m.Observe("BREW", "", 400, time.Millisecond)
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	m.Write(w)
+	_ = w.Flush()
+	out := buf.String()
+	for _, line := range []string{
+		`scc_http_requests_total{method="GET",route="/api/monitors/{id}",code="200"} 1`,
+		`scc_http_requests_total{method="GET",route="/api/monitors/{id}",code="404"} 1`,
+		`scc_http_requests_total{method="OTHER",route="other",code="400"} 1`,
+		`scc_http_request_duration_seconds_bucket{method="GET",route="/api/monitors/{id}",le="0.025"} 1`,
+		`scc_http_request_duration_seconds_bucket{method="GET",route="/api/monitors/{id}",le="5"} 2`,
+		`scc_http_request_duration_seconds_bucket{method="GET",route="/api/monitors/{id}",le="+Inf"} 2`,
+		`scc_http_request_duration_seconds_count{method="GET",route="/api/monitors/{id}"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("missing %q in output:\n%s", line, out)
+		}
+	}
+}
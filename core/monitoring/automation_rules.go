@@ -12,15 +12,14 @@ import (
 	"berkut-scc/tasks"
 )
 
-func (e *Engine) handleAutoTaskOnDown(ctx context.Context, m store.Monitor, prev, next *store.MonitorState, now time.Time) {
+func (e *Engine) handleAutoTaskOnDown(ctx context.Context, m store.Monitor, prev, next *store.MonitorState, outageStart bool, now time.Time) {
 	if e.taskStore == nil || !m.AutoTaskOnDown {
 		return
 	}
 	if next == nil || m.IsPaused || next.MaintenanceActive {
 		return
 	}
-	prevStatus := monitorRawStatus(prev)
-	if monitorRawStatus(next) != "down" || prevStatus == "down" {
+	if monitorOutageStatus(next) != "down" || !outageStart {
 		return
 	}
 
@@ -167,6 +166,275 @@ func (e *Engine) handleAutoTLSIncident(ctx context.Context, m store.Monitor, pre
 	}
 }
 
+// securityChange is a change reported by a security-sensitive check: DNS drift, a new SSH host key or new page content.
+type securityChange struct {
+	source       string // incident source, e.g. monitoring_dns
+	action       string // audit action and incident timeline event
+	eventType    string
+	message      string // event message and what happened in the incident
+	timeline     string // incident timeline message
+	audit        string // audit details after the monitor id
+	incident     bool
+	title        string
+	description  string
+	incidentType string
+}
+
+// reportSecurityChange records the change as a monitor event and an audit entry and, when enabled, opens an
+// incident for it or adds it to the timeline of the one still open.
+func (e *Engine) reportSecurityChange(ctx context.Context, m store.Monitor, change securityChange, now time.Time) {
+	now = now.UTC()
+	_, _ = e.store.AddEvent(ctx, &store.MonitorEvent{
+		MonitorID: m.ID,
+		TS:        now,
+		EventType: change.eventType,
+		Message:   change.message,
+	})
+	if e.audits != nil {
+		_ = e.audits.Log(ctx, "system", change.action, fmt.Sprintf("monitor_id=%d|%s", m.ID, change.audit))
+	}
+	if e.incidents == nil || m.IsPaused || !change.incident {
+		return
+	}
+	owner := monitorActorID(m)
+	if existing, _ := e.incidents.FindOpenIncidentBySource(ctx, change.source, m.ID); existing != nil {
+		_, _ = e.incidents.AddIncidentTimeline(ctx, &store.IncidentTimelineEvent{
+			IncidentID: existing.ID,
+			EventType:  change.action,
+			Message:    change.timeline,
+			CreatedBy:  owner,
+			EventAt:    now,
+		})
+		return
+	}
+	incident := &store.Incident{
+		Title:       change.title,
+		Description: change.description,
+		Severity:    "high",
+		Status:      "open",
+		OwnerUserID: owner,
+		CreatedBy:   owner,
+		UpdatedBy:   owner,
+		Source:      change.source,
+		SourceRefID: &m.ID,
+		Meta: store.IncidentMeta{
+			IncidentType:    change.incidentType,
+			DetectionSource: "Мониторинг",
+			DetectedAt:      now.Format(time.RFC3339),
+			AffectedSystems: automationMonitorDisplayName(m),
+			WhatHappened:    change.message,
+		},
+	}
+	id, err := e.incidents.CreateIncident(ctx, incident, nil, nil, e.incidentRegFormat)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring %s incident create: %v", change.eventType, err)
+		}
+		return
+	}
+	_, _ = e.incidents.AddIncidentTimeline(ctx, &store.IncidentTimelineEvent{
+		IncidentID: id,
+		EventType:  change.action,
+		Message:    change.timeline,
+		CreatedBy:  owner,
+		EventAt:    now,
+	})
+	if e.audits != nil {
+		area := strings.TrimPrefix(change.source, "monitoring_")
+		_ = e.audits.Log(ctx, "system", "monitoring."+area+".incident.auto_create", fmt.Sprintf("incident_id=%d|monitor_id=%d", id, m.ID))
+	}
+}
+
+// handleDNSDrift records changed NS/MX/A sets as a security event and optionally opens an incident.
+func (e *Engine) handleDNSDrift(ctx context.Context, m store.Monitor, changes []string, now time.Time) {
+	if len(changes) == 0 {
+		return
+	}
+	msg := strings.Join(changes, "; ")
+	e.reportSecurityChange(ctx, m, securityChange{
+		source:       "monitoring_dns",
+		action:       "monitoring.dns.drift",
+		eventType:    "dns_drift",
+		message:      msg,
+		timeline:     msg,
+		audit:        msg,
+		incident:     dnsOptions(m).DriftIncident,
+		title:        fmt.Sprintf("DNS: изменение записей — %s", strings.TrimSpace(m.Host)),
+		description:  "Изменились NS, MX или A записи домена. Проверьте, не перехвачено ли управление DNS.",
+		incidentType: "Изменение DNS",
+	}, now)
+}
+
+// handleSSHHostKeyChange records a changed SSH host key as a security event and optionally opens an incident.
+func (e *Engine) handleSSHHostKeyChange(ctx context.Context, m store.Monitor, change string, now time.Time) {
+	if change == "" {
+		return
+	}
+	e.reportSecurityChange(ctx, m, securityChange{
+		source:       "monitoring_ssh",
+		action:       "monitoring.ssh.host_key_changed",
+		eventType:    "host_key_changed",
+		message:      change,
+		timeline:     change,
+		audit:        change,
+		incident:     sshOptions(m).HostKeyIncident,
+		title:        fmt.Sprintf("SSH: смена ключа хоста — %s", automationMonitorDisplayName(m)),
+		description:  "Сервер предъявил другой ключ хоста. Проверьте, не было ли переустановки или подмены сервера (MITM).",
+		incidentType: "Смена ключа SSH",
+	}, now)
+}
+
+// handleTLSGradeDrop records a worse tls monitor grade and notifies the monitor's channels.
+func (e *Engine) handleTLSGradeDrop(ctx context.Context, m store.Monitor, change string, result CheckResult) {
+	if change == "" {
+		return
+	}
+	now := result.CheckedAt.UTC()
+	_, _ = e.store.AddEvent(ctx, &store.MonitorEvent{
+		MonitorID: m.ID,
+		TS:        now,
+		EventType: "tls_grade_dropped",
+		Message:   change,
+	})
+	if e.audits != nil {
+		_ = e.audits.Log(ctx, "system", "monitoring.tls.grade_dropped", fmt.Sprintf("monitor_id=%d|%s", m.ID, change))
+	}
+	if e.sender == nil || e.encryptor == nil || m.IsPaused {
+		return
+	}
+	if list, err := e.store.ActiveMaintenanceFor(ctx, m.ID, m.Tags, now); err == nil && len(list) > 0 {
+		return
+	}
+	channels, err := e.resolveNotificationChannels(ctx, m.ID)
+	if err != nil || len(channels) == 0 {
+		return
+	}
+	e.dispatchNotification(ctx, channels, buildNotificationMessage("tls_grade_dropped", "ru", m, result, nil, now, false), "tls_grade_dropped", &m.ID)
+}
+
+// handleContentChange keeps the content baseline of http monitors and reports a new page version
+// with a diff against the accepted baseline; it fires once per version until the baseline is accepted.
+func (e *Engine) handleContentChange(ctx context.Context, m store.Monitor, result CheckResult) {
+	snap := result.Content
+	if snap == nil {
+		return
+	}
+	now := result.CheckedAt.UTC()
+	content, err := e.store.GetContent(ctx, m.ID)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring content %d: %v", m.ID, err)
+		}
+		return
+	}
+	body := compressContent(snap.Text)
+	if content == nil {
+		_ = e.store.CreateContent(ctx, &store.MonitorContent{
+			MonitorID:    m.ID,
+			BaselineHash: snap.Hash,
+			Baseline:     body,
+			BaselineAt:   now,
+			CurrentHash:  snap.Hash,
+			Current:      body,
+			CheckedAt:    now,
+		})
+		return
+	}
+	seen := content.CurrentHash == snap.Hash
+	if err := e.store.RecordContentCheck(ctx, m.ID, snap.Hash, body, now); err != nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring content %d: %v", m.ID, err)
+		}
+		return
+	}
+	if seen || snap.Hash == content.BaselineHash {
+		return
+	}
+	diff, err := ContentDiff(content.Baseline, body)
+	if err != nil {
+		diff = ""
+	}
+	e.reportSecurityChange(ctx, m, securityChange{
+		source:       "monitoring_content",
+		action:       "monitoring.content.changed",
+		eventType:    "content_changed",
+		message:      diff,
+		timeline:     fmt.Sprintf("%s: %s", strings.TrimSpace(m.URL), snap.Hash),
+		audit:        "hash=" + snap.Hash,
+		incident:     contentOptions(m).Incident,
+		title:        fmt.Sprintf("Веб: изменение содержимого — %s", automationMonitorDisplayName(m)),
+		description:  "Содержимое страницы отличается от утверждённого эталона. Проверьте, не был ли сайт дефейснут, и примите новый эталон, если изменение ожидаемое.",
+		incidentType: "Изменение веб-страницы",
+	}, now)
+}
+
+// handleHeadersRegression reports new security headers policy violations and records them
+// against the linked control.
+func (e *Engine) handleHeadersRegression(ctx context.Context, m store.Monitor, change string, result CheckResult) {
+	if change == "" {
+		return
+	}
+	now := result.CheckedAt.UTC()
+	_, _ = e.store.AddEvent(ctx, &store.MonitorEvent{
+		MonitorID: m.ID,
+		TS:        now,
+		EventType: "headers_regressed",
+		Message:   change,
+	})
+	if e.audits != nil {
+		_ = e.audits.Log(ctx, "system", "monitoring.headers.regressed", fmt.Sprintf("monitor_id=%d|%s", m.ID, change))
+	}
+	if m.IsPaused {
+		return
+	}
+	e.createHeadersViolation(ctx, m, change, now)
+	if e.sender == nil || e.encryptor == nil {
+		return
+	}
+	if list, err := e.store.ActiveMaintenanceFor(ctx, m.ID, m.Tags, now); err == nil && len(list) > 0 {
+		return
+	}
+	channels, err := e.resolveNotificationChannels(ctx, m.ID)
+	if err != nil || len(channels) == 0 {
+		return
+	}
+	e.dispatchNotification(ctx, channels, buildNotificationMessage("headers_regressed", "ru", m, result, nil, now, false), "headers_regressed", &m.ID)
+}
+
+func (e *Engine) createHeadersViolation(ctx context.Context, m store.Monitor, change string, now time.Time) {
+	controlID := headersOptions(m).ControlID
+	if e.controls == nil || controlID <= 0 {
+		return
+	}
+	control, err := e.controls.GetControl(ctx, controlID)
+	if err != nil || control == nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring headers control %d for monitor %d not found: %v", controlID, m.ID, err)
+		}
+		return
+	}
+	v := &store.ControlViolation{
+		ControlID:  control.ID,
+		HappenedAt: now,
+		Severity:   "medium",
+		Summary:    fmt.Sprintf("Веб: нарушение политики заголовков безопасности — %s", automationMonitorDisplayName(m)),
+		ImpactMD:   fmt.Sprintf("%s\n\nОценка и новые нарушения: %s", strings.TrimSpace(m.URL), change),
+		CreatedBy:  monitorActorID(m),
+		IsAuto:     true,
+		IsActive:   true,
+	}
+	id, err := e.controls.CreateControlViolation(ctx, v)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring headers control violation create: %v", err)
+		}
+		return
+	}
+	if e.audits != nil {
+		_ = e.audits.Log(ctx, "system", "monitoring.headers.violation.auto_create", fmt.Sprintf("violation_id=%d|control=%s|monitor_id=%d", id, control.Code, m.ID))
+	}
+}
+
 func (e *Engine) pickTaskDestination(ctx context.Context) (int64, int64, error) {
 	boards, err := e.taskStore.ListBoards(ctx, tasks.BoardFilter{})
 	if err != nil || len(boards) == 0 {
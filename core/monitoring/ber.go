@@ -0,0 +1,153 @@
+package monitoring
+
+import (
+	"bufio"
+	"io"
+)
+
+// Minimal BER (X.690) encoding for LDAP: definite lengths, single-byte tags and non-negative integers only.
+
+const (
+	berTagBoolean     = 0x01
+	berTagInteger     = 0x02
+	berTagOctetString = 0x04
+	berTagEnumerated  = 0x0a
+	berTagSequence    = 0x30
+
+	berMaxElement = 1 << 20
+)
+
+type berElement struct {
+	tag     byte
+	content []byte
+}
+
+func berTLV(tag byte, parts ...[]byte) []byte {
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+	out := append([]byte{tag}, berLength(size)...)
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func berLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for v := n; v > 0; v >>= 8 {
+		digits = append([]byte{byte(v)}, digits...)
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
+
+func berInt(tag byte, v int) []byte {
+	out := []byte{byte(v)}
+	for v >>= 8; v > 0; v >>= 8 {
+		out = append([]byte{byte(v)}, out...)
+	}
+	if out[0]&0x80 != 0 {
+		out = append([]byte{0}, out...)
+	}
+	return berTLV(tag, out)
+}
+
+func berString(tag byte, s string) []byte {
+	return berTLV(tag, []byte(s))
+}
+
+func berBool(v bool) []byte {
+	if v {
+		return berTLV(berTagBoolean, []byte{0xff})
+	}
+	return berTLV(berTagBoolean, []byte{0})
+}
+
+// readBER reads one element from the stream.
+func readBER(r *bufio.Reader) (berElement, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return berElement{}, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return berElement{}, err
+	}
+	size := int(first)
+	if first&0x80 != 0 {
+		count := int(first & 0x7f)
+		if count == 0 || count > 4 {
+			return berElement{}, ErrProtocol
+		}
+		size = 0
+		for i := 0; i < count; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return berElement{}, err
+			}
+			size = size<<8 | int(b)
+		}
+	}
+	if size > berMaxElement {
+		return berElement{}, ErrProtocol
+	}
+	content := make([]byte, size)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return berElement{}, err
+	}
+	return berElement{tag: tag, content: content}, nil
+}
+
+// parseBER splits the first element off buf.
+func parseBER(buf []byte) (berElement, []byte, error) {
+	if len(buf) < 2 {
+		return berElement{}, nil, ErrProtocol
+	}
+	tag := buf[0]
+	size := int(buf[1])
+	offset := 2
+	if buf[1]&0x80 != 0 {
+		count := int(buf[1] & 0x7f)
+		if count == 0 || count > 4 || len(buf) < 2+count {
+			return berElement{}, nil, ErrProtocol
+		}
+		size = 0
+		for _, b := range buf[2 : 2+count] {
+			size = size<<8 | int(b)
+		}
+		offset += count
+	}
+	if size < 0 || size > len(buf)-offset {
+		return berElement{}, nil, ErrProtocol
+	}
+	return berElement{tag: tag, content: buf[offset : offset+size]}, buf[offset+size:], nil
+}
+
+// berChildren parses the content of a constructed element.
+func berChildren(content []byte) ([]berElement, error) {
+	var out []berElement
+	for len(content) > 0 {
+		el, rest, err := parseBER(content)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, el)
+		content = rest
+	}
+	return out, nil
+}
+
+func berIntValue(el berElement) (int, error) {
+	if len(el.content) == 0 || len(el.content) > 4 {
+		return 0, ErrProtocol
+	}
+	v := int(int8(el.content[0]))
+	for _, b := range el.content[1:] {
+		v = v<<8 | int(b)
+	}
+	return v, nil
+}
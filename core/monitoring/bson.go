@@ -0,0 +1,274 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Minimal BSON codec for MongoDB wire commands. Only the types exchanged by
+// hello, ping, buildInfo and SASL commands are encoded; decoding skips over
+// any standard type so server replies can be inspected safely.
+
+type bsonElem struct {
+	Key   string
+	Value any
+}
+
+// bsonDoc keeps keys ordered, as MongoDB reads the command name from the first key.
+type bsonDoc []bsonElem
+
+const bsonMaxDepth = 32
+
+var errBSONType = errors.New("unsupported bson type")
+
+func (d bsonDoc) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, 0, 0})
+	for _, el := range d {
+		if err := appendBSONElem(&buf, el.Key, el.Value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte(0)
+	out := buf.Bytes()
+	binary.LittleEndian.PutUint32(out, uint32(len(out)))
+	return out, nil
+}
+
+func appendBSONElem(buf *bytes.Buffer, key string, value any) error {
+	writeName := func(kind byte) {
+		buf.WriteByte(kind)
+		buf.WriteString(key)
+		buf.WriteByte(0)
+	}
+	var scratch [8]byte
+	switch v := value.(type) {
+	case nil:
+		writeName(0x0a)
+	case float64:
+		writeName(0x01)
+		binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(v))
+		buf.Write(scratch[:8])
+	case string:
+		writeName(0x02)
+		binary.LittleEndian.PutUint32(scratch[:], uint32(len(v)+1))
+		buf.Write(scratch[:4])
+		buf.WriteString(v)
+		buf.WriteByte(0)
+	case bsonDoc:
+		writeName(0x03)
+		raw, err := v.marshal()
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+	case []byte:
+		writeName(0x05)
+		binary.LittleEndian.PutUint32(scratch[:], uint32(len(v)))
+		buf.Write(scratch[:4])
+		buf.WriteByte(0)
+		buf.Write(v)
+	case bool:
+		writeName(0x08)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case int32:
+		writeName(0x10)
+		binary.LittleEndian.PutUint32(scratch[:], uint32(v))
+		buf.Write(scratch[:4])
+	case int:
+		return appendBSONElem(buf, key, int64(v))
+	case int64:
+		writeName(0x12)
+		binary.LittleEndian.PutUint64(scratch[:], uint64(v))
+		buf.Write(scratch[:8])
+	default:
+		return errBSONType
+	}
+	return nil
+}
+
+// decodeBSON parses a document into a map. Arrays become []any, binaries []byte,
+// datetimes int64 milliseconds; object ids, timestamps and decimals are returned raw.
+func decodeBSON(raw []byte) (map[string]any, error) {
+	doc, _, err := decodeBSONDoc(raw, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]any, len(doc))
+	for _, el := range doc {
+		out[el.Key] = el.Value
+	}
+	return out, nil
+}
+
+func decodeBSONDoc(raw []byte, depth int) (bsonDoc, int, error) {
+	if depth > bsonMaxDepth || len(raw) < 5 {
+		return nil, 0, ErrProtocol
+	}
+	size := int(binary.LittleEndian.Uint32(raw))
+	if size < 5 || size > len(raw) || raw[size-1] != 0 {
+		return nil, 0, ErrProtocol
+	}
+	body := raw[4 : size-1]
+	var doc bsonDoc
+	for len(body) > 0 {
+		kind := body[0]
+		end := bytes.IndexByte(body[1:], 0)
+		if end < 0 {
+			return nil, 0, ErrProtocol
+		}
+		key := string(body[1 : 1+end])
+		body = body[2+end:]
+		value, n, err := decodeBSONValue(kind, body, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		body = body[n:]
+		doc = append(doc, bsonElem{Key: key, Value: value})
+	}
+	return doc, size, nil
+}
+
+func decodeBSONValue(kind byte, data []byte, depth int) (any, int, error) {
+	need := func(n int) error {
+		if n < 0 || len(data) < n {
+			return ErrProtocol
+		}
+		return nil
+	}
+	switch kind {
+	case 0x01:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), 8, nil
+	case 0x02, 0x0d, 0x0e:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+		size := int(int32(binary.LittleEndian.Uint32(data)))
+		if size < 1 {
+			return nil, 0, ErrProtocol
+		}
+		if err := need(4 + size); err != nil {
+			return nil, 0, err
+		}
+		return string(data[4 : 4+size-1]), 4 + size, nil
+	case 0x03, 0x04:
+		doc, n, err := decodeBSONDoc(data, depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		if kind == 0x04 {
+			items := make([]any, 0, len(doc))
+			for _, el := range doc {
+				items = append(items, el.Value)
+			}
+			return items, n, nil
+		}
+		out := make(map[string]any, len(doc))
+		for _, el := range doc {
+			out[el.Key] = el.Value
+		}
+		return out, n, nil
+	case 0x05:
+		if err := need(5); err != nil {
+			return nil, 0, err
+		}
+		size := int(int32(binary.LittleEndian.Uint32(data)))
+		if err := need(5 + size); err != nil {
+			return nil, 0, err
+		}
+		return append([]byte(nil), data[5:5+size]...), 5 + size, nil
+	case 0x06, 0x0a, 0x7f, 0xff:
+		return nil, 0, nil
+	case 0x07:
+		if err := need(12); err != nil {
+			return nil, 0, err
+		}
+		return append([]byte(nil), data[:12]...), 12, nil
+	case 0x08:
+		if err := need(1); err != nil {
+			return nil, 0, err
+		}
+		return data[0] != 0, 1, nil
+	case 0x09, 0x12:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+		return int64(binary.LittleEndian.Uint64(data)), 8, nil
+	case 0x0b:
+		first := bytes.IndexByte(data, 0)
+		if first < 0 {
+			return nil, 0, ErrProtocol
+		}
+		second := bytes.IndexByte(data[first+1:], 0)
+		if second < 0 {
+			return nil, 0, ErrProtocol
+		}
+		return string(data[:first]), first + second + 2, nil
+	case 0x0c:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+		size := int(int32(binary.LittleEndian.Uint32(data)))
+		if err := need(4 + size + 12); err != nil || size < 1 {
+			return nil, 0, ErrProtocol
+		}
+		return string(data[4 : 4+size-1]), 4 + size + 12, nil
+	case 0x0f:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+		size := int(int32(binary.LittleEndian.Uint32(data)))
+		if size < 4 {
+			return nil, 0, ErrProtocol
+		}
+		if err := need(size); err != nil {
+			return nil, 0, err
+		}
+		return nil, size, nil
+	case 0x10:
+		if err := need(4); err != nil {
+			return nil, 0, err
+		}
+		return int32(binary.LittleEndian.Uint32(data)), 4, nil
+	case 0x11:
+		if err := need(8); err != nil {
+			return nil, 0, err
+		}
+		return binary.LittleEndian.Uint64(data), 8, nil
+	case 0x13:
+		if err := need(16); err != nil {
+			return nil, 0, err
+		}
+		return append([]byte(nil), data[:16]...), 16, nil
+	default:
+		return nil, 0, ErrProtocol
+	}
+}
+
+// bsonNumber converts any numeric BSON value to float64.
+func bsonNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func bsonBool(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
@@ -25,6 +25,9 @@ import (
 var (
 	ErrInvalidURL     = errors.New("invalid url")
 	ErrPrivateBlocked = errors.New("private network blocked")
+	ErrProtocol       = errors.New("unexpected protocol response")
+	// ErrInvalidTLSMaterial reports a CA bundle or client key pair that cannot be parsed.
+	ErrInvalidTLSMaterial = errors.New("invalid tls material")
 )
 
 type CheckResult struct {
@@ -34,6 +37,18 @@ type CheckResult struct {
 	Error      string
 	CheckedAt  time.Time
 	TLS        *TLSInfo
+	Ping       *PingStats
+	// Degraded marks a successful check that crossed a soft threshold.
+	Degraded bool
+	// ServerVersion is reported by protocol checks that learn it from the handshake.
+	ServerVersion string
+	// ServerRole is the replication role reported by the node (e.g. primary, replica).
+	ServerRole string
+	Details    *store.MonitorDetails
+	// Values are numbers extracted from the response, stored with the metric for charting.
+	Values map[string]float64
+	// Content is the normalised body of http monitors with content watching enabled.
+	Content *ContentSnapshot
 }
 
 type TLSInfo struct {
@@ -43,6 +58,9 @@ type TLSInfo struct {
 	Issuer            string
 	SANs              []string
 	FingerprintSHA256 string
+	// Grade and Security are set by tls monitors.
+	Grade    string
+	Security *store.TLSSecurity
 }
 
 func CheckMonitor(ctx context.Context, m store.Monitor, settings store.MonitorSettings) CheckResult {
@@ -92,42 +110,57 @@ func runSingleCheck(ctx context.Context, m store.Monitor, settings store.Monitor
 		res, err = checkHTTP(ctx, m, settings, timeout, TypeHTTPKeyword)
 	case TypeHTTPJSON:
 		res, err = checkHTTP(ctx, m, settings, timeout, TypeHTTPJSON)
+	case TypeHTTPTransaction:
+		res, err = checkTransaction(ctx, m, settings, timeout)
 	case TypeTCP:
 		res, err = checkTCP(ctx, m, settings, timeout)
 	case TypeDNS:
-		res, err = checkDNS(ctx, m, settings)
+		res, err = checkDNS(ctx, m, settings, timeout)
 	case TypeRedis:
 		res, err = checkRedis(ctx, m, settings, timeout)
 	case TypePostgres:
 		res, err = checkPostgres(ctx, m, settings, timeout)
-	case TypePing, TypeTailscalePing:
+	case TypePing:
+		res, err = checkPing(ctx, m, settings, timeout)
+	case TypeTailscalePing:
 		res, err = checkPingLike(ctx, m, settings, timeout)
 	case TypeGRPCKeyword:
 		res, err = checkGRPCKeyword(ctx, m, settings, timeout)
 	case TypeDocker:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeDocker))
-	case TypeSteam:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeSteam))
-	case TypeGameDig:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeGameDig))
+		res, err = checkDocker(ctx, m, settings, timeout)
+	case TypeSteam, TypeGameDig:
+		res, err = checkGame(ctx, m, settings, timeout)
 	case TypeMQTT:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeMQTT))
+		res, err = checkMQTT(ctx, m, settings, timeout)
 	case TypeKafkaProducer:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeKafkaProducer))
+		res, err = checkKafka(ctx, m, settings, timeout)
 	case TypeMSSQL:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeMSSQL))
+		res, err = checkMSSQL(ctx, m, settings, timeout)
 	case TypeMySQL:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeMySQL))
+		res, err = checkMySQL(ctx, m, settings, timeout)
 	case TypeMongoDB:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeMongoDB))
+		res, err = checkMongoDB(ctx, m, settings, timeout)
 	case TypeRadius:
-		res, err = checkHostPort(ctx, m, settings, timeout, DefaultPortForType(TypeRadius))
+		res, err = checkRadius(ctx, m, settings, timeout)
+	case TypeSMTP, TypeIMAP, TypePOP3:
+		res, err = checkMail(ctx, m, settings, timeout)
+	case TypeSSH:
+		res, err = checkSSH(ctx, m, settings, timeout)
+	case TypeLDAP:
+		res, err = checkLDAP(ctx, m, settings, timeout)
+	case TypeTLS:
+		res, err = checkTLS(ctx, m, settings, timeout)
+	case TypePrometheus:
+		res, err = checkPrometheus(ctx, m, settings, timeout)
 	case TypePush:
 		res, err = CheckResult{OK: true}, nil
 	default:
 		return failedResult(res, errors.New("unsupported monitor type")), errors.New("unsupported monitor type")
 	}
-	res.LatencyMs = int(time.Since(start).Milliseconds())
+	// Checkers that measure protocol-level latency (e.g. ICMP RTT) report it themselves.
+	if res.LatencyMs <= 0 {
+		res.LatencyMs = int(time.Since(start).Milliseconds())
+	}
 	res.CheckedAt = time.Now().UTC()
 	if err != nil {
 		return res, err
@@ -140,7 +173,7 @@ func checkHTTP(ctx context.Context, m store.Monitor, settings store.MonitorSetti
 	if err != nil {
 		return CheckResult{}, ErrInvalidURL
 	}
-	if err := guardTarget(ctx, parsed.Hostname(), settings.AllowPrivateNetworks); err != nil {
+	if err := guardTarget(ctx, parsed.Hostname(), settings); err != nil {
 		return CheckResult{}, err
 	}
 	method := strings.ToUpper(strings.TrimSpace(m.Method))
@@ -170,25 +203,31 @@ func checkHTTP(ctx context.Context, m store.Monitor, settings store.MonitorSetti
 			req.Header.Set("Content-Type", "application/xml")
 		}
 	}
-	client := &http.Client{
-		Timeout: timeout,
+	client, err := httpMonitorClient(m, settings, timeout)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer client.CloseIdleConnections()
+	if key, err := applyHTTPAuth(ctx, req, m, settings, client); err != nil {
+		return CheckResult{}, err
+	} else if key != "" {
+		return CheckResult{OK: false, Error: key}, nil
 	}
 	if expectsRedirectStatus(m.AllowedStatus) {
 		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		}
 	}
-	if strings.EqualFold(parsed.Scheme, "https") && m.IgnoreTLSErrors {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return CheckResult{}, err
 	}
 	defer resp.Body.Close()
 	code := resp.StatusCode
+	if code == http.StatusUnauthorized {
+		// A revoked or rotated token must not be reused until it expires.
+		forgetOAuth2Token(m)
+	}
 	res := CheckResult{
 		StatusCode: &code,
 	}
@@ -203,11 +242,22 @@ func checkHTTP(ctx context.Context, m store.Monitor, settings store.MonitorSetti
 		res.Error = fmt.Sprintf("status_%d", code)
 		return res, nil
 	}
-	if mode == TypeHTTPJSON || mode == TypeHTTPKeyword {
+	if headers := headersOptions(m); headers.Enabled {
+		res.Details = &store.MonitorDetails{Headers: evaluateSecurityHeaders(resp, strings.EqualFold(resp.Request.URL.Scheme, "https"), headers)}
+		if headers.MinScore > 0 && res.Details.Headers.Score < headers.MinScore {
+			res.OK = false
+			res.Error = fmt.Sprintf("monitoring.error.headersScoreBelow: %d", res.Details.Headers.Score)
+		}
+	}
+	content := contentOptions(m)
+	if mode == TypeHTTPJSON || mode == TypeHTTPKeyword || content.Enabled {
 		payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
 		if err != nil {
 			return CheckResult{}, err
 		}
+		if content.Enabled {
+			res.Content = contentSnapshot(payload, content)
+		}
 		if mode == TypeHTTPJSON {
 			var parsed any
 			if err := json.Unmarshal(payload, &parsed); err != nil {
@@ -215,6 +265,15 @@ func checkHTTP(ctx context.Context, m store.Monitor, settings store.MonitorSetti
 				res.Error = "monitoring.error.invalidJsonResponse"
 				return res, nil
 			}
+			if opts := m.Options.JSON; opts != nil && len(opts.Assertions) > 0 {
+				values, failures := evaluateJSONAssertions(parsed, opts.Assertions)
+				res.Values = values
+				if len(failures) > 0 {
+					res.OK = false
+					res.Error = jsonAssertionError(failures)
+					return res, nil
+				}
+			}
 		}
 		if mode == TypeHTTPKeyword {
 			needle := strings.TrimSpace(m.RequestBody)
@@ -238,15 +297,14 @@ func checkTCP(ctx context.Context, m store.Monitor, settings store.MonitorSettin
 	if host == "" {
 		return CheckResult{}, errors.New("empty host")
 	}
-	if err := guardTarget(ctx, host, settings.AllowPrivateNetworks); err != nil {
+	if err := guardTarget(ctx, host, settings); err != nil {
 		return CheckResult{}, err
 	}
 	if m.Port <= 0 {
 		return CheckResult{}, errors.New("invalid port")
 	}
 	addr := net.JoinHostPort(host, strconv.Itoa(m.Port))
-	dialer := &net.Dialer{Timeout: timeout}
-	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	conn, err := guardedDialer(settings, timeout).DialContext(ctx, "tcp", addr)
 	if err != nil {
 		return CheckResult{}, err
 	}
@@ -254,82 +312,22 @@ func checkTCP(ctx context.Context, m store.Monitor, settings store.MonitorSettin
 	return CheckResult{OK: true}, nil
 }
 
-func checkDNS(ctx context.Context, m store.Monitor, settings store.MonitorSettings) (CheckResult, error) {
-	host := strings.TrimSpace(m.Host)
-	if host == "" {
-		return CheckResult{}, errors.New("empty host")
-	}
-	if err := guardTarget(ctx, host, settings.AllowPrivateNetworks); err != nil {
-		return CheckResult{}, err
-	}
-	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
-	if err != nil {
-		return CheckResult{}, err
-	}
-	res := CheckResult{OK: true}
-	expect := strings.TrimSpace(m.RequestBody)
-	if expect != "" {
-		found := false
-		for _, addr := range addrs {
-			if strings.Contains(addr, expect) {
-				found = true
-				break
-			}
-		}
-		if !found {
-			res.OK = false
-			res.Error = "monitoring.error.dnsNoAnswer"
-		}
-	}
-	return res, nil
-}
-
-func checkRedis(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
-	host := strings.TrimSpace(m.Host)
-	if host == "" {
-		return CheckResult{}, errors.New("empty host")
-	}
-	if err := guardTarget(ctx, host, settings.AllowPrivateNetworks); err != nil {
-		return CheckResult{}, err
-	}
-	port := m.Port
-	if port <= 0 {
-		port = 6379
-	}
-	addr := net.JoinHostPort(host, strconv.Itoa(port))
-	dialer := &net.Dialer{Timeout: timeout}
-	conn, err := dialer.DialContext(ctx, "tcp", addr)
-	if err != nil {
-		return CheckResult{}, err
-	}
-	defer conn.Close()
-	_ = conn.SetDeadline(time.Now().Add(timeout))
-	if _, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
-		return CheckResult{}, err
-	}
-	buf := make([]byte, 256)
-	n, err := conn.Read(buf)
-	if err != nil {
-		return CheckResult{}, err
-	}
-	res := strings.ToUpper(strings.TrimSpace(string(buf[:n])))
-	if !strings.Contains(res, "PONG") {
-		return CheckResult{OK: false, Error: "monitoring.error.requestFailed"}, nil
-	}
-	return CheckResult{OK: true}, nil
-}
-
 func checkPostgres(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
 	parsed, err := url.Parse(strings.TrimSpace(m.URL))
 	if err != nil || parsed.Hostname() == "" {
 		return CheckResult{}, ErrInvalidURL
 	}
-	if err := guardTarget(ctx, parsed.Hostname(), settings.AllowPrivateNetworks); err != nil {
+	if err := guardTarget(ctx, parsed.Hostname(), settings); err != nil {
 		return CheckResult{}, err
 	}
 	checkCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	conn, err := pgx.Connect(checkCtx, strings.TrimSpace(m.URL))
+	cfg, err := pgx.ParseConfig(strings.TrimSpace(m.URL))
+	if err != nil {
+		return CheckResult{}, ErrInvalidURL
+	}
+	cfg.DialFunc = guardedDialer(settings, timeout).DialContext
+	conn, err := pgx.ConnectConfig(checkCtx, cfg)
 	if err != nil {
 		return CheckResult{}, err
 	}
@@ -356,68 +354,6 @@ func parseMonitorURL(raw string) (*url.URL, error) {
 	return u, nil
 }
 
-func guardTarget(ctx context.Context, host string, allowPrivate bool) error {
-	if allowPrivate {
-		return nil
-	}
-	host = strings.TrimSpace(host)
-	if host == "" {
-		return ErrPrivateBlocked
-	}
-	lower := strings.ToLower(host)
-	if lower == "localhost" || strings.HasSuffix(lower, ".localhost") {
-		return ErrPrivateBlocked
-	}
-	if ip := net.ParseIP(host); ip != nil {
-		if isPrivateIP(ip) {
-			return ErrPrivateBlocked
-		}
-		return nil
-	}
-	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
-	if err != nil {
-		return err
-	}
-	for _, addr := range addrs {
-		if isPrivateIP(addr.IP) {
-			return ErrPrivateBlocked
-		}
-	}
-	return nil
-}
-
-func isPrivateIP(ip net.IP) bool {
-	if ip == nil {
-		return false
-	}
-	if ip.IsLoopback() || ip.IsLinkLocalMulticast() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
-		return true
-	}
-	ip4 := ip.To4()
-	if ip4 != nil {
-		switch {
-		case ip4[0] == 10:
-			return true
-		case ip4[0] == 172 && ip4[1]&0xf0 == 16:
-			return true
-		case ip4[0] == 192 && ip4[1] == 168:
-			return true
-		case ip4[0] == 127:
-			return true
-		case ip4[0] == 169 && ip4[1] == 254:
-			return true
-		}
-		return false
-	}
-	if ip.IsPrivate() {
-		return true
-	}
-	if ip.IsUnspecified() {
-		return true
-	}
-	return false
-}
-
 func statusAllowed(code int, allowed []string) bool {
 	ranges := parseStatusRanges(allowed)
 	if len(ranges) == 0 {
@@ -500,6 +436,15 @@ func checkErrorKey(err error) string {
 	if errors.Is(err, ErrPrivateBlocked) {
 		return "monitoring.error.privateBlocked"
 	}
+	if errors.Is(err, ErrICMPUnavailable) {
+		return "monitoring.error.icmpUnavailable"
+	}
+	if errors.Is(err, ErrProtocol) {
+		return "monitoring.error.protocolError"
+	}
+	if errors.Is(err, ErrInvalidTLSMaterial) {
+		return "monitoring.error.invalidTLSMaterial"
+	}
 	var unknownAuthority x509.UnknownAuthorityError
 	if errors.As(err, &unknownAuthority) {
 		return "monitoring.error.tlsHandshakeFailed"
@@ -519,6 +464,9 @@ func checkErrorKey(err error) string {
 	if errors.As(err, &netErr) && netErr.Timeout() {
 		return "monitoring.error.timeout"
 	}
+	if errors.Is(err, errTLSRefused) {
+		return "monitoring.error.tlsHandshakeFailed"
+	}
 	return "monitoring.error.requestFailed"
 }
 
@@ -0,0 +1,289 @@
+package monitoring
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	maxContentPatterns = 20
+	maxContentPattern  = 512
+	// contentDiffContext is the number of unchanged lines around each hunk.
+	contentDiffContext = 3
+	// maxContentDiffCells bounds the LCS table; larger changes are shown as a full replacement.
+	maxContentDiffCells = 1 << 20
+	// MaxContentDiff bounds the diff stored in monitoring events.
+	MaxContentDiff = 8 << 10
+)
+
+// ContentSnapshot is the normalised body of a content-watching http monitor.
+type ContentSnapshot struct {
+	Hash string
+	Text string
+}
+
+func contentOptions(m store.Monitor) store.ContentOptions {
+	if m.Options.Content == nil {
+		return store.ContentOptions{}
+	}
+	return *m.Options.Content
+}
+
+// ValidContentOptions checks the ignore patterns and regions of a content-watching monitor.
+func ValidContentOptions(opts store.ContentOptions) bool {
+	if len(opts.IgnorePatterns) > maxContentPatterns || len(opts.IgnoreRegions) > maxContentPatterns {
+		return false
+	}
+	for _, pattern := range opts.IgnorePatterns {
+		if pattern == "" || len(pattern) > maxContentPattern {
+			return false
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return false
+		}
+	}
+	for _, region := range opts.IgnoreRegions {
+		if region.Start == "" || region.End == "" || len(region.Start) > maxContentPattern || len(region.End) > maxContentPattern {
+			return false
+		}
+	}
+	return true
+}
+
+func contentSnapshot(body []byte, opts store.ContentOptions) *ContentSnapshot {
+	text := normalizeContent(body, opts)
+	sum := sha256.Sum256([]byte(text))
+	return &ContentSnapshot{Hash: hex.EncodeToString(sum[:]), Text: text}
+}
+
+// normalizeContent strips the dynamic parts of the body and puts every tag on its own line,
+// so that the hash is stable and the diff readable for minified pages.
+func normalizeContent(body []byte, opts store.ContentOptions) string {
+	text := strings.ToValidUTF8(string(body), "")
+	for _, region := range opts.IgnoreRegions {
+		text = stripContentRegion(text, region.Start, region.End)
+	}
+	for _, pattern := range opts.IgnorePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		text = re.ReplaceAllString(text, "")
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "><", ">\n<")
+	lines := strings.Split(text, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func stripContentRegion(text, start, end string) string {
+	var sb strings.Builder
+	for {
+		i := strings.Index(text, start)
+		if i < 0 {
+			break
+		}
+		j := strings.Index(text[i+len(start):], end)
+		if j < 0 {
+			// An unterminated region is kept, so a broken page still shows up as a change.
+			break
+		}
+		sb.WriteString(text[:i])
+		text = text[i+len(start)+j+len(end):]
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+func compressContent(text string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(text))
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func decompressContent(raw []byte) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, 4<<20))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// ContentDiff returns the unified diff between two compressed bodies, cut to MaxContentDiff.
+func ContentDiff(baseline, current []byte) (string, error) {
+	before, err := decompressContent(baseline)
+	if err != nil {
+		return "", err
+	}
+	after, err := decompressContent(current)
+	if err != nil {
+		return "", err
+	}
+	diff := unifiedDiff(splitContentLines(before), splitContentLines(after))
+	if len(diff) > MaxContentDiff {
+		cut := strings.LastIndexByte(diff[:MaxContentDiff], '\n') + 1
+		diff = diff[:cut] + "...\n"
+	}
+	return diff, nil
+}
+
+func splitContentLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff formats the line differences between a and b like diff -u.
+func unifiedDiff(a, b []string) string {
+	lines := diffLines(a, b)
+	var changes []int
+	for i, line := range lines {
+		if line.op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("--- baseline\n+++ current\n")
+	for k := 0; k < len(changes); {
+		start := max(changes[k]-contentDiffContext, 0)
+		last := changes[k]
+		for k < len(changes) && changes[k]-last <= 2*contentDiffContext {
+			last = changes[k]
+			k++
+		}
+		end := min(last+contentDiffContext+1, len(lines))
+		aStart, bStart := 1, 1
+		for _, line := range lines[:start] {
+			if line.op != '+' {
+				aStart++
+			}
+			if line.op != '-' {
+				bStart++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, line := range lines[start:end] {
+			if line.op != '+' {
+				aCount++
+			}
+			if line.op != '-' {
+				bCount++
+			}
+		}
+		// An empty range starts at the line before it.
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, line := range lines[start:end] {
+			sb.WriteByte(line.op)
+			sb.WriteString(line.text)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	out := make([]diffLine, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		out = append(out, diffLine{' ', line})
+	}
+	out = append(out, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		out = append(out, diffLine{' ', line})
+	}
+	return out
+}
+
+// diffMiddle aligns a and b on their longest common subsequence.
+func diffMiddle(a, b []string) []diffLine {
+	var out []diffLine
+	if len(a)*len(b) > maxContentDiffCells {
+		for _, line := range a {
+			out = append(out, diffLine{'-', line})
+		}
+		for _, line := range b {
+			out = append(out, diffLine{'+', line})
+		}
+		return out
+	}
+	n, m := len(a), len(b)
+	// lcs[i*(m+1)+j] is the LCS length of a[i:] and b[j:].
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			out = append(out, diffLine{'-', a[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, diffLine{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, diffLine{'+', b[j]})
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestNormalizeContent(t *testing.T) {
+	opts := store.ContentOptions{
+		IgnorePatterns: []string{`csrf=[0-9a-f]+`},
+		IgnoreRegions:  []store.ContentRegion{{Start: "<!--ts-->", End: "<!--/ts-->"}},
+	}
+	body := "<html><body>\r\n  <p>Hello</p><!--ts-->12:00:01<!--/ts-->\n\n<a href=\"/x?csrf=ab12\">x</a></body></html>"
+	want := "<html>\n<body>\n<p>Hello</p>\n<a href=\"/x?\">x</a>\n</body>\n</html>"
+	if got := normalizeContent([]byte(body), opts); got != want {
+		t.Fatalf("unexpected normalised body:\n%s", got)
+	}
+	other := strings.Replace(strings.Replace(body, "12:00:01", "13:14:15", 1), "ab12", "ff00", 1)
+	if contentSnapshot([]byte(body), opts).Hash != contentSnapshot([]byte(other), opts).Hash {
+		t.Fatalf("expected dynamic regions to be ignored")
+	}
+	if got := stripContentRegion("a<x>b", "<x>", "</x>"); got != "a<x>b" {
+		t.Fatalf("unterminated region must be kept, got %q", got)
+	}
+	if ValidContentOptions(store.ContentOptions{IgnorePatterns: []string{"("}}) {
+		t.Fatalf("expected invalid pattern to be rejected")
+	}
+	if ValidContentOptions(store.ContentOptions{IgnoreRegions: []store.ContentRegion{{Start: "<x>"}}}) {
+		t.Fatalf("expected region without end to be rejected")
+	}
+}
+
+func TestContentDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn"
+	after := "a\nb\nc\nd\nE\nf\ng\nh\ni\nj\nk\nl\nm\nn\no"
+	diff, err := ContentDiff(compressContent(before), compressContent(after))
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	want := "--- baseline\n+++ current\n" +
+		"@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n" +
+		"@@ -12,3 +12,4 @@\n l\n m\n n\n+o\n"
+	if diff != want {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+	if diff, _ := ContentDiff(compressContent(before), compressContent(before)); diff != "" {
+		t.Fatalf("expected empty diff, got %q", diff)
+	}
+	if diff, _ := ContentDiff(nil, compressContent("x")); diff != "--- baseline\n+++ current\n@@ -0,0 +1,1 @@\n+x\n" {
+		t.Fatalf("unexpected diff from empty baseline: %q", diff)
+	}
+	long := strings.Repeat("line\n", 4000)
+	if diff, _ := ContentDiff(nil, compressContent(long)); len(diff) > MaxContentDiff+4 || !strings.HasSuffix(diff, "...\n") {
+		t.Fatalf("expected diff to be truncated, got %d bytes", len(diff))
+	}
+}
+
+func TestCheckMonitorHTTPContentSnapshot(t *testing.T) {
+	var version int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&version) == 0 {
+			_, _ = w.Write([]byte("<h1>Welcome</h1><span>nonce=1</span>"))
+			return
+		}
+		_, _ = w.Write([]byte("<h1>Hacked</h1><span>nonce=2</span>"))
+	}))
+	defer srv.Close()
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeHTTP, URL: srv.URL, Method: http.MethodGet, TimeoutSec: 3, AllowedStatus: []string{"200-299"}}
+
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK || res.Content != nil {
+		t.Fatalf("expected no snapshot without content watch, got ok=%v content=%v", res.OK, res.Content)
+	}
+	mon.Options.Content = &store.ContentOptions{Enabled: true, IgnorePatterns: []string{`nonce=\d+`}}
+	first := CheckMonitor(context.Background(), mon, settings)
+	if !first.OK || first.Content == nil || first.Content.Text != "<h1>Welcome</h1>\n<span>\n</span>" {
+		t.Fatalf("unexpected snapshot ok=%v content=%+v", first.OK, first.Content)
+	}
+	atomic.StoreInt32(&version, 1)
+	second := CheckMonitor(context.Background(), mon, settings)
+	if !second.OK || second.Content == nil || second.Content.Hash == first.Content.Hash {
+		t.Fatalf("expected a changed hash, got ok=%v content=%+v", second.OK, second.Content)
+	}
+}
@@ -0,0 +1,201 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	DatabaseTLSDisable = "disable"
+	DatabaseTLSPrefer  = "prefer"
+	DatabaseTLSRequire = "require"
+)
+
+const defaultDatabaseQuery = "SELECT 1"
+
+// NormalizeDatabaseTLSMode returns the effective TLS mode, or an empty string for unknown values.
+func NormalizeDatabaseTLSMode(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", DatabaseTLSPrefer:
+		return DatabaseTLSPrefer
+	case DatabaseTLSDisable:
+		return DatabaseTLSDisable
+	case DatabaseTLSRequire:
+		return DatabaseTLSRequire
+	default:
+		return ""
+	}
+}
+
+func databaseOptions(m store.Monitor) store.DatabaseOptions {
+	var opts store.DatabaseOptions
+	if m.Options.Database != nil {
+		opts = *m.Options.Database
+	}
+	opts.TLSMode = NormalizeDatabaseTLSMode(opts.TLSMode)
+	if opts.TLSMode == "" {
+		opts.TLSMode = DatabaseTLSPrefer
+	}
+	opts.Database = strings.TrimSpace(opts.Database)
+	opts.Query = strings.TrimSpace(opts.Query)
+	return opts
+}
+
+func monitorUsername(m store.Monitor) string {
+	if m.Credentials == nil {
+		return ""
+	}
+	return strings.TrimSpace(m.Credentials.Username)
+}
+
+func monitorPassword(m store.Monitor) string {
+	if m.Credentials == nil {
+		return ""
+	}
+	return m.Credentials.Password
+}
+
+// monitorHostPort resolves host and port from Host/Port or, as a fallback, from URL.
+func monitorHostPort(m store.Monitor, defaultPort int) (string, int, error) {
+	host := strings.TrimSpace(m.Host)
+	port := m.Port
+	if host == "" && strings.TrimSpace(m.URL) != "" {
+		if u, err := url.Parse(strings.TrimSpace(m.URL)); err == nil {
+			host = strings.TrimSpace(u.Hostname())
+			if port <= 0 && u.Port() != "" {
+				if p, convErr := strconv.Atoi(u.Port()); convErr == nil {
+					port = p
+				}
+			}
+		}
+	}
+	if host == "" {
+		return "", 0, errors.New("empty host")
+	}
+	if port <= 0 {
+		port = defaultPort
+	}
+	if port <= 0 || port > 65535 {
+		return "", 0, errors.New("invalid port")
+	}
+	return host, port, nil
+}
+
+func dialMonitorTarget(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration, defaultPort int) (net.Conn, string, error) {
+	return dialMonitorNetwork(ctx, "tcp", m, settings, timeout, defaultPort)
+}
+
+func dialMonitorNetwork(ctx context.Context, network string, m store.Monitor, settings store.MonitorSettings, timeout time.Duration, defaultPort int) (net.Conn, string, error) {
+	host, port, err := monitorHostPort(m, defaultPort)
+	if err != nil {
+		return nil, "", err
+	}
+	conn, err := dialGuarded(ctx, network, host, port, settings, timeout)
+	if err != nil {
+		return nil, "", err
+	}
+	return conn, host, nil
+}
+
+// dialGuarded dials host:port under the network policy and bounds the connection by timeout.
+func dialGuarded(ctx context.Context, network, host string, port int, settings store.MonitorSettings, timeout time.Duration) (net.Conn, error) {
+	if err := guardTarget(ctx, host, settings); err != nil {
+		return nil, err
+	}
+	conn, err := guardedDialer(settings, timeout).DialContext(ctx, network, net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+// monitorTLSConfig builds the client TLS config from the monitor's TLS options and client certificate.
+func monitorTLSConfig(m store.Monitor, host string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: m.IgnoreTLSErrors,
+	}
+	if m.Options.TLS != nil && strings.TrimSpace(m.Options.TLS.CACert) != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(m.Options.TLS.CACert)) {
+			return nil, ErrInvalidTLSMaterial
+		}
+		cfg.RootCAs = pool
+	}
+	if c := m.Credentials; c != nil && c.ClientCert != "" && c.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(c.ClientCert), []byte(c.ClientKey))
+		if err != nil {
+			return nil, ErrInvalidTLSMaterial
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// databaseTLSConfig builds the TLS config for the in-protocol encryption of MySQL and MSSQL. In prefer mode the
+// certificate is not verified, like libpq and the MySQL client do, since stock installs use self-signed ones;
+// require keeps the full verification.
+func databaseTLSConfig(m store.Monitor, host string, opts store.DatabaseOptions) (*tls.Config, error) {
+	cfg, err := monitorTLSConfig(m, host)
+	if err != nil {
+		return nil, err
+	}
+	if opts.TLSMode == DatabaseTLSPrefer {
+		cfg.InsecureSkipVerify = true
+	}
+	return cfg, nil
+}
+
+// upgradeTLS runs a TLS handshake over conn and records the peer certificate in res.
+func upgradeTLS(ctx context.Context, conn net.Conn, m store.Monitor, host string, res *CheckResult) (net.Conn, error) {
+	cfg, err := monitorTLSConfig(m, host)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	state := tlsConn.ConnectionState()
+	res.TLS = tlsFromState(&state)
+	return tlsConn, nil
+}
+
+// checkQueryResult compares the first column of the first row with the expected value.
+func checkQueryResult(res *CheckResult, got string, opts store.DatabaseOptions) {
+	expected := strings.TrimSpace(opts.ExpectedResult)
+	if expected == "" {
+		return
+	}
+	if strings.TrimSpace(got) != expected {
+		res.OK = false
+		res.Error = "monitoring.error.queryResultMismatch"
+	}
+}
+
+// checkExpectedRole marks the result down when the node reports a role other than the expected one.
+func checkExpectedRole(res *CheckResult, expected string) {
+	if expected == "" || !res.OK || res.ServerRole == expected {
+		return
+	}
+	res.OK = false
+	res.Degraded = false
+	res.Error = "monitoring.error.unexpectedRole"
+}
@@ -0,0 +1,339 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorMySQL(t *testing.T) {
+	host, port := startFakeMySQL(t, "secret", "42", nil)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeMySQL, Host: host, Port: port, TimeoutSec: 2}
+
+	res := CheckMonitor(context.Background(), base, settings)
+	if !res.OK || res.ServerVersion != "8.0.36-test" {
+		t.Fatalf("expected handshake-only check ok, got ok=%v error=%s version=%q", res.OK, res.Error, res.ServerVersion)
+	}
+
+	withCreds := base
+	withCreds.Credentials = &store.MonitorCredentials{Username: "monitor", Password: "secret"}
+	withCreds.Options.Database = &store.DatabaseOptions{Query: "SELECT 42", ExpectedResult: "42"}
+	res = CheckMonitor(context.Background(), withCreds, settings)
+	if !res.OK {
+		t.Fatalf("expected authenticated check ok, got error=%s", res.Error)
+	}
+
+	withCreds.Options.Database = &store.DatabaseOptions{ExpectedResult: "1"}
+	res = CheckMonitor(context.Background(), withCreds, settings)
+	if res.OK || res.Error != "monitoring.error.queryResultMismatch" {
+		t.Fatalf("expected result mismatch, got ok=%v error=%s", res.OK, res.Error)
+	}
+
+	withCreds.Credentials = &store.MonitorCredentials{Username: "monitor", Password: "wrong"}
+	withCreds.Options.Database = nil
+	res = CheckMonitor(context.Background(), withCreds, settings)
+	if res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%s", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorMySQLSelfSignedTLS(t *testing.T) {
+	cert := testServerCertificate(t, "mysql.local")
+	host, port := startFakeMySQL(t, "secret", "42", &cert)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	mon := store.Monitor{
+		Type:        TypeMySQL,
+		Host:        host,
+		Port:        port,
+		TimeoutSec:  2,
+		Credentials: &store.MonitorCredentials{Username: "monitor", Password: "secret"},
+		Options:     store.MonitorOptions{Database: &store.DatabaseOptions{ExpectedResult: "42"}},
+	}
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK {
+		t.Fatalf("expected prefer mode to accept a self-signed certificate, got error=%s", res.Error)
+	}
+	if res.TLS == nil || res.TLS.CommonName != "mysql.local" {
+		t.Fatalf("expected tls details, got %+v", res.TLS)
+	}
+	mon.Options.Database.TLSMode = DatabaseTLSRequire
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK {
+		t.Fatalf("expected require mode to verify the certificate")
+	}
+}
+
+func TestCheckMonitorMSSQL(t *testing.T) {
+	host, port := startFakeMSSQL(t, "sa", "secret", 7, tdsEncryptNotSup)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeMSSQL, Host: host, Port: port, TimeoutSec: 2}
+
+	res := CheckMonitor(context.Background(), base, settings)
+	if !res.OK || res.ServerVersion != "16.0.1000" {
+		t.Fatalf("expected prelogin check ok, got ok=%v error=%s version=%q", res.OK, res.Error, res.ServerVersion)
+	}
+
+	withCreds := base
+	withCreds.Credentials = &store.MonitorCredentials{Username: "sa", Password: "secret"}
+	withCreds.Options.Database = &store.DatabaseOptions{ExpectedResult: "7"}
+	res = CheckMonitor(context.Background(), withCreds, settings)
+	if !res.OK {
+		t.Fatalf("expected login and query ok, got error=%s", res.Error)
+	}
+
+	withCreds.Options.Database = &store.DatabaseOptions{TLSMode: DatabaseTLSRequire}
+	res = CheckMonitor(context.Background(), withCreds, settings)
+	if res.OK || res.Error != "monitoring.error.tlsRequired" {
+		t.Fatalf("expected tls required failure, got ok=%v error=%s", res.OK, res.Error)
+	}
+
+	withCreds.Options.Database = nil
+	withCreds.Credentials = &store.MonitorCredentials{Username: "sa", Password: "wrong"}
+	res = CheckMonitor(context.Background(), withCreds, settings)
+	if res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%s", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorMSSQLLoginEncryption(t *testing.T) {
+	host, port := startFakeMSSQL(t, "sa", "secret", 1, tdsEncryptOff)
+	res := CheckMonitor(context.Background(), store.Monitor{
+		Type:            TypeMSSQL,
+		Host:            host,
+		Port:            port,
+		TimeoutSec:      2,
+		IgnoreTLSErrors: true,
+		Credentials:     &store.MonitorCredentials{Username: "sa", Password: "secret"},
+		Options:         store.MonitorOptions{Database: &store.DatabaseOptions{ExpectedResult: "1"}},
+	}, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if !res.OK {
+		t.Fatalf("expected check ok, got error=%s", res.Error)
+	}
+	if res.TLS == nil || res.TLS.CommonName != "mssql.local" {
+		t.Fatalf("expected tls details from the login handshake, got %+v", res.TLS)
+	}
+}
+
+func TestCheckMonitorMSSQLSelfSignedTLS(t *testing.T) {
+	host, port := startFakeMSSQL(t, "sa", "secret", 1, tdsEncryptOff)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	mon := store.Monitor{
+		Type:        TypeMSSQL,
+		Host:        host,
+		Port:        port,
+		TimeoutSec:  2,
+		Credentials: &store.MonitorCredentials{Username: "sa", Password: "secret"},
+		Options:     store.MonitorOptions{Database: &store.DatabaseOptions{ExpectedResult: "1"}},
+	}
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK {
+		t.Fatalf("expected prefer mode to accept a self-signed certificate, got error=%s", res.Error)
+	}
+	mon.Options.Database.TLSMode = DatabaseTLSRequire
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK {
+		t.Fatalf("expected require mode to verify the certificate")
+	}
+}
+
+func TestFormatTDSValue(t *testing.T) {
+	cases := []struct {
+		col  tdsColumn
+		data []byte
+		want string
+	}{
+		{tdsColumn{typ: 0xe7}, ucs2("ok"), "ok"},
+		{tdsColumn{typ: 0x6a, scale: 2}, []byte{0, 0x39, 0x30, 0, 0}, "-123.45"},
+		{tdsColumn{typ: 0x68}, []byte{1}, "1"},
+		{tdsColumn{typ: 0x7f}, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "-1"},
+	}
+	for _, tc := range cases {
+		if got := formatTDSValue(tc.col, tc.data); got != tc.want {
+			t.Fatalf("type 0x%02x: expected %q, got %q", tc.col.typ, tc.want, got)
+		}
+	}
+}
+
+func startFakeMySQL(t *testing.T, password, value string, cert *tls.Certificate) (string, int) {
+	t.Helper()
+	return startFakeServer(t, func(conn net.Conn) {
+		c := &mysqlConn{conn: conn}
+		scramble := []byte("abcdefghijklmnopqrst")
+		caps := uint32(mysqlClientProtocol41 | mysqlClientSecureConn | mysqlClientPluginAuth | mysqlClientLongPassword | mysqlClientTransactions)
+		if cert != nil {
+			caps |= mysqlClientSSL
+		}
+		hs := []byte{10}
+		hs = append(hs, "8.0.36-test\x00"...)
+		hs = append(hs, 1, 0, 0, 0)
+		hs = append(hs, scramble[:8]...)
+		hs = append(hs, 0, byte(caps), byte(caps>>8), mysqlCharsetUTF8, 2, 0, byte(caps>>16), byte(caps>>24), 21)
+		hs = append(hs, make([]byte, 10)...)
+		hs = append(hs, scramble[8:]...)
+		hs = append(hs, 0)
+		hs = append(hs, mysqlNativeAuth+"\x00"...)
+		_ = c.writePacket(hs)
+
+		resp, err := c.readPacket()
+		if err == nil && len(resp) == 32 && binary.LittleEndian.Uint32(resp[0:4])&mysqlClientSSL != 0 {
+			if cert == nil {
+				return
+			}
+			srv := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*cert}})
+			if err := srv.Handshake(); err != nil {
+				return
+			}
+			c.conn = srv
+			resp, err = c.readPacket()
+		}
+		if err != nil || len(resp) < 33 {
+			return
+		}
+		_, rest := splitNullTerminated(resp[32:])
+		if len(rest) == 0 {
+			return
+		}
+		auth := rest[1 : 1+int(rest[0])]
+		want, _ := mysqlAuthResponse(mysqlNativeAuth, scramble, password)
+		if !bytes.Equal(auth, want) {
+			_ = c.writePacket(append([]byte{mysqlPacketError, 0x15, 0x04, '#', '2', '8', '0', '0', '0'}, "Access denied"...))
+			return
+		}
+		_ = c.writePacket([]byte{mysqlPacketOK, 0, 0, 2, 0, 0, 0})
+
+		if _, err := c.readPacket(); err != nil {
+			return
+		}
+		_ = c.writePacket([]byte{1})
+		_ = c.writePacket(append([]byte{3}, "def"...))
+		_ = c.writePacket([]byte{mysqlPacketEOF, 0, 0, 2, 0})
+		_ = c.writePacket(append([]byte{byte(len(value))}, value...))
+		_ = c.writePacket([]byte{mysqlPacketEOF, 0, 0, 2, 0})
+		_, _ = c.readPacket()
+	})
+}
+
+func startFakeMSSQL(t *testing.T, username, password string, value int32, encryption byte) (string, int) {
+	t.Helper()
+	cert := testServerCertificate(t, "mssql.local")
+	return startFakeServer(t, func(conn net.Conn) {
+		if _, _, err := readTDSMessage(conn); err != nil {
+			return
+		}
+		prelogin := []byte{
+			tdsPreloginVersion, 0, 11, 0, 6,
+			tdsPreloginEncryption, 0, 17, 0, 1,
+			tdsPreloginTerminator,
+			16, 0, 0x03, 0xe8, 0, 0,
+			encryption,
+		}
+		_ = writeTDSMessage(conn, tdsPacketReply, prelogin)
+
+		// With ENCRYPT_OFF only LOGIN7 is read over TLS, the rest of the session is plain.
+		var loginReader io.Reader = conn
+		if encryption == tdsEncryptOff {
+			shim := &tdsHandshakeConn{Conn: conn, framing: true}
+			srv := tls.Server(shim, &tls.Config{Certificates: []tls.Certificate{cert}})
+			if err := srv.Handshake(); err != nil {
+				return
+			}
+			if err := shim.flush(); err != nil {
+				return
+			}
+			shim.framing = false
+			loginReader = srv
+		}
+		_, login, err := readTDSMessage(loginReader)
+		if err != nil || len(login) < tdsLogin7Header {
+			return
+		}
+		field := func(at int) []byte {
+			off := int(binary.LittleEndian.Uint16(login[at:]))
+			n := int(binary.LittleEndian.Uint16(login[at+2:])) * 2
+			return login[off : off+n]
+		}
+		done := []byte{tdsTokenDone, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+		if fromUCS2(field(40)) != username || !bytes.Equal(field(44), tdsManglePassword(ucs2(password))) {
+			msg := ucs2("Login failed")
+			body := []byte{0x48, 0x48, 0, 0, 1, 14}
+			body = binary.LittleEndian.AppendUint16(body, uint16(len(msg)/2))
+			body = append(body, msg...)
+			body = append(body, 0, 0, 1, 0, 0, 0)
+			token := binary.LittleEndian.AppendUint16([]byte{tdsTokenError}, uint16(len(body)))
+			_ = writeTDSMessage(conn, tdsPacketReply, append(append(token, body...), done...))
+			return
+		}
+		name := ucs2("Microsoft SQL Server")
+		ack := []byte{1, 0x74, 0, 0, 4, byte(len(name) / 2)}
+		ack = append(ack, name...)
+		ack = append(ack, 16, 0, 0x03, 0xe8)
+		token := binary.LittleEndian.AppendUint16([]byte{tdsTokenLoginAck}, uint16(len(ack)))
+		_ = writeTDSMessage(conn, tdsPacketReply, append(append(token, ack...), done...))
+
+		if _, _, err := readTDSMessage(conn); err != nil {
+			return
+		}
+		rows := []byte{tdsTokenColMetadata, 1, 0, 0, 0, 0, 0, 0, 0, 0x26, 4, 0}
+		rows = append(rows, tdsTokenRow, 4)
+		rows = binary.LittleEndian.AppendUint32(rows, uint32(value))
+		_ = writeTDSMessage(conn, tdsPacketReply, append(rows, done...))
+	})
+}
+
+func testServerCertificate(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		DNSNames:     []string{commonName},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("cert: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func startFakeServer(t *testing.T, handle func(net.Conn)) (string, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	host, portRaw, _ := net.SplitHostPort(ln.Addr().String())
+	port, _ := strconv.Atoi(portRaw)
+	return host, port
+}
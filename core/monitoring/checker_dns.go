@@ -0,0 +1,530 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	DNSTransportUDP = "udp"
+	DNSTransportTCP = "tcp"
+	DNSTransportDoT = "dot"
+
+	dnsUDPSize     = 1232
+	dnsMaxExpected = 64
+)
+
+var dnsRecordTypes = map[string]uint16{
+	"A":     1,
+	"NS":    2,
+	"CNAME": 5,
+	"SOA":   6,
+	"MX":    15,
+	"TXT":   16,
+	"AAAA":  28,
+	"SRV":   33,
+	"CAA":   257,
+}
+
+// dnsDriftTypes are the record sets watched in drift mode: a change in any of them is how a hijack shows up.
+var dnsDriftTypes = []string{"NS", "MX", "A"}
+
+var resolvConfPath = "/etc/resolv.conf"
+
+var errDNSTruncated = errors.New("dns response truncated")
+
+func NormalizeDNSRecordType(raw string) string {
+	val := strings.ToUpper(strings.TrimSpace(raw))
+	if val == "" || val == "GET" {
+		return "A"
+	}
+	if _, ok := dnsRecordTypes[val]; ok {
+		return val
+	}
+	return ""
+}
+
+func NormalizeDNSTransport(raw string) string {
+	switch val := strings.ToLower(strings.TrimSpace(raw)); val {
+	case "":
+		return DNSTransportUDP
+	case DNSTransportUDP, DNSTransportTCP, DNSTransportDoT:
+		return val
+	case "tls":
+		return DNSTransportDoT
+	default:
+		return ""
+	}
+}
+
+func dnsOptions(m store.Monitor) store.DNSOptions {
+	var opts store.DNSOptions
+	if m.Options.DNS != nil {
+		opts = *m.Options.DNS
+	}
+	opts.Resolver = strings.TrimSpace(opts.Resolver)
+	opts.Transport = NormalizeDNSTransport(opts.Transport)
+	return opts
+}
+
+type dnsResolver struct {
+	host      string
+	port      int
+	transport string
+	m         store.Monitor
+	settings  store.MonitorSettings
+	timeout   time.Duration
+}
+
+func checkDNS(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	name := strings.TrimSuffix(strings.TrimSpace(m.Host), ".")
+	if name == "" {
+		return CheckResult{}, errors.New("empty host")
+	}
+	qtype := NormalizeDNSRecordType(m.Method)
+	if qtype == "" {
+		return CheckResult{}, fmt.Errorf("unsupported record type %q", m.Method)
+	}
+	opts := dnsOptions(m)
+	if opts.Transport == "" {
+		return CheckResult{}, fmt.Errorf("unsupported dns transport")
+	}
+	resolver, err := newDNSResolver(ctx, m, opts, settings, timeout)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	answers := map[string][]string{}
+	records, rcode, err := resolver.query(ctx, name, qtype)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	answers[qtype] = records
+	res := CheckResult{OK: true, Details: &store.MonitorDetails{DNS: &store.DNSDetails{Records: records}}}
+	switch {
+	case rcode == dnsRcodeServFail || rcode == dnsRcodeRefused:
+		res.OK = false
+		res.Error = "monitoring.error.dnsServerFailure"
+	case len(records) == 0:
+		res.OK = false
+		res.Error = "monitoring.error.dnsNoAnswer"
+	case len(opts.Expected) > 0:
+		if !dnsSameSet(records, normalizeDNSRecords(qtype, opts.Expected)) {
+			res.OK = false
+			res.Error = "monitoring.error.dnsUnexpectedAnswer"
+		}
+	case strings.TrimSpace(m.RequestBody) != "":
+		// Legacy expectation: any record containing the text.
+		expect := strings.TrimSpace(m.RequestBody)
+		found := false
+		for _, rec := range records {
+			if strings.Contains(rec, expect) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res.OK = false
+			res.Error = "monitoring.error.dnsNoAnswer"
+		}
+	}
+	if !opts.Drift {
+		return res, nil
+	}
+	baseline := map[string][]string{}
+	for _, t := range dnsDriftTypes {
+		set, ok := answers[t]
+		if !ok {
+			if set, _, err = resolver.query(ctx, name, t); err != nil {
+				return CheckResult{}, err
+			}
+		}
+		baseline[t] = set
+	}
+	res.Details.DNS.Baseline = baseline
+	return res, nil
+}
+
+// detectDNSDrift compares the drift baseline with the previous check and degrades the result on changes.
+func detectDNSDrift(prev *store.MonitorDetails, res *CheckResult) []string {
+	if prev == nil || prev.DNS == nil || len(prev.DNS.Baseline) == 0 || res.Details == nil || res.Details.DNS == nil {
+		return nil
+	}
+	var changes []string
+	for _, t := range dnsDriftTypes {
+		before, ok := prev.DNS.Baseline[t]
+		after, seen := res.Details.DNS.Baseline[t]
+		if !ok || !seen || dnsSameSet(before, after) {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", t, strings.Join(before, ", "), strings.Join(after, ", ")))
+	}
+	if len(changes) > 0 && res.OK && !res.Degraded {
+		res.Degraded = true
+		res.Error = "monitoring.error.dnsDrift"
+	}
+	return changes
+}
+
+func dnsSameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeDNSRecords brings expected values to the presentation format produced by the parser.
+func normalizeDNSRecords(qtype string, values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, raw := range values {
+		val := strings.TrimSpace(raw)
+		if val == "" {
+			continue
+		}
+		switch qtype {
+		case "TXT", "CAA":
+		case "A", "AAAA":
+			if ip := net.ParseIP(val); ip != nil {
+				val = ip.String()
+			}
+		default:
+			fields := strings.Fields(strings.ToLower(val))
+			for i, f := range fields {
+				fields[i] = strings.TrimSuffix(f, ".")
+			}
+			val = strings.Join(fields, " ")
+		}
+		out = append(out, val)
+	}
+	return out
+}
+
+func newDNSResolver(ctx context.Context, m store.Monitor, opts store.DNSOptions, settings store.MonitorSettings, timeout time.Duration) (*dnsResolver, error) {
+	r := &dnsResolver{transport: opts.Transport, m: m, settings: settings, timeout: timeout}
+	if opts.Resolver == "" {
+		// The system resolver is host configuration; the queried name is guarded as before.
+		if err := guardTarget(ctx, strings.TrimSpace(m.Host), settings); err != nil {
+			return nil, err
+		}
+		r.host = systemNameserver()
+		r.settings.AllowPrivateNetworks = true
+	} else {
+		r.host, r.port = splitHostPort(opts.Resolver)
+	}
+	if r.host == "" {
+		return nil, errors.New("empty resolver")
+	}
+	if r.port <= 0 {
+		r.port = 53
+		if r.transport == DNSTransportDoT {
+			r.port = 853
+		}
+	}
+	return r, nil
+}
+
+func systemNameserver() string {
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return strings.SplitN(fields[1], "%", 2)[0]
+		}
+	}
+	return "127.0.0.1"
+}
+
+const (
+	dnsRcodeNoError  = 0
+	dnsRcodeServFail = 2
+	dnsRcodeNXDomain = 3
+	dnsRcodeRefused  = 5
+)
+
+// query returns the records of qtype for name in presentation format together with the response code.
+func (r *dnsResolver) query(ctx context.Context, name, qtype string) ([]string, int, error) {
+	code := dnsRecordTypes[qtype]
+	msg, id, err := buildDNSQuery(name, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	var resp []byte
+	switch r.transport {
+	case DNSTransportUDP:
+		resp, err = r.exchangeUDP(ctx, msg, id)
+		if errors.Is(err, errDNSTruncated) {
+			resp, err = r.exchangeStream(ctx, msg, id, false)
+		}
+	case DNSTransportTCP:
+		resp, err = r.exchangeStream(ctx, msg, id, false)
+	default:
+		resp, err = r.exchangeStream(ctx, msg, id, true)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	records, rcode, err := parseDNSResponse(resp, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rcode == dnsRcodeNXDomain {
+		return nil, rcode, nil
+	}
+	return records, rcode, nil
+}
+
+func (r *dnsResolver) exchangeUDP(ctx context.Context, msg []byte, id uint16) ([]byte, error) {
+	conn, err := dialGuarded(ctx, "udp", r.host, r.port, r.settings, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Datagrams with another ID are stale or spoofed and ignored.
+		if n < 12 || binary.BigEndian.Uint16(buf) != id {
+			continue
+		}
+		if binary.BigEndian.Uint16(buf[2:])&0x0200 != 0 {
+			return nil, errDNSTruncated
+		}
+		return append([]byte{}, buf[:n]...), nil
+	}
+}
+
+func (r *dnsResolver) exchangeStream(ctx context.Context, msg []byte, id uint16, useTLS bool) ([]byte, error) {
+	conn, err := dialGuarded(ctx, "tcp", r.host, r.port, r.settings, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if useTLS {
+		cfg, err := monitorTLSConfig(r.m, r.host)
+		if err != nil {
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(msg)))
+	if _, err := conn.Write(append(out, msg...)); err != nil {
+		return nil, err
+	}
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	if len(resp) < 12 || binary.BigEndian.Uint16(resp) != id {
+		return nil, fmt.Errorf("%w: dns id mismatch", ErrProtocol)
+	}
+	return resp, nil
+}
+
+func buildDNSQuery(name string, qtype uint16) ([]byte, uint16, error) {
+	var idBuf [2]byte
+	if _, err := rand.Read(idBuf[:]); err != nil {
+		return nil, 0, err
+	}
+	id := binary.BigEndian.Uint16(idBuf[:])
+	msg := binary.BigEndian.AppendUint16(nil, id)
+	// RD set; one question and an EDNS0 OPT record advertising a larger UDP payload.
+	msg = append(msg, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 1)
+	if len(name) > 253 {
+		return nil, 0, errors.New("dns name too long")
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return nil, 0, errors.New("invalid dns name")
+		}
+		msg = append(append(msg, byte(len(label))), label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, qtype)
+	msg = binary.BigEndian.AppendUint16(msg, 1)
+	msg = append(msg, 0, 0, 41)
+	msg = binary.BigEndian.AppendUint16(msg, dnsUDPSize)
+	msg = append(msg, 0, 0, 0, 0, 0, 0)
+	return msg, id, nil
+}
+
+func parseDNSResponse(msg []byte, qtype uint16) ([]string, int, error) {
+	if len(msg) < 12 || msg[2]&0x80 == 0 {
+		return nil, 0, fmt.Errorf("%w: not a dns response", ErrProtocol)
+	}
+	rcode := int(msg[3] & 0x0F)
+	qd := int(binary.BigEndian.Uint16(msg[4:]))
+	an := int(binary.BigEndian.Uint16(msg[6:]))
+	off := 12
+	for i := 0; i < qd; i++ {
+		_, next, err := readDNSName(msg, off)
+		if err != nil || next+4 > len(msg) {
+			return nil, 0, fmt.Errorf("%w: malformed question", ErrProtocol)
+		}
+		off = next + 4
+	}
+	records := []string{}
+	seen := map[string]bool{}
+	for i := 0; i < an; i++ {
+		_, next, err := readDNSName(msg, off)
+		if err != nil || next+10 > len(msg) {
+			return nil, 0, fmt.Errorf("%w: malformed answer", ErrProtocol)
+		}
+		typ := binary.BigEndian.Uint16(msg[next:])
+		rdlen := int(binary.BigEndian.Uint16(msg[next+8:]))
+		start := next + 10
+		if start+rdlen > len(msg) {
+			return nil, 0, fmt.Errorf("%w: malformed answer", ErrProtocol)
+		}
+		off = start + rdlen
+		if typ != qtype {
+			continue
+		}
+		rec, err := formatDNSRecord(msg, typ, start, rdlen)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !seen[rec] {
+			seen[rec] = true
+			records = append(records, rec)
+		}
+	}
+	sort.Strings(records)
+	return records, rcode, nil
+}
+
+// formatDNSRecord renders rdata in the presentation format used for expected values and drift baselines.
+func formatDNSRecord(msg []byte, typ uint16, start, rdlen int) (string, error) {
+	rdata := msg[start : start+rdlen]
+	bad := fmt.Errorf("%w: malformed rdata", ErrProtocol)
+	switch typ {
+	case 1, 28:
+		if (typ == 1 && rdlen != 4) || (typ == 28 && rdlen != 16) {
+			return "", bad
+		}
+		return net.IP(rdata).String(), nil
+	case 2, 5:
+		name, _, err := readDNSName(msg, start)
+		return name, err
+	case 15:
+		if rdlen < 3 {
+			return "", bad
+		}
+		name, _, err := readDNSName(msg, start+2)
+		return fmt.Sprintf("%d %s", binary.BigEndian.Uint16(rdata), name), err
+	case 16:
+		var b strings.Builder
+		for i := 0; i < len(rdata); {
+			n := int(rdata[i])
+			if i+1+n > len(rdata) {
+				return "", bad
+			}
+			b.Write(rdata[i+1 : i+1+n])
+			i += 1 + n
+		}
+		return b.String(), nil
+	case 6:
+		mname, next, err := readDNSName(msg, start)
+		if err != nil {
+			return "", err
+		}
+		rname, next, err := readDNSName(msg, next)
+		if err != nil || next+20 > start+rdlen {
+			return "", bad
+		}
+		nums := make([]string, 5)
+		for i := range nums {
+			nums[i] = strconv.FormatUint(uint64(binary.BigEndian.Uint32(msg[next+4*i:])), 10)
+		}
+		return mname + " " + rname + " " + strings.Join(nums, " "), nil
+	case 33:
+		if rdlen < 7 {
+			return "", bad
+		}
+		target, _, err := readDNSName(msg, start+6)
+		return fmt.Sprintf("%d %d %d %s", binary.BigEndian.Uint16(rdata), binary.BigEndian.Uint16(rdata[2:]), binary.BigEndian.Uint16(rdata[4:]), target), err
+	case 257:
+		if rdlen < 2 || 2+int(rdata[1]) > rdlen {
+			return "", bad
+		}
+		tagEnd := 2 + int(rdata[1])
+		return fmt.Sprintf("%d %s %q", rdata[0], strings.ToLower(string(rdata[2:tagEnd])), string(rdata[tagEnd:])), nil
+	}
+	return "", bad
+}
+
+// readDNSName decodes a possibly compressed name at off and returns it lower-cased without the trailing dot.
+func readDNSName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, ErrProtocol
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.ToLower(strings.Join(labels, ".")), next, nil
+		case n&0xC0 == 0xC0:
+			if off+1 >= len(msg) || jumps > 32 {
+				return "", 0, ErrProtocol
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3FFF)
+			jumps++
+		case n&0xC0 != 0:
+			return "", 0, ErrProtocol
+		default:
+			if off+1+n > len(msg) {
+				return "", 0, ErrProtocol
+			}
+			labels = append(labels, string(msg[off+1:off+1+n]))
+			off += 1 + n
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+type fakeZone struct {
+	mu      sync.Mutex
+	records map[string][][]byte
+}
+
+func (z *fakeZone) set(name, qtype string, rdata ...[]byte) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.records[name+"|"+qtype] = rdata
+}
+
+// answer builds a response to query; over UDP names starting with "big." are truncated.
+func (z *fakeZone) answer(query []byte, udp bool) []byte {
+	name, off, err := readDNSName(query, 12)
+	if err != nil {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[off:])
+	var typeName string
+	for k, v := range dnsRecordTypes {
+		if v == qtype {
+			typeName = k
+		}
+	}
+	flags := uint16(0x8180)
+	z.mu.Lock()
+	rdata := z.records[name+"|"+typeName]
+	known := false
+	for key := range z.records {
+		known = known || strings.HasPrefix(key, name+"|")
+	}
+	z.mu.Unlock()
+	switch {
+	case name == "fail.example.com":
+		flags |= dnsRcodeServFail
+	case !known:
+		flags |= dnsRcodeNXDomain
+	case udp && strings.HasPrefix(name, "big."):
+		flags |= 0x0200
+		rdata = nil
+	}
+	resp := append([]byte{}, query[:2]...)
+	resp = binary.BigEndian.AppendUint16(resp, flags)
+	resp = append(resp, 0, 1)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(rdata)))
+	resp = append(resp, 0, 0, 0, 0)
+	resp = append(resp, query[12:off+4]...)
+	for _, rd := range rdata {
+		resp = append(resp, 0xC0, 0x0C)
+		resp = binary.BigEndian.AppendUint16(resp, qtype)
+		resp = append(resp, 0, 1, 0, 0, 0, 60)
+		resp = binary.BigEndian.AppendUint16(resp, uint16(len(rd)))
+		resp = append(resp, rd...)
+	}
+	return resp
+}
+
+func dnsTestName(name string) []byte {
+	var out []byte
+	for _, label := range strings.Split(name, ".") {
+		out = append(append(out, byte(len(label))), label...)
+	}
+	return append(out, 0)
+}
+
+// startFakeDNS serves zone over UDP and TCP on the same port, like a real resolver.
+func startFakeDNS(t *testing.T, zone *fakeZone) int {
+	t.Helper()
+	port := startFakeUDPServer(t, func(req []byte) [][]byte {
+		if resp := zone.answer(req, true); resp != nil {
+			return [][]byte{resp}
+		}
+		return nil
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				serveDNSStream(conn, zone)
+			}(conn)
+		}
+	}()
+	return port
+}
+
+func serveDNSStream(conn net.Conn, zone *fakeZone) {
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return
+	}
+	query := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, query); err != nil {
+		return
+	}
+	resp := zone.answer(query, false)
+	_, _ = conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...))
+}
+
+func newFakeZone() *fakeZone {
+	zone := &fakeZone{records: map[string][][]byte{}}
+	zone.set("example.com", "A", []byte{192, 0, 2, 10}, []byte{192, 0, 2, 11})
+	zone.set("example.com", "NS", dnsTestName("ns1.example.net"), dnsTestName("ns2.example.net"))
+	zone.set("example.com", "MX", append([]byte{0, 10}, dnsTestName("Mail.Example.com")...))
+	zone.set("example.com", "TXT", []byte("\x0bv=spf1 -all"))
+	zone.set("big.example.com", "A", []byte{198, 51, 100, 1})
+	return zone
+}
+
+func TestCheckMonitorDNS(t *testing.T) {
+	zone := newFakeZone()
+	port := startFakeDNS(t, zone)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+
+	cases := []struct {
+		name    string
+		host    string
+		rtype   string
+		opts    store.DNSOptions
+		body    string
+		ok      bool
+		err     string
+		records []string
+	}{
+		{name: "exact set", host: "example.com", rtype: "A", opts: store.DNSOptions{Expected: []string{"192.0.2.11", "192.0.2.10"}}, ok: true, records: []string{"192.0.2.10", "192.0.2.11"}},
+		{name: "set differs", host: "example.com", rtype: "A", opts: store.DNSOptions{Expected: []string{"192.0.2.10"}}, err: "monitoring.error.dnsUnexpectedAnswer"},
+		{name: "mx", host: "example.com", rtype: "MX", opts: store.DNSOptions{Expected: []string{"10 mail.example.com."}}, ok: true, records: []string{"10 mail.example.com"}},
+		{name: "txt legacy substring", host: "example.com", rtype: "TXT", body: "spf1", ok: true, records: []string{"v=spf1 -all"}},
+		{name: "tcp", host: "example.com", rtype: "NS", opts: store.DNSOptions{Transport: "tcp"}, ok: true, records: []string{"ns1.example.net", "ns2.example.net"}},
+		{name: "truncated falls back to tcp", host: "big.example.com", rtype: "A", ok: true, records: []string{"198.51.100.1"}},
+		{name: "nxdomain", host: "missing.example.com", rtype: "A", err: "monitoring.error.dnsNoAnswer"},
+		{name: "servfail", host: "fail.example.com", rtype: "A", err: "monitoring.error.dnsServerFailure"},
+	}
+	for _, tc := range cases {
+		opts := tc.opts
+		opts.Resolver = "127.0.0.1:" + strconv.Itoa(port)
+		mon := store.Monitor{Type: TypeDNS, Host: tc.host, Method: tc.rtype, RequestBody: tc.body, TimeoutSec: 2}
+		mon.Options.DNS = &opts
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v error=%q", tc.name, res.OK, res.Error)
+		}
+		if tc.records != nil && (res.Details == nil || res.Details.DNS == nil || strings.Join(res.Details.DNS.Records, ",") != strings.Join(tc.records, ",")) {
+			t.Fatalf("%s: unexpected records %+v", tc.name, res.Details)
+		}
+	}
+}
+
+func TestCheckMonitorDNSOverTLS(t *testing.T) {
+	zone := newFakeZone()
+	cert := testServerCertificate(t, "127.0.0.1")
+	_, port := startFakeServer(t, func(conn net.Conn) {
+		tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+		defer tlsConn.Close()
+		serveDNSStream(tlsConn, zone)
+	})
+	mon := store.Monitor{Type: TypeDNS, Host: "example.com", Method: "A", TimeoutSec: 2, IgnoreTLSErrors: true}
+	mon.Options.DNS = &store.DNSOptions{Resolver: "127.0.0.1:" + strconv.Itoa(port), Transport: "dot"}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if !res.OK || res.Details == nil || len(res.Details.DNS.Records) != 2 {
+		t.Fatalf("expected DoT answer, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestDNSDrift(t *testing.T) {
+	zone := newFakeZone()
+	port := startFakeDNS(t, zone)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	mon := store.Monitor{Type: TypeDNS, Host: "example.com", Method: "A", TimeoutSec: 2}
+	mon.Options.DNS = &store.DNSOptions{Resolver: "127.0.0.1:" + strconv.Itoa(port), Drift: true}
+
+	first := CheckMonitor(context.Background(), mon, settings)
+	if !first.OK || first.Details == nil || len(first.Details.DNS.Baseline["NS"]) != 2 || len(first.Details.DNS.Baseline["MX"]) != 1 {
+		t.Fatalf("expected drift baseline, got %+v", first.Details)
+	}
+	if changes := detectDNSDrift(nil, &first); changes != nil || first.Degraded {
+		t.Fatalf("first check must only record the baseline")
+	}
+
+	same := CheckMonitor(context.Background(), mon, settings)
+	if changes := detectDNSDrift(first.Details, &same); changes != nil || same.Degraded {
+		t.Fatalf("unchanged records must not drift: %v", changes)
+	}
+
+	zone.set("example.com", "NS", dnsTestName("ns1.attacker.test"))
+	hijacked := CheckMonitor(context.Background(), mon, settings)
+	changes := detectDNSDrift(first.Details, &hijacked)
+	if len(changes) != 1 || !strings.HasPrefix(changes[0], "NS: ") || !hijacked.Degraded || hijacked.Error != "monitoring.error.dnsDrift" {
+		t.Fatalf("expected NS drift, got changes=%v degraded=%v error=%q", changes, hijacked.Degraded, hijacked.Error)
+	}
+}
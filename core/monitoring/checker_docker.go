@@ -0,0 +1,238 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	dockerMaxContainers = 100
+	dockerMaxBody       = 4 << 20
+)
+
+var errDockerNotFound = errors.New("docker object not found")
+
+type dockerInspect struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	RestartCount int    `json:"RestartCount"`
+	Config       struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+	State struct {
+		Status    string `json:"Status"`
+		Running   bool   `json:"Running"`
+		StartedAt string `json:"StartedAt"`
+		Health    *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+}
+
+type dockerAPI struct {
+	client *http.Client
+	base   string
+}
+
+func dockerOptions(m store.Monitor) store.DockerOptions {
+	var opts store.DockerOptions
+	if m.Options.Docker != nil {
+		opts = *m.Options.Docker
+	}
+	opts.Socket = strings.TrimSpace(opts.Socket)
+	opts.Container = strings.TrimSpace(opts.Container)
+	opts.LabelSelector = strings.TrimSpace(opts.LabelSelector)
+	return opts
+}
+
+func checkDocker(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := dockerOptions(m)
+	api, err := newDockerAPI(ctx, m, settings, timeout, opts)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer api.client.CloseIdleConnections()
+	res := CheckResult{OK: true}
+	var version struct {
+		Version string `json:"Version"`
+	}
+	resp, err := api.get(ctx, "/version", &version)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res.ServerVersion = version.Version
+	res.TLS = tlsFromState(resp.TLS)
+
+	var ids []string
+	switch {
+	case opts.LabelSelector != "":
+		filters, _ := json.Marshal(map[string][]string{"label": {opts.LabelSelector}})
+		var list []struct {
+			ID string `json:"Id"`
+		}
+		if _, err := api.get(ctx, "/containers/json?all=true&filters="+url.QueryEscape(string(filters)), &list); err != nil {
+			return CheckResult{}, err
+		}
+		if len(list) == 0 {
+			return CheckResult{OK: false, Error: "monitoring.error.containerNotFound"}, nil
+		}
+		for i, item := range list {
+			if i == dockerMaxContainers {
+				break
+			}
+			ids = append(ids, item.ID)
+		}
+	case opts.Container != "":
+		ids = []string{opts.Container}
+	default:
+		// Without a container or selector the check only confirms the Engine API answers.
+		return res, nil
+	}
+
+	now := time.Now().UTC()
+	res.Details = &store.MonitorDetails{}
+	for _, id := range ids {
+		var info dockerInspect
+		if _, err := api.get(ctx, "/containers/"+url.PathEscape(id)+"/json", &info); err != nil {
+			if errors.Is(err, errDockerNotFound) {
+				return CheckResult{OK: false, Error: "monitoring.error.containerNotFound"}, nil
+			}
+			return CheckResult{}, err
+		}
+		details := containerDetails(info, now)
+		res.Details.Containers = append(res.Details.Containers, details)
+		switch {
+		case !info.State.Running:
+			markContainerFailure(&res, "monitoring.error.containerNotRunning")
+		case details.Health == "unhealthy":
+			markContainerFailure(&res, "monitoring.error.containerUnhealthy")
+		case details.Health == "starting" && res.OK:
+			res.Degraded = true
+			res.Error = "monitoring.error.containerStarting"
+		}
+	}
+	return res, nil
+}
+
+func markContainerFailure(res *CheckResult, key string) {
+	if !res.OK {
+		return
+	}
+	res.OK = false
+	res.Degraded = false
+	res.Error = key
+}
+
+func containerDetails(info dockerInspect, now time.Time) store.ContainerDetails {
+	id := info.ID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	details := store.ContainerDetails{
+		ID:           id,
+		Name:         strings.TrimPrefix(info.Name, "/"),
+		Image:        info.Config.Image,
+		State:        info.State.Status,
+		RestartCount: info.RestartCount,
+	}
+	if info.State.Health != nil {
+		details.Health = info.State.Health.Status
+	}
+	if started, err := time.Parse(time.RFC3339Nano, info.State.StartedAt); err == nil && started.Year() > 1 {
+		started = started.UTC()
+		details.StartedAt = &started
+		if info.State.Running {
+			details.UptimeSec = int64(now.Sub(started).Seconds())
+		}
+	}
+	return details
+}
+
+// checkContainerRestarts fails the result when a container restarted since the previous check.
+func checkContainerRestarts(prev *store.MonitorDetails, res *CheckResult) {
+	if prev == nil || res.Details == nil {
+		return
+	}
+	counts := make(map[string]int, len(prev.Containers))
+	for _, c := range prev.Containers {
+		counts[c.ID] = c.RestartCount
+	}
+	for _, c := range res.Details.Containers {
+		if before, ok := counts[c.ID]; ok && c.RestartCount > before {
+			markContainerFailure(res, "monitoring.error.containerRestarted")
+			return
+		}
+	}
+}
+
+func newDockerAPI(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration, opts store.DockerOptions) (*dockerAPI, error) {
+	transport := &http.Transport{
+		DisableKeepAlives:   true,
+		TLSHandshakeTimeout: timeout,
+	}
+	api := &dockerAPI{client: &http.Client{Timeout: timeout, Transport: transport}}
+	if opts.Socket != "" {
+		// The local Engine socket grants host-level access, so it counts as a private target.
+		if !settings.AllowPrivateNetworks {
+			return nil, ErrPrivateBlocked
+		}
+		dialer := &net.Dialer{Timeout: timeout}
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", opts.Socket)
+		}
+		api.base = "http://docker"
+		return api, nil
+	}
+	host, port, err := monitorHostPort(m, DefaultPortForType(TypeDocker))
+	if err != nil {
+		return nil, err
+	}
+	if err := guardTarget(ctx, host, settings); err != nil {
+		return nil, err
+	}
+	transport.DialContext = guardedDialer(settings, timeout).DialContext
+	scheme := "http"
+	if opts.TLS {
+		cfg, err := monitorTLSConfig(m, host)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = cfg
+		scheme = "https"
+	}
+	api.base = scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+	return api, nil
+}
+
+func (a *dockerAPI) get(ctx context.Context, path string, out any) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return resp, errDockerNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, fmt.Errorf("docker api %s: status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, dockerMaxBody)).Decode(out); err != nil {
+		return resp, ErrProtocol
+	}
+	return resp, nil
+}
@@ -0,0 +1,126 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorDocker(t *testing.T) {
+	socket := startFakeDocker(t)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeDocker, TimeoutSec: 2}
+
+	cases := []struct {
+		name       string
+		opts       store.DockerOptions
+		ok         bool
+		degraded   bool
+		err        string
+		containers int
+	}{
+		{name: "engine only", opts: store.DockerOptions{}, ok: true},
+		{name: "healthy", opts: store.DockerOptions{Container: "siem"}, ok: true, containers: 1},
+		{name: "starting", opts: store.DockerOptions{Container: "ids"}, ok: true, degraded: true, err: "monitoring.error.containerStarting", containers: 1},
+		{name: "stopped", opts: store.DockerOptions{Container: "scanner"}, err: "monitoring.error.containerNotRunning", containers: 1},
+		{name: "missing", opts: store.DockerOptions{Container: "nope"}, err: "monitoring.error.containerNotFound"},
+		{name: "selector", opts: store.DockerOptions{LabelSelector: "scc.role=security"}, err: "monitoring.error.containerUnhealthy", containers: 2},
+		{name: "empty selector", opts: store.DockerOptions{LabelSelector: "scc.role=none"}, err: "monitoring.error.containerNotFound"},
+	}
+	for _, tc := range cases {
+		mon := base
+		opts := tc.opts
+		opts.Socket = socket
+		mon.Options.Docker = &opts
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Degraded != tc.degraded || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v degraded=%v error=%q", tc.name, res.OK, res.Degraded, res.Error)
+		}
+		if tc.ok && res.ServerVersion != "27.1.1" {
+			t.Fatalf("%s: expected engine version, got %q", tc.name, res.ServerVersion)
+		}
+		got := 0
+		if res.Details != nil {
+			got = len(res.Details.Containers)
+		}
+		if got != tc.containers {
+			t.Fatalf("%s: expected %d containers in details, got %d", tc.name, tc.containers, got)
+		}
+	}
+
+	mon := base
+	mon.Options.Docker = &store.DockerOptions{Socket: socket, Container: "siem"}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{})
+	if res.OK || res.Error != "monitoring.error.privateBlocked" {
+		t.Fatalf("expected unix socket to require private networks, got ok=%v error=%s", res.OK, res.Error)
+	}
+}
+
+func TestCheckContainerRestarts(t *testing.T) {
+	prev := &store.MonitorDetails{Containers: []store.ContainerDetails{{ID: "a", RestartCount: 1}}}
+	res := CheckResult{OK: true, Details: &store.MonitorDetails{Containers: []store.ContainerDetails{{ID: "a", RestartCount: 1}}}}
+	checkContainerRestarts(prev, &res)
+	if !res.OK {
+		t.Fatalf("unchanged restart count must not fail")
+	}
+	res.Details.Containers[0].RestartCount = 2
+	checkContainerRestarts(prev, &res)
+	if res.OK || res.Error != "monitoring.error.containerRestarted" {
+		t.Fatalf("expected restart growth to fail, got ok=%v error=%s", res.OK, res.Error)
+	}
+	res = CheckResult{OK: true, Details: &store.MonitorDetails{Containers: []store.ContainerDetails{{ID: "b", RestartCount: 5}}}}
+	checkContainerRestarts(prev, &res)
+	if !res.OK {
+		t.Fatalf("recreated container must not be compared with the old one")
+	}
+}
+
+// startFakeDocker serves a subset of the Engine API on a unix socket.
+func startFakeDocker(t *testing.T) string {
+	t.Helper()
+	started := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	inspect := map[string]map[string]any{
+		"siem":    {"Id": "1111111111111111", "Name": "/siem", "RestartCount": 0, "Config": map[string]any{"Image": "siem:1.2"}, "State": map[string]any{"Status": "running", "Running": true, "StartedAt": started, "Health": map[string]any{"Status": "healthy"}}},
+		"ids":     {"Id": "2222222222222222", "Name": "/ids", "RestartCount": 3, "Config": map[string]any{"Image": "ids:7"}, "State": map[string]any{"Status": "running", "Running": true, "StartedAt": started, "Health": map[string]any{"Status": "starting"}}},
+		"scanner": {"Id": "3333333333333333", "Name": "/scanner", "RestartCount": 0, "Config": map[string]any{"Image": "scanner:2"}, "State": map[string]any{"Status": "exited", "Running": false, "StartedAt": started}},
+		"waf":     {"Id": "4444444444444444", "Name": "/waf", "RestartCount": 1, "Config": map[string]any{"Image": "waf:3"}, "State": map[string]any{"Status": "running", "Running": true, "StartedAt": started, "Health": map[string]any{"Status": "unhealthy"}}},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"Version": "27.1.1"})
+	})
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		list := []map[string]string{}
+		if strings.Contains(r.URL.Query().Get("filters"), "scc.role=security") {
+			list = append(list, map[string]string{"Id": "siem"}, map[string]string{"Id": "waf"})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("/containers/", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/containers/"), "/json")
+		info, ok := inspect[name]
+		if !ok {
+			http.Error(w, `{"message":"No such container"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(info)
+	})
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return socket
+}
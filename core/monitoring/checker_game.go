@@ -0,0 +1,516 @@
+package monitoring
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	GameProtocolSource    = "source"
+	GameProtocolMinecraft = "minecraft"
+	GameProtocolQuake3    = "quake3"
+
+	gameMaxPlayerNames = 64
+	gameMaxPacket      = 1400
+)
+
+// gameProtocol queries a game server over a connection dialed to its query port.
+// Protocols are registered in gameProtocols by name.
+type gameProtocol interface {
+	Network() string
+	DefaultPort() int
+	Query(conn net.Conn, host string, port int) (*store.GameDetails, error)
+}
+
+var gameProtocols = map[string]gameProtocol{
+	GameProtocolSource:    sourceQuery{},
+	GameProtocolMinecraft: minecraftQuery{},
+	GameProtocolQuake3:    quake3Query{},
+}
+
+func NormalizeGameProtocol(raw string) string {
+	val := strings.ToLower(strings.TrimSpace(raw))
+	switch val {
+	case "":
+		return GameProtocolSource
+	case "a2s", "steam", "valve":
+		return GameProtocolSource
+	case "slp":
+		return GameProtocolMinecraft
+	case "q3", "quake":
+		return GameProtocolQuake3
+	}
+	if _, ok := gameProtocols[val]; ok {
+		return val
+	}
+	return ""
+}
+
+// DefaultGamePort returns the default query port of a protocol, 0 for unknown ones.
+func DefaultGamePort(protocol string) int {
+	if p, ok := gameProtocols[NormalizeGameProtocol(protocol)]; ok {
+		return p.DefaultPort()
+	}
+	return 0
+}
+
+func gameOptions(m store.Monitor) store.GameOptions {
+	var opts store.GameOptions
+	if m.Options.Game != nil {
+		opts = *m.Options.Game
+	}
+	opts.Protocol = NormalizeGameProtocol(opts.Protocol)
+	if NormalizeType(m.Type) == TypeSteam {
+		opts.Protocol = GameProtocolSource
+	}
+	if opts.MinPlayersChecks > 0 && opts.MinPlayers <= 0 {
+		opts.MinPlayers = 1
+	}
+	return opts
+}
+
+func checkGame(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := gameOptions(m)
+	proto, ok := gameProtocols[opts.Protocol]
+	if !ok {
+		return CheckResult{}, fmt.Errorf("unsupported game protocol %q", opts.Protocol)
+	}
+	host, port, err := monitorHostPort(m, proto.DefaultPort())
+	if err != nil {
+		return CheckResult{}, err
+	}
+	conn, err := dialGuarded(ctx, proto.Network(), host, port, settings, timeout)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	details, err := proto.Query(conn, host, port)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if len(details.PlayerNames) > gameMaxPlayerNames {
+		details.PlayerNames = details.PlayerNames[:gameMaxPlayerNames]
+	}
+	return CheckResult{OK: true, Details: &store.MonitorDetails{Game: details}}, nil
+}
+
+// checkGamePlayers counts consecutive checks below the player threshold and fails the result once the limit is reached.
+func checkGamePlayers(m store.Monitor, prev *store.MonitorDetails, res *CheckResult) {
+	if res.Details == nil || res.Details.Game == nil {
+		return
+	}
+	opts := gameOptions(m)
+	if opts.MinPlayersChecks <= 0 {
+		return
+	}
+	game := res.Details.Game
+	if game.Players >= opts.MinPlayers {
+		game.LowPlayerChecks = 0
+		return
+	}
+	game.LowPlayerChecks = 1
+	if prev != nil && prev.Game != nil {
+		game.LowPlayerChecks = prev.Game.LowPlayerChecks + 1
+	}
+	if game.LowPlayerChecks >= opts.MinPlayersChecks && res.OK {
+		res.OK = false
+		res.Error = "monitoring.error.gamePlayersLow"
+	}
+}
+
+type gameReader struct {
+	data []byte
+	err  error
+}
+
+func (r *gameReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = ErrProtocol
+		return nil
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *gameReader) byte() int {
+	if b := r.take(1); b != nil {
+		return int(b[0])
+	}
+	return 0
+}
+
+func (r *gameReader) uint16() int {
+	if b := r.take(2); b != nil {
+		return int(binary.LittleEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *gameReader) int32() int32 {
+	if b := r.take(4); b != nil {
+		return int32(binary.LittleEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *gameReader) cstring() string {
+	if r.err != nil {
+		return ""
+	}
+	idx := bytes.IndexByte(r.data, 0)
+	if idx < 0 {
+		r.err = ErrProtocol
+		return ""
+	}
+	out := string(r.data[:idx])
+	r.data = r.data[idx+1:]
+	return out
+}
+
+// sourceQuery implements the Valve A2S_INFO and A2S_PLAYER queries.
+type sourceQuery struct{}
+
+const (
+	a2sInfoReply      = 0x49
+	a2sGoldSrcReply   = 0x6D
+	a2sPlayerReply    = 0x44
+	a2sChallengeReply = 0x41
+	a2sSinglePacket   = -1
+	a2sSplitPacket    = -2
+	a2sTheShipAppID   = 2400
+)
+
+var a2sHeader = []byte{0xFF, 0xFF, 0xFF, 0xFF}
+
+func (sourceQuery) Network() string  { return "udp" }
+func (sourceQuery) DefaultPort() int { return 27015 }
+
+func (sourceQuery) Query(conn net.Conn, _ string, _ int) (*store.GameDetails, error) {
+	info, err := a2sRequest(conn, func(challenge []byte) []byte {
+		req := append(append([]byte{}, a2sHeader...), 'T')
+		req = append(req, "Source Engine Query\x00"...)
+		return append(req, challenge...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	details, err := parseA2SInfo(info)
+	if err != nil {
+		return nil, err
+	}
+	// The player list is optional: many servers disable A2S_PLAYER.
+	players, err := a2sRequest(conn, func(challenge []byte) []byte {
+		if challenge == nil {
+			challenge = a2sHeader
+		}
+		req := append(append([]byte{}, a2sHeader...), 'U')
+		return append(req, challenge...)
+	})
+	if err == nil {
+		details.PlayerNames = parseA2SPlayers(players)
+	}
+	return details, nil
+}
+
+// a2sRequest sends a query and repeats it with the server challenge when one is returned.
+func a2sRequest(conn net.Conn, build func(challenge []byte) []byte) ([]byte, error) {
+	var challenge []byte
+	for attempt := 0; attempt < 3; attempt++ {
+		if _, err := conn.Write(build(challenge)); err != nil {
+			return nil, err
+		}
+		payload, err := a2sRead(conn)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			return nil, ErrProtocol
+		}
+		if payload[0] != a2sChallengeReply {
+			return payload, nil
+		}
+		if len(payload) < 5 {
+			return nil, ErrProtocol
+		}
+		challenge = append([]byte{}, payload[1:5]...)
+	}
+	return nil, fmt.Errorf("%w: challenge not accepted", ErrProtocol)
+}
+
+// a2sRead returns one response without the packet header, reassembling split Source packets.
+func a2sRead(conn net.Conn) ([]byte, error) {
+	buf := make([]byte, gameMaxPacket+64)
+	var parts [][]byte
+	total, received := 0, 0
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		r := &gameReader{data: buf[:n]}
+		switch r.int32() {
+		case a2sSinglePacket:
+			if parts == nil {
+				return append([]byte{}, r.data...), nil
+			}
+			continue
+		case a2sSplitPacket:
+		default:
+			return nil, ErrProtocol
+		}
+		id := uint32(r.int32())
+		count, index := r.byte(), r.byte()
+		r.uint16()
+		if r.err != nil || count == 0 || index >= count {
+			return nil, ErrProtocol
+		}
+		if id&0x80000000 != 0 {
+			return nil, fmt.Errorf("%w: compressed split response", ErrProtocol)
+		}
+		if parts == nil {
+			parts, total = make([][]byte, count), count
+		}
+		if count != total {
+			return nil, ErrProtocol
+		}
+		if parts[index] == nil {
+			parts[index] = append([]byte{}, r.data...)
+			received++
+		}
+		if received == total {
+			r := &gameReader{data: bytes.Join(parts, nil)}
+			if r.int32() != a2sSinglePacket {
+				return nil, ErrProtocol
+			}
+			return r.data, r.err
+		}
+	}
+}
+
+func parseA2SInfo(payload []byte) (*store.GameDetails, error) {
+	r := &gameReader{data: payload}
+	details := &store.GameDetails{}
+	switch r.byte() {
+	case a2sInfoReply:
+		r.byte()
+		details.Name = r.cstring()
+		details.Map = r.cstring()
+		r.cstring()
+		details.Game = r.cstring()
+		appID := r.uint16()
+		details.Players = r.byte()
+		details.MaxPlayers = r.byte()
+		details.Bots = r.byte()
+		r.take(4)
+		if appID == a2sTheShipAppID {
+			r.take(3)
+		}
+		details.Version = r.cstring()
+	case a2sGoldSrcReply:
+		r.cstring()
+		details.Name = r.cstring()
+		details.Map = r.cstring()
+		r.cstring()
+		details.Game = r.cstring()
+		details.Players = r.byte()
+		details.MaxPlayers = r.byte()
+	default:
+		return nil, ErrProtocol
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return details, nil
+}
+
+func parseA2SPlayers(payload []byte) []string {
+	r := &gameReader{data: payload}
+	if r.byte() != a2sPlayerReply {
+		return nil
+	}
+	count := r.byte()
+	names := make([]string, 0, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		r.byte()
+		name := r.cstring()
+		r.take(8)
+		if r.err == nil && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// minecraftQuery implements the Java Edition Server List Ping.
+type minecraftQuery struct{}
+
+const minecraftMaxStatus = 64 << 10
+
+func (minecraftQuery) Network() string  { return "tcp" }
+func (minecraftQuery) DefaultPort() int { return 25565 }
+
+func (minecraftQuery) Query(conn net.Conn, host string, port int) (*store.GameDetails, error) {
+	handshake := []byte{0x00}
+	// Protocol version -1 asks the server to report its own version.
+	handshake = binary.AppendUvarint(handshake, 0xFFFFFFFF)
+	handshake = binary.AppendUvarint(handshake, uint64(len(host)))
+	handshake = append(handshake, host...)
+	handshake = binary.BigEndian.AppendUint16(handshake, uint16(port))
+	handshake = append(handshake, 0x01)
+	var out []byte
+	out = binary.AppendUvarint(out, uint64(len(handshake)))
+	out = append(out, handshake...)
+	out = append(out, 0x01, 0x00)
+	if _, err := conn.Write(out); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 || size > minecraftMaxStatus {
+		return nil, ErrProtocol
+	}
+	packet := make([]byte, size)
+	if _, err := io.ReadFull(br, packet); err != nil {
+		return nil, err
+	}
+	id, n := binary.Uvarint(packet)
+	if n <= 0 || id != 0 {
+		return nil, ErrProtocol
+	}
+	packet = packet[n:]
+	strLen, n := binary.Uvarint(packet)
+	if n <= 0 || strLen > uint64(len(packet)-n) {
+		return nil, ErrProtocol
+	}
+	var status struct {
+		Version struct {
+			Name string `json:"name"`
+		} `json:"version"`
+		Players struct {
+			Max    int `json:"max"`
+			Online int `json:"online"`
+			Sample []struct {
+				Name string `json:"name"`
+			} `json:"sample"`
+		} `json:"players"`
+		Description json.RawMessage `json:"description"`
+	}
+	if err := json.Unmarshal(packet[n:n+int(strLen)], &status); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProtocol, err)
+	}
+	details := &store.GameDetails{
+		Name:       minecraftText(status.Description),
+		Game:       "minecraft",
+		Version:    status.Version.Name,
+		Players:    status.Players.Online,
+		MaxPlayers: status.Players.Max,
+	}
+	for _, p := range status.Players.Sample {
+		details.PlayerNames = append(details.PlayerNames, p.Name)
+	}
+	return details, nil
+}
+
+// minecraftText flattens a chat component (string or {"text", "extra"}) into plain text.
+func minecraftText(raw json.RawMessage) string {
+	return stripGameColors(minecraftRawText(raw, 0))
+}
+
+func minecraftRawText(raw json.RawMessage, depth int) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var comp struct {
+		Text  string            `json:"text"`
+		Extra []json.RawMessage `json:"extra"`
+	}
+	if depth > 8 || json.Unmarshal(raw, &comp) != nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(comp.Text)
+	for _, extra := range comp.Extra {
+		b.WriteString(minecraftRawText(extra, depth+1))
+	}
+	return b.String()
+}
+
+// stripGameColors removes Minecraft (§x) and Quake (^x) color codes.
+func stripGameColors(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if (runes[i] == '§' || runes[i] == '^') && i+1 < len(runes) {
+			i++
+			continue
+		}
+		b.WriteRune(runes[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// quake3Query implements the Quake III "getstatus" query used by id Tech 3 games.
+type quake3Query struct{}
+
+func (quake3Query) Network() string  { return "udp" }
+func (quake3Query) DefaultPort() int { return 27960 }
+
+func (quake3Query) Query(conn net.Conn, _ string, _ int) (*store.GameDetails, error) {
+	if _, err := conn.Write(append(append([]byte{}, a2sHeader...), "getstatus\n"...)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 64<<10)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	prefix := append(append([]byte{}, a2sHeader...), "statusResponse\n"...)
+	if !bytes.HasPrefix(buf[:n], prefix) {
+		return nil, ErrProtocol
+	}
+	lines := strings.Split(strings.TrimRight(string(buf[len(prefix):n]), "\n"), "\n")
+	vars := map[string]string{}
+	pairs := strings.Split(strings.TrimPrefix(lines[0], "\\"), "\\")
+	for i := 0; i+1 < len(pairs); i += 2 {
+		vars[strings.ToLower(pairs[i])] = pairs[i+1]
+	}
+	details := &store.GameDetails{
+		Name:    stripGameColors(vars["sv_hostname"]),
+		Map:     vars["mapname"],
+		Game:    vars["gamename"],
+		Version: vars["version"],
+	}
+	details.MaxPlayers, _ = strconv.Atoi(vars["sv_maxclients"])
+	for _, line := range lines[1:] {
+		// Player lines are `score ping "name"`.
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		details.Players++
+		if ping, err := strconv.Atoi(fields[1]); err == nil && ping == 0 {
+			details.Bots++
+		}
+		details.PlayerNames = append(details.PlayerNames, stripGameColors(strings.Trim(fields[2], `"`)))
+	}
+	return details, nil
+}
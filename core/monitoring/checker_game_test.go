@@ -0,0 +1,193 @@
+package monitoring
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorSteam(t *testing.T) {
+	port := startFakeSourceServer(t)
+	mon := store.Monitor{Type: TypeSteam, Host: "127.0.0.1", Port: port, TimeoutSec: 2}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if !res.OK || res.Details == nil || res.Details.Game == nil {
+		t.Fatalf("expected game details, got ok=%v error=%q", res.OK, res.Error)
+	}
+	game := res.Details.Game
+	if game.Name != "SCC training range" || game.Map != "de_dust2" || game.Players != 2 || game.MaxPlayers != 16 || game.Bots != 1 {
+		t.Fatalf("unexpected info: %+v", game)
+	}
+	if game.Version != "1.38.7.9" || len(game.PlayerNames) != 2 || game.PlayerNames[1] != "blue-team" {
+		t.Fatalf("unexpected players: %+v", game)
+	}
+}
+
+func TestCheckMonitorMinecraft(t *testing.T) {
+	port := startFakeMinecraftServer(t)
+	mon := store.Monitor{Type: TypeGameDig, Host: "127.0.0.1", Port: port, TimeoutSec: 2}
+	mon.Options.Game = &store.GameOptions{Protocol: "minecraft"}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if !res.OK || res.Details == nil || res.Details.Game == nil {
+		t.Fatalf("expected game details, got ok=%v error=%q", res.OK, res.Error)
+	}
+	game := res.Details.Game
+	if game.Name != "CTF lobby" || game.Version != "1.21.1" || game.Players != 3 || game.MaxPlayers != 20 || len(game.PlayerNames) != 1 {
+		t.Fatalf("unexpected status: %+v", game)
+	}
+}
+
+func TestCheckMonitorQuake3(t *testing.T) {
+	port := startFakeUDPServer(t, func(req []byte) [][]byte {
+		if string(req) != "\xff\xff\xff\xffgetstatus\n" {
+			return nil
+		}
+		return [][]byte{[]byte("\xff\xff\xff\xffstatusResponse\n\\sv_hostname\\^1Red ^7Arena\\mapname\\q3dm17\\sv_maxclients\\8\\gamename\\baseq3\n" +
+			"12 48 \"^2alice\"\n0 0 \"bot\"\n")}
+	})
+	mon := store.Monitor{Type: TypeGameDig, Host: "127.0.0.1", Port: port, TimeoutSec: 2}
+	mon.Options.Game = &store.GameOptions{Protocol: "quake3"}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if !res.OK || res.Details == nil || res.Details.Game == nil {
+		t.Fatalf("expected game details, got ok=%v error=%q", res.OK, res.Error)
+	}
+	game := res.Details.Game
+	if game.Name != "Red Arena" || game.Map != "q3dm17" || game.Players != 2 || game.Bots != 1 || game.MaxPlayers != 8 || game.PlayerNames[0] != "alice" {
+		t.Fatalf("unexpected status: %+v", game)
+	}
+}
+
+func TestCheckGamePlayers(t *testing.T) {
+	mon := store.Monitor{Type: TypeSteam}
+	mon.Options.Game = &store.GameOptions{MinPlayersChecks: 2}
+	result := func(players int) CheckResult {
+		return CheckResult{OK: true, Details: &store.MonitorDetails{Game: &store.GameDetails{Players: players}}}
+	}
+	res := result(0)
+	checkGamePlayers(mon, nil, &res)
+	if !res.OK || res.Details.Game.LowPlayerChecks != 1 {
+		t.Fatalf("first empty check must only be counted, got ok=%v count=%d", res.OK, res.Details.Game.LowPlayerChecks)
+	}
+	prev := res.Details
+	res = result(0)
+	checkGamePlayers(mon, prev, &res)
+	if res.OK || res.Error != "monitoring.error.gamePlayersLow" {
+		t.Fatalf("expected down after two empty checks, got ok=%v error=%q", res.OK, res.Error)
+	}
+	res = result(1)
+	checkGamePlayers(mon, prev, &res)
+	if !res.OK || res.Details.Game.LowPlayerChecks != 0 {
+		t.Fatalf("players must reset the streak")
+	}
+}
+
+func startFakeUDPServer(t *testing.T, handle func(req []byte) [][]byte) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			for _, reply := range handle(append([]byte{}, buf[:n]...)) {
+				_, _ = conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// startFakeSourceServer answers A2S_INFO and A2S_PLAYER behind a challenge; the player list is split in two packets.
+func startFakeSourceServer(t *testing.T) int {
+	challenge := []byte{0x0A, 0x0B, 0x0C, 0x0D}
+	challengeReply := append(append(append([]byte{}, a2sHeader...), a2sChallengeReply), challenge...)
+	return startFakeUDPServer(t, func(req []byte) [][]byte {
+		if !bytes.HasPrefix(req, a2sHeader) || len(req) < 5 {
+			return nil
+		}
+		switch req[4] {
+		case 'T':
+			if !bytes.HasSuffix(req, challenge) {
+				return [][]byte{challengeReply}
+			}
+			info := append(append([]byte{}, a2sHeader...), a2sInfoReply, 17)
+			for _, s := range []string{"SCC training range", "de_dust2", "csgo", "Counter-Strike"} {
+				info = append(append(info, s...), 0)
+			}
+			info = binary.LittleEndian.AppendUint16(info, 730)
+			info = append(info, 2, 16, 1, 'd', 'l', 0, 1)
+			return [][]byte{append(append(info, "1.38.7.9"...), 0)}
+		case 'U':
+			if !bytes.Equal(req[5:], challenge) {
+				return [][]byte{challengeReply}
+			}
+			players := append(append([]byte{}, a2sHeader...), a2sPlayerReply, 2)
+			for i, name := range []string{"red-team", "blue-team"} {
+				players = append(append(append(players, byte(i)), name...), 0)
+				players = append(players, 5, 0, 0, 0, 0, 0, 0x80, 0x3F)
+			}
+			half := len(players) / 2
+			split := func(index int, chunk []byte) []byte {
+				pkt := []byte{0xFE, 0xFF, 0xFF, 0xFF, 0x01, 0x00, 0x00, 0x00, 2, byte(index)}
+				pkt = binary.LittleEndian.AppendUint16(pkt, 1248)
+				return append(pkt, chunk...)
+			}
+			// Parts arrive out of order.
+			return [][]byte{split(1, players[half:]), split(0, players[:half])}
+		}
+		return nil
+	})
+}
+
+func startFakeMinecraftServer(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				br := bufio.NewReader(conn)
+				for i := 0; i < 2; i++ {
+					size, err := binary.ReadUvarint(br)
+					if err != nil {
+						return
+					}
+					if _, err := io.CopyN(io.Discard, br, int64(size)); err != nil {
+						return
+					}
+				}
+				status, _ := json.Marshal(map[string]any{
+					"version":     map[string]any{"name": "1.21.1", "protocol": 767},
+					"players":     map[string]any{"max": 20, "online": 3, "sample": []map[string]string{{"name": "red-team"}}},
+					"description": map[string]any{"text": "§aCTF", "extra": []any{" lobby"}},
+				})
+				packet := []byte{0x00}
+				packet = binary.AppendUvarint(packet, uint64(len(status)))
+				packet = append(packet, status...)
+				out := binary.AppendUvarint(nil, uint64(len(packet)))
+				_, _ = conn.Write(append(out, packet...))
+			}(conn)
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
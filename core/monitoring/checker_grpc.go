@@ -0,0 +1,404 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	grpcMaxMessage      = 4 << 20
+	grpcMaxReflectFiles = 64
+
+	grpcCodeNotFound      = 5
+	grpcCodeUnimplemented = 12
+
+	grpcHealthServing        = 1
+	grpcHealthServiceUnknown = 3
+)
+
+var grpcMethodRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*\.[A-Za-z_][A-Za-z0-9_]*/[A-Za-z_][A-Za-z0-9_]*$`)
+
+// grpcReservedHeaders are set by the client itself and cannot be overridden by monitor metadata.
+var grpcReservedHeaders = map[string]bool{
+	"content-type": true, "te": true, "host": true, "connection": true,
+	"grpc-timeout": true, "grpc-encoding": true, "grpc-accept-encoding": true,
+}
+
+// grpcStatusError is a non-OK grpc-status returned by the server.
+type grpcStatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *grpcStatusError) Error() string {
+	return fmt.Sprintf("grpc status %d: %s", e.Code, e.Message)
+}
+
+func grpcStatusCode(err error) int {
+	var se *grpcStatusError
+	if errors.As(err, &se) {
+		return se.Code
+	}
+	return -1
+}
+
+// NormalizeGRPCMethod accepts "package.Service/Method" with an optional leading slash.
+func NormalizeGRPCMethod(raw string) (string, bool) {
+	val := strings.TrimPrefix(strings.TrimSpace(raw), "/")
+	if val == "" {
+		return "", true
+	}
+	if !grpcMethodRe.MatchString(val) {
+		return "", false
+	}
+	return val, true
+}
+
+func grpcOptions(m store.Monitor) store.GRPCOptions {
+	var opts store.GRPCOptions
+	if m.Options.GRPC != nil {
+		opts = *m.Options.GRPC
+	}
+	opts.Service = strings.TrimSpace(opts.Service)
+	opts.Method, _ = NormalizeGRPCMethod(opts.Method)
+	return opts
+}
+
+type grpcClient struct {
+	client   *http.Client
+	base     string
+	metadata map[string]string
+	tls      *tls.ConnectionState
+}
+
+func checkGRPCKeyword(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	u, err := parseGRPCURL(m.URL)
+	if err != nil {
+		return CheckResult{}, ErrInvalidURL
+	}
+	client, err := newGRPCClient(ctx, m, u, settings, timeout)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer client.client.CloseIdleConnections()
+	opts := grpcOptions(m)
+	var res CheckResult
+	if opts.Method != "" {
+		res, err = grpcCheckMethod(ctx, client, m, opts)
+	} else {
+		res, err = grpcCheckHealth(ctx, client, opts.Service)
+	}
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if client.tls != nil {
+		res.TLS = tlsFromState(client.tls)
+	}
+	return res, nil
+}
+
+func newGRPCClient(ctx context.Context, m store.Monitor, u *url.URL, settings store.MonitorSettings, timeout time.Duration) (*grpcClient, error) {
+	host := u.Hostname()
+	if err := guardTarget(ctx, host, settings); err != nil {
+		return nil, err
+	}
+	secure := strings.EqualFold(u.Scheme, "grpcs")
+	port := 80
+	if secure {
+		port = 443
+	}
+	if raw := u.Port(); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 65535 {
+			return nil, ErrInvalidURL
+		}
+		port = n
+	}
+	protocols := new(http.Protocols)
+	transport := &http.Transport{
+		TLSHandshakeTimeout: timeout,
+		Protocols:           protocols,
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialGuarded(ctx, network, host, port, settings, timeout)
+		},
+	}
+	scheme := "http"
+	if secure {
+		cfg, err := monitorTLSConfig(m, host)
+		if err != nil {
+			return nil, err
+		}
+		cfg.NextProtos = []string{"h2"}
+		transport.TLSClientConfig = cfg
+		protocols.SetHTTP2(true)
+		scheme = "https"
+	} else {
+		// gRPC over plaintext is HTTP/2 with prior knowledge (h2c).
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	return &grpcClient{
+		client:   &http.Client{Timeout: timeout, Transport: transport},
+		base:     scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port)),
+		metadata: m.Headers,
+	}, nil
+}
+
+// call performs a request with a single message in each direction and returns the reply.
+func (c *grpcClient) call(ctx context.Context, method string, msg []byte) ([]byte, error) {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	frame = append(frame, msg...)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/"+method, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.metadata {
+		key := strings.ToLower(strings.TrimSpace(k))
+		if key == "" || grpcReservedHeaders[key] || strings.HasPrefix(key, ":") {
+			continue
+		}
+		req.Header.Set(key, v)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.TLS != nil && c.tls == nil {
+		c.tls = resp.TLS
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: http status %d", ErrProtocol, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/grpc") {
+		return nil, fmt.Errorf("%w: content type %q", ErrProtocol, ct)
+	}
+	reply, readErr := readGRPCMessage(resp.Body)
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, grpcMaxMessage))
+	// Trailers-only responses carry the status in the headers.
+	status := resp.Trailer.Get("Grpc-Status")
+	message := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		message = resp.Header.Get("Grpc-Message")
+	}
+	if status == "" {
+		return nil, fmt.Errorf("%w: missing grpc-status", ErrProtocol)
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return nil, fmt.Errorf("%w: grpc-status %q", ErrProtocol, status)
+	}
+	if code != 0 {
+		if decoded, err := url.PathUnescape(message); err == nil {
+			message = decoded
+		}
+		return nil, &grpcStatusError{Code: code, Message: message}
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	return reply, nil
+}
+
+func readGRPCMessage(r io.Reader) ([]byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("%w: no reply message", ErrProtocol)
+	}
+	if hdr[0] != 0 {
+		return nil, fmt.Errorf("%w: compressed reply", ErrProtocol)
+	}
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if size > grpcMaxMessage {
+		return nil, fmt.Errorf("%w: reply too large", ErrProtocol)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, fmt.Errorf("%w: truncated reply", ErrProtocol)
+	}
+	return msg, nil
+}
+
+// grpcCheckHealth calls grpc.health.v1.Health/Check and expects SERVING.
+func grpcCheckHealth(ctx context.Context, c *grpcClient, service string) (CheckResult, error) {
+	var req []byte
+	if service != "" {
+		req = protoAppendString(nil, 1, service)
+	}
+	reply, err := c.call(ctx, "grpc.health.v1.Health/Check", req)
+	switch code := grpcStatusCode(err); {
+	case code == grpcCodeNotFound:
+		return CheckResult{OK: false, Error: "monitoring.error.grpcServiceUnknown"}, nil
+	case code >= 0:
+		return CheckResult{OK: false, Error: "monitoring.error.grpcCallFailed"}, nil
+	case err != nil:
+		return CheckResult{}, err
+	}
+	fields, err := protoFields(reply)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("%w: %v", ErrProtocol, err)
+	}
+	status := uint64(0)
+	for _, f := range fields {
+		if f.Num == 1 && f.Wire == protoWireVarint {
+			status = f.Value
+		}
+	}
+	switch status {
+	case grpcHealthServing:
+		return CheckResult{OK: true}, nil
+	case grpcHealthServiceUnknown:
+		return CheckResult{OK: false, Error: "monitoring.error.grpcServiceUnknown"}, nil
+	default:
+		return CheckResult{OK: false, Error: "monitoring.error.grpcNotServing"}, nil
+	}
+}
+
+// grpcCheckMethod calls a unary method described by server reflection and searches the keyword in its JSON reply.
+func grpcCheckMethod(ctx context.Context, c *grpcClient, m store.Monitor, opts store.GRPCOptions) (CheckResult, error) {
+	needle := strings.TrimSpace(m.RequestBody)
+	if needle == "" {
+		return CheckResult{OK: false, Error: "monitoring.error.keywordRequired"}, nil
+	}
+	reg, err := grpcResolve(ctx, c, opts.Method)
+	if code := grpcStatusCode(err); code == grpcCodeUnimplemented {
+		return CheckResult{OK: false, Error: "monitoring.error.grpcReflectionUnavailable"}, nil
+	} else if code == grpcCodeNotFound {
+		return CheckResult{OK: false, Error: "monitoring.error.grpcMethodNotFound"}, nil
+	} else if code >= 0 {
+		return CheckResult{OK: false, Error: "monitoring.error.grpcCallFailed"}, nil
+	} else if err != nil {
+		return CheckResult{}, err
+	}
+	method, ok := reg.methods[opts.Method]
+	if !ok || method.Streaming || reg.messages[method.Input] == nil || reg.messages[method.Output] == nil {
+		return CheckResult{OK: false, Error: "monitoring.error.grpcMethodNotFound"}, nil
+	}
+	req, err := reg.encodeJSON(method.Input, []byte(opts.Request))
+	if err != nil {
+		return CheckResult{OK: false, Error: "monitoring.error.grpcInvalidRequest"}, nil
+	}
+	reply, err := c.call(ctx, opts.Method, req)
+	if grpcStatusCode(err) >= 0 {
+		return CheckResult{OK: false, Error: "monitoring.error.grpcCallFailed"}, nil
+	} else if err != nil {
+		return CheckResult{}, err
+	}
+	body, err := reg.decodeJSON(method.Output, reply)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("%w: %v", ErrProtocol, err)
+	}
+	if !strings.Contains(string(body), needle) {
+		return CheckResult{OK: false, Error: "monitoring.error.keywordNotFound"}, nil
+	}
+	return CheckResult{OK: true}, nil
+}
+
+// grpcResolve loads the descriptors of the method's service and their dependencies via server reflection.
+// The v1 service is tried first, older servers only expose v1alpha.
+func grpcResolve(ctx context.Context, c *grpcClient, method string) (*protoRegistry, error) {
+	service := method[:strings.LastIndex(method, "/")]
+	var err error
+	for _, api := range []string{"grpc.reflection.v1.ServerReflection", "grpc.reflection.v1alpha.ServerReflection"} {
+		reg := newProtoRegistry()
+		if err = grpcReflect(ctx, c, api, reg, protoAppendString(nil, 4, service)); err == nil {
+			return reg, nil
+		}
+		if grpcStatusCode(err) != grpcCodeUnimplemented {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func grpcReflect(ctx context.Context, c *grpcClient, api string, reg *protoRegistry, req []byte) error {
+	pending := [][]byte{req}
+	for first := true; len(pending) > 0; first = false {
+		if len(reg.files) > grpcMaxReflectFiles {
+			return fmt.Errorf("%w: too many descriptor files", ErrProtocol)
+		}
+		next := pending[0]
+		pending = pending[1:]
+		reply, err := c.call(ctx, api+"/ServerReflectionInfo", next)
+		if err == nil {
+			var files [][]byte
+			if files, err = parseReflectionReply(reply); err == nil {
+				err = addReflectedFiles(reg, files, &pending)
+			}
+		}
+		// A dependency the server cannot describe only matters if the method uses its types.
+		if err != nil && (first || grpcStatusCode(err) < 0) {
+			return err
+		}
+	}
+	return nil
+}
+
+func addReflectedFiles(reg *protoRegistry, files [][]byte, pending *[][]byte) error {
+	for _, raw := range files {
+		_, deps, err := reg.addFile(raw)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrProtocol, err)
+		}
+		for _, dep := range deps {
+			if _, seen := reg.files[dep]; !seen {
+				reg.files[dep] = false
+				*pending = append(*pending, protoAppendString(nil, 3, dep))
+			}
+		}
+	}
+	return nil
+}
+
+// parseReflectionReply returns the serialized FileDescriptorProtos from a ServerReflectionResponse.
+func parseReflectionReply(reply []byte) ([][]byte, error) {
+	fields, err := protoFields(reply)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProtocol, err)
+	}
+	var files [][]byte
+	for _, f := range fields {
+		switch f.Num {
+		case 4:
+			inner, err := protoFields(f.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrProtocol, err)
+			}
+			for _, fd := range inner {
+				if fd.Num == 1 {
+					files = append(files, fd.Bytes)
+				}
+			}
+		case 7:
+			se := &grpcStatusError{}
+			inner, _ := protoFields(f.Bytes)
+			for _, e := range inner {
+				switch e.Num {
+				case 1:
+					se.Code = int(int32(e.Value))
+				case 2:
+					se.Message = string(e.Bytes)
+				}
+			}
+			return nil, se
+		}
+	}
+	return files, nil
+}
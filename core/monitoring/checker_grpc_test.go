@@ -0,0 +1,272 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorGRPCHealth(t *testing.T) {
+	addr := startFakeGRPC(t)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	auth := map[string]string{"X-Api-Key": "secret"}
+
+	cases := []struct {
+		name    string
+		service string
+		headers map[string]string
+		ok      bool
+		err     string
+	}{
+		{name: "server", headers: auth, ok: true},
+		{name: "service", service: "scc", headers: auth, ok: true},
+		{name: "not serving", service: "maint", headers: auth, err: "monitoring.error.grpcNotServing"},
+		{name: "unknown", service: "nope", headers: auth, err: "monitoring.error.grpcServiceUnknown"},
+		{name: "no metadata", service: "scc", err: "monitoring.error.grpcCallFailed"},
+	}
+	for _, tc := range cases {
+		mon := store.Monitor{Type: TypeGRPCKeyword, URL: "grpc://" + addr, TimeoutSec: 2, Headers: tc.headers}
+		mon.Options.GRPC = &store.GRPCOptions{Service: tc.service}
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v error=%q", tc.name, res.OK, res.Error)
+		}
+	}
+}
+
+func TestCheckMonitorGRPCMethod(t *testing.T) {
+	addr := startFakeGRPC(t)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+
+	cases := []struct {
+		name    string
+		method  string
+		request string
+		keyword string
+		ok      bool
+		err     string
+	}{
+		{name: "keyword", method: "/test.echo.Echo/Say", request: `{"text":"hi","count":3,"mode":"LOUD"}`, keyword: "HIHIHI", ok: true},
+		{name: "dependency types", method: "test.echo.Echo/Say", request: `{"text":"hi","count":"2"}`, keyword: `"total":"2"`, ok: true},
+		{name: "map field", method: "test.echo.Echo/Say", request: `{"text":"hi"}`, keyword: `"counts":{"x":7}`, ok: true},
+		{name: "missing keyword", method: "test.echo.Echo/Say", request: `{"text":"hi"}`, keyword: "bye", err: "monitoring.error.keywordNotFound"},
+		{name: "unknown method", method: "test.echo.Echo/Nope", keyword: "hi", err: "monitoring.error.grpcMethodNotFound"},
+		{name: "unknown service", method: "test.echo.Other/Say", keyword: "hi", err: "monitoring.error.grpcMethodNotFound"},
+		{name: "streaming", method: "test.echo.Echo/Watch", keyword: "hi", err: "monitoring.error.grpcMethodNotFound"},
+		{name: "bad request", method: "test.echo.Echo/Say", request: `{"bogus":1}`, keyword: "hi", err: "monitoring.error.grpcInvalidRequest"},
+	}
+	for _, tc := range cases {
+		mon := store.Monitor{
+			Type:        TypeGRPCKeyword,
+			URL:         "grpc://" + addr,
+			TimeoutSec:  2,
+			RequestBody: tc.keyword,
+			Headers:     map[string]string{"X-Api-Key": "secret"},
+		}
+		mon.Options.GRPC = &store.GRPCOptions{Method: tc.method, Request: tc.request}
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v error=%q", tc.name, res.OK, res.Error)
+		}
+	}
+}
+
+func TestCheckMonitorGRPCTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(fakeGRPCHandler())
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	mon := store.Monitor{
+		Type:            TypeGRPCKeyword,
+		URL:             "grpcs://" + srv.Listener.Addr().String(),
+		TimeoutSec:      2,
+		IgnoreTLSErrors: true,
+		Headers:         map[string]string{"X-Api-Key": "secret"},
+	}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if !res.OK || res.TLS == nil {
+		t.Fatalf("expected healthy TLS server with certificate info, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestNormalizeGRPCMethod(t *testing.T) {
+	if got, ok := NormalizeGRPCMethod(" /pkg.v1.Service/Get "); !ok || got != "pkg.v1.Service/Get" {
+		t.Fatalf("unexpected normalization: %q %v", got, ok)
+	}
+	for _, raw := range []string{"Service/Get", "pkg.Service", "pkg.Service/Get/x", "pkg.Service/Get Me"} {
+		if _, ok := NormalizeGRPCMethod(raw); ok {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func startFakeGRPC(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	srv := &http.Server{Handler: fakeGRPCHandler(), Protocols: protocols}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return ln.Addr().String()
+}
+
+func fakeGRPCHandler() http.Handler {
+	echoFile, commonFile := fakeEchoDescriptors()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := readGRPCMessage(r.Body)
+		if err != nil {
+			writeGRPCStatus(w, 13, "bad frame")
+			return
+		}
+		if r.Header.Get("X-Api-Key") != "secret" {
+			writeGRPCStatus(w, 16, "missing api key")
+			return
+		}
+		fields, _ := protoFields(req)
+		switch r.URL.Path {
+		case "/grpc.health.v1.Health/Check":
+			service := ""
+			for _, f := range fields {
+				if f.Num == 1 {
+					service = string(f.Bytes)
+				}
+			}
+			switch service {
+			case "", "scc":
+				writeGRPCReply(w, protoAppendVarint(nil, 1, grpcHealthServing))
+			case "maint":
+				writeGRPCReply(w, protoAppendVarint(nil, 1, 2))
+			default:
+				writeGRPCStatus(w, grpcCodeNotFound, "unknown service")
+			}
+		case "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo":
+			var file []byte
+			for _, f := range fields {
+				switch {
+				case f.Num == 4 && string(f.Bytes) == "test.echo.Echo":
+					file = echoFile
+				case f.Num == 3 && string(f.Bytes) == "common.proto":
+					file = commonFile
+				}
+			}
+			if file == nil {
+				errResp := protoAppendVarint(nil, 1, grpcCodeNotFound)
+				writeGRPCReply(w, protoAppendBytes(nil, 7, errResp))
+				return
+			}
+			writeGRPCReply(w, protoAppendBytes(nil, 4, protoAppendBytes(nil, 1, file)))
+		case "/test.echo.Echo/Say":
+			text, count, loud := "", uint64(1), false
+			for _, f := range fields {
+				switch f.Num {
+				case 1:
+					text = string(f.Bytes)
+				case 2:
+					count = f.Value
+				case 3:
+					loud = f.Value == 1
+				}
+			}
+			text = strings.Repeat(text, int(count))
+			if loud {
+				text = strings.ToUpper(text)
+			}
+			stats := protoAppendVarint(nil, 1, count)
+			stats = protoAppendString(stats, 2, "a")
+			stats = protoAppendString(stats, 2, "b")
+			entry := protoAppendVarint(protoAppendString(nil, 1, "x"), 2, 7)
+			reply := protoAppendString(nil, 1, text)
+			reply = protoAppendBytes(reply, 2, stats)
+			reply = protoAppendBytes(reply, 3, entry)
+			writeGRPCReply(w, reply)
+		default:
+			writeGRPCStatus(w, grpcCodeUnimplemented, "unknown method")
+		}
+	})
+}
+
+func writeGRPCReply(w http.ResponseWriter, msg []byte) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	frame := make([]byte, 5)
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	_, _ = w.Write(append(frame, msg...))
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+}
+
+// writeGRPCStatus sends a trailers-only response.
+func writeGRPCStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", msg)
+	w.WriteHeader(http.StatusOK)
+}
+
+func fakeProtoField(name string, num, label, typ int, typeName string) []byte {
+	b := protoAppendString(nil, 1, name)
+	b = protoAppendVarint(b, 3, uint64(num))
+	b = protoAppendVarint(b, 4, uint64(label))
+	b = protoAppendVarint(b, 5, uint64(typ))
+	if typeName != "" {
+		b = protoAppendString(b, 6, typeName)
+	}
+	return b
+}
+
+func fakeEchoDescriptors() ([]byte, []byte) {
+	const optional = 1
+	stats := protoAppendString(nil, 1, "Stats")
+	stats = protoAppendBytes(stats, 2, fakeProtoField("total", 1, optional, protoTypeInt64, ""))
+	stats = protoAppendBytes(stats, 2, fakeProtoField("tags", 2, protoLabelRepeated, protoTypeString, ""))
+	common := protoAppendString(nil, 1, "common.proto")
+	common = protoAppendString(common, 2, "test.common")
+	common = protoAppendBytes(common, 4, stats)
+
+	mode := protoAppendString(nil, 1, "Mode")
+	mode = protoAppendBytes(mode, 2, protoAppendVarint(protoAppendString(nil, 1, "PLAIN"), 2, 0))
+	mode = protoAppendBytes(mode, 2, protoAppendVarint(protoAppendString(nil, 1, "LOUD"), 2, 1))
+	req := protoAppendString(nil, 1, "EchoRequest")
+	req = protoAppendBytes(req, 2, fakeProtoField("text", 1, optional, protoTypeString, ""))
+	req = protoAppendBytes(req, 2, fakeProtoField("count", 2, optional, protoTypeInt32, ""))
+	req = protoAppendBytes(req, 2, fakeProtoField("mode", 3, optional, protoTypeEnum, ".test.echo.Mode"))
+	entry := protoAppendString(nil, 1, "CountsEntry")
+	entry = protoAppendBytes(entry, 2, fakeProtoField("key", 1, optional, protoTypeString, ""))
+	entry = protoAppendBytes(entry, 2, fakeProtoField("value", 2, optional, protoTypeInt32, ""))
+	entry = protoAppendBytes(entry, 7, protoAppendVarint(nil, 7, 1))
+	reply := protoAppendString(nil, 1, "EchoReply")
+	reply = protoAppendBytes(reply, 2, fakeProtoField("text", 1, optional, protoTypeString, ""))
+	reply = protoAppendBytes(reply, 2, fakeProtoField("stats", 2, optional, protoTypeMessage, ".test.common.Stats"))
+	reply = protoAppendBytes(reply, 2, fakeProtoField("counts", 3, protoLabelRepeated, protoTypeMessage, ".test.echo.EchoReply.CountsEntry"))
+	reply = protoAppendBytes(reply, 3, entry)
+	say := protoAppendString(nil, 1, "Say")
+	say = protoAppendString(say, 2, ".test.echo.EchoRequest")
+	say = protoAppendString(say, 3, ".test.echo.EchoReply")
+	watch := protoAppendString(nil, 1, "Watch")
+	watch = protoAppendString(watch, 2, ".test.echo.EchoRequest")
+	watch = protoAppendString(watch, 3, ".test.echo.EchoReply")
+	watch = protoAppendVarint(watch, 6, 1)
+	svc := protoAppendString(nil, 1, "Echo")
+	svc = protoAppendBytes(svc, 2, say)
+	svc = protoAppendBytes(svc, 2, watch)
+
+	echo := protoAppendString(nil, 1, "echo.proto")
+	echo = protoAppendString(echo, 2, "test.echo")
+	echo = protoAppendString(echo, 3, "common.proto")
+	echo = protoAppendBytes(echo, 4, req)
+	echo = protoAppendBytes(echo, 4, reply)
+	echo = protoAppendBytes(echo, 5, mode)
+	echo = protoAppendBytes(echo, 6, svc)
+	return echo, common
+}
@@ -0,0 +1,169 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+// Security headers policy violations; cookie violations carry the cookie name after a colon.
+const (
+	HeadersHSTSMissing           = "hsts_missing"
+	HeadersHSTSShort             = "hsts_short"
+	HeadersCSPMissing            = "csp_missing"
+	HeadersFramingAllowed        = "framing_allowed"
+	HeadersNoSniffMissing        = "nosniff_missing"
+	HeadersReferrerPolicyMissing = "referrer_policy_missing"
+	HeadersReferrerPolicyUnsafe  = "referrer_policy_unsafe"
+	HeadersCookieNotSecure       = "cookie_not_secure"
+	HeadersCookieNotHTTPOnly     = "cookie_not_httponly"
+	HeadersCookieNoSameSite      = "cookie_no_samesite"
+
+	defaultMinHSTSMaxAge = 180 * 24 * 3600
+)
+
+// headersPenalty is deducted from 100 once per violated rule, however many cookies break it.
+var headersPenalty = map[string]int{
+	HeadersHSTSMissing:           20,
+	HeadersHSTSShort:             10,
+	HeadersCSPMissing:            20,
+	HeadersFramingAllowed:        15,
+	HeadersNoSniffMissing:        10,
+	HeadersReferrerPolicyMissing: 5,
+	HeadersReferrerPolicyUnsafe:  5,
+	HeadersCookieNotSecure:       10,
+	HeadersCookieNotHTTPOnly:     5,
+	HeadersCookieNoSameSite:      5,
+}
+
+func headersOptions(m store.Monitor) store.HeadersOptions {
+	if m.Options.Headers == nil {
+		return store.HeadersOptions{}
+	}
+	return *m.Options.Headers
+}
+
+// evaluateSecurityHeaders scores the final response of an http monitor against the policy.
+func evaluateSecurityHeaders(resp *http.Response, https bool, opts store.HeadersOptions) *store.HeadersDetails {
+	violations := []string{}
+	minAge := opts.MinHSTSMaxAge
+	if minAge <= 0 {
+		minAge = defaultMinHSTSMaxAge
+	}
+	// Browsers ignore HSTS received over plain http, so such a site never has it.
+	if age, ok := hstsMaxAge(resp.Header.Get("Strict-Transport-Security")); !https || !ok {
+		violations = append(violations, HeadersHSTSMissing)
+	} else if age < minAge {
+		violations = append(violations, HeadersHSTSShort)
+	}
+	csp := resp.Header.Values("Content-Security-Policy")
+	if len(csp) == 0 {
+		violations = append(violations, HeadersCSPMissing)
+	}
+	frameOptions := strings.ToUpper(strings.TrimSpace(resp.Header.Get("X-Frame-Options")))
+	if frameOptions != "DENY" && frameOptions != "SAMEORIGIN" && !cspDirective(csp, "frame-ancestors") {
+		violations = append(violations, HeadersFramingAllowed)
+	}
+	if !strings.EqualFold(strings.TrimSpace(resp.Header.Get("X-Content-Type-Options")), "nosniff") {
+		violations = append(violations, HeadersNoSniffMissing)
+	}
+	switch referrerPolicy(resp.Header.Values("Referrer-Policy")) {
+	case "":
+		violations = append(violations, HeadersReferrerPolicyMissing)
+	case "unsafe-url", "no-referrer-when-downgrade":
+		violations = append(violations, HeadersReferrerPolicyUnsafe)
+	}
+	for _, cookie := range resp.Cookies() {
+		if !cookie.Secure {
+			violations = append(violations, HeadersCookieNotSecure+":"+cookie.Name)
+		}
+		if !cookie.HttpOnly {
+			violations = append(violations, HeadersCookieNotHTTPOnly+":"+cookie.Name)
+		}
+		// Without the attribute the cookie is sent on cross-site subrequests by older browsers.
+		if cookie.SameSite == 0 || cookie.SameSite == http.SameSiteDefaultMode {
+			violations = append(violations, HeadersCookieNoSameSite+":"+cookie.Name)
+		}
+	}
+	return &store.HeadersDetails{Score: headersScore(violations), Violations: violations}
+}
+
+func headersScore(violations []string) int {
+	score := 100
+	seen := map[string]bool{}
+	for _, v := range violations {
+		rule, _, _ := strings.Cut(v, ":")
+		if seen[rule] {
+			continue
+		}
+		seen[rule] = true
+		score -= headersPenalty[rule]
+	}
+	return max(score, 0)
+}
+
+func hstsMaxAge(header string) (int, bool) {
+	for _, part := range strings.Split(header, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		age, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`))
+		if err != nil || age <= 0 {
+			return 0, false
+		}
+		return age, true
+	}
+	return 0, false
+}
+
+func cspDirective(policies []string, directive string) bool {
+	for _, policy := range policies {
+		for _, part := range strings.Split(policy, ";") {
+			fields := strings.Fields(part)
+			if len(fields) > 0 && strings.EqualFold(fields[0], directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// referrerPolicy returns the effective policy: the last token the browser understands wins.
+func referrerPolicy(values []string) string {
+	out := ""
+	for _, value := range values {
+		for _, token := range strings.Split(value, ",") {
+			switch token = strings.ToLower(strings.TrimSpace(token)); token {
+			case "no-referrer", "no-referrer-when-downgrade", "origin", "origin-when-cross-origin",
+				"same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url":
+				out = token
+			}
+		}
+	}
+	return out
+}
+
+// detectHeadersRegression reports the violations that were not present in the previous check with the score change.
+func detectHeadersRegression(prev *store.MonitorDetails, res CheckResult) string {
+	if prev == nil || prev.Headers == nil || res.Details == nil || res.Details.Headers == nil {
+		return ""
+	}
+	before := map[string]bool{}
+	for _, v := range prev.Headers.Violations {
+		before[v] = true
+	}
+	var added []string
+	for _, v := range res.Details.Headers.Violations {
+		if !before[v] {
+			added = append(added, v)
+		}
+	}
+	if len(added) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d -> %d: %s", prev.Headers.Score, res.Details.Headers.Score, strings.Join(added, ", "))
+}
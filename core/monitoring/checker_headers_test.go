@@ -0,0 +1,86 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestEvaluateSecurityHeaders(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+	resp.Header.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+	resp.Header.Set("X-Content-Type-Options", "nosniff")
+	resp.Header.Set("Referrer-Policy", "no-referrer, strict-origin-when-cross-origin")
+	resp.Header.Add("Set-Cookie", "sid=1; Path=/; Secure; HttpOnly; SameSite=Lax")
+	got := evaluateSecurityHeaders(resp, true, store.HeadersOptions{})
+	if got.Score != 100 || len(got.Violations) != 0 {
+		t.Fatalf("expected a clean policy, got %+v", got)
+	}
+
+	resp.Header.Set("Strict-Transport-Security", "max-age=3600")
+	resp.Header.Del("Content-Security-Policy")
+	resp.Header.Set("Referrer-Policy", "unsafe-url")
+	resp.Header.Add("Set-Cookie", "theme=dark; Path=/")
+	resp.Header.Add("Set-Cookie", "lang=en; Path=/")
+	got = evaluateSecurityHeaders(resp, true, store.HeadersOptions{})
+	want := []string{
+		HeadersHSTSShort, HeadersCSPMissing, HeadersFramingAllowed, HeadersReferrerPolicyUnsafe,
+		"cookie_not_secure:theme", "cookie_not_httponly:theme", "cookie_no_samesite:theme",
+		"cookie_not_secure:lang", "cookie_not_httponly:lang", "cookie_no_samesite:lang",
+	}
+	if !reflect.DeepEqual(got.Violations, want) {
+		t.Fatalf("unexpected violations %v", got.Violations)
+	}
+	if got.Score != 30 {
+		t.Fatalf("expected each rule to count once, got score %d", got.Score)
+	}
+	if got := evaluateSecurityHeaders(resp, true, store.HeadersOptions{MinHSTSMaxAge: 60}); got.Violations[0] != HeadersCSPMissing {
+		t.Fatalf("expected a custom max-age to be accepted, got %v", got.Violations)
+	}
+	if got := evaluateSecurityHeaders(resp, false, store.HeadersOptions{}); got.Violations[0] != HeadersHSTSMissing {
+		t.Fatalf("expected HSTS over plain http to be ignored, got %v", got.Violations)
+	}
+}
+
+func TestCheckMonitorHTTPSecurityHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "same-origin")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	}))
+	defer srv.Close()
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeHTTP, URL: srv.URL, Method: http.MethodGet, TimeoutSec: 3, AllowedStatus: []string{"200-299"}}
+	mon.Options.Headers = &store.HeadersOptions{Enabled: true}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || res.Details == nil || res.Details.Headers == nil || res.Details.Headers.Score != 80 {
+		t.Fatalf("expected only HSTS to be missing, got ok=%v details=%+v", res.OK, res.Details)
+	}
+	mon.Options.Headers.MinScore = 90
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.headersScoreBelow: 80" {
+		t.Fatalf("expected the minimum score to fail the check, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestDetectHeadersRegression(t *testing.T) {
+	result := func(score int, violations ...string) CheckResult {
+		return CheckResult{Details: &store.MonitorDetails{Headers: &store.HeadersDetails{Score: score, Violations: violations}}}
+	}
+	prev := result(80, HeadersHSTSMissing).Details
+	if got := detectHeadersRegression(prev, result(100)); got != "" {
+		t.Fatalf("improvement reported as %q", got)
+	}
+	if got := detectHeadersRegression(prev, result(60, HeadersHSTSMissing, HeadersCSPMissing)); got != "80 -> 60: csp_missing" {
+		t.Fatalf("unexpected regression %q", got)
+	}
+	if got := detectHeadersRegression(nil, result(0, HeadersCSPMissing)); got != "" {
+		t.Fatalf("first check must not report a regression, got %q", got)
+	}
+}
@@ -0,0 +1,221 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	HTTPAuthNone   = "none"
+	HTTPAuthBasic  = "basic"
+	HTTPAuthBearer = "bearer"
+	HTTPAuthOAuth2 = "oauth2"
+
+	// oauth2ExpirySkew renews tokens shortly before they expire; oauth2DefaultTTL is used when expires_in is missing.
+	oauth2ExpirySkew  = 30 * time.Second
+	oauth2DefaultTTL  = 5 * time.Minute
+	oauth2MaxResponse = 64 << 10
+)
+
+// errOAuth2Token is reported when the token endpoint does not issue a usable access token.
+var errOAuth2Token = errors.New("oauth2 token request failed")
+
+type oauth2Token struct {
+	value   string
+	expires time.Time
+}
+
+// oauth2Tokens caches client-credentials tokens between checks; the key covers every input of the token request.
+var oauth2Tokens = struct {
+	sync.Mutex
+	items map[string]oauth2Token
+}{items: map[string]oauth2Token{}}
+
+// NormalizeHTTPAuth returns the canonical auth mode or "" when it is not supported.
+func NormalizeHTTPAuth(raw string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(raw)); mode {
+	case "", HTTPAuthNone:
+		return HTTPAuthNone
+	case HTTPAuthBasic, HTTPAuthBearer, HTTPAuthOAuth2:
+		return mode
+	}
+	return ""
+}
+
+func httpOptions(m store.Monitor) store.HTTPOptions {
+	if m.Options.HTTP == nil {
+		return store.HTTPOptions{}
+	}
+	return *m.Options.HTTP
+}
+
+// httpMonitorTransport builds a per-check transport with the monitor TLS material, proxy and guarded dialer.
+func httpMonitorTransport(m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (*http.Transport, error) {
+	// ServerName stays empty so redirects and the token endpoint are verified against their own host.
+	tlsCfg, err := monitorTLSConfig(m, "")
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	transport.DialContext = guardedDialer(settings, timeout).DialContext
+	if proxy := strings.TrimSpace(httpOptions(m).Proxy); proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, ErrInvalidURL
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if transport.Proxy != nil {
+		transport.Proxy = guardedProxy(settings, transport.Proxy)
+	}
+	return transport, nil
+}
+
+// httpMonitorClient wraps httpMonitorTransport in a client that checks every redirect hop against the network policy.
+func httpMonitorClient(m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (*http.Client, error) {
+	transport, err := httpMonitorTransport(m, settings, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: guardRedirect(settings),
+	}, nil
+}
+
+// applyHTTPAuth sets the Authorization header; a non-empty key is a soft failure to report as the check error.
+func applyHTTPAuth(ctx context.Context, req *http.Request, m store.Monitor, settings store.MonitorSettings, client *http.Client) (string, error) {
+	opts := httpOptions(m)
+	creds := m.Credentials
+	if creds == nil {
+		creds = &store.MonitorCredentials{}
+	}
+	switch NormalizeHTTPAuth(opts.Auth) {
+	case HTTPAuthBasic:
+		if creds.Username == "" {
+			return "monitoring.error.credentialsRequired", nil
+		}
+		req.SetBasicAuth(creds.Username, creds.Password)
+	case HTTPAuthBearer:
+		if creds.Token == "" {
+			return "monitoring.error.credentialsRequired", nil
+		}
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	case HTTPAuthOAuth2:
+		if opts.OAuth2 == nil || creds.Secret == "" {
+			return "monitoring.error.credentialsRequired", nil
+		}
+		token, err := oauth2ClientToken(ctx, m, *opts.OAuth2, creds.Secret, settings, client)
+		if errors.Is(err, errOAuth2Token) {
+			return "monitoring.error.oauthTokenFailed", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return "", nil
+}
+
+func oauth2CacheKey(m store.Monitor, opts store.OAuth2Options, secret string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		opts.TokenURL, opts.ClientID, secret, strings.Join(opts.Scopes, " "),
+	}, "\x00")))
+	return strconv.FormatInt(m.ID, 10) + ":" + hex.EncodeToString(sum[:])
+}
+
+// forgetOAuth2Token drops the cached token, e.g. after the target rejected it.
+func forgetOAuth2Token(m store.Monitor) {
+	opts := httpOptions(m)
+	if opts.OAuth2 == nil || m.Credentials == nil {
+		return
+	}
+	key := oauth2CacheKey(m, *opts.OAuth2, m.Credentials.Secret)
+	oauth2Tokens.Lock()
+	delete(oauth2Tokens.items, key)
+	oauth2Tokens.Unlock()
+}
+
+// oauth2ClientToken returns a cached access token or requests a new one with the client-credentials grant.
+func oauth2ClientToken(ctx context.Context, m store.Monitor, opts store.OAuth2Options, secret string, settings store.MonitorSettings, client *http.Client) (string, error) {
+	key := oauth2CacheKey(m, opts, secret)
+	now := time.Now()
+	oauth2Tokens.Lock()
+	cached, ok := oauth2Tokens.items[key]
+	oauth2Tokens.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.value, nil
+	}
+	tokenURL, err := parseMonitorURL(opts.TokenURL)
+	if err != nil {
+		return "", err
+	}
+	if err := guardTarget(ctx, tokenURL.Hostname(), settings); err != nil {
+		return "", err
+	}
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(opts.Scopes) > 0 {
+		form.Set("scope", strings.Join(opts.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// RFC 6749 2.3.1: the client credentials are form-encoded before they go into the basic auth header.
+	req.SetBasicAuth(url.QueryEscape(opts.ClientID), url.QueryEscape(secret))
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, oauth2MaxResponse))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errOAuth2Token
+	}
+	var parsed struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(payload, &parsed); err != nil || parsed.AccessToken == "" {
+		return "", errOAuth2Token
+	}
+	if parsed.TokenType != "" && !strings.EqualFold(parsed.TokenType, "bearer") {
+		return "", errOAuth2Token
+	}
+	ttl := oauth2DefaultTTL
+	if parsed.ExpiresIn > 0 {
+		ttl = time.Duration(parsed.ExpiresIn) * time.Second
+	}
+	if ttl > 2*oauth2ExpirySkew {
+		ttl -= oauth2ExpirySkew
+	}
+	oauth2Tokens.Lock()
+	for k, tok := range oauth2Tokens.items {
+		if now.After(tok.expires) {
+			delete(oauth2Tokens.items, k)
+		}
+	}
+	oauth2Tokens.items[key] = oauth2Token{value: parsed.AccessToken, expires: now.Add(ttl)}
+	oauth2Tokens.Unlock()
+	return parsed.AccessToken, nil
+}
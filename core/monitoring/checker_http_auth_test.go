@@ -0,0 +1,183 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorHTTPClientCertificate(t *testing.T) {
+	clientCertPEM, clientKeyPEM, clientCert := testClientCertificatePEM(t)
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	defer srv.Close()
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+
+	mon := store.Monitor{Type: TypeHTTP, URL: srv.URL, Method: "GET", AllowedStatus: []string{"200-299"}, TimeoutSec: 3}
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.tlsHandshakeFailed" {
+		t.Fatalf("expected untrusted server certificate, got ok=%v error=%q", res.OK, res.Error)
+	}
+	mon.Options.TLS = &store.TLSOptions{CACert: caPEM}
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK {
+		t.Fatalf("expected the server to require a client certificate")
+	}
+	mon.Credentials = &store.MonitorCredentials{ClientCert: clientCertPEM, ClientKey: clientKeyPEM}
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK || res.TLS == nil {
+		t.Fatalf("expected mutual TLS to succeed, got error=%q", res.Error)
+	}
+}
+
+func TestCheckMonitorHTTPBasicAndBearer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if (ok && user == "monitor" && pass == "s3cret") || r.Header.Get("Authorization") == "Bearer static-token" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+
+	cases := []struct {
+		auth  string
+		creds *store.MonitorCredentials
+		ok    bool
+		err   string
+	}{
+		{auth: "basic", creds: &store.MonitorCredentials{Username: "monitor", Password: "s3cret"}, ok: true},
+		{auth: "basic", creds: &store.MonitorCredentials{Username: "monitor", Password: "wrong"}, err: "status_401"},
+		{auth: "bearer", creds: &store.MonitorCredentials{Token: "static-token"}, ok: true},
+		{auth: "bearer", err: "monitoring.error.credentialsRequired"},
+	}
+	for _, tc := range cases {
+		mon := store.Monitor{Type: TypeHTTP, URL: srv.URL, Method: "GET", AllowedStatus: []string{"200-299"}, TimeoutSec: 3, Credentials: tc.creds}
+		mon.Options.HTTP = &store.HTTPOptions{Auth: tc.auth}
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v error=%q", tc.auth, res.OK, res.Error)
+		}
+	}
+}
+
+func TestCheckMonitorHTTPOAuth2ClientCredentials(t *testing.T) {
+	var issued atomic.Int32
+	var reject atomic.Bool
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "health:read" ||
+			id != "scc-monitor" || secret != "client-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token-` + strconv.Itoa(int(n)) + `","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokens.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reject.Load() || r.Header.Get("Authorization") != "Bearer token-"+strconv.Itoa(int(issued.Load())) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	mon := store.Monitor{ID: 9101, Type: TypeHTTP, URL: api.URL, Method: "GET", AllowedStatus: []string{"200-299"}, TimeoutSec: 3,
+		Credentials: &store.MonitorCredentials{Secret: "client-secret"}}
+	mon.Options.HTTP = &store.HTTPOptions{Auth: "oauth2", OAuth2: &store.OAuth2Options{TokenURL: tokens.URL, ClientID: "scc-monitor", Scopes: []string{"health:read"}}}
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+
+	for i := 0; i < 2; i++ {
+		if res := CheckMonitor(context.Background(), mon, settings); !res.OK {
+			t.Fatalf("check %d: expected ok, got error=%q", i, res.Error)
+		}
+	}
+	if issued.Load() != 1 {
+		t.Fatalf("expected the token to be cached, issued %d", issued.Load())
+	}
+	reject.Store(true)
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK {
+		t.Fatalf("expected rejected token")
+	}
+	reject.Store(false)
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK || issued.Load() != 2 {
+		t.Fatalf("expected a new token after 401, got ok=%v issued=%d", res.OK, issued.Load())
+	}
+
+	mon.Credentials = &store.MonitorCredentials{Secret: "wrong"}
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.oauthTokenFailed" {
+		t.Fatalf("expected oauthTokenFailed, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorHTTPProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !r.URL.IsAbs() || r.URL.Host != "backend.internal:8080" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	mon := store.Monitor{Type: TypeHTTP, URL: "http://backend.internal:8080/health", Method: "GET", AllowedStatus: []string{"200-299"}, TimeoutSec: 3}
+	mon.Options.HTTP = &store.HTTPOptions{Proxy: proxy.URL}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3})
+	if !res.OK {
+		t.Fatalf("expected request through the proxy, got error=%q", res.Error)
+	}
+}
+
+func testClientCertificatePEM(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "scc-monitor"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("cert: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM), cert
+}
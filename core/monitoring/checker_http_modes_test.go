@@ -94,6 +94,83 @@ func TestCheckMonitorHTTPJSONValidation(t *testing.T) {
 	}
 }
 
+func TestCheckMonitorHTTPJSONAssertions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"ok","db":{"lag":3,"role":"primary"},"nodes":["a","b"],"build.id":"v1.4.2"}`))
+	}))
+	defer srv.Close()
+
+	check := func(assertions ...store.JSONAssertion) CheckResult {
+		mon := store.Monitor{
+			Type:          "http_json",
+			URL:           srv.URL,
+			Method:        "GET",
+			AllowedStatus: []string{"200-299"},
+			TimeoutSec:    3,
+		}
+		mon.Options.JSON = &store.JSONOptions{Assertions: assertions}
+		return CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3})
+	}
+
+	res := check(
+		store.JSONAssertion{Path: "$.status", Op: "==", Value: `"ok"`},
+		store.JSONAssertion{Path: "$.db.lag", Op: "<", Value: "30"},
+		store.JSONAssertion{Path: "$.db.role", Op: "!=", Value: "replica"},
+		store.JSONAssertion{Path: "$.nodes", Op: "contains", Value: "b"},
+		store.JSONAssertion{Path: "$['build.id']", Op: "regex", Value: `^v1\.\d+`},
+		store.JSONAssertion{Path: "$.nodes[-1]", Op: "exists"},
+	)
+	if !res.OK {
+		t.Fatalf("expected assertions to pass, got error=%s", res.Error)
+	}
+	if res.Values["$.db.lag"] != 3 || len(res.Values) != 1 {
+		t.Fatalf("expected numeric value to be extracted, got %v", res.Values)
+	}
+
+	res = check(
+		store.JSONAssertion{Path: "$.status", Op: "==", Value: "ok"},
+		store.JSONAssertion{Path: "$.db.lag", Op: ">", Value: "10"},
+		store.JSONAssertion{Path: "$.cache.hits", Op: "exists"},
+	)
+	want := "monitoring.error.jsonAssertionFailed: $.db.lag > 10; $.cache.hits exists (missing)"
+	if res.OK || res.Error != want {
+		t.Fatalf("expected %q, got ok=%v error=%q", want, res.OK, res.Error)
+	}
+	if res.Values["$.db.lag"] != 3 {
+		t.Fatalf("values must be kept for failed checks, got %v", res.Values)
+	}
+}
+
+func TestValidJSONAssertions(t *testing.T) {
+	valid := []store.JSONAssertion{{Path: "$.a[0]['b c']", Op: "==", Value: "1"}, {Path: "$", Op: "exists"}}
+	if !ValidJSONAssertions(valid) {
+		t.Fatalf("expected assertions to be valid")
+	}
+	for _, a := range []store.JSONAssertion{
+		{Path: "status", Op: "=="},
+		{Path: "$..a", Op: "exists"},
+		{Path: "$.a[x]", Op: "exists"},
+		{Path: "$.a", Op: "~"},
+		{Path: "$.a", Op: "<", Value: "ten"},
+		{Path: "$.a", Op: "regex", Value: "("},
+	} {
+		if ValidJSONAssertions([]store.JSONAssertion{a}) {
+			t.Fatalf("expected %+v to be rejected", a)
+		}
+	}
+}
+
+func TestNotifyErrorTextWithDetail(t *testing.T) {
+	got := notifyErrorText("en", "monitoring.error.jsonAssertionFailed: $.db.lag < 30")
+	if got != "JSON assertion failed: $.db.lag < 30" {
+		t.Fatalf("unexpected notification text %q", got)
+	}
+	if got := notifyErrorText("ru", "monitoring.error.oauthTokenFailed"); got == "monitoring.error.oauthTokenFailed" {
+		t.Fatalf("expected translated text")
+	}
+}
+
 func TestCheckMonitorHTTPRedirectFollowDefault(t *testing.T) {
 	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -128,6 +205,9 @@ func TestCheckMonitorPingByHost(t *testing.T) {
 		Host:       "localhost",
 		TimeoutSec: 2,
 	}, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if res.Error == "monitoring.error.icmpUnavailable" {
+		t.Skip("icmp sockets are not permitted in this environment")
+	}
 	if !res.OK {
 		t.Fatalf("expected ping-like check ok, got error=%s", res.Error)
 	}
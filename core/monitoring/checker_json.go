@@ -0,0 +1,255 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	maxJSONAssertions      = 32
+	maxJSONAssertionErrLen = 1024
+)
+
+var errInvalidJSONPath = errors.New("invalid json path")
+
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// NormalizeJSONOp returns the canonical assertion operator or "" when it is not supported.
+func NormalizeJSONOp(raw string) string {
+	op := strings.ToLower(strings.TrimSpace(raw))
+	switch op {
+	case "==", "!=", "<", ">", "contains", "regex", "exists":
+		return op
+	case "=":
+		return "=="
+	}
+	return ""
+}
+
+// ValidJSONAssertions reports whether every assertion has a parsable path, a known operator and a usable value.
+func ValidJSONAssertions(list []store.JSONAssertion) bool {
+	if len(list) > maxJSONAssertions {
+		return false
+	}
+	for _, a := range list {
+		if len(a.Path) > 256 || len(a.Value) > 1024 {
+			return false
+		}
+		if _, err := parseJSONPath(a.Path); err != nil {
+			return false
+		}
+		switch NormalizeJSONOp(a.Op) {
+		case "":
+			return false
+		case "<", ">":
+			if _, err := strconv.ParseFloat(strings.TrimSpace(a.Value), 64); err != nil {
+				return false
+			}
+		case "regex":
+			if _, err := regexp.Compile(a.Value); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseJSONPath supports the subset used by health endpoints: $.a.b, $.items[0], $['key.with.dots'].
+func parseJSONPath(raw string) ([]jsonPathStep, error) {
+	path := strings.TrimSpace(raw)
+	if !strings.HasPrefix(path, "$") {
+		return nil, errInvalidJSONPath
+	}
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, errInvalidJSONPath
+			}
+			steps = append(steps, jsonPathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errInvalidJSONPath
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, errInvalidJSONPath
+			}
+			steps = append(steps, jsonPathStep{index: idx, isIndex: true})
+		default:
+			return nil, errInvalidJSONPath
+		}
+	}
+	return steps, nil
+}
+
+func lookupJSONPath(doc any, steps []jsonPathStep) (any, bool) {
+	cur := doc
+	for _, step := range steps {
+		if step.isIndex {
+			arr, ok := cur.([]any)
+			if !ok {
+				return nil, false
+			}
+			idx := step.index
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+			cur = arr[idx]
+			continue
+		}
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[step.key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// evaluateJSONAssertions returns the numeric values found at the assertion paths and every failed assertion.
+// Actual values are left out of the failures so a monitor that stays down keeps the same error between checks.
+func evaluateJSONAssertions(doc any, list []store.JSONAssertion) (map[string]float64, []string) {
+	var values map[string]float64
+	var failures []string
+	for _, a := range list {
+		path := strings.TrimSpace(a.Path)
+		op := NormalizeJSONOp(a.Op)
+		steps, err := parseJSONPath(path)
+		if err != nil || op == "" {
+			failures = append(failures, fmt.Sprintf("%s %s: invalid assertion", path, a.Op))
+			continue
+		}
+		actual, found := lookupJSONPath(doc, steps)
+		if num, ok := actual.(float64); ok && found {
+			if values == nil {
+				values = map[string]float64{}
+			}
+			values[path] = num
+		}
+		if jsonAssertionHolds(op, a.Value, actual, found) {
+			continue
+		}
+		desc := path + " " + op
+		if op != "exists" {
+			desc += " " + strings.TrimSpace(a.Value)
+		}
+		if !found {
+			desc += " (missing)"
+		}
+		failures = append(failures, desc)
+	}
+	return values, failures
+}
+
+func jsonAssertionHolds(op, raw string, actual any, found bool) bool {
+	if op == "exists" {
+		return found
+	}
+	if !found {
+		return false
+	}
+	expected := parseJSONLiteral(raw)
+	switch op {
+	case "==":
+		return reflect.DeepEqual(actual, expected)
+	case "!=":
+		return !reflect.DeepEqual(actual, expected)
+	case "<", ">":
+		num, ok := actual.(float64)
+		limit, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if !ok || err != nil {
+			return false
+		}
+		if op == "<" {
+			return num < limit
+		}
+		return num > limit
+	case "contains":
+		switch v := actual.(type) {
+		case string:
+			return strings.Contains(v, jsonScalarText(expected))
+		case []any:
+			for _, item := range v {
+				if reflect.DeepEqual(item, expected) {
+					return true
+				}
+			}
+		case map[string]any:
+			_, ok := v[jsonScalarText(expected)]
+			return ok
+		}
+		return false
+	case "regex":
+		re, err := regexp.Compile(raw)
+		if err != nil {
+			return false
+		}
+		switch actual.(type) {
+		case string, float64, bool:
+			return re.MatchString(jsonScalarText(actual))
+		}
+		return false
+	}
+	return false
+}
+
+// parseJSONLiteral decodes raw as a JSON value; anything that is not valid JSON is taken as a bare string.
+func parseJSONLiteral(raw string) any {
+	trimmed := strings.TrimSpace(raw)
+	var val any
+	if err := json.Unmarshal([]byte(trimmed), &val); err != nil {
+		return trimmed
+	}
+	return val
+}
+
+func jsonScalarText(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+func jsonAssertionError(failures []string) string {
+	msg := "monitoring.error.jsonAssertionFailed: " + strings.Join(failures, "; ")
+	if len(msg) > maxJSONAssertionErrLen {
+		msg = strings.ToValidUTF8(msg[:maxJSONAssertionErrLen-3], "") + "..."
+	}
+	return msg
+}
@@ -0,0 +1,525 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	KafkaSASLPlain       = "PLAIN"
+	KafkaSASLSCRAMSHA256 = "SCRAM-SHA-256"
+	KafkaSASLSCRAMSHA512 = "SCRAM-SHA-512"
+)
+
+const (
+	kafkaAPIProduce          = 0
+	kafkaAPIMetadata         = 3
+	kafkaAPISaslHandshake    = 17
+	kafkaAPIApiVersions      = 18
+	kafkaAPISaslAuthenticate = 36
+
+	kafkaProduceVersion          = 3
+	kafkaMetadataVersion         = 4
+	kafkaSaslHandshakeVersion    = 1
+	kafkaSaslAuthenticateVersion = 0
+
+	kafkaErrLeaderNotAvailable   = 5
+	kafkaErrNotLeader            = 6
+	kafkaErrUnknownTopic         = 3
+	kafkaErrSASLAuthFailed       = 58
+	kafkaErrUnsupportedMechanism = 33
+	kafkaMaxResponseSize         = 16 * 1024 * 1024
+	kafkaClientID                = "berkut-scc"
+	kafkaProduceTimeoutMs        = 5000
+)
+
+var errKafkaUnsupportedMechanism = errors.New("sasl mechanism not enabled on broker")
+
+// NormalizeKafkaSASLMechanism returns the canonical mechanism name; an empty name disables SASL.
+func NormalizeKafkaSASLMechanism(raw string) (string, bool) {
+	switch mechanism := strings.ToUpper(strings.TrimSpace(raw)); mechanism {
+	case "", KafkaSASLPlain, KafkaSASLSCRAMSHA256, KafkaSASLSCRAMSHA512:
+		return mechanism, true
+	default:
+		return "", false
+	}
+}
+
+func kafkaOptions(m store.Monitor) store.KafkaOptions {
+	var opts store.KafkaOptions
+	if m.Options.Kafka != nil {
+		opts = *m.Options.Kafka
+	}
+	opts.SASLMechanism, _ = NormalizeKafkaSASLMechanism(opts.SASLMechanism)
+	opts.Topic = strings.TrimSpace(opts.Topic)
+	return opts
+}
+
+type kafkaConn struct {
+	conn          net.Conn
+	correlationID int32
+	versions      map[int16][2]int16
+}
+
+type kafkaBroker struct {
+	host string
+	port int
+}
+
+type kafkaPartition struct {
+	errorCode int16
+	leader    int32
+}
+
+func checkKafka(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := kafkaOptions(m)
+	if opts.SASLMechanism != "" && monitorUsername(m) == "" {
+		return CheckResult{OK: false, Error: "monitoring.error.credentialsRequired"}, nil
+	}
+	host, port, err := monitorHostPort(m, DefaultPortForType(TypeKafkaProducer))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res := CheckResult{OK: true}
+	client, soft, err := openKafkaConn(ctx, m, settings, timeout, opts, host, port, &res)
+	if err != nil || soft != "" {
+		return CheckResult{OK: false, Error: soft}, err
+	}
+	defer client.conn.Close()
+	brokers, partitions, topicErr, err := client.metadata(opts.Topic)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if len(brokers) == 0 {
+		return CheckResult{}, ErrProtocol
+	}
+	if opts.Topic == "" {
+		return res, nil
+	}
+	if topicErr != 0 {
+		if topicErr == kafkaErrLeaderNotAvailable {
+			return CheckResult{OK: false, Error: "monitoring.error.kafkaLeaderUnavailable"}, nil
+		}
+		return CheckResult{OK: false, Error: "monitoring.error.kafkaTopicUnavailable"}, nil
+	}
+	part, ok := partitions[opts.Partition]
+	if !ok {
+		return CheckResult{OK: false, Error: "monitoring.error.kafkaTopicUnavailable"}, nil
+	}
+	leader, ok := brokers[part.leader]
+	if part.errorCode == kafkaErrLeaderNotAvailable || part.leader < 0 || !ok {
+		return CheckResult{OK: false, Error: "monitoring.error.kafkaLeaderUnavailable"}, nil
+	}
+	if !opts.Produce {
+		return res, nil
+	}
+	producer := client
+	if leader.host != host || leader.port != port {
+		// The advertised leader address comes from the broker, so it goes through the same guard.
+		var leaderRes CheckResult
+		producer, soft, err = openKafkaConn(ctx, m, settings, timeout, opts, leader.host, leader.port, &leaderRes)
+		if err != nil || soft != "" {
+			return CheckResult{OK: false, Error: soft}, err
+		}
+		defer producer.conn.Close()
+	}
+	code, err := producer.produce(opts.Topic, opts.Partition)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	switch code {
+	case 0:
+		return res, nil
+	case kafkaErrLeaderNotAvailable, kafkaErrNotLeader:
+		return CheckResult{OK: false, Error: "monitoring.error.kafkaLeaderUnavailable"}, nil
+	default:
+		return CheckResult{OK: false, Error: "monitoring.error.kafkaProduceFailed"}, nil
+	}
+}
+
+// openKafkaConn dials a broker, negotiates API versions and authenticates. A non-empty
+// second return value is an error key for a soft failure.
+func openKafkaConn(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration, opts store.KafkaOptions, host string, port int, res *CheckResult) (*kafkaConn, string, error) {
+	conn, err := dialGuarded(ctx, "tcp", host, port, settings, timeout)
+	if err != nil {
+		return nil, "", err
+	}
+	if opts.TLS {
+		tlsConn, err := upgradeTLS(ctx, conn, m, host, res)
+		if err != nil {
+			conn.Close()
+			return nil, "", err
+		}
+		conn = tlsConn
+	}
+	client := &kafkaConn{conn: conn}
+	soft, err := client.handshake(opts.SASLMechanism, monitorUsername(m), monitorPassword(m))
+	if err != nil || soft != "" {
+		conn.Close()
+		return nil, soft, err
+	}
+	return client, "", nil
+}
+
+func (c *kafkaConn) handshake(mechanism, user, password string) (string, error) {
+	if err := c.apiVersions(); err != nil {
+		return "", err
+	}
+	for _, api := range [][2]int16{{kafkaAPIMetadata, kafkaMetadataVersion}, {kafkaAPIProduce, kafkaProduceVersion}} {
+		if !c.supports(api[0], api[1]) {
+			return "", ErrProtocol
+		}
+	}
+	if mechanism == "" {
+		return "", nil
+	}
+	err := c.authenticate(mechanism, user, password)
+	switch {
+	case errors.Is(err, errAuthRejected):
+		return "monitoring.error.authFailed", nil
+	case errors.Is(err, errKafkaUnsupportedMechanism):
+		return "monitoring.error.kafkaSaslUnsupported", nil
+	}
+	return "", err
+}
+
+func (c *kafkaConn) apiVersions() error {
+	resp, err := c.roundTrip(kafkaAPIApiVersions, 0, nil)
+	if err != nil {
+		return err
+	}
+	r := &kafkaReader{data: resp}
+	if code := r.int16(); code != 0 {
+		return ErrProtocol
+	}
+	count := r.arrayLen()
+	c.versions = make(map[int16][2]int16, count)
+	for i := 0; i < count && r.err == nil; i++ {
+		key := r.int16()
+		c.versions[key] = [2]int16{r.int16(), r.int16()}
+	}
+	return r.err
+}
+
+func (c *kafkaConn) supports(api, version int16) bool {
+	r, ok := c.versions[api]
+	return ok && r[0] <= version && version <= r[1]
+}
+
+func (c *kafkaConn) authenticate(mechanism, user, password string) error {
+	if !c.supports(kafkaAPISaslHandshake, kafkaSaslHandshakeVersion) || !c.supports(kafkaAPISaslAuthenticate, kafkaSaslAuthenticateVersion) {
+		return ErrProtocol
+	}
+	resp, err := c.roundTrip(kafkaAPISaslHandshake, kafkaSaslHandshakeVersion, appendKafkaString(nil, mechanism))
+	if err != nil {
+		return err
+	}
+	r := &kafkaReader{data: resp}
+	switch code := r.int16(); code {
+	case 0:
+	case kafkaErrUnsupportedMechanism:
+		return errKafkaUnsupportedMechanism
+	default:
+		return ErrProtocol
+	}
+	if mechanism == KafkaSASLPlain {
+		_, err := c.saslAuthenticate([]byte("\x00" + user + "\x00" + password))
+		return err
+	}
+	var newHash func() hash.Hash = sha256.New
+	if mechanism == KafkaSASLSCRAMSHA512 {
+		newHash = sha512.New
+	}
+	scram, err := newSCRAMClient(newHash, user, password)
+	if err != nil {
+		return err
+	}
+	serverFirst, err := c.saslAuthenticate(scram.clientFirst())
+	if err != nil {
+		return err
+	}
+	final, err := scram.clientFinal(serverFirst)
+	if err != nil {
+		return err
+	}
+	serverFinal, err := c.saslAuthenticate(final)
+	if err != nil {
+		return err
+	}
+	return scram.verifyServerFinal(serverFinal)
+}
+
+func (c *kafkaConn) saslAuthenticate(payload []byte) ([]byte, error) {
+	resp, err := c.roundTrip(kafkaAPISaslAuthenticate, kafkaSaslAuthenticateVersion, appendKafkaBytes(nil, payload))
+	if err != nil {
+		return nil, err
+	}
+	r := &kafkaReader{data: resp}
+	code := r.int16()
+	msg := r.nullableString()
+	out := r.bytes()
+	if r.err != nil {
+		return nil, r.err
+	}
+	switch code {
+	case 0:
+		return out, nil
+	case kafkaErrSASLAuthFailed:
+		return nil, fmt.Errorf("%w: %s", errAuthRejected, msg)
+	default:
+		return nil, ErrProtocol
+	}
+}
+
+// metadata returns brokers by node id and, for a topic, its partitions and topic-level error code.
+func (c *kafkaConn) metadata(topic string) (map[int32]kafkaBroker, map[int32]kafkaPartition, int16, error) {
+	var body []byte
+	if topic == "" {
+		body = binary.BigEndian.AppendUint32(body, 0)
+	} else {
+		body = binary.BigEndian.AppendUint32(body, 1)
+		body = appendKafkaString(body, topic)
+	}
+	body = append(body, 0)
+	resp, err := c.roundTrip(kafkaAPIMetadata, kafkaMetadataVersion, body)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	r := &kafkaReader{data: resp}
+	r.int32()
+	brokers := map[int32]kafkaBroker{}
+	for i, n := 0, r.arrayLen(); i < n && r.err == nil; i++ {
+		id := r.int32()
+		host := r.string()
+		port := int(r.int32())
+		r.nullableString()
+		brokers[id] = kafkaBroker{host: host, port: port}
+	}
+	r.nullableString()
+	r.int32()
+	partitions := map[int32]kafkaPartition{}
+	var topicErr int16
+	for i, n := 0, r.arrayLen(); i < n && r.err == nil; i++ {
+		code := r.int16()
+		name := r.string()
+		r.int8()
+		for j, pn := 0, r.arrayLen(); j < pn && r.err == nil; j++ {
+			part := kafkaPartition{errorCode: r.int16()}
+			index := r.int32()
+			part.leader = r.int32()
+			r.skipInt32Array()
+			r.skipInt32Array()
+			if name == topic {
+				partitions[index] = part
+			}
+		}
+		if name == topic {
+			topicErr = code
+		}
+	}
+	if r.err != nil {
+		return nil, nil, 0, r.err
+	}
+	return brokers, partitions, topicErr, nil
+}
+
+// produce writes one canary record with a v2 record batch and returns the partition error code.
+func (c *kafkaConn) produce(topic string, partition int32) (int16, error) {
+	batch := kafkaRecordBatch([]byte("berkut-scc canary "+time.Now().UTC().Format(time.RFC3339)), time.Now())
+	var body []byte
+	body = binary.BigEndian.AppendUint16(body, 0xffff)
+	body = binary.BigEndian.AppendUint16(body, 1)
+	body = binary.BigEndian.AppendUint32(body, kafkaProduceTimeoutMs)
+	body = binary.BigEndian.AppendUint32(body, 1)
+	body = appendKafkaString(body, topic)
+	body = binary.BigEndian.AppendUint32(body, 1)
+	body = binary.BigEndian.AppendUint32(body, uint32(partition))
+	body = appendKafkaBytes(body, batch)
+	resp, err := c.roundTrip(kafkaAPIProduce, kafkaProduceVersion, body)
+	if err != nil {
+		return 0, err
+	}
+	r := &kafkaReader{data: resp}
+	for i, n := 0, r.arrayLen(); i < n && r.err == nil; i++ {
+		r.string()
+		for j, pn := 0, r.arrayLen(); j < pn && r.err == nil; j++ {
+			index := r.int32()
+			code := r.int16()
+			r.int64()
+			r.int64()
+			if r.err == nil && index == partition {
+				return code, nil
+			}
+		}
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, ErrProtocol
+}
+
+func kafkaRecordBatch(value []byte, now time.Time) []byte {
+	var record []byte
+	record = append(record, 0)
+	record = binary.AppendVarint(record, 0)
+	record = binary.AppendVarint(record, 0)
+	record = binary.AppendVarint(record, -1)
+	record = binary.AppendVarint(record, int64(len(value)))
+	record = append(record, value...)
+	record = binary.AppendVarint(record, 0)
+
+	var tail []byte
+	ts := uint64(now.UnixMilli())
+	tail = binary.BigEndian.AppendUint16(tail, 0)
+	tail = binary.BigEndian.AppendUint32(tail, 0)
+	tail = binary.BigEndian.AppendUint64(tail, ts)
+	tail = binary.BigEndian.AppendUint64(tail, ts)
+	tail = binary.BigEndian.AppendUint64(tail, 0xffffffffffffffff)
+	tail = binary.BigEndian.AppendUint16(tail, 0xffff)
+	tail = binary.BigEndian.AppendUint32(tail, 0xffffffff)
+	tail = binary.BigEndian.AppendUint32(tail, 1)
+	tail = binary.AppendVarint(tail, int64(len(record)))
+	tail = append(tail, record...)
+
+	var batch []byte
+	batch = binary.BigEndian.AppendUint64(batch, 0)
+	batch = binary.BigEndian.AppendUint32(batch, uint32(4+1+4+len(tail)))
+	batch = binary.BigEndian.AppendUint32(batch, 0xffffffff)
+	batch = append(batch, 2)
+	batch = binary.BigEndian.AppendUint32(batch, crc32.Checksum(tail, crc32.MakeTable(crc32.Castagnoli)))
+	return append(batch, tail...)
+}
+
+func (c *kafkaConn) roundTrip(api, version int16, body []byte) ([]byte, error) {
+	c.correlationID++
+	var req []byte
+	req = binary.BigEndian.AppendUint32(req, 0)
+	req = binary.BigEndian.AppendUint16(req, uint16(api))
+	req = binary.BigEndian.AppendUint16(req, uint16(version))
+	req = binary.BigEndian.AppendUint32(req, uint32(c.correlationID))
+	req = appendKafkaString(req, kafkaClientID)
+	req = append(req, body...)
+	binary.BigEndian.PutUint32(req, uint32(len(req)-4))
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, err
+	}
+	size := int(int32(binary.BigEndian.Uint32(header)))
+	if size < 4 || size > kafkaMaxResponseSize || int32(binary.BigEndian.Uint32(header[4:])) != c.correlationID {
+		return nil, ErrProtocol
+	}
+	resp := make([]byte, size-4)
+	if _, err := io.ReadFull(c.conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func appendKafkaString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+func appendKafkaBytes(buf, b []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+	return append(buf, b...)
+}
+
+// kafkaReader decodes non-flexible Kafka protocol fields; the first error sticks.
+type kafkaReader struct {
+	data []byte
+	err  error
+}
+
+func (r *kafkaReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = ErrProtocol
+		return nil
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *kafkaReader) int8() int8 {
+	if b := r.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (r *kafkaReader) int16() int16 {
+	if b := r.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int32() int32 {
+	if b := r.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int64() int64 {
+	if b := r.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) arrayLen() int {
+	n := int(r.int32())
+	if n < 0 {
+		return 0
+	}
+	if n > len(r.data) {
+		r.err = ErrProtocol
+		return 0
+	}
+	return n
+}
+
+func (r *kafkaReader) string() string {
+	return string(r.take(int(r.int16())))
+}
+
+func (r *kafkaReader) nullableString() string {
+	n := int(r.int16())
+	if n < 0 {
+		return ""
+	}
+	return string(r.take(n))
+}
+
+func (r *kafkaReader) bytes() []byte {
+	n := int(r.int32())
+	if n < 0 {
+		return nil
+	}
+	return bytes.Clone(r.take(n))
+}
+
+func (r *kafkaReader) skipInt32Array() {
+	r.take(r.arrayLen() * 4)
+}
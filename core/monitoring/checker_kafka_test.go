@@ -0,0 +1,162 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorKafka(t *testing.T) {
+	host, port := startFakeKafka(t, "probe", "secret")
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeKafkaProducer, Host: host, Port: port, TimeoutSec: 2}
+
+	if res := CheckMonitor(context.Background(), base, settings); !res.OK {
+		t.Fatalf("expected metadata check ok, got %s", res.Error)
+	}
+
+	base.Credentials = &store.MonitorCredentials{Username: "probe", Password: "secret"}
+	cases := []struct {
+		name string
+		opts store.KafkaOptions
+		ok   bool
+		err  string
+	}{
+		{name: "produce", opts: store.KafkaOptions{SASLMechanism: "plain", Topic: "canary", Produce: true}, ok: true},
+		{name: "leader unavailable", opts: store.KafkaOptions{SASLMechanism: "PLAIN", Topic: "canary", Partition: 1}, err: "monitoring.error.kafkaLeaderUnavailable"},
+		{name: "missing topic", opts: store.KafkaOptions{SASLMechanism: "PLAIN", Topic: "missing"}, err: "monitoring.error.kafkaTopicUnavailable"},
+		{name: "unsupported mechanism", opts: store.KafkaOptions{SASLMechanism: "SCRAM-SHA-512"}, err: "monitoring.error.kafkaSaslUnsupported"},
+	}
+	for _, tc := range cases {
+		mon := base
+		opts := tc.opts
+		mon.Options.Kafka = &opts
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v error=%q", tc.name, res.OK, res.Error)
+		}
+	}
+
+	base.Credentials = &store.MonitorCredentials{Username: "probe", Password: "wrong"}
+	base.Options.Kafka = &store.KafkaOptions{SASLMechanism: "PLAIN"}
+	if res := CheckMonitor(context.Background(), base, settings); res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%s", res.OK, res.Error)
+	}
+}
+
+// startFakeKafka serves a single broker (node 1) hosting topic "canary" with a healthy
+// partition 0 and a leaderless partition 1. Produce requests must carry a valid v2 batch CRC.
+func startFakeKafka(t *testing.T, user, password string) (string, int) {
+	return startFakeServer(t, func(conn net.Conn) {
+		for {
+			header := make([]byte, 4)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			req := make([]byte, binary.BigEndian.Uint32(header))
+			if _, err := io.ReadFull(conn, req); err != nil {
+				return
+			}
+			r := &kafkaReader{data: req}
+			api := r.int16()
+			r.int16()
+			correlation := r.int32()
+			r.nullableString()
+			var resp []byte
+			switch api {
+			case kafkaAPIApiVersions:
+				resp = binary.BigEndian.AppendUint16(resp, 0)
+				resp = binary.BigEndian.AppendUint32(resp, 5)
+				for _, v := range [][3]uint16{{0, 3, 9}, {3, 0, 12}, {17, 0, 1}, {18, 0, 3}, {36, 0, 2}} {
+					resp = binary.BigEndian.AppendUint16(resp, v[0])
+					resp = binary.BigEndian.AppendUint16(resp, v[1])
+					resp = binary.BigEndian.AppendUint16(resp, v[2])
+				}
+			case kafkaAPISaslHandshake:
+				code := uint16(0)
+				if r.string() != KafkaSASLPlain {
+					code = kafkaErrUnsupportedMechanism
+				}
+				resp = binary.BigEndian.AppendUint16(resp, code)
+				resp = binary.BigEndian.AppendUint32(resp, 1)
+				resp = appendKafkaString(resp, KafkaSASLPlain)
+			case kafkaAPISaslAuthenticate:
+				code := uint16(0)
+				if string(r.bytes()) != "\x00"+user+"\x00"+password {
+					code = kafkaErrSASLAuthFailed
+				}
+				resp = binary.BigEndian.AppendUint16(resp, code)
+				resp = binary.BigEndian.AppendUint16(resp, 0xffff)
+				resp = appendKafkaBytes(resp, nil)
+			case kafkaAPIMetadata:
+				self := conn.LocalAddr().(*net.TCPAddr)
+				var topics []string
+				for i, n := 0, r.arrayLen(); i < n; i++ {
+					topics = append(topics, r.string())
+				}
+				resp = binary.BigEndian.AppendUint32(resp, 0)
+				resp = binary.BigEndian.AppendUint32(resp, 1)
+				resp = binary.BigEndian.AppendUint32(resp, 1)
+				resp = appendKafkaString(resp, self.IP.String())
+				resp = binary.BigEndian.AppendUint32(resp, uint32(self.Port))
+				resp = binary.BigEndian.AppendUint16(resp, 0xffff)
+				resp = binary.BigEndian.AppendUint16(resp, 0xffff)
+				resp = binary.BigEndian.AppendUint32(resp, 1)
+				resp = binary.BigEndian.AppendUint32(resp, uint32(len(topics)))
+				for _, topic := range topics {
+					if topic != "canary" {
+						resp = binary.BigEndian.AppendUint16(resp, kafkaErrUnknownTopic)
+						resp = appendKafkaString(resp, topic)
+						resp = append(resp, 0)
+						resp = binary.BigEndian.AppendUint32(resp, 0)
+						continue
+					}
+					resp = binary.BigEndian.AppendUint16(resp, 0)
+					resp = appendKafkaString(resp, topic)
+					resp = append(resp, 0)
+					resp = binary.BigEndian.AppendUint32(resp, 2)
+					for _, p := range [][3]int32{{0, 0, 1}, {kafkaErrLeaderNotAvailable, 1, -1}} {
+						resp = binary.BigEndian.AppendUint16(resp, uint16(p[0]))
+						resp = binary.BigEndian.AppendUint32(resp, uint32(p[1]))
+						resp = binary.BigEndian.AppendUint32(resp, uint32(p[2]))
+						resp = binary.BigEndian.AppendUint32(resp, 0)
+						resp = binary.BigEndian.AppendUint32(resp, 0)
+					}
+				}
+			case kafkaAPIProduce:
+				r.nullableString()
+				r.int16()
+				r.int32()
+				r.arrayLen()
+				topic := r.string()
+				r.arrayLen()
+				partition := r.int32()
+				batch := r.bytes()
+				code := uint16(0)
+				if len(batch) < 21 || batch[16] != 2 || binary.BigEndian.Uint32(batch[17:21]) != crc32.Checksum(batch[21:], crc32.MakeTable(crc32.Castagnoli)) {
+					code = 2
+				}
+				resp = binary.BigEndian.AppendUint32(resp, 1)
+				resp = appendKafkaString(resp, topic)
+				resp = binary.BigEndian.AppendUint32(resp, 1)
+				resp = binary.BigEndian.AppendUint32(resp, uint32(partition))
+				resp = binary.BigEndian.AppendUint16(resp, code)
+				resp = binary.BigEndian.AppendUint64(resp, 0)
+				resp = binary.BigEndian.AppendUint64(resp, 0xffffffffffffffff)
+				resp = binary.BigEndian.AppendUint32(resp, 0)
+			default:
+				return
+			}
+			out := binary.BigEndian.AppendUint32(nil, uint32(len(resp)+4))
+			out = binary.BigEndian.AppendUint32(out, uint32(correlation))
+			if _, err := conn.Write(append(out, resp...)); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,445 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	LDAPScopeBase = "base"
+	LDAPScopeOne  = "one"
+	LDAPScopeSub  = "sub"
+)
+
+const (
+	ldapDefaultFilter  = "(objectClass=*)"
+	ldapStartTLSOID    = "1.3.6.1.4.1.1466.20037"
+	ldapMaxMessages    = 10000
+	ldapMaxFilterLen   = 4096
+	ldapMaxFilterDepth = 16
+
+	ldapOpBindRequest     = 0x60
+	ldapOpBindResponse    = 0x61
+	ldapOpUnbindRequest   = 0x42
+	ldapOpSearchRequest   = 0x63
+	ldapOpSearchEntry     = 0x64
+	ldapOpSearchDone      = 0x65
+	ldapOpSearchReference = 0x73
+	ldapOpExtendedRequest = 0x77
+	ldapOpExtendedResp    = 0x78
+
+	ldapResultSuccess            = 0
+	ldapResultSizeLimitExceeded  = 4
+	ldapResultInvalidCredentials = 49
+)
+
+var errInvalidLDAPFilter = errors.New("invalid ldap filter")
+
+// DefaultLDAPPort returns 636 for LDAPS and 389 otherwise. LDAP uses the TLS modes of mail monitors.
+func DefaultLDAPPort(tlsMode string) int {
+	if NormalizeMailTLS(tlsMode) == MailTLSImplicit {
+		return 636
+	}
+	return DefaultPortForType(TypeLDAP)
+}
+
+// NormalizeLDAPScope returns base, one or sub, or an empty string for unknown values.
+func NormalizeLDAPScope(raw string) string {
+	switch scope := strings.ToLower(strings.TrimSpace(raw)); scope {
+	case "", LDAPScopeSub, "subtree":
+		return LDAPScopeSub
+	case LDAPScopeBase:
+		return LDAPScopeBase
+	case LDAPScopeOne, "onelevel":
+		return LDAPScopeOne
+	default:
+		return ""
+	}
+}
+
+// ValidLDAPFilter reports whether raw is an RFC 4515 search filter this checker can encode.
+func ValidLDAPFilter(raw string) bool {
+	_, err := encodeLDAPFilter(raw)
+	return err == nil
+}
+
+func ldapOptions(m store.Monitor) store.LDAPOptions {
+	var opts store.LDAPOptions
+	if m.Options.LDAP != nil {
+		opts = *m.Options.LDAP
+	}
+	opts.TLS = NormalizeMailTLS(opts.TLS)
+	if opts.TLS == "" {
+		opts.TLS = MailTLSStartTLS
+	}
+	opts.BaseDN = strings.TrimSpace(opts.BaseDN)
+	opts.Filter = strings.TrimSpace(opts.Filter)
+	if opts.Filter == "" {
+		opts.Filter = ldapDefaultFilter
+	}
+	opts.Scope = NormalizeLDAPScope(opts.Scope)
+	if opts.Scope == "" {
+		opts.Scope = LDAPScopeSub
+	}
+	if opts.MinEntries < 0 {
+		opts.MinEntries = 0
+	}
+	return opts
+}
+
+type ldapConn struct {
+	conn  net.Conn
+	r     *bufio.Reader
+	msgID int
+}
+
+// checkLDAP binds anonymously or with the monitor credentials and runs the optional search.
+// The bind round trip is reported as the latency.
+func checkLDAP(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := ldapOptions(m)
+	filter, err := encodeLDAPFilter(opts.Filter)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	conn, host, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultLDAPPort(opts.TLS))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	res := CheckResult{OK: true}
+	if opts.TLS == MailTLSImplicit {
+		if conn, err = upgradeTLS(ctx, conn, m, host, &res); err != nil {
+			return CheckResult{}, err
+		}
+	}
+	client := &ldapConn{conn: conn, r: bufio.NewReader(conn)}
+	if opts.TLS == MailTLSStartTLS {
+		code, err := client.extended(ldapStartTLSOID)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		if code != ldapResultSuccess {
+			res.OK = false
+			res.Error = "monitoring.error.tlsRequired"
+			return res, nil
+		}
+		tlsConn, err := upgradeTLS(ctx, conn, m, host, &res)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		client = &ldapConn{conn: tlsConn, r: bufio.NewReader(tlsConn), msgID: client.msgID}
+	}
+	user := monitorUsername(m)
+	if user != "" && opts.TLS == MailTLSNone {
+		res.OK = false
+		res.Error = "monitoring.error.tlsRequired"
+		return res, nil
+	}
+	start := time.Now()
+	code, err := client.bind(user, monitorPassword(m))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res.LatencyMs = max(int(time.Since(start).Milliseconds()), 1)
+	switch {
+	case code == ldapResultInvalidCredentials:
+		res.OK = false
+		res.Error = "monitoring.error.authFailed"
+		return res, nil
+	case code != ldapResultSuccess:
+		res.OK = false
+		res.Error = "monitoring.error.ldapError: " + strconv.Itoa(code)
+		return res, nil
+	}
+	if opts.BaseDN != "" {
+		entries, code, err := client.search(opts.BaseDN, opts.Scope, filter, opts.MinEntries)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		res.Values = map[string]float64{"entries": float64(entries)}
+		switch {
+		case code != ldapResultSuccess && code != ldapResultSizeLimitExceeded:
+			res.OK = false
+			res.Error = "monitoring.error.ldapError: " + strconv.Itoa(code)
+		case entries < opts.MinEntries:
+			res.OK = false
+			res.Error = "monitoring.error.ldapTooFewEntries: " + strconv.Itoa(entries)
+		}
+	}
+	client.unbind()
+	return res, nil
+}
+
+func (c *ldapConn) send(op []byte) (int, error) {
+	c.msgID++
+	_, err := c.conn.Write(berTLV(berTagSequence, berInt(berTagInteger, c.msgID), op))
+	return c.msgID, err
+}
+
+// read returns the next protocol op addressed to id; unsolicited notifications (id 0) are skipped.
+func (c *ldapConn) read(id int) (berElement, error) {
+	for i := 0; i < ldapMaxMessages; i++ {
+		msg, err := readBER(c.r)
+		if err != nil {
+			return berElement{}, err
+		}
+		if msg.tag != berTagSequence {
+			return berElement{}, ErrProtocol
+		}
+		parts, err := berChildren(msg.content)
+		if err != nil || len(parts) < 2 {
+			return berElement{}, ErrProtocol
+		}
+		msgID, err := berIntValue(parts[0])
+		if err != nil {
+			return berElement{}, err
+		}
+		if msgID == id {
+			return parts[1], nil
+		}
+	}
+	return berElement{}, ErrProtocol
+}
+
+func (c *ldapConn) bind(dn, password string) (int, error) {
+	id, err := c.send(berTLV(ldapOpBindRequest,
+		berInt(berTagInteger, 3),
+		berString(berTagOctetString, dn),
+		berString(0x80, password),
+	))
+	if err != nil {
+		return 0, err
+	}
+	return c.expect(id, ldapOpBindResponse)
+}
+
+func (c *ldapConn) extended(oid string) (int, error) {
+	id, err := c.send(berTLV(ldapOpExtendedRequest, berString(0x80, oid)))
+	if err != nil {
+		return 0, err
+	}
+	return c.expect(id, ldapOpExtendedResp)
+}
+
+// search counts the entries below base; it asks for no attributes and at most limit entries.
+func (c *ldapConn) search(base, scope string, filter []byte, limit int) (int, int, error) {
+	scopes := map[string]int{LDAPScopeBase: 0, LDAPScopeOne: 1, LDAPScopeSub: 2}
+	id, err := c.send(berTLV(ldapOpSearchRequest,
+		berString(berTagOctetString, base),
+		berInt(berTagEnumerated, scopes[scope]),
+		berInt(berTagEnumerated, 0),
+		berInt(berTagInteger, max(limit, 1)),
+		berInt(berTagInteger, 0),
+		berBool(false),
+		filter,
+		berTLV(berTagSequence, berString(berTagOctetString, "1.1")),
+	))
+	if err != nil {
+		return 0, 0, err
+	}
+	entries := 0
+	for i := 0; i < ldapMaxMessages; i++ {
+		op, err := c.read(id)
+		if err != nil {
+			return 0, 0, err
+		}
+		switch op.tag {
+		case ldapOpSearchEntry:
+			entries++
+		case ldapOpSearchReference:
+		case ldapOpSearchDone:
+			code, err := parseLDAPResult(op)
+			return entries, code, err
+		default:
+			return 0, 0, ErrProtocol
+		}
+	}
+	return 0, 0, ErrProtocol
+}
+
+func (c *ldapConn) unbind() {
+	_, _ = c.send(berTLV(ldapOpUnbindRequest))
+}
+
+func (c *ldapConn) expect(id int, tag byte) (int, error) {
+	op, err := c.read(id)
+	if err != nil {
+		return 0, err
+	}
+	if op.tag != tag {
+		return 0, ErrProtocol
+	}
+	return parseLDAPResult(op)
+}
+
+// parseLDAPResult returns the resultCode of an LDAPResult (RFC 4511 section 4.1.9).
+func parseLDAPResult(op berElement) (int, error) {
+	parts, err := berChildren(op.content)
+	if err != nil || len(parts) < 3 || parts[0].tag != berTagEnumerated {
+		return 0, ErrProtocol
+	}
+	code, err := berIntValue(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	return code, nil
+}
+
+// encodeLDAPFilter encodes an RFC 4515 filter string: &, |, !, equality, presence,
+// substrings, >=, <= and ~= with \XX escapes. Extensible matches are not supported.
+func encodeLDAPFilter(raw string) ([]byte, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > ldapMaxFilterLen {
+		return nil, errInvalidLDAPFilter
+	}
+	out, rest, err := parseLDAPFilter(raw, 0)
+	if err != nil || rest != "" {
+		return nil, errInvalidLDAPFilter
+	}
+	return out, nil
+}
+
+func parseLDAPFilter(s string, depth int) ([]byte, string, error) {
+	if depth > ldapMaxFilterDepth || !strings.HasPrefix(s, "(") || len(s) < 3 {
+		return nil, "", errInvalidLDAPFilter
+	}
+	s = s[1:]
+	switch s[0] {
+	case '&', '|':
+		tag := byte(0xa0)
+		if s[0] == '|' {
+			tag = 0xa1
+		}
+		s = s[1:]
+		var children [][]byte
+		for strings.HasPrefix(s, "(") {
+			child, rest, err := parseLDAPFilter(s, depth+1)
+			if err != nil {
+				return nil, "", err
+			}
+			children = append(children, child)
+			s = rest
+		}
+		if len(children) == 0 || !strings.HasPrefix(s, ")") {
+			return nil, "", errInvalidLDAPFilter
+		}
+		return berTLV(tag, children...), s[1:], nil
+	case '!':
+		child, rest, err := parseLDAPFilter(s[1:], depth+1)
+		if err != nil || !strings.HasPrefix(rest, ")") {
+			return nil, "", errInvalidLDAPFilter
+		}
+		return berTLV(0xa2, child), rest[1:], nil
+	}
+	end := strings.IndexAny(s, "()")
+	if end < 0 || s[end] != ')' {
+		return nil, "", errInvalidLDAPFilter
+	}
+	item, err := encodeLDAPFilterItem(s[:end])
+	if err != nil {
+		return nil, "", err
+	}
+	return item, s[end+1:], nil
+}
+
+func encodeLDAPFilterItem(item string) ([]byte, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, errInvalidLDAPFilter
+	}
+	attr, value := item[:eq], item[eq+1:]
+	tag := byte(0xa3)
+	switch attr[len(attr)-1] {
+	case '>':
+		tag, attr = 0xa5, attr[:len(attr)-1]
+	case '<':
+		tag, attr = 0xa6, attr[:len(attr)-1]
+	case '~':
+		tag, attr = 0xa8, attr[:len(attr)-1]
+	}
+	if !validLDAPAttribute(attr) {
+		return nil, errInvalidLDAPFilter
+	}
+	if tag == 0xa3 && value == "*" {
+		return berString(0x87, attr), nil
+	}
+	if tag == 0xa3 && strings.Contains(value, "*") {
+		return encodeLDAPSubstrings(attr, value)
+	}
+	unescaped, err := unescapeLDAPValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return berTLV(tag, berString(berTagOctetString, attr), berString(berTagOctetString, unescaped)), nil
+}
+
+func encodeLDAPSubstrings(attr, value string) ([]byte, error) {
+	parts := strings.Split(value, "*")
+	var subs [][]byte
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		unescaped, err := unescapeLDAPValue(part)
+		if err != nil {
+			return nil, err
+		}
+		tag := byte(0x81)
+		switch i {
+		case 0:
+			tag = 0x80
+		case len(parts) - 1:
+			tag = 0x82
+		}
+		subs = append(subs, berString(tag, unescaped))
+	}
+	if len(subs) == 0 {
+		return nil, errInvalidLDAPFilter
+	}
+	return berTLV(0xa4, berString(berTagOctetString, attr), berTLV(berTagSequence, subs...)), nil
+}
+
+func unescapeLDAPValue(raw string) (string, error) {
+	if !strings.Contains(raw, "\\") {
+		return raw, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' {
+			b.WriteByte(raw[i])
+			continue
+		}
+		if i+2 >= len(raw) {
+			return "", errInvalidLDAPFilter
+		}
+		decoded, err := hex.DecodeString(raw[i+1 : i+3])
+		if err != nil {
+			return "", errInvalidLDAPFilter
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
+
+func validLDAPAttribute(attr string) bool {
+	if attr == "" {
+		return false
+	}
+	for _, r := range attr {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == ';':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package monitoring
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func ldapTestResponse(id int, tag byte, code int) []byte {
+	return berTLV(berTagSequence, berInt(berTagInteger, id),
+		berTLV(tag, berInt(berTagEnumerated, code), berString(berTagOctetString, ""), berString(berTagOctetString, "")))
+}
+
+// startFakeLDAP serves StartTLS, simple binds for cn=svc or anonymous, and searches returning entries results.
+func startFakeLDAP(t *testing.T, entries int) (string, int) {
+	cert := testServerCertificate(t, "ldap.test")
+	return startFakeServer(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := readBER(r)
+			if err != nil {
+				return
+			}
+			parts, err := berChildren(msg.content)
+			if err != nil || len(parts) < 2 {
+				return
+			}
+			id, _ := berIntValue(parts[0])
+			op := parts[1]
+			switch op.tag {
+			case ldapOpExtendedRequest:
+				_, _ = conn.Write(ldapTestResponse(id, ldapOpExtendedResp, ldapResultSuccess))
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+				if tlsConn.Handshake() != nil {
+					return
+				}
+				conn, r = tlsConn, bufio.NewReader(tlsConn)
+			case ldapOpBindRequest:
+				fields, _ := berChildren(op.content)
+				dn, password := string(fields[1].content), string(fields[2].content)
+				code := ldapResultInvalidCredentials
+				if dn == "" || (dn == "cn=svc,dc=example,dc=org" && password == "s3cret") {
+					code = ldapResultSuccess
+				}
+				_, _ = conn.Write(ldapTestResponse(id, ldapOpBindResponse, code))
+			case ldapOpSearchRequest:
+				for i := 0; i < entries; i++ {
+					_, _ = conn.Write(berTLV(berTagSequence, berInt(berTagInteger, id),
+						berTLV(ldapOpSearchEntry, berString(berTagOctetString, "cn=svc,dc=example,dc=org"), berTLV(berTagSequence))))
+				}
+				_, _ = conn.Write(ldapTestResponse(id, ldapOpSearchDone, ldapResultSuccess))
+			default:
+				return
+			}
+		}
+	})
+}
+
+func TestCheckMonitorLDAP(t *testing.T) {
+	host, port := startFakeLDAP(t, 1)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeLDAP, Host: host, Port: port, TimeoutSec: 3, IgnoreTLSErrors: true,
+		Credentials: &store.MonitorCredentials{Username: "cn=svc,dc=example,dc=org", Password: "s3cret"}}
+	mon.Options.LDAP = &store.LDAPOptions{BaseDN: "dc=example,dc=org", Filter: "(&(objectClass=user)(sAMAccountName=svc))", MinEntries: 1}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || res.TLS == nil || res.TLS.CommonName != "ldap.test" || res.Values["entries"] != 1 || res.LatencyMs <= 0 {
+		t.Fatalf("expected StartTLS bind and search to pass, got ok=%v error=%q values=%v", res.OK, res.Error, res.Values)
+	}
+
+	mon.Options.LDAP.MinEntries = 2
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.ldapTooFewEntries: 1" {
+		t.Fatalf("expected too few entries, got ok=%v error=%q", res.OK, res.Error)
+	}
+
+	mon.Credentials.Password = "wrong"
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected invalid credentials, got ok=%v error=%q", res.OK, res.Error)
+	}
+
+	mon.Options.LDAP = &store.LDAPOptions{TLS: MailTLSNone}
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.tlsRequired" {
+		t.Fatalf("expected a plaintext simple bind to be refused, got ok=%v error=%q", res.OK, res.Error)
+	}
+	mon.Credentials = nil
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK || res.TLS != nil {
+		t.Fatalf("expected anonymous plaintext bind to pass, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestEncodeLDAPFilter(t *testing.T) {
+	cases := map[string]string{
+		"(cn=Babs Jensen)":      "a3110402636e040b42616273204a656e73656e",
+		"(objectClass=*)":       "870b6f626a656374436c617373",
+		"(sAMAccountName=svc*)": "a417040e73414d4163636f756e744e616d6530058003737663",
+		"(!(cn=a\\2ab))":        "a20ba3090402636e0403612a62",
+	}
+	for filter, want := range cases {
+		got, err := encodeLDAPFilter(filter)
+		if err != nil {
+			t.Fatalf("%s: %v", filter, err)
+		}
+		if hex.EncodeToString(got) != want {
+			t.Fatalf("%s: got %x, want %s", filter, got, want)
+		}
+	}
+	and, err := encodeLDAPFilter("(&(objectClass=user)(|(cn=a)(cn=b)))")
+	if err != nil || and[0] != 0xa0 || !bytes.Contains(and, []byte{0xa1}) {
+		t.Fatalf("unexpected and/or encoding %x: %v", and, err)
+	}
+	for _, bad := range []string{"", "cn=x", "(cn=x", "(&)", "(cn=\\zz)", "(=x)", "(c n=x)", "(cn=x))"} {
+		if ValidLDAPFilter(bad) {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
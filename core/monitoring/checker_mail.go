@@ -0,0 +1,477 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	MailTLSStartTLS = "starttls"
+	MailTLSImplicit = "tls"
+	MailTLSNone     = "none"
+
+	defaultMailHelo = "localhost"
+	// maxMailLines bounds multi-line replies (IMAP untagged data, POP3 CAPA) read before the final line.
+	maxMailLines   = 256
+	maxMailBanner  = 128
+	mailAuthPlain  = "PLAIN"
+	mailAuthLogin  = "LOGIN"
+	smtpReplyError = 400
+)
+
+// NormalizeMailTLS returns the effective TLS mode, or an empty string for unknown values.
+func NormalizeMailTLS(raw string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(raw)); mode {
+	case "", MailTLSStartTLS:
+		return MailTLSStartTLS
+	case MailTLSImplicit, MailTLSNone:
+		return mode
+	default:
+		return ""
+	}
+}
+
+// DefaultMailPort returns the standard port of the mail protocol for the TLS mode.
+func DefaultMailPort(kind, tlsMode string) int {
+	implicit := NormalizeMailTLS(tlsMode) == MailTLSImplicit
+	switch NormalizeType(kind) {
+	case TypeSMTP:
+		if implicit {
+			return 465
+		}
+		return 25
+	case TypeIMAP:
+		if implicit {
+			return 993
+		}
+		return 143
+	case TypePOP3:
+		if implicit {
+			return 995
+		}
+		return 110
+	}
+	return 0
+}
+
+// ValidMailAddress reports whether raw is a bare address usable in MAIL FROM/RCPT TO.
+func ValidMailAddress(raw string) bool {
+	addr, err := mail.ParseAddress(raw)
+	return err == nil && addr.Name == "" && addr.Address == strings.TrimSpace(raw) && !strings.ContainsAny(raw, "<>\r\n")
+}
+
+func mailOptions(m store.Monitor) store.MailOptions {
+	var opts store.MailOptions
+	if m.Options.Mail != nil {
+		opts = *m.Options.Mail
+	}
+	opts.TLS = NormalizeMailTLS(opts.TLS)
+	if opts.TLS == "" {
+		opts.TLS = MailTLSStartTLS
+	}
+	opts.Helo = strings.TrimSpace(opts.Helo)
+	if opts.Helo == "" {
+		opts.Helo = defaultMailHelo
+	}
+	opts.MailFrom = strings.TrimSpace(opts.MailFrom)
+	opts.RcptTo = strings.TrimSpace(opts.RcptTo)
+	return opts
+}
+
+// mailSession is a line-based session that can be upgraded to TLS in place.
+type mailSession struct {
+	conn net.Conn
+	tp   *textproto.Conn
+	tls  bool
+}
+
+func newMailSession(conn net.Conn) *mailSession {
+	_, isTLS := conn.(*tls.Conn)
+	return &mailSession{conn: conn, tp: textproto.NewConn(conn), tls: isTLS}
+}
+
+func (s *mailSession) upgrade(ctx context.Context, m store.Monitor, host string, res *CheckResult) error {
+	tlsConn, err := upgradeTLS(ctx, s.conn, m, host, res)
+	if err != nil {
+		return err
+	}
+	s.conn = tlsConn
+	s.tp = textproto.NewConn(tlsConn)
+	s.tls = true
+	return nil
+}
+
+func checkMail(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := mailOptions(m)
+	kind := NormalizeType(m.Type)
+	conn, host, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultMailPort(kind, opts.TLS))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	res := CheckResult{OK: true}
+	if opts.TLS == MailTLSImplicit {
+		if conn, err = upgradeTLS(ctx, conn, m, host, &res); err != nil {
+			return CheckResult{}, err
+		}
+	}
+	switch kind {
+	case TypeSMTP:
+		err = checkSMTP(conn, m, host, opts, &res)
+	case TypeIMAP:
+		err = checkIMAP(ctx, newMailSession(conn), m, host, opts, &res)
+	case TypePOP3:
+		err = checkPOP3(ctx, newMailSession(conn), m, host, opts, &res)
+	default:
+		return CheckResult{}, errors.New("unsupported monitor type")
+	}
+	if err != nil {
+		return CheckResult{}, err
+	}
+	return res, nil
+}
+
+// checkSMTP greets the server, upgrades with STARTTLS, authenticates and optionally
+// opens a test envelope that is reset before DATA, so no message is ever sent.
+func checkSMTP(conn net.Conn, m store.Monitor, host string, opts store.MailOptions, res *CheckResult) error {
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return mailReplyResult(res, err)
+	}
+	if err := client.Hello(opts.Helo); err != nil {
+		return mailReplyResult(res, err)
+	}
+	if opts.TLS == MailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return mailSoftFailure(res, "monitoring.error.tlsRequired")
+		}
+		cfg, err := monitorTLSConfig(m, host)
+		if err != nil {
+			return err
+		}
+		if err := client.StartTLS(cfg); err != nil {
+			return err
+		}
+		state, _ := client.TLSConnectionState()
+		res.TLS = tlsFromState(&state)
+	}
+	if user := monitorUsername(m); user != "" {
+		if _, isTLS := client.TLSConnectionState(); !isTLS {
+			return mailSoftFailure(res, "monitoring.error.tlsRequired")
+		}
+		auth := smtpAuth(client, user, monitorPassword(m), host)
+		if auth == nil {
+			return mailSoftFailure(res, "monitoring.error.authFailed")
+		}
+		if err := client.Auth(auth); err != nil {
+			var tpErr *textproto.Error
+			if errors.As(err, &tpErr) {
+				return mailSoftFailure(res, "monitoring.error.authFailed")
+			}
+			return err
+		}
+	}
+	if opts.MailFrom != "" && opts.RcptTo != "" {
+		if err := client.Mail(opts.MailFrom); err != nil {
+			return mailReplyResult(res, err)
+		}
+		if err := client.Rcpt(opts.RcptTo); err != nil {
+			return mailReplyResult(res, err)
+		}
+		if err := client.Reset(); err != nil {
+			return mailReplyResult(res, err)
+		}
+	}
+	_ = client.Quit()
+	return nil
+}
+
+// smtpAuth picks PLAIN, falling back to LOGIN, from the mechanisms the server advertises.
+func smtpAuth(client *smtp.Client, user, password, host string) smtp.Auth {
+	advertised, mechs := client.Extension("AUTH")
+	if !advertised {
+		return nil
+	}
+	list := strings.Fields(strings.ToUpper(mechs))
+	for _, mech := range list {
+		if mech == mailAuthPlain {
+			return smtp.PlainAuth("", user, password, host)
+		}
+	}
+	for _, mech := range list {
+		if mech == mailAuthLogin {
+			return &smtpLoginAuth{user: user, password: password}
+		}
+	}
+	return nil
+}
+
+type smtpLoginAuth struct {
+	user     string
+	password string
+}
+
+func (a *smtpLoginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	return mailAuthLogin, nil, nil
+}
+
+func (a *smtpLoginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "user"):
+		return []byte(a.user), nil
+	case strings.HasPrefix(prompt, "pass"):
+		return []byte(a.password), nil
+	}
+	return nil, ErrProtocol
+}
+
+// checkIMAP reads the greeting, upgrades with STARTTLS, asks for CAPABILITY and logs in when credentials are set.
+func checkIMAP(ctx context.Context, s *mailSession, m store.Monitor, host string, opts store.MailOptions, res *CheckResult) error {
+	greeting, err := s.tp.ReadLine()
+	if err != nil {
+		return err
+	}
+	switch {
+	case strings.HasPrefix(greeting, "* OK"), strings.HasPrefix(greeting, "* PREAUTH"):
+		res.ServerVersion = mailBanner(imapText(greeting))
+	case strings.HasPrefix(greeting, "* BYE"):
+		return mailSoftFailure(res, "monitoring.error.mailRejected")
+	default:
+		return ErrProtocol
+	}
+	caps, err := s.imapCapabilities("a1")
+	if err != nil {
+		return err
+	}
+	if opts.TLS == MailTLSStartTLS {
+		if !caps["STARTTLS"] {
+			return mailSoftFailure(res, "monitoring.error.tlsRequired")
+		}
+		if ok, _, err := s.imapCommand("a2", "STARTTLS"); err != nil {
+			return err
+		} else if !ok {
+			return ErrProtocol
+		}
+		if err := s.upgrade(ctx, m, host, res); err != nil {
+			return err
+		}
+		// RFC 3501 6.2.1: capabilities learned before STARTTLS must be discarded.
+		if caps, err = s.imapCapabilities("a3"); err != nil {
+			return err
+		}
+	}
+	if user := monitorUsername(m); user != "" {
+		if !s.tls || caps["LOGINDISABLED"] {
+			return mailSoftFailure(res, "monitoring.error.tlsRequired")
+		}
+		userArg, ok1 := imapQuote(user)
+		passArg, ok2 := imapQuote(monitorPassword(m))
+		if !ok1 || !ok2 {
+			return mailSoftFailure(res, "monitoring.error.authFailed")
+		}
+		ok, _, err := s.imapCommand("a4", "LOGIN "+userArg+" "+passArg)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return mailSoftFailure(res, "monitoring.error.authFailed")
+		}
+	}
+	_, _, _ = s.imapCommand("a5", "LOGOUT")
+	return nil
+}
+
+func (s *mailSession) imapCapabilities(tag string) (map[string]bool, error) {
+	ok, untagged, err := s.imapCommand(tag, "CAPABILITY")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrProtocol
+	}
+	caps := map[string]bool{}
+	for _, line := range untagged {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "CAPABILITY") {
+			continue
+		}
+		for _, c := range fields[2:] {
+			caps[strings.ToUpper(c)] = true
+		}
+	}
+	return caps, nil
+}
+
+// imapCommand sends a tagged command and returns whether it completed with OK along with the untagged lines.
+func (s *mailSession) imapCommand(tag, cmd string) (bool, []string, error) {
+	if err := s.tp.PrintfLine("%s %s", tag, cmd); err != nil {
+		return false, nil, err
+	}
+	var untagged []string
+	for i := 0; i < maxMailLines; i++ {
+		line, err := s.tp.ReadLine()
+		if err != nil {
+			return false, nil, err
+		}
+		if rest, found := strings.CutPrefix(line, tag+" "); found {
+			return strings.HasPrefix(strings.ToUpper(rest), "OK"), untagged, nil
+		}
+		untagged = append(untagged, line)
+	}
+	return false, nil, ErrProtocol
+}
+
+// imapQuote renders s as an IMAP quoted string; CR and LF cannot be quoted and need a literal, which is not supported.
+func imapQuote(s string) (string, bool) {
+	if strings.ContainsAny(s, "\r\n") {
+		return "", false
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`, true
+}
+
+// imapText drops the status and the optional response code from an untagged status line.
+func imapText(line string) string {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 3 {
+		return ""
+	}
+	text := fields[2]
+	if strings.HasPrefix(text, "[") {
+		if end := strings.IndexByte(text, ']'); end >= 0 {
+			text = text[end+1:]
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// checkPOP3 reads the greeting, upgrades with STLS and authenticates with USER/PASS when credentials are set.
+func checkPOP3(ctx context.Context, s *mailSession, m store.Monitor, host string, opts store.MailOptions, res *CheckResult) error {
+	greeting, err := s.tp.ReadLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		if strings.HasPrefix(greeting, "-ERR") {
+			return mailSoftFailure(res, "monitoring.error.mailRejected")
+		}
+		return ErrProtocol
+	}
+	res.ServerVersion = mailBanner(strings.TrimPrefix(greeting, "+OK"))
+	if opts.TLS == MailTLSStartTLS {
+		caps, err := s.pop3Capabilities()
+		if err != nil {
+			return err
+		}
+		if !caps["STLS"] {
+			return mailSoftFailure(res, "monitoring.error.tlsRequired")
+		}
+		if ok, err := s.pop3Command("STLS"); err != nil {
+			return err
+		} else if !ok {
+			return ErrProtocol
+		}
+		if err := s.upgrade(ctx, m, host, res); err != nil {
+			return err
+		}
+	}
+	if user := monitorUsername(m); user != "" {
+		password := monitorPassword(m)
+		if !s.tls {
+			return mailSoftFailure(res, "monitoring.error.tlsRequired")
+		}
+		if strings.ContainsAny(user+password, "\r\n") {
+			return mailSoftFailure(res, "monitoring.error.authFailed")
+		}
+		ok, err := s.pop3Command("USER " + user)
+		if err == nil && ok {
+			ok, err = s.pop3Command("PASS " + password)
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return mailSoftFailure(res, "monitoring.error.authFailed")
+		}
+	}
+	_, _ = s.pop3Command("QUIT")
+	return nil
+}
+
+func (s *mailSession) pop3Command(cmd string) (bool, error) {
+	if err := s.tp.PrintfLine("%s", cmd); err != nil {
+		return false, err
+	}
+	line, err := s.tp.ReadLine()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case strings.HasPrefix(line, "+OK"):
+		return true, nil
+	case strings.HasPrefix(line, "-ERR"):
+		return false, nil
+	}
+	return false, ErrProtocol
+}
+
+// pop3Capabilities runs CAPA (RFC 2449); servers without it report no capabilities.
+func (s *mailSession) pop3Capabilities() (map[string]bool, error) {
+	caps := map[string]bool{}
+	ok, err := s.pop3Command("CAPA")
+	if err != nil || !ok {
+		return caps, err
+	}
+	for i := 0; i < maxMailLines; i++ {
+		line, err := s.tp.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "." {
+			return caps, nil
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			caps[strings.ToUpper(fields[0])] = true
+		}
+	}
+	return nil, ErrProtocol
+}
+
+// mailReplyResult turns a negative SMTP reply into a soft failure carrying the reply code.
+func mailReplyResult(res *CheckResult, err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= smtpReplyError {
+		return mailSoftFailure(res, fmt.Sprintf("monitoring.error.mailRejected: %d", tpErr.Code))
+	}
+	return err
+}
+
+func mailSoftFailure(res *CheckResult, key string) error {
+	res.OK = false
+	res.Error = key
+	return nil
+}
+
+func mailBanner(raw string) string {
+	banner := strings.TrimSpace(raw)
+	if len(banner) > maxMailBanner {
+		banner = strings.ToValidUTF8(banner[:maxMailBanner], "")
+	}
+	return banner
+}
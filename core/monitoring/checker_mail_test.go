@@ -0,0 +1,239 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+// fakeMailConn is the server side of a fake mail session that can switch to TLS mid-stream.
+type fakeMailConn struct {
+	conn net.Conn
+	tp   *textproto.Conn
+	cert tls.Certificate
+	tls  bool
+}
+
+func (c *fakeMailConn) send(lines ...string) {
+	for _, line := range lines {
+		_ = c.tp.PrintfLine("%s", line)
+	}
+}
+
+func (c *fakeMailConn) startTLS() bool {
+	tlsConn := tls.Server(c.conn, &tls.Config{Certificates: []tls.Certificate{c.cert}})
+	if err := tlsConn.Handshake(); err != nil {
+		return false
+	}
+	c.conn = tlsConn
+	c.tp = textproto.NewConn(tlsConn)
+	c.tls = true
+	return true
+}
+
+func startFakeMailServer(t *testing.T, greeting string, handle func(c *fakeMailConn, line string) bool) (string, int) {
+	cert := testServerCertificate(t, "mail.test")
+	return startFakeServer(t, func(conn net.Conn) {
+		c := &fakeMailConn{conn: conn, tp: textproto.NewConn(conn), cert: cert}
+		c.send(greeting)
+		for {
+			line, err := c.tp.ReadLine()
+			if err != nil || !handle(c, line) {
+				return
+			}
+		}
+	})
+}
+
+func fakeSMTP(t *testing.T) (string, int) {
+	return startFakeMailServer(t, "220 mail.test ESMTP ready", func(c *fakeMailConn, line string) bool {
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			if c.tls {
+				c.send("250-mail.test", "250 AUTH LOGIN PLAIN")
+			} else {
+				c.send("250-mail.test", "250 STARTTLS")
+			}
+		case cmd == "STARTTLS":
+			c.send("220 go ahead")
+			return c.startTLS()
+		case strings.HasPrefix(cmd, "AUTH PLAIN "):
+			raw, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(line[len("AUTH PLAIN "):]))
+			if string(raw) == "\x00probe\x00s3cret" {
+				c.send("235 authenticated")
+			} else {
+				c.send("535 authentication failed")
+			}
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			c.send("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			if strings.Contains(line, "<postmaster@mail.test>") {
+				c.send("250 ok")
+			} else {
+				c.send("550 5.1.1 no such user")
+			}
+		case cmd == "RSET":
+			c.send("250 reset")
+		case cmd == "QUIT":
+			c.send("221 bye")
+			return false
+		case strings.HasPrefix(cmd, "DATA"):
+			c.send("554 DATA must not be sent by the probe")
+		default:
+			c.send("502 unknown")
+		}
+		return true
+	})
+}
+
+func TestCheckMonitorSMTP(t *testing.T) {
+	host, port := fakeSMTP(t)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeSMTP, Host: host, Port: port, TimeoutSec: 3, IgnoreTLSErrors: true,
+		Credentials: &store.MonitorCredentials{Username: "probe", Password: "s3cret"}}
+	mon.Options.Mail = &store.MailOptions{MailFrom: "monitor@example.com", RcptTo: "postmaster@mail.test"}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || res.TLS == nil || res.TLS.CommonName != "mail.test" {
+		t.Fatalf("expected STARTTLS session with certificate, got ok=%v error=%q tls=%+v", res.OK, res.Error, res.TLS)
+	}
+
+	mon.Options.Mail.RcptTo = "nobody@mail.test"
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.mailRejected: 550" {
+		t.Fatalf("expected rejected recipient, got ok=%v error=%q", res.OK, res.Error)
+	}
+
+	mon.Options.Mail.RcptTo = ""
+	mon.Credentials = &store.MonitorCredentials{Username: "probe", Password: "wrong"}
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.authFailed" || res.TLS == nil {
+		t.Fatalf("expected auth failure with captured certificate, got ok=%v error=%q", res.OK, res.Error)
+	}
+
+	mon.Options.Mail.TLS = MailTLSNone
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.tlsRequired" {
+		t.Fatalf("expected credentials to stay off plaintext sessions, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorIMAP(t *testing.T) {
+	host, port := startFakeMailServer(t, "* OK [CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED] Dovecot ready.", func(c *fakeMailConn, line string) bool {
+		tag, cmd, _ := strings.Cut(line, " ")
+		switch {
+		case cmd == "CAPABILITY":
+			if c.tls {
+				c.send("* CAPABILITY IMAP4rev1 AUTH=PLAIN")
+			} else {
+				c.send("* CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED")
+			}
+			c.send(tag + " OK done")
+		case cmd == "STARTTLS":
+			c.send(tag + " OK begin TLS")
+			return c.startTLS()
+		case strings.HasPrefix(cmd, "LOGIN "):
+			if cmd == `LOGIN "probe" "p\"ss"` {
+				c.send(tag + " OK logged in")
+			} else {
+				c.send(tag + " NO [AUTHENTICATIONFAILED] invalid credentials")
+			}
+		case cmd == "LOGOUT":
+			c.send("* BYE", tag+" OK bye")
+			return false
+		default:
+			c.send(tag + " BAD unknown")
+		}
+		return true
+	})
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeIMAP, Host: host, Port: port, TimeoutSec: 3, IgnoreTLSErrors: true,
+		Credentials: &store.MonitorCredentials{Username: "probe", Password: `p"ss`}}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || res.TLS == nil || res.ServerVersion != "Dovecot ready." {
+		t.Fatalf("expected IMAP login over STARTTLS, got ok=%v error=%q version=%q", res.OK, res.Error, res.ServerVersion)
+	}
+	mon.Credentials.Password = "wrong"
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorPOP3(t *testing.T) {
+	cert := testServerCertificate(t, "mail.test")
+	handle := func(c *fakeMailConn, line string) bool {
+		switch {
+		case line == "CAPA":
+			c.send("+OK capabilities", "USER")
+			if !c.tls {
+				c.send("STLS")
+			}
+			c.send(".")
+		case line == "STLS":
+			c.send("+OK begin TLS")
+			return c.startTLS()
+		case strings.HasPrefix(line, "USER "):
+			c.send("+OK")
+		case strings.HasPrefix(line, "PASS "):
+			if line == "PASS s3cret" {
+				c.send("+OK maildrop ready")
+			} else {
+				c.send("-ERR invalid password")
+			}
+		case line == "QUIT":
+			c.send("+OK bye")
+			return false
+		default:
+			c.send("-ERR unknown")
+		}
+		return true
+	}
+	host, port := startFakeMailServer(t, "+OK POP3 ready", handle)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypePOP3, Host: host, Port: port, TimeoutSec: 3, IgnoreTLSErrors: true,
+		Credentials: &store.MonitorCredentials{Username: "probe", Password: "s3cret"}}
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK || res.TLS == nil {
+		t.Fatalf("expected POP3 login over STLS, got ok=%v error=%q", res.OK, res.Error)
+	}
+	mon.Credentials.Password = "wrong"
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%q", res.OK, res.Error)
+	}
+
+	// Implicit TLS (port 995 style): the handshake happens before the greeting.
+	tlsHost, tlsPort := startFakeServer(t, func(conn net.Conn) {
+		c := &fakeMailConn{conn: conn, cert: cert}
+		if !c.startTLS() {
+			return
+		}
+		c.send("+OK POP3 ready")
+		r := bufio.NewReader(c.conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil || !handle(c, strings.TrimRight(line, "\r\n")) {
+				return
+			}
+		}
+	})
+	mon = store.Monitor{Type: TypePOP3, Host: tlsHost, Port: tlsPort, TimeoutSec: 3, IgnoreTLSErrors: true}
+	mon.Options.Mail = &store.MailOptions{TLS: MailTLSImplicit}
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK || res.TLS == nil {
+		t.Fatalf("expected implicit TLS session, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestDefaultMailPort(t *testing.T) {
+	cases := map[string]int{"smtp/": 25, "smtp/tls": 465, "imap/starttls": 143, "imap/tls": 993, "pop3/none": 110, "pop3/tls": 995}
+	for key, want := range cases {
+		kind, mode, _ := strings.Cut(key, "/")
+		if got := DefaultMailPort(kind, mode); got != want {
+			t.Fatalf("%s: got %d, want %d", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	MongoRolePrimary    = "primary"
+	MongoRoleSecondary  = "secondary"
+	MongoRoleArbiter    = "arbiter"
+	MongoRoleStandalone = "standalone"
+	MongoRoleMongos     = "mongos"
+	// mongoRoleOther covers replica set members in transitional states (startup, recovering, rollback).
+	mongoRoleOther = "other"
+)
+
+const (
+	mongoOpMsg          = 2013
+	mongoMaxMessageSize = 48 * 1024 * 1024
+	mongoCodeAuthFailed = 18
+)
+
+// NormalizeMongoRole validates an expected MongoDB role. Unknown values yield an empty string.
+func NormalizeMongoRole(raw string) string {
+	switch role := strings.ToLower(strings.TrimSpace(raw)); role {
+	case MongoRolePrimary, MongoRoleSecondary, MongoRoleArbiter, MongoRoleStandalone, MongoRoleMongos:
+		return role
+	default:
+		return ""
+	}
+}
+
+func mongoDBOptions(m store.Monitor) store.MongoDBOptions {
+	var opts store.MongoDBOptions
+	if m.Options.MongoDB != nil {
+		opts = *m.Options.MongoDB
+	}
+	opts.AuthSource = strings.TrimSpace(opts.AuthSource)
+	if opts.AuthSource == "" {
+		opts.AuthSource = "admin"
+	}
+	opts.Database = strings.TrimSpace(opts.Database)
+	if opts.Database == "" {
+		opts.Database = opts.AuthSource
+	}
+	opts.ExpectedRole = NormalizeMongoRole(opts.ExpectedRole)
+	return opts
+}
+
+type mongoCommandError struct {
+	Code    int
+	Message string
+}
+
+func (e *mongoCommandError) Error() string {
+	return fmt.Sprintf("mongodb command error %d: %s", e.Code, e.Message)
+}
+
+type mongoConn struct {
+	conn      net.Conn
+	requestID int32
+}
+
+func checkMongoDB(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := mongoDBOptions(m)
+	conn, host, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultPortForType(TypeMongoDB))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	res := CheckResult{OK: true}
+	if opts.TLS {
+		tlsConn, err := upgradeTLS(ctx, conn, m, host, &res)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		conn = tlsConn
+	}
+	client := &mongoConn{conn: conn}
+	user := monitorUsername(m)
+	hello := bsonDoc{{Key: "hello", Value: int32(1)}}
+	if user != "" {
+		hello = append(hello, bsonElem{Key: "saslSupportedMechs", Value: opts.AuthSource + "." + user})
+	}
+	hello = append(hello, bsonElem{Key: "$db", Value: "admin"})
+	reply, err := client.command(hello)
+	var cmdErr *mongoCommandError
+	if errors.As(err, &cmdErr) {
+		// Servers before 4.4.2 do not know hello but accept the legacy isMaster over OP_MSG.
+		hello[0] = bsonElem{Key: "isMaster", Value: int32(1)}
+		reply, err = client.command(hello)
+	}
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res.ServerRole = mongoRole(reply)
+	if user != "" {
+		mechanism := mongoAuthMechanism(reply["saslSupportedMechs"])
+		if err := client.authenticate(opts.AuthSource, user, monitorPassword(m), mechanism); err != nil {
+			if errors.Is(err, errAuthRejected) {
+				return CheckResult{OK: false, Error: "monitoring.error.authFailed"}, nil
+			}
+			return CheckResult{}, err
+		}
+	}
+	if _, err := client.command(bsonDoc{{Key: "ping", Value: int32(1)}, {Key: "$db", Value: opts.Database}}); err != nil {
+		if errors.As(err, &cmdErr) {
+			return CheckResult{OK: false, Error: "monitoring.error.queryFailed"}, nil
+		}
+		return CheckResult{}, err
+	}
+	// buildInfo is allowed without authentication; a refusal only hides the version.
+	info, err := client.command(bsonDoc{{Key: "buildInfo", Value: int32(1)}, {Key: "$db", Value: "admin"}})
+	if err != nil && !errors.As(err, &cmdErr) {
+		return CheckResult{}, err
+	}
+	if version, ok := info["version"].(string); ok {
+		res.ServerVersion = version
+	}
+	checkExpectedRole(&res, opts.ExpectedRole)
+	return res, nil
+}
+
+func mongoRole(hello map[string]any) string {
+	switch {
+	case hello["msg"] == "isdbgrid":
+		return MongoRoleMongos
+	case bsonBool(hello["arbiterOnly"]):
+		return MongoRoleArbiter
+	case bsonBool(hello["secondary"]):
+		return MongoRoleSecondary
+	}
+	primary := bsonBool(hello["isWritablePrimary"]) || bsonBool(hello["ismaster"])
+	if _, replicaSet := hello["setName"].(string); !replicaSet {
+		if primary {
+			return MongoRoleStandalone
+		}
+		return mongoRoleOther
+	}
+	if primary {
+		return MongoRolePrimary
+	}
+	return mongoRoleOther
+}
+
+// mongoAuthMechanism prefers SCRAM-SHA-256 unless the server only lists SCRAM-SHA-1 for the user.
+func mongoAuthMechanism(raw any) string {
+	mechs, ok := raw.([]any)
+	if !ok || len(mechs) == 0 {
+		return "SCRAM-SHA-256"
+	}
+	for _, mech := range mechs {
+		if mech == "SCRAM-SHA-256" {
+			return "SCRAM-SHA-256"
+		}
+	}
+	for _, mech := range mechs {
+		if mech == "SCRAM-SHA-1" {
+			return "SCRAM-SHA-1"
+		}
+	}
+	return "SCRAM-SHA-256"
+}
+
+func (c *mongoConn) authenticate(source, user, password, mechanism string) error {
+	var newHash func() hash.Hash = sha256.New
+	if mechanism == "SCRAM-SHA-1" {
+		newHash = sha1.New
+		sum := md5.Sum([]byte(user + ":mongo:" + password))
+		password = hex.EncodeToString(sum[:])
+	}
+	scram, err := newSCRAMClient(newHash, user, password)
+	if err != nil {
+		return err
+	}
+	reply, err := c.command(bsonDoc{
+		{Key: "saslStart", Value: int32(1)},
+		{Key: "mechanism", Value: mechanism},
+		{Key: "payload", Value: scram.clientFirst()},
+		{Key: "autoAuthorize", Value: int32(1)},
+		{Key: "options", Value: bsonDoc{{Key: "skipEmptyExchange", Value: true}}},
+		{Key: "$db", Value: source},
+	})
+	if err != nil {
+		return mongoAuthError(err)
+	}
+	serverFirst, _ := reply["payload"].([]byte)
+	final, err := scram.clientFinal(serverFirst)
+	if err != nil {
+		return err
+	}
+	conversationID := reply["conversationId"]
+	reply, err = c.command(bsonDoc{
+		{Key: "saslContinue", Value: int32(1)},
+		{Key: "conversationId", Value: conversationID},
+		{Key: "payload", Value: final},
+		{Key: "$db", Value: source},
+	})
+	if err != nil {
+		return mongoAuthError(err)
+	}
+	serverFinal, _ := reply["payload"].([]byte)
+	if err := scram.verifyServerFinal(serverFinal); err != nil {
+		return err
+	}
+	if bsonBool(reply["done"]) {
+		return nil
+	}
+	reply, err = c.command(bsonDoc{
+		{Key: "saslContinue", Value: int32(1)},
+		{Key: "conversationId", Value: conversationID},
+		{Key: "payload", Value: []byte{}},
+		{Key: "$db", Value: source},
+	})
+	if err != nil {
+		return mongoAuthError(err)
+	}
+	if !bsonBool(reply["done"]) {
+		return ErrProtocol
+	}
+	return nil
+}
+
+func mongoAuthError(err error) error {
+	var cmdErr *mongoCommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == mongoCodeAuthFailed {
+		return fmt.Errorf("%w: %s", errAuthRejected, cmdErr.Message)
+	}
+	return err
+}
+
+// command sends an OP_MSG with a single body section and returns the reply body.
+func (c *mongoConn) command(doc bsonDoc) (map[string]any, error) {
+	body, err := doc.marshal()
+	if err != nil {
+		return nil, err
+	}
+	c.requestID++
+	msg := make([]byte, 21, 21+len(body))
+	binary.LittleEndian.PutUint32(msg[0:], uint32(21+len(body)))
+	binary.LittleEndian.PutUint32(msg[4:], uint32(c.requestID))
+	binary.LittleEndian.PutUint32(msg[12:], mongoOpMsg)
+	msg = append(msg, body...)
+	if _, err := c.conn.Write(msg); err != nil {
+		return nil, err
+	}
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, err
+	}
+	size := int(int32(binary.LittleEndian.Uint32(header)))
+	if size < 21 || size > mongoMaxMessageSize {
+		return nil, ErrProtocol
+	}
+	if int32(binary.LittleEndian.Uint32(header[8:])) != c.requestID || binary.LittleEndian.Uint32(header[12:]) != mongoOpMsg {
+		return nil, ErrProtocol
+	}
+	payload := make([]byte, size-16)
+	if _, err := io.ReadFull(c.conn, payload); err != nil {
+		return nil, err
+	}
+	flags := binary.LittleEndian.Uint32(payload)
+	payload = payload[4:]
+	if flags&1 != 0 {
+		if len(payload) < 4 {
+			return nil, ErrProtocol
+		}
+		payload = payload[:len(payload)-4]
+	}
+	var reply map[string]any
+	for len(payload) > 0 {
+		kind := payload[0]
+		payload = payload[1:]
+		if len(payload) < 4 {
+			return nil, ErrProtocol
+		}
+		sectionSize := int(int32(binary.LittleEndian.Uint32(payload)))
+		if sectionSize < 5 || sectionSize > len(payload) {
+			return nil, ErrProtocol
+		}
+		if kind == 0 {
+			if reply, err = decodeBSON(payload[:sectionSize]); err != nil {
+				return nil, err
+			}
+		}
+		payload = payload[sectionSize:]
+	}
+	if reply == nil {
+		return nil, ErrProtocol
+	}
+	if ok, _ := bsonNumber(reply["ok"]); ok != 1 {
+		code, _ := bsonNumber(reply["code"])
+		errmsg, _ := reply["errmsg"].(string)
+		return reply, &mongoCommandError{Code: int(code), Message: errmsg}
+	}
+	return reply, nil
+}
@@ -0,0 +1,163 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestSCRAMClientRFC7677(t *testing.T) {
+	c := &scramClient{newHash: sha256.New, username: "user", password: "pencil", nonce: "rOprNGfwEbeRWgbNEkqO"}
+	if got := string(c.clientFirst()); got != "n,,n=user,r=rOprNGfwEbeRWgbNEkqO" {
+		t.Fatalf("unexpected client first: %s", got)
+	}
+	final, err := c.clientFinal([]byte("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"))
+	if err != nil {
+		t.Fatalf("client final: %v", err)
+	}
+	if string(final) != "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=" {
+		t.Fatalf("unexpected client final: %s", final)
+	}
+	if err := c.verifyServerFinal([]byte("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")); err != nil {
+		t.Fatalf("server signature: %v", err)
+	}
+	if err := c.verifyServerFinal([]byte("v=AAAA")); err == nil {
+		t.Fatalf("expected forged server signature to be rejected")
+	}
+}
+
+func TestCheckMonitorMongoDB(t *testing.T) {
+	host, port := startFakeMongo(t, "monitor", "secret", false)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeMongoDB, Host: host, Port: port, TimeoutSec: 2}
+
+	res := CheckMonitor(context.Background(), base, settings)
+	if !res.OK || res.ServerRole != MongoRoleSecondary || res.ServerVersion != "7.0.5" {
+		t.Fatalf("expected unauthenticated check ok, got ok=%v error=%s role=%q version=%q", res.OK, res.Error, res.ServerRole, res.ServerVersion)
+	}
+
+	base.Credentials = &store.MonitorCredentials{Username: "monitor", Password: "secret"}
+	base.Options.MongoDB = &store.MongoDBOptions{ExpectedRole: MongoRoleSecondary}
+	res = CheckMonitor(context.Background(), base, settings)
+	if !res.OK {
+		t.Fatalf("expected authenticated check ok, got error=%s", res.Error)
+	}
+
+	base.Options.MongoDB.ExpectedRole = MongoRolePrimary
+	res = CheckMonitor(context.Background(), base, settings)
+	if res.OK || res.Error != "monitoring.error.unexpectedRole" {
+		t.Fatalf("expected unexpected role, got ok=%v error=%s", res.OK, res.Error)
+	}
+
+	base.Credentials = &store.MonitorCredentials{Username: "monitor", Password: "wrong"}
+	res = CheckMonitor(context.Background(), base, settings)
+	if res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%s", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorMongoDBLegacyHello(t *testing.T) {
+	host, port := startFakeMongo(t, "", "", true)
+	res := CheckMonitor(context.Background(), store.Monitor{Type: TypeMongoDB, Host: host, Port: port, TimeoutSec: 2},
+		store.MonitorSettings{AllowPrivateNetworks: true})
+	if !res.OK || res.ServerRole != MongoRoleSecondary {
+		t.Fatalf("expected isMaster fallback, got ok=%v error=%s role=%q", res.OK, res.Error, res.ServerRole)
+	}
+}
+
+// startFakeMongo answers OP_MSG commands as a replica set secondary and verifies SCRAM-SHA-256 proofs.
+func startFakeMongo(t *testing.T, user, password string, legacy bool) (string, int) {
+	salt := []byte("fake-mongo-salt")
+	salted, _ := pbkdf2.Key(sha256.New, password, salt, 4096, sha256.Size)
+	return startFakeServer(t, func(conn net.Conn) {
+		var clientFirstBare, serverFirst string
+		for {
+			header := make([]byte, 16)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			payload := make([]byte, binary.LittleEndian.Uint32(header)-16)
+			if _, err := io.ReadFull(conn, payload); err != nil {
+				return
+			}
+			cmd, _, err := decodeBSONDoc(payload[5:], 0)
+			if err != nil {
+				return
+			}
+			args := map[string]any{}
+			for _, el := range cmd {
+				args[el.Key] = el.Value
+			}
+			var reply bsonDoc
+			switch cmd[0].Key {
+			case "hello":
+				if legacy {
+					reply = bsonDoc{{Key: "ok", Value: float64(0)}, {Key: "code", Value: int32(59)}, {Key: "errmsg", Value: "no such command: 'hello'"}}
+					break
+				}
+				fallthrough
+			case "isMaster":
+				reply = bsonDoc{
+					{Key: "ismaster", Value: false},
+					{Key: "secondary", Value: true},
+					{Key: "setName", Value: "rs0"},
+					{Key: "saslSupportedMechs", Value: nil},
+					{Key: "ok", Value: float64(1)},
+				}
+			case "saslStart":
+				first := string(args["payload"].([]byte))
+				clientFirstBare = strings.TrimPrefix(first, "n,,")
+				nonce := strings.SplitN(clientFirstBare, ",r=", 2)[1]
+				serverFirst = "r=" + nonce + "server,s=" + base64.StdEncoding.EncodeToString(salt) + ",i=4096"
+				reply = bsonDoc{{Key: "conversationId", Value: int32(1)}, {Key: "done", Value: false}, {Key: "payload", Value: []byte(serverFirst)}, {Key: "ok", Value: float64(1)}}
+			case "saslContinue":
+				final := string(args["payload"].([]byte))
+				if final == "" {
+					reply = bsonDoc{{Key: "done", Value: true}, {Key: "payload", Value: []byte{}}, {Key: "ok", Value: float64(1)}}
+					break
+				}
+				withoutProof, proofRaw, _ := strings.Cut(final, ",p=")
+				authMessage := clientFirstBare + "," + serverFirst + "," + withoutProof
+				clientKey := scramHMAC(sha256.New, salted, "Client Key")
+				storedKey := sha256.Sum256(clientKey)
+				signature := scramHMAC(sha256.New, storedKey[:], authMessage)
+				proof, _ := base64.StdEncoding.DecodeString(proofRaw)
+				recovered := make([]byte, len(proof))
+				for i := range proof {
+					recovered[i] = proof[i] ^ signature[i%len(signature)]
+				}
+				sum := sha256.Sum256(recovered)
+				if !strings.Contains(clientFirstBare, "n="+user+",") || !hmac.Equal(sum[:], storedKey[:]) {
+					reply = bsonDoc{{Key: "ok", Value: float64(0)}, {Key: "code", Value: int32(18)}, {Key: "errmsg", Value: "Authentication failed."}}
+					break
+				}
+				serverSig := scramHMAC(sha256.New, scramHMAC(sha256.New, salted, "Server Key"), authMessage)
+				reply = bsonDoc{{Key: "conversationId", Value: int32(1)}, {Key: "done", Value: false},
+					{Key: "payload", Value: []byte("v=" + base64.StdEncoding.EncodeToString(serverSig))}, {Key: "ok", Value: float64(1)}}
+			case "ping":
+				reply = bsonDoc{{Key: "ok", Value: float64(1)}}
+			case "buildInfo":
+				reply = bsonDoc{{Key: "version", Value: "7.0.5"}, {Key: "versionArray", Value: bsonDoc{{Key: "0", Value: int32(7)}}}, {Key: "ok", Value: float64(1)}}
+			default:
+				reply = bsonDoc{{Key: "ok", Value: float64(0)}, {Key: "code", Value: int32(59)}, {Key: "errmsg", Value: "no such command"}}
+			}
+			body, _ := reply.marshal()
+			msg := make([]byte, 21, 21+len(body))
+			binary.LittleEndian.PutUint32(msg[0:], uint32(21+len(body)))
+			copy(msg[8:12], header[4:8])
+			binary.LittleEndian.PutUint32(msg[12:], mongoOpMsg)
+			if _, err := conn.Write(append(msg, body...)); err != nil {
+				return
+			}
+		}
+	})
+}
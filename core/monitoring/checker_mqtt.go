@@ -0,0 +1,323 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	MQTTVersion311 = "3.1.1"
+	MQTTVersion5   = "5"
+)
+
+const (
+	mqttConnect     = 0x10
+	mqttConnack     = 0x20
+	mqttPublish     = 0x30
+	mqttSubscribe   = 0x82
+	mqttSuback      = 0x90
+	mqttDisconnect  = 0xe0
+	mqttMaxPacket   = 1 << 20
+	mqttKeepAlive   = 30
+	mqttSubscribeID = 1
+)
+
+// NormalizeMQTTVersion returns 3.1.1 or 5, or an empty string for unknown values.
+func NormalizeMQTTVersion(raw string) string {
+	switch strings.TrimSpace(raw) {
+	case "", MQTTVersion311, "3", "4":
+		return MQTTVersion311
+	case MQTTVersion5, "5.0":
+		return MQTTVersion5
+	default:
+		return ""
+	}
+}
+
+func mqttOptions(m store.Monitor) store.MQTTOptions {
+	var opts store.MQTTOptions
+	if m.Options.MQTT != nil {
+		opts = *m.Options.MQTT
+	}
+	opts.Version = NormalizeMQTTVersion(opts.Version)
+	if opts.Version == "" {
+		opts.Version = MQTTVersion311
+	}
+	opts.ClientID = strings.TrimSpace(opts.ClientID)
+	opts.Topic = strings.TrimSpace(opts.Topic)
+	return opts
+}
+
+type mqttConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	v5   bool
+}
+
+func checkMQTT(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := mqttOptions(m)
+	conn, host, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultPortForType(TypeMQTT))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	res := CheckResult{OK: true}
+	if opts.TLS {
+		tlsConn, err := upgradeTLS(ctx, conn, m, host, &res)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		conn = tlsConn
+	}
+	client := &mqttConn{conn: conn, r: bufio.NewReader(conn), v5: opts.Version == MQTTVersion5}
+	clientID := opts.ClientID
+	if clientID == "" {
+		clientID = "berkut-scc-" + mqttToken(6)
+	}
+	if err := client.write(mqttConnect, client.connectBody(clientID, monitorUsername(m), monitorPassword(m))); err != nil {
+		return CheckResult{}, err
+	}
+	kind, body, err := client.read()
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if kind&0xf0 != mqttConnack || len(body) < 2 {
+		return CheckResult{}, ErrProtocol
+	}
+	if code := body[1]; code != 0 {
+		return CheckResult{OK: false, Error: mqttConnackError(code, client.v5)}, nil
+	}
+	defer func() { _ = client.write(mqttDisconnect, nil) }()
+	if opts.Topic == "" {
+		return res, nil
+	}
+	start := time.Now()
+	ok, err := client.roundTrip(opts.Topic)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return CheckResult{OK: false, Error: "monitoring.error.mqttNoMessage"}, nil
+		}
+		return CheckResult{}, err
+	}
+	if !ok {
+		return CheckResult{OK: false, Error: "monitoring.error.mqttSubscribeFailed"}, nil
+	}
+	res.LatencyMs = int(time.Since(start).Milliseconds())
+	if res.LatencyMs <= 0 {
+		res.LatencyMs = 1
+	}
+	return res, nil
+}
+
+func (c *mqttConn) connectBody(clientID, username, password string) []byte {
+	var body []byte
+	body = appendMQTTString(body, "MQTT")
+	level := byte(4)
+	if c.v5 {
+		level = 5
+	}
+	flags := byte(0x02)
+	if username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+	body = append(body, level, flags, 0, mqttKeepAlive)
+	if c.v5 {
+		body = append(body, 0)
+	}
+	body = appendMQTTString(body, clientID)
+	if username != "" {
+		body = appendMQTTString(body, username)
+		if password != "" {
+			body = appendMQTTString(body, password)
+		}
+	}
+	return body
+}
+
+// roundTrip subscribes to topic, publishes a unique payload and waits for it to come back.
+// It returns false when the broker refuses the subscription.
+func (c *mqttConn) roundTrip(topic string) (bool, error) {
+	var sub []byte
+	sub = append(sub, 0, mqttSubscribeID)
+	if c.v5 {
+		sub = append(sub, 0)
+	}
+	sub = appendMQTTString(sub, topic)
+	sub = append(sub, 0)
+	if err := c.write(mqttSubscribe, sub); err != nil {
+		return false, err
+	}
+	for {
+		kind, body, err := c.read()
+		if err != nil {
+			return false, err
+		}
+		if kind&0xf0 != mqttSuback {
+			continue
+		}
+		if len(body) < 3 || binary.BigEndian.Uint16(body) != mqttSubscribeID {
+			return false, ErrProtocol
+		}
+		codes := body[2:]
+		if c.v5 {
+			props, n := readMQTTVarint(codes)
+			if n <= 0 || n+props > len(codes) {
+				return false, ErrProtocol
+			}
+			codes = codes[n+props:]
+		}
+		if len(codes) == 0 || codes[0] >= 0x80 {
+			return false, nil
+		}
+		break
+	}
+	payload := "berkut-scc-probe-" + mqttToken(8)
+	var pub []byte
+	pub = appendMQTTString(pub, topic)
+	if c.v5 {
+		pub = append(pub, 0)
+	}
+	pub = append(pub, payload...)
+	if err := c.write(mqttPublish, pub); err != nil {
+		return false, err
+	}
+	for {
+		kind, body, err := c.read()
+		if err != nil {
+			return false, err
+		}
+		if kind&0xf0 != mqttPublish {
+			continue
+		}
+		if got, ok := c.publishPayload(kind, body); ok && got == payload {
+			return true, nil
+		}
+	}
+}
+
+func (c *mqttConn) publishPayload(kind byte, body []byte) (string, bool) {
+	if len(body) < 2 {
+		return "", false
+	}
+	topicLen := int(binary.BigEndian.Uint16(body))
+	rest := body[2:]
+	if topicLen > len(rest) {
+		return "", false
+	}
+	rest = rest[topicLen:]
+	if (kind>>1)&0x03 > 0 {
+		if len(rest) < 2 {
+			return "", false
+		}
+		rest = rest[2:]
+	}
+	if c.v5 {
+		props, n := readMQTTVarint(rest)
+		if n <= 0 || n+props > len(rest) {
+			return "", false
+		}
+		rest = rest[n+props:]
+	}
+	return string(rest), true
+}
+
+func (c *mqttConn) write(kind byte, body []byte) error {
+	packet := []byte{kind}
+	size := len(body)
+	for {
+		b := byte(size % 128)
+		size /= 128
+		if size > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if size == 0 {
+			break
+		}
+	}
+	_, err := c.conn.Write(append(packet, body...))
+	return err
+}
+
+func (c *mqttConn) read() (byte, []byte, error) {
+	kind, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	size, multiplier := 0, 1
+	for i := 0; ; i++ {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, ErrProtocol
+		}
+		multiplier *= 128
+	}
+	if size > mqttMaxPacket {
+		return 0, nil, ErrProtocol
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return kind, body, nil
+}
+
+func mqttConnackError(code byte, v5 bool) string {
+	if v5 {
+		switch code {
+		case 0x86, 0x87, 0x8c:
+			return "monitoring.error.authFailed"
+		}
+		return "monitoring.error.mqttRefused"
+	}
+	switch code {
+	case 4, 5:
+		return "monitoring.error.authFailed"
+	}
+	return "monitoring.error.mqttRefused"
+}
+
+func appendMQTTString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+// readMQTTVarint decodes a variable byte integer and returns it with the number of bytes consumed.
+func readMQTTVarint(data []byte) (int, int) {
+	value, multiplier := 0, 1
+	for i := 0; i < len(data) && i < 4; i++ {
+		value += int(data[i]&0x7f) * multiplier
+		if data[i]&0x80 == 0 {
+			return value, i + 1
+		}
+		multiplier *= 128
+	}
+	return 0, 0
+}
+
+func mqttToken(n int) string {
+	raw := make([]byte, n)
+	_, _ = rand.Read(raw)
+	return hex.EncodeToString(raw)
+}
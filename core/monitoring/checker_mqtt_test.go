@@ -0,0 +1,111 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorMQTT(t *testing.T) {
+	host, port := startFakeMQTT(t, "probe", "secret")
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeMQTT, Host: host, Port: port, TimeoutSec: 2}
+
+	res := CheckMonitor(context.Background(), base, settings)
+	if res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected anonymous connect to be refused, got ok=%v error=%s", res.OK, res.Error)
+	}
+
+	base.Credentials = &store.MonitorCredentials{Username: "probe", Password: "secret"}
+	for _, version := range []string{MQTTVersion311, MQTTVersion5} {
+		mon := base
+		mon.Options.MQTT = &store.MQTTOptions{Version: version}
+		if res := CheckMonitor(context.Background(), mon, settings); !res.OK {
+			t.Fatalf("mqtt %s connect: %s", version, res.Error)
+		}
+		mon.Options.MQTT.Topic = "probes/berkut"
+		if res := CheckMonitor(context.Background(), mon, settings); !res.OK || res.LatencyMs <= 0 {
+			t.Fatalf("mqtt %s round trip: ok=%v error=%s latency=%d", version, res.OK, res.Error, res.LatencyMs)
+		}
+		mon.Options.MQTT.Topic = "denied/topic"
+		if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.mqttSubscribeFailed" {
+			t.Fatalf("mqtt %s denied subscription: ok=%v error=%s", version, res.OK, res.Error)
+		}
+	}
+}
+
+// startFakeMQTT is a single-client broker that echoes publishes on the subscribed topic.
+func startFakeMQTT(t *testing.T, user, password string) (string, int) {
+	return startFakeServer(t, func(conn net.Conn) {
+		c := &mqttConn{conn: conn, r: bufio.NewReader(conn)}
+		kind, body, err := c.read()
+		if err != nil || kind != mqttConnect {
+			return
+		}
+		nameLen := int(binary.BigEndian.Uint16(body))
+		rest := body[2+nameLen:]
+		c.v5 = rest[0] == 5
+		flags := rest[1]
+		rest = rest[4:]
+		if c.v5 {
+			rest = rest[1:]
+		}
+		fields := []string{}
+		for len(rest) >= 2 {
+			n := int(binary.BigEndian.Uint16(rest))
+			fields = append(fields, string(rest[2:2+n]))
+			rest = rest[2+n:]
+		}
+		code := byte(0)
+		if flags&0x80 == 0 || len(fields) < 3 || fields[1] != user || fields[2] != password {
+			code = 5
+			if c.v5 {
+				code = 0x86
+			}
+		}
+		ack := []byte{0, code}
+		if c.v5 {
+			ack = append(ack, 0)
+		}
+		if err := c.write(mqttConnack, ack); err != nil || code != 0 {
+			return
+		}
+		topic := ""
+		for {
+			kind, body, err := c.read()
+			if err != nil {
+				return
+			}
+			switch kind & 0xf0 {
+			case mqttSubscribe & 0xf0:
+				filter := body[2:]
+				if c.v5 {
+					filter = filter[1:]
+				}
+				n := int(binary.BigEndian.Uint16(filter))
+				topic = string(filter[2 : 2+n])
+				ack := []byte{body[0], body[1]}
+				if c.v5 {
+					ack = append(ack, 0)
+				}
+				if topic == "denied/topic" {
+					ack = append(ack, 0x80)
+				} else {
+					ack = append(ack, 0)
+				}
+				_ = c.write(mqttSuback, ack)
+			case mqttPublish:
+				n := int(binary.BigEndian.Uint16(body))
+				if string(body[2:2+n]) == topic {
+					_ = c.write(mqttPublish, body)
+				}
+			case mqttDisconnect:
+				return
+			}
+		}
+	})
+}
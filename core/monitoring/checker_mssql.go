@@ -0,0 +1,791 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	tdsPacketSQLBatch = 0x01
+	tdsPacketReply    = 0x04
+	tdsPacketLogin7   = 0x10
+	tdsPacketPrelogin = 0x12
+
+	tdsStatusEOM    = 0x01
+	tdsHeaderSize   = 8
+	tdsPacketSize   = 4096
+	tdsMaxResponse  = 1 << 20
+	tdsVersion74    = 0x74000004
+	tdsLogin7Header = 94
+
+	tdsEncryptOff    = 0x00
+	tdsEncryptOn     = 0x01
+	tdsEncryptNotSup = 0x02
+	tdsEncryptReq    = 0x03
+
+	tdsPreloginVersion    = 0x00
+	tdsPreloginEncryption = 0x01
+	tdsPreloginInstOpt    = 0x02
+	tdsPreloginThreadID   = 0x03
+	tdsPreloginMARS       = 0x04
+	tdsPreloginTerminator = 0xff
+)
+
+// TDS token types used in login and SQL batch responses.
+const (
+	tdsTokenReturnStatus = 0x79
+	tdsTokenColMetadata  = 0x81
+	tdsTokenOrder        = 0xa9
+	tdsTokenError        = 0xaa
+	tdsTokenInfo         = 0xab
+	tdsTokenLoginAck     = 0xad
+	tdsTokenRow          = 0xd1
+	tdsTokenNBCRow       = 0xd2
+	tdsTokenEnvChange    = 0xe3
+	tdsTokenSSPI         = 0xed
+	tdsTokenDone         = 0xfd
+	tdsTokenDoneProc     = 0xfe
+	tdsTokenDoneInProc   = 0xff
+)
+
+type tdsColumn struct {
+	typ   byte
+	size  int
+	scale byte
+	plp   bool
+}
+
+type tdsResponse struct {
+	loginAck   bool
+	errNumber  int32
+	errMessage string
+	hasValue   bool
+	value      string
+}
+
+func checkMSSQL(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	conn, host, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultPortForType(TypeMSSQL))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	opts := databaseOptions(m)
+	clientEnc := byte(tdsEncryptOff)
+	switch opts.TLSMode {
+	case DatabaseTLSDisable:
+		clientEnc = tdsEncryptNotSup
+	case DatabaseTLSRequire:
+		clientEnc = tdsEncryptOn
+	}
+	if err := writeTDSMessage(conn, tdsPacketPrelogin, buildTDSPrelogin(clientEnc)); err != nil {
+		return CheckResult{}, err
+	}
+	_, reply, err := readTDSMessage(conn)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	version, serverEnc, err := parseTDSPrelogin(reply)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res := CheckResult{OK: true, ServerVersion: version}
+	username := monitorUsername(m)
+	if username == "" && (opts.Query != "" || opts.ExpectedResult != "") {
+		res.OK = false
+		res.Error = "monitoring.error.credentialsRequired"
+		return res, nil
+	}
+	if serverEnc == tdsEncryptNotSup && opts.TLSMode == DatabaseTLSRequire {
+		res.OK = false
+		res.Error = "monitoring.error.tlsRequired"
+		return res, nil
+	}
+	if clientEnc == tdsEncryptNotSup && serverEnc == tdsEncryptReq {
+		return res, errors.New("server requires encryption")
+	}
+
+	// Without full encryption only the LOGIN7 packet travels over TLS.
+	var transport io.ReadWriter = conn
+	var loginTransport io.ReadWriter = conn
+	if serverEnc != tdsEncryptNotSup && clientEnc != tdsEncryptNotSup {
+		shim := &tdsHandshakeConn{Conn: conn, framing: true}
+		cfg, err := databaseTLSConfig(m, host, opts)
+		if err != nil {
+			return res, err
+		}
+		// TLS 1.3 needs TDS 8 strict mode; TDS 7.x wraps the handshake in PRELOGIN packets.
+		cfg.MaxVersion = tls.VersionTLS12
+		tlsConn := tls.Client(shim, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return res, err
+		}
+		if err := shim.flush(); err != nil {
+			return res, err
+		}
+		shim.framing = false
+		state := tlsConn.ConnectionState()
+		res.TLS = tlsFromState(&state)
+		loginTransport = tlsConn
+		if serverEnc == tdsEncryptOn || serverEnc == tdsEncryptReq || clientEnc == tdsEncryptOn {
+			transport = tlsConn
+		}
+	}
+	if username == "" {
+		return res, nil
+	}
+
+	login := buildTDSLogin7(username, monitorPassword(m), opts.Database, host)
+	if err := writeTDSMessage(loginTransport, tdsPacketLogin7, login); err != nil {
+		return res, err
+	}
+	_, reply, err = readTDSMessage(transport)
+	if err != nil {
+		return res, err
+	}
+	loginResp, err := parseTDSResponse(reply)
+	if err != nil {
+		return res, err
+	}
+	if !loginResp.loginAck {
+		res.OK = false
+		res.Error = "monitoring.error.authFailed"
+		return res, nil
+	}
+
+	query := opts.Query
+	if query == "" {
+		query = defaultDatabaseQuery
+	}
+	if err := writeTDSMessage(transport, tdsPacketSQLBatch, buildTDSSQLBatch(query)); err != nil {
+		return res, err
+	}
+	_, reply, err = readTDSMessage(transport)
+	if err != nil {
+		return res, err
+	}
+	queryResp, err := parseTDSResponse(reply)
+	if err != nil {
+		return res, err
+	}
+	if queryResp.errNumber != 0 {
+		res.OK = false
+		res.Error = "monitoring.error.queryFailed"
+		return res, nil
+	}
+	checkQueryResult(&res, queryResp.value, opts)
+	return res, nil
+}
+
+// tdsHandshakeConn frames TLS handshake records into PRELOGIN packets as TDS 7.x requires.
+// Writes are buffered until the next read so every handshake flight becomes one TDS message.
+type tdsHandshakeConn struct {
+	net.Conn
+	framing bool
+	wbuf    bytes.Buffer
+	rbuf    []byte
+}
+
+func (c *tdsHandshakeConn) Read(b []byte) (int, error) {
+	if !c.framing {
+		return c.Conn.Read(b)
+	}
+	if err := c.flush(); err != nil {
+		return 0, err
+	}
+	if len(c.rbuf) == 0 {
+		_, payload, err := readTDSMessage(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		c.rbuf = payload
+	}
+	n := copy(b, c.rbuf)
+	c.rbuf = c.rbuf[n:]
+	return n, nil
+}
+
+func (c *tdsHandshakeConn) Write(b []byte) (int, error) {
+	if !c.framing {
+		return c.Conn.Write(b)
+	}
+	return c.wbuf.Write(b)
+}
+
+func (c *tdsHandshakeConn) flush() error {
+	if c.wbuf.Len() == 0 {
+		return nil
+	}
+	err := writeTDSMessage(c.Conn, tdsPacketPrelogin, c.wbuf.Bytes())
+	c.wbuf.Reset()
+	return err
+}
+
+func writeTDSMessage(w io.Writer, typ byte, payload []byte) error {
+	const chunk = tdsPacketSize - tdsHeaderSize
+	packetID := byte(1)
+	for {
+		n := len(payload)
+		status := byte(tdsStatusEOM)
+		if n > chunk {
+			n = chunk
+			status = 0
+		}
+		buf := make([]byte, tdsHeaderSize+n)
+		buf[0] = typ
+		buf[1] = status
+		binary.BigEndian.PutUint16(buf[2:4], uint16(tdsHeaderSize+n))
+		buf[6] = packetID
+		copy(buf[tdsHeaderSize:], payload[:n])
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		payload = payload[n:]
+		packetID++
+		if status == tdsStatusEOM {
+			return nil
+		}
+	}
+}
+
+func readTDSMessage(r io.Reader) (byte, []byte, error) {
+	var payload []byte
+	var typ byte
+	for {
+		var hdr [tdsHeaderSize]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return 0, nil, err
+		}
+		typ = hdr[0]
+		size := int(binary.BigEndian.Uint16(hdr[2:4]))
+		if size < tdsHeaderSize || len(payload)+size > tdsMaxResponse {
+			return 0, nil, ErrProtocol
+		}
+		buf := make([]byte, size-tdsHeaderSize)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return 0, nil, err
+		}
+		payload = append(payload, buf...)
+		if hdr[1]&tdsStatusEOM != 0 {
+			return typ, payload, nil
+		}
+	}
+}
+
+func buildTDSPrelogin(encryption byte) []byte {
+	type option struct {
+		token byte
+		data  []byte
+	}
+	threadID := make([]byte, 4)
+	binary.BigEndian.PutUint32(threadID, uint32(os.Getpid()))
+	options := []option{
+		{tdsPreloginVersion, []byte{0, 0, 0, 0, 0, 0}},
+		{tdsPreloginEncryption, []byte{encryption}},
+		{tdsPreloginInstOpt, []byte{0}},
+		{tdsPreloginThreadID, threadID},
+		{tdsPreloginMARS, []byte{0}},
+	}
+	offset := len(options)*5 + 1
+	var head, data bytes.Buffer
+	for _, opt := range options {
+		head.WriteByte(opt.token)
+		_ = binary.Write(&head, binary.BigEndian, uint16(offset+data.Len()))
+		_ = binary.Write(&head, binary.BigEndian, uint16(len(opt.data)))
+		data.Write(opt.data)
+	}
+	head.WriteByte(tdsPreloginTerminator)
+	return append(head.Bytes(), data.Bytes()...)
+}
+
+func parseTDSPrelogin(b []byte) (string, byte, error) {
+	version := ""
+	encryption := byte(tdsEncryptNotSup)
+	for pos := 0; ; pos += 5 {
+		if pos >= len(b) {
+			return "", 0, ErrProtocol
+		}
+		token := b[pos]
+		if token == tdsPreloginTerminator {
+			break
+		}
+		if pos+5 > len(b) {
+			return "", 0, ErrProtocol
+		}
+		offset := int(binary.BigEndian.Uint16(b[pos+1 : pos+3]))
+		size := int(binary.BigEndian.Uint16(b[pos+3 : pos+5]))
+		if offset+size > len(b) {
+			return "", 0, ErrProtocol
+		}
+		data := b[offset : offset+size]
+		switch token {
+		case tdsPreloginVersion:
+			if len(data) >= 4 {
+				version = fmt.Sprintf("%d.%d.%d", data[0], data[1], binary.BigEndian.Uint16(data[2:4]))
+			}
+		case tdsPreloginEncryption:
+			if len(data) >= 1 {
+				encryption = data[0]
+			}
+		}
+	}
+	return version, encryption, nil
+}
+
+func buildTDSLogin7(username, password, database, server string) []byte {
+	hostname, _ := os.Hostname()
+	fields := [][]byte{
+		ucs2(hostname),
+		ucs2(username),
+		tdsManglePassword(ucs2(password)),
+		ucs2("berkut-scc"),
+		ucs2(server),
+		nil, // extension
+		ucs2("berkut-scc"),
+		nil, // language
+		ucs2(database),
+	}
+	buf := make([]byte, tdsLogin7Header)
+	binary.LittleEndian.PutUint32(buf[4:8], tdsVersion74)
+	binary.LittleEndian.PutUint32(buf[8:12], tdsPacketSize)
+	binary.LittleEndian.PutUint32(buf[16:20], uint32(os.Getpid()))
+	buf[24] = 0xe0 // USE_DB_ON, INIT_DB_FATAL, SET_LANG_ON
+	buf[25] = 0x03 // INIT_LANG_FATAL, ODBC_ON
+	binary.LittleEndian.PutUint32(buf[32:36], 0x0409)
+	pos := 36
+	for _, field := range fields {
+		binary.LittleEndian.PutUint16(buf[pos:pos+2], uint16(len(buf)))
+		binary.LittleEndian.PutUint16(buf[pos+2:pos+4], uint16(len(field)/2))
+		buf = append(buf, field...)
+		pos += 4
+	}
+	// ClientID (6 bytes) is left zero; SSPI, AtchDBFile and ChangePassword are empty.
+	for _, at := range []int{78, 82, 86} {
+		binary.LittleEndian.PutUint16(buf[at:at+2], uint16(len(buf)))
+	}
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(buf)))
+	return buf
+}
+
+func buildTDSSQLBatch(query string) []byte {
+	// ALL_HEADERS with a single transaction descriptor header (auto-commit).
+	buf := make([]byte, 22)
+	binary.LittleEndian.PutUint32(buf[0:4], 22)
+	binary.LittleEndian.PutUint32(buf[4:8], 18)
+	binary.LittleEndian.PutUint16(buf[8:10], 2)
+	binary.LittleEndian.PutUint32(buf[18:22], 1)
+	return append(buf, ucs2(query)...)
+}
+
+func tdsManglePassword(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, c := range b {
+		out[i] = ((c << 4) | (c >> 4)) ^ 0xa5
+	}
+	return out
+}
+
+func ucs2(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, len(units)*2)
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(out[i*2:], u)
+	}
+	return out
+}
+
+func fromUCS2(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// tdsReader is a bounds-checked cursor over a token stream.
+type tdsReader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *tdsReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.b) {
+		r.err = ErrProtocol
+		return nil
+	}
+	out := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return out
+}
+
+func (r *tdsReader) u8() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *tdsReader) u16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *tdsReader) u32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *tdsReader) u64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *tdsReader) bVarChar() string {
+	return fromUCS2(r.next(int(r.u8()) * 2))
+}
+
+func (r *tdsReader) usVarChar() string {
+	return fromUCS2(r.next(int(r.u16()) * 2))
+}
+
+func parseTDSResponse(b []byte) (tdsResponse, error) {
+	var resp tdsResponse
+	var columns []tdsColumn
+	r := &tdsReader{b: b}
+	for r.pos < len(r.b) && r.err == nil {
+		token := r.u8()
+		switch token {
+		case tdsTokenLoginAck:
+			r.next(int(r.u16()))
+			resp.loginAck = true
+		case tdsTokenError:
+			body := &tdsReader{b: r.next(int(r.u16()))}
+			number := int32(body.u32())
+			body.next(2) // state, class
+			msg := body.usVarChar()
+			if resp.errNumber == 0 && body.err == nil {
+				resp.errNumber = number
+				resp.errMessage = msg
+			}
+		case tdsTokenInfo, tdsTokenEnvChange, tdsTokenOrder, tdsTokenSSPI:
+			r.next(int(r.u16()))
+		case tdsTokenDone, tdsTokenDoneProc, tdsTokenDoneInProc:
+			r.next(12)
+		case tdsTokenReturnStatus:
+			r.next(4)
+		case tdsTokenColMetadata:
+			count := int(r.u16())
+			if count == 0xffff {
+				columns = nil
+				continue
+			}
+			columns = make([]tdsColumn, 0, count)
+			for i := 0; i < count && r.err == nil; i++ {
+				r.next(6) // user type, flags
+				col, err := readTDSTypeInfo(r)
+				if err != nil {
+					return resp, err
+				}
+				r.bVarChar()
+				columns = append(columns, col)
+			}
+		case tdsTokenRow, tdsTokenNBCRow:
+			var nulls []byte
+			if token == tdsTokenNBCRow {
+				nulls = r.next((len(columns) + 7) / 8)
+			}
+			for i, col := range columns {
+				if nulls != nil && nulls[i/8]&(1<<(i%8)) != 0 {
+					if !resp.hasValue && i == 0 {
+						resp.hasValue, resp.value = true, "NULL"
+					}
+					continue
+				}
+				value, err := readTDSValue(r, col)
+				if err != nil {
+					return resp, err
+				}
+				if !resp.hasValue && i == 0 {
+					resp.hasValue, resp.value = true, value
+				}
+			}
+		default:
+			return resp, ErrProtocol
+		}
+	}
+	if r.err != nil {
+		return resp, r.err
+	}
+	return resp, nil
+}
+
+func readTDSTypeInfo(r *tdsReader) (tdsColumn, error) {
+	col := tdsColumn{typ: r.u8()}
+	switch col.typ {
+	case 0x1f: // NULL
+	case 0x30, 0x32: // INT1, BIT
+		col.size = 1
+	case 0x34: // INT2
+		col.size = 2
+	case 0x38, 0x3a, 0x3b, 0x7a: // INT4, DATETIM4, FLT4, MONEY4
+		col.size = 4
+	case 0x3c, 0x3d, 0x3e, 0x7f: // MONEY, DATETIME, FLT8, INT8
+		col.size = 8
+	case 0x24, 0x26, 0x68, 0x6d, 0x6e, 0x6f: // GUID, INTN, BITN, FLTN, MONEYN, DATETIMN
+		col.size = int(r.u8())
+	case 0x37, 0x3f, 0x6a, 0x6c: // DECIMAL, NUMERIC, DECIMALN, NUMERICN
+		col.size = int(r.u8())
+		r.u8() // precision
+		col.scale = r.u8()
+	case 0x28: // DATEN
+	case 0x29, 0x2a, 0x2b: // TIMEN, DATETIME2N, DATETIMEOFFSETN
+		col.scale = r.u8()
+	case 0xa7, 0xaf, 0xe7, 0xef: // BIGVARCHAR, BIGCHAR, NVARCHAR, NCHAR
+		col.size = int(r.u16())
+		r.next(5) // collation
+		col.plp = col.size == 0xffff
+	case 0xa5, 0xad: // BIGVARBIN, BIGBINARY
+		col.size = int(r.u16())
+		col.plp = col.size == 0xffff
+	case 0x22, 0x23, 0x63: // IMAGE, TEXT, NTEXT
+		col.size = int(r.u32())
+		if col.typ != 0x22 {
+			r.next(5)
+		}
+		parts := int(r.u8())
+		for i := 0; i < parts; i++ {
+			r.usVarChar()
+		}
+	case 0xf1: // XML
+		if r.u8() != 0 {
+			r.bVarChar()
+			r.bVarChar()
+			r.usVarChar()
+		}
+		col.plp = true
+	case 0x62: // SQL_VARIANT
+		col.size = int(r.u32())
+	default:
+		return col, fmt.Errorf("%w: tds column type 0x%02x", ErrProtocol, col.typ)
+	}
+	return col, r.err
+}
+
+func readTDSValue(r *tdsReader, col tdsColumn) (string, error) {
+	var data []byte
+	switch {
+	case col.plp:
+		total := r.u64()
+		if total == math.MaxUint64 {
+			return "NULL", r.err
+		}
+		for r.err == nil {
+			chunk := int(r.u32())
+			if chunk == 0 {
+				break
+			}
+			if len(data)+chunk > tdsMaxResponse {
+				return "", ErrProtocol
+			}
+			data = append(data, r.next(chunk)...)
+		}
+	case col.typ == 0x1f:
+		return "NULL", nil
+	case col.typ == 0x22 || col.typ == 0x23 || col.typ == 0x63:
+		ptrLen := int(r.u8())
+		if ptrLen == 0 {
+			return "NULL", r.err
+		}
+		r.next(ptrLen + 8) // text pointer, timestamp
+		data = r.next(int(r.u32()))
+	case col.typ == 0x62:
+		size := int(r.u32())
+		if size == 0 {
+			return "NULL", r.err
+		}
+		r.next(size)
+		return "", r.err
+	case col.typ == 0xa5 || col.typ == 0xa7 || col.typ == 0xad || col.typ == 0xaf || col.typ == 0xe7 || col.typ == 0xef:
+		size := int(r.u16())
+		if size == 0xffff {
+			return "NULL", r.err
+		}
+		data = r.next(size)
+	case col.typ == 0x30 || col.typ == 0x32 || col.typ == 0x34 || col.typ == 0x38 || col.typ == 0x3a ||
+		col.typ == 0x3b || col.typ == 0x3c || col.typ == 0x3d || col.typ == 0x3e || col.typ == 0x7a || col.typ == 0x7f:
+		data = r.next(col.size)
+	default:
+		size := int(r.u8())
+		if size == 0 {
+			return "NULL", r.err
+		}
+		data = r.next(size)
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+	return formatTDSValue(col, data), nil
+}
+
+func formatTDSValue(col tdsColumn, data []byte) string {
+	switch col.typ {
+	case 0x30, 0x32, 0x34, 0x38, 0x7f, 0x26, 0x68:
+		return formatTDSInt(data, col.typ == 0x30 || col.typ == 0x32 || col.typ == 0x68)
+	case 0x3b, 0x3e, 0x6d:
+		if len(data) == 4 {
+			return strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), 'g', -1, 32)
+		}
+		if len(data) == 8 {
+			return strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)), 'g', -1, 64)
+		}
+	case 0x3c, 0x7a, 0x6e:
+		var v int64
+		if len(data) == 4 {
+			v = int64(int32(binary.LittleEndian.Uint32(data)))
+		} else if len(data) == 8 {
+			v = int64(binary.LittleEndian.Uint32(data[0:4]))<<32 | int64(binary.LittleEndian.Uint32(data[4:8]))
+		}
+		return formatScaled(big.NewInt(v), 4)
+	case 0x37, 0x3f, 0x6a, 0x6c:
+		if len(data) < 1 {
+			return ""
+		}
+		mag := make([]byte, len(data)-1)
+		for i := range mag {
+			mag[i] = data[len(data)-1-i]
+		}
+		v := new(big.Int).SetBytes(mag)
+		if data[0] == 0 {
+			v.Neg(v)
+		}
+		return formatScaled(v, int(col.scale))
+	case 0x24:
+		if len(data) == 16 {
+			return strings.ToUpper(fmt.Sprintf("%x-%x-%x-%x-%x",
+				[]byte{data[3], data[2], data[1], data[0]}, []byte{data[5], data[4]}, []byte{data[7], data[6]}, data[8:10], data[10:16]))
+		}
+	case 0xe7, 0xef, 0x63, 0xf1:
+		return fromUCS2(data)
+	case 0xa7, 0xaf, 0x23:
+		return string(data)
+	case 0xa5, 0xad, 0x22:
+		return "0x" + strings.ToUpper(hex.EncodeToString(data))
+	case 0x3a, 0x3d, 0x6f:
+		return formatTDSDateTime(data)
+	case 0x28, 0x29, 0x2a, 0x2b:
+		return formatTDSDate2(col, data)
+	}
+	return ""
+}
+
+func formatTDSInt(data []byte, unsigned bool) string {
+	switch len(data) {
+	case 1:
+		if unsigned {
+			return strconv.Itoa(int(data[0]))
+		}
+		return strconv.Itoa(int(int8(data[0])))
+	case 2:
+		return strconv.Itoa(int(int16(binary.LittleEndian.Uint16(data))))
+	case 4:
+		return strconv.FormatInt(int64(int32(binary.LittleEndian.Uint32(data))), 10)
+	case 8:
+		return strconv.FormatInt(int64(binary.LittleEndian.Uint64(data)), 10)
+	}
+	return ""
+}
+
+func formatScaled(v *big.Int, scale int) string {
+	s := new(big.Int).Abs(v).String()
+	if scale > 0 {
+		if len(s) <= scale {
+			s = strings.Repeat("0", scale-len(s)+1) + s
+		}
+		s = s[:len(s)-scale] + "." + s[len(s)-scale:]
+	}
+	if v.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+var tdsEpoch1900 = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func formatTDSDateTime(data []byte) string {
+	switch len(data) {
+	case 4:
+		days := binary.LittleEndian.Uint16(data[0:2])
+		minutes := binary.LittleEndian.Uint16(data[2:4])
+		t := tdsEpoch1900.AddDate(0, 0, int(days)).Add(time.Duration(minutes) * time.Minute)
+		return t.Format("2006-01-02 15:04:05")
+	case 8:
+		days := int32(binary.LittleEndian.Uint32(data[0:4]))
+		ticks := binary.LittleEndian.Uint32(data[4:8])
+		t := tdsEpoch1900.AddDate(0, 0, int(days)).Add(time.Duration(ticks) * time.Second / 300)
+		return t.Format("2006-01-02 15:04:05.000")
+	}
+	return ""
+}
+
+func formatTDSDate2(col tdsColumn, data []byte) string {
+	readDate := func(b []byte) time.Time {
+		days := int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+		return time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+	}
+	readTime := func(b []byte) time.Duration {
+		var v uint64
+		for i := len(b) - 1; i >= 0; i-- {
+			v = v<<8 | uint64(b[i])
+		}
+		for i := 0; i < 7-int(col.scale); i++ {
+			v *= 10
+		}
+		return time.Duration(v) * 100
+	}
+	switch col.typ {
+	case 0x28:
+		if len(data) == 3 {
+			return readDate(data).Format("2006-01-02")
+		}
+	case 0x29:
+		return time.Time{}.Add(readTime(data)).Format("15:04:05.9999999")
+	case 0x2a:
+		if len(data) > 3 {
+			n := len(data) - 3
+			return readDate(data[n:]).Add(readTime(data[:n])).Format("2006-01-02 15:04:05.9999999")
+		}
+	case 0x2b:
+		if len(data) > 5 {
+			n := len(data) - 5
+			offset := int(int16(binary.LittleEndian.Uint16(data[n+3:])))
+			t := readDate(data[n : n+3]).Add(readTime(data[:n]))
+			return t.In(time.FixedZone("", offset*60)).Format("2006-01-02 15:04:05.9999999 -07:00")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,520 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	mysqlClientLongPassword  = 0x00000001
+	mysqlClientConnectWithDB = 0x00000008
+	mysqlClientProtocol41    = 0x00000200
+	mysqlClientSSL           = 0x00000800
+	mysqlClientTransactions  = 0x00002000
+	mysqlClientSecureConn    = 0x00008000
+	mysqlClientPluginAuth    = 0x00080000
+
+	mysqlMaxPacket     = 1 << 24
+	mysqlMaxResponse   = 1 << 20
+	mysqlCharsetUTF8   = 33
+	mysqlNativeAuth    = "mysql_native_password"
+	mysqlCachingSHA2   = "caching_sha2_password"
+	mysqlComQuit       = 0x01
+	mysqlComQuery      = 0x03
+	mysqlPacketOK      = 0x00
+	mysqlPacketMore    = 0x01
+	mysqlPacketEOF     = 0xfe
+	mysqlPacketError   = 0xff
+	mysqlFastAuthOK    = 0x03
+	mysqlFullAuth      = 0x04
+	mysqlRequestPubKey = 0x02
+)
+
+// mysqlServerError is an ERR packet returned by the server.
+type mysqlServerError struct {
+	Code    uint16
+	Message string
+}
+
+func (e *mysqlServerError) Error() string {
+	return fmt.Sprintf("mysql error %d: %s", e.Code, e.Message)
+}
+
+type mysqlHandshake struct {
+	version  string
+	caps     uint32
+	scramble []byte
+	plugin   string
+}
+
+type mysqlConn struct {
+	conn net.Conn
+	seq  byte
+}
+
+func checkMySQL(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	conn, host, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultPortForType(TypeMySQL))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	opts := databaseOptions(m)
+	c := &mysqlConn{conn: conn}
+	packet, err := c.readPacket()
+	if err != nil {
+		return CheckResult{}, err
+	}
+	hs, err := parseMySQLHandshake(packet)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res := CheckResult{OK: true, ServerVersion: hs.version}
+	username := monitorUsername(m)
+	if username == "" && (opts.Query != "" || opts.ExpectedResult != "") {
+		res.OK = false
+		res.Error = "monitoring.error.credentialsRequired"
+		return res, nil
+	}
+
+	caps := uint32(mysqlClientLongPassword | mysqlClientProtocol41 | mysqlClientTransactions | mysqlClientSecureConn | mysqlClientPluginAuth)
+	if opts.Database != "" {
+		caps |= mysqlClientConnectWithDB
+	}
+	useTLS := false
+	switch {
+	case opts.TLSMode == DatabaseTLSDisable:
+	case hs.caps&mysqlClientSSL != 0:
+		useTLS = true
+	case opts.TLSMode == DatabaseTLSRequire:
+		res.OK = false
+		res.Error = "monitoring.error.tlsRequired"
+		return res, nil
+	}
+	if useTLS {
+		caps |= mysqlClientSSL
+		if err := c.writePacket(mysqlSSLRequest(caps & hs.caps)); err != nil {
+			return res, err
+		}
+		cfg, err := databaseTLSConfig(m, host, opts)
+		if err != nil {
+			return res, err
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return res, err
+		}
+		state := tlsConn.ConnectionState()
+		res.TLS = tlsFromState(&state)
+		c.conn = tlsConn
+	}
+	if username == "" {
+		// Without credentials the handshake (and TLS, if offered) is the health signal.
+		return res, nil
+	}
+
+	password := monitorPassword(m)
+	plugin := hs.plugin
+	if plugin == "" {
+		plugin = mysqlNativeAuth
+	}
+	authData, err := mysqlAuthResponse(plugin, hs.scramble, password)
+	if err != nil {
+		return res, err
+	}
+	if err := c.writePacket(mysqlHandshakeResponse(caps&(hs.caps|mysqlClientSSL), username, authData, opts.Database, plugin)); err != nil {
+		return res, err
+	}
+	if err := c.finishAuth(plugin, hs.scramble, password, useTLS); err != nil {
+		var serverErr *mysqlServerError
+		if errors.As(err, &serverErr) {
+			res.OK = false
+			res.Error = "monitoring.error.authFailed"
+			return res, nil
+		}
+		return res, err
+	}
+
+	query := opts.Query
+	if query == "" {
+		query = defaultDatabaseQuery
+	}
+	value, err := c.query(query)
+	if err != nil {
+		var serverErr *mysqlServerError
+		if errors.As(err, &serverErr) {
+			res.OK = false
+			res.Error = "monitoring.error.queryFailed"
+			return res, nil
+		}
+		return res, err
+	}
+	c.seq = 0
+	_ = c.writePacket([]byte{mysqlComQuit})
+	checkQueryResult(&res, value, opts)
+	return res, nil
+}
+
+func (c *mysqlConn) readPacket() ([]byte, error) {
+	var payload []byte
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(c.conn, hdr[:]); err != nil {
+			return nil, err
+		}
+		size := int(uint32(hdr[0]) | uint32(hdr[1])<<8 | uint32(hdr[2])<<16)
+		c.seq = hdr[3] + 1
+		if len(payload)+size > mysqlMaxResponse {
+			return nil, ErrProtocol
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(c.conn, buf); err != nil {
+			return nil, err
+		}
+		payload = append(payload, buf...)
+		if size < mysqlMaxPacket-1 {
+			return payload, nil
+		}
+	}
+}
+
+func (c *mysqlConn) writePacket(payload []byte) error {
+	if len(payload) >= mysqlMaxPacket-1 {
+		return errors.New("mysql packet too large")
+	}
+	buf := make([]byte, 4+len(payload))
+	buf[0] = byte(len(payload))
+	buf[1] = byte(len(payload) >> 8)
+	buf[2] = byte(len(payload) >> 16)
+	buf[3] = c.seq
+	copy(buf[4:], payload)
+	c.seq++
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+// finishAuth follows auth switch and caching_sha2_password exchanges until the server sends OK or ERR.
+func (c *mysqlConn) finishAuth(plugin string, scramble []byte, password string, secure bool) error {
+	for i := 0; i < 8; i++ {
+		packet, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+		if len(packet) == 0 {
+			return ErrProtocol
+		}
+		switch packet[0] {
+		case mysqlPacketOK:
+			return nil
+		case mysqlPacketError:
+			return parseMySQLError(packet)
+		case mysqlPacketEOF:
+			name, data := splitNullTerminated(packet[1:])
+			if name == "" {
+				return ErrProtocol
+			}
+			plugin = name
+			scramble = bytes.TrimRight(data, "\x00")
+			resp, err := mysqlAuthResponse(plugin, scramble, password)
+			if err != nil {
+				return err
+			}
+			if err := c.writePacket(resp); err != nil {
+				return err
+			}
+		case mysqlPacketMore:
+			if plugin != mysqlCachingSHA2 || len(packet) < 2 {
+				return ErrProtocol
+			}
+			switch packet[1] {
+			case mysqlFastAuthOK:
+				continue
+			case mysqlFullAuth:
+				if secure {
+					if err := c.writePacket(append([]byte(password), 0)); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := c.writePacket([]byte{mysqlRequestPubKey}); err != nil {
+					return err
+				}
+				keyPacket, err := c.readPacket()
+				if err != nil {
+					return err
+				}
+				if len(keyPacket) < 2 || keyPacket[0] != mysqlPacketMore {
+					return ErrProtocol
+				}
+				enc, err := mysqlEncryptPassword(keyPacket[1:], scramble, password)
+				if err != nil {
+					return err
+				}
+				if err := c.writePacket(enc); err != nil {
+					return err
+				}
+			default:
+				return ErrProtocol
+			}
+		default:
+			return ErrProtocol
+		}
+	}
+	return ErrProtocol
+}
+
+// query runs a text protocol query and returns the first column of the first row.
+func (c *mysqlConn) query(q string) (string, error) {
+	c.seq = 0
+	if err := c.writePacket(append([]byte{mysqlComQuery}, q...)); err != nil {
+		return "", err
+	}
+	packet, err := c.readPacket()
+	if err != nil {
+		return "", err
+	}
+	if len(packet) == 0 {
+		return "", ErrProtocol
+	}
+	switch packet[0] {
+	case mysqlPacketOK:
+		return "", nil
+	case mysqlPacketError:
+		return "", parseMySQLError(packet)
+	}
+	columns, _, ok := readLenEncInt(packet)
+	if !ok || columns == 0 {
+		return "", ErrProtocol
+	}
+	// Column definitions are followed by an EOF packet since CLIENT_DEPRECATE_EOF is not negotiated.
+	for i := uint64(0); i <= columns; i++ {
+		if _, err := c.readPacket(); err != nil {
+			return "", err
+		}
+	}
+	value := ""
+	first := true
+	for {
+		row, err := c.readPacket()
+		if err != nil {
+			return "", err
+		}
+		if len(row) == 0 {
+			return "", ErrProtocol
+		}
+		if row[0] == mysqlPacketEOF && len(row) < 9 {
+			return value, nil
+		}
+		if row[0] == mysqlPacketError {
+			return "", parseMySQLError(row)
+		}
+		if first {
+			first = false
+			if row[0] == 0xfb {
+				value = "NULL"
+				continue
+			}
+			n, size, ok := readLenEncInt(row)
+			if !ok || uint64(len(row)-size) < n {
+				return "", ErrProtocol
+			}
+			value = string(row[size : size+int(n)])
+		}
+	}
+}
+
+func parseMySQLHandshake(b []byte) (mysqlHandshake, error) {
+	var hs mysqlHandshake
+	if len(b) == 0 {
+		return hs, ErrProtocol
+	}
+	if b[0] == mysqlPacketError {
+		return hs, parseMySQLError(b)
+	}
+	// Only protocol v10 with CLIENT_PROTOCOL_41 (MySQL 4.1+) is supported.
+	if b[0] != 10 {
+		return hs, ErrProtocol
+	}
+	version, rest := splitNullTerminated(b[1:])
+	hs.version = version
+	// connection id (4), auth data part 1 (8), filler (1), capability flags lower (2)
+	if len(rest) < 15 {
+		return hs, ErrProtocol
+	}
+	hs.scramble = append([]byte{}, rest[4:12]...)
+	hs.caps = uint32(binary.LittleEndian.Uint16(rest[13:15]))
+	rest = rest[15:]
+	if len(rest) < 16 {
+		return hs, ErrProtocol
+	}
+	// charset (1), status (2), capability flags upper (2), auth data length (1), reserved (10)
+	hs.caps |= uint32(binary.LittleEndian.Uint16(rest[3:5])) << 16
+	authLen := int(rest[5])
+	rest = rest[16:]
+	if hs.caps&mysqlClientSecureConn != 0 {
+		n := authLen - 8
+		if n < 13 {
+			n = 13
+		}
+		if n > len(rest) {
+			n = len(rest)
+		}
+		hs.scramble = append(hs.scramble, bytes.TrimRight(rest[:n], "\x00")...)
+		rest = rest[n:]
+	}
+	if hs.caps&mysqlClientPluginAuth != 0 {
+		hs.plugin, _ = splitNullTerminated(rest)
+	}
+	if hs.caps&mysqlClientProtocol41 == 0 {
+		return hs, ErrProtocol
+	}
+	return hs, nil
+}
+
+func parseMySQLError(b []byte) error {
+	if len(b) < 3 || b[0] != mysqlPacketError {
+		return ErrProtocol
+	}
+	code := binary.LittleEndian.Uint16(b[1:3])
+	msg := b[3:]
+	// Protocol 4.1 adds a '#' marker and a 5-character SQL state.
+	if len(msg) >= 6 && msg[0] == '#' {
+		msg = msg[6:]
+	}
+	return &mysqlServerError{Code: code, Message: string(msg)}
+}
+
+func mysqlSSLRequest(caps uint32) []byte {
+	buf := make([]byte, 32)
+	binary.LittleEndian.PutUint32(buf[0:4], caps)
+	binary.LittleEndian.PutUint32(buf[4:8], mysqlMaxPacket)
+	buf[8] = mysqlCharsetUTF8
+	return buf
+}
+
+func mysqlHandshakeResponse(caps uint32, username string, authData []byte, database, plugin string) []byte {
+	buf := mysqlSSLRequest(caps)
+	buf = append(buf, username...)
+	buf = append(buf, 0, byte(len(authData)))
+	buf = append(buf, authData...)
+	if caps&mysqlClientConnectWithDB != 0 {
+		buf = append(buf, database...)
+		buf = append(buf, 0)
+	}
+	if caps&mysqlClientPluginAuth != 0 {
+		buf = append(buf, plugin...)
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+func mysqlAuthResponse(plugin string, scramble []byte, password string) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	if len(scramble) > 20 {
+		scramble = scramble[:20]
+	}
+	switch plugin {
+	case mysqlNativeAuth:
+		// SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password)))
+		stage1 := sha1.Sum([]byte(password))
+		stage2 := sha1.Sum(stage1[:])
+		h := sha1.New()
+		h.Write(scramble)
+		h.Write(stage2[:])
+		out := h.Sum(nil)
+		for i := range out {
+			out[i] ^= stage1[i]
+		}
+		return out, nil
+	case mysqlCachingSHA2:
+		// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble)
+		m1 := sha256.Sum256([]byte(password))
+		m2 := sha256.Sum256(m1[:])
+		h := sha256.New()
+		h.Write(m2[:])
+		h.Write(scramble)
+		out := h.Sum(nil)
+		for i := range out {
+			out[i] ^= m1[i]
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported mysql auth plugin %q", plugin)
+	}
+}
+
+func mysqlEncryptPassword(keyPEM, scramble []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, ErrProtocol
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		if pkcs1, pkcs1Err := x509.ParsePKCS1PublicKey(block.Bytes); pkcs1Err == nil {
+			parsed = pkcs1
+		} else {
+			return nil, err
+		}
+	}
+	pub, ok := parsed.(*rsa.PublicKey)
+	if !ok || len(scramble) == 0 {
+		return nil, ErrProtocol
+	}
+	plain := append([]byte(password), 0)
+	for i := range plain {
+		plain[i] ^= scramble[i%len(scramble)]
+	}
+	return rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, plain, nil)
+}
+
+func splitNullTerminated(b []byte) (string, []byte) {
+	idx := bytes.IndexByte(b, 0)
+	if idx < 0 {
+		return string(b), nil
+	}
+	return string(b[:idx]), b[idx+1:]
+}
+
+// readLenEncInt decodes a MySQL length-encoded integer and returns the value and its size.
+func readLenEncInt(b []byte) (uint64, int, bool) {
+	if len(b) == 0 {
+		return 0, 0, false
+	}
+	switch b[0] {
+	case 0xfc:
+		if len(b) < 3 {
+			return 0, 0, false
+		}
+		return uint64(binary.LittleEndian.Uint16(b[1:3])), 3, true
+	case 0xfd:
+		if len(b) < 4 {
+			return 0, 0, false
+		}
+		return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, 4, true
+	case 0xfe:
+		if len(b) < 9 {
+			return 0, 0, false
+		}
+		return binary.LittleEndian.Uint64(b[1:9]), 9, true
+	case 0xfb, 0xff:
+		return 0, 0, false
+	default:
+		return uint64(b[0]), 1, true
+	}
+}
@@ -0,0 +1,388 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+var (
+	ErrICMPUnavailable = errors.New("icmp sockets unavailable")
+	errPingNoReply     = errors.New("no echo reply")
+)
+
+const (
+	defaultPingCount      = 4
+	maxPingCount          = 20
+	defaultPingIntervalMs = 200
+	defaultPingSize       = 56
+	maxPingSize           = 1472
+	pingNonceSize         = 8
+	// pingReplyWait is how long a single echo request waits for its reply before it counts as lost.
+	pingReplyWait = 2 * time.Second
+)
+
+const (
+	icmpv4EchoRequest = 8
+	icmpv4EchoReply   = 0
+	icmpv6EchoRequest = 128
+	icmpv6EchoReply   = 129
+)
+
+// PingStats summarizes one series of ICMP echo requests.
+type PingStats struct {
+	Sent     int
+	Received int
+	LossPct  float64
+	MinMs    float64
+	AvgMs    float64
+	MaxMs    float64
+	JitterMs float64
+}
+
+// pingLatencyMs is the average RTT in whole milliseconds. Answered pings report at least 1 ms, so sub-millisecond
+// LAN round trips are not replaced with the duration of the whole series.
+func pingLatencyMs(stats PingStats) int {
+	if stats.Received == 0 {
+		return 0
+	}
+	return max(int(math.Round(stats.AvgMs)), 1)
+}
+
+func checkPing(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	host, port := pingTarget(m)
+	if host == "" {
+		return CheckResult{}, errors.New("empty host")
+	}
+	if err := guardTarget(ctx, host, settings); err != nil {
+		return CheckResult{}, err
+	}
+	ip, err := resolvePingIP(ctx, host)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if !newNetworkPolicy(settings).permits(ip) {
+		return CheckResult{}, ErrPrivateBlocked
+	}
+	opts := normalizedPingOptions(m.Options.Ping)
+	stats, err := icmpEcho(ctx, ip, opts, timeout)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res := CheckResult{OK: true, Ping: &stats, LatencyMs: pingLatencyMs(stats)}
+	if stats.Received == 0 {
+		res.OK = false
+		res.Error = "monitoring.error.pingNoReply"
+		return res, errPingNoReply
+	}
+	evaluatePingThresholds(&res, stats, opts)
+	if res.OK && port > 0 {
+		addr := net.JoinHostPort(ip.String(), strconv.Itoa(port))
+		conn, err := guardedDialer(settings, timeout).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return res, err
+		}
+		_ = conn.Close()
+	}
+	return res, nil
+}
+
+func evaluatePingThresholds(res *CheckResult, stats PingStats, opts store.PingOptions) {
+	switch {
+	case opts.MaxLossPct != nil && stats.LossPct > *opts.MaxLossPct:
+		res.OK = false
+		res.Error = "monitoring.error.pingLossHigh"
+	case opts.MaxRTTMs != nil && stats.AvgMs > *opts.MaxRTTMs:
+		res.OK = false
+		res.Error = "monitoring.error.pingRttHigh"
+	case opts.DegradedLossPct != nil && stats.LossPct > *opts.DegradedLossPct:
+		res.Degraded = true
+		res.Error = "monitoring.error.pingLossHigh"
+	case opts.DegradedRTTMs != nil && stats.AvgMs > *opts.DegradedRTTMs:
+		res.Degraded = true
+		res.Error = "monitoring.error.pingRttHigh"
+	}
+}
+
+func pingTarget(m store.Monitor) (string, int) {
+	host := strings.TrimSpace(m.Host)
+	if host == "" {
+		host = strings.TrimSpace(m.URL)
+	}
+	if strings.Contains(host, "://") {
+		if u, err := parseMonitorURL(host); err == nil && strings.TrimSpace(u.Hostname()) != "" {
+			host = strings.TrimSpace(u.Hostname())
+		}
+	}
+	if strings.Contains(host, "/") && !strings.Contains(host, ":") {
+		host = strings.SplitN(host, "/", 2)[0]
+	}
+	port := m.Port
+	if parsedHost, parsedPort := splitHostPort(host); parsedHost != "" {
+		host = parsedHost
+		if port <= 0 && parsedPort > 0 {
+			port = parsedPort
+		}
+	}
+	return host, port
+}
+
+func resolvePingIP(ctx context.Context, host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			return addr.IP, nil
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no host records")
+	}
+	return addrs[0].IP, nil
+}
+
+func normalizedPingOptions(raw *store.PingOptions) store.PingOptions {
+	var opts store.PingOptions
+	if raw != nil {
+		opts = *raw
+	}
+	if opts.Count <= 0 {
+		opts.Count = defaultPingCount
+	}
+	if opts.Count > maxPingCount {
+		opts.Count = maxPingCount
+	}
+	if opts.IntervalMs <= 0 {
+		opts.IntervalMs = defaultPingIntervalMs
+	}
+	if opts.IntervalMs < 20 {
+		opts.IntervalMs = 20
+	}
+	if opts.PacketSize <= 0 {
+		opts.PacketSize = defaultPingSize
+	}
+	if opts.PacketSize < pingNonceSize {
+		opts.PacketSize = pingNonceSize
+	}
+	if opts.PacketSize > maxPingSize {
+		opts.PacketSize = maxPingSize
+	}
+	return opts
+}
+
+type pingReply struct {
+	seq int
+	at  time.Time
+}
+
+func icmpEcho(ctx context.Context, ip net.IP, opts store.PingOptions, timeout time.Duration) (PingStats, error) {
+	v6 := ip.To4() == nil
+	conn, datagram, err := listenICMP(v6)
+	if err != nil {
+		return PingStats{}, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetReadDeadline(deadline)
+
+	nonce := make([]byte, pingNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return PingStats{}, err
+	}
+	id := int(binary.BigEndian.Uint16(nonce[:2]))
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	if datagram {
+		dst = &net.UDPAddr{IP: ip}
+	}
+
+	replies := make(chan pingReply, opts.Count)
+	go func() {
+		defer close(replies)
+		buf := make([]byte, maxPingSize+64)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if seq, ok := parseEchoReply(buf[:n], v6, nonce); ok {
+				select {
+				case replies <- pingReply{seq: seq, at: time.Now()}:
+				default:
+				}
+			}
+		}
+	}()
+
+	interval := time.Duration(opts.IntervalMs) * time.Millisecond
+	// Keep the whole series inside the check timeout.
+	if maxInterval := timeout / time.Duration(opts.Count+1); interval > maxInterval {
+		interval = maxInterval
+	}
+	return runPingSeries(ctx, opts.Count, interval, pingReplyWait, func(seq int) error {
+		_, err := conn.WriteTo(buildEchoRequest(v6, id, seq, nonce, opts.PacketSize), dst)
+		return err
+	}, replies)
+}
+
+// runPingSeries sends count requests one interval apart and matches the replies to them. It stops once every
+// request is sent and each one is answered or has waited replyWait, so a lost packet costs its own reply
+// wait rather than the whole check timeout; replies is closed when the check deadline passes.
+func runPingSeries(ctx context.Context, count int, interval, replyWait time.Duration, send func(seq int) error, replies <-chan pingReply) (PingStats, error) {
+	sent := 0
+	pending := make(map[int]time.Time, count)
+	rtts := make([]float64, 0, count)
+	sendNext := func() error {
+		sent++
+		pending[sent] = time.Now()
+		return send(sent)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	if err := sendNext(); err != nil {
+		return PingStats{}, err
+	}
+	for sent < count || len(pending) > 0 {
+		var expiry *time.Timer
+		var expired <-chan time.Time
+		if len(pending) > 0 {
+			oldest := time.Time{}
+			for _, at := range pending {
+				if oldest.IsZero() || at.Before(oldest) {
+					oldest = at
+				}
+			}
+			expiry = time.NewTimer(time.Until(oldest.Add(replyWait)))
+			expired = expiry.C
+		}
+		select {
+		case <-ctx.Done():
+			return pingStats(sent, rtts), nil
+		case now := <-expired:
+			for seq, at := range pending {
+				if !now.Before(at.Add(replyWait)) {
+					delete(pending, seq)
+				}
+			}
+		case <-ticker.C:
+			if sent < count {
+				if err := sendNext(); err != nil {
+					return PingStats{}, err
+				}
+			}
+		case reply, ok := <-replies:
+			if !ok {
+				return pingStats(sent, rtts), nil
+			}
+			if start, known := pending[reply.seq]; known {
+				delete(pending, reply.seq)
+				rtts = append(rtts, float64(reply.at.Sub(start).Microseconds())/1000)
+			}
+		}
+		if expiry != nil {
+			expiry.Stop()
+		}
+	}
+	return pingStats(sent, rtts), nil
+}
+
+func pingStats(sent int, rtts []float64) PingStats {
+	stats := PingStats{Sent: sent, Received: len(rtts)}
+	if sent > 0 {
+		stats.LossPct = float64(sent-len(rtts)) * 100 / float64(sent)
+	}
+	if len(rtts) == 0 {
+		return stats
+	}
+	stats.MinMs = rtts[0]
+	sum := 0.0
+	jitter := 0.0
+	for i, rtt := range rtts {
+		sum += rtt
+		if rtt < stats.MinMs {
+			stats.MinMs = rtt
+		}
+		if rtt > stats.MaxMs {
+			stats.MaxMs = rtt
+		}
+		if i > 0 {
+			jitter += math.Abs(rtt - rtts[i-1])
+		}
+	}
+	stats.AvgMs = sum / float64(len(rtts))
+	if len(rtts) > 1 {
+		stats.JitterMs = jitter / float64(len(rtts)-1)
+	}
+	return stats
+}
+
+func buildEchoRequest(v6 bool, id, seq int, nonce []byte, size int) []byte {
+	msg := make([]byte, 8+size)
+	msg[0] = icmpv4EchoRequest
+	if v6 {
+		msg[0] = icmpv6EchoRequest
+	}
+	binary.BigEndian.PutUint16(msg[4:6], uint16(id))
+	binary.BigEndian.PutUint16(msg[6:8], uint16(seq))
+	copy(msg[8:], nonce)
+	// ICMPv6 checksums are filled in by the kernel.
+	if !v6 {
+		binary.BigEndian.PutUint16(msg[2:4], icmpChecksum(msg))
+	}
+	return msg
+}
+
+func parseEchoReply(b []byte, v6 bool, nonce []byte) (int, bool) {
+	// Some platforms deliver the IPv4 header on datagram ICMP sockets.
+	if !v6 && len(b) >= 20 && b[0]>>4 == 4 {
+		hdrLen := int(b[0]&0x0f) * 4
+		if len(b) < hdrLen {
+			return 0, false
+		}
+		b = b[hdrLen:]
+	}
+	if len(b) < 8+len(nonce) {
+		return 0, false
+	}
+	want := byte(icmpv4EchoReply)
+	if v6 {
+		want = icmpv6EchoReply
+	}
+	if b[0] != want || b[1] != 0 {
+		return 0, false
+	}
+	if string(b[8:8+len(nonce)]) != string(nonce) {
+		return 0, false
+	}
+	return int(binary.BigEndian.Uint16(b[6:8])), true
+}
+
+func icmpChecksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
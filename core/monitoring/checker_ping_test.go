@@ -0,0 +1,118 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func TestPingStatsLossAndJitter(t *testing.T) {
+	stats := pingStats(4, []float64{10, 14, 12})
+	if stats.Sent != 4 || stats.Received != 3 {
+		t.Fatalf("unexpected counters: %+v", stats)
+	}
+	if stats.LossPct != 25 {
+		t.Fatalf("expected 25%% loss, got %v", stats.LossPct)
+	}
+	if stats.MinMs != 10 || stats.MaxMs != 14 || stats.AvgMs != 12 {
+		t.Fatalf("unexpected rtt: %+v", stats)
+	}
+	if stats.JitterMs != 3 {
+		t.Fatalf("expected jitter 3, got %v", stats.JitterMs)
+	}
+}
+
+func TestEvaluatePingThresholds(t *testing.T) {
+	maxLoss := 50.0
+	degradedRTT := 5.0
+	opts := store.PingOptions{MaxLossPct: &maxLoss, DegradedRTTMs: &degradedRTT}
+
+	res := CheckResult{OK: true}
+	evaluatePingThresholds(&res, PingStats{Sent: 4, Received: 1, LossPct: 75, AvgMs: 1}, opts)
+	if res.OK || res.Error != "monitoring.error.pingLossHigh" {
+		t.Fatalf("expected loss threshold to mark down, got %+v", res)
+	}
+
+	res = CheckResult{OK: true}
+	evaluatePingThresholds(&res, PingStats{Sent: 4, Received: 4, AvgMs: 12}, opts)
+	if !res.OK || !res.Degraded || res.Error != "monitoring.error.pingRttHigh" {
+		t.Fatalf("expected degraded rtt result, got %+v", res)
+	}
+}
+
+func TestEchoRequestRoundTrip(t *testing.T) {
+	nonce := []byte("12345678")
+	msg := buildEchoRequest(false, 7, 3, nonce, 56)
+	if icmpChecksum(msg) != 0 {
+		t.Fatalf("expected valid checksum")
+	}
+	msg[0] = icmpv4EchoReply
+	seq, ok := parseEchoReply(msg, false, nonce)
+	if !ok || seq != 3 {
+		t.Fatalf("expected reply seq 3, got %d ok=%v", seq, ok)
+	}
+	if _, ok := parseEchoReply(msg, false, []byte("87654321")); ok {
+		t.Fatalf("expected foreign nonce to be ignored")
+	}
+}
+
+func TestCheckMonitorPingReportsStats(t *testing.T) {
+	res := CheckMonitor(context.Background(), store.Monitor{
+		Type:       TypePing,
+		Host:       "127.0.0.1",
+		TimeoutSec: 2,
+		Options:    store.MonitorOptions{Ping: &store.PingOptions{Count: 3, IntervalMs: 20}},
+	}, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2})
+	if res.Error == "monitoring.error.icmpUnavailable" {
+		t.Skip("icmp sockets are not permitted in this environment")
+	}
+	if !res.OK || res.Ping == nil {
+		t.Fatalf("expected ok with stats, got error=%s", res.Error)
+	}
+	if res.Ping.Sent != 3 || res.Ping.Received != 3 || res.Ping.LossPct != 0 {
+		t.Fatalf("unexpected stats: %+v", res.Ping)
+	}
+	if res.LatencyMs != pingLatencyMs(*res.Ping) {
+		t.Fatalf("expected the RTT as latency, got %d ms for %+v", res.LatencyMs, res.Ping)
+	}
+}
+
+func TestPingLatencyMs(t *testing.T) {
+	cases := []struct {
+		stats PingStats
+		want  int
+	}{
+		{stats: PingStats{Sent: 3, Received: 3, AvgMs: 0.04}, want: 1},
+		{stats: PingStats{Sent: 3, Received: 3, AvgMs: 12.6}, want: 13},
+		{stats: PingStats{Sent: 3}, want: 0},
+	}
+	for _, tc := range cases {
+		if got := pingLatencyMs(tc.stats); got != tc.want {
+			t.Fatalf("%+v: got %d, want %d", tc.stats, got, tc.want)
+		}
+	}
+}
+
+func TestRunPingSeriesStopsAfterLostReply(t *testing.T) {
+	replies := make(chan pingReply, 4)
+	send := func(seq int) error {
+		// The second request is lost, every other one is answered right away.
+		if seq != 2 {
+			replies <- pingReply{seq: seq, at: time.Now()}
+		}
+		return nil
+	}
+	start := time.Now()
+	stats, err := runPingSeries(context.Background(), 4, 10*time.Millisecond, 200*time.Millisecond, send, replies)
+	if err != nil {
+		t.Fatalf("series: %v", err)
+	}
+	if stats.Sent != 4 || stats.Received != 3 || stats.LossPct != 25 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the series to stop once the lost request expired, took %s", elapsed)
+	}
+}
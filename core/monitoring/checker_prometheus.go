@@ -0,0 +1,414 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	maxPrometheusExpression = 1024
+	maxPrometheusMatchers   = 16
+	// maxPrometheusBody bounds the scraped exposition; large exporters stay well below it.
+	maxPrometheusBody = 8 << 20
+	prometheusAccept  = "application/openmetrics-text;version=1.0.0;q=0.9,text/plain;version=0.0.4;q=0.5,*/*;q=0.1"
+)
+
+var errInvalidPrometheusExpr = errors.New("invalid prometheus expression")
+
+var prometheusNameRe = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+type prometheusMatcher struct {
+	name  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (pm prometheusMatcher) matches(labels map[string]string) bool {
+	val := labels[pm.name]
+	switch pm.op {
+	case "=":
+		return val == pm.value
+	case "!=":
+		return val != pm.value
+	case "=~":
+		return pm.re.MatchString(val)
+	default:
+		return !pm.re.MatchString(val)
+	}
+}
+
+// prometheusExpr is a parsed threshold expression: [sum|max|min(]metric{matchers}[)] op number.
+type prometheusExpr struct {
+	// selector is the left-hand side as written; the evaluated value is stored under it.
+	selector  string
+	aggregate string
+	metric    string
+	matchers  []prometheusMatcher
+	op        string
+	threshold float64
+}
+
+// ValidPrometheusExpression reports whether the threshold expression of a prometheus monitor parses.
+func ValidPrometheusExpression(raw string) bool {
+	_, err := parsePrometheusExpr(raw)
+	return err == nil
+}
+
+func parsePrometheusExpr(raw string) (prometheusExpr, error) {
+	var expr prometheusExpr
+	text := strings.TrimSpace(raw)
+	if text == "" || len(text) > maxPrometheusExpression {
+		return expr, errInvalidPrometheusExpr
+	}
+	opAt, opLen := findPrometheusComparator(text)
+	if opAt < 0 {
+		return expr, errInvalidPrometheusExpr
+	}
+	expr.op = text[opAt : opAt+opLen]
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(text[opAt+opLen:]), 64)
+	if err != nil {
+		return expr, errInvalidPrometheusExpr
+	}
+	expr.threshold = threshold
+	left := strings.TrimSpace(text[:opAt])
+	expr.selector = left
+	if open := strings.IndexByte(left, '('); open > 0 {
+		expr.aggregate = strings.ToLower(strings.TrimSpace(left[:open]))
+		switch expr.aggregate {
+		case "sum", "max", "min":
+		default:
+			return expr, errInvalidPrometheusExpr
+		}
+		if !strings.HasSuffix(left, ")") {
+			return expr, errInvalidPrometheusExpr
+		}
+		left = strings.TrimSpace(left[open+1 : len(left)-1])
+	}
+	name := left
+	if brace := strings.IndexByte(left, '{'); brace >= 0 {
+		name = strings.TrimSpace(left[:brace])
+		if !strings.HasSuffix(left, "}") {
+			return expr, errInvalidPrometheusExpr
+		}
+		matchers, err := parsePrometheusMatchers(left[brace+1 : len(left)-1])
+		if err != nil {
+			return expr, err
+		}
+		expr.matchers = matchers
+	}
+	if !prometheusNameRe.MatchString(name) {
+		return expr, errInvalidPrometheusExpr
+	}
+	expr.metric = name
+	return expr, nil
+}
+
+// findPrometheusComparator returns the first comparison operator outside label matchers.
+func findPrometheusComparator(text string) (int, int) {
+	depth := 0
+	inQuote := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inQuote:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inQuote = false
+			}
+		case c == '"':
+			inQuote = true
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		case depth == 0:
+			two := ""
+			if i+1 < len(text) {
+				two = text[i : i+2]
+			}
+			switch two {
+			case ">=", "<=", "==", "!=":
+				return i, 2
+			}
+			if c == '>' || c == '<' {
+				return i, 1
+			}
+		}
+	}
+	return -1, 0
+}
+
+func parsePrometheusMatchers(raw string) ([]prometheusMatcher, error) {
+	var out []prometheusMatcher
+	rest := strings.TrimSpace(raw)
+	for rest != "" {
+		end := strings.IndexAny(rest, "=!")
+		if end <= 0 {
+			return nil, errInvalidPrometheusExpr
+		}
+		m := prometheusMatcher{name: strings.TrimSpace(rest[:end])}
+		if !prometheusNameRe.MatchString(m.name) || strings.Contains(m.name, ":") {
+			return nil, errInvalidPrometheusExpr
+		}
+		rest = rest[end:]
+		for _, op := range []string{"=~", "!~", "!=", "="} {
+			if strings.HasPrefix(rest, op) {
+				m.op = op
+				break
+			}
+		}
+		if m.op == "" {
+			return nil, errInvalidPrometheusExpr
+		}
+		rest = strings.TrimSpace(rest[len(m.op):])
+		value, tail, ok := readPrometheusQuoted(rest)
+		if !ok {
+			return nil, errInvalidPrometheusExpr
+		}
+		m.value = value
+		if m.op == "=~" || m.op == "!~" {
+			re, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return nil, errInvalidPrometheusExpr
+			}
+			m.re = re
+		}
+		out = append(out, m)
+		if len(out) > maxPrometheusMatchers {
+			return nil, errInvalidPrometheusExpr
+		}
+		rest = strings.TrimSpace(tail)
+		if rest == "" {
+			break
+		}
+		if rest[0] != ',' {
+			return nil, errInvalidPrometheusExpr
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+	return out, nil
+}
+
+// readPrometheusQuoted reads a double-quoted label value with \\, \" and \n escapes.
+func readPrometheusQuoted(text string) (string, string, bool) {
+	if !strings.HasPrefix(text, `"`) {
+		return "", "", false
+	}
+	var b strings.Builder
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		switch c {
+		case '\\':
+			if i+1 >= len(text) {
+				return "", "", false
+			}
+			i++
+			if text[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(text[i])
+			}
+		case '"':
+			return b.String(), text[i+1:], true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// parsePrometheusSample splits an exposition line into the metric name, labels and value.
+func parsePrometheusSample(line string) (string, map[string]string, float64, bool) {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return "", nil, 0, false
+	}
+	name := line[:end]
+	rest := line[end:]
+	labels := map[string]string{}
+	if rest[0] == '{' {
+		rest = rest[1:]
+		for {
+			rest = strings.TrimLeft(rest, " \t,")
+			if rest == "" {
+				return "", nil, 0, false
+			}
+			if rest[0] == '}' {
+				rest = rest[1:]
+				break
+			}
+			eq := strings.IndexByte(rest, '=')
+			if eq <= 0 {
+				return "", nil, 0, false
+			}
+			key := strings.TrimSpace(rest[:eq])
+			value, tail, ok := readPrometheusQuoted(strings.TrimSpace(rest[eq+1:]))
+			if !ok {
+				return "", nil, 0, false
+			}
+			labels[key] = value
+			rest = tail
+		}
+	}
+	// OpenMetrics exemplars follow the sample after " # ".
+	if idx := strings.Index(rest, " # "); idx >= 0 {
+		rest = rest[:idx]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, false
+	}
+	return name, labels, value, true
+}
+
+// scrapePrometheus collects the values of the series matching the expression selector.
+func scrapePrometheus(body io.Reader, expr prometheusExpr) ([]float64, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	var values []float64
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || !strings.HasPrefix(line, expr.metric) {
+			continue
+		}
+		name, labels, value, ok := parsePrometheusSample(line)
+		if !ok || name != expr.metric {
+			continue
+		}
+		matched := true
+		for _, m := range expr.matchers {
+			if !m.matches(labels) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			values = append(values, value)
+		}
+	}
+	return values, scanner.Err()
+}
+
+func (expr prometheusExpr) fold(values []float64) float64 {
+	out := values[0]
+	for _, v := range values[1:] {
+		switch expr.aggregate {
+		case "sum":
+			out += v
+		case "max":
+			out = math.Max(out, v)
+		case "min":
+			out = math.Min(out, v)
+		}
+	}
+	return out
+}
+
+func (expr prometheusExpr) holds(value float64) bool {
+	switch expr.op {
+	case ">":
+		return value > expr.threshold
+	case ">=":
+		return value >= expr.threshold
+	case "<":
+		return value < expr.threshold
+	case "<=":
+		return value <= expr.threshold
+	case "==":
+		return value == expr.threshold
+	default:
+		return value != expr.threshold
+	}
+}
+
+func checkPrometheus(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	parsed, err := parseMonitorURL(m.URL)
+	if err != nil {
+		return CheckResult{}, ErrInvalidURL
+	}
+	if err := guardTarget(ctx, parsed.Hostname(), settings); err != nil {
+		return CheckResult{}, err
+	}
+	if m.Options.Prometheus == nil {
+		return CheckResult{OK: false, Error: "monitoring.error.invalidPrometheusOptions"}, nil
+	}
+	expr, err := parsePrometheusExpr(m.Options.Prometheus.Expression)
+	if err != nil {
+		return CheckResult{OK: false, Error: "monitoring.error.invalidPrometheusOptions"}, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	for k, v := range m.Headers {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", prometheusAccept)
+	}
+	client, err := httpMonitorClient(m, settings, timeout)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer client.CloseIdleConnections()
+	if key, err := applyHTTPAuth(ctx, req, m, settings, client); err != nil {
+		return CheckResult{}, err
+	} else if key != "" {
+		return CheckResult{OK: false, Error: key}, nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer resp.Body.Close()
+	code := resp.StatusCode
+	if code == http.StatusUnauthorized {
+		forgetOAuth2Token(m)
+	}
+	res := CheckResult{StatusCode: &code}
+	if resp.TLS != nil {
+		res.TLS = tlsFromState(resp.TLS)
+	}
+	if !statusAllowed(code, m.AllowedStatus) {
+		res.Error = fmt.Sprintf("status_%d", code)
+		return res, nil
+	}
+	values, err := scrapePrometheus(io.LimitReader(resp.Body, maxPrometheusBody), expr)
+	if err != nil {
+		res.Error = "monitoring.error.invalidPrometheusResponse"
+		return res, nil
+	}
+	switch {
+	case len(values) == 0:
+		res.Error = "monitoring.error.prometheusNoSeries"
+		return res, nil
+	case len(values) > 1 && expr.aggregate == "":
+		res.Error = fmt.Sprintf("monitoring.error.prometheusAmbiguous: %d", len(values))
+		return res, nil
+	}
+	value := expr.fold(values)
+	res.Values = map[string]float64{expr.selector: value}
+	if expr.holds(value) {
+		res.Error = fmt.Sprintf("monitoring.error.prometheusThreshold: %s", strconv.FormatFloat(value, 'g', -1, 64))
+		return res, nil
+	}
+	res.OK = true
+	return res, nil
+}
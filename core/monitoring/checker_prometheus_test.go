@@ -0,0 +1,143 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+const prometheusExposition = `# HELP queue_depth Messages waiting per queue.
+# TYPE queue_depth gauge
+queue_depth{queue="alerts",region="eu"} 1200
+queue_depth{queue="alerts",region="us"} 300
+queue_depth{queue="mail",region="eu"} 5 1700000000000
+queue_depth_total 99
+# TYPE up gauge
+up{job="api-eu",instance="a:9100"} 1
+up{job="api-us",instance="b:9100"} 0
+up{job="worker",instance="c\"x\\y"} 1 # {trace_id="abc"} 1
+`
+
+func TestParsePrometheusExpr(t *testing.T) {
+	expr, err := parsePrometheusExpr(` sum(queue_depth{queue="alerts", region=~"e.|us"}) >= 1500 `)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if expr.aggregate != "sum" || expr.metric != "queue_depth" || expr.op != ">=" || expr.threshold != 1500 || len(expr.matchers) != 2 {
+		t.Fatalf("unexpected expression %+v", expr)
+	}
+	if expr.selector != `sum(queue_depth{queue="alerts", region=~"e.|us"})` {
+		t.Fatalf("unexpected selector %q", expr.selector)
+	}
+	if expr, err := parsePrometheusExpr(`label_check{v="a>b"} != 0`); err != nil || expr.op != "!=" || expr.matchers[0].value != "a>b" {
+		t.Fatalf("expected comparators inside label values to be skipped, got %+v %v", expr, err)
+	}
+	for _, raw := range []string{
+		"",
+		"queue_depth",
+		"queue_depth >",
+		"queue_depth > abc",
+		"avg(queue_depth) > 1",
+		`queue_depth{queue="alerts" > 1`,
+		`queue_depth{queue=alerts} > 1`,
+		`queue_depth{queue=~"("} > 1`,
+		`1queue > 1`,
+		`queue_depth{queue="` + strings.Repeat("a", maxPrometheusExpression) + `"} > 1`,
+	} {
+		if ValidPrometheusExpression(raw) {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestScrapePrometheus(t *testing.T) {
+	cases := []struct {
+		expr string
+		want []float64
+	}{
+		{expr: `queue_depth{queue="alerts"} > 0`, want: []float64{1200, 300}},
+		{expr: `queue_depth{queue="mail"} > 0`, want: []float64{5}},
+		{expr: `queue_depth{queue!="alerts"} > 0`, want: []float64{5}},
+		{expr: `up{job=~"api-.*"} > 0`, want: []float64{1, 0}},
+		{expr: `up{job!~"api"} > 0`, want: []float64{1, 0, 1}},
+		{expr: `up{instance="c\"x\\y"} > 0`, want: []float64{1}},
+		{expr: `queue_depth_total > 0`, want: []float64{99}},
+		{expr: `missing > 0`},
+	}
+	for _, tc := range cases {
+		expr, err := parsePrometheusExpr(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		got, err := scrapePrometheus(strings.NewReader(prometheusExposition), expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.expr, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%s: got %v, want %v", tc.expr, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestCheckMonitorPrometheus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer scrape-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "openmetrics") {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(prometheusExposition))
+	}))
+	defer srv.Close()
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+
+	cases := []struct {
+		expr  string
+		ok    bool
+		err   string
+		value float64
+	}{
+		{expr: `queue_depth{queue="alerts",region="eu"} > 1000`, err: "monitoring.error.prometheusThreshold: 1200", value: 1200},
+		{expr: `queue_depth{queue="mail"} > 1000`, ok: true, value: 5},
+		{expr: `sum(queue_depth{queue="alerts"}) > 2000`, ok: true, value: 1500},
+		{expr: `min(up{job=~"api.*"}) == 0`, err: "monitoring.error.prometheusThreshold: 0", value: 0},
+		{expr: `max(up{job=~"api.*"}) == 0`, ok: true, value: 1},
+		{expr: `queue_depth{queue="alerts"} > 1000`, err: "monitoring.error.prometheusAmbiguous: 2"},
+		{expr: `queue_depth{queue="unknown"} > 1000`, err: "monitoring.error.prometheusNoSeries"},
+	}
+	for _, tc := range cases {
+		mon := store.Monitor{Type: TypePrometheus, URL: srv.URL + "/metrics", Method: http.MethodGet, TimeoutSec: 3,
+			AllowedStatus: []string{"200-299"}, Credentials: &store.MonitorCredentials{Token: "scrape-token"}}
+		mon.Options.HTTP = &store.HTTPOptions{Auth: HTTPAuthBearer}
+		mon.Options.Prometheus = &store.PrometheusOptions{Expression: tc.expr}
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v error=%q", tc.expr, res.OK, res.Error)
+		}
+		if tc.ok || strings.HasPrefix(tc.err, "monitoring.error.prometheusThreshold") {
+			selector, _ := parsePrometheusExpr(tc.expr)
+			if got, ok := res.Values[selector.selector]; !ok || got != tc.value {
+				t.Fatalf("%s: expected value %v, got %v", tc.expr, tc.value, res.Values)
+			}
+		}
+	}
+
+	mon := store.Monitor{Type: TypePrometheus, URL: srv.URL + "/metrics", Method: http.MethodGet, TimeoutSec: 3, AllowedStatus: []string{"200-299"}}
+	mon.Options.Prometheus = &store.PrometheusOptions{Expression: `up > 0`}
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "status_401" {
+		t.Fatalf("expected an unauthenticated scrape to fail, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
@@ -0,0 +1,250 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	RadiusAuthPAP  = "pap"
+	RadiusAuthCHAP = "chap"
+
+	RadiusExpectAccept = "accept"
+	RadiusExpectReject = "reject"
+)
+
+const (
+	radiusAccessRequest   = 1
+	radiusAccessAccept    = 2
+	radiusAccessReject    = 3
+	radiusAccessChallenge = 11
+
+	radiusAttrUserName             = 1
+	radiusAttrUserPassword         = 2
+	radiusAttrCHAPPassword         = 3
+	radiusAttrNASIdentifier        = 32
+	radiusAttrMessageAuthenticator = 80
+
+	radiusMaxPacket            = 4096
+	radiusMaxPasswordLen       = 128
+	radiusDefaultNASIdentifier = "berkut-scc"
+)
+
+// NormalizeRadiusAuthMethod returns pap or chap, or an empty string for unknown values.
+func NormalizeRadiusAuthMethod(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", RadiusAuthPAP:
+		return RadiusAuthPAP
+	case RadiusAuthCHAP:
+		return RadiusAuthCHAP
+	default:
+		return ""
+	}
+}
+
+// NormalizeRadiusExpectedResult returns accept or reject, or an empty string for unknown values.
+func NormalizeRadiusExpectedResult(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", RadiusExpectAccept:
+		return RadiusExpectAccept
+	case RadiusExpectReject:
+		return RadiusExpectReject
+	default:
+		return ""
+	}
+}
+
+func radiusOptions(m store.Monitor) store.RadiusOptions {
+	var opts store.RadiusOptions
+	if m.Options.Radius != nil {
+		opts = *m.Options.Radius
+	}
+	opts.AuthMethod = NormalizeRadiusAuthMethod(opts.AuthMethod)
+	if opts.AuthMethod == "" {
+		opts.AuthMethod = RadiusAuthPAP
+	}
+	opts.ExpectedResult = NormalizeRadiusExpectedResult(opts.ExpectedResult)
+	if opts.ExpectedResult == "" {
+		opts.ExpectedResult = RadiusExpectAccept
+	}
+	opts.NASIdentifier = strings.TrimSpace(opts.NASIdentifier)
+	if opts.NASIdentifier == "" {
+		opts.NASIdentifier = radiusDefaultNASIdentifier
+	}
+	return opts
+}
+
+func checkRadius(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := radiusOptions(m)
+	user := monitorUsername(m)
+	if user == "" || m.Credentials == nil || m.Credentials.Secret == "" {
+		return CheckResult{OK: false, Error: "monitoring.error.credentialsRequired"}, nil
+	}
+	secret := []byte(m.Credentials.Secret)
+	request, err := buildRadiusAccessRequest(secret, user, monitorPassword(m), opts)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	conn, _, err := dialMonitorNetwork(ctx, "udp", m, settings, timeout, DefaultPortForType(TypeRadius))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write(request); err != nil {
+		return CheckResult{}, err
+	}
+	buf := make([]byte, radiusMaxPacket)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		resp := buf[:n]
+		// Late answers to earlier probes carry a different identifier and are skipped.
+		if len(resp) < 20 || resp[1] != request[1] {
+			continue
+		}
+		return radiusResult(request, resp, secret, opts.ExpectedResult), nil
+	}
+}
+
+func radiusResult(request, resp, secret []byte, expected string) CheckResult {
+	if !verifyRadiusResponse(request, resp, secret) {
+		return CheckResult{OK: false, Error: "monitoring.error.radiusBadAuthenticator"}
+	}
+	switch resp[0] {
+	case radiusAccessAccept:
+		if expected == RadiusExpectReject {
+			return CheckResult{OK: false, Error: "monitoring.error.radiusUnexpectedAccept"}
+		}
+		return CheckResult{OK: true}
+	case radiusAccessReject:
+		if expected == RadiusExpectReject {
+			return CheckResult{OK: true}
+		}
+		return CheckResult{OK: false, Error: "monitoring.error.authFailed"}
+	case radiusAccessChallenge:
+		return CheckResult{OK: false, Error: "monitoring.error.radiusChallenge"}
+	default:
+		return CheckResult{OK: false, Error: "monitoring.error.protocolError"}
+	}
+}
+
+func buildRadiusAccessRequest(secret []byte, user, password string, opts store.RadiusOptions) ([]byte, error) {
+	if len(password) > radiusMaxPasswordLen {
+		return nil, errors.New("radius password too long")
+	}
+	header := make([]byte, 20)
+	if _, err := rand.Read(header[1:2]); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(header[4:20]); err != nil {
+		return nil, err
+	}
+	header[0] = radiusAccessRequest
+	authenticator := header[4:20]
+	var attrs bytes.Buffer
+	appendRadiusAttr(&attrs, radiusAttrUserName, []byte(user))
+	if opts.AuthMethod == RadiusAuthCHAP {
+		// The request authenticator doubles as the CHAP challenge (RFC 2865, section 2.2).
+		chapID := header[1]
+		h := md5.New()
+		h.Write([]byte{chapID})
+		h.Write([]byte(password))
+		h.Write(authenticator)
+		appendRadiusAttr(&attrs, radiusAttrCHAPPassword, append([]byte{chapID}, h.Sum(nil)...))
+	} else {
+		appendRadiusAttr(&attrs, radiusAttrUserPassword, radiusHidePassword(password, secret, authenticator))
+	}
+	appendRadiusAttr(&attrs, radiusAttrNASIdentifier, []byte(opts.NASIdentifier))
+	// Message-Authenticator is sent on every request; servers hardened against
+	// BlastRADIUS reject requests without it.
+	maOffset := 20 + attrs.Len() + 2
+	appendRadiusAttr(&attrs, radiusAttrMessageAuthenticator, make([]byte, 16))
+	packet := append(header, attrs.Bytes()...)
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)))
+	mac := hmac.New(md5.New, secret)
+	mac.Write(packet)
+	copy(packet[maOffset:], mac.Sum(nil))
+	return packet, nil
+}
+
+// radiusHidePassword implements User-Password hiding from RFC 2865, section 5.2.
+func radiusHidePassword(password string, secret, authenticator []byte) []byte {
+	size := (len(password) + 15) / 16 * 16
+	if size == 0 {
+		size = 16
+	}
+	out := make([]byte, size)
+	copy(out, password)
+	prev := authenticator
+	for i := 0; i < size; i += 16 {
+		h := md5.New()
+		h.Write(secret)
+		h.Write(prev)
+		sum := h.Sum(nil)
+		for j := 0; j < 16; j++ {
+			out[i+j] ^= sum[j]
+		}
+		prev = out[i : i+16]
+	}
+	return out
+}
+
+func appendRadiusAttr(buf *bytes.Buffer, kind byte, value []byte) {
+	if len(value) > 253 {
+		value = value[:253]
+	}
+	buf.WriteByte(kind)
+	buf.WriteByte(byte(len(value) + 2))
+	buf.Write(value)
+}
+
+// verifyRadiusResponse checks the Response Authenticator and, when present, the
+// Message-Authenticator of a reply; a mismatch usually means a wrong shared secret.
+func verifyRadiusResponse(request, resp, secret []byte) bool {
+	length := int(binary.BigEndian.Uint16(resp[2:4]))
+	if length < 20 || length > len(resp) {
+		return false
+	}
+	resp = resp[:length]
+	h := md5.New()
+	h.Write(resp[:4])
+	h.Write(request[4:20])
+	h.Write(resp[20:])
+	h.Write(secret)
+	if !hmac.Equal(h.Sum(nil), resp[4:20]) {
+		return false
+	}
+	for attrs := resp[20:]; len(attrs) >= 2; {
+		size := int(attrs[1])
+		if size < 2 || size > len(attrs) {
+			return false
+		}
+		if attrs[0] == radiusAttrMessageAuthenticator {
+			if size != 18 {
+				return false
+			}
+			offset := length - len(attrs) + 2
+			check := append([]byte(nil), resp...)
+			copy(check[4:20], request[4:20])
+			copy(check[offset:offset+16], make([]byte, 16))
+			mac := hmac.New(md5.New, secret)
+			mac.Write(check)
+			if !hmac.Equal(mac.Sum(nil), resp[offset:offset+16]) {
+				return false
+			}
+		}
+		attrs = attrs[size:]
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorRadius(t *testing.T) {
+	host, port := startFakeRadius(t, "shared", "probe", "pass123")
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeRadius, Host: host, Port: port, TimeoutSec: 2}
+
+	res := CheckMonitor(context.Background(), base, settings)
+	if res.OK || res.Error != "monitoring.error.credentialsRequired" {
+		t.Fatalf("expected missing secret to fail, got ok=%v error=%s", res.OK, res.Error)
+	}
+
+	cases := []struct {
+		name  string
+		creds store.MonitorCredentials
+		opts  *store.RadiusOptions
+		ok    bool
+		err   string
+	}{
+		{name: "pap accept", creds: store.MonitorCredentials{Username: "probe", Password: "pass123", Secret: "shared"}, ok: true},
+		{name: "chap accept", creds: store.MonitorCredentials{Username: "probe", Password: "pass123", Secret: "shared"}, opts: &store.RadiusOptions{AuthMethod: "chap"}, ok: true},
+		{name: "reject", creds: store.MonitorCredentials{Username: "probe", Password: "nope", Secret: "shared"}, err: "monitoring.error.authFailed"},
+		{name: "expected reject", creds: store.MonitorCredentials{Username: "probe", Password: "nope", Secret: "shared"}, opts: &store.RadiusOptions{ExpectedResult: "reject"}, ok: true},
+		{name: "unexpected accept", creds: store.MonitorCredentials{Username: "probe", Password: "pass123", Secret: "shared"}, opts: &store.RadiusOptions{ExpectedResult: "reject"}, err: "monitoring.error.radiusUnexpectedAccept"},
+		{name: "wrong secret", creds: store.MonitorCredentials{Username: "probe", Password: "pass123", Secret: "other"}, err: "monitoring.error.radiusBadAuthenticator"},
+	}
+	for _, tc := range cases {
+		mon := base
+		creds := tc.creds
+		mon.Credentials = &creds
+		mon.Options.Radius = tc.opts
+		res := CheckMonitor(context.Background(), mon, settings)
+		if res.OK != tc.ok || res.Error != tc.err {
+			t.Fatalf("%s: got ok=%v error=%q", tc.name, res.OK, res.Error)
+		}
+	}
+}
+
+func TestRadiusHidePasswordRoundTrip(t *testing.T) {
+	auth := bytes.Repeat([]byte{7}, 16)
+	for _, password := range []string{"", "short", "exactly-16-bytes", "a password longer than one block"} {
+		hidden := radiusHidePassword(password, []byte("secret"), auth)
+		if got := radiusRevealPassword(hidden, []byte("secret"), auth); got != password {
+			t.Fatalf("round trip %q: got %q", password, got)
+		}
+	}
+}
+
+// startFakeRadius answers Access-Requests for a single user, signing replies with the shared secret
+// it was started with. Requests signed with another secret are still answered so the client can
+// detect the mismatch through the Response Authenticator.
+func startFakeRadius(t *testing.T, secret, user, password string) (string, int) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, radiusMaxPacket)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := append([]byte(nil), buf[:n]...)
+			auth := req[4:20]
+			accepted := false
+			var gotUser string
+			for attrs := req[20:]; len(attrs) >= 2 && int(attrs[1]) <= len(attrs); attrs = attrs[attrs[1]:] {
+				value := attrs[2:attrs[1]]
+				switch attrs[0] {
+				case radiusAttrUserName:
+					gotUser = string(value)
+				case radiusAttrUserPassword:
+					accepted = radiusRevealPassword(value, []byte(secret), auth) == password
+				case radiusAttrCHAPPassword:
+					h := md5.New()
+					h.Write(value[:1])
+					h.Write([]byte(password))
+					h.Write(auth)
+					accepted = bytes.Equal(h.Sum(nil), value[1:])
+				}
+			}
+			code := byte(radiusAccessReject)
+			if accepted && gotUser == user {
+				code = radiusAccessAccept
+			}
+			resp := make([]byte, 20, 38)
+			resp[0], resp[1] = code, req[1]
+			copy(resp[4:20], auth)
+			resp = append(resp, radiusAttrMessageAuthenticator, 18)
+			resp = append(resp, make([]byte, 16)...)
+			binary.BigEndian.PutUint16(resp[2:4], uint16(len(resp)))
+			mac := hmac.New(md5.New, []byte(secret))
+			mac.Write(resp)
+			copy(resp[22:], mac.Sum(nil))
+			h := md5.New()
+			h.Write(resp)
+			h.Write([]byte(secret))
+			copy(resp[4:20], h.Sum(nil))
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func radiusRevealPassword(hidden, secret, authenticator []byte) string {
+	out := make([]byte, len(hidden))
+	prev := authenticator
+	for i := 0; i+16 <= len(hidden); i += 16 {
+		h := md5.New()
+		h.Write(secret)
+		h.Write(prev)
+		sum := h.Sum(nil)
+		for j := 0; j < 16; j++ {
+			out[i+j] = hidden[i+j] ^ sum[j]
+		}
+		prev = hidden[i : i+16]
+	}
+	return string(bytes.TrimRight(out, "\x00"))
+}
@@ -0,0 +1,239 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	RedisRoleMaster   = "master"
+	RedisRoleReplica  = "replica"
+	RedisRoleSentinel = "sentinel"
+)
+
+const (
+	redisMaxBulkLen  = 1 << 20
+	redisMaxArrayLen = 1024
+)
+
+// NormalizeRedisRole maps role names and their aliases to master, replica or sentinel.
+// Unknown values yield an empty string.
+func NormalizeRedisRole(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "master", "primary":
+		return RedisRoleMaster
+	case "replica", "slave", "secondary":
+		return RedisRoleReplica
+	case "sentinel":
+		return RedisRoleSentinel
+	default:
+		return ""
+	}
+}
+
+func redisOptions(m store.Monitor) store.RedisOptions {
+	var opts store.RedisOptions
+	if m.Options.Redis != nil {
+		opts = *m.Options.Redis
+	}
+	opts.ExpectedRole = NormalizeRedisRole(opts.ExpectedRole)
+	return opts
+}
+
+type redisError string
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func checkRedis(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := redisOptions(m)
+	conn, host, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultPortForType(TypeRedis))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer conn.Close()
+	res := CheckResult{OK: true}
+	if opts.TLS {
+		tlsConn, err := upgradeTLS(ctx, conn, m, host, &res)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		conn = tlsConn
+	}
+	client := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	if password := monitorPassword(m); password != "" {
+		args := []string{"AUTH", password}
+		if user := monitorUsername(m); user != "" {
+			args = []string{"AUTH", user, password}
+		}
+		reply, err := client.do(args...)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		if _, ok := reply.(redisError); ok {
+			return CheckResult{OK: false, Error: "monitoring.error.authFailed"}, nil
+		}
+	}
+	reply, err := client.do("PING")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	if msg, ok := reply.(redisError); ok {
+		if strings.HasPrefix(string(msg), "NOAUTH") {
+			return CheckResult{OK: false, Error: "monitoring.error.credentialsRequired"}, nil
+		}
+		return CheckResult{OK: false, Error: "monitoring.error.requestFailed"}, nil
+	}
+	if pong, _ := reply.(string); !strings.EqualFold(pong, "PONG") {
+		return CheckResult{}, ErrProtocol
+	}
+	info, err := client.info("server")
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res.ServerVersion = info["redis_version"]
+	role, linkUp, err := client.role()
+	if err != nil {
+		return CheckResult{}, err
+	}
+	res.ServerRole = role
+	if role == RedisRoleReplica && !linkUp {
+		res.Degraded = true
+		res.Error = "monitoring.error.replicationLinkDown"
+	}
+	checkExpectedRole(&res, opts.ExpectedRole)
+	return res, nil
+}
+
+// role prefers ROLE and falls back to INFO replication when the ACL forbids it.
+func (c *redisConn) role() (string, bool, error) {
+	reply, err := c.do("ROLE")
+	if err != nil {
+		return "", false, err
+	}
+	if items, ok := reply.([]any); ok && len(items) > 0 {
+		name, _ := items[0].(string)
+		role := NormalizeRedisRole(name)
+		if role != RedisRoleReplica {
+			return role, true, nil
+		}
+		state := ""
+		if len(items) > 3 {
+			state, _ = items[3].(string)
+		}
+		return role, state == "connected", nil
+	}
+	info, err := c.info("replication")
+	if err != nil {
+		return "", false, err
+	}
+	role := NormalizeRedisRole(info["role"])
+	if role != RedisRoleReplica {
+		return role, true, nil
+	}
+	return role, info["master_link_status"] == "up", nil
+}
+
+// info returns the key/value pairs of an INFO section; errors replied by the server yield an empty map.
+func (c *redisConn) info(section string) (map[string]string, error) {
+	out := map[string]string{}
+	reply, err := c.do("INFO", section)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := reply.(string)
+	if !ok {
+		return out, nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, val, found := strings.Cut(line, ":"); found {
+			out[key] = val
+		}
+	}
+	return out, nil
+}
+
+func (c *redisConn) do(args ...string) (any, error) {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return c.read(0)
+}
+
+// read decodes one RESP2 reply: string, int64, []any, nil or redisError.
+func (c *redisConn) read(depth int) (any, error) {
+	if depth > 8 {
+		return nil, ErrProtocol
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	if line == "" {
+		return nil, ErrProtocol
+	}
+	body := line[1:]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return redisError(body), nil
+	case ':':
+		val, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, ErrProtocol
+		}
+		return val, nil
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil || size > redisMaxBulkLen {
+			return nil, ErrProtocol
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil || count > redisMaxArrayLen {
+			return nil, ErrProtocol
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]any, 0, count)
+		for i := 0; i < count; i++ {
+			item, err := c.read(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, ErrProtocol
+	}
+}
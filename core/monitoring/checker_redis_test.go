@@ -0,0 +1,120 @@
+package monitoring
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestCheckMonitorRedis(t *testing.T) {
+	host, port := startFakeRedis(t, "monitor", "secret", "slave", "connected")
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 2}
+	base := store.Monitor{Type: TypeRedis, Host: host, Port: port, TimeoutSec: 2}
+
+	res := CheckMonitor(context.Background(), base, settings)
+	if res.OK || res.Error != "monitoring.error.credentialsRequired" {
+		t.Fatalf("expected NOAUTH to require credentials, got ok=%v error=%s", res.OK, res.Error)
+	}
+
+	base.Credentials = &store.MonitorCredentials{Username: "monitor", Password: "secret"}
+	res = CheckMonitor(context.Background(), base, settings)
+	if !res.OK || res.ServerRole != RedisRoleReplica || res.ServerVersion != "7.2.4" {
+		t.Fatalf("expected replica ok, got ok=%v error=%s role=%q version=%q", res.OK, res.Error, res.ServerRole, res.ServerVersion)
+	}
+
+	base.Options.Redis = &store.RedisOptions{ExpectedRole: "primary"}
+	res = CheckMonitor(context.Background(), base, settings)
+	if res.OK || res.Error != "monitoring.error.unexpectedRole" {
+		t.Fatalf("expected unexpected role, got ok=%v error=%s", res.OK, res.Error)
+	}
+
+	base.Credentials = &store.MonitorCredentials{Username: "monitor", Password: "wrong"}
+	res = CheckMonitor(context.Background(), base, settings)
+	if res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%s", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorRedisReplicationFallback(t *testing.T) {
+	host, port := startFakeRedis(t, "", "", "slave", "")
+	res := CheckMonitor(context.Background(), store.Monitor{Type: TypeRedis, Host: host, Port: port, TimeoutSec: 2},
+		store.MonitorSettings{AllowPrivateNetworks: true})
+	if !res.OK || !res.Degraded || res.Error != "monitoring.error.replicationLinkDown" || res.ServerRole != RedisRoleReplica {
+		t.Fatalf("expected degraded replica from INFO replication, got %+v", res)
+	}
+}
+
+// startFakeRedis serves a RESP2 subset. An empty linkState makes ROLE fail with NOPERM
+// so the checker falls back to INFO replication, which then reports the link as down.
+func startFakeRedis(t *testing.T, user, password, role, linkState string) (string, int) {
+	return startFakeServer(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		authed := password == ""
+		for {
+			args, err := readFakeRESPCommand(r)
+			if err != nil {
+				return
+			}
+			var reply string
+			switch strings.ToUpper(args[0]) {
+			case "AUTH":
+				if len(args) == 3 && args[1] == user && args[2] == password {
+					authed = true
+					reply = "+OK\r\n"
+				} else {
+					reply = "-WRONGPASS invalid username-password pair\r\n"
+				}
+			case "PING":
+				if !authed {
+					reply = "-NOAUTH Authentication required.\r\n"
+				} else {
+					reply = "+PONG\r\n"
+				}
+			case "INFO":
+				body := "# Server\r\nredis_version:7.2.4\r\n"
+				if len(args) > 1 && args[1] == "replication" {
+					body = "# Replication\r\nrole:" + role + "\r\nmaster_link_status:down\r\n"
+				}
+				reply = "$" + strconv.Itoa(len(body)) + "\r\n" + body + "\r\n"
+			case "ROLE":
+				if linkState == "" {
+					reply = "-NOPERM this user has no permissions to run the 'role' command\r\n"
+				} else {
+					reply = "*5\r\n$" + strconv.Itoa(len(role)) + "\r\n" + role + "\r\n$9\r\n127.0.0.1\r\n:6379\r\n$" +
+						strconv.Itoa(len(linkState)) + "\r\n" + linkState + "\r\n:100\r\n"
+				}
+			default:
+				reply = "-ERR unknown command\r\n"
+			}
+			if _, err := io.WriteString(conn, reply); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func readFakeRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		val, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(val, "\r\n"))
+	}
+	return args, nil
+}
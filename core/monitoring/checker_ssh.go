@@ -0,0 +1,369 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"berkut-scc/core/store"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// sshProbeUser is offered for the "none" authentication attempt when no key is configured.
+	sshProbeUser    = "probe"
+	maxSSHRecord    = 64 << 10
+	maxSSHCommand   = 1024
+	sshMsgKexInit   = 20
+	sshKexInitLists = 10
+)
+
+var errSSHHostKeyMismatch = errors.New("ssh host key does not match the pinned fingerprint")
+
+// sshKexInit holds the algorithm name-lists from the server's SSH_MSG_KEXINIT.
+type sshKexInit struct {
+	kex        []string
+	hostKeys   []string
+	ciphersC2S []string
+	ciphersS2C []string
+	macsC2S    []string
+	macsS2C    []string
+}
+
+// ValidSSHFingerprint reports whether raw is a SHA256 host key fingerprint as printed by ssh-keygen -l.
+func ValidSSHFingerprint(raw string) bool {
+	rest, ok := strings.CutPrefix(normalizeSSHFingerprint(raw), "SHA256:")
+	if !ok {
+		return false
+	}
+	sum, err := base64.RawStdEncoding.DecodeString(rest)
+	return err == nil && len(sum) == sha256.Size
+}
+
+// ValidSSHCommand reports whether command fits on a single exec request line.
+func ValidSSHCommand(command string) bool {
+	return len(command) <= maxSSHCommand && !strings.ContainsAny(command, "\r\n\x00")
+}
+
+// ValidSSHPrivateKey reports whether key parses, using passphrase when the key is encrypted.
+func ValidSSHPrivateKey(key, passphrase string) bool {
+	_, err := sshSigner(key, passphrase)
+	return err == nil
+}
+
+func normalizeSSHFingerprint(raw string) string {
+	return strings.TrimRight(strings.TrimSpace(raw), "=")
+}
+
+func sshSigner(key, passphrase string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(key))
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) && passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	}
+	return signer, err
+}
+
+func sshOptions(m store.Monitor) store.SSHOptions {
+	var opts store.SSHOptions
+	if m.Options.SSH != nil {
+		opts = *m.Options.SSH
+	}
+	opts.HostKeyFingerprint = normalizeSSHFingerprint(opts.HostKeyFingerprint)
+	opts.Command = strings.TrimSpace(opts.Command)
+	return opts
+}
+
+func monitorPrivateKey(m store.Monitor) string {
+	if m.Credentials == nil {
+		return ""
+	}
+	return strings.TrimSpace(m.Credentials.PrivateKey)
+}
+
+// sshRecorder keeps the first bytes read from the server, so the banner and the KEXINIT
+// can be inspected after the handshake; x/crypto/ssh does not expose what the server offered.
+type sshRecorder struct {
+	net.Conn
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (c *sshRecorder) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.mu.Lock()
+	if room := maxSSHRecord - len(c.buf); room > 0 && n > 0 {
+		c.buf = append(c.buf, p[:min(n, room)]...)
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c *sshRecorder) recorded() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]byte(nil), c.buf...)
+}
+
+// checkSSH completes the transport handshake, records the banner, the offered algorithms and the
+// host key, and with a private key authenticates and runs the optional command.
+func checkSSH(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	opts := sshOptions(m)
+	var signer ssh.Signer
+	if key := monitorPrivateKey(m); key != "" {
+		var err error
+		if signer, err = sshSigner(key, monitorPassword(m)); err != nil {
+			return CheckResult{OK: false, Error: "monitoring.error.invalidSSHKey"}, nil
+		}
+	} else if opts.Command != "" {
+		return CheckResult{OK: false, Error: "monitoring.error.sshKeyRequired"}, nil
+	}
+	conn, _, err := dialMonitorTarget(ctx, m, settings, timeout, DefaultPortForType(TypeSSH))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	rec := &sshRecorder{Conn: conn}
+	defer rec.Close()
+
+	var hostKey ssh.PublicKey
+	algs := sshAllAlgorithms()
+	cfg := &ssh.ClientConfig{
+		Config: ssh.Config{KeyExchanges: algs.KeyExchanges, Ciphers: algs.Ciphers, MACs: algs.MACs},
+		User:   monitorUsername(m),
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			// Refuse before authenticating, so a key is never offered to an impostor.
+			if opts.HostKeyFingerprint != "" && ssh.FingerprintSHA256(key) != opts.HostKeyFingerprint {
+				return errSSHHostKeyMismatch
+			}
+			return nil
+		},
+		HostKeyAlgorithms: algs.HostKeys,
+		Timeout:           timeout,
+	}
+	if cfg.User == "" {
+		cfg.User = sshProbeUser
+	}
+	if signer != nil {
+		cfg.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+	}
+	clientConn, chans, reqs, err := ssh.NewClientConn(rec, rec.RemoteAddr().String(), cfg)
+	if hostKey == nil {
+		// The key exchange did not finish, so there is nothing to report about the server.
+		if err == nil {
+			err = ErrProtocol
+		}
+		return CheckResult{}, err
+	}
+	res := CheckResult{OK: true, Details: &store.MonitorDetails{SSH: sshDetails(rec.recorded(), hostKey)}}
+	res.ServerVersion = sshServerVersion(res.Details.SSH.Banner)
+	// Without a key the server refuses the "none" method; that error is expected once the host key is known.
+	switch {
+	case errors.Is(err, errSSHHostKeyMismatch):
+		res.OK = false
+		res.Error = "monitoring.error.sshHostKeyChanged: " + res.Details.SSH.HostKeyFingerprint
+		return res, nil
+	case err != nil && signer != nil:
+		res.OK = false
+		res.Error = "monitoring.error.authFailed"
+		return res, nil
+	case err == nil:
+		client := ssh.NewClient(clientConn, chans, reqs)
+		defer client.Close()
+		if opts.Command != "" {
+			code, err := runSSHCommand(client, opts.Command)
+			if err != nil {
+				return CheckResult{}, err
+			}
+			if code != 0 {
+				res.OK = false
+				res.Error = "monitoring.error.sshCommandFailed: " + strconv.Itoa(code)
+				return res, nil
+			}
+		}
+	}
+	if weak := res.Details.SSH.WeakAlgorithms; len(weak) > 0 && !opts.AllowWeakAlgorithms {
+		res.OK = false
+		res.Error = "monitoring.error.sshWeakAlgorithms: " + strings.Join(weak, ", ")
+	}
+	return res, nil
+}
+
+// detectSSHHostKeyChange compares the host key with the previous check, or with the pinned
+// fingerprint when there is no previous one, and returns "old -> new" when it changed.
+func detectSSHHostKeyChange(m store.Monitor, prev *store.MonitorDetails, res CheckResult) string {
+	if res.Details == nil || res.Details.SSH == nil || res.Details.SSH.HostKeyFingerprint == "" {
+		return ""
+	}
+	before := ""
+	if prev != nil && prev.SSH != nil {
+		before = prev.SSH.HostKeyFingerprint
+	}
+	if before == "" {
+		before = sshOptions(m).HostKeyFingerprint
+	}
+	after := res.Details.SSH.HostKeyFingerprint
+	if before == "" || before == after {
+		return ""
+	}
+	return before + " -> " + after
+}
+
+// sshAllAlgorithms offers the insecure algorithms too, so servers that only support them can still be inspected.
+func sshAllAlgorithms() ssh.Algorithms {
+	supported := ssh.SupportedAlgorithms()
+	insecure := ssh.InsecureAlgorithms()
+	return ssh.Algorithms{
+		KeyExchanges: append(supported.KeyExchanges, insecure.KeyExchanges...),
+		Ciphers:      append(supported.Ciphers, insecure.Ciphers...),
+		MACs:         append(supported.MACs, insecure.MACs...),
+		HostKeys:     append(supported.HostKeys, insecure.HostKeys...),
+	}
+}
+
+func runSSHCommand(client *ssh.Client, command string) (int, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+	err = session.Run(command)
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	return 0, err
+}
+
+func sshDetails(raw []byte, hostKey ssh.PublicKey) *store.SSHDetails {
+	banner, kex := parseSSHServerHello(raw)
+	details := &store.SSHDetails{
+		Banner:             banner,
+		HostKeyType:        hostKey.Type(),
+		HostKeyFingerprint: ssh.FingerprintSHA256(hostKey),
+	}
+	if kex != nil {
+		details.KeyExchanges = kex.kex
+		details.HostKeyAlgorithms = kex.hostKeys
+		details.Ciphers = appendUnique(append([]string{}, kex.ciphersS2C...), kex.ciphersC2S...)
+		details.MACs = appendUnique(append([]string{}, kex.macsS2C...), kex.macsC2S...)
+		details.WeakAlgorithms = weakSSHAlgorithms(details)
+	}
+	return details
+}
+
+// sshServerVersion strips the protocol prefix, e.g. "SSH-2.0-OpenSSH_9.6" becomes "OpenSSH_9.6".
+func sshServerVersion(banner string) string {
+	if rest, ok := strings.CutPrefix(banner, "SSH-"); ok {
+		if _, software, found := strings.Cut(rest, "-"); found {
+			return software
+		}
+	}
+	return banner
+}
+
+// parseSSHServerHello reads the identification line and the first binary packet, which is the
+// unencrypted KEXINIT (RFC 4253 sections 4.2 and 7.1). Lines before the identification are skipped.
+func parseSSHServerHello(raw []byte) (string, *sshKexInit) {
+	banner := ""
+	for banner == "" {
+		idx := bytes.IndexByte(raw, '\n')
+		if idx < 0 {
+			return "", nil
+		}
+		line := strings.TrimRight(string(raw[:idx]), "\r")
+		raw = raw[idx+1:]
+		if strings.HasPrefix(line, "SSH-") {
+			banner = line
+		}
+	}
+	if len(raw) < 5 {
+		return banner, nil
+	}
+	length := int(binary.BigEndian.Uint32(raw))
+	padding := int(raw[4])
+	if length > len(raw)-4 || padding+1 > length {
+		return banner, nil
+	}
+	payload := raw[5 : 4+length-padding]
+	if len(payload) < 17 || payload[0] != sshMsgKexInit {
+		return banner, nil
+	}
+	payload = payload[17:]
+	lists := make([][]string, 0, sshKexInitLists)
+	for i := 0; i < sshKexInitLists; i++ {
+		if len(payload) < 4 {
+			return banner, nil
+		}
+		n := int(binary.BigEndian.Uint32(payload))
+		if n > len(payload)-4 {
+			return banner, nil
+		}
+		var names []string
+		if n > 0 {
+			names = strings.Split(string(payload[4:4+n]), ",")
+		}
+		lists = append(lists, names)
+		payload = payload[4+n:]
+	}
+	return banner, &sshKexInit{
+		kex:        lists[0],
+		hostKeys:   lists[1],
+		ciphersC2S: lists[2],
+		ciphersS2C: lists[3],
+		macsC2S:    lists[4],
+		macsS2C:    lists[5],
+	}
+}
+
+// weakSSHAlgorithms lists offered SHA-1 key exchanges and host key signatures, DSA, CBC mode and RC4
+// ciphers, MD5 and truncated MACs. Plain hmac-sha1 is still in OpenSSH defaults and is not reported.
+func weakSSHAlgorithms(d *store.SSHDetails) []string {
+	var weak []string
+	for _, name := range d.KeyExchanges {
+		if strings.HasSuffix(name, "-sha1") {
+			weak = append(weak, name)
+		}
+	}
+	for _, name := range d.HostKeyAlgorithms {
+		if name == ssh.KeyAlgoRSA || name == ssh.KeyAlgoDSA || name == ssh.CertAlgoRSAv01 || name == ssh.CertAlgoDSAv01 {
+			weak = append(weak, name)
+		}
+	}
+	for _, name := range d.Ciphers {
+		if strings.Contains(name, "-cbc") || strings.HasPrefix(name, "arcfour") || name == "none" {
+			weak = append(weak, name)
+		}
+	}
+	for _, name := range d.MACs {
+		if strings.Contains(name, "md5") || strings.Contains(name, "-96") {
+			weak = append(weak, name)
+		}
+	}
+	return weak
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,197 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"net"
+	"strings"
+	"testing"
+
+	"berkut-scc/core/store"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func testSSHSigner(t *testing.T) (ssh.Signer, string) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	return signer, string(pem.EncodeToMemory(block))
+}
+
+// startFakeSSHServer accepts clientKey for public key auth and answers exec requests
+// with exit status 0 for "true" and 1 otherwise.
+func startFakeSSHServer(t *testing.T, configure func(*ssh.ServerConfig), clientKey ssh.PublicKey) (string, int, ssh.PublicKey) {
+	t.Helper()
+	hostSigner, _ := testSSHSigner(t)
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if clientKey != nil && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+		ServerVersion: "SSH-2.0-OpenSSH_9.6 Test",
+	}
+	cfg.KeyExchanges = []string{ssh.KeyExchangeCurve25519, ssh.KeyExchangeDH14SHA256}
+	cfg.AddHostKey(hostSigner)
+	if configure != nil {
+		configure(cfg)
+	}
+	host, port := startFakeServer(t, func(conn net.Conn) {
+		_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		for newChan := range chans {
+			ch, requests, err := newChan.Accept()
+			if err != nil {
+				return
+			}
+			for req := range requests {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				_ = req.Reply(true, nil)
+				status := make([]byte, 4)
+				if string(req.Payload[4:]) != "true" {
+					binary.BigEndian.PutUint32(status, 1)
+				}
+				_, _ = ch.SendRequest("exit-status", false, status)
+				_ = ch.Close()
+				break
+			}
+		}
+	})
+	return host, port, hostSigner.PublicKey()
+}
+
+func TestCheckMonitorSSHHandshake(t *testing.T) {
+	host, port, hostKey := startFakeSSHServer(t, func(cfg *ssh.ServerConfig) {
+		cfg.MACs = []string{ssh.HMACSHA256ETM, ssh.HMACSHA256}
+	}, nil)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeSSH, Host: host, Port: port, TimeoutSec: 3}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || res.Details == nil || res.Details.SSH == nil {
+		t.Fatalf("expected handshake without credentials to pass, got ok=%v error=%q", res.OK, res.Error)
+	}
+	details := res.Details.SSH
+	if details.HostKeyFingerprint != ssh.FingerprintSHA256(hostKey) || details.HostKeyType != ssh.KeyAlgoED25519 {
+		t.Fatalf("unexpected host key: %+v", details)
+	}
+	if details.Banner != "SSH-2.0-OpenSSH_9.6 Test" || res.ServerVersion != "OpenSSH_9.6 Test" {
+		t.Fatalf("unexpected banner %q / version %q", details.Banner, res.ServerVersion)
+	}
+	if len(details.KeyExchanges) == 0 || len(details.Ciphers) == 0 || len(details.MACs) != 2 || len(details.WeakAlgorithms) != 0 {
+		t.Fatalf("unexpected algorithms: %+v", details)
+	}
+
+	mon.Options.SSH = &store.SSHOptions{HostKeyFingerprint: ssh.FingerprintSHA256(hostKey)}
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK {
+		t.Fatalf("expected pinned key to match, got error=%q", res.Error)
+	}
+	mon.Options.SSH.HostKeyFingerprint = "SHA256:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU"
+	res = CheckMonitor(context.Background(), mon, settings)
+	if res.OK || res.Error != "monitoring.error.sshHostKeyChanged: "+ssh.FingerprintSHA256(hostKey) {
+		t.Fatalf("expected host key change, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorSSHWeakAlgorithms(t *testing.T) {
+	host, port, _ := startFakeSSHServer(t, func(cfg *ssh.ServerConfig) {
+		cfg.Ciphers = []string{ssh.CipherAES128CTR, ssh.InsecureCipherAES128CBC}
+		cfg.MACs = []string{ssh.HMACSHA256, ssh.InsecureHMACSHA196}
+	}, nil)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeSSH, Host: host, Port: port, TimeoutSec: 3}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if res.OK || res.Error != "monitoring.error.sshWeakAlgorithms: aes128-cbc, hmac-sha1-96" {
+		t.Fatalf("expected weak algorithms to fail the check, got ok=%v error=%q", res.OK, res.Error)
+	}
+	mon.Options.SSH = &store.SSHOptions{AllowWeakAlgorithms: true}
+	res = CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || len(res.Details.SSH.WeakAlgorithms) != 2 {
+		t.Fatalf("expected weak algorithms to be reported only, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorSSHKeyAuthAndCommand(t *testing.T) {
+	clientSigner, clientPEM := testSSHSigner(t)
+	host, port, _ := startFakeSSHServer(t, nil, clientSigner.PublicKey())
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeSSH, Host: host, Port: port, TimeoutSec: 3,
+		Credentials: &store.MonitorCredentials{Username: "monitor", PrivateKey: clientPEM}}
+	mon.Options.SSH = &store.SSHOptions{Command: "true", AllowWeakAlgorithms: true}
+
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK {
+		t.Fatalf("expected command to succeed, got error=%q", res.Error)
+	}
+	mon.Options.SSH.Command = "false"
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.sshCommandFailed: 1" {
+		t.Fatalf("expected command failure, got ok=%v error=%q", res.OK, res.Error)
+	}
+	_, otherPEM := testSSHSigner(t)
+	mon.Credentials.PrivateKey = otherPEM
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.authFailed" {
+		t.Fatalf("expected auth failure, got ok=%v error=%q", res.OK, res.Error)
+	}
+	mon.Credentials = nil
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.sshKeyRequired" {
+		t.Fatalf("expected command without key to be refused, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestValidSSHFingerprint(t *testing.T) {
+	signer, pemKey := testSSHSigner(t)
+	if !ValidSSHFingerprint(ssh.FingerprintSHA256(signer.PublicKey())) || !ValidSSHFingerprint(" SHA256:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU= ") {
+		t.Fatalf("expected valid fingerprints")
+	}
+	for _, raw := range []string{"", "MD5:aa:bb", "SHA256:short", ssh.FingerprintLegacyMD5(signer.PublicKey())} {
+		if ValidSSHFingerprint(raw) {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+	if !ValidSSHPrivateKey(pemKey, "") || ValidSSHPrivateKey(strings.Replace(pemKey, "A", "B", 5), "") {
+		t.Fatalf("unexpected private key validation")
+	}
+}
+
+func TestDetectSSHHostKeyChange(t *testing.T) {
+	mon := store.Monitor{Type: TypeSSH}
+	result := func(fp string) CheckResult {
+		return CheckResult{Details: &store.MonitorDetails{SSH: &store.SSHDetails{HostKeyFingerprint: fp}}}
+	}
+	prev := &store.MonitorDetails{SSH: &store.SSHDetails{HostKeyFingerprint: "SHA256:old"}}
+	if got := detectSSHHostKeyChange(mon, prev, result("SHA256:old")); got != "" {
+		t.Fatalf("unchanged key reported as %q", got)
+	}
+	if got := detectSSHHostKeyChange(mon, prev, result("SHA256:new")); got != "SHA256:old -> SHA256:new" {
+		t.Fatalf("unexpected change %q", got)
+	}
+	if got := detectSSHHostKeyChange(mon, nil, result("SHA256:new")); got != "" {
+		t.Fatalf("first check without a pin must not report a change, got %q", got)
+	}
+	mon.Options.SSH = &store.SSHOptions{HostKeyFingerprint: "SHA256:pinned"}
+	if got := detectSSHHostKeyChange(mon, nil, result("SHA256:new")); got != "SHA256:pinned -> SHA256:new" {
+		t.Fatalf("expected change against the pin, got %q", got)
+	}
+}
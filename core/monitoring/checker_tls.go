@@ -0,0 +1,396 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// Issues reported by tls monitors, in the order they are checked.
+const (
+	TLSIssueUntrustedChain   = "untrusted_chain"
+	TLSIssueIncompleteChain  = "incomplete_chain"
+	TLSIssueExpired          = "expired"
+	TLSIssueHostnameMismatch = "hostname_mismatch"
+	TLSIssueWeakSignature    = "weak_signature"
+	TLSIssueWeakKey          = "weak_key"
+	TLSIssueLegacyProtocol   = "legacy_protocol"
+	TLSIssueNoTLS12          = "no_tls12"
+	TLSIssueRC4              = "rc4_cipher"
+	TLSIssue3DES             = "3des_cipher"
+	TLSIssueNoForwardSecrecy = "no_forward_secrecy"
+)
+
+// tlsGrades are ordered from worst to best.
+var tlsGrades = []string{"F", "C", "B", "A", "A+"}
+
+var tlsScanVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13}
+
+// errTLSRefused wraps handshake failures, which mean "not supported" for a probe rather than an unreachable target.
+var errTLSRefused = errors.New("tls handshake refused")
+
+func tlsScanOptions(m store.Monitor) store.TLSScanOptions {
+	var opts store.TLSScanOptions
+	if m.Options.TLSScan != nil {
+		opts = *m.Options.TLSScan
+	}
+	opts.ServerName = strings.TrimSpace(opts.ServerName)
+	opts.MinGrade = NormalizeTLSGrade(opts.MinGrade)
+	return opts
+}
+
+// NormalizeTLSGrade returns the canonical grade or "" when raw is not one.
+func NormalizeTLSGrade(raw string) string {
+	grade := strings.ToUpper(strings.TrimSpace(raw))
+	if TLSGradeRank(grade) < 0 {
+		return ""
+	}
+	return grade
+}
+
+// TLSGradeRank orders grades from F (0) to A+; unknown grades rank -1.
+func TLSGradeRank(grade string) int {
+	for i, g := range tlsGrades {
+		if g == grade {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkTLS enumerates protocol versions and cipher suites of host:port, validates the presented chain and grades the endpoint.
+// The monitor timeout bounds the whole scan, not each probe handshake.
+func checkTLS(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	opts := tlsScanOptions(m)
+	host, port, err := monitorHostPort(m, DefaultPortForType(TypeTLS))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	serverName := opts.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+	base, err := monitorTLSConfig(m, serverName)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	// Every probe completes the handshake; the chain is verified separately so that each problem is reported.
+	roots := base.RootCAs
+	base.InsecureSkipVerify = true
+	base.MinVersion = tls.VersionTLS10
+	base.CipherSuites = tlsScanCipherSuites(0)
+
+	handshake := func(cfg *tls.Config) (*tls.ConnectionState, error) {
+		conn, err := dialGuarded(ctx, "tcp", host, port, settings, timeout)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			// Running out of time is not a refusal and must not silently shorten the scan.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, fmt.Errorf("%w: %w", errTLSRefused, err)
+		}
+		state := tlsConn.ConnectionState()
+		return &state, nil
+	}
+
+	start := time.Now()
+	state, err := handshake(base)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	latency := int(time.Since(start).Milliseconds())
+	if len(state.PeerCertificates) == 0 {
+		return CheckResult{}, ErrProtocol
+	}
+
+	sec := &store.TLSSecurity{}
+	var supported []uint16
+	for _, version := range tlsScanVersions {
+		cfg := base.Clone()
+		cfg.MinVersion, cfg.MaxVersion = version, version
+		probe, err := handshake(cfg)
+		if errors.Is(err, errTLSRefused) {
+			continue
+		}
+		if err != nil {
+			return CheckResult{}, err
+		}
+		supported = append(supported, version)
+		sec.Protocols = append(sec.Protocols, tls.VersionName(version))
+		if version == tls.VersionTLS13 {
+			// TLS 1.3 suites are not configurable in crypto/tls, so only the negotiated one is known.
+			sec.CipherSuites = appendUnique(sec.CipherSuites, tls.CipherSuiteName(probe.CipherSuite))
+		}
+	}
+	for _, version := range supported {
+		if version == tls.VersionTLS13 {
+			continue
+		}
+		// Servers may enable different suites per version, so each one is enumerated: offer the
+		// remaining suites until the server refuses them all.
+		offered := tlsScanCipherSuites(version)
+		for len(offered) > 0 {
+			cfg := base.Clone()
+			cfg.MinVersion, cfg.MaxVersion = version, version
+			cfg.CipherSuites = offered
+			probe, err := handshake(cfg)
+			if errors.Is(err, errTLSRefused) {
+				break
+			}
+			if err != nil {
+				return CheckResult{}, err
+			}
+			sec.CipherSuites = appendUnique(sec.CipherSuites, tls.CipherSuiteName(probe.CipherSuite))
+			offered = removeCipherSuite(offered, probe.CipherSuite)
+		}
+	}
+
+	var chainIssues []string
+	sec.Chain, chainIssues = analyzeTLSChain(state.PeerCertificates, roots, serverName, time.Now())
+	sec.Issues = append(chainIssues, tlsProtocolIssues(sec)...)
+	grade := gradeTLS(sec)
+
+	res := CheckResult{OK: true, LatencyMs: latency, TLS: tlsFromState(state)}
+	res.TLS.Grade = grade
+	res.TLS.Security = sec
+	var legacyNames []string
+	for _, version := range supported {
+		if version < tls.VersionTLS12 {
+			legacyNames = append(legacyNames, tls.VersionName(version))
+		}
+	}
+	var blocking []string
+	for _, issue := range chainIssues {
+		switch issue {
+		case TLSIssueUntrustedChain, TLSIssueIncompleteChain, TLSIssueExpired, TLSIssueHostnameMismatch:
+			blocking = append(blocking, issue)
+		}
+	}
+	switch {
+	case len(blocking) > 0 && !m.IgnoreTLSErrors:
+		res.OK = false
+		res.Error = "monitoring.error.tlsChainInvalid: " + strings.Join(blocking, ", ")
+	case len(legacyNames) > 0 && !opts.AllowLegacy:
+		res.OK = false
+		res.Error = "monitoring.error.tlsLegacyProtocol: " + strings.Join(legacyNames, ", ")
+	case opts.MinGrade != "" && TLSGradeRank(grade) < TLSGradeRank(opts.MinGrade):
+		res.OK = false
+		res.Error = "monitoring.error.tlsGradeBelow: " + grade
+	}
+	return res, nil
+}
+
+// tlsScanCipherSuites lists every suite crypto/tls can offer for version, insecure ones included; 0 means any version.
+func tlsScanCipherSuites(version uint16) []uint16 {
+	var out []uint16
+	for _, list := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range list {
+			for _, v := range suite.SupportedVersions {
+				if (version == 0 && v < tls.VersionTLS13) || v == version {
+					out = append(out, suite.ID)
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+func removeCipherSuite(list []uint16, id uint16) []uint16 {
+	out := make([]uint16, 0, len(list))
+	for _, item := range list {
+		if item != id {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// analyzeTLSChain describes the presented certificates and reports chain problems.
+func analyzeTLSChain(certs []*x509.Certificate, roots *x509.CertPool, serverName string, now time.Time) ([]store.TLSChainCert, []string) {
+	leaf := certs[0]
+	chain := make([]store.TLSChainCert, 0, len(certs))
+	intermediates := x509.NewCertPool()
+	weakSignature := false
+	for i, cert := range certs {
+		keyType, keyBits := certificateKey(cert)
+		chain = append(chain, store.TLSChainCert{
+			Subject:            certificateName(cert.Subject.CommonName, cert.Subject.String()),
+			Issuer:             certificateName(cert.Issuer.CommonName, cert.Issuer.String()),
+			NotAfter:           cert.NotAfter.UTC(),
+			SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+			KeyType:            keyType,
+			KeyBits:            keyBits,
+		})
+		if i > 0 {
+			intermediates.AddCert(cert)
+		}
+		// A self-signed root's own signature is never checked by clients.
+		if !selfSignedCertificate(cert) && weakSignatureAlgorithm(cert.SignatureAlgorithm) {
+			weakSignature = true
+		}
+	}
+
+	var issues []string
+	verifyAt := now
+	if now.After(leaf.NotAfter) || now.Before(leaf.NotBefore) {
+		// Check the trust path as of a moment the leaf was valid, so an expired certificate is not also reported as untrusted.
+		verifyAt = leaf.NotAfter.Add(-time.Second)
+		if now.Before(leaf.NotBefore) {
+			verifyAt = leaf.NotBefore.Add(time.Second)
+		}
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: verifyAt})
+	var unknownAuthority x509.UnknownAuthorityError
+	var insecureAlgorithm x509.InsecureAlgorithmError
+	switch {
+	case err == nil:
+	case errors.As(err, &insecureAlgorithm):
+		weakSignature = true
+	case errors.As(err, &unknownAuthority) && len(certs) == 1 && !selfSignedCertificate(leaf):
+		issues = append(issues, TLSIssueIncompleteChain)
+	default:
+		issues = append(issues, TLSIssueUntrustedChain)
+	}
+	if verifyAt != now {
+		issues = append(issues, TLSIssueExpired)
+	}
+	if serverName != "" && leaf.VerifyHostname(serverName) != nil {
+		issues = append(issues, TLSIssueHostnameMismatch)
+	}
+	if weakSignature {
+		issues = append(issues, TLSIssueWeakSignature)
+	}
+	switch key := leaf.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			issues = append(issues, TLSIssueWeakKey)
+		}
+	case *ecdsa.PublicKey:
+		if key.Curve.Params().BitSize < 256 {
+			issues = append(issues, TLSIssueWeakKey)
+		}
+	}
+	return chain, issues
+}
+
+func tlsProtocolIssues(sec *store.TLSSecurity) []string {
+	var issues []string
+	modern, legacy, forwardSecret := false, false, false
+	for _, name := range sec.Protocols {
+		switch name {
+		case tls.VersionName(tls.VersionTLS10), tls.VersionName(tls.VersionTLS11):
+			legacy = true
+		case tls.VersionName(tls.VersionTLS13):
+			modern, forwardSecret = true, true
+		default:
+			modern = true
+		}
+	}
+	if legacy {
+		issues = append(issues, TLSIssueLegacyProtocol)
+	}
+	if !modern {
+		issues = append(issues, TLSIssueNoTLS12)
+	}
+	rc4, tripleDES := false, false
+	for _, name := range sec.CipherSuites {
+		rc4 = rc4 || strings.Contains(name, "_RC4_")
+		tripleDES = tripleDES || strings.Contains(name, "_3DES_")
+		forwardSecret = forwardSecret || strings.HasPrefix(name, "TLS_ECDHE_")
+	}
+	if rc4 {
+		issues = append(issues, TLSIssueRC4)
+	}
+	if tripleDES {
+		issues = append(issues, TLSIssue3DES)
+	}
+	if !forwardSecret {
+		issues = append(issues, TLSIssueNoForwardSecrecy)
+	}
+	return issues
+}
+
+// gradeTLS starts at A and caps the grade by the worst issue; A+ needs TLS 1.3 and no issues at all.
+func gradeTLS(sec *store.TLSSecurity) string {
+	rank := TLSGradeRank("A")
+	for _, issue := range sec.Issues {
+		limit := TLSGradeRank("B")
+		switch issue {
+		case TLSIssueUntrustedChain, TLSIssueExpired, TLSIssueHostnameMismatch, TLSIssueRC4:
+			limit = TLSGradeRank("F")
+		case TLSIssueNoTLS12, TLSIssue3DES:
+			limit = TLSGradeRank("C")
+		}
+		rank = min(rank, limit)
+	}
+	if len(sec.Issues) == 0 {
+		for _, name := range sec.Protocols {
+			if name == tls.VersionName(tls.VersionTLS13) {
+				rank = TLSGradeRank("A+")
+			}
+		}
+	}
+	return tlsGrades[rank]
+}
+
+// detectTLSGradeDrop compares the grade with the stored scan and returns "old -> new" when it got worse.
+func detectTLSGradeDrop(prev *store.MonitorTLS, res CheckResult) string {
+	if prev == nil || res.TLS == nil || prev.Grade == "" || res.TLS.Grade == "" {
+		return ""
+	}
+	if TLSGradeRank(res.TLS.Grade) >= TLSGradeRank(prev.Grade) {
+		return ""
+	}
+	return prev.Grade + " -> " + res.TLS.Grade
+}
+
+func certificateName(commonName, full string) string {
+	if name := strings.TrimSpace(commonName); name != "" {
+		return name
+	}
+	return full
+}
+
+func certificateKey(cert *x509.Certificate) (string, int) {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	default:
+		return cert.PublicKeyAlgorithm.String(), 0
+	}
+}
+
+func selfSignedCertificate(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject)
+}
+
+func weakSignatureAlgorithm(alg x509.SignatureAlgorithm) bool {
+	switch alg {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		return true
+	default:
+		return false
+	}
+}
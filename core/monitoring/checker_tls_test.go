@@ -0,0 +1,208 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func startFakeTLSServer(t *testing.T, cert tls.Certificate, minVersion uint16) (string, int) {
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: minVersion}
+	return startFakeServer(t, func(conn net.Conn) {
+		_ = tls.Server(conn, cfg).Handshake()
+	})
+}
+
+func TestCheckMonitorTLSScan(t *testing.T) {
+	cert := testServerCertificate(t, "tls.test")
+	host, port := startFakeTLSServer(t, cert, tls.VersionTLS12)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeTLS, Host: host, Port: port, TimeoutSec: 3}
+	mon.Options.TLS = &store.TLSOptions{CACert: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}))}
+	mon.Options.TLSScan = &store.TLSScanOptions{ServerName: "tls.test", MinGrade: "a"}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || res.TLS == nil || res.TLS.Security == nil {
+		t.Fatalf("expected scan to pass, got ok=%v error=%q", res.OK, res.Error)
+	}
+	sec := res.TLS.Security
+	if res.TLS.Grade != "A+" || len(sec.Issues) != 0 || res.TLS.CommonName != "tls.test" {
+		t.Fatalf("unexpected grade %q issues %v", res.TLS.Grade, sec.Issues)
+	}
+	if !reflect.DeepEqual(sec.Protocols, []string{"TLS 1.2", "TLS 1.3"}) || len(sec.CipherSuites) < 2 {
+		t.Fatalf("unexpected protocols %v / suites %v", sec.Protocols, sec.CipherSuites)
+	}
+	if len(sec.Chain) != 1 || sec.Chain[0].KeyType != "ECDSA" || sec.Chain[0].KeyBits != 256 {
+		t.Fatalf("unexpected chain %+v", sec.Chain)
+	}
+
+	mon.Options.TLSScan.ServerName = "other.test"
+	if res := CheckMonitor(context.Background(), mon, settings); res.OK || res.Error != "monitoring.error.tlsChainInvalid: hostname_mismatch" || res.TLS.Grade != "F" {
+		t.Fatalf("expected hostname mismatch, got ok=%v error=%q", res.OK, res.Error)
+	}
+	mon.Options.TLS = nil
+	mon.Options.TLSScan.ServerName = ""
+	mon.IgnoreTLSErrors = true
+	res = CheckMonitor(context.Background(), mon, settings)
+	if res.OK || res.Error != "monitoring.error.tlsGradeBelow: F" {
+		t.Fatalf("expected untrusted chain to fail the minimum grade, got ok=%v error=%q", res.OK, res.Error)
+	}
+	if issues := res.TLS.Security.Issues; len(issues) != 2 || issues[0] != TLSIssueUntrustedChain || issues[1] != TLSIssueHostnameMismatch {
+		t.Fatalf("unexpected issues %v", issues)
+	}
+}
+
+func TestCheckMonitorTLSLegacyProtocols(t *testing.T) {
+	host, port := startFakeTLSServer(t, testServerCertificate(t, "tls.test"), tls.VersionTLS10)
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+	mon := store.Monitor{Type: TypeTLS, Host: host, Port: port, TimeoutSec: 3, IgnoreTLSErrors: true}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if res.OK || res.Error != "monitoring.error.tlsLegacyProtocol: TLS 1.0, TLS 1.1" {
+		t.Fatalf("expected legacy protocols to fail the check, got ok=%v error=%q", res.OK, res.Error)
+	}
+	mon.Options.TLSScan = &store.TLSScanOptions{AllowLegacy: true}
+	res = CheckMonitor(context.Background(), mon, settings)
+	if !res.OK || len(res.TLS.Security.Protocols) != 4 {
+		t.Fatalf("expected legacy protocols to be reported only, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorTLSEnumeratesSuitesPerVersion(t *testing.T) {
+	cert := testServerCertificate(t, "tls.test")
+	modern := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS10,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}}
+	// TLS 1.0 alone still accepts 3DES, which a scan of the highest legacy version never sees.
+	legacy := modern.Clone()
+	legacy.CipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA, tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+	cfg := modern.Clone()
+	cfg.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		for _, v := range hello.SupportedVersions {
+			if v > tls.VersionTLS10 {
+				return modern, nil
+			}
+		}
+		return legacy, nil
+	}
+	host, port := startFakeServer(t, func(conn net.Conn) {
+		_ = tls.Server(conn, cfg).Handshake()
+	})
+	mon := store.Monitor{Type: TypeTLS, Host: host, Port: port, TimeoutSec: 3, IgnoreTLSErrors: true}
+	mon.Options.TLSScan = &store.TLSScanOptions{AllowLegacy: true}
+
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3})
+	if res.TLS == nil || res.TLS.Security == nil {
+		t.Fatalf("expected scan results, got error=%q", res.Error)
+	}
+	suites := map[string]bool{}
+	for _, name := range res.TLS.Security.CipherSuites {
+		suites[name] = true
+	}
+	if !suites[tls.CipherSuiteName(tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA)] {
+		t.Fatalf("expected the TLS 1.0 only CBC suite to be reported, got %v", res.TLS.Security.CipherSuites)
+	}
+}
+
+func TestCheckMonitorTLSScanDeadline(t *testing.T) {
+	// The server accepts but never answers, so every handshake would wait for its own timeout.
+	host, port := startFakeServer(t, func(conn net.Conn) {
+		time.Sleep(3 * time.Second)
+	})
+	mon := store.Monitor{Type: TypeTLS, Host: host, Port: port, TimeoutSec: 1}
+	start := time.Now()
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 1})
+	if res.OK {
+		t.Fatalf("expected the scan to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the scan to stop at the monitor timeout, took %s", elapsed)
+	}
+}
+
+func TestAnalyzeTLSChainIncomplete(t *testing.T) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Intermediate"},
+		NotBefore:             time.Now().Add(-3 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, _ := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	ca, _ := x509.ParseCertificate(caDER)
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf.test"},
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     time.Now().Add(-time.Hour),
+		DNSNames:     []string{"leaf.test"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, _ := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	leaf, _ := x509.ParseCertificate(leafDER)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	chain, issues := analyzeTLSChain([]*x509.Certificate{leaf}, roots, "leaf.test", time.Now())
+	if len(chain) != 1 || chain[0].Issuer != "Test Intermediate" || !reflect.DeepEqual(issues, []string{TLSIssueExpired}) {
+		t.Fatalf("expected only expiry with a trusted issuer, got %v", issues)
+	}
+	_, issues = analyzeTLSChain([]*x509.Certificate{leaf}, x509.NewCertPool(), "leaf.test", time.Now())
+	if !reflect.DeepEqual(issues, []string{TLSIssueIncompleteChain, TLSIssueExpired}) {
+		t.Fatalf("expected a missing intermediate, got %v", issues)
+	}
+}
+
+func TestGradeTLS(t *testing.T) {
+	cases := []struct {
+		protocols []string
+		issues    []string
+		want      string
+	}{
+		{[]string{"TLS 1.2", "TLS 1.3"}, nil, "A+"},
+		{[]string{"TLS 1.2"}, nil, "A"},
+		{[]string{"TLS 1.0", "TLS 1.2", "TLS 1.3"}, []string{TLSIssueLegacyProtocol}, "B"},
+		{[]string{"TLS 1.2"}, []string{TLSIssueWeakKey, TLSIssue3DES}, "C"},
+		{[]string{"TLS 1.2", "TLS 1.3"}, []string{TLSIssueWeakSignature, TLSIssueHostnameMismatch}, "F"},
+	}
+	for _, tc := range cases {
+		if got := gradeTLS(&store.TLSSecurity{Protocols: tc.protocols, Issues: tc.issues}); got != tc.want {
+			t.Fatalf("%v %v: got %s, want %s", tc.protocols, tc.issues, got, tc.want)
+		}
+	}
+	issues := tlsProtocolIssues(&store.TLSSecurity{Protocols: []string{"TLS 1.1"}, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}})
+	if !reflect.DeepEqual(issues, []string{TLSIssueLegacyProtocol, TLSIssueNoTLS12, TLSIssueRC4, TLSIssueNoForwardSecrecy}) {
+		t.Fatalf("unexpected protocol issues %v", issues)
+	}
+}
+
+func TestDetectTLSGradeDrop(t *testing.T) {
+	result := func(grade string) CheckResult {
+		return CheckResult{TLS: &TLSInfo{Grade: grade}}
+	}
+	prev := &store.MonitorTLS{Grade: "A"}
+	if got := detectTLSGradeDrop(prev, result("A+")); got != "" {
+		t.Fatalf("improvement reported as %q", got)
+	}
+	if got := detectTLSGradeDrop(prev, result("B")); got != "A -> B" {
+		t.Fatalf("unexpected drop %q", got)
+	}
+	if got := detectTLSGradeDrop(nil, result("F")); got != "" {
+		t.Fatalf("first scan must not report a drop, got %q", got)
+	}
+}
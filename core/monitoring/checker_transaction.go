@@ -0,0 +1,354 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	maxTransactionSteps    = 20
+	maxTransactionExtracts = 16
+	maxTransactionResponse = 1 << 20
+
+	TransactionExtractJSON   = "json"
+	TransactionExtractRegex  = "regex"
+	TransactionExtractHeader = "header"
+	TransactionExtractCookie = "cookie"
+)
+
+var (
+	transactionVarRef  = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	transactionVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+var transactionMethods = map[string]bool{
+	http.MethodGet: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodHead: true, http.MethodOptions: true,
+}
+
+// transactionOutcome is what one step reports back; failure is a soft failure used as the check error.
+type transactionOutcome struct {
+	code    int
+	tls     *TLSInfo
+	failure string
+}
+
+// NormalizeTransactionExtract returns the canonical extraction source or "" when it is not supported.
+func NormalizeTransactionExtract(raw string) string {
+	switch from := strings.ToLower(strings.TrimSpace(raw)); from {
+	case TransactionExtractJSON, TransactionExtractRegex, TransactionExtractHeader, TransactionExtractCookie:
+		return from
+	}
+	return ""
+}
+
+// ValidTransactionSteps reports whether the steps can run: known methods, parsable URLs, assertions and
+// extractors, and no reference to a variable that is not extracted by an earlier step.
+func ValidTransactionSteps(steps []store.TransactionStep) bool {
+	if len(steps) == 0 || len(steps) > maxTransactionSteps {
+		return false
+	}
+	defined := map[string]bool{}
+	for _, step := range steps {
+		rawURL := strings.TrimSpace(step.URL)
+		if rawURL == "" || len(rawURL) > 2048 || len(step.Name) > 128 || len(step.Body) > 64<<10 || len(step.Keyword) > 1024 {
+			return false
+		}
+		if method := strings.ToUpper(strings.TrimSpace(step.Method)); method != "" && !transactionMethods[method] {
+			return false
+		}
+		if _, err := url.Parse(transactionVarRef.ReplaceAllString(rawURL, "x")); err != nil {
+			return false
+		}
+		refs := []string{rawURL, step.Body}
+		for _, v := range step.Headers {
+			refs = append(refs, v)
+		}
+		for _, text := range refs {
+			for _, match := range transactionVarRef.FindAllStringSubmatch(text, -1) {
+				if !defined[match[1]] {
+					return false
+				}
+			}
+		}
+		if !ValidJSONAssertions(step.Assertions) || len(step.Extract) > maxTransactionExtracts {
+			return false
+		}
+		for _, ex := range step.Extract {
+			expr := strings.TrimSpace(ex.Expr)
+			if !transactionVarName.MatchString(ex.Name) || expr == "" || len(expr) > 1024 {
+				return false
+			}
+			switch NormalizeTransactionExtract(ex.From) {
+			case "":
+				return false
+			case TransactionExtractJSON:
+				if _, err := parseJSONPath(expr); err != nil {
+					return false
+				}
+			case TransactionExtractRegex:
+				if _, err := regexp.Compile(ex.Expr); err != nil {
+					return false
+				}
+			}
+		}
+		for _, ex := range step.Extract {
+			defined[ex.Name] = true
+		}
+	}
+	return true
+}
+
+// checkTransaction runs the steps in order with one cookie jar, stopping at the first failing step.
+// The monitor timeout bounds the whole journey, not each step.
+func checkTransaction(ctx context.Context, m store.Monitor, settings store.MonitorSettings, timeout time.Duration) (CheckResult, error) {
+	base, err := parseMonitorURL(m.URL)
+	if err != nil {
+		return CheckResult{}, ErrInvalidURL
+	}
+	if m.Options.Transaction == nil || len(m.Options.Transaction.Steps) == 0 {
+		return CheckResult{OK: false, Error: "monitoring.error.transactionStepsRequired"}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	client, err := httpMonitorClient(m, settings, timeout)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	defer client.CloseIdleConnections()
+	if client.Jar, err = cookiejar.New(nil); err != nil {
+		return CheckResult{}, err
+	}
+	details := &store.TransactionDetails{}
+	res := CheckResult{OK: true, Details: &store.MonitorDetails{Transaction: details}, Values: map[string]float64{}}
+	vars := map[string]string{}
+	for i, step := range m.Options.Transaction.Steps {
+		stepRes := store.TransactionStepResult{Name: transactionStepName(step, i)}
+		start := time.Now()
+		out, err := runTransactionStep(ctx, client, m, settings, base, step, vars)
+		stepRes.LatencyMs = int(time.Since(start).Milliseconds())
+		stepRes.StatusCode = out.code
+		res.Values["step:"+stepRes.Name] = float64(stepRes.LatencyMs)
+		if out.code > 0 {
+			code := out.code
+			res.StatusCode = &code
+		}
+		if res.TLS == nil && out.tls != nil {
+			res.TLS = out.tls
+		}
+		if err != nil {
+			stepRes.Error = failedResult(CheckResult{}, err).Error
+			details.Steps = append(details.Steps, stepRes)
+			details.FailedStep = stepRes.Name
+			res.OK = false
+			if i == 0 {
+				return res, err
+			}
+			// Earlier steps may have changed state on the target, so the journey is not retried.
+			res.Error = stepRes.Error
+			return res, nil
+		}
+		if out.failure != "" {
+			stepRes.Error = out.failure
+			details.Steps = append(details.Steps, stepRes)
+			details.FailedStep = stepRes.Name
+			res.OK = false
+			res.Error = out.failure
+			return res, nil
+		}
+		stepRes.OK = true
+		details.Steps = append(details.Steps, stepRes)
+	}
+	return res, nil
+}
+
+func runTransactionStep(ctx context.Context, client *http.Client, m store.Monitor, settings store.MonitorSettings, base *url.URL, step store.TransactionStep, vars map[string]string) (transactionOutcome, error) {
+	var out transactionOutcome
+	ref, err := url.Parse(expandTransactionVars(strings.TrimSpace(step.URL), vars))
+	if err != nil {
+		return out, ErrInvalidURL
+	}
+	target, err := parseMonitorURL(base.ResolveReference(ref).String())
+	if err != nil {
+		return out, err
+	}
+	if err := guardTarget(ctx, target.Hostname(), settings); err != nil {
+		return out, err
+	}
+	method := strings.ToUpper(strings.TrimSpace(step.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	body := expandTransactionVars(step.Body, vars)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(body))
+	if err != nil {
+		return out, err
+	}
+	for k, v := range m.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range step.Headers {
+		req.Header.Set(k, expandTransactionVars(v, vars))
+	}
+	if body != "" && req.Header.Get("Content-Type") == "" && json.Valid([]byte(body)) {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// A step that sets Authorization itself (e.g. with an extracted token) is not overridden by the monitor auth.
+	if req.Header.Get("Authorization") == "" {
+		if key, err := applyHTTPAuth(ctx, req, m, settings, client); err != nil {
+			return out, err
+		} else if key != "" {
+			out.failure = key
+			return out, nil
+		}
+	}
+	allowed := step.AllowedStatus
+	if len(allowed) == 0 {
+		allowed = m.AllowedStatus
+	}
+	client.CheckRedirect = guardRedirect(settings)
+	if expectsRedirectStatus(allowed) {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+	out.code = resp.StatusCode
+	if out.code == http.StatusUnauthorized {
+		forgetOAuth2Token(m)
+	}
+	// Only the certificate of the monitor host is tracked, like for single-request HTTP monitors.
+	if resp.TLS != nil && strings.EqualFold(resp.Request.URL.Hostname(), base.Hostname()) {
+		out.tls = tlsFromState(resp.TLS)
+	}
+	if !statusAllowed(out.code, allowed) {
+		out.failure = fmt.Sprintf("status_%d", out.code)
+		return out, nil
+	}
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, maxTransactionResponse))
+	if err != nil {
+		return out, err
+	}
+	if needle := strings.TrimSpace(step.Keyword); needle != "" && !strings.Contains(string(payload), needle) {
+		out.failure = "monitoring.error.keywordNotFound"
+		return out, nil
+	}
+	var doc any
+	if transactionNeedsJSON(step) {
+		if err := json.Unmarshal(payload, &doc); err != nil {
+			out.failure = "monitoring.error.invalidJsonResponse"
+			return out, nil
+		}
+	}
+	if len(step.Assertions) > 0 {
+		if _, failures := evaluateJSONAssertions(doc, step.Assertions); len(failures) > 0 {
+			out.failure = jsonAssertionError(failures)
+			return out, nil
+		}
+	}
+	for _, ex := range step.Extract {
+		val, ok := extractTransactionValue(ex, resp, payload, doc, client.Jar)
+		if !ok {
+			out.failure = "monitoring.error.transactionExtractFailed: " + ex.Name
+			return out, nil
+		}
+		vars[ex.Name] = val
+	}
+	return out, nil
+}
+
+func transactionNeedsJSON(step store.TransactionStep) bool {
+	if len(step.Assertions) > 0 {
+		return true
+	}
+	for _, ex := range step.Extract {
+		if NormalizeTransactionExtract(ex.From) == TransactionExtractJSON {
+			return true
+		}
+	}
+	return false
+}
+
+func extractTransactionValue(ex store.TransactionExtract, resp *http.Response, payload []byte, doc any, jar http.CookieJar) (string, bool) {
+	expr := strings.TrimSpace(ex.Expr)
+	switch NormalizeTransactionExtract(ex.From) {
+	case TransactionExtractJSON:
+		steps, err := parseJSONPath(expr)
+		if err != nil {
+			return "", false
+		}
+		val, found := lookupJSONPath(doc, steps)
+		if !found || val == nil {
+			return "", false
+		}
+		return jsonScalarText(val), true
+	case TransactionExtractRegex:
+		re, err := regexp.Compile(ex.Expr)
+		if err != nil {
+			return "", false
+		}
+		match := re.FindSubmatch(payload)
+		if match == nil {
+			return "", false
+		}
+		if len(match) > 1 {
+			return string(match[1]), true
+		}
+		return string(match[0]), true
+	case TransactionExtractHeader:
+		if _, ok := resp.Header[http.CanonicalHeaderKey(expr)]; !ok {
+			return "", false
+		}
+		return resp.Header.Get(expr), true
+	case TransactionExtractCookie:
+		for _, c := range resp.Cookies() {
+			if c.Name == expr {
+				return c.Value, true
+			}
+		}
+		if jar != nil {
+			for _, c := range jar.Cookies(resp.Request.URL) {
+				if c.Name == expr {
+					return c.Value, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// expandTransactionVars replaces {{name}} references; validation guarantees every name was extracted earlier.
+func expandTransactionVars(text string, vars map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	return transactionVarRef.ReplaceAllStringFunc(text, func(ref string) string {
+		name := transactionVarRef.FindStringSubmatch(ref)[1]
+		if val, ok := vars[name]; ok {
+			return val
+		}
+		return ref
+	})
+}
+
+func transactionStepName(step store.TransactionStep, idx int) string {
+	if name := strings.TrimSpace(step.Name); name != "" {
+		return name
+	}
+	return "#" + strconv.Itoa(idx+1)
+}
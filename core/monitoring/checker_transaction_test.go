@@ -0,0 +1,183 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func transactionTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var creds map[string]string
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" || json.Unmarshal(body, &creds) != nil || creds["user"] != "probe" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s-42", Path: "/"})
+		w.Header().Set("X-Request-Id", "req-7")
+		_, _ = w.Write([]byte(`{"token":"tok-1","user":{"id":42}}`))
+	})
+	mux.HandleFunc("/orders/42", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if r.Header.Get("Authorization") != "Bearer tok-1" || err != nil || cookie.Value != "s-42" || r.Header.Get("X-Trace") != "req-7" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"orders":[{"state":"paid"}],"next":"/orders/42/page/2"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckMonitorHTTPTransaction(t *testing.T) {
+	srv := transactionTestServer(t)
+	steps := []store.TransactionStep{
+		{
+			Name: "login", Method: "POST", URL: "/login", Body: `{"user":"probe"}`,
+			Extract: []store.TransactionExtract{
+				{Name: "token", From: "json", Expr: "$.token"},
+				{Name: "uid", From: "regex", Expr: `"id":(\d+)`},
+				{Name: "rid", From: "header", Expr: "X-Request-Id"},
+				{Name: "sid", From: "cookie", Expr: "session"},
+			},
+		},
+		{
+			Name: "orders", URL: srv.URL + "/orders/{{uid}}",
+			Headers:    map[string]string{"Authorization": "Bearer {{token}}", "X-Trace": "{{rid}}"},
+			Keyword:    "paid",
+			Assertions: []store.JSONAssertion{{Path: "$.orders[0].state", Op: "==", Value: "paid"}},
+		},
+	}
+	if !ValidTransactionSteps(steps) {
+		t.Fatalf("expected valid steps")
+	}
+	mon := store.Monitor{Type: TypeHTTPTransaction, URL: srv.URL, AllowedStatus: []string{"200-299"}, TimeoutSec: 3}
+	mon.Options.Transaction = &store.TransactionOptions{Steps: steps}
+	settings := store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 3}
+
+	res := CheckMonitor(context.Background(), mon, settings)
+	if !res.OK {
+		t.Fatalf("expected transaction to pass, got error=%q", res.Error)
+	}
+	tx := res.Details.Transaction
+	if len(tx.Steps) != 2 || tx.FailedStep != "" || !tx.Steps[1].OK || tx.Steps[1].StatusCode != http.StatusOK {
+		t.Fatalf("unexpected step results: %+v", tx)
+	}
+	if _, ok := res.Values["step:orders"]; !ok {
+		t.Fatalf("expected step latency in values, got %v", res.Values)
+	}
+
+	steps[1].Assertions = []store.JSONAssertion{{Path: "$.orders[0].state", Op: "==", Value: "refunded"}}
+	res = CheckMonitor(context.Background(), mon, settings)
+	if res.OK || !strings.HasPrefix(res.Error, "monitoring.error.jsonAssertionFailed") || res.Details.Transaction.FailedStep != "orders" {
+		t.Fatalf("expected the orders step to fail, got ok=%v error=%q details=%+v", res.OK, res.Error, res.Details.Transaction)
+	}
+
+	steps[0].Extract = append(steps[0].Extract, store.TransactionExtract{Name: "missing", From: "json", Expr: "$.refresh"})
+	res = CheckMonitor(context.Background(), mon, settings)
+	if res.OK || res.Error != "monitoring.error.transactionExtractFailed: missing" || len(res.Details.Transaction.Steps) != 1 {
+		t.Fatalf("expected extraction failure in the first step, got ok=%v error=%q", res.OK, res.Error)
+	}
+
+	steps[0].Extract = steps[0].Extract[:4]
+	steps[0].Body = `{"user":"intruder"}`
+	res = CheckMonitor(context.Background(), mon, settings)
+	if res.OK || res.Error != "status_401" || res.Details.Transaction.FailedStep != "login" {
+		t.Fatalf("expected login to be rejected, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorHTTPTransactionBlockedStep(t *testing.T) {
+	srv := transactionTestServer(t)
+	mon := store.Monitor{Type: TypeHTTPTransaction, URL: srv.URL, AllowedStatus: []string{"200-299"}, TimeoutSec: 3}
+	mon.Options.Transaction = &store.TransactionOptions{Steps: []store.TransactionStep{
+		{Name: "metadata", URL: "http://169.254.169.254/latest/meta-data"},
+	}}
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowedNetworks: []string{"127.0.0.1"}, DefaultTimeoutSec: 3})
+	if res.OK || res.Error != "monitoring.error.privateBlocked" || res.Details == nil || res.Details.Transaction.FailedStep != "metadata" {
+		t.Fatalf("expected the step to be blocked, got ok=%v error=%q", res.OK, res.Error)
+	}
+}
+
+func TestCheckMonitorHTTPTransactionDeadlineAndNoReplay(t *testing.T) {
+	var posts int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/order", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+		time.Sleep(600 * time.Millisecond)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(600 * time.Millisecond)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mon := store.Monitor{Type: TypeHTTPTransaction, URL: srv.URL, AllowedStatus: []string{"200-299"}, TimeoutSec: 1, Retries: 2, RetryIntervalSec: 1}
+	mon.Options.Transaction = &store.TransactionOptions{Steps: []store.TransactionStep{
+		{Name: "order", Method: "POST", URL: "/order"},
+		{Name: "slow", URL: "/slow"},
+		{Name: "slow-again", URL: "/slow"},
+	}}
+	start := time.Now()
+	res := CheckMonitor(context.Background(), mon, store.MonitorSettings{AllowPrivateNetworks: true, DefaultTimeoutSec: 1})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the journey to stop at the monitor timeout, took %s", elapsed)
+	}
+	if res.OK || res.Details == nil || res.Details.Transaction.FailedStep != "slow" {
+		t.Fatalf("expected the second step to hit the deadline, got ok=%v error=%q", res.OK, res.Error)
+	}
+	if n := atomic.LoadInt32(&posts); n != 1 {
+		t.Fatalf("expected the side-effecting step to run once, ran %d times", n)
+	}
+}
+
+func TestValidTransactionSteps(t *testing.T) {
+	cases := []struct {
+		name  string
+		steps []store.TransactionStep
+		want  bool
+	}{
+		{name: "empty", want: false},
+		{name: "plain", steps: []store.TransactionStep{{URL: "/health"}}, want: true},
+		{name: "bad method", steps: []store.TransactionStep{{URL: "/health", Method: "TRACE"}}, want: false},
+		{name: "undefined variable", steps: []store.TransactionStep{{URL: "/users/{{uid}}"}}, want: false},
+		{name: "variable from same step", steps: []store.TransactionStep{{URL: "/users/{{uid}}", Extract: []store.TransactionExtract{{Name: "uid", From: "json", Expr: "$.id"}}}}, want: false},
+		{name: "bad source", steps: []store.TransactionStep{{URL: "/a", Extract: []store.TransactionExtract{{Name: "x", From: "xpath", Expr: "//a"}}}}, want: false},
+		{name: "bad name", steps: []store.TransactionStep{{URL: "/a", Extract: []store.TransactionExtract{{Name: "1x", From: "header", Expr: "X-Id"}}}}, want: false},
+		{name: "bad regex", steps: []store.TransactionStep{{URL: "/a", Extract: []store.TransactionExtract{{Name: "x", From: "regex", Expr: "("}}}}, want: false},
+	}
+	for _, tc := range cases {
+		if got := ValidTransactionSteps(tc.steps); got != tc.want {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestTransactionNotificationListsSteps(t *testing.T) {
+	mon := store.Monitor{Name: "Checkout", Type: TypeHTTPTransaction, URL: "https://shop.example.com"}
+	mon.Options.Transaction = &store.TransactionOptions{Steps: []store.TransactionStep{{Name: "login"}, {Name: "cart"}, {Name: "pay"}}}
+	res := CheckResult{LatencyMs: 180, Error: "status_500", Details: &store.MonitorDetails{Transaction: &store.TransactionDetails{
+		Steps: []store.TransactionStepResult{
+			{Name: "login", LatencyMs: 120, OK: true, StatusCode: 200},
+			{Name: "cart", LatencyMs: 60, StatusCode: 500, Error: "status_500"},
+		},
+		FailedStep: "cart",
+	}}}
+	msg := buildNotificationMessage("down", "en", mon, res, nil, time.Now(), false)
+	for _, want := range []string{"Failed step: cart (2/3)", "Steps: login 120 ms, cart 60 ms"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Fatalf("expected %q in notification:\n%s", want, msg.Text)
+		}
+	}
+}
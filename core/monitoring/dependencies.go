@@ -0,0 +1,178 @@
+package monitoring
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// DependencyNode is a monitor in the dependency tree together with the monitors that depend on it.
+type DependencyNode struct {
+	MonitorID int64             `json:"monitor_id"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Status    string            `json:"status"`
+	ParentID  *int64            `json:"parent_id,omitempty"`
+	Children  []*DependencyNode `json:"children,omitempty"`
+}
+
+// BuildDependencyTree arranges the monitors under their parents, keeping the given order among siblings.
+// Monitors without a known parent, or caught in a parent loop, are roots.
+func BuildDependencyTree(monitors []store.MonitorSummary) []*DependencyNode {
+	parents := dependencyParents(monitors)
+	nodes := make(map[int64]*DependencyNode, len(monitors))
+	for _, m := range monitors {
+		nodes[m.ID] = &DependencyNode{MonitorID: m.ID, Name: m.Name, Type: m.Type, Status: m.Status, ParentID: m.ParentID}
+	}
+	var roots []*DependencyNode
+	for _, m := range monitors {
+		node := nodes[m.ID]
+		if m.ParentID != nil {
+			if parent, ok := nodes[*m.ParentID]; ok && !DependencyCycle(parents, m.ID, *m.ParentID) {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// DependencyAncestors returns the parent chain of the monitor, nearest parent first.
+func DependencyAncestors(monitors []store.MonitorSummary, id int64) []*DependencyNode {
+	byID := make(map[int64]store.MonitorSummary, len(monitors))
+	for _, m := range monitors {
+		byID[m.ID] = m
+	}
+	var out []*DependencyNode
+	seen := map[int64]bool{id: true}
+	current, ok := byID[id]
+	for ok && current.ParentID != nil && !seen[*current.ParentID] {
+		seen[*current.ParentID] = true
+		current, ok = byID[*current.ParentID]
+		if ok {
+			out = append(out, &DependencyNode{MonitorID: current.ID, Name: current.Name, Type: current.Type, Status: current.Status, ParentID: current.ParentID})
+		}
+	}
+	return out
+}
+
+// DependencyCycle reports whether making parentID the parent of id would close a loop. A parent chain that
+// already loops counts as a cycle as well.
+func DependencyCycle(parents map[int64]int64, id, parentID int64) bool {
+	seen := map[int64]bool{}
+	for current := parentID; !seen[current]; {
+		if current == id {
+			return true
+		}
+		seen[current] = true
+		next, ok := parents[current]
+		if !ok {
+			return false
+		}
+		current = next
+	}
+	return true
+}
+
+func dependencyParents(monitors []store.MonitorSummary) map[int64]int64 {
+	out := make(map[int64]int64, len(monitors))
+	for _, m := range monitors {
+		if m.ParentID != nil {
+			out[m.ID] = *m.ParentID
+		}
+	}
+	return out
+}
+
+// unreachableParent returns the parent of a failed monitor when the last stored result of the parent is down.
+// The stored result is trusted while it is younger than the parent interval plus one check; an older one is not
+// used, and a parent that was up before the failure is checked in the background once per outage of the child,
+// so the child never waits for its parent.
+func (e *Engine) unreachableParent(ctx context.Context, m store.Monitor, prev *store.MonitorState, checkedAt time.Time, settings store.MonitorSettings) *store.Monitor {
+	if m.ParentID == nil || *m.ParentID == m.ID {
+		return nil
+	}
+	parent, err := e.store.GetMonitor(ctx, *m.ParentID)
+	if err != nil || parent == nil || parent.IsPaused || !parent.IsActive {
+		return nil
+	}
+	state, _ := e.store.GetMonitorState(ctx, parent.ID)
+	if !parentStateFresh(*parent, state, settings, checkedAt) {
+		e.refreshParent(ctx, *parent, settings)
+		return nil
+	}
+	if parentStateDown(state) {
+		return parent
+	}
+	prevStatus := monitorOutageStatus(prev)
+	if state.LastCheckedAt.Before(checkedAt) && prevStatus != "down" && prevStatus != "unreachable" {
+		e.refreshParent(ctx, *parent, settings)
+	}
+	return nil
+}
+
+func parentStateDown(state *store.MonitorState) bool {
+	return monitorRawStatus(state) == "down"
+}
+
+// parentStateFresh reports whether the stored parent result still describes the parent at now.
+func parentStateFresh(parent store.Monitor, state *store.MonitorState, settings store.MonitorSettings, now time.Time) bool {
+	if state == nil || state.LastCheckedAt == nil {
+		return false
+	}
+	interval := parent.IntervalSec
+	if interval <= 0 {
+		interval = settings.DefaultIntervalSec
+	}
+	if interval <= 0 {
+		interval = 60
+	}
+	window := time.Duration(interval+max(parent.PushGraceSec, 0))*time.Second + parentCheckBudget(parent, settings)
+	return now.Sub(*state.LastCheckedAt) <= window
+}
+
+// refreshParent runs the parent check in its own slot unless one is already in flight or the engine is busy,
+// in which case the scheduler gets to it. Passive parents change only when they report.
+// The check is detached from the deadline of the child check so that it cannot fail on it.
+func (e *Engine) refreshParent(ctx context.Context, parent store.Monitor, settings store.MonitorSettings) {
+	if TypeIsPassive(parent.Type) || !e.acquireSlot(parent.ID) {
+		return
+	}
+	go func() {
+		defer e.releaseSlot(parent.ID)
+		_ = e.runCheck(context.WithoutCancel(ctx), parent, settings)
+	}()
+}
+
+// parentCheckBudget is the longest a parent check with all its retries may take.
+func parentCheckBudget(m store.Monitor, settings store.MonitorSettings) time.Duration {
+	timeoutSec := m.TimeoutSec
+	if timeoutSec <= 0 {
+		timeoutSec = settings.DefaultTimeoutSec
+	}
+	if timeoutSec <= 0 {
+		timeoutSec = 20
+	}
+	retries := max(m.Retries, 0)
+	return time.Duration((timeoutSec+2)*(retries+1)+max(m.RetryIntervalSec, 0)*retries) * time.Second
+}
+
+// monitorOutageStatus is the raw result of the state with failures behind a down parent reported as unreachable,
+// so an outage of the monitor itself starts only once its parent is back.
+func monitorOutageStatus(st *store.MonitorState) string {
+	status := monitorRawStatus(st)
+	if status == "down" && st.Unreachable {
+		return "unreachable"
+	}
+	return status
+}
+
+func dependencyEventMessage(parent *store.Monitor) string {
+	if parent == nil {
+		return ""
+	}
+	return strings.TrimSpace(parent.Name)
+}
@@ -0,0 +1,41 @@
+package monitoring
+
+import (
+	"testing"
+
+	"berkut-scc/core/store"
+)
+
+func TestBuildDependencyTree(t *testing.T) {
+	ref := func(id int64) *int64 { return &id }
+	monitors := []store.MonitorSummary{
+		{Monitor: store.Monitor{ID: 1, Name: "router"}},
+		{Monitor: store.Monitor{ID: 2, Name: "switch", ParentID: ref(1)}},
+		{Monitor: store.Monitor{ID: 3, Name: "web", ParentID: ref(2)}},
+		{Monitor: store.Monitor{ID: 4, Name: "orphan", ParentID: ref(99)}},
+		{Monitor: store.Monitor{ID: 5, Name: "loop-a", ParentID: ref(6)}},
+		{Monitor: store.Monitor{ID: 6, Name: "loop-b", ParentID: ref(5)}},
+	}
+	roots := BuildDependencyTree(monitors)
+	if len(roots) != 4 || roots[0].MonitorID != 1 || roots[1].MonitorID != 4 {
+		t.Fatalf("unexpected roots %+v", roots)
+	}
+	if len(roots[0].Children) != 1 || len(roots[0].Children[0].Children) != 1 || roots[0].Children[0].Children[0].MonitorID != 3 {
+		t.Fatalf("unexpected tree under the router %+v", roots[0])
+	}
+	ancestors := DependencyAncestors(monitors, 3)
+	if len(ancestors) != 2 || ancestors[0].MonitorID != 2 || ancestors[1].MonitorID != 1 {
+		t.Fatalf("unexpected ancestors %+v", ancestors)
+	}
+
+	parents := dependencyParents(monitors)
+	if !DependencyCycle(parents, 1, 3) || !DependencyCycle(parents, 1, 1) {
+		t.Fatalf("expected a cycle when the router depends on its own subtree")
+	}
+	if DependencyCycle(parents, 3, 4) || DependencyCycle(parents, 4, 1) {
+		t.Fatalf("expected no cycle for independent monitors")
+	}
+	if !DependencyCycle(parents, 1, 5) {
+		t.Fatalf("expected a parent chain that already loops to count as a cycle")
+	}
+}
@@ -2,6 +2,7 @@ package monitoring
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
@@ -21,6 +22,7 @@ type Engine struct {
 	sender            TelegramSender
 	incidentRegFormat string
 	taskStore         tasks.Store
+	controls          store.ControlsStore
 	logger            *utils.Logger
 	cancel            context.CancelFunc
 	running           bool
@@ -29,11 +31,15 @@ type Engine struct {
 	inFlight          map[int64]struct{}
 	sem               chan struct{}
 	maxConcurrent     int
+	queueDepth        int
+	deliveries        map[deliveryKey]int64
 	lastSettingsAt    time.Time
 	settings          store.MonitorSettings
 	lastCleanupAt     time.Time
 	lastMaintenanceAt time.Time
 	lastSLAAt         time.Time
+	pushLocks         map[int64]*sync.Mutex
+	probeReplays      map[string]time.Time
 }
 
 func NewEngine(store store.MonitoringStore, logger *utils.Logger) *Engine {
@@ -60,6 +66,14 @@ func (e *Engine) SetTaskStore(taskStore tasks.Store) {
 	e.taskStore = taskStore
 }
 
+// SetControlsStore enables control violations for monitors linked to a control.
+func (e *Engine) SetControlsStore(controls store.ControlsStore) {
+	if e == nil {
+		return
+	}
+	e.controls = controls
+}
+
 func (e *Engine) Start() {
 	e.StartWithContext(context.Background())
 }
@@ -233,11 +247,29 @@ func (e *Engine) runDueChecks(ctx context.Context, settings store.MonitorSetting
 		}
 		return
 	}
+	waiting := 0
+	defer func() {
+		e.mu.Lock()
+		e.queueDepth = waiting
+		e.mu.Unlock()
+	}()
 	for _, m := range list {
 		if TypeIsPassive(m.Type) {
+			if !e.pushOverdue(ctx, m, time.Now().UTC()) {
+				continue
+			}
+			if !e.acquireSlot(m.ID) {
+				waiting++
+				continue
+			}
+			go func(mon store.Monitor) {
+				defer e.releaseSlot(mon.ID)
+				_ = e.recordMissedPush(ctx, mon, settings)
+			}(m)
 			continue
 		}
 		if !e.acquireSlot(m.ID) {
+			waiting++
 			continue
 		}
 		go func(mon store.Monitor) {
@@ -257,10 +289,16 @@ func (e *Engine) runRetention(ctx context.Context, settings store.MonitorSetting
 	if !last.IsZero() && time.Since(last) < time.Hour {
 		return
 	}
-	before := time.Now().UTC().Add(-time.Duration(settings.RetentionDays) * 24 * time.Hour)
+	now := time.Now().UTC()
+	before := now.Add(-time.Duration(settings.RetentionDays) * 24 * time.Hour)
+	if err := e.store.FinalizeMetricRollups(ctx, before, now); err != nil && e.logger != nil {
+		e.logger.Errorf("monitoring rollup percentiles: %v", err)
+	}
+	e.settleSLARollups(ctx, before, now)
 	if _, err := e.store.DeleteMetricsBefore(ctx, before); err != nil && e.logger != nil {
 		e.logger.Errorf("monitoring retention: %v", err)
 	}
+	e.pruneRollups(ctx, settings, now)
 	e.mu.Lock()
 	e.lastCleanupAt = time.Now().UTC()
 	e.mu.Unlock()
@@ -304,7 +342,69 @@ func (e *Engine) releaseSlot(id int64) {
 }
 
 func (e *Engine) runCheck(ctx context.Context, m store.Monitor, settings store.MonitorSettings) error {
-	result := CheckMonitor(ctx, m, settings)
+	var result CheckResult
+	var dnsChanges []string
+	var hostKeyChange, gradeDrop, headersRegression string
+	if creds, err := e.monitorCredentials(m); err != nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring credentials %d: %v", m.ID, err)
+		}
+		result = CheckResult{OK: false, Error: "monitoring.error.credentialsUnavailable", CheckedAt: time.Now().UTC()}
+	} else {
+		m.Credentials = creds
+		probes := probeOptions(m)
+		if probes == nil || probes.IncludeLocal {
+			result = CheckMonitor(ctx, m, settings)
+		}
+		if probes != nil {
+			result = e.probeCheck(ctx, m, *probes, result)
+		}
+		if result.Details != nil {
+			var prevDetails *store.MonitorDetails
+			if prev, err := e.store.GetMonitorState(ctx, m.ID); err == nil && prev != nil {
+				prevDetails = prev.Details
+			}
+			checkContainerRestarts(prevDetails, &result)
+			checkGamePlayers(m, prevDetails, &result)
+			dnsChanges = detectDNSDrift(prevDetails, &result)
+			hostKeyChange = detectSSHHostKeyChange(m, prevDetails, result)
+			headersRegression = detectHeadersRegression(prevDetails, result)
+		}
+		if result.TLS != nil && result.TLS.Grade != "" {
+			prevTLS, _ := e.store.GetTLS(ctx, m.ID)
+			gradeDrop = detectTLSGradeDrop(prevTLS, result)
+		}
+	}
+	if err := e.recordResult(ctx, m, result, settings); err != nil {
+		return err
+	}
+	e.handleDNSDrift(ctx, m, dnsChanges, result.CheckedAt)
+	e.handleSSHHostKeyChange(ctx, m, hostKeyChange, result.CheckedAt)
+	e.handleTLSGradeDrop(ctx, m, gradeDrop, result)
+	e.handleContentChange(ctx, m, result)
+	e.handleHeadersRegression(ctx, m, headersRegression, result)
+	return nil
+}
+
+func (e *Engine) monitorCredentials(m store.Monitor) (*store.MonitorCredentials, error) {
+	if len(m.CredentialsEnc) == 0 {
+		return nil, nil
+	}
+	if e.encryptor == nil {
+		return nil, errors.New("encryptor not configured")
+	}
+	raw, err := e.encryptor.DecryptBlob(m.CredentialsEnc)
+	if err != nil {
+		return nil, err
+	}
+	var creds store.MonitorCredentials
+	if err := json.Unmarshal(raw, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (e *Engine) storeResult(ctx context.Context, m store.Monitor, result CheckResult, settings store.MonitorSettings) (func(), error) {
 	if result.CheckedAt.IsZero() {
 		result.CheckedAt = time.Now().UTC()
 	}
@@ -318,14 +418,27 @@ func (e *Engine) runCheck(ctx context.Context, m store.Monitor, settings store.M
 		val := result.Error
 		errText = &val
 	}
-	_, err := e.store.AddMetric(ctx, &store.MonitorMetric{
+	metric := &store.MonitorMetric{
 		MonitorID:  m.ID,
 		TS:         result.CheckedAt,
 		LatencyMs:  result.LatencyMs,
 		OK:         result.OK,
 		StatusCode: statusCode,
 		Error:      errText,
-	})
+		Values:     result.Values,
+	}
+	if p := result.Ping; p != nil {
+		metric.PacketsSent = &p.Sent
+		metric.PacketsReceived = &p.Received
+		metric.PacketLossPct = &p.LossPct
+		if p.Received > 0 {
+			metric.RTTMinMs = &p.MinMs
+			metric.RTTAvgMs = &p.AvgMs
+			metric.RTTMaxMs = &p.MaxMs
+			metric.JitterMs = &p.JitterMs
+		}
+	}
+	_, err := e.store.AddMetric(ctx, metric)
 	if err != nil && e.logger != nil {
 		e.logger.Errorf("monitoring add metric: %v", err)
 	}
@@ -333,7 +446,17 @@ func (e *Engine) runCheck(ctx context.Context, m store.Monitor, settings store.M
 	return e.updateState(ctx, m, result, tlsRecord, settings)
 }
 
-func (e *Engine) updateState(ctx context.Context, m store.Monitor, result CheckResult, tlsRecord *store.MonitorTLS, settings store.MonitorSettings) error {
+func (e *Engine) recordResult(ctx context.Context, m store.Monitor, result CheckResult, settings store.MonitorSettings) error {
+	automate, err := e.storeResult(ctx, m, result, settings)
+	if automate != nil {
+		automate()
+	}
+	return err
+}
+
+// updateState stores the new state of the monitor and returns the notification, incident and task step for
+// the transition, so callers holding a per-monitor lock can run it after releasing the lock.
+func (e *Engine) updateState(ctx context.Context, m store.Monitor, result CheckResult, tlsRecord *store.MonitorTLS, settings store.MonitorSettings) (func(), error) {
 	prev, _ := e.store.GetMonitorState(ctx, m.ID)
 	rawStatus := "down"
 	if result.OK {
@@ -344,19 +467,32 @@ func (e *Engine) updateState(ctx context.Context, m store.Monitor, result CheckR
 	if list, err := e.store.ActiveMaintenanceFor(ctx, m.ID, m.Tags, now); err == nil && len(list) > 0 {
 		maintenanceActive = true
 	}
+	var parent *store.Monitor
+	if rawStatus == "down" {
+		parent = e.unreachableParent(ctx, m, prev, now, settings)
+	}
+	unreachable := parent != nil
 	status := rawStatus
 	if m.IsPaused {
 		status = "paused"
 	} else if maintenanceActive {
 		status = "maintenance"
+	} else if unreachable {
+		status = "unreachable"
+	} else if result.OK && result.Degraded {
+		status = "degraded"
 	}
 	next := &store.MonitorState{
 		MonitorID:         m.ID,
 		Status:            status,
 		LastResultStatus:  rawStatus,
 		MaintenanceActive: maintenanceActive,
+		Unreachable:       unreachable,
 		LastCheckedAt:     &now,
 		LastError:         result.Error,
+		ServerVersion:     result.ServerVersion,
+		ServerRole:        result.ServerRole,
+		Details:           result.Details,
 	}
 	if result.StatusCode != nil {
 		val := *result.StatusCode
@@ -378,6 +514,22 @@ func (e *Engine) updateState(ctx context.Context, m store.Monitor, result CheckR
 		if next.LastDownAt == nil {
 			next.LastDownAt = prev.LastDownAt
 		}
+		if next.ServerVersion == "" {
+			next.ServerVersion = prev.ServerVersion
+		}
+		if next.Details == nil {
+			next.Details = prev.Details
+		}
+		if next.ServerRole == "" {
+			next.ServerRole = prev.ServerRole
+		} else if prev.ServerRole != "" && prev.ServerRole != next.ServerRole {
+			_, _ = e.store.AddEvent(ctx, &store.MonitorEvent{
+				MonitorID: m.ID,
+				TS:        now,
+				EventType: "role_changed",
+				Message:   prev.ServerRole + " -> " + next.ServerRole,
+			})
+		}
 		shouldLog := false
 		if prev.LastCheckedAt == nil || prev.LastResultStatus == "" {
 			shouldLog = rawStatus == "down"
@@ -406,6 +558,14 @@ func (e *Engine) updateState(ctx context.Context, m store.Monitor, result CheckR
 				Message:   msg,
 			})
 		}
+		if status == "degraded" && prev.Status != "degraded" {
+			_, _ = e.store.AddEvent(ctx, &store.MonitorEvent{
+				MonitorID: m.ID,
+				TS:        now,
+				EventType: "degraded",
+				Message:   result.Error,
+			})
+		}
 		if prev.MaintenanceActive != maintenanceActive {
 			eventType := "maintenance_start"
 			if !maintenanceActive {
@@ -419,6 +579,25 @@ func (e *Engine) updateState(ctx context.Context, m store.Monitor, result CheckR
 			})
 		}
 	}
+	if unreachable != (prev != nil && prev.Unreachable) {
+		eventType := store.EventUnreachableStart
+		var windowErr error
+		if unreachable {
+			windowErr = e.store.OpenUnreachableWindow(ctx, m.ID, &parent.ID, now)
+		} else {
+			eventType = store.EventUnreachableEnd
+			windowErr = e.store.CloseUnreachableWindow(ctx, m.ID, now)
+		}
+		if windowErr != nil && e.logger != nil {
+			e.logger.Errorf("monitoring unreachable window monitor %d: %v", m.ID, windowErr)
+		}
+		_, _ = e.store.AddEvent(ctx, &store.MonitorEvent{
+			MonitorID: m.ID,
+			TS:        now,
+			EventType: eventType,
+			Message:   dependencyEventMessage(parent),
+		})
+	}
 	if tlsRecord != nil {
 		next.TLSNotAfter = &tlsRecord.NotAfter
 		days := int(time.Until(tlsRecord.NotAfter).Hours() / 24)
@@ -444,8 +623,10 @@ func (e *Engine) updateState(ctx context.Context, m store.Monitor, result CheckR
 	if err := e.fillStats(ctx, m.ID, next, now); err != nil && e.logger != nil {
 		e.logger.Errorf("monitoring stats: %v", err)
 	}
-	e.handleAutomation(ctx, m, prev, next, result, tlsRecord, settings)
-	return e.store.UpsertMonitorState(ctx, next)
+	if err := e.store.UpsertMonitorState(ctx, next); err != nil {
+		return nil, err
+	}
+	return func() { e.handleAutomation(ctx, m, prev, next, result, tlsRecord, settings) }, nil
 }
 
 func (e *Engine) fillStats(ctx context.Context, monitorID int64, st *store.MonitorState, now time.Time) error {
@@ -501,6 +682,8 @@ func (e *Engine) runMaintenance(ctx context.Context, settings store.MonitorSetti
 			display = "paused"
 		} else if active {
 			display = "maintenance"
+		} else if rawStatus == "down" && state.Unreachable {
+			display = "unreachable"
 		}
 		prevMaint := state.MaintenanceActive
 		if prevMaint == active && state.Status == display {
@@ -532,7 +715,7 @@ func (e *Engine) updateTLS(ctx context.Context, m store.Monitor, result CheckRes
 	if !TypeSupportsTLSMetadata(kind) {
 		return nil
 	}
-	if !(strings.HasPrefix(strings.ToLower(strings.TrimSpace(m.URL)), "https://") || strings.HasPrefix(strings.ToLower(strings.TrimSpace(m.URL)), "grpcs://")) {
+	if TypeUsesURL(kind) && !(strings.HasPrefix(strings.ToLower(strings.TrimSpace(m.URL)), "https://") || strings.HasPrefix(strings.ToLower(strings.TrimSpace(m.URL)), "grpcs://")) {
 		return nil
 	}
 	now := result.CheckedAt
@@ -540,7 +723,8 @@ func (e *Engine) updateTLS(ctx context.Context, m store.Monitor, result CheckRes
 		now = time.Now().UTC()
 	}
 	if result.TLS != nil {
-		if settings.TLSRefreshHours > 0 {
+		// tls monitors store a fresh scan on every check, so a grade drop is compared against the latest one.
+		if settings.TLSRefreshHours > 0 && kind != TypeTLS {
 			if existing, _ := e.store.GetTLS(ctx, m.ID); existing != nil {
 				if time.Since(existing.CheckedAt) < time.Duration(settings.TLSRefreshHours)*time.Hour {
 					return existing
@@ -557,6 +741,8 @@ func (e *Engine) updateTLS(ctx context.Context, m store.Monitor, result CheckRes
 			SANs:              result.TLS.SANs,
 			FingerprintSHA256: result.TLS.FingerprintSHA256,
 			LastError:         nil,
+			Grade:             result.TLS.Grade,
+			Security:          result.TLS.Security,
 		}
 		_ = e.store.UpsertTLS(ctx, record)
 		return record
@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package monitoring
+
+import (
+	"fmt"
+	"net"
+)
+
+// listenICMP opens a raw ICMP socket; datagram ICMP sockets are not available on this platform.
+func listenICMP(v6 bool) (net.PacketConn, bool, error) {
+	network, addr := "ip4:icmp", "0.0.0.0"
+	if v6 {
+		network, addr = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrICMPUnavailable, err)
+	}
+	return conn, false, nil
+}
@@ -0,0 +1,48 @@
+//go:build linux || darwin
+
+package monitoring
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// listenICMP prefers unprivileged datagram ICMP sockets (net.ipv4.ping_group_range on Linux)
+// and falls back to raw sockets, which require root or CAP_NET_RAW.
+// The returned flag reports whether the socket is a datagram one.
+func listenICMP(v6 bool) (net.PacketConn, bool, error) {
+	if conn, err := listenICMPDatagram(v6); err == nil {
+		return conn, true, nil
+	}
+	network, addr := "ip4:icmp", "0.0.0.0"
+	if v6 {
+		network, addr = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := net.ListenPacket(network, addr)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrICMPUnavailable, err)
+	}
+	return conn, false, nil
+}
+
+func listenICMPDatagram(v6 bool) (net.PacketConn, error) {
+	family, proto := syscall.AF_INET, syscall.IPPROTO_ICMP
+	var sa syscall.Sockaddr = &syscall.SockaddrInet4{}
+	if v6 {
+		family, proto = syscall.AF_INET6, syscall.IPPROTO_ICMPV6
+		sa = &syscall.SockaddrInet6{}
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM, proto)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Bind(fd, sa); err != nil {
+		_ = syscall.Close(fd)
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), "icmp")
+	defer f.Close()
+	return net.FilePacketConn(f)
+}
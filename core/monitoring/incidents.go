@@ -10,18 +10,15 @@ import (
 	"berkut-scc/core/store"
 )
 
-func (e *Engine) handleAutoIncident(ctx context.Context, m store.Monitor, prev, next *store.MonitorState, rawStatus string, now time.Time, settings store.MonitorSettings) {
+func (e *Engine) handleAutoIncident(ctx context.Context, m store.Monitor, prev, next *store.MonitorState, rawStatus string, outageStart bool, now time.Time, settings store.MonitorSettings) {
 	if e.incidents == nil || !m.AutoIncident {
 		return
 	}
 	if next == nil || m.IsPaused || next.MaintenanceActive {
 		return
 	}
-	prevStatus := ""
-	if prev != nil {
-		prevStatus = strings.ToLower(strings.TrimSpace(prev.LastResultStatus))
-	}
-	if rawStatus == "down" && prevStatus != "down" {
+	prevStatus := monitorRawStatus(prev)
+	if rawStatus == "down" && outageStart {
 		existing, _ := e.incidents.FindOpenIncidentBySource(ctx, "monitoring", m.ID)
 		if existing != nil {
 			return
@@ -0,0 +1,212 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const maxRedirects = 10
+
+var errTooManyRedirects = errors.New("stopped after 10 redirects")
+
+// defaultBlockedNetworks are refused unless private networks are allowed or the range is in the allow list.
+var defaultBlockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"224.0.0.0/4",
+	"255.255.255.255/32",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// networkPolicy decides which addresses checks may connect to. Precedence, first match wins:
+// the blocked list refuses, then AllowPrivateNetworks or the allowed list permits,
+// then the default private ranges refuse and everything else is permitted.
+// The allowed list only lifts the default block; it never restricts public addresses.
+type networkPolicy struct {
+	allowPrivate bool
+	allowed      []*net.IPNet
+	blocked      []*net.IPNet
+}
+
+func newNetworkPolicy(settings store.MonitorSettings) networkPolicy {
+	return networkPolicy{
+		allowPrivate: settings.AllowPrivateNetworks,
+		allowed:      parseNetworks(settings.AllowedNetworks),
+		blocked:      parseNetworks(settings.BlockedNetworks),
+	}
+}
+
+// allowsAll reports whether no address can be refused, so resolving names up front is pointless.
+func (p networkPolicy) allowsAll() bool {
+	return p.allowPrivate && len(p.blocked) == 0
+}
+
+func (p networkPolicy) permits(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if containsIP(p.blocked, ip) {
+		return false
+	}
+	if p.allowPrivate || containsIP(p.allowed, ip) {
+		return true
+	}
+	return !isPrivateIP(ip)
+}
+
+// guardTarget resolves host and refuses it when any address is outside the network policy.
+// It gives an early, readable error; guardedDialer repeats the check on the address actually dialed.
+func guardTarget(ctx context.Context, host string, settings store.MonitorSettings) error {
+	policy := newNetworkPolicy(settings)
+	if policy.allowsAll() {
+		return nil
+	}
+	host = strings.Trim(strings.TrimSpace(host), "[]")
+	if host == "" {
+		return ErrPrivateBlocked
+	}
+	lower := strings.ToLower(host)
+	if lower == "localhost" || strings.HasSuffix(lower, ".localhost") {
+		if !policy.permits(net.IPv4(127, 0, 0, 1)) {
+			return ErrPrivateBlocked
+		}
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !policy.permits(ip) {
+			return ErrPrivateBlocked
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !policy.permits(addr.IP) {
+			return ErrPrivateBlocked
+		}
+	}
+	return nil
+}
+
+// guardedDialer returns a dialer that checks every resolved address right before connecting,
+// so a name that re-resolves to a private address after guardTarget (DNS rebinding) is still refused.
+func guardedDialer(settings store.MonitorSettings, timeout time.Duration) *net.Dialer {
+	policy := newNetworkPolicy(settings)
+	dialer := &net.Dialer{Timeout: timeout}
+	if policy.allowsAll() {
+		return dialer
+	}
+	dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		if strings.HasPrefix(network, "unix") {
+			return nil
+		}
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return ErrPrivateBlocked
+		}
+		if !policy.permits(net.ParseIP(host)) {
+			return ErrPrivateBlocked
+		}
+		return nil
+	}
+	return dialer
+}
+
+// guardedProxy wraps a transport proxy func so the target is checked before the request goes through a proxy.
+// The dialer then only sees the proxy address, so without this any host behind the proxy would be reachable.
+func guardedProxy(settings store.MonitorSettings, proxy func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL, err := proxy(req)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+		if err := guardTarget(req.Context(), req.URL.Hostname(), settings); err != nil {
+			return nil, err
+		}
+		return proxyURL, nil
+	}
+}
+
+// guardRedirect checks every redirect hop against the network policy before it is followed.
+// The dialer catches the same hops, and guardedProxy covers them when a proxy connects on our behalf.
+func guardRedirect(settings store.MonitorSettings) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errTooManyRedirects
+		}
+		return guardTarget(req.Context(), req.URL.Hostname(), settings)
+	}
+}
+
+// ValidNetwork reports whether raw is a CIDR or a single IP address usable in the allow/deny lists.
+func ValidNetwork(raw string) bool {
+	return parseNetwork(raw) != nil
+}
+
+func parseNetwork(raw string) *net.IPNet {
+	raw = strings.TrimSpace(raw)
+	if _, network, err := net.ParseCIDR(raw); err == nil {
+		return network
+	}
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+func parseNetworks(list []string) []*net.IPNet {
+	var out []*net.IPNet
+	for _, raw := range list {
+		if network := parseNetwork(raw); network != nil {
+			out = append(out, network)
+		}
+	}
+	return out
+}
+
+func mustParseCIDRs(list ...string) []*net.IPNet {
+	out := parseNetworks(list)
+	if len(out) != len(list) {
+		panic("monitoring: invalid default network")
+	}
+	return out
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPrivateIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	return containsIP(defaultBlockedNetworks, ip)
+}
@@ -0,0 +1,154 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func TestNetworkPolicy(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings store.MonitorSettings
+		ip       string
+		want     bool
+	}{
+		{name: "public", ip: "93.184.216.34", want: true},
+		{name: "loopback", ip: "127.0.0.1", want: false},
+		{name: "metadata", ip: "169.254.169.254", want: false},
+		{name: "unspecified", ip: "0.0.0.0", want: false},
+		{name: "cgnat", ip: "100.100.100.200", want: false},
+		{name: "mapped loopback", ip: "::ffff:127.0.0.1", want: false},
+		{name: "ula", ip: "fd00::1", want: false},
+		{name: "allow list", settings: store.MonitorSettings{AllowedNetworks: []string{"10.20.0.0/16"}}, ip: "10.20.3.4", want: true},
+		{name: "outside allow list", settings: store.MonitorSettings{AllowedNetworks: []string{"10.20.0.0/16"}}, ip: "10.30.3.4", want: false},
+		{name: "private allowed", settings: store.MonitorSettings{AllowPrivateNetworks: true}, ip: "192.168.1.1", want: true},
+		{name: "deny beats private", settings: store.MonitorSettings{AllowPrivateNetworks: true, BlockedNetworks: []string{"169.254.169.254"}}, ip: "169.254.169.254", want: false},
+		{name: "deny beats allow", settings: store.MonitorSettings{AllowedNetworks: []string{"10.0.0.0/8"}, BlockedNetworks: []string{"10.0.0.0/24"}}, ip: "10.0.0.5", want: false},
+		{name: "deny public", settings: store.MonitorSettings{BlockedNetworks: []string{"93.184.216.0/24"}}, ip: "93.184.216.34", want: false},
+	}
+	for _, tc := range cases {
+		if got := newNetworkPolicy(tc.settings).permits(net.ParseIP(tc.ip)); got != tc.want {
+			t.Fatalf("%s: permits(%s)=%v, want %v", tc.name, tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestNetworkPolicyPrecedence(t *testing.T) {
+	settings := store.MonitorSettings{
+		AllowPrivateNetworks: true,
+		AllowedNetworks:      []string{"10.0.0.0/8"},
+		BlockedNetworks:      []string{"10.1.0.0/16", "93.184.216.0/24"},
+	}
+	policy := newNetworkPolicy(settings)
+	cases := map[string]bool{
+		"10.1.2.3":      false, // blocked list beats both the allowed list and allow_private_networks
+		"10.2.0.1":      true,  // allowed list
+		"192.168.1.1":   true,  // allow_private_networks, outside the allowed list
+		"93.184.216.34": false, // blocked list applies to public addresses too
+		"8.8.8.8":       true,  // the allowed list does not restrict public addresses
+	}
+	for ip, want := range cases {
+		if got := policy.permits(net.ParseIP(ip)); got != want {
+			t.Fatalf("permits(%s)=%v, want %v", ip, got, want)
+		}
+	}
+	if policy.allowsAll() {
+		t.Fatalf("a blocked list must keep the policy from allowing everything")
+	}
+	settings.BlockedNetworks = nil
+	if !newNetworkPolicy(settings).allowsAll() {
+		t.Fatalf("allow_private_networks without a blocked list should allow everything")
+	}
+}
+
+func TestHTTPMonitorClientChecksTargetBehindProxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	mon := store.Monitor{Type: TypeHTTP, Options: store.MonitorOptions{HTTP: &store.HTTPOptions{Proxy: proxy.URL}}}
+	settings := store.MonitorSettings{AllowedNetworks: []string{"127.0.0.1/32"}}
+	client, err := httpMonitorClient(mon, settings, 3*time.Second)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	resp, err := client.Get("http://10.1.2.3/latest/meta-data")
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrPrivateBlocked) || proxied {
+		t.Fatalf("expected private target behind proxy to be blocked, got err=%v proxied=%v", err, proxied)
+	}
+
+	settings.AllowedNetworks = append(settings.AllowedNetworks, "10.1.2.3")
+	client, err = httpMonitorClient(mon, settings, 3*time.Second)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	resp, err = client.Get("http://10.1.2.3/latest/meta-data")
+	if err != nil {
+		t.Fatalf("expected allow-listed target through proxy, got %v", err)
+	}
+	resp.Body.Close()
+	if !proxied {
+		t.Fatalf("expected request to go through the proxy")
+	}
+}
+
+func TestGuardedDialerChecksDialedAddress(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	// A name that passed guardTarget but now resolves to loopback ends up here with the loopback address.
+	_, err = guardedDialer(store.MonitorSettings{}, time.Second).DialContext(context.Background(), "tcp", addr)
+	if !errors.Is(err, ErrPrivateBlocked) {
+		t.Fatalf("expected dial to loopback to be blocked, got %v", err)
+	}
+	conn, err := guardedDialer(store.MonitorSettings{AllowedNetworks: []string{"127.0.0.1"}}, time.Second).DialContext(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatalf("expected allow-listed dial, got %v", err)
+	}
+	_ = conn.Close()
+}
+
+func TestCheckMonitorHTTPRedirectToBlockedNetwork(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("127.0.0.2 is not routable here: %v", err)
+	}
+	internal := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	internal.Listener = inner
+	internal.Start()
+	defer internal.Close()
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL+"/latest/meta-data", http.StatusFound)
+	}))
+	defer public.Close()
+
+	mon := store.Monitor{Type: TypeHTTP, URL: public.URL, Method: "GET", AllowedStatus: []string{"200-299"}, TimeoutSec: 3}
+	settings := store.MonitorSettings{AllowedNetworks: []string{"127.0.0.1/32"}, DefaultTimeoutSec: 3}
+	res := CheckMonitor(context.Background(), mon, settings)
+	if res.OK || res.Error != "monitoring.error.privateBlocked" {
+		t.Fatalf("expected redirect hop to be blocked, got ok=%v error=%q", res.OK, res.Error)
+	}
+	settings.AllowedNetworks = append(settings.AllowedNetworks, "127.0.0.2")
+	if res := CheckMonitor(context.Background(), mon, settings); !res.OK {
+		t.Fatalf("expected allow-listed redirect to be followed, got error=%q", res.Error)
+	}
+}
@@ -120,29 +120,41 @@ func (e *Engine) handleAutomation(ctx context.Context, m store.Monitor, prev, ne
 	if rawStatus == "" {
 		rawStatus = "down"
 	}
+	// A failure behind a down parent is covered by the alerts of the parent.
+	if rawStatus == "down" && next.Unreachable {
+		rawStatus = "unreachable"
+	}
 	st, _ := e.store.GetNotificationState(ctx, m.ID)
 	if st == nil {
 		st = &store.MonitorNotificationState{MonitorID: m.ID}
 	}
-	if rawStatus == "down" {
-		if prev == nil || strings.ToLower(strings.TrimSpace(prev.LastResultStatus)) != "down" {
+	// An outage that was open when the parent went down and fails again once the parent is back is the same
+	// outage: it stays open while unreachable, so it is not notified, tasked or escalated twice.
+	outageStart := false
+	switch rawStatus {
+	case "down":
+		prevStatus := monitorOutageStatus(prev)
+		if prevStatus == "down" || (prevStatus == "unreachable" && st.DownStartedAt != nil) {
+			st.DownSequence++
+		} else {
 			st.DownStartedAt = &now
 			st.DownSequence = 1
-		} else {
-			st.DownSequence++
+			outageStart = true
 		}
-	} else {
+	case "unreachable":
+		// Leave DownStartedAt as is: it tells whether the monitor was already down when its parent went down.
+	default:
 		st.DownStartedAt = nil
 		st.DownSequence = 0
 	}
-	e.handleNotifications(ctx, m, prev, next, rawStatus, now, st, tlsRecord, result, settings)
-	e.handleAutoTaskOnDown(ctx, m, prev, next, now)
+	e.handleNotifications(ctx, m, prev, next, rawStatus, outageStart, now, st, tlsRecord, result, settings)
+	e.handleAutoTaskOnDown(ctx, m, prev, next, outageStart, now)
 	e.handleAutoTLSIncident(ctx, m, prev, next, tlsRecord, now, settings)
-	e.handleAutoIncident(ctx, m, prev, next, rawStatus, now, settings)
+	e.handleAutoIncident(ctx, m, prev, next, rawStatus, outageStart, now, settings)
 	_ = e.store.UpsertNotificationState(ctx, st)
 }
 
-func (e *Engine) handleNotifications(ctx context.Context, m store.Monitor, prev, next *store.MonitorState, rawStatus string, now time.Time, st *store.MonitorNotificationState, tlsRecord *store.MonitorTLS, result CheckResult, settings store.MonitorSettings) {
+func (e *Engine) handleNotifications(ctx context.Context, m store.Monitor, prev, next *store.MonitorState, rawStatus string, outageStart bool, now time.Time, st *store.MonitorNotificationState, tlsRecord *store.MonitorTLS, result CheckResult, settings store.MonitorSettings) {
 	if e.sender == nil || e.encryptor == nil {
 		return
 	}
@@ -202,7 +214,7 @@ func (e *Engine) handleNotifications(ctx context.Context, m store.Monitor, prev,
 		return
 	}
 	if rawStatus == "down" &&
-		(prev == nil || prev.LastCheckedAt == nil || strings.ToLower(strings.TrimSpace(prev.LastResultStatus)) != "down") &&
+		(prev == nil || prev.LastCheckedAt == nil || outageStart) &&
 		canNotifyDownOutage() &&
 		canSend(st.LastNotifiedAt) &&
 		canSend(st.LastDownNotifiedAt) {
@@ -307,7 +319,11 @@ func (e *Engine) dispatchNotification(ctx context.Context, channels []store.Noti
 }
 
 func (e *Engine) logNotificationDelivery(ctx context.Context, item store.MonitorNotificationDelivery) {
-	if e == nil || e.store == nil {
+	if e == nil {
+		return
+	}
+	e.countDelivery(item.EventType, item.Status)
+	if e.store == nil {
 		return
 	}
 	if _, err := e.store.AddNotificationDelivery(ctx, &item); err != nil && e.logger != nil {
@@ -360,6 +376,10 @@ func buildNotificationMessage(kind, lang string, m store.Monitor, result CheckRe
 		title = notifyText(lang, "monitoring.notify.upTitle")
 	case "tls_expiring":
 		title = notifyText(lang, "monitoring.notify.tlsTitle")
+	case "tls_grade_dropped":
+		title = notifyText(lang, "monitoring.notify.tlsGradeTitle")
+	case "headers_regressed":
+		title = notifyText(lang, "monitoring.notify.headersTitle")
 	case "maintenance_start":
 		title = notifyText(lang, "monitoring.notify.maintenanceStartTitle")
 	case "maintenance_end":
@@ -375,6 +395,22 @@ func buildNotificationMessage(kind, lang string, m store.Monitor, result CheckRe
 		if reason := notifyErrorText(lang, result.Error); reason != "" {
 			lines = append(lines, fmt.Sprintf("%s: %s", notifyText(lang, "monitoring.notify.error"), reason))
 		}
+		if tx := transactionDetails(result); tx != nil && tx.FailedStep != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s (%d/%d)", notifyText(lang, "monitoring.notify.failedStep"), tx.FailedStep, len(tx.Steps), transactionStepCount(m)))
+		}
+	}
+	if kind == "tls_grade_dropped" && result.TLS != nil {
+		lines = append(lines, fmt.Sprintf("%s: %s", notifyText(lang, "monitoring.notify.grade"), result.TLS.Grade))
+		if sec := result.TLS.Security; sec != nil && len(sec.Issues) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", notifyText(lang, "monitoring.notify.tlsIssues"), strings.Join(sec.Issues, ", ")))
+		}
+	}
+	if kind == "headers_regressed" && result.Details != nil && result.Details.Headers != nil {
+		headers := result.Details.Headers
+		lines = append(lines, fmt.Sprintf("%s: %d", notifyText(lang, "monitoring.notify.headersScore"), headers.Score))
+		if len(headers.Violations) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", notifyText(lang, "monitoring.notify.headersViolations"), strings.Join(headers.Violations, ", ")))
+		}
 	}
 	if kind == "tls_expiring" && tlsRecord != nil {
 		lines = append(lines, fmt.Sprintf("%s: %s", notifyText(lang, "monitoring.notify.expires"), formatNotifyTime(tlsRecord.NotAfter)))
@@ -382,6 +418,13 @@ func buildNotificationMessage(kind, lang string, m store.Monitor, result CheckRe
 		lines = append(lines, fmt.Sprintf("%s: %d", notifyText(lang, "monitoring.notify.daysLeft"), days))
 	} else if result.LatencyMs > 0 {
 		lines = append(lines, fmt.Sprintf("%s: %d ms", notifyText(lang, "monitoring.notify.latency"), result.LatencyMs))
+		if tx := transactionDetails(result); tx != nil && len(tx.Steps) > 0 {
+			steps := make([]string, 0, len(tx.Steps))
+			for _, step := range tx.Steps {
+				steps = append(steps, fmt.Sprintf("%s %d ms", step.Name, step.LatencyMs))
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s", notifyText(lang, "monitoring.notify.steps"), strings.Join(steps, ", ")))
+		}
 	}
 	lines = append(lines, fmt.Sprintf("%s: %s", notifyText(lang, "monitoring.notify.time"), formatNotifyTime(now)))
 	lines = append(lines, "")
@@ -389,6 +432,20 @@ func buildNotificationMessage(kind, lang string, m store.Monitor, result CheckRe
 	return TelegramMessage{Text: strings.Join(lines, "\n")}
 }
 
+func transactionDetails(result CheckResult) *store.TransactionDetails {
+	if result.Details == nil {
+		return nil
+	}
+	return result.Details.Transaction
+}
+
+func transactionStepCount(m store.Monitor) int {
+	if m.Options.Transaction == nil {
+		return 0
+	}
+	return len(m.Options.Transaction.Steps)
+}
+
 func notifyErrorText(lang, raw string) string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
@@ -400,6 +457,12 @@ func notifyErrorText(lang, raw string) string {
 			return fmt.Sprintf("%s %d", notifyText(lang, "monitoring.notify.httpStatus"), parsed)
 		}
 	}
+	// Some checks append details to the key, e.g. the failed JSON assertions.
+	if key, detail, found := strings.Cut(trimmed, ": "); found && strings.HasPrefix(key, "monitoring.error.") {
+		if text := notifyErrorText(lang, key); text != key {
+			return text + ": " + detail
+		}
+	}
 	switch trimmed {
 	case "monitoring.error.invalidUrl",
 		"monitoring.error.privateBlocked",
@@ -412,6 +475,72 @@ func notifyErrorText(lang, raw string) string {
 		"monitoring.error.dnsNoAnswer",
 		"monitoring.error.paused",
 		"monitoring.error.engineDisabled",
+		"monitoring.error.pushMissed",
+		"monitoring.error.pushReportedDown",
+		"monitoring.error.pingNoReply",
+		"monitoring.error.pingLossHigh",
+		"monitoring.error.pingRttHigh",
+		"monitoring.error.icmpUnavailable",
+		"monitoring.error.protocolError",
+		"monitoring.error.authFailed",
+		"monitoring.error.queryFailed",
+		"monitoring.error.queryResultMismatch",
+		"monitoring.error.tlsRequired",
+		"monitoring.error.credentialsRequired",
+		"monitoring.error.credentialsUnavailable",
+		"monitoring.error.unexpectedRole",
+		"monitoring.error.replicationLinkDown",
+		"monitoring.error.radiusBadAuthenticator",
+		"monitoring.error.radiusUnexpectedAccept",
+		"monitoring.error.radiusChallenge",
+		"monitoring.error.mqttRefused",
+		"monitoring.error.mqttSubscribeFailed",
+		"monitoring.error.mqttNoMessage",
+		"monitoring.error.kafkaLeaderUnavailable",
+		"monitoring.error.kafkaTopicUnavailable",
+		"monitoring.error.kafkaProduceFailed",
+		"monitoring.error.kafkaSaslUnsupported",
+		"monitoring.error.containerNotFound",
+		"monitoring.error.containerNotRunning",
+		"monitoring.error.containerUnhealthy",
+		"monitoring.error.containerStarting",
+		"monitoring.error.containerRestarted",
+		"monitoring.error.invalidTLSMaterial",
+		"monitoring.error.grpcNotServing",
+		"monitoring.error.grpcServiceUnknown",
+		"monitoring.error.grpcCallFailed",
+		"monitoring.error.grpcReflectionUnavailable",
+		"monitoring.error.grpcMethodNotFound",
+		"monitoring.error.grpcInvalidRequest",
+		"monitoring.error.gamePlayersLow",
+		"monitoring.error.dnsServerFailure",
+		"monitoring.error.dnsUnexpectedAnswer",
+		"monitoring.error.dnsDrift",
+		"monitoring.error.jsonAssertionFailed",
+		"monitoring.error.oauthTokenFailed",
+		"monitoring.error.transactionStepsRequired",
+		"monitoring.error.transactionExtractFailed",
+		"monitoring.error.mailRejected",
+		"monitoring.error.sshHostKeyChanged",
+		"monitoring.error.sshWeakAlgorithms",
+		"monitoring.error.sshCommandFailed",
+		"monitoring.error.sshKeyRequired",
+		"monitoring.error.invalidSSHKey",
+		"monitoring.error.ldapError",
+		"monitoring.error.ldapTooFewEntries",
+		"monitoring.error.tlsChainInvalid",
+		"monitoring.error.tlsLegacyProtocol",
+		"monitoring.error.tlsGradeBelow",
+		"monitoring.error.headersScoreBelow",
+		"monitoring.error.probeQuorum",
+		"monitoring.error.probePartial",
+		"monitoring.error.probesStale",
+		"monitoring.error.probesUnavailable",
+		"monitoring.error.invalidPrometheusOptions",
+		"monitoring.error.invalidPrometheusResponse",
+		"monitoring.error.prometheusNoSeries",
+		"monitoring.error.prometheusAmbiguous",
+		"monitoring.error.prometheusThreshold",
 		"monitoring.error.busy":
 		return notifyText(lang, trimmed)
 	default:
@@ -437,60 +566,208 @@ func monitorTarget(m store.Monitor) string {
 func notifyText(lang, key string) string {
 	lang = strings.ToLower(strings.TrimSpace(lang))
 	ru := map[string]string{
-		"monitoring.notify.downTitle":             "\U0001f6a8 \u041c\u043e\u043d\u0438\u0442\u043e\u0440 \u043d\u0435\u0434\u043e\u0441\u0442\u0443\u043f\u0435\u043d",
-		"monitoring.notify.upTitle":               "\u2705 \u041c\u043e\u043d\u0438\u0442\u043e\u0440 \u0432\u043e\u0441\u0441\u0442\u0430\u043d\u043e\u0432\u043b\u0435\u043d",
-		"monitoring.notify.tlsTitle":              "\u26a0\ufe0f \u0418\u0441\u0442\u0435\u043a\u0430\u0435\u0442 \u0441\u0435\u0440\u0442\u0438\u0444\u0438\u043a\u0430\u0442",
-		"monitoring.notify.maintenanceStartTitle": "\U0001f6e0\ufe0f \u041d\u0430\u0447\u0430\u043b\u043e \u043e\u0431\u0441\u043b\u0443\u0436\u0438\u0432\u0430\u043d\u0438\u044f",
-		"monitoring.notify.maintenanceEndTitle":   "\u2705 \u041e\u0431\u0441\u043b\u0443\u0436\u0438\u0432\u0430\u043d\u0438\u0435 \u0437\u0430\u0432\u0435\u0440\u0448\u0435\u043d\u043e",
-		"monitoring.notify.repeatDown":            "\u26a0\ufe0f \u043f\u043e\u0432\u0442\u043e\u0440\u043d\u043e\u0435 \u043f\u0430\u0434\u0435\u043d\u0438\u0435",
-		"monitoring.notify.testTitle":             "\u2705 \u0422\u0435\u0441\u0442\u043e\u0432\u043e\u0435 \u0443\u0432\u0435\u0434\u043e\u043c\u043b\u0435\u043d\u0438\u0435",
-		"monitoring.notify.latency":               "\u0417\u0430\u0434\u0435\u0440\u0436\u043a\u0430",
-		"monitoring.notify.time":                  "\u0412\u0440\u0435\u043c\u044f",
-		"monitoring.notify.error":                 "\u041e\u0448\u0438\u0431\u043a\u0430",
-		"monitoring.notify.httpStatus":            "HTTP \u0441\u0442\u0430\u0442\u0443\u0441",
-		"monitoring.notify.expires":               "\u0418\u0441\u0442\u0435\u043a\u0430\u0435\u0442",
-		"monitoring.notify.daysLeft":              "\u0414\u043d\u0435\u0439 \u043e\u0441\u0442\u0430\u043b\u043e\u0441\u044c",
-		"monitoring.error.invalidUrl":             "\u041d\u0435\u043a\u043e\u0440\u0440\u0435\u043a\u0442\u043d\u044b\u0439 URL",
-		"monitoring.error.privateBlocked":         "\u041f\u0440\u0438\u0432\u0430\u0442\u043d\u044b\u0435 \u0441\u0435\u0442\u0438 \u0437\u0430\u043f\u0440\u0435\u0449\u0435\u043d\u044b",
-		"monitoring.error.tlsHandshakeFailed":     "\u041e\u0448\u0438\u0431\u043a\u0430 TLS \u0440\u0443\u043a\u043e\u043f\u043e\u0436\u0430\u0442\u0438\u044f",
-		"monitoring.error.timeout":                "\u041f\u0440\u0435\u0432\u044b\u0448\u0435\u043d \u0442\u0430\u0439\u043c\u0430\u0443\u0442",
-		"monitoring.error.requestFailed":          "\u0417\u0430\u043f\u0440\u043e\u0441 \u0437\u0430\u0432\u0435\u0440\u0448\u0438\u043b\u0441\u044f \u043e\u0448\u0438\u0431\u043a\u043e\u0439",
-		"monitoring.error.invalidJsonResponse":    "\u041e\u0442\u0432\u0435\u0442 \u043d\u0435 \u044f\u0432\u043b\u044f\u0435\u0442\u0441\u044f \u0432\u0430\u043b\u0438\u0434\u043d\u044b\u043c JSON",
-		"monitoring.error.keywordRequired":        "\u0423\u043a\u0430\u0436\u0438\u0442\u0435 \u043e\u0436\u0438\u0434\u0430\u0435\u043c\u043e\u0435 \u0441\u043b\u043e\u0432\u043e",
-		"monitoring.error.keywordNotFound":        "\u041e\u0436\u0438\u0434\u0430\u0435\u043c\u043e\u0435 \u0441\u043b\u043e\u0432\u043e \u043d\u0435 \u043d\u0430\u0439\u0434\u0435\u043d\u043e \u0432 \u043e\u0442\u0432\u0435\u0442\u0435",
-		"monitoring.error.dnsNoAnswer":            "DNS-\u043e\u0442\u0432\u0435\u0442 \u043d\u0435 \u0441\u043e\u0432\u043f\u0430\u0434\u0430\u0435\u0442 \u0441 \u043e\u0436\u0438\u0434\u0430\u043d\u0438\u0435\u043c",
-		"monitoring.error.paused":                 "\u041c\u043e\u043d\u0438\u0442\u043e\u0440 \u043d\u0430 \u043f\u0430\u0443\u0437\u0435",
-		"monitoring.error.engineDisabled":         "\u0414\u0432\u0438\u0436\u043e\u043a \u043c\u043e\u043d\u0438\u0442\u043e\u0440\u0438\u043d\u0433\u0430 \u043e\u0442\u043a\u043b\u044e\u0447\u0435\u043d",
-		"monitoring.error.busy":                   "\u041d\u0435\u0442 \u0441\u0432\u043e\u0431\u043e\u0434\u043d\u044b\u0445 \u0432\u043e\u0440\u043a\u0435\u0440\u043e\u0432 \u043f\u0440\u043e\u0432\u0435\u0440\u043a\u0438",
-		"monitoring.notify.footer":                "Berkut SCC",
+		"monitoring.notify.downTitle":                "\U0001f6a8 \u041c\u043e\u043d\u0438\u0442\u043e\u0440 \u043d\u0435\u0434\u043e\u0441\u0442\u0443\u043f\u0435\u043d",
+		"monitoring.notify.upTitle":                  "\u2705 \u041c\u043e\u043d\u0438\u0442\u043e\u0440 \u0432\u043e\u0441\u0441\u0442\u0430\u043d\u043e\u0432\u043b\u0435\u043d",
+		"monitoring.notify.tlsTitle":                 "\u26a0\ufe0f \u0418\u0441\u0442\u0435\u043a\u0430\u0435\u0442 \u0441\u0435\u0440\u0442\u0438\u0444\u0438\u043a\u0430\u0442",
+		"monitoring.notify.maintenanceStartTitle":    "\U0001f6e0\ufe0f \u041d\u0430\u0447\u0430\u043b\u043e \u043e\u0431\u0441\u043b\u0443\u0436\u0438\u0432\u0430\u043d\u0438\u044f",
+		"monitoring.notify.maintenanceEndTitle":      "\u2705 \u041e\u0431\u0441\u043b\u0443\u0436\u0438\u0432\u0430\u043d\u0438\u0435 \u0437\u0430\u0432\u0435\u0440\u0448\u0435\u043d\u043e",
+		"monitoring.notify.repeatDown":               "\u26a0\ufe0f \u043f\u043e\u0432\u0442\u043e\u0440\u043d\u043e\u0435 \u043f\u0430\u0434\u0435\u043d\u0438\u0435",
+		"monitoring.notify.testTitle":                "\u2705 \u0422\u0435\u0441\u0442\u043e\u0432\u043e\u0435 \u0443\u0432\u0435\u0434\u043e\u043c\u043b\u0435\u043d\u0438\u0435",
+		"monitoring.notify.latency":                  "\u0417\u0430\u0434\u0435\u0440\u0436\u043a\u0430",
+		"monitoring.notify.time":                     "\u0412\u0440\u0435\u043c\u044f",
+		"monitoring.notify.error":                    "\u041e\u0448\u0438\u0431\u043a\u0430",
+		"monitoring.notify.httpStatus":               "HTTP \u0441\u0442\u0430\u0442\u0443\u0441",
+		"monitoring.notify.expires":                  "\u0418\u0441\u0442\u0435\u043a\u0430\u0435\u0442",
+		"monitoring.notify.daysLeft":                 "\u0414\u043d\u0435\u0439 \u043e\u0441\u0442\u0430\u043b\u043e\u0441\u044c",
+		"monitoring.error.invalidUrl":                "\u041d\u0435\u043a\u043e\u0440\u0440\u0435\u043a\u0442\u043d\u044b\u0439 URL",
+		"monitoring.error.privateBlocked":            "\u041f\u0440\u0438\u0432\u0430\u0442\u043d\u044b\u0435 \u0441\u0435\u0442\u0438 \u0437\u0430\u043f\u0440\u0435\u0449\u0435\u043d\u044b",
+		"monitoring.error.tlsHandshakeFailed":        "\u041e\u0448\u0438\u0431\u043a\u0430 TLS \u0440\u0443\u043a\u043e\u043f\u043e\u0436\u0430\u0442\u0438\u044f",
+		"monitoring.error.timeout":                   "\u041f\u0440\u0435\u0432\u044b\u0448\u0435\u043d \u0442\u0430\u0439\u043c\u0430\u0443\u0442",
+		"monitoring.error.requestFailed":             "\u0417\u0430\u043f\u0440\u043e\u0441 \u0437\u0430\u0432\u0435\u0440\u0448\u0438\u043b\u0441\u044f \u043e\u0448\u0438\u0431\u043a\u043e\u0439",
+		"monitoring.error.invalidJsonResponse":       "\u041e\u0442\u0432\u0435\u0442 \u043d\u0435 \u044f\u0432\u043b\u044f\u0435\u0442\u0441\u044f \u0432\u0430\u043b\u0438\u0434\u043d\u044b\u043c JSON",
+		"monitoring.error.keywordRequired":           "\u0423\u043a\u0430\u0436\u0438\u0442\u0435 \u043e\u0436\u0438\u0434\u0430\u0435\u043c\u043e\u0435 \u0441\u043b\u043e\u0432\u043e",
+		"monitoring.error.keywordNotFound":           "\u041e\u0436\u0438\u0434\u0430\u0435\u043c\u043e\u0435 \u0441\u043b\u043e\u0432\u043e \u043d\u0435 \u043d\u0430\u0439\u0434\u0435\u043d\u043e \u0432 \u043e\u0442\u0432\u0435\u0442\u0435",
+		"monitoring.error.dnsNoAnswer":               "DNS-\u043e\u0442\u0432\u0435\u0442 \u043d\u0435 \u0441\u043e\u0432\u043f\u0430\u0434\u0430\u0435\u0442 \u0441 \u043e\u0436\u0438\u0434\u0430\u043d\u0438\u0435\u043c",
+		"monitoring.error.paused":                    "\u041c\u043e\u043d\u0438\u0442\u043e\u0440 \u043d\u0430 \u043f\u0430\u0443\u0437\u0435",
+		"monitoring.error.engineDisabled":            "\u0414\u0432\u0438\u0436\u043e\u043a \u043c\u043e\u043d\u0438\u0442\u043e\u0440\u0438\u043d\u0433\u0430 \u043e\u0442\u043a\u043b\u044e\u0447\u0435\u043d",
+		"monitoring.error.busy":                      "\u041d\u0435\u0442 \u0441\u0432\u043e\u0431\u043e\u0434\u043d\u044b\u0445 \u0432\u043e\u0440\u043a\u0435\u0440\u043e\u0432 \u043f\u0440\u043e\u0432\u0435\u0440\u043a\u0438",
+		"monitoring.error.pushMissed":                "Heartbeat \u043d\u0435 \u043f\u043e\u043b\u0443\u0447\u0435\u043d \u0432 \u043e\u0436\u0438\u0434\u0430\u0435\u043c\u044b\u0439 \u0438\u043d\u0442\u0435\u0440\u0432\u0430\u043b",
+		"monitoring.error.pushReportedDown":          "\u041e\u0442\u043f\u0440\u0430\u0432\u0438\u0442\u0435\u043b\u044c \u0441\u043e\u043e\u0431\u0449\u0438\u043b \u043e \u0441\u0431\u043e\u0435",
+		"monitoring.error.pingNoReply":               "\u041d\u0435\u0442 \u043e\u0442\u0432\u0435\u0442\u043e\u0432 \u043d\u0430 ICMP echo",
+		"monitoring.error.pingLossHigh":              "\u041f\u043e\u0442\u0435\u0440\u0438 ICMP-\u043f\u0430\u043a\u0435\u0442\u043e\u0432 \u043f\u0440\u0435\u0432\u044b\u0448\u0430\u044e\u0442 \u043f\u043e\u0440\u043e\u0433",
+		"monitoring.error.pingRttHigh":               "\u0412\u0440\u0435\u043c\u044f \u043e\u0442\u043a\u043b\u0438\u043a\u0430 ICMP \u043f\u0440\u0435\u0432\u044b\u0448\u0430\u0435\u0442 \u043f\u043e\u0440\u043e\u0433",
+		"monitoring.error.icmpUnavailable":           "ICMP-\u0441\u043e\u043a\u0435\u0442\u044b \u043d\u0435\u0434\u043e\u0441\u0442\u0443\u043f\u043d\u044b (\u043f\u0440\u043e\u0432\u0435\u0440\u044c\u0442\u0435 ping_group_range \u0438\u043b\u0438 CAP_NET_RAW)",
+		"monitoring.error.protocolError":             "\u041d\u0435\u043e\u0436\u0438\u0434\u0430\u043d\u043d\u044b\u0439 \u043e\u0442\u0432\u0435\u0442 \u043f\u0440\u043e\u0442\u043e\u043a\u043e\u043b\u0430",
+		"monitoring.error.authFailed":                "\u041e\u0448\u0438\u0431\u043a\u0430 \u0430\u0443\u0442\u0435\u043d\u0442\u0438\u0444\u0438\u043a\u0430\u0446\u0438\u0438",
+		"monitoring.error.queryFailed":               "\u041e\u0448\u0438\u0431\u043a\u0430 \u0432\u044b\u043f\u043e\u043b\u043d\u0435\u043d\u0438\u044f \u0437\u0430\u043f\u0440\u043e\u0441\u0430",
+		"monitoring.error.queryResultMismatch":       "\u0420\u0435\u0437\u0443\u043b\u044c\u0442\u0430\u0442 \u0437\u0430\u043f\u0440\u043e\u0441\u0430 \u043d\u0435 \u0441\u043e\u0432\u043f\u0430\u0434\u0430\u0435\u0442 \u0441 \u043e\u0436\u0438\u0434\u0430\u0435\u043c\u044b\u043c",
+		"monitoring.error.tlsRequired":               "\u0421\u0435\u0440\u0432\u0435\u0440 \u043d\u0435 \u043f\u043e\u0434\u0434\u0435\u0440\u0436\u0438\u0432\u0430\u0435\u0442 TLS",
+		"monitoring.error.credentialsRequired":       "\u0414\u043b\u044f \u043f\u0440\u043e\u0432\u0435\u0440\u043a\u0438 \u0437\u0430\u043f\u0440\u043e\u0441\u043e\u043c \u043d\u0443\u0436\u043d\u044b \u0443\u0447\u0451\u0442\u043d\u044b\u0435 \u0434\u0430\u043d\u043d\u044b\u0435",
+		"monitoring.error.credentialsUnavailable":    "\u041d\u0435 \u0443\u0434\u0430\u043b\u043e\u0441\u044c \u0440\u0430\u0441\u0448\u0438\u0444\u0440\u043e\u0432\u0430\u0442\u044c \u0441\u043e\u0445\u0440\u0430\u043d\u0451\u043d\u043d\u044b\u0435 \u0443\u0447\u0451\u0442\u043d\u044b\u0435 \u0434\u0430\u043d\u043d\u044b\u0435",
+		"monitoring.error.unexpectedRole":            "\u0423\u0437\u0435\u043b \u043d\u0430\u0445\u043e\u0434\u0438\u0442\u0441\u044f \u0432 \u043d\u0435\u043e\u0436\u0438\u0434\u0430\u043d\u043d\u043e\u0439 \u0440\u043e\u043b\u0438 \u0440\u0435\u043f\u043b\u0438\u043a\u0430\u0446\u0438\u0438",
+		"monitoring.error.replicationLinkDown":       "\u0420\u0435\u043f\u043b\u0438\u043a\u0430 \u043f\u043e\u0442\u0435\u0440\u044f\u043b\u0430 \u0441\u0432\u044f\u0437\u044c \u0441 \u043c\u0430\u0441\u0442\u0435\u0440\u043e\u043c",
+		"monitoring.error.radiusBadAuthenticator":    "\u041d\u0435\u0432\u0435\u0440\u043d\u044b\u0439 \u0430\u0443\u0442\u0435\u043d\u0442\u0438\u0444\u0438\u043a\u0430\u0442\u043e\u0440 \u043e\u0442\u0432\u0435\u0442\u0430 RADIUS (\u043f\u0440\u043e\u0432\u0435\u0440\u044c\u0442\u0435 \u043e\u0431\u0449\u0438\u0439 \u0441\u0435\u043a\u0440\u0435\u0442)",
+		"monitoring.error.radiusUnexpectedAccept":    "RADIUS \u0441\u0435\u0440\u0432\u0435\u0440 \u043f\u0440\u0438\u043d\u044f\u043b \u0437\u0430\u043f\u0440\u043e\u0441, \u043a\u043e\u0442\u043e\u0440\u044b\u0439 \u0434\u043e\u043b\u0436\u0435\u043d \u0431\u044b\u043b \u0431\u044b\u0442\u044c \u043e\u0442\u043a\u043b\u043e\u043d\u0451\u043d",
+		"monitoring.error.radiusChallenge":           "RADIUS \u0441\u0435\u0440\u0432\u0435\u0440 \u0437\u0430\u043f\u0440\u043e\u0441\u0438\u043b \u0434\u043e\u043f\u043e\u043b\u043d\u0438\u0442\u0435\u043b\u044c\u043d\u044b\u0439 challenge",
+		"monitoring.error.mqttRefused":               "MQTT \u0431\u0440\u043e\u043a\u0435\u0440 \u043e\u0442\u043a\u043b\u043e\u043d\u0438\u043b \u043f\u043e\u0434\u043a\u043b\u044e\u0447\u0435\u043d\u0438\u0435",
+		"monitoring.error.mqttSubscribeFailed":       "MQTT \u0431\u0440\u043e\u043a\u0435\u0440 \u043e\u0442\u043a\u043b\u043e\u043d\u0438\u043b \u0442\u0435\u0441\u0442\u043e\u0432\u0443\u044e \u043f\u043e\u0434\u043f\u0438\u0441\u043a\u0443",
+		"monitoring.error.mqttNoMessage":             "MQTT \u0431\u0440\u043e\u043a\u0435\u0440 \u043d\u0435 \u0434\u043e\u0441\u0442\u0430\u0432\u0438\u043b \u0442\u0435\u0441\u0442\u043e\u0432\u043e\u0435 \u0441\u043e\u043e\u0431\u0449\u0435\u043d\u0438\u0435",
+		"monitoring.error.kafkaLeaderUnavailable":    "\u041b\u0438\u0434\u0435\u0440 \u043f\u0430\u0440\u0442\u0438\u0446\u0438\u0438 Kafka \u043d\u0435\u0434\u043e\u0441\u0442\u0443\u043f\u0435\u043d",
+		"monitoring.error.kafkaTopicUnavailable":     "\u0422\u043e\u043f\u0438\u043a \u0438\u043b\u0438 \u043f\u0430\u0440\u0442\u0438\u0446\u0438\u044f Kafka \u043d\u0435 \u043d\u0430\u0439\u0434\u0435\u043d\u044b",
+		"monitoring.error.kafkaProduceFailed":        "Kafka \u043d\u0435 \u043f\u0440\u0438\u043d\u044f\u043b \u043a\u043e\u043d\u0442\u0440\u043e\u043b\u044c\u043d\u043e\u0435 \u0441\u043e\u043e\u0431\u0449\u0435\u043d\u0438\u0435",
+		"monitoring.error.kafkaSaslUnsupported":      "\u041c\u0435\u0445\u0430\u043d\u0438\u0437\u043c SASL \u043d\u0435 \u0432\u043a\u043b\u044e\u0447\u0451\u043d \u043d\u0430 \u0431\u0440\u043e\u043a\u0435\u0440\u0435 Kafka",
+		"monitoring.error.containerNotFound":         "\u041a\u043e\u043d\u0442\u0435\u0439\u043d\u0435\u0440 \u043d\u0435 \u043d\u0430\u0439\u0434\u0435\u043d",
+		"monitoring.error.containerNotRunning":       "\u041a\u043e\u043d\u0442\u0435\u0439\u043d\u0435\u0440 \u043d\u0435 \u0437\u0430\u043f\u0443\u0449\u0435\u043d",
+		"monitoring.error.containerUnhealthy":        "Healthcheck \u043a\u043e\u043d\u0442\u0435\u0439\u043d\u0435\u0440\u0430 \u0441\u043e\u043e\u0431\u0449\u0430\u0435\u0442 unhealthy",
+		"monitoring.error.containerStarting":         "Healthcheck \u043a\u043e\u043d\u0442\u0435\u0439\u043d\u0435\u0440\u0430 \u0435\u0449\u0451 \u0432 \u0441\u043e\u0441\u0442\u043e\u044f\u043d\u0438\u0438 starting",
+		"monitoring.error.containerRestarted":        "\u041a\u043e\u043d\u0442\u0435\u0439\u043d\u0435\u0440 \u043f\u0435\u0440\u0435\u0437\u0430\u043f\u0443\u0441\u043a\u0430\u043b\u0441\u044f \u0441 \u043c\u043e\u043c\u0435\u043d\u0442\u0430 \u043f\u0440\u0435\u0434\u044b\u0434\u0443\u0449\u0435\u0439 \u043f\u0440\u043e\u0432\u0435\u0440\u043a\u0438",
+		"monitoring.error.invalidTLSMaterial":        "\u041d\u0435\u043a\u043e\u0440\u0440\u0435\u043a\u0442\u043d\u044b\u0439 CA \u0438\u043b\u0438 \u043a\u043b\u0438\u0435\u043d\u0442\u0441\u043a\u0438\u0439 \u0441\u0435\u0440\u0442\u0438\u0444\u0438\u043a\u0430\u0442",
+		"monitoring.error.grpcNotServing":            "gRPC health check \u0441\u043e\u043e\u0431\u0449\u0430\u0435\u0442, \u0447\u0442\u043e \u0441\u0435\u0440\u0432\u0438\u0441 \u043d\u0435 \u043e\u0431\u0441\u043b\u0443\u0436\u0438\u0432\u0430\u0435\u0442 \u0437\u0430\u043f\u0440\u043e\u0441\u044b",
+		"monitoring.error.grpcServiceUnknown":        "gRPC health check \u043d\u0435 \u0437\u043d\u0430\u0435\u0442 \u0443\u043a\u0430\u0437\u0430\u043d\u043d\u044b\u0439 \u0441\u0435\u0440\u0432\u0438\u0441",
+		"monitoring.error.grpcCallFailed":            "gRPC \u0432\u044b\u0437\u043e\u0432 \u0432\u0435\u0440\u043d\u0443\u043b \u0441\u0442\u0430\u0442\u0443\u0441 \u043e\u0448\u0438\u0431\u043a\u0438",
+		"monitoring.error.grpcReflectionUnavailable": "\u041d\u0430 gRPC \u0441\u0435\u0440\u0432\u0435\u0440\u0435 \u043d\u0435 \u0432\u043a\u043b\u044e\u0447\u0451\u043d reflection",
+		"monitoring.error.grpcMethodNotFound":        "gRPC \u043c\u0435\u0442\u043e\u0434 \u043d\u0435 \u043d\u0430\u0439\u0434\u0435\u043d \u0438\u043b\u0438 \u043d\u0435 \u044f\u0432\u043b\u044f\u0435\u0442\u0441\u044f unary",
+		"monitoring.error.grpcInvalidRequest":        "gRPC \u0437\u0430\u043f\u0440\u043e\u0441 \u043d\u0435 \u0441\u043e\u043e\u0442\u0432\u0435\u0442\u0441\u0442\u0432\u0443\u0435\u0442 \u0432\u0445\u043e\u0434\u043d\u043e\u043c\u0443 \u0441\u043e\u043e\u0431\u0449\u0435\u043d\u0438\u044e \u043c\u0435\u0442\u043e\u0434\u0430",
+		"monitoring.error.gamePlayersLow":            "\u0421\u043b\u0438\u0448\u043a\u043e\u043c \u043c\u0430\u043b\u043e \u0438\u0433\u0440\u043e\u043a\u043e\u0432 \u043d\u0430 \u0438\u0433\u0440\u043e\u0432\u043e\u043c \u0441\u0435\u0440\u0432\u0435\u0440\u0435",
+		"monitoring.error.dnsServerFailure":          "DNS \u0441\u0435\u0440\u0432\u0435\u0440 \u043d\u0435 \u0441\u043c\u043e\u0433 \u043e\u0442\u0432\u0435\u0442\u0438\u0442\u044c (SERVFAIL/REFUSED)",
+		"monitoring.error.dnsUnexpectedAnswer":       "DNS \u0437\u0430\u043f\u0438\u0441\u0438 \u043e\u0442\u043b\u0438\u0447\u0430\u044e\u0442\u0441\u044f \u043e\u0442 \u043e\u0436\u0438\u0434\u0430\u0435\u043c\u043e\u0433\u043e \u043d\u0430\u0431\u043e\u0440\u0430",
+		"monitoring.error.dnsDrift":                  "NS, MX \u0438\u043b\u0438 A \u0437\u0430\u043f\u0438\u0441\u0438 \u0438\u0437\u043c\u0435\u043d\u0438\u043b\u0438\u0441\u044c \u0441 \u043f\u0440\u043e\u0448\u043b\u043e\u0439 \u043f\u0440\u043e\u0432\u0435\u0440\u043a\u0438",
+		"monitoring.error.jsonAssertionFailed":       "\u041f\u0440\u043e\u0432\u0435\u0440\u043a\u0430 JSON \u043d\u0435 \u043f\u0440\u043e\u0439\u0434\u0435\u043d\u0430",
+		"monitoring.error.oauthTokenFailed":          "\u041d\u0435 \u0443\u0434\u0430\u043b\u043e\u0441\u044c \u043f\u043e\u043b\u0443\u0447\u0438\u0442\u044c \u0442\u043e\u043a\u0435\u043d OAuth2",
+		"monitoring.error.transactionStepsRequired":  "\u0412 \u0442\u0440\u0430\u043d\u0437\u0430\u043a\u0446\u0438\u0438 \u043d\u0435\u0442 \u0448\u0430\u0433\u043e\u0432",
+		"monitoring.error.transactionExtractFailed":  "\u041f\u0435\u0440\u0435\u043c\u0435\u043d\u043d\u0430\u044f \u043d\u0435 \u043d\u0430\u0439\u0434\u0435\u043d\u0430 \u0432 \u043e\u0442\u0432\u0435\u0442\u0435",
+		"monitoring.notify.failedStep":               "\u0428\u0430\u0433 \u0441 \u043e\u0448\u0438\u0431\u043a\u043e\u0439",
+		"monitoring.notify.steps":                    "\u0428\u0430\u0433\u0438",
+		"monitoring.error.mailRejected":              "\u041f\u043e\u0447\u0442\u043e\u0432\u044b\u0439 \u0441\u0435\u0440\u0432\u0435\u0440 \u043e\u0442\u043a\u043b\u043e\u043d\u0438\u043b \u043a\u043e\u043c\u0430\u043d\u0434\u0443",
+		"monitoring.error.sshHostKeyChanged":         "\u041a\u043b\u044e\u0447 \u0445\u043e\u0441\u0442\u0430 SSH \u0438\u0437\u043c\u0435\u043d\u0438\u043b\u0441\u044f",
+		"monitoring.error.sshWeakAlgorithms":         "SSH-\u0441\u0435\u0440\u0432\u0435\u0440 \u043f\u0440\u0435\u0434\u043b\u0430\u0433\u0430\u0435\u0442 \u0441\u043b\u0430\u0431\u044b\u0435 \u0430\u043b\u0433\u043e\u0440\u0438\u0442\u043c\u044b",
+		"monitoring.error.sshCommandFailed":          "SSH-\u043a\u043e\u043c\u0430\u043d\u0434\u0430 \u0437\u0430\u0432\u0435\u0440\u0448\u0438\u043b\u0430\u0441\u044c \u0441 \u043e\u0448\u0438\u0431\u043a\u043e\u0439",
+		"monitoring.error.sshKeyRequired":            "\u0414\u043b\u044f SSH-\u043a\u043e\u043c\u0430\u043d\u0434\u044b \u043d\u0443\u0436\u0435\u043d \u0437\u0430\u043a\u0440\u044b\u0442\u044b\u0439 \u043a\u043b\u044e\u0447",
+		"monitoring.error.invalidSSHKey":             "\u041d\u0435\u043a\u043e\u0440\u0440\u0435\u043a\u0442\u043d\u044b\u0439 \u0437\u0430\u043a\u0440\u044b\u0442\u044b\u0439 \u043a\u043b\u044e\u0447 SSH",
+		"monitoring.error.ldapError":                 "LDAP-\u0441\u0435\u0440\u0432\u0435\u0440 \u0432\u0435\u0440\u043d\u0443\u043b \u043e\u0448\u0438\u0431\u043a\u0443",
+		"monitoring.error.ldapTooFewEntries":         "\u041f\u043e\u0438\u0441\u043a LDAP \u0432\u0435\u0440\u043d\u0443\u043b \u0441\u043b\u0438\u0448\u043a\u043e\u043c \u043c\u0430\u043b\u043e \u0437\u0430\u043f\u0438\u0441\u0435\u0439",
+		"monitoring.notify.tlsGradeTitle":            "\u26a0\ufe0f \u041e\u0446\u0435\u043d\u043a\u0430 TLS \u0441\u043d\u0438\u0437\u0438\u043b\u0430\u0441\u044c",
+		"monitoring.notify.grade":                    "\u041e\u0446\u0435\u043d\u043a\u0430",
+		"monitoring.notify.tlsIssues":                "\u041f\u0440\u043e\u0431\u043b\u0435\u043c\u044b",
+		"monitoring.error.tlsChainInvalid":           "\u0426\u0435\u043f\u043e\u0447\u043a\u0430 \u0441\u0435\u0440\u0442\u0438\u0444\u0438\u043a\u0430\u0442\u043e\u0432 \u043d\u0435\u0434\u0435\u0439\u0441\u0442\u0432\u0438\u0442\u0435\u043b\u044c\u043d\u0430",
+		"monitoring.error.tlsLegacyProtocol":         "\u0412\u043a\u043b\u044e\u0447\u0451\u043d \u0443\u0441\u0442\u0430\u0440\u0435\u0432\u0448\u0438\u0439 \u043f\u0440\u043e\u0442\u043e\u043a\u043e\u043b TLS",
+		"monitoring.error.tlsGradeBelow":             "\u041e\u0446\u0435\u043d\u043a\u0430 TLS \u043d\u0438\u0436\u0435 \u043c\u0438\u043d\u0438\u043c\u0430\u043b\u044c\u043d\u043e\u0439",
+		"monitoring.notify.headersTitle":             "\u26a0\ufe0f \u041f\u043e\u043b\u0438\u0442\u0438\u043a\u0430 \u0437\u0430\u0433\u043e\u043b\u043e\u0432\u043a\u043e\u0432 \u0431\u0435\u0437\u043e\u043f\u0430\u0441\u043d\u043e\u0441\u0442\u0438 \u043d\u0430\u0440\u0443\u0448\u0435\u043d\u0430",
+		"monitoring.notify.headersScore":             "\u041e\u0446\u0435\u043d\u043a\u0430",
+		"monitoring.notify.headersViolations":        "\u041d\u0430\u0440\u0443\u0448\u0435\u043d\u0438\u044f",
+		"monitoring.error.headersScoreBelow":         "\u041e\u0446\u0435\u043d\u043a\u0430 \u0437\u0430\u0433\u043e\u043b\u043e\u0432\u043a\u043e\u0432 \u0431\u0435\u0437\u043e\u043f\u0430\u0441\u043d\u043e\u0441\u0442\u0438 \u043d\u0438\u0436\u0435 \u043c\u0438\u043d\u0438\u043c\u0430\u043b\u044c\u043d\u043e\u0439",
+		"monitoring.error.probeQuorum":               "\u0421\u0431\u043e\u0439 \u043d\u0430 \u043a\u0432\u043e\u0440\u0443\u043c\u0435 \u043f\u0440\u043e\u0431",
+		"monitoring.error.probePartial":              "\u0427\u0430\u0441\u0442\u044c \u043b\u043e\u043a\u0430\u0446\u0438\u0439 \u043f\u0440\u043e\u0431 \u043d\u0435\u0434\u043e\u0441\u0442\u0443\u043f\u043d\u0430",
+		"monitoring.error.probesStale":               "\u0427\u0430\u0441\u0442\u044c \u043b\u043e\u043a\u0430\u0446\u0438\u0439 \u043f\u0440\u043e\u0431 \u043d\u0435 \u043f\u0440\u0438\u0441\u044b\u043b\u0430\u0435\u0442 \u0440\u0435\u0437\u0443\u043b\u044c\u0442\u0430\u0442\u044b",
+		"monitoring.error.probesUnavailable":         "\u041d\u0435\u0442 \u0441\u0432\u0435\u0436\u0438\u0445 \u0440\u0435\u0437\u0443\u043b\u044c\u0442\u0430\u0442\u043e\u0432 \u043e\u0442 \u043f\u0440\u043e\u0431",
+		"monitoring.error.invalidPrometheusOptions":  "\u041d\u0435\u043a\u043e\u0440\u0440\u0435\u043a\u0442\u043d\u043e\u0435 \u0432\u044b\u0440\u0430\u0436\u0435\u043d\u0438\u0435 Prometheus",
+		"monitoring.error.invalidPrometheusResponse": "\u041d\u0435 \u0443\u0434\u0430\u043b\u043e\u0441\u044c \u0440\u0430\u0437\u043e\u0431\u0440\u0430\u0442\u044c \u043e\u0442\u0432\u0435\u0442 Prometheus",
+		"monitoring.error.prometheusNoSeries":        "\u041d\u0438 \u043e\u0434\u0438\u043d \u0440\u044f\u0434 \u043d\u0435 \u043f\u043e\u0434\u0445\u043e\u0434\u0438\u0442 \u043f\u043e\u0434 \u0432\u044b\u0440\u0430\u0436\u0435\u043d\u0438\u0435",
+		"monitoring.error.prometheusAmbiguous":       "\u041f\u043e\u0434 \u0432\u044b\u0440\u0430\u0436\u0435\u043d\u0438\u0435 \u043f\u043e\u0434\u0445\u043e\u0434\u0438\u0442 \u043d\u0435\u0441\u043a\u043e\u043b\u044c\u043a\u043e \u0440\u044f\u0434\u043e\u0432",
+		"monitoring.error.prometheusThreshold":       "\u0414\u043e\u0441\u0442\u0438\u0433\u043d\u0443\u0442 \u043f\u043e\u0440\u043e\u0433 \u043c\u0435\u0442\u0440\u0438\u043a\u0438",
+		"monitoring.notify.footer":                   "Berkut SCC",
 	}
 	en := map[string]string{
-		"monitoring.notify.downTitle":             "\U0001f6a8 Monitor down",
-		"monitoring.notify.upTitle":               "\u2705 Monitor recovered",
-		"monitoring.notify.tlsTitle":              "\u26a0\ufe0f TLS certificate expiring",
-		"monitoring.notify.maintenanceStartTitle": "\U0001f6e0\ufe0f Maintenance started",
-		"monitoring.notify.maintenanceEndTitle":   "\u2705 Maintenance ended",
-		"monitoring.notify.repeatDown":            "\u26a0\ufe0f repeated outage",
-		"monitoring.notify.testTitle":             "\u2705 Test notification",
-		"monitoring.notify.latency":               "Latency",
-		"monitoring.notify.time":                  "Time",
-		"monitoring.notify.error":                 "Error",
-		"monitoring.notify.httpStatus":            "HTTP status",
-		"monitoring.notify.expires":               "Expires",
-		"monitoring.notify.daysLeft":              "Days left",
-		"monitoring.error.invalidUrl":             "Invalid URL",
-		"monitoring.error.privateBlocked":         "Private networks are blocked",
-		"monitoring.error.tlsHandshakeFailed":     "TLS handshake failed",
-		"monitoring.error.timeout":                "Timeout exceeded",
-		"monitoring.error.requestFailed":          "Request failed",
-		"monitoring.error.invalidJsonResponse":    "Response is not a valid JSON",
-		"monitoring.error.keywordRequired":        "Expected word is required",
-		"monitoring.error.keywordNotFound":        "Expected word was not found in response",
-		"monitoring.error.dnsNoAnswer":            "DNS answer does not match expectation",
-		"monitoring.error.paused":                 "Monitor is paused",
-		"monitoring.error.engineDisabled":         "Monitoring engine is disabled",
-		"monitoring.error.busy":                   "No available workers for check",
-		"monitoring.notify.footer":                "Berkut SCC",
+		"monitoring.notify.downTitle":                "\U0001f6a8 Monitor down",
+		"monitoring.notify.upTitle":                  "\u2705 Monitor recovered",
+		"monitoring.notify.tlsTitle":                 "\u26a0\ufe0f TLS certificate expiring",
+		"monitoring.notify.maintenanceStartTitle":    "\U0001f6e0\ufe0f Maintenance started",
+		"monitoring.notify.maintenanceEndTitle":      "\u2705 Maintenance ended",
+		"monitoring.notify.repeatDown":               "\u26a0\ufe0f repeated outage",
+		"monitoring.notify.testTitle":                "\u2705 Test notification",
+		"monitoring.notify.latency":                  "Latency",
+		"monitoring.notify.time":                     "Time",
+		"monitoring.notify.error":                    "Error",
+		"monitoring.notify.httpStatus":               "HTTP status",
+		"monitoring.notify.expires":                  "Expires",
+		"monitoring.notify.daysLeft":                 "Days left",
+		"monitoring.error.invalidUrl":                "Invalid URL",
+		"monitoring.error.privateBlocked":            "Private networks are blocked",
+		"monitoring.error.tlsHandshakeFailed":        "TLS handshake failed",
+		"monitoring.error.timeout":                   "Timeout exceeded",
+		"monitoring.error.requestFailed":             "Request failed",
+		"monitoring.error.invalidJsonResponse":       "Response is not a valid JSON",
+		"monitoring.error.keywordRequired":           "Expected word is required",
+		"monitoring.error.keywordNotFound":           "Expected word was not found in response",
+		"monitoring.error.dnsNoAnswer":               "DNS answer does not match expectation",
+		"monitoring.error.paused":                    "Monitor is paused",
+		"monitoring.error.engineDisabled":            "Monitoring engine is disabled",
+		"monitoring.error.busy":                      "No available workers for check",
+		"monitoring.error.pushMissed":                "No heartbeat received within the expected interval",
+		"monitoring.error.pushReportedDown":          "Sender reported a failure",
+		"monitoring.error.pingNoReply":               "No ICMP echo replies received",
+		"monitoring.error.pingLossHigh":              "ICMP packet loss exceeds the threshold",
+		"monitoring.error.pingRttHigh":               "ICMP round-trip time exceeds the threshold",
+		"monitoring.error.icmpUnavailable":           "ICMP sockets are unavailable (check ping_group_range or CAP_NET_RAW)",
+		"monitoring.error.protocolError":             "Unexpected protocol response",
+		"monitoring.error.authFailed":                "Authentication failed",
+		"monitoring.error.queryFailed":               "Query failed",
+		"monitoring.error.queryResultMismatch":       "Query result does not match the expected value",
+		"monitoring.error.tlsRequired":               "Server does not support TLS",
+		"monitoring.error.credentialsRequired":       "Credentials are required for the query check",
+		"monitoring.error.credentialsUnavailable":    "Stored credentials could not be decrypted",
+		"monitoring.error.unexpectedRole":            "Node is in an unexpected replication role",
+		"monitoring.error.replicationLinkDown":       "Replica has lost the link to its master",
+		"monitoring.error.radiusBadAuthenticator":    "RADIUS response authenticator mismatch (check the shared secret)",
+		"monitoring.error.radiusUnexpectedAccept":    "RADIUS server accepted a request that was expected to be rejected",
+		"monitoring.error.radiusChallenge":           "RADIUS server requested an additional challenge",
+		"monitoring.error.mqttRefused":               "MQTT broker refused the connection",
+		"monitoring.error.mqttSubscribeFailed":       "MQTT broker refused the test subscription",
+		"monitoring.error.mqttNoMessage":             "Test message was not delivered back by the MQTT broker",
+		"monitoring.error.kafkaLeaderUnavailable":    "Kafka partition leader is unavailable",
+		"monitoring.error.kafkaTopicUnavailable":     "Kafka topic or partition not found",
+		"monitoring.error.kafkaProduceFailed":        "Kafka canary message was not accepted",
+		"monitoring.error.kafkaSaslUnsupported":      "SASL mechanism is not enabled on the Kafka broker",
+		"monitoring.error.containerNotFound":         "Container not found",
+		"monitoring.error.containerNotRunning":       "Container is not running",
+		"monitoring.error.containerUnhealthy":        "Container healthcheck reports unhealthy",
+		"monitoring.error.containerStarting":         "Container healthcheck is still starting",
+		"monitoring.error.containerRestarted":        "Container restarted since the previous check",
+		"monitoring.error.invalidTLSMaterial":        "Invalid CA bundle or client certificate",
+		"monitoring.error.grpcNotServing":            "gRPC health check reports the service is not serving",
+		"monitoring.error.grpcServiceUnknown":        "gRPC health check does not know the service",
+		"monitoring.error.grpcCallFailed":            "gRPC call returned an error status",
+		"monitoring.error.grpcReflectionUnavailable": "gRPC server reflection is not enabled",
+		"monitoring.error.grpcMethodNotFound":        "gRPC method not found or not unary",
+		"monitoring.error.grpcInvalidRequest":        "gRPC request does not match the method input",
+		"monitoring.error.gamePlayersLow":            "Too few players on the game server",
+		"monitoring.error.dnsServerFailure":          "DNS server failed to answer (SERVFAIL/REFUSED)",
+		"monitoring.error.dnsUnexpectedAnswer":       "DNS records differ from the expected set",
+		"monitoring.error.dnsDrift":                  "NS, MX or A records changed since the previous check",
+		"monitoring.error.jsonAssertionFailed":       "JSON assertion failed",
+		"monitoring.error.oauthTokenFailed":          "OAuth2 token request failed",
+		"monitoring.error.transactionStepsRequired":  "Transaction has no steps",
+		"monitoring.error.transactionExtractFailed":  "Variable not found in the response",
+		"monitoring.notify.failedStep":               "Failed step",
+		"monitoring.notify.steps":                    "Steps",
+		"monitoring.error.mailRejected":              "Mail server rejected the command",
+		"monitoring.error.sshHostKeyChanged":         "SSH host key changed",
+		"monitoring.error.sshWeakAlgorithms":         "SSH server offers weak algorithms",
+		"monitoring.error.sshCommandFailed":          "SSH command exited with an error",
+		"monitoring.error.sshKeyRequired":            "SSH command requires a private key",
+		"monitoring.error.invalidSSHKey":             "Invalid SSH private key",
+		"monitoring.error.ldapError":                 "LDAP server returned an error",
+		"monitoring.error.ldapTooFewEntries":         "LDAP search returned too few entries",
+		"monitoring.notify.tlsGradeTitle":            "\u26a0\ufe0f TLS grade dropped",
+		"monitoring.notify.grade":                    "Grade",
+		"monitoring.notify.tlsIssues":                "Issues",
+		"monitoring.error.tlsChainInvalid":           "Certificate chain is invalid",
+		"monitoring.error.tlsLegacyProtocol":         "Legacy TLS protocol enabled",
+		"monitoring.error.tlsGradeBelow":             "TLS grade below the minimum",
+		"monitoring.notify.headersTitle":             "\u26a0\ufe0f Security headers policy regressed",
+		"monitoring.notify.headersScore":             "Score",
+		"monitoring.notify.headersViolations":        "Violations",
+		"monitoring.error.headersScoreBelow":         "Security headers score below the minimum",
+		"monitoring.error.probeQuorum":               "Probe quorum failed",
+		"monitoring.error.probePartial":              "Some probe locations fail",
+		"monitoring.error.probesStale":               "Some probe locations report no results",
+		"monitoring.error.probesUnavailable":         "No fresh results from probes",
+		"monitoring.error.invalidPrometheusOptions":  "Invalid Prometheus expression",
+		"monitoring.error.invalidPrometheusResponse": "Unreadable Prometheus response",
+		"monitoring.error.prometheusNoSeries":        "No series matches the expression",
+		"monitoring.error.prometheusAmbiguous":       "Several series match the expression",
+		"monitoring.error.prometheusThreshold":       "Metric threshold reached",
+		"monitoring.notify.footer":                   "Berkut SCC",
 	}
 	if lang == "ru" {
 		if v, ok := ru[key]; ok {
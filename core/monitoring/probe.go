@@ -0,0 +1,504 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+// Probe agents sign every request after enrolment with the secret they received;
+// the signature covers the method, the endpoint, the timestamp and the body hash.
+const (
+	ProbeHeaderID        = "X-Probe-ID"
+	ProbeHeaderTimestamp = "X-Probe-Timestamp"
+	ProbeHeaderSignature = "X-Probe-Signature"
+	ProbeHeaderVersion   = "X-Probe-Version"
+
+	ProbeEndpointEnroll   = "/probe/enroll"
+	ProbeEndpointMonitors = "/probe/monitors"
+	ProbeEndpointResults  = "/probe/results"
+
+	// ProbeMaxSkew bounds the clock difference between a probe and the server.
+	ProbeMaxSkew = 5 * time.Minute
+	// DefaultProbePollSec is how often probes fetch their assignments, which is also their heartbeat.
+	DefaultProbePollSec = 30
+	// MaxProbesPerMonitor bounds the locations of one monitor.
+	MaxProbesPerMonitor = 10
+	// ProbeMaxPayloadBytes bounds a signed probe request body; probes split their results to stay below it.
+	ProbeMaxPayloadBytes = 1 << 20
+
+	maxProbeReports      = 500
+	maxProbeReportText   = 500
+	maxProbeReportValues = 100
+	// maxProbeContentText keeps a batch of content snapshots within the probe payload limit.
+	maxProbeContentText = 128 << 10
+)
+
+// ProbeEnrolRequest is sent once by a new probe with the token shown when it was created.
+type ProbeEnrolRequest struct {
+	Token   string `json:"token"`
+	Version string `json:"version,omitempty"`
+}
+
+// ProbeEnrolResponse carries the signing secret; the server keeps it encrypted and never shows it again.
+type ProbeEnrolResponse struct {
+	ProbeID int64  `json:"probe_id"`
+	Secret  string `json:"secret"`
+	PollSec int    `json:"poll_sec"`
+}
+
+// ProbeAssignment is a monitor a probe has to run with its decrypted credentials.
+type ProbeAssignment struct {
+	Monitor     store.Monitor             `json:"monitor"`
+	Credentials *store.MonitorCredentials `json:"credentials,omitempty"`
+}
+
+// ProbeConfig is returned to a probe on every poll.
+type ProbeConfig struct {
+	ProbeID int64 `json:"probe_id"`
+	PollSec int   `json:"poll_sec"`
+	// Settings carry the network policy and default timeout; probes obey the same SSRF rules as the server.
+	Settings store.MonitorSettings `json:"settings"`
+	Monitors []ProbeAssignment     `json:"monitors"`
+}
+
+// ProbeReport is the result of one check run by a probe.
+// The check output travels with it, so drift, regression and change detection also work for probe-only monitors.
+type ProbeReport struct {
+	MonitorID     int64                 `json:"monitor_id"`
+	OK            bool                  `json:"ok"`
+	StatusCode    *int                  `json:"status_code,omitempty"`
+	LatencyMs     int                   `json:"latency_ms"`
+	Error         string                `json:"error,omitempty"`
+	CheckedAt     time.Time             `json:"checked_at"`
+	TLS           *TLSInfo              `json:"tls,omitempty"`
+	Ping          *PingStats            `json:"ping,omitempty"`
+	ServerVersion string                `json:"server_version,omitempty"`
+	ServerRole    string                `json:"server_role,omitempty"`
+	Details       *store.MonitorDetails `json:"details,omitempty"`
+	Values        map[string]float64    `json:"values,omitempty"`
+	Content       *ContentSnapshot      `json:"content,omitempty"`
+}
+
+// NewProbeReport converts a check result into the report a probe sends back.
+func NewProbeReport(monitorID int64, res CheckResult) ProbeReport {
+	rep := ProbeReport{
+		MonitorID:     monitorID,
+		OK:            res.OK,
+		StatusCode:    res.StatusCode,
+		LatencyMs:     res.LatencyMs,
+		Error:         res.Error,
+		CheckedAt:     res.CheckedAt,
+		TLS:           res.TLS,
+		Ping:          res.Ping,
+		ServerVersion: res.ServerVersion,
+		ServerRole:    res.ServerRole,
+		Details:       res.Details,
+		Values:        res.Values,
+		Content:       res.Content,
+	}
+	if rep.Content != nil && len(rep.Content.Text) > maxProbeContentText {
+		rep.Content = &ContentSnapshot{Hash: rep.Content.Hash, Text: rep.Content.Text[:maxProbeContentText]}
+	}
+	return rep
+}
+
+// probeOutput is the part of a probe report kept in monitor_probe_results.payload_json.
+type probeOutput struct {
+	TLS           *TLSInfo              `json:"tls,omitempty"`
+	Ping          *PingStats            `json:"ping,omitempty"`
+	ServerVersion string                `json:"server_version,omitempty"`
+	ServerRole    string                `json:"server_role,omitempty"`
+	Details       *store.MonitorDetails `json:"details,omitempty"`
+	Values        map[string]float64    `json:"values,omitempty"`
+	Content       *ContentSnapshot      `json:"content,omitempty"`
+}
+
+func (rep ProbeReport) output() probeOutput {
+	out := probeOutput{
+		TLS:           rep.TLS,
+		Ping:          rep.Ping,
+		ServerVersion: truncateProbeText(rep.ServerVersion),
+		ServerRole:    truncateProbeText(rep.ServerRole),
+		Details:       rep.Details,
+		Content:       rep.Content,
+	}
+	if out.Details != nil {
+		details := *out.Details
+		details.Probes = nil
+		out.Details = &details
+	}
+	if len(rep.Values) <= maxProbeReportValues {
+		out.Values = rep.Values
+	}
+	if out.Content != nil && len(out.Content.Text) > maxProbeContentText {
+		out.Content = nil
+	}
+	return out
+}
+
+func truncateProbeText(text string) string {
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > maxProbeReportText {
+		return string(runes[:maxProbeReportText])
+	}
+	return text
+}
+
+// HashProbeToken returns the value stored in monitoring_probes.enrol_token_hash for a plaintext token.
+func HashProbeToken(token string) string {
+	return HashPushToken(token)
+}
+
+// SignProbeRequest returns the hex HMAC-SHA256 of a probe request.
+func SignProbeRequest(secret, method, endpoint string, ts int64, body []byte) string {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s", strings.ToUpper(method), endpoint, ts, hex.EncodeToString(sum[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyProbeRequest checks the signature and rejects timestamps outside ProbeMaxSkew.
+func VerifyProbeRequest(secret, method, endpoint, tsRaw, signature string, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(strings.TrimSpace(tsRaw), 10, 64)
+	if err != nil || secret == "" {
+		return false
+	}
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew > ProbeMaxSkew || skew < -ProbeMaxSkew {
+		return false
+	}
+	want := SignProbeRequest(secret, method, endpoint, ts, body)
+	return hmac.Equal([]byte(want), []byte(strings.ToLower(strings.TrimSpace(signature))))
+}
+
+// ClaimProbeRequest reports whether a verified probe request is seen for the first time. Signatures are kept
+// until their timestamp leaves ProbeMaxSkew, so a captured request cannot be replayed while it still verifies.
+func (e *Engine) ClaimProbeRequest(probeID int64, tsRaw, signature string, now time.Time) bool {
+	ts, err := strconv.ParseInt(strings.TrimSpace(tsRaw), 10, 64)
+	if err != nil {
+		return false
+	}
+	key := strconv.FormatInt(probeID, 10) + "|" + strings.ToLower(strings.TrimSpace(signature))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.probeReplays == nil {
+		e.probeReplays = map[string]time.Time{}
+	}
+	for k, expires := range e.probeReplays {
+		if now.After(expires) {
+			delete(e.probeReplays, k)
+		}
+	}
+	if _, seen := e.probeReplays[key]; seen {
+		return false
+	}
+	e.probeReplays[key] = time.Unix(ts, 0).Add(ProbeMaxSkew)
+	return true
+}
+
+// ProbeQuorum returns the number of failing locations that takes the monitor down.
+func ProbeQuorum(opts store.ProbeOptions) int {
+	total := len(opts.ProbeIDs)
+	if opts.IncludeLocal {
+		total++
+	}
+	if opts.Quorum > 0 && opts.Quorum <= total {
+		return opts.Quorum
+	}
+	return total/2 + 1
+}
+
+func probeOptions(m store.Monitor) *store.ProbeOptions {
+	if m.Options.Probes == nil || len(m.Options.Probes.ProbeIDs) == 0 {
+		return nil
+	}
+	return m.Options.Probes
+}
+
+// probeResultTTL is how long a location result counts: two missed intervals plus a poll of slack.
+func probeResultTTL(m store.Monitor) time.Duration {
+	interval := m.IntervalSec
+	if interval <= 0 {
+		interval = 60
+	}
+	return time.Duration(2*interval+2*DefaultProbePollSec) * time.Second
+}
+
+// ProbeLocations returns the last result of every location of a monitor, in the order they are configured.
+func (e *Engine) ProbeLocations(ctx context.Context, m store.Monitor, now time.Time) ([]store.ProbeLocation, error) {
+	locations, _, err := e.probeLocations(ctx, m, now)
+	return locations, err
+}
+
+// probeLocations is ProbeLocations together with the stored results by probe ID.
+func (e *Engine) probeLocations(ctx context.Context, m store.Monitor, now time.Time) ([]store.ProbeLocation, map[int64]store.MonitorProbeResult, error) {
+	opts := probeOptions(m)
+	if opts == nil {
+		return nil, nil, nil
+	}
+	results, err := e.store.ListProbeResults(ctx, m.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	probes, err := e.store.ListProbes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byResult := map[int64]store.MonitorProbeResult{}
+	for _, item := range results {
+		byResult[item.ProbeID] = item
+	}
+	byProbe := map[int64]store.MonitoringProbe{}
+	for _, p := range probes {
+		byProbe[p.ID] = p
+	}
+	ids := append([]int64{}, opts.ProbeIDs...)
+	if opts.IncludeLocal {
+		ids = append([]int64{0}, ids...)
+	}
+	ttl := probeResultTTL(m)
+	out := make([]store.ProbeLocation, 0, len(ids))
+	for _, id := range ids {
+		loc := store.ProbeLocation{ProbeID: id, Stale: true}
+		if p, ok := byProbe[id]; ok {
+			loc.Name = p.Name
+			loc.Location = p.Location
+		}
+		if item, ok := byResult[id]; ok {
+			checkedAt := item.CheckedAt
+			loc.OK = item.OK
+			loc.LatencyMs = item.LatencyMs
+			loc.Error = item.Error
+			loc.CheckedAt = &checkedAt
+			loc.Stale = now.Sub(checkedAt) > ttl
+		}
+		out = append(out, loc)
+	}
+	return out, byResult, nil
+}
+
+// evaluateProbeQuorum folds the location results of a monitor into one check result:
+// the monitor is down when at least the quorum of fresh locations fails, degraded when some do.
+func evaluateProbeQuorum(opts store.ProbeOptions, locations []store.ProbeLocation, now time.Time) CheckResult {
+	res := CheckResult{CheckedAt: now, Details: &store.MonitorDetails{Probes: locations}}
+	failed, stale, up, latency := 0, 0, 0, 0
+	for _, loc := range locations {
+		switch {
+		case loc.Stale:
+			stale++
+		case !loc.OK:
+			failed++
+		default:
+			up++
+			latency += loc.LatencyMs
+		}
+	}
+	if up > 0 {
+		res.LatencyMs = latency / up
+	}
+	total := len(locations)
+	switch {
+	case failed >= ProbeQuorum(opts):
+		res.Error = fmt.Sprintf("monitoring.error.probeQuorum: %d/%d", failed, total)
+	case up+failed == 0:
+		res.Error = "monitoring.error.probesUnavailable"
+	case failed > 0:
+		res.OK, res.Degraded = true, true
+		res.Error = fmt.Sprintf("monitoring.error.probePartial: %d/%d", failed, total)
+	case stale > 0:
+		res.OK, res.Degraded = true, true
+		res.Error = fmt.Sprintf("monitoring.error.probesStale: %d/%d", stale, total)
+	default:
+		res.OK = true
+	}
+	return res
+}
+
+// probeCheck replaces the local result of a monitor run by probes with the quorum of its locations.
+// The local result counts as location 0 when the monitor includes the server and keeps its details;
+// otherwise the output of the first fresh location in configured order is used, so drift checks keep one source.
+func (e *Engine) probeCheck(ctx context.Context, m store.Monitor, opts store.ProbeOptions, local CheckResult) CheckResult {
+	now := time.Now().UTC()
+	if opts.IncludeLocal {
+		if err := e.store.UpsertProbeResult(ctx, &store.MonitorProbeResult{
+			MonitorID:  m.ID,
+			OK:         local.OK,
+			StatusCode: local.StatusCode,
+			LatencyMs:  local.LatencyMs,
+			Error:      local.Error,
+			CheckedAt:  local.CheckedAt,
+		}); err != nil && e.logger != nil {
+			e.logger.Errorf("monitoring probe result %d: %v", m.ID, err)
+		}
+	}
+	locations, results, err := e.probeLocations(ctx, m, now)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring probe locations %d: %v", m.ID, err)
+		}
+		return CheckResult{Error: "monitoring.error.probesUnavailable", CheckedAt: now}
+	}
+	res := evaluateProbeQuorum(opts, locations, now)
+	if opts.IncludeLocal {
+		mergeProbeOutput(&res, local)
+		return res
+	}
+	for _, loc := range locations {
+		item, ok := results[loc.ProbeID]
+		if loc.Stale || !ok || item.Payload == "" {
+			continue
+		}
+		var out probeOutput
+		if err := json.Unmarshal([]byte(item.Payload), &out); err != nil {
+			continue
+		}
+		mergeProbeOutput(&res, CheckResult{
+			StatusCode:    item.StatusCode,
+			TLS:           out.TLS,
+			Ping:          out.Ping,
+			ServerVersion: out.ServerVersion,
+			ServerRole:    out.ServerRole,
+			Details:       out.Details,
+			Values:        out.Values,
+			Content:       out.Content,
+		})
+		break
+	}
+	return res
+}
+
+// mergeProbeOutput copies the check output of one location into the quorum result, keeping the location list.
+func mergeProbeOutput(res *CheckResult, src CheckResult) {
+	res.StatusCode = src.StatusCode
+	res.TLS = src.TLS
+	res.Ping = src.Ping
+	res.ServerVersion = src.ServerVersion
+	res.ServerRole = src.ServerRole
+	res.Values = src.Values
+	res.Content = src.Content
+	if src.Details != nil {
+		details := *src.Details
+		details.Probes = res.Details.Probes
+		res.Details = &details
+	}
+}
+
+// ProbeConfig returns the monitors assigned to a probe together with the policy it has to apply.
+func (e *Engine) ProbeConfig(ctx context.Context, probeID int64) (*ProbeConfig, error) {
+	list, err := e.store.ListProbeMonitors(ctx, probeID)
+	if err != nil {
+		return nil, err
+	}
+	settings := e.currentSettings(ctx)
+	cfg := &ProbeConfig{
+		ProbeID: probeID,
+		PollSec: DefaultProbePollSec,
+		Settings: store.MonitorSettings{
+			DefaultTimeoutSec:    settings.DefaultTimeoutSec,
+			AllowPrivateNetworks: settings.AllowPrivateNetworks,
+			AllowedNetworks:      settings.AllowedNetworks,
+			BlockedNetworks:      settings.BlockedNetworks,
+		},
+		Monitors: []ProbeAssignment{},
+	}
+	for _, m := range list {
+		if !m.IsActive || m.IsPaused || TypeIsPassive(m.Type) {
+			continue
+		}
+		creds, err := e.monitorCredentials(m)
+		if err != nil {
+			if e.logger != nil {
+				e.logger.Errorf("monitoring credentials %d: %v", m.ID, err)
+			}
+			continue
+		}
+		cfg.Monitors = append(cfg.Monitors, ProbeAssignment{Monitor: m, Credentials: creds})
+	}
+	return cfg, nil
+}
+
+// RecordProbeReports stores the results a probe sent for the monitors assigned to it and returns how many were accepted.
+// They are evaluated on the next scheduled check of each monitor.
+func (e *Engine) RecordProbeReports(ctx context.Context, probeID int64, reports []ProbeReport) (int, error) {
+	if len(reports) > maxProbeReports {
+		return 0, errors.New("monitoring.error.tooManyProbeReports")
+	}
+	list, err := e.store.ListProbeMonitors(ctx, probeID)
+	if err != nil {
+		return 0, err
+	}
+	assigned := map[int64]bool{}
+	for _, m := range list {
+		assigned[m.ID] = true
+	}
+	now := time.Now().UTC()
+	accepted := 0
+	for _, rep := range reports {
+		if !assigned[rep.MonitorID] {
+			continue
+		}
+		// A result from the future would stay fresh forever; old ones simply go stale.
+		checkedAt := rep.CheckedAt.UTC()
+		if checkedAt.IsZero() || checkedAt.After(now) {
+			checkedAt = now
+		}
+		payload, err := json.Marshal(rep.output())
+		if err != nil {
+			return accepted, err
+		}
+		if err := e.store.UpsertProbeResult(ctx, &store.MonitorProbeResult{
+			MonitorID:  rep.MonitorID,
+			ProbeID:    probeID,
+			OK:         rep.OK,
+			StatusCode: rep.StatusCode,
+			LatencyMs:  max(rep.LatencyMs, 0),
+			Error:      truncateProbeText(rep.Error),
+			CheckedAt:  checkedAt,
+			Payload:    string(payload),
+		}); err != nil {
+			return accepted, err
+		}
+		accepted++
+	}
+	return accepted, nil
+}
+
+// ProbeHeartbeat marks the probe as seen and feeds its liveness push monitor. Only enrolment and the
+// assignment poll are heartbeats, so the liveness monitor gets one point per poll interval.
+func (e *Engine) ProbeHeartbeat(ctx context.Context, p *store.MonitoringProbe, remoteAddr, version string) error {
+	if err := e.ProbeSeen(ctx, p, remoteAddr, version); err != nil {
+		return err
+	}
+	if p.MonitorID == nil {
+		return nil
+	}
+	mon, err := e.store.GetMonitor(ctx, *p.MonitorID)
+	if err != nil || mon == nil || !mon.IsActive || !TypeIsPassive(mon.Type) {
+		return err
+	}
+	return e.RecordPush(ctx, *mon, CheckResult{OK: true, CheckedAt: *p.LastSeenAt})
+}
+
+// ProbeSeen records the address and version of an authenticated probe request.
+func (e *Engine) ProbeSeen(ctx context.Context, p *store.MonitoringProbe, remoteAddr, version string) error {
+	now := time.Now().UTC()
+	p.LastSeenAt = &now
+	p.RemoteAddr = remoteAddr
+	if version = strings.TrimSpace(version); version != "" {
+		if len(version) > 64 {
+			version = version[:64]
+		}
+		p.Version = version
+	}
+	return e.store.TouchProbe(ctx, p.ID, now, p.RemoteAddr, p.Version)
+}
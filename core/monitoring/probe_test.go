@@ -0,0 +1,89 @@
+package monitoring
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+func TestProbeRequestSignature(t *testing.T) {
+	now := time.Now().UTC()
+	ts := now.Unix()
+	body := []byte(`[{"monitor_id":1,"ok":true}]`)
+	sig := SignProbeRequest("secret", "post", ProbeEndpointResults, ts, body)
+	raw := strconv.FormatInt(ts, 10)
+	if !VerifyProbeRequest("secret", "POST", ProbeEndpointResults, raw, sig, body, now) {
+		t.Fatalf("expected a valid signature")
+	}
+	if VerifyProbeRequest("other", "POST", ProbeEndpointResults, raw, sig, body, now) {
+		t.Fatalf("expected a wrong secret to be rejected")
+	}
+	if VerifyProbeRequest("secret", "POST", ProbeEndpointMonitors, raw, sig, body, now) {
+		t.Fatalf("expected the signature to be bound to the endpoint")
+	}
+	if VerifyProbeRequest("secret", "POST", ProbeEndpointResults, raw, sig, []byte(`[]`), now) {
+		t.Fatalf("expected a changed body to be rejected")
+	}
+	if VerifyProbeRequest("secret", "POST", ProbeEndpointResults, raw, sig, body, now.Add(ProbeMaxSkew+time.Minute)) {
+		t.Fatalf("expected a replayed request to be rejected")
+	}
+}
+
+func TestClaimProbeRequestRejectsReplays(t *testing.T) {
+	e := NewEngine(nil, nil)
+	now := time.Now().UTC()
+	raw := strconv.FormatInt(now.Unix(), 10)
+	sig := SignProbeRequest("secret", "GET", ProbeEndpointMonitors, now.Unix(), nil)
+	if !e.ClaimProbeRequest(1, raw, sig, now) {
+		t.Fatalf("expected the first request to be accepted")
+	}
+	if e.ClaimProbeRequest(1, raw, sig, now.Add(time.Minute)) {
+		t.Fatalf("expected a replayed request to be rejected")
+	}
+	if !e.ClaimProbeRequest(2, raw, sig, now) {
+		t.Fatalf("expected the signature to be tracked per probe")
+	}
+	if !e.ClaimProbeRequest(1, raw, sig, now.Add(ProbeMaxSkew+time.Second)) || len(e.probeReplays) != 1 {
+		t.Fatalf("expected expired signatures to be forgotten, kept %d", len(e.probeReplays))
+	}
+}
+
+func TestEvaluateProbeQuorum(t *testing.T) {
+	opts := store.ProbeOptions{ProbeIDs: []int64{1, 2, 3}}
+	if got := ProbeQuorum(opts); got != 2 {
+		t.Fatalf("expected a majority quorum, got %d", got)
+	}
+	loc := func(id int64, ok, stale bool, latency int) store.ProbeLocation {
+		return store.ProbeLocation{ProbeID: id, OK: ok, Stale: stale, LatencyMs: latency}
+	}
+	now := time.Now().UTC()
+
+	res := evaluateProbeQuorum(opts, []store.ProbeLocation{loc(1, true, false, 10), loc(2, true, false, 30), loc(3, true, false, 20)}, now)
+	if !res.OK || res.Degraded || res.LatencyMs != 20 || len(res.Details.Probes) != 3 {
+		t.Fatalf("expected up with the average latency, got %+v", res)
+	}
+	res = evaluateProbeQuorum(opts, []store.ProbeLocation{loc(1, false, false, 0), loc(2, true, false, 30), loc(3, true, false, 20)}, now)
+	if !res.OK || !res.Degraded || res.Error != "monitoring.error.probePartial: 1/3" {
+		t.Fatalf("expected one failing location to degrade the monitor, got %+v", res)
+	}
+	res = evaluateProbeQuorum(opts, []store.ProbeLocation{loc(1, false, false, 0), loc(2, false, false, 0), loc(3, true, false, 20)}, now)
+	if res.OK || res.Error != "monitoring.error.probeQuorum: 2/3" {
+		t.Fatalf("expected the quorum to take the monitor down, got %+v", res)
+	}
+	res = evaluateProbeQuorum(opts, []store.ProbeLocation{loc(1, false, true, 0), loc(2, true, false, 30), loc(3, true, false, 20)}, now)
+	if !res.OK || !res.Degraded || res.Error != "monitoring.error.probesStale: 1/3" {
+		t.Fatalf("expected a stale location not to count as failed, got %+v", res)
+	}
+	res = evaluateProbeQuorum(opts, []store.ProbeLocation{loc(1, true, true, 0), loc(2, true, true, 0), loc(3, true, true, 0)}, now)
+	if res.OK || res.Error != "monitoring.error.probesUnavailable" {
+		t.Fatalf("expected no fresh result to fail the check, got %+v", res)
+	}
+
+	strict := store.ProbeOptions{ProbeIDs: []int64{1, 2}, IncludeLocal: true, Quorum: 3}
+	res = evaluateProbeQuorum(strict, []store.ProbeLocation{loc(0, false, false, 0), loc(1, false, false, 0), loc(2, true, false, 5)}, now)
+	if !res.OK || !res.Degraded {
+		t.Fatalf("expected two of three failures below a quorum of 3 to degrade only, got %+v", res)
+	}
+}
@@ -0,0 +1,803 @@
+package monitoring
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Minimal protobuf support for gRPC checks: the wire format, the subset of
+// descriptor.proto returned by server reflection and the canonical JSON mapping.
+
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+// Field types from google.protobuf.FieldDescriptorProto.Type.
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeUint64   = 4
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeGroup    = 10
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeUint32   = 13
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+
+	protoLabelRepeated = 3
+	protoMaxDepth      = 32
+)
+
+var errProtoMalformed = errors.New("malformed protobuf message")
+
+type protoField struct {
+	Num   int
+	Wire  int
+	Value uint64
+	Bytes []byte
+}
+
+func protoAppendTag(b []byte, num, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(num)<<3|uint64(wire))
+}
+
+func protoAppendVarint(b []byte, num int, v uint64) []byte {
+	return binary.AppendUvarint(protoAppendTag(b, num, protoWireVarint), v)
+}
+
+func protoAppendBytes(b []byte, num int, v []byte) []byte {
+	b = binary.AppendUvarint(protoAppendTag(b, num, protoWireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+func protoAppendString(b []byte, num int, v string) []byte {
+	return protoAppendBytes(b, num, []byte(v))
+}
+
+// protoFields splits an encoded message into its fields. Groups are not supported.
+func protoFields(b []byte) ([]protoField, error) {
+	var out []protoField
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 || tag>>3 == 0 {
+			return nil, errProtoMalformed
+		}
+		b = b[n:]
+		f := protoField{Num: int(tag >> 3), Wire: int(tag & 7)}
+		switch f.Wire {
+		case protoWireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errProtoMalformed
+			}
+			f.Value = v
+			b = b[n:]
+		case protoWireFixed64:
+			if len(b) < 8 {
+				return nil, errProtoMalformed
+			}
+			f.Value = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case protoWireFixed32:
+			if len(b) < 4 {
+				return nil, errProtoMalformed
+			}
+			f.Value = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case protoWireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return nil, errProtoMalformed
+			}
+			f.Bytes = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			return nil, errProtoMalformed
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+type protoFieldDesc struct {
+	Name     string
+	JSONName string
+	Number   int
+	Label    int
+	Type     int
+	TypeName string
+}
+
+type protoMessageDesc struct {
+	Name     string
+	Fields   []protoFieldDesc
+	MapEntry bool
+}
+
+func (d *protoMessageDesc) field(num int) *protoFieldDesc {
+	for i := range d.Fields {
+		if d.Fields[i].Number == num {
+			return &d.Fields[i]
+		}
+	}
+	return nil
+}
+
+func (d *protoMessageDesc) fieldByName(name string) *protoFieldDesc {
+	for i := range d.Fields {
+		if d.Fields[i].Name == name || d.Fields[i].JSONName == name {
+			return &d.Fields[i]
+		}
+	}
+	return nil
+}
+
+type protoEnumDesc struct {
+	Values map[string]int32
+	Names  map[int32]string
+}
+
+type protoMethodDesc struct {
+	Input     string
+	Output    string
+	Streaming bool
+}
+
+// protoRegistry indexes descriptors by fully-qualified name without the leading dot.
+type protoRegistry struct {
+	files    map[string]bool
+	messages map[string]*protoMessageDesc
+	enums    map[string]*protoEnumDesc
+	methods  map[string]protoMethodDesc
+}
+
+func newProtoRegistry() *protoRegistry {
+	return &protoRegistry{
+		files:    map[string]bool{},
+		messages: map[string]*protoMessageDesc{},
+		enums:    map[string]*protoEnumDesc{},
+		methods:  map[string]protoMethodDesc{},
+	}
+}
+
+// addFile registers a serialized FileDescriptorProto and returns its dependencies.
+func (r *protoRegistry) addFile(raw []byte) (string, []string, error) {
+	fields, err := protoFields(raw)
+	if err != nil {
+		return "", nil, err
+	}
+	var name, pkg string
+	var deps []string
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			name = string(f.Bytes)
+		case 2:
+			pkg = string(f.Bytes)
+		case 3:
+			deps = append(deps, string(f.Bytes))
+		}
+	}
+	if r.files[name] {
+		return name, deps, nil
+	}
+	r.files[name] = true
+	for _, f := range fields {
+		if f.Wire != protoWireBytes {
+			continue
+		}
+		switch f.Num {
+		case 4:
+			err = r.addMessage(pkg, f.Bytes)
+		case 5:
+			err = r.addEnum(pkg, f.Bytes)
+		case 6:
+			err = r.addService(pkg, f.Bytes)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return name, deps, nil
+}
+
+func protoQualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func (r *protoRegistry) addMessage(scope string, raw []byte) error {
+	fields, err := protoFields(raw)
+	if err != nil {
+		return err
+	}
+	msg := &protoMessageDesc{}
+	for _, f := range fields {
+		if f.Num == 1 {
+			msg.Name = protoQualify(scope, string(f.Bytes))
+		}
+	}
+	for _, f := range fields {
+		if f.Wire != protoWireBytes {
+			continue
+		}
+		switch f.Num {
+		case 2:
+			fd, err := parseProtoField(f.Bytes)
+			if err != nil {
+				return err
+			}
+			msg.Fields = append(msg.Fields, fd)
+		case 3:
+			err = r.addMessage(msg.Name, f.Bytes)
+		case 4:
+			err = r.addEnum(msg.Name, f.Bytes)
+		case 7:
+			var opts []protoField
+			opts, err = protoFields(f.Bytes)
+			for _, o := range opts {
+				if o.Num == 7 && o.Wire == protoWireVarint {
+					msg.MapEntry = o.Value != 0
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	r.messages[msg.Name] = msg
+	return nil
+}
+
+func parseProtoField(raw []byte) (protoFieldDesc, error) {
+	fields, err := protoFields(raw)
+	if err != nil {
+		return protoFieldDesc{}, err
+	}
+	var fd protoFieldDesc
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			fd.Name = string(f.Bytes)
+		case 3:
+			fd.Number = int(f.Value)
+		case 4:
+			fd.Label = int(f.Value)
+		case 5:
+			fd.Type = int(f.Value)
+		case 6:
+			fd.TypeName = strings.TrimPrefix(string(f.Bytes), ".")
+		case 10:
+			fd.JSONName = string(f.Bytes)
+		}
+	}
+	if fd.JSONName == "" {
+		fd.JSONName = protoJSONName(fd.Name)
+	}
+	return fd, nil
+}
+
+// protoJSONName mirrors protoc's lowerCamelCase conversion for descriptors without json_name.
+func protoJSONName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (r *protoRegistry) addEnum(scope string, raw []byte) error {
+	fields, err := protoFields(raw)
+	if err != nil {
+		return err
+	}
+	enum := &protoEnumDesc{Values: map[string]int32{}, Names: map[int32]string{}}
+	var name string
+	for _, f := range fields {
+		switch f.Num {
+		case 1:
+			name = protoQualify(scope, string(f.Bytes))
+		case 2:
+			vals, err := protoFields(f.Bytes)
+			if err != nil {
+				return err
+			}
+			var valName string
+			var num int32
+			for _, v := range vals {
+				switch v.Num {
+				case 1:
+					valName = string(v.Bytes)
+				case 2:
+					num = int32(v.Value)
+				}
+			}
+			enum.Values[valName] = num
+			if _, ok := enum.Names[num]; !ok {
+				enum.Names[num] = valName
+			}
+		}
+	}
+	r.enums[name] = enum
+	return nil
+}
+
+func (r *protoRegistry) addService(scope string, raw []byte) error {
+	fields, err := protoFields(raw)
+	if err != nil {
+		return err
+	}
+	var svc string
+	for _, f := range fields {
+		if f.Num == 1 {
+			svc = protoQualify(scope, string(f.Bytes))
+		}
+	}
+	for _, f := range fields {
+		if f.Num != 2 {
+			continue
+		}
+		parts, err := protoFields(f.Bytes)
+		if err != nil {
+			return err
+		}
+		var name string
+		var method protoMethodDesc
+		for _, p := range parts {
+			switch p.Num {
+			case 1:
+				name = string(p.Bytes)
+			case 2:
+				method.Input = strings.TrimPrefix(string(p.Bytes), ".")
+			case 3:
+				method.Output = strings.TrimPrefix(string(p.Bytes), ".")
+			case 5, 6:
+				method.Streaming = method.Streaming || p.Value != 0
+			}
+		}
+		r.methods[svc+"/"+name] = method
+	}
+	return nil
+}
+
+// encodeJSON converts a protobuf JSON document into the binary encoding of the named message.
+func (r *protoRegistry) encodeJSON(msgName string, raw []byte) ([]byte, error) {
+	var doc any = map[string]any{}
+	if len(strings.TrimSpace(string(raw))) > 0 {
+		dec := json.NewDecoder(strings.NewReader(string(raw)))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("request must be a JSON object")
+	}
+	return r.encodeMessage(nil, msgName, obj, 0)
+}
+
+func (r *protoRegistry) encodeMessage(b []byte, msgName string, obj map[string]any, depth int) ([]byte, error) {
+	msg := r.messages[msgName]
+	if msg == nil {
+		return nil, fmt.Errorf("unknown message %s", msgName)
+	}
+	if depth > protoMaxDepth {
+		return nil, errors.New("message nesting too deep")
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		val := obj[k]
+		fd := msg.fieldByName(k)
+		if fd == nil {
+			return nil, fmt.Errorf("unknown field %s.%s", msgName, k)
+		}
+		if val == nil {
+			continue
+		}
+		var err error
+		if fd.Label == protoLabelRepeated {
+			if entry := r.messages[fd.TypeName]; fd.Type == protoTypeMessage && entry != nil && entry.MapEntry {
+				b, err = r.encodeMap(b, fd, entry, val, depth)
+			} else {
+				list, ok := val.([]any)
+				if !ok {
+					return nil, fmt.Errorf("field %s must be an array", k)
+				}
+				for _, item := range list {
+					if b, err = r.encodeValue(b, fd, item, depth); err != nil {
+						break
+					}
+				}
+			}
+		} else {
+			b, err = r.encodeValue(b, fd, val, depth)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (r *protoRegistry) encodeMap(b []byte, fd *protoFieldDesc, entry *protoMessageDesc, val any, depth int) ([]byte, error) {
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("field %s must be an object", fd.Name)
+	}
+	keyDesc, valDesc := entry.field(1), entry.field(2)
+	if keyDesc == nil || valDesc == nil {
+		return nil, fmt.Errorf("invalid map entry %s", entry.Name)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var key any = k
+		if keyDesc.Type != protoTypeString {
+			key = json.Number(k)
+		}
+		item, err := r.encodeValue(nil, keyDesc, key, depth)
+		if err != nil {
+			return nil, err
+		}
+		if item, err = r.encodeValue(item, valDesc, obj[k], depth); err != nil {
+			return nil, err
+		}
+		b = protoAppendBytes(b, fd.Number, item)
+	}
+	return b, nil
+}
+
+func (r *protoRegistry) encodeValue(b []byte, fd *protoFieldDesc, val any, depth int) ([]byte, error) {
+	switch fd.Type {
+	case protoTypeString:
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be a string", fd.Name)
+		}
+		return protoAppendString(b, fd.Number, s), nil
+	case protoTypeBytes:
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be base64", fd.Name)
+		}
+		raw, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			if raw, err = base64.URLEncoding.DecodeString(s); err != nil {
+				return nil, fmt.Errorf("field %s must be base64", fd.Name)
+			}
+		}
+		return protoAppendBytes(b, fd.Number, raw), nil
+	case protoTypeBool:
+		v, ok := val.(bool)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be a boolean", fd.Name)
+		}
+		var n uint64
+		if v {
+			n = 1
+		}
+		return protoAppendVarint(b, fd.Number, n), nil
+	case protoTypeMessage:
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be an object", fd.Name)
+		}
+		inner, err := r.encodeMessage(nil, fd.TypeName, obj, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return protoAppendBytes(b, fd.Number, inner), nil
+	case protoTypeEnum:
+		if s, ok := val.(string); ok {
+			enum := r.enums[fd.TypeName]
+			if enum == nil {
+				return nil, fmt.Errorf("unknown enum %s", fd.TypeName)
+			}
+			n, ok := enum.Values[s]
+			if !ok {
+				return nil, fmt.Errorf("unknown value %s for %s", s, fd.Name)
+			}
+			return protoAppendVarint(b, fd.Number, uint64(int64(n))), nil
+		}
+		n, err := protoJSONInt(val, 32)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fd.Name, err)
+		}
+		return protoAppendVarint(b, fd.Number, uint64(n)), nil
+	case protoTypeDouble, protoTypeFloat:
+		f, err := protoJSONFloat(val)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fd.Name, err)
+		}
+		if fd.Type == protoTypeFloat {
+			b = protoAppendTag(b, fd.Number, protoWireFixed32)
+			return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(f))), nil
+		}
+		b = protoAppendTag(b, fd.Number, protoWireFixed64)
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(f)), nil
+	case protoTypeUint32, protoTypeUint64, protoTypeFixed32, protoTypeFixed64:
+		bits := 64
+		if fd.Type == protoTypeUint32 || fd.Type == protoTypeFixed32 {
+			bits = 32
+		}
+		n, err := protoJSONUint(val, bits)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fd.Name, err)
+		}
+		switch fd.Type {
+		case protoTypeFixed32:
+			return binary.LittleEndian.AppendUint32(protoAppendTag(b, fd.Number, protoWireFixed32), uint32(n)), nil
+		case protoTypeFixed64:
+			return binary.LittleEndian.AppendUint64(protoAppendTag(b, fd.Number, protoWireFixed64), n), nil
+		}
+		return protoAppendVarint(b, fd.Number, n), nil
+	case protoTypeInt32, protoTypeInt64, protoTypeSint32, protoTypeSint64, protoTypeSfixed32, protoTypeSfixed64:
+		bits := 64
+		if fd.Type == protoTypeInt32 || fd.Type == protoTypeSint32 || fd.Type == protoTypeSfixed32 {
+			bits = 32
+		}
+		n, err := protoJSONInt(val, bits)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fd.Name, err)
+		}
+		switch fd.Type {
+		case protoTypeSint32, protoTypeSint64:
+			return protoAppendVarint(b, fd.Number, uint64(n<<1)^uint64(n>>63)), nil
+		case protoTypeSfixed32:
+			return binary.LittleEndian.AppendUint32(protoAppendTag(b, fd.Number, protoWireFixed32), uint32(int32(n))), nil
+		case protoTypeSfixed64:
+			return binary.LittleEndian.AppendUint64(protoAppendTag(b, fd.Number, protoWireFixed64), uint64(n)), nil
+		}
+		return protoAppendVarint(b, fd.Number, uint64(n)), nil
+	}
+	return nil, fmt.Errorf("field %s has unsupported type %d", fd.Name, fd.Type)
+}
+
+// protoJSONNumber accepts JSON numbers and numeric strings, as the protobuf JSON mapping does.
+func protoJSONNumber(val any) (string, error) {
+	switch v := val.(type) {
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return v, nil
+	}
+	return "", errors.New("expected a number")
+}
+
+func protoJSONInt(val any, bits int) (int64, error) {
+	s, err := protoJSONNumber(val)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, bits)
+}
+
+func protoJSONUint(val any, bits int) (uint64, error) {
+	s, err := protoJSONNumber(val)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(s, 10, bits)
+}
+
+func protoJSONFloat(val any) (float64, error) {
+	s, err := protoJSONNumber(val)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// decodeJSON converts an encoded message into its protobuf JSON form.
+func (r *protoRegistry) decodeJSON(msgName string, raw []byte) ([]byte, error) {
+	obj, err := r.decodeMessage(msgName, raw, 0)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+func (r *protoRegistry) decodeMessage(msgName string, raw []byte, depth int) (map[string]any, error) {
+	msg := r.messages[msgName]
+	if msg == nil {
+		return nil, fmt.Errorf("unknown message %s", msgName)
+	}
+	if depth > protoMaxDepth {
+		return nil, errors.New("message nesting too deep")
+	}
+	fields, err := protoFields(raw)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	for _, f := range fields {
+		fd := msg.field(f.Num)
+		if fd == nil {
+			continue
+		}
+		if fd.Label != protoLabelRepeated {
+			val, err := r.decodeValue(fd, f, depth)
+			if err != nil {
+				return nil, err
+			}
+			out[fd.JSONName] = val
+			continue
+		}
+		if entry := r.messages[fd.TypeName]; fd.Type == protoTypeMessage && entry != nil && entry.MapEntry {
+			if err := r.decodeMapEntry(out, fd, entry, f.Bytes, depth); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		list, _ := out[fd.JSONName].([]any)
+		if f.Wire == protoWireBytes && fd.Type != protoTypeString && fd.Type != protoTypeBytes && fd.Type != protoTypeMessage {
+			items, err := protoUnpack(fd.Type, f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				val, err := r.decodeValue(fd, item, depth)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, val)
+			}
+		} else {
+			val, err := r.decodeValue(fd, f, depth)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+		}
+		out[fd.JSONName] = list
+	}
+	return out, nil
+}
+
+func (r *protoRegistry) decodeMapEntry(out map[string]any, fd *protoFieldDesc, entry *protoMessageDesc, raw []byte, depth int) error {
+	obj, _ := out[fd.JSONName].(map[string]any)
+	if obj == nil {
+		obj = map[string]any{}
+		out[fd.JSONName] = obj
+	}
+	fields, err := protoFields(raw)
+	if err != nil {
+		return err
+	}
+	var key string
+	var val any
+	for _, f := range fields {
+		desc := entry.field(f.Num)
+		if desc == nil {
+			continue
+		}
+		v, err := r.decodeValue(desc, f, depth)
+		if err != nil {
+			return err
+		}
+		if f.Num == 1 {
+			key = fmt.Sprint(v)
+		} else if f.Num == 2 {
+			val = v
+		}
+	}
+	obj[key] = val
+	return nil
+}
+
+// protoUnpack splits a packed repeated scalar field into individual values.
+func protoUnpack(typ int, raw []byte) ([]protoField, error) {
+	var out []protoField
+	for len(raw) > 0 {
+		switch typ {
+		case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+			if len(raw) < 8 {
+				return nil, errProtoMalformed
+			}
+			out = append(out, protoField{Wire: protoWireFixed64, Value: binary.LittleEndian.Uint64(raw)})
+			raw = raw[8:]
+		case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+			if len(raw) < 4 {
+				return nil, errProtoMalformed
+			}
+			out = append(out, protoField{Wire: protoWireFixed32, Value: uint64(binary.LittleEndian.Uint32(raw))})
+			raw = raw[4:]
+		default:
+			v, n := binary.Uvarint(raw)
+			if n <= 0 {
+				return nil, errProtoMalformed
+			}
+			out = append(out, protoField{Wire: protoWireVarint, Value: v})
+			raw = raw[n:]
+		}
+	}
+	return out, nil
+}
+
+func (r *protoRegistry) decodeValue(fd *protoFieldDesc, f protoField, depth int) (any, error) {
+	switch fd.Type {
+	case protoTypeString:
+		return string(f.Bytes), nil
+	case protoTypeBytes:
+		return base64.StdEncoding.EncodeToString(f.Bytes), nil
+	case protoTypeMessage:
+		return r.decodeMessage(fd.TypeName, f.Bytes, depth+1)
+	case protoTypeBool:
+		return f.Value != 0, nil
+	case protoTypeEnum:
+		if enum := r.enums[fd.TypeName]; enum != nil {
+			if name, ok := enum.Names[int32(f.Value)]; ok {
+				return name, nil
+			}
+		}
+		return int32(f.Value), nil
+	case protoTypeDouble:
+		return protoJSONFloatValue(math.Float64frombits(f.Value)), nil
+	case protoTypeFloat:
+		return protoJSONFloatValue(float64(math.Float32frombits(uint32(f.Value)))), nil
+	case protoTypeInt32, protoTypeSfixed32:
+		return int32(f.Value), nil
+	case protoTypeUint32, protoTypeFixed32:
+		return uint32(f.Value), nil
+	case protoTypeSint32:
+		return int32(uint32(f.Value>>1) ^ -uint32(f.Value&1)), nil
+	// 64-bit integers are strings in the protobuf JSON mapping.
+	case protoTypeInt64, protoTypeSfixed64:
+		return strconv.FormatInt(int64(f.Value), 10), nil
+	case protoTypeUint64, protoTypeFixed64:
+		return strconv.FormatUint(f.Value, 10), nil
+	case protoTypeSint64:
+		return strconv.FormatInt(int64(f.Value>>1)^-int64(f.Value&1), 10), nil
+	}
+	return nil, fmt.Errorf("field %s has unsupported type %d", fd.Name, fd.Type)
+}
+
+func protoJSONFloatValue(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
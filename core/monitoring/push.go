@@ -0,0 +1,115 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+const (
+	defaultPushGraceSec = 30
+	maxPushGraceSec     = 86400
+)
+
+// HashPushToken returns the value stored in monitors.push_token_hash for a plaintext token.
+func HashPushToken(token string) string {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ""
+	}
+	return utils.Sha256Hex([]byte(token))
+}
+
+// PushGraceSec returns the effective grace period added on top of the interval
+// before a passive monitor without heartbeats is marked down.
+func PushGraceSec(m store.Monitor) int {
+	if m.PushGraceSec <= 0 {
+		return defaultPushGraceSec
+	}
+	if m.PushGraceSec > maxPushGraceSec {
+		return maxPushGraceSec
+	}
+	return m.PushGraceSec
+}
+
+// RecordPush stores a heartbeat reported by an external sender and runs it
+// through the same state, notification, incident and SLA pipeline as active checks.
+func (e *Engine) RecordPush(ctx context.Context, m store.Monitor, result CheckResult) error {
+	if !TypeIsPassive(m.Type) {
+		return errors.New("monitoring.error.pushOnly")
+	}
+	if result.CheckedAt.IsZero() {
+		result.CheckedAt = time.Now().UTC()
+	}
+	if result.OK {
+		result.Error = ""
+	} else if strings.TrimSpace(result.Error) == "" {
+		result.Error = "monitoring.error.pushReportedDown"
+	}
+	settings := e.currentSettings(ctx)
+	lock := e.pushLock(m.ID)
+	lock.Lock()
+	automate, err := e.storeResult(ctx, m, result, settings)
+	lock.Unlock()
+	if automate != nil {
+		automate()
+	}
+	return err
+}
+
+// pushLock serialises the heartbeats of one passive monitor; notifications are sent outside of it.
+func (e *Engine) pushLock(id int64) *sync.Mutex {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pushLocks == nil {
+		e.pushLocks = map[int64]*sync.Mutex{}
+	}
+	lock, ok := e.pushLocks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		e.pushLocks[id] = lock
+	}
+	return lock
+}
+
+func (e *Engine) pushOverdue(ctx context.Context, m store.Monitor, now time.Time) bool {
+	baseline := m.CreatedAt
+	if m.UpdatedAt.After(baseline) {
+		baseline = m.UpdatedAt
+	}
+	if state, err := e.store.GetMonitorState(ctx, m.ID); err == nil && state != nil && state.LastCheckedAt != nil {
+		baseline = *state.LastCheckedAt
+	}
+	interval := m.IntervalSec
+	if interval <= 0 {
+		interval = 60
+	}
+	deadline := baseline.Add(time.Duration(interval+PushGraceSec(m)) * time.Second)
+	return !now.Before(deadline)
+}
+
+func (e *Engine) recordMissedPush(ctx context.Context, m store.Monitor, settings store.MonitorSettings) error {
+	lock := e.pushLock(m.ID)
+	lock.Lock()
+	now := time.Now().UTC()
+	// A heartbeat may have arrived while the slot was being acquired.
+	if !e.pushOverdue(ctx, m, now) {
+		lock.Unlock()
+		return nil
+	}
+	automate, err := e.storeResult(ctx, m, CheckResult{
+		OK:        false,
+		Error:     "monitoring.error.pushMissed",
+		CheckedAt: now,
+	}, settings)
+	lock.Unlock()
+	if automate != nil {
+		automate()
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package monitoring
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"berkut-scc/core/store"
+)
+
+const (
+	// HourlyRollupRetentionDays bounds the hourly buckets; older history is served by the daily rollup.
+	HourlyRollupRetentionDays  = 90
+	DefaultRollupRetentionDays = 730
+	// MinRollupRetentionDays keeps the hourly buckets of the previous calendar month for its SLA period.
+	MinRollupRetentionDays = 62
+)
+
+func rollupRetentionDays(settings store.MonitorSettings) (int, int) {
+	daily := settings.RollupRetentionDays
+	if daily <= 0 {
+		daily = DefaultRollupRetentionDays
+	}
+	return min(HourlyRollupRetentionDays, daily), daily
+}
+
+func (e *Engine) pruneRollups(ctx context.Context, settings store.MonitorSettings, now time.Time) {
+	hourlyDays, dailyDays := rollupRetentionDays(settings)
+	cutoffs := []struct {
+		resolution string
+		days       int
+	}{
+		{store.RollupHourly, hourlyDays},
+		{store.RollupDaily, dailyDays},
+	}
+	for _, item := range cutoffs {
+		before := now.Add(-time.Duration(item.days) * 24 * time.Hour)
+		if _, err := e.store.DeleteMetricRollupsBefore(ctx, item.resolution, before); err != nil && e.logger != nil {
+			e.logger.Errorf("monitoring rollup retention %s: %v", item.resolution, err)
+		}
+	}
+}
+
+// settleSLARollups stores the SLA counts of the hourly buckets closed before now that are still backed by raw
+// points, since bounding the scan to the raw retention. Later SLA evaluations read these counts instead of the
+// raw points of hours touched by a maintenance or unreachable window.
+func (e *Engine) settleSLARollups(ctx context.Context, since, now time.Time) {
+	items, err := e.store.ListUnsettledSLARollups(ctx, since, now.UTC().Truncate(time.Hour))
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Errorf("monitoring rollup sla counts: %v", err)
+		}
+		return
+	}
+	monitors := make(map[int64]*store.Monitor)
+	for _, item := range items {
+		mon, ok := monitors[item.MonitorID]
+		if !ok {
+			mon, err = e.store.GetMonitor(ctx, item.MonitorID)
+			if err != nil {
+				if e.logger != nil {
+					e.logger.Errorf("monitoring rollup sla counts monitor %d: %v", item.MonitorID, err)
+				}
+				continue
+			}
+			monitors[item.MonitorID] = mon
+		}
+		if mon == nil {
+			continue
+		}
+		end := item.BucketStart.Add(time.Hour)
+		windows, err := e.slaWindows(ctx, *mon, item.BucketStart, end)
+		if err == nil {
+			var okCount, totalCount int
+			okCount, totalCount, err = e.rawSLACounts(ctx, mon.ID, item.BucketStart, end, windows)
+			if err == nil {
+				err = e.store.SetRollupSLACounts(ctx, mon.ID, item.BucketStart, okCount, totalCount)
+			}
+		}
+		if err != nil && e.logger != nil {
+			e.logger.Errorf("monitoring rollup sla counts monitor %d: %v", mon.ID, err)
+		}
+	}
+}
+
+// slaCounts counts the checks of [periodStart, periodEnd) that fall outside the excluded windows. Hours clear
+// of any window come from the hourly rollup and hours covered by one are skipped. Hours a window only touches
+// and the partial hours at the period edges use the SLA counts settled when the hour closed, scaled to the
+// part inside the period; raw points are read only for hours not settled yet.
+func (e *Engine) slaCounts(ctx context.Context, monitorID int64, periodStart, periodEnd time.Time, windows []store.MaintenanceWindow) (int, int, error) {
+	periodStart, periodEnd = periodStart.UTC(), periodEnd.UTC()
+	first := periodStart.Truncate(time.Hour)
+	rollups, err := e.store.ListMetricRollups(ctx, monitorID, store.RollupHourly, first, periodEnd)
+	if err != nil {
+		return 0, 0, err
+	}
+	byHour := make(map[int64]store.MonitorMetricRollup, len(rollups))
+	for _, item := range rollups {
+		byHour[item.BucketStart.Unix()] = item
+	}
+	okCount, totalCount := 0, 0
+	var rawSpans []store.MaintenanceWindow
+	for hour := first; hour.Before(periodEnd); hour = hour.Add(time.Hour) {
+		item, ok := byHour[hour.Unix()]
+		if !ok {
+			continue
+		}
+		from, to := hour, hour.Add(time.Hour)
+		if from.Before(periodStart) {
+			from = periodStart
+		}
+		if to.After(periodEnd) {
+			to = periodEnd
+		}
+		partial := to.Sub(from) < time.Hour
+		switch {
+		case coveredByWindows(from, to, windows):
+		case !partial && !overlapsWindows(from, to, windows):
+			totalCount += item.TotalCount
+			okCount += item.OKCount
+		case item.SLATotalCount != nil:
+			share := to.Sub(from).Seconds() / time.Hour.Seconds()
+			totalCount += int(math.Round(float64(*item.SLATotalCount) * share))
+			okCount += int(math.Round(float64(*item.SLAOKCount) * share))
+		default:
+			if n := len(rawSpans); n > 0 && rawSpans[n-1].End.Equal(from) {
+				rawSpans[n-1].End = to
+			} else {
+				rawSpans = append(rawSpans, store.MaintenanceWindow{Start: from, End: to})
+			}
+		}
+	}
+	for _, span := range rawSpans {
+		spanOK, spanTotal, err := e.rawSLACounts(ctx, monitorID, span.Start, span.End, windows)
+		if err != nil {
+			return 0, 0, err
+		}
+		okCount += spanOK
+		totalCount += spanTotal
+	}
+	return okCount, totalCount, nil
+}
+
+// rawSLACounts counts the raw points of [start, end) outside the windows.
+func (e *Engine) rawSLACounts(ctx context.Context, monitorID int64, start, end time.Time, windows []store.MaintenanceWindow) (int, int, error) {
+	metrics, err := e.store.ListMetricsBetween(ctx, monitorID, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	okCount, totalCount := 0, 0
+	for _, metric := range metrics {
+		if tsInsideWindows(metric.TS, windows) {
+			continue
+		}
+		totalCount++
+		if metric.OK {
+			okCount++
+		}
+	}
+	return okCount, totalCount, nil
+}
+
+// coveredByWindows reports whether [start, end) lies inside a single window; windows are expected merged.
+func coveredByWindows(start, end time.Time, windows []store.MaintenanceWindow) bool {
+	for _, item := range windows {
+		if !item.Start.After(start) && !item.End.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsWindows(start, end time.Time, windows []store.MaintenanceWindow) bool {
+	for _, item := range windows {
+		if item.Start.Before(end) && item.End.After(start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package monitoring
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"berkut-scc/config"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func newRollupTestStore(t *testing.T) store.MonitoringStore {
+	t.Helper()
+	cfg := &config.AppConfig{DBPath: filepath.Join(t.TempDir(), "monitoring.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := store.ApplyMigrations(context.Background(), db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return store.NewMonitoringStore(db)
+}
+
+func TestSLACountsOutliveRawPoints(t *testing.T) {
+	ms := newRollupTestStore(t)
+	ctx := context.Background()
+	mon := &store.Monitor{Name: "SLA", Type: "tcp", Host: "example.com", Port: 443, IntervalSec: 600, TimeoutSec: 2, IsActive: true}
+	id, err := ms.CreateMonitor(ctx, mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	mon.ID = id
+	day := startOfUTCDay(time.Now()).Add(-3 * 24 * time.Hour)
+	base := day.Add(10 * time.Hour)
+	if _, err := ms.CreateMaintenance(ctx, &store.MonitorMaintenance{
+		Name: "Window", MonitorID: &id, StartsAt: base.Add(30 * time.Minute), EndsAt: base.Add(time.Hour), IsActive: true,
+	}); err != nil {
+		t.Fatalf("create maintenance: %v", err)
+	}
+	points := []store.MonitorMetric{
+		{MonitorID: id, TS: base.Add(5 * time.Minute), LatencyMs: 100, OK: true},
+		{MonitorID: id, TS: base.Add(15 * time.Minute), OK: false},
+		{MonitorID: id, TS: base.Add(35 * time.Minute), OK: false},
+		{MonitorID: id, TS: base.Add(45 * time.Minute), LatencyMs: 100, OK: true},
+		{MonitorID: id, TS: base.Add(65 * time.Minute), LatencyMs: 100, OK: true},
+	}
+	for i := range points {
+		if _, err := ms.AddMetric(ctx, &points[i]); err != nil {
+			t.Fatalf("add metric: %v", err)
+		}
+	}
+	e := NewEngine(ms, utils.NewLogger())
+	settings := store.MonitorSettings{DefaultIntervalSec: 600, DefaultSLATargetPct: 50}
+	policy := store.MonitorSLAPolicy{MonitorID: id}
+	before, err := e.EvaluateMonitorSLAWindow(ctx, *mon, policy, settings, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("sla: %v", err)
+	}
+	if before.UptimePct != 66.67 {
+		t.Fatalf("expected the checks inside maintenance to be left out, got %+v", before)
+	}
+
+	e.settleSLARollups(ctx, day, time.Now().UTC())
+	hourly, err := ms.ListMetricRollups(ctx, id, store.RollupHourly, base, base.Add(time.Hour))
+	if err != nil || len(hourly) != 1 || hourly[0].SLATotalCount == nil || *hourly[0].SLATotalCount != 2 || *hourly[0].SLAOKCount != 1 {
+		t.Fatalf("expected the touched hour to be settled, got %+v err=%v", hourly, err)
+	}
+	if _, err := ms.DeleteMetricsBefore(ctx, time.Now().UTC()); err != nil {
+		t.Fatalf("retention delete: %v", err)
+	}
+	after, err := e.EvaluateMonitorSLAWindow(ctx, *mon, policy, settings, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("sla: %v", err)
+	}
+	if after != before {
+		t.Fatalf("expected the SLA to survive the raw retention, got %+v before %+v", after, before)
+	}
+}
@@ -0,0 +1,113 @@
+package monitoring
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// errAuthRejected is returned when the server refuses the supplied credentials.
+var errAuthRejected = errors.New("authentication rejected")
+
+const scramMaxIterations = 1 << 20
+
+// scramClient implements the client side of SCRAM (RFC 5802) without channel binding.
+type scramClient struct {
+	newHash         func() hash.Hash
+	username        string
+	password        string
+	nonce           string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func newSCRAMClient(newHash func() hash.Hash, username, password string) (*scramClient, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	return &scramClient{
+		newHash:  newHash,
+		username: username,
+		password: password,
+		nonce:    base64.RawStdEncoding.EncodeToString(raw),
+	}, nil
+}
+
+func (c *scramClient) clientFirst() []byte {
+	escaped := strings.NewReplacer("=", "=3D", ",", "=2C").Replace(c.username)
+	c.clientFirstBare = "n=" + escaped + ",r=" + c.nonce
+	return []byte("n,," + c.clientFirstBare)
+}
+
+func (c *scramClient) clientFinal(serverFirst []byte) ([]byte, error) {
+	attrs := parseSCRAMAttributes(string(serverFirst))
+	if msg, ok := attrs["e"]; ok {
+		return nil, fmt.Errorf("%w: %s", errAuthRejected, msg)
+	}
+	serverNonce := attrs["r"]
+	if !strings.HasPrefix(serverNonce, c.nonce) || len(serverNonce) == len(c.nonce) {
+		return nil, ErrProtocol
+	}
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	if err != nil || len(salt) == 0 {
+		return nil, ErrProtocol
+	}
+	iterations, err := strconv.Atoi(attrs["i"])
+	if err != nil || iterations <= 0 || iterations > scramMaxIterations {
+		return nil, ErrProtocol
+	}
+	salted, err := pbkdf2.Key(c.newHash, c.password, salt, iterations, c.newHash().Size())
+	if err != nil {
+		return nil, err
+	}
+	withoutProof := "c=biws,r=" + serverNonce
+	authMessage := c.clientFirstBare + "," + string(serverFirst) + "," + withoutProof
+	clientKey := scramHMAC(c.newHash, salted, "Client Key")
+	h := c.newHash()
+	h.Write(clientKey)
+	storedKey := h.Sum(nil)
+	signature := scramHMAC(c.newHash, storedKey, authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ signature[i]
+	}
+	c.serverSignature = scramHMAC(c.newHash, scramHMAC(c.newHash, salted, "Server Key"), authMessage)
+	return []byte(withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+// verifyServerFinal checks the server signature so a spoofed server cannot pass the check.
+func (c *scramClient) verifyServerFinal(serverFinal []byte) error {
+	attrs := parseSCRAMAttributes(string(serverFinal))
+	if msg, ok := attrs["e"]; ok {
+		return fmt.Errorf("%w: %s", errAuthRejected, msg)
+	}
+	got, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || len(c.serverSignature) == 0 || !hmac.Equal(got, c.serverSignature) {
+		return ErrProtocol
+	}
+	return nil
+}
+
+func scramHMAC(newHash func() hash.Hash, key []byte, msg string) []byte {
+	mac := hmac.New(newHash, key)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
+
+func parseSCRAMAttributes(raw string) map[string]string {
+	out := map[string]string{}
+	for _, part := range strings.Split(raw, ",") {
+		if len(part) < 2 || part[1] != '=' {
+			continue
+		}
+		out[part[:1]] = part[2:]
+	}
+	return out
+}
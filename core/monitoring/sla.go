@@ -105,11 +105,7 @@ func (e *Engine) evaluateSLAPeriod(ctx context.Context, settings store.MonitorSe
 }
 
 func (e *Engine) EvaluateMonitorSLAWindow(ctx context.Context, monitor store.Monitor, policy store.MonitorSLAPolicy, settings store.MonitorSettings, periodStart, periodEnd time.Time) (SLAEvaluation, error) {
-	metrics, err := e.store.ListMetrics(ctx, monitor.ID, periodStart)
-	if err != nil {
-		return SLAEvaluation{}, err
-	}
-	windows, err := e.store.MaintenanceWindowsFor(ctx, monitor.ID, monitor.Tags, periodStart, periodEnd)
+	windows, err := e.slaWindows(ctx, monitor, periodStart, periodEnd)
 	if err != nil {
 		return SLAEvaluation{}, err
 	}
@@ -134,19 +130,9 @@ func (e *Engine) EvaluateMonitorSLAWindow(ctx context.Context, monitor store.Mon
 	if expectedChecks < 1 {
 		expectedChecks = 1
 	}
-	okCount := 0
-	totalCount := 0
-	for _, metric := range metrics {
-		if metric.TS.Before(periodStart) || !metric.TS.Before(periodEnd) {
-			continue
-		}
-		if tsInsideWindows(metric.TS, windows) {
-			continue
-		}
-		totalCount++
-		if metric.OK {
-			okCount++
-		}
+	okCount, totalCount, err := e.slaCounts(ctx, monitor.ID, periodStart, periodEnd, windows)
+	if err != nil {
+		return SLAEvaluation{}, err
 	}
 	coverage := (float64(totalCount) / float64(expectedChecks)) * 100.0
 	if coverage > 100 {
@@ -172,6 +158,20 @@ func (e *Engine) EvaluateMonitorSLAWindow(ctx context.Context, monitor store.Mon
 	}, nil
 }
 
+// slaWindows lists the spans of [start, end) left out of the SLA: maintenance and, like it, the time spent
+// unreachable behind a down parent.
+func (e *Engine) slaWindows(ctx context.Context, monitor store.Monitor, start, end time.Time) ([]store.MaintenanceWindow, error) {
+	windows, err := e.store.MaintenanceWindowsFor(ctx, monitor.ID, monitor.Tags, start, end)
+	if err != nil {
+		return nil, err
+	}
+	unreachable, err := e.store.UnreachableWindowsFor(ctx, monitor.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return store.MergeMaintenanceWindows(append(windows, unreachable...)), nil
+}
+
 func tsInsideWindows(ts time.Time, windows []store.MaintenanceWindow) bool {
 	for _, item := range windows {
 		if (ts.After(item.Start) || ts.Equal(item.Start)) && ts.Before(item.End) {
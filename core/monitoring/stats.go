@@ -0,0 +1,61 @@
+package monitoring
+
+import "sort"
+
+// EngineStats is a point-in-time view of the scheduler for the metrics exporter.
+type EngineStats struct {
+	Running           bool
+	QueueDepth        int
+	InFlight          int
+	SemaphoreCapacity int
+	SemaphoreInUse    int
+	Deliveries        []DeliveryCount
+}
+
+// DeliveryCount is the number of notification deliveries logged since start for one event type and outcome.
+type DeliveryCount struct {
+	EventType string
+	Status    string
+	Count     int64
+}
+
+type deliveryKey struct {
+	eventType string
+	status    string
+}
+
+func (e *Engine) Stats() EngineStats {
+	if e == nil {
+		return EngineStats{}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := EngineStats{
+		Running:           e.running,
+		QueueDepth:        e.queueDepth,
+		InFlight:          len(e.inFlight),
+		SemaphoreCapacity: e.maxConcurrent,
+	}
+	if e.sem != nil {
+		stats.SemaphoreInUse = len(e.sem)
+	}
+	for key, count := range e.deliveries {
+		stats.Deliveries = append(stats.Deliveries, DeliveryCount{EventType: key.eventType, Status: key.status, Count: count})
+	}
+	sort.Slice(stats.Deliveries, func(i, j int) bool {
+		if stats.Deliveries[i].EventType != stats.Deliveries[j].EventType {
+			return stats.Deliveries[i].EventType < stats.Deliveries[j].EventType
+		}
+		return stats.Deliveries[i].Status < stats.Deliveries[j].Status
+	})
+	return stats
+}
+
+func (e *Engine) countDelivery(eventType, status string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.deliveries == nil {
+		e.deliveries = map[deliveryKey]int64{}
+	}
+	e.deliveries[deliveryKey{eventType: eventType, status: status}]++
+}
@@ -3,26 +3,34 @@ package monitoring
 import "strings"
 
 const (
-	TypeHTTP          = "http"
-	TypeTCP           = "tcp"
-	TypePing          = "ping"
-	TypeHTTPKeyword   = "http_keyword"
-	TypeHTTPJSON      = "http_json"
-	TypeGRPCKeyword   = "grpc_keyword"
-	TypeDNS           = "dns"
-	TypeDocker        = "docker"
-	TypePush          = "push"
-	TypeSteam         = "steam"
-	TypeGameDig       = "gamedig"
-	TypeMQTT          = "mqtt"
-	TypeKafkaProducer = "kafka_producer"
-	TypeMSSQL         = "mssql"
-	TypePostgres      = "postgres"
-	TypeMySQL         = "mysql"
-	TypeMongoDB       = "mongodb"
-	TypeRadius        = "radius"
-	TypeRedis         = "redis"
-	TypeTailscalePing = "tailscale_ping"
+	TypeHTTP            = "http"
+	TypeTCP             = "tcp"
+	TypePing            = "ping"
+	TypeHTTPKeyword     = "http_keyword"
+	TypeHTTPJSON        = "http_json"
+	TypeGRPCKeyword     = "grpc_keyword"
+	TypeDNS             = "dns"
+	TypeDocker          = "docker"
+	TypePush            = "push"
+	TypeSteam           = "steam"
+	TypeGameDig         = "gamedig"
+	TypeMQTT            = "mqtt"
+	TypeKafkaProducer   = "kafka_producer"
+	TypeMSSQL           = "mssql"
+	TypePostgres        = "postgres"
+	TypeMySQL           = "mysql"
+	TypeMongoDB         = "mongodb"
+	TypeRadius          = "radius"
+	TypeRedis           = "redis"
+	TypeTailscalePing   = "tailscale_ping"
+	TypeHTTPTransaction = "http_transaction"
+	TypeSMTP            = "smtp"
+	TypeIMAP            = "imap"
+	TypePOP3            = "pop3"
+	TypeSSH             = "ssh"
+	TypeLDAP            = "ldap"
+	TypeTLS             = "tls"
+	TypePrometheus      = "prometheus"
 )
 
 func NormalizeType(raw string) string {
@@ -33,7 +41,8 @@ func IsSupportedType(raw string) bool {
 	switch NormalizeType(raw) {
 	case TypeHTTP, TypeTCP, TypePing, TypeHTTPKeyword, TypeHTTPJSON, TypeGRPCKeyword, TypeDNS,
 		TypeDocker, TypePush, TypeSteam, TypeGameDig, TypeMQTT, TypeKafkaProducer, TypeMSSQL,
-		TypePostgres, TypeMySQL, TypeMongoDB, TypeRadius, TypeRedis, TypeTailscalePing:
+		TypePostgres, TypeMySQL, TypeMongoDB, TypeRadius, TypeRedis, TypeTailscalePing, TypeHTTPTransaction,
+		TypeSMTP, TypeIMAP, TypePOP3, TypeSSH, TypeLDAP, TypeTLS, TypePrometheus:
 		return true
 	default:
 		return false
@@ -42,7 +51,7 @@ func IsSupportedType(raw string) bool {
 
 func IsHTTPType(raw string) bool {
 	switch NormalizeType(raw) {
-	case TypeHTTP, TypeHTTPKeyword, TypeHTTPJSON:
+	case TypeHTTP, TypeHTTPKeyword, TypeHTTPJSON, TypeHTTPTransaction:
 		return true
 	default:
 		return false
@@ -51,7 +60,7 @@ func IsHTTPType(raw string) bool {
 
 func TypeUsesURL(raw string) bool {
 	switch NormalizeType(raw) {
-	case TypeHTTP, TypeHTTPKeyword, TypeHTTPJSON, TypePostgres, TypeGRPCKeyword:
+	case TypeHTTP, TypeHTTPKeyword, TypeHTTPJSON, TypeHTTPTransaction, TypePostgres, TypeGRPCKeyword, TypePrometheus:
 		return true
 	default:
 		return false
@@ -61,7 +70,8 @@ func TypeUsesURL(raw string) bool {
 func TypeUsesHostPort(raw string) bool {
 	switch NormalizeType(raw) {
 	case TypeTCP, TypePing, TypeDNS, TypeDocker, TypeSteam, TypeGameDig, TypeMQTT, TypeKafkaProducer,
-		TypeMSSQL, TypeMySQL, TypeMongoDB, TypeRadius, TypeRedis, TypeTailscalePing:
+		TypeMSSQL, TypeMySQL, TypeMongoDB, TypeRadius, TypeRedis, TypeTailscalePing, TypeSMTP, TypeIMAP, TypePOP3,
+		TypeSSH, TypeLDAP, TypeTLS:
 		return true
 	default:
 		return false
@@ -74,7 +84,8 @@ func TypeIsPassive(raw string) bool {
 
 func TypeSupportsTLSMetadata(raw string) bool {
 	switch NormalizeType(raw) {
-	case TypeHTTP, TypeHTTPKeyword, TypeHTTPJSON, TypeGRPCKeyword:
+	case TypeHTTP, TypeHTTPKeyword, TypeHTTPJSON, TypeHTTPTransaction, TypeGRPCKeyword, TypeMySQL, TypeMSSQL, TypeMongoDB, TypeRedis, TypeMQTT, TypeKafkaProducer, TypeDocker,
+		TypeSMTP, TypeIMAP, TypePOP3, TypeLDAP, TypeTLS, TypePrometheus:
 		return true
 	default:
 		return false
@@ -105,6 +116,18 @@ func DefaultPortForType(raw string) int {
 		return 27015
 	case TypeGameDig:
 		return 27015
+	case TypeSMTP:
+		return 25
+	case TypeIMAP:
+		return 143
+	case TypePOP3:
+		return 110
+	case TypeSSH:
+		return 22
+	case TypeLDAP:
+		return 389
+	case TypeTLS:
+		return 443
 	default:
 		return 0
 	}
@@ -201,6 +201,7 @@ type IncidentsStore interface {
 	ListIncidentTimeline(ctx context.Context, incidentID int64, limit int, eventType string) ([]IncidentTimelineEvent, error)
 	AddIncidentTimeline(ctx context.Context, ev *IncidentTimelineEvent) (int64, error)
 	FindOpenIncidentBySource(ctx context.Context, source string, refID int64) (*Incident, error)
+	CountOpenIncidentsBySeverity(ctx context.Context) (map[string]int, error)
 }
 
 type incidentsStore struct {
@@ -375,6 +376,27 @@ func (s *incidentsStore) FindOpenIncidentBySource(ctx context.Context, source st
 	return s.scanIncident(row)
 }
 
+func (s *incidentsStore) CountOpenIncidentsBySeverity(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT severity, COUNT(*) FROM incidents
+		WHERE deleted_at IS NULL AND status!='closed'
+		GROUP BY severity`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]int{}
+	for rows.Next() {
+		var severity string
+		var count int
+		if err := rows.Scan(&severity, &count); err != nil {
+			return nil, err
+		}
+		out[severity] += count
+	}
+	return out, rows.Err()
+}
+
 func (s *incidentsStore) ListIncidents(ctx context.Context, filter IncidentFilter) ([]Incident, error) {
 	var clauses []string
 	var args []any
@@ -591,11 +591,16 @@ var migrations = []string{
 		is_paused INTEGER NOT NULL DEFAULT 0,
 		tags_json TEXT NOT NULL DEFAULT '[]',
 		group_id INTEGER,
+		parent_id INTEGER,
 		sla_target_pct REAL,
 		auto_incident INTEGER NOT NULL DEFAULT 0,
 		auto_task_on_down INTEGER NOT NULL DEFAULT 0,
 		incident_severity TEXT NOT NULL DEFAULT 'low',
 		incident_type_id TEXT NOT NULL DEFAULT '',
+		push_token_hash TEXT NOT NULL DEFAULT '',
+		push_grace_sec INTEGER NOT NULL DEFAULT 0,
+		options_json TEXT NOT NULL DEFAULT '{}',
+		credentials_enc BLOB,
 		created_by INTEGER,
 		created_at TIMESTAMP NOT NULL,
 		updated_at TIMESTAMP NOT NULL
@@ -616,6 +621,9 @@ var migrations = []string{
 		avg_latency_24h REAL NOT NULL DEFAULT 0,
 		tls_days_left INTEGER,
 		tls_not_after TIMESTAMP,
+		server_version TEXT NOT NULL DEFAULT '',
+		server_role TEXT NOT NULL DEFAULT '',
+		details_json TEXT NOT NULL DEFAULT '{}',
 		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
 	);`,
 	`CREATE TABLE IF NOT EXISTS monitor_metrics (
@@ -626,6 +634,14 @@ var migrations = []string{
 		ok INTEGER NOT NULL DEFAULT 0,
 		status_code INTEGER,
 		error TEXT,
+		packets_sent INTEGER,
+		packets_received INTEGER,
+		packet_loss_pct REAL,
+		rtt_min_ms REAL,
+		rtt_avg_ms REAL,
+		rtt_max_ms REAL,
+		jitter_ms REAL,
+		values_json TEXT,
 		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
 	);`,
 	`CREATE TABLE IF NOT EXISTS monitor_events (
@@ -646,6 +662,83 @@ var migrations = []string{
 		san_json TEXT NOT NULL DEFAULT '[]',
 		fingerprint_sha256 TEXT NOT NULL DEFAULT '',
 		last_error TEXT,
+		grade TEXT NOT NULL DEFAULT '',
+		security_json TEXT NOT NULL DEFAULT '',
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`,
+	`CREATE TABLE IF NOT EXISTS monitor_content (
+		monitor_id INTEGER PRIMARY KEY,
+		baseline_hash TEXT NOT NULL DEFAULT '',
+		baseline_gz BLOB,
+		baseline_at TIMESTAMP NOT NULL,
+		accepted_by TEXT NOT NULL DEFAULT '',
+		current_hash TEXT NOT NULL DEFAULT '',
+		current_gz BLOB,
+		checked_at TIMESTAMP NOT NULL,
+		changed_at TIMESTAMP,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`,
+	`CREATE TABLE IF NOT EXISTS monitoring_probes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		location TEXT NOT NULL DEFAULT '',
+		monitor_id INTEGER,
+		enrol_token_hash TEXT NOT NULL DEFAULT '',
+		enrol_expires_at TIMESTAMP,
+		secret_enc BLOB,
+		version TEXT NOT NULL DEFAULT '',
+		remote_addr TEXT NOT NULL DEFAULT '',
+		enrolled_at TIMESTAMP,
+		last_seen_at TIMESTAMP,
+		created_by INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE SET NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS monitor_probe_results (
+		monitor_id INTEGER NOT NULL,
+		probe_id INTEGER NOT NULL,
+		ok INTEGER NOT NULL,
+		status_code INTEGER,
+		latency_ms INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		checked_at TIMESTAMP NOT NULL,
+		payload_json TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY(monitor_id, probe_id),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`,
+	`CREATE TABLE IF NOT EXISTS monitor_metrics_hourly (
+		monitor_id INTEGER NOT NULL,
+		bucket_start TIMESTAMP NOT NULL,
+		total_count INTEGER NOT NULL DEFAULT 0,
+		ok_count INTEGER NOT NULL DEFAULT 0,
+		latency_sum_ms INTEGER NOT NULL DEFAULT 0,
+		latency_min_ms INTEGER,
+		latency_p95_ms INTEGER,
+		latency_max_ms INTEGER,
+		sla_total_count INTEGER,
+		sla_ok_count INTEGER,
+		PRIMARY KEY(monitor_id, bucket_start),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`,
+	`CREATE TABLE IF NOT EXISTS monitor_metrics_daily (
+		monitor_id INTEGER NOT NULL,
+		bucket_start TIMESTAMP NOT NULL,
+		total_count INTEGER NOT NULL DEFAULT 0,
+		ok_count INTEGER NOT NULL DEFAULT 0,
+		latency_sum_ms INTEGER NOT NULL DEFAULT 0,
+		latency_min_ms INTEGER,
+		latency_p95_ms INTEGER,
+		latency_max_ms INTEGER,
+		PRIMARY KEY(monitor_id, bucket_start),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`,
+	`CREATE TABLE IF NOT EXISTS monitor_unreachable_windows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		monitor_id INTEGER NOT NULL,
+		parent_id INTEGER,
+		started_at TIMESTAMP NOT NULL,
+		ended_at TIMESTAMP,
 		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
 	);`,
 	`CREATE TABLE IF NOT EXISTS monitor_maintenance (
@@ -690,6 +783,9 @@ var migrations = []string{
 		default_retries INTEGER NOT NULL DEFAULT 2,
 		default_retry_interval_sec INTEGER NOT NULL DEFAULT 30,
 		default_sla_target_pct REAL NOT NULL DEFAULT 90,
+		rollup_retention_days INTEGER NOT NULL DEFAULT 730,
+		allowed_networks_json TEXT NOT NULL DEFAULT '[]',
+		blocked_networks_json TEXT NOT NULL DEFAULT '[]',
 		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);`,
 	`CREATE TABLE IF NOT EXISTS notification_channels (
@@ -763,8 +859,11 @@ var migrations = []string{
 	`CREATE INDEX IF NOT EXISTS idx_monitors_name ON monitors(name);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_metrics_monitor_ts ON monitor_metrics(monitor_id, ts);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_events_monitor_ts ON monitor_events(monitor_id, ts);`,
+	`CREATE INDEX IF NOT EXISTS idx_monitor_metrics_hourly_bucket ON monitor_metrics_hourly(bucket_start);`,
+	`CREATE INDEX IF NOT EXISTS idx_monitor_metrics_daily_bucket ON monitor_metrics_daily(bucket_start);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_tls_checked ON monitor_tls(checked_at);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_maintenance_window ON monitor_maintenance(starts_at, ends_at);`,
+	`CREATE INDEX IF NOT EXISTS idx_monitor_unreachable_windows_monitor ON monitor_unreachable_windows(monitor_id, started_at);`,
 	`CREATE INDEX IF NOT EXISTS idx_notification_channels_default ON notification_channels(is_default, is_active);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_notification_deliveries_created ON monitor_notification_deliveries(created_at);`,
 	`CREATE INDEX IF NOT EXISTS idx_monitor_notification_deliveries_status ON monitor_notification_deliveries(status, acknowledged_at);`,
@@ -1106,14 +1205,31 @@ func ensureMonitoringColumns(ctx context.Context, db *sql.DB) error {
 	}
 	cols := []col{
 		{Table: "monitors", Name: "sla_target_pct", SQL: "ALTER TABLE monitors ADD COLUMN sla_target_pct REAL"},
+		{Table: "monitors", Name: "parent_id", SQL: "ALTER TABLE monitors ADD COLUMN parent_id INTEGER"},
 		{Table: "monitors", Name: "auto_incident", SQL: "ALTER TABLE monitors ADD COLUMN auto_incident INTEGER NOT NULL DEFAULT 0"},
 		{Table: "monitors", Name: "auto_task_on_down", SQL: "ALTER TABLE monitors ADD COLUMN auto_task_on_down INTEGER NOT NULL DEFAULT 0"},
 		{Table: "monitors", Name: "incident_severity", SQL: "ALTER TABLE monitors ADD COLUMN incident_severity TEXT NOT NULL DEFAULT 'low'"},
 		{Table: "monitors", Name: "incident_type_id", SQL: "ALTER TABLE monitors ADD COLUMN incident_type_id TEXT NOT NULL DEFAULT ''"},
+		{Table: "monitors", Name: "push_token_hash", SQL: "ALTER TABLE monitors ADD COLUMN push_token_hash TEXT NOT NULL DEFAULT ''"},
+		{Table: "monitors", Name: "push_grace_sec", SQL: "ALTER TABLE monitors ADD COLUMN push_grace_sec INTEGER NOT NULL DEFAULT 0"},
+		{Table: "monitors", Name: "options_json", SQL: "ALTER TABLE monitors ADD COLUMN options_json TEXT NOT NULL DEFAULT '{}'"},
+		{Table: "monitors", Name: "credentials_enc", SQL: "ALTER TABLE monitors ADD COLUMN credentials_enc BLOB"},
+		{Table: "monitor_metrics", Name: "packets_sent", SQL: "ALTER TABLE monitor_metrics ADD COLUMN packets_sent INTEGER"},
+		{Table: "monitor_metrics", Name: "packets_received", SQL: "ALTER TABLE monitor_metrics ADD COLUMN packets_received INTEGER"},
+		{Table: "monitor_metrics", Name: "packet_loss_pct", SQL: "ALTER TABLE monitor_metrics ADD COLUMN packet_loss_pct REAL"},
+		{Table: "monitor_metrics", Name: "rtt_min_ms", SQL: "ALTER TABLE monitor_metrics ADD COLUMN rtt_min_ms REAL"},
+		{Table: "monitor_metrics", Name: "rtt_avg_ms", SQL: "ALTER TABLE monitor_metrics ADD COLUMN rtt_avg_ms REAL"},
+		{Table: "monitor_metrics", Name: "rtt_max_ms", SQL: "ALTER TABLE monitor_metrics ADD COLUMN rtt_max_ms REAL"},
+		{Table: "monitor_metrics", Name: "jitter_ms", SQL: "ALTER TABLE monitor_metrics ADD COLUMN jitter_ms REAL"},
+		{Table: "monitor_metrics", Name: "values_json", SQL: "ALTER TABLE monitor_metrics ADD COLUMN values_json TEXT"},
 		{Table: "monitor_state", Name: "last_result_status", SQL: "ALTER TABLE monitor_state ADD COLUMN last_result_status TEXT NOT NULL DEFAULT ''"},
 		{Table: "monitor_state", Name: "maintenance_active", SQL: "ALTER TABLE monitor_state ADD COLUMN maintenance_active INTEGER NOT NULL DEFAULT 0"},
+		{Table: "monitor_state", Name: "unreachable", SQL: "ALTER TABLE monitor_state ADD COLUMN unreachable INTEGER NOT NULL DEFAULT 0"},
 		{Table: "monitor_state", Name: "tls_days_left", SQL: "ALTER TABLE monitor_state ADD COLUMN tls_days_left INTEGER"},
 		{Table: "monitor_state", Name: "tls_not_after", SQL: "ALTER TABLE monitor_state ADD COLUMN tls_not_after TIMESTAMP"},
+		{Table: "monitor_state", Name: "server_version", SQL: "ALTER TABLE monitor_state ADD COLUMN server_version TEXT NOT NULL DEFAULT ''"},
+		{Table: "monitor_state", Name: "server_role", SQL: "ALTER TABLE monitor_state ADD COLUMN server_role TEXT NOT NULL DEFAULT ''"},
+		{Table: "monitor_state", Name: "details_json", SQL: "ALTER TABLE monitor_state ADD COLUMN details_json TEXT NOT NULL DEFAULT '{}'"},
 		{Table: "monitoring_settings", Name: "tls_refresh_hours", SQL: "ALTER TABLE monitoring_settings ADD COLUMN tls_refresh_hours INTEGER NOT NULL DEFAULT 24"},
 		{Table: "monitoring_settings", Name: "tls_expiring_days", SQL: "ALTER TABLE monitoring_settings ADD COLUMN tls_expiring_days INTEGER NOT NULL DEFAULT 30"},
 		{Table: "monitoring_settings", Name: "notify_suppress_minutes", SQL: "ALTER TABLE monitoring_settings ADD COLUMN notify_suppress_minutes INTEGER NOT NULL DEFAULT 5"},
@@ -1123,11 +1239,14 @@ func ensureMonitoringColumns(ctx context.Context, db *sql.DB) error {
 		{Table: "monitoring_settings", Name: "auto_tls_incident", SQL: "ALTER TABLE monitoring_settings ADD COLUMN auto_tls_incident INTEGER NOT NULL DEFAULT 1"},
 		{Table: "monitoring_settings", Name: "auto_tls_incident_days", SQL: "ALTER TABLE monitoring_settings ADD COLUMN auto_tls_incident_days INTEGER NOT NULL DEFAULT 14"},
 		{Table: "monitoring_settings", Name: "auto_incident_close_on_up", SQL: "ALTER TABLE monitoring_settings ADD COLUMN auto_incident_close_on_up INTEGER NOT NULL DEFAULT 0"},
+		{Table: "monitoring_settings", Name: "allowed_networks_json", SQL: "ALTER TABLE monitoring_settings ADD COLUMN allowed_networks_json TEXT NOT NULL DEFAULT '[]'"},
+		{Table: "monitoring_settings", Name: "blocked_networks_json", SQL: "ALTER TABLE monitoring_settings ADD COLUMN blocked_networks_json TEXT NOT NULL DEFAULT '[]'"},
 		{Table: "monitors", Name: "ignore_tls_errors", SQL: "ALTER TABLE monitors ADD COLUMN ignore_tls_errors INTEGER NOT NULL DEFAULT 0"},
 		{Table: "monitors", Name: "notify_tls_expiring", SQL: "ALTER TABLE monitors ADD COLUMN notify_tls_expiring INTEGER NOT NULL DEFAULT 1"},
 		{Table: "monitoring_settings", Name: "default_retries", SQL: "ALTER TABLE monitoring_settings ADD COLUMN default_retries INTEGER NOT NULL DEFAULT 2"},
 		{Table: "monitoring_settings", Name: "default_retry_interval_sec", SQL: "ALTER TABLE monitoring_settings ADD COLUMN default_retry_interval_sec INTEGER NOT NULL DEFAULT 30"},
 		{Table: "monitoring_settings", Name: "default_sla_target_pct", SQL: "ALTER TABLE monitoring_settings ADD COLUMN default_sla_target_pct REAL NOT NULL DEFAULT 90"},
+		{Table: "monitoring_settings", Name: "rollup_retention_days", SQL: "ALTER TABLE monitoring_settings ADD COLUMN rollup_retention_days INTEGER NOT NULL DEFAULT 730"},
 	}
 	for _, c := range cols {
 		exists, err := columnExists(ctx, db, c.Table, c.Name)
@@ -1151,6 +1270,8 @@ func ensureMonitoringColumns(ctx context.Context, db *sql.DB) error {
 		san_json TEXT NOT NULL DEFAULT '[]',
 		fingerprint_sha256 TEXT NOT NULL DEFAULT '',
 		last_error TEXT,
+		grade TEXT NOT NULL DEFAULT '',
+		security_json TEXT NOT NULL DEFAULT '',
 		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
 	);`); err != nil {
 		return err
@@ -1164,6 +1285,8 @@ func ensureMonitoringColumns(ctx context.Context, db *sql.DB) error {
 		{Table: "monitor_tls", Name: "san_json", SQL: "ALTER TABLE monitor_tls ADD COLUMN san_json TEXT NOT NULL DEFAULT '[]'"},
 		{Table: "monitor_tls", Name: "fingerprint_sha256", SQL: "ALTER TABLE monitor_tls ADD COLUMN fingerprint_sha256 TEXT NOT NULL DEFAULT ''"},
 		{Table: "monitor_tls", Name: "last_error", SQL: "ALTER TABLE monitor_tls ADD COLUMN last_error TEXT"},
+		{Table: "monitor_tls", Name: "grade", SQL: "ALTER TABLE monitor_tls ADD COLUMN grade TEXT NOT NULL DEFAULT ''"},
+		{Table: "monitor_tls", Name: "security_json", SQL: "ALTER TABLE monitor_tls ADD COLUMN security_json TEXT NOT NULL DEFAULT ''"},
 	}
 	for _, c := range monitorTLSCols {
 		exists, err := columnExists(ctx, db, c.Table, c.Name)
@@ -1300,9 +1423,114 @@ func ensureMonitoringColumns(ctx context.Context, db *sql.DB) error {
 	);`); err != nil {
 		return err
 	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS monitor_content (
+		monitor_id INTEGER PRIMARY KEY,
+		baseline_hash TEXT NOT NULL DEFAULT '',
+		baseline_gz BLOB,
+		baseline_at TIMESTAMP NOT NULL,
+		accepted_by TEXT NOT NULL DEFAULT '',
+		current_hash TEXT NOT NULL DEFAULT '',
+		current_gz BLOB,
+		checked_at TIMESTAMP NOT NULL,
+		changed_at TIMESTAMP,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS monitoring_probes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		location TEXT NOT NULL DEFAULT '',
+		monitor_id INTEGER,
+		enrol_token_hash TEXT NOT NULL DEFAULT '',
+		enrol_expires_at TIMESTAMP,
+		secret_enc BLOB,
+		version TEXT NOT NULL DEFAULT '',
+		remote_addr TEXT NOT NULL DEFAULT '',
+		enrolled_at TIMESTAMP,
+		last_seen_at TIMESTAMP,
+		created_by INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE SET NULL
+	);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS monitor_probe_results (
+		monitor_id INTEGER NOT NULL,
+		probe_id INTEGER NOT NULL,
+		ok INTEGER NOT NULL,
+		status_code INTEGER,
+		latency_ms INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		checked_at TIMESTAMP NOT NULL,
+		payload_json TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY(monitor_id, probe_id),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitoring_probes_enrol ON monitoring_probes(enrol_token_hash);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS monitor_metrics_hourly (
+		monitor_id INTEGER NOT NULL,
+		bucket_start TIMESTAMP NOT NULL,
+		total_count INTEGER NOT NULL DEFAULT 0,
+		ok_count INTEGER NOT NULL DEFAULT 0,
+		latency_sum_ms INTEGER NOT NULL DEFAULT 0,
+		latency_min_ms INTEGER,
+		latency_p95_ms INTEGER,
+		latency_max_ms INTEGER,
+		sla_total_count INTEGER,
+		sla_ok_count INTEGER,
+		PRIMARY KEY(monitor_id, bucket_start),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS monitor_metrics_daily (
+		monitor_id INTEGER NOT NULL,
+		bucket_start TIMESTAMP NOT NULL,
+		total_count INTEGER NOT NULL DEFAULT 0,
+		ok_count INTEGER NOT NULL DEFAULT 0,
+		latency_sum_ms INTEGER NOT NULL DEFAULT 0,
+		latency_min_ms INTEGER,
+		latency_p95_ms INTEGER,
+		latency_max_ms INTEGER,
+		PRIMARY KEY(monitor_id, bucket_start),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitor_metrics_hourly_bucket ON monitor_metrics_hourly(bucket_start);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitor_metrics_daily_bucket ON monitor_metrics_daily(bucket_start);`); err != nil {
+		return err
+	}
 	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitor_tls_checked ON monitor_tls(checked_at);`); err != nil {
 		return err
 	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitors_push_token_hash ON monitors(push_token_hash);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitors_parent ON monitors(parent_id);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS monitor_unreachable_windows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		monitor_id INTEGER NOT NULL,
+		parent_id INTEGER,
+		started_at TIMESTAMP NOT NULL,
+		ended_at TIMESTAMP,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);`); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitor_unreachable_windows_monitor ON monitor_unreachable_windows(monitor_id, started_at);`); err != nil {
+		return err
+	}
 	if _, err := db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_monitor_maintenance_window ON monitor_maintenance(starts_at, ends_at);`); err != nil {
 		return err
 	}
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE monitors ADD COLUMN IF NOT EXISTS push_token_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE monitors ADD COLUMN IF NOT EXISTS push_grace_sec INTEGER NOT NULL DEFAULT 0;
+UPDATE monitors
+SET push_token_hash=encode(sha256(convert_to(request_body, 'UTF8')), 'hex'), request_body=''
+WHERE type='push' AND request_body<>'' AND push_token_hash='';
+CREATE INDEX IF NOT EXISTS idx_monitors_push_token_hash ON monitors(push_token_hash);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_monitors_push_token_hash;
+ALTER TABLE monitors DROP COLUMN IF EXISTS push_grace_sec;
+ALTER TABLE monitors DROP COLUMN IF EXISTS push_token_hash;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE monitors ADD COLUMN IF NOT EXISTS options_json TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS packets_sent INTEGER;
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS packets_received INTEGER;
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS packet_loss_pct REAL;
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS rtt_min_ms REAL;
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS rtt_avg_ms REAL;
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS rtt_max_ms REAL;
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS jitter_ms REAL;
+
+-- +goose Down
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS jitter_ms;
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS rtt_max_ms;
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS rtt_avg_ms;
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS rtt_min_ms;
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS packet_loss_pct;
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS packets_received;
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS packets_sent;
+ALTER TABLE monitors DROP COLUMN IF EXISTS options_json;
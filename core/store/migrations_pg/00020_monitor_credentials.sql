@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE monitors ADD COLUMN IF NOT EXISTS credentials_enc BYTEA;
+ALTER TABLE monitor_state ADD COLUMN IF NOT EXISTS server_version TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE monitor_state DROP COLUMN IF EXISTS server_version;
+ALTER TABLE monitors DROP COLUMN IF EXISTS credentials_enc;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE monitor_state ADD COLUMN IF NOT EXISTS server_role TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE monitor_state DROP COLUMN IF EXISTS server_role;
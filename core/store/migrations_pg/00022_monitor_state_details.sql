@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE monitor_state ADD COLUMN IF NOT EXISTS details_json TEXT NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE monitor_state DROP COLUMN IF EXISTS details_json;
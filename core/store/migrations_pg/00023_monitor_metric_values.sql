@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE monitor_metrics ADD COLUMN IF NOT EXISTS values_json TEXT;
+
+-- +goose Down
+ALTER TABLE monitor_metrics DROP COLUMN IF EXISTS values_json;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE monitoring_settings ADD COLUMN IF NOT EXISTS allowed_networks_json TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE monitoring_settings ADD COLUMN IF NOT EXISTS blocked_networks_json TEXT NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE monitoring_settings DROP COLUMN IF EXISTS blocked_networks_json;
+ALTER TABLE monitoring_settings DROP COLUMN IF EXISTS allowed_networks_json;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE monitor_tls ADD COLUMN IF NOT EXISTS grade TEXT NOT NULL DEFAULT '';
+ALTER TABLE monitor_tls ADD COLUMN IF NOT EXISTS security_json TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE monitor_tls DROP COLUMN IF EXISTS security_json;
+ALTER TABLE monitor_tls DROP COLUMN IF EXISTS grade;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS monitor_content (
+		monitor_id INTEGER PRIMARY KEY,
+		baseline_hash TEXT NOT NULL DEFAULT '',
+		baseline_gz BYTEA,
+		baseline_at TIMESTAMP NOT NULL,
+		accepted_by TEXT NOT NULL DEFAULT '',
+		current_hash TEXT NOT NULL DEFAULT '',
+		current_gz BYTEA,
+		checked_at TIMESTAMP NOT NULL,
+		changed_at TIMESTAMP,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);
+
+-- +goose Down
+DROP TABLE IF EXISTS monitor_content;
@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS monitoring_probes (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		name TEXT NOT NULL,
+		location TEXT NOT NULL DEFAULT '',
+		monitor_id INTEGER,
+		enrol_token_hash TEXT NOT NULL DEFAULT '',
+		enrol_expires_at TIMESTAMP,
+		secret_enc BYTEA,
+		version TEXT NOT NULL DEFAULT '',
+		remote_addr TEXT NOT NULL DEFAULT '',
+		enrolled_at TIMESTAMP,
+		last_seen_at TIMESTAMP,
+		created_by INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE SET NULL
+	);
+CREATE INDEX IF NOT EXISTS idx_monitoring_probes_enrol ON monitoring_probes(enrol_token_hash);
+CREATE TABLE IF NOT EXISTS monitor_probe_results (
+		monitor_id INTEGER NOT NULL,
+		probe_id INTEGER NOT NULL,
+		ok INTEGER NOT NULL,
+		status_code INTEGER,
+		latency_ms INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		checked_at TIMESTAMP NOT NULL,
+		payload_json TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY(monitor_id, probe_id),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);
+
+-- +goose Down
+DROP TABLE IF EXISTS monitor_probe_results;
+DROP INDEX IF EXISTS idx_monitoring_probes_enrol;
+DROP TABLE IF EXISTS monitoring_probes;
@@ -0,0 +1,56 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS monitor_metrics_hourly (
+		monitor_id INTEGER NOT NULL,
+		bucket_start TIMESTAMP NOT NULL,
+		total_count INTEGER NOT NULL DEFAULT 0,
+		ok_count INTEGER NOT NULL DEFAULT 0,
+		latency_sum_ms BIGINT NOT NULL DEFAULT 0,
+		latency_min_ms INTEGER,
+		latency_p95_ms INTEGER,
+		latency_max_ms INTEGER,
+		sla_total_count INTEGER,
+		sla_ok_count INTEGER,
+		PRIMARY KEY(monitor_id, bucket_start),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);
+CREATE TABLE IF NOT EXISTS monitor_metrics_daily (
+		monitor_id INTEGER NOT NULL,
+		bucket_start TIMESTAMP NOT NULL,
+		total_count INTEGER NOT NULL DEFAULT 0,
+		ok_count INTEGER NOT NULL DEFAULT 0,
+		latency_sum_ms BIGINT NOT NULL DEFAULT 0,
+		latency_min_ms INTEGER,
+		latency_p95_ms INTEGER,
+		latency_max_ms INTEGER,
+		PRIMARY KEY(monitor_id, bucket_start),
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);
+CREATE INDEX IF NOT EXISTS idx_monitor_metrics_hourly_bucket ON monitor_metrics_hourly(bucket_start);
+CREATE INDEX IF NOT EXISTS idx_monitor_metrics_daily_bucket ON monitor_metrics_daily(bucket_start);
+ALTER TABLE monitoring_settings ADD COLUMN IF NOT EXISTS rollup_retention_days INTEGER NOT NULL DEFAULT 730;
+
+-- The p95 of the open hour and of every day is left to FinalizeMetricRollups once the bucket is closed.
+INSERT INTO monitor_metrics_hourly(monitor_id, bucket_start, total_count, ok_count, latency_sum_ms, latency_min_ms, latency_p95_ms, latency_max_ms)
+SELECT monitor_id, date_trunc('hour', ts), COUNT(*), SUM(CASE WHEN ok=1 THEN 1 ELSE 0 END),
+	COALESCE(SUM(CASE WHEN ok=1 THEN latency_ms ELSE 0 END), 0),
+	MIN(latency_ms) FILTER (WHERE ok=1),
+	CASE WHEN date_trunc('hour', ts) < date_trunc('hour', now() AT TIME ZONE 'UTC')
+		THEN percentile_disc(0.95) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE ok=1) END,
+	MAX(latency_ms) FILTER (WHERE ok=1)
+FROM monitor_metrics
+GROUP BY monitor_id, date_trunc('hour', ts)
+ON CONFLICT (monitor_id, bucket_start) DO NOTHING;
+
+INSERT INTO monitor_metrics_daily(monitor_id, bucket_start, total_count, ok_count, latency_sum_ms, latency_min_ms, latency_p95_ms, latency_max_ms)
+SELECT monitor_id, date_trunc('day', bucket_start), SUM(total_count), SUM(ok_count), SUM(latency_sum_ms),
+	MIN(latency_min_ms), NULL, MAX(latency_max_ms)
+FROM monitor_metrics_hourly
+GROUP BY monitor_id, date_trunc('day', bucket_start)
+ON CONFLICT (monitor_id, bucket_start) DO NOTHING;
+
+-- +goose Down
+ALTER TABLE monitoring_settings DROP COLUMN IF EXISTS rollup_retention_days;
+DROP INDEX IF EXISTS idx_monitor_metrics_daily_bucket;
+DROP INDEX IF EXISTS idx_monitor_metrics_hourly_bucket;
+DROP TABLE IF EXISTS monitor_metrics_daily;
+DROP TABLE IF EXISTS monitor_metrics_hourly;
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE monitors ADD COLUMN IF NOT EXISTS parent_id INTEGER;
+ALTER TABLE monitor_state ADD COLUMN IF NOT EXISTS unreachable INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_monitors_parent ON monitors(parent_id);
+CREATE TABLE IF NOT EXISTS monitor_unreachable_windows (
+		id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+		monitor_id INTEGER NOT NULL,
+		parent_id INTEGER,
+		started_at TIMESTAMP NOT NULL,
+		ended_at TIMESTAMP,
+		FOREIGN KEY(monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+	);
+CREATE INDEX IF NOT EXISTS idx_monitor_unreachable_windows_monitor ON monitor_unreachable_windows(monitor_id, started_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_monitor_unreachable_windows_monitor;
+DROP TABLE IF EXISTS monitor_unreachable_windows;
+DROP INDEX IF EXISTS idx_monitors_parent;
+ALTER TABLE monitor_state DROP COLUMN IF EXISTS unreachable;
+ALTER TABLE monitors DROP COLUMN IF EXISTS parent_id;
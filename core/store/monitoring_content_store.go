@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *monitoringStore) GetContent(ctx context.Context, monitorID int64) (*MonitorContent, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT monitor_id, baseline_hash, baseline_gz, baseline_at, accepted_by, current_hash, current_gz, checked_at, changed_at
+		FROM monitor_content WHERE monitor_id=?`, monitorID)
+	var content MonitorContent
+	var changedAt sql.NullTime
+	if err := row.Scan(&content.MonitorID, &content.BaselineHash, &content.Baseline, &content.BaselineAt, &content.AcceptedBy,
+		&content.CurrentHash, &content.Current, &content.CheckedAt, &changedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if changedAt.Valid {
+		content.ChangedAt = &changedAt.Time
+	}
+	return &content, nil
+}
+
+// CreateContent stores the first baseline of a monitor; a row written by a concurrent check is kept.
+func (s *monitoringStore) CreateContent(ctx context.Context, content *MonitorContent) error {
+	if content == nil {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO monitor_content(monitor_id, baseline_hash, baseline_gz, baseline_at, accepted_by, current_hash, current_gz, checked_at, changed_at)
+		VALUES(?,?,?,?,?,?,?,?,?)
+		ON CONFLICT (monitor_id) DO NOTHING`,
+		content.MonitorID, content.BaselineHash, content.Baseline, content.BaselineAt, content.AcceptedBy, content.CurrentHash, content.Current,
+		content.CheckedAt, content.ChangedAt)
+	return err
+}
+
+// RecordContentCheck stores the body seen by a check without touching the baseline columns, so a check
+// that overlaps with an accept cannot undo it. changed_at is set when the body first differs from the
+// baseline and cleared when it matches it again.
+func (s *monitoringStore) RecordContentCheck(ctx context.Context, monitorID int64, hash string, body []byte, checkedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE monitor_content
+		SET changed_at=CASE WHEN baseline_hash=? THEN NULL WHEN current_hash=? THEN changed_at ELSE ? END,
+			current_hash=?, current_gz=?, checked_at=?
+		WHERE monitor_id=?`,
+		hash, hash, checkedAt, hash, body, checkedAt, monitorID)
+	return err
+}
+
+// AcceptContentBaseline makes the last seen body the baseline only while it still has the given hash.
+// It reports whether the baseline was replaced.
+func (s *monitoringStore) AcceptContentBaseline(ctx context.Context, monitorID int64, hash, acceptedBy string, acceptedAt time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE monitor_content
+		SET baseline_hash=current_hash, baseline_gz=current_gz, baseline_at=?, accepted_by=?, changed_at=NULL
+		WHERE monitor_id=? AND current_hash=?`,
+		acceptedAt, acceptedBy, monitorID, hash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
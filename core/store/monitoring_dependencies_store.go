@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Event types marking in the event log when a monitor became unreachable because its parent was down and
+// when it stopped being so.
+const (
+	EventUnreachableStart = "unreachable_start"
+	EventUnreachableEnd   = "unreachable_end"
+)
+
+// OpenUnreachableWindow starts an unreachable period of the monitor behind the given parent unless one is
+// already open.
+func (s *monitoringStore) OpenUnreachableWindow(ctx context.Context, monitorID int64, parentID *int64, at time.Time) error {
+	var open int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM monitor_unreachable_windows WHERE monitor_id=? AND ended_at IS NULL`, monitorID).Scan(&open); err != nil {
+		return err
+	}
+	if open > 0 {
+		return nil
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO monitor_unreachable_windows(monitor_id, parent_id, started_at) VALUES(?,?,?)`, monitorID, parentID, at.UTC())
+	return err
+}
+
+// CloseUnreachableWindow ends the open unreachable period of the monitor, if any.
+func (s *monitoringStore) CloseUnreachableWindow(ctx context.Context, monitorID int64, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE monitor_unreachable_windows SET ended_at=? WHERE monitor_id=? AND ended_at IS NULL`, at.UTC(), monitorID)
+	return err
+}
+
+// UnreachableWindowsFor lists the unreachable periods of the monitor within [since, until), clipped to the
+// range. A period that has not ended yet lasts until the end of the range. The periods are kept apart from
+// the monitor events, so clearing the event log does not change SLA results.
+func (s *monitoringStore) UnreachableWindowsFor(ctx context.Context, monitorID int64, since, until time.Time) ([]MaintenanceWindow, error) {
+	if !until.After(since) {
+		return nil, nil
+	}
+	since, until = since.UTC(), until.UTC()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT started_at, ended_at FROM monitor_unreachable_windows
+		WHERE monitor_id=? AND started_at<? AND (ended_at IS NULL OR ended_at>?)
+		ORDER BY started_at ASC`, monitorID, until, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var windows []MaintenanceWindow
+	for rows.Next() {
+		var start time.Time
+		var end sql.NullTime
+		if err := rows.Scan(&start, &end); err != nil {
+			return nil, err
+		}
+		item := MaintenanceWindow{Start: start.UTC(), End: until}
+		if end.Valid && end.Time.UTC().Before(until) {
+			item.End = end.Time.UTC()
+		}
+		if item.Start.Before(since) {
+			item.Start = since
+		}
+		windows = append(windows, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return MergeMaintenanceWindows(windows), nil
+}
@@ -160,7 +160,7 @@ func (s *monitoringStore) MaintenanceWindowsFor(ctx context.Context, monitorID i
 			windows = append(windows, MaintenanceWindow{Start: rng.Start, End: rng.End})
 		}
 	}
-	return mergeMaintenanceWindows(windows), nil
+	return MergeMaintenanceWindows(windows), nil
 }
 
 func scanMaintenance(row interface {
@@ -93,7 +93,8 @@ func maintenanceScheduleToJSON(in MaintenanceSchedule) string {
 	return string(b)
 }
 
-func mergeMaintenanceWindows(in []MaintenanceWindow) []MaintenanceWindow {
+// MergeMaintenanceWindows sorts the windows and joins the overlapping ones.
+func MergeMaintenanceWindows(in []MaintenanceWindow) []MaintenanceWindow {
 	if len(in) == 0 {
 		return nil
 	}
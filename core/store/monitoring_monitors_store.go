@@ -14,14 +14,15 @@ func (s *monitoringStore) CreateMonitor(ctx context.Context, m *Monitor) (int64,
 	headersJSON, _ := json.Marshal(normalizeHeaders(m.Headers))
 	allowedJSON, _ := json.Marshal(normalizeStatusRanges(m.AllowedStatus))
 	res, err := s.db.ExecContext(ctx, `
-		INSERT INTO monitors(name, type, url, host, port, method, request_body, request_body_type, headers_json, interval_sec, timeout_sec, retries, retry_interval_sec, allowed_status_json, ignore_tls_errors, notify_tls_expiring, is_active, is_paused, tags_json, group_id, sla_target_pct, auto_incident, auto_task_on_down, incident_severity, incident_type_id, created_by, created_at, updated_at)
-		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		INSERT INTO monitors(name, type, url, host, port, method, request_body, request_body_type, headers_json, interval_sec, timeout_sec, retries, retry_interval_sec, allowed_status_json, ignore_tls_errors, notify_tls_expiring, is_active, is_paused, tags_json, group_id, parent_id, sla_target_pct, auto_incident, auto_task_on_down, incident_severity, incident_type_id, push_token_hash, push_grace_sec, options_json, credentials_enc, created_by, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
 		strings.TrimSpace(m.Name), strings.ToLower(strings.TrimSpace(m.Type)), strings.TrimSpace(m.URL), strings.TrimSpace(m.Host),
 		m.Port, strings.ToUpper(strings.TrimSpace(m.Method)), m.RequestBody, strings.ToLower(strings.TrimSpace(m.RequestBodyType)),
 		string(headersJSON), m.IntervalSec, m.TimeoutSec, m.Retries, m.RetryIntervalSec, string(allowedJSON),
 		boolToInt(m.IgnoreTLSErrors), boolToInt(m.NotifyTLSExpiring), boolToInt(m.IsActive), boolToInt(m.IsPaused),
-		tagsToJSON(normalizeMonitorTags(m.Tags)), nullableID(m.GroupID), m.SLATargetPct,
+		tagsToJSON(normalizeMonitorTags(m.Tags)), nullableID(m.GroupID), nullableID(m.ParentID), m.SLATargetPct,
 		boolToInt(m.AutoIncident), boolToInt(m.AutoTaskOnDown), strings.TrimSpace(m.IncidentSeverity), strings.TrimSpace(m.IncidentTypeID),
+		strings.TrimSpace(m.PushTokenHash), m.PushGraceSec, monitorOptionsToJSON(m.Options), m.CredentialsEnc,
 		m.CreatedBy, now, now)
 	if err != nil {
 		return 0, err
@@ -35,35 +36,51 @@ func (s *monitoringStore) UpdateMonitor(ctx context.Context, m *Monitor) error {
 	allowedJSON, _ := json.Marshal(normalizeStatusRanges(m.AllowedStatus))
 	_, err := s.db.ExecContext(ctx, `
 		UPDATE monitors
-		SET name=?, type=?, url=?, host=?, port=?, method=?, request_body=?, request_body_type=?, headers_json=?, interval_sec=?, timeout_sec=?, retries=?, retry_interval_sec=?, allowed_status_json=?, ignore_tls_errors=?, notify_tls_expiring=?, is_active=?, is_paused=?, tags_json=?, group_id=?, sla_target_pct=?, auto_incident=?, auto_task_on_down=?, incident_severity=?, incident_type_id=?, updated_at=?
+		SET name=?, type=?, url=?, host=?, port=?, method=?, request_body=?, request_body_type=?, headers_json=?, interval_sec=?, timeout_sec=?, retries=?, retry_interval_sec=?, allowed_status_json=?, ignore_tls_errors=?, notify_tls_expiring=?, is_active=?, is_paused=?, tags_json=?, group_id=?, parent_id=?, sla_target_pct=?, auto_incident=?, auto_task_on_down=?, incident_severity=?, incident_type_id=?, push_token_hash=?, push_grace_sec=?, options_json=?, credentials_enc=?, updated_at=?
 		WHERE id=?`,
 		strings.TrimSpace(m.Name), strings.ToLower(strings.TrimSpace(m.Type)), strings.TrimSpace(m.URL), strings.TrimSpace(m.Host),
 		m.Port, strings.ToUpper(strings.TrimSpace(m.Method)), m.RequestBody, strings.ToLower(strings.TrimSpace(m.RequestBodyType)),
 		string(headersJSON), m.IntervalSec, m.TimeoutSec, m.Retries, m.RetryIntervalSec, string(allowedJSON),
 		boolToInt(m.IgnoreTLSErrors), boolToInt(m.NotifyTLSExpiring), boolToInt(m.IsActive), boolToInt(m.IsPaused),
-		tagsToJSON(normalizeMonitorTags(m.Tags)), nullableID(m.GroupID), m.SLATargetPct,
+		tagsToJSON(normalizeMonitorTags(m.Tags)), nullableID(m.GroupID), nullableID(m.ParentID), m.SLATargetPct,
 		boolToInt(m.AutoIncident), boolToInt(m.AutoTaskOnDown), strings.TrimSpace(m.IncidentSeverity), strings.TrimSpace(m.IncidentTypeID),
+		strings.TrimSpace(m.PushTokenHash), m.PushGraceSec, monitorOptionsToJSON(m.Options), m.CredentialsEnc,
 		time.Now().UTC(), m.ID)
 	return err
 }
 
 func (s *monitoringStore) DeleteMonitor(ctx context.Context, id int64) error {
+	// Children of the removed monitor become top-level monitors.
+	if _, err := s.db.ExecContext(ctx, `UPDATE monitors SET parent_id=NULL WHERE parent_id=?`, id); err != nil {
+		return err
+	}
 	_, err := s.db.ExecContext(ctx, `DELETE FROM monitors WHERE id=?`, id)
 	return err
 }
 
 func (s *monitoringStore) GetMonitor(ctx context.Context, id int64) (*Monitor, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT id, name, type, url, host, port, method, request_body, request_body_type, headers_json, interval_sec, timeout_sec, retries, retry_interval_sec, allowed_status_json, ignore_tls_errors, notify_tls_expiring, is_active, is_paused, tags_json, group_id, sla_target_pct, auto_incident, auto_task_on_down, incident_severity, incident_type_id, created_by, created_at, updated_at
+		SELECT id, name, type, url, host, port, method, request_body, request_body_type, headers_json, interval_sec, timeout_sec, retries, retry_interval_sec, allowed_status_json, ignore_tls_errors, notify_tls_expiring, is_active, is_paused, tags_json, group_id, parent_id, sla_target_pct, auto_incident, auto_task_on_down, incident_severity, incident_type_id, push_token_hash, push_grace_sec, options_json, credentials_enc, created_by, created_at, updated_at
 		FROM monitors WHERE id=?`, id)
 	return scanMonitor(row)
 }
 
+func (s *monitoringStore) GetMonitorByPushTokenHash(ctx context.Context, hash string) (*Monitor, error) {
+	hash = strings.TrimSpace(hash)
+	if hash == "" {
+		return nil, nil
+	}
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, name, type, url, host, port, method, request_body, request_body_type, headers_json, interval_sec, timeout_sec, retries, retry_interval_sec, allowed_status_json, ignore_tls_errors, notify_tls_expiring, is_active, is_paused, tags_json, group_id, parent_id, sla_target_pct, auto_incident, auto_task_on_down, incident_severity, incident_type_id, push_token_hash, push_grace_sec, options_json, credentials_enc, created_by, created_at, updated_at
+		FROM monitors WHERE push_token_hash=?`, hash)
+	return scanMonitor(row)
+}
+
 func (s *monitoringStore) ListMonitors(ctx context.Context, filter MonitorFilter) ([]MonitorSummary, error) {
 	query := `
 		SELECT m.id, m.name, m.type, m.url, m.host, m.port, m.method, m.request_body, m.request_body_type, m.headers_json,
 			m.interval_sec, m.timeout_sec, m.retries, m.retry_interval_sec, m.allowed_status_json, m.ignore_tls_errors, m.notify_tls_expiring, m.is_active, m.is_paused,
-			m.tags_json, m.group_id, m.sla_target_pct, m.auto_incident, m.auto_task_on_down, m.incident_severity, m.incident_type_id, m.created_by, m.created_at, m.updated_at,
+			m.tags_json, m.group_id, m.parent_id, m.sla_target_pct, m.auto_incident, m.auto_task_on_down, m.incident_severity, m.incident_type_id, m.push_token_hash, m.push_grace_sec, m.options_json, m.credentials_enc, m.created_by, m.created_at, m.updated_at,
 			COALESCE(s.status, ''), s.last_checked_at, s.last_up_at, s.last_down_at, s.last_latency_ms, s.last_status_code, s.last_error
 		FROM monitors m
 		LEFT JOIN monitor_state s ON s.monitor_id=m.id`
@@ -112,7 +129,7 @@ func (s *monitoringStore) ListDueMonitors(ctx context.Context, now time.Time) ([
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT m.id, m.name, m.type, m.url, m.host, m.port, m.method, m.request_body, m.request_body_type, m.headers_json,
 			m.interval_sec, m.timeout_sec, m.retries, m.retry_interval_sec, m.allowed_status_json, m.ignore_tls_errors, m.notify_tls_expiring, m.is_active, m.is_paused,
-			m.tags_json, m.group_id, m.sla_target_pct, m.auto_incident, m.auto_task_on_down, m.incident_severity, m.incident_type_id, m.created_by, m.created_at, m.updated_at,
+			m.tags_json, m.group_id, m.parent_id, m.sla_target_pct, m.auto_incident, m.auto_task_on_down, m.incident_severity, m.incident_type_id, m.push_token_hash, m.push_grace_sec, m.options_json, m.credentials_enc, m.created_by, m.created_at, m.updated_at,
 			s.last_checked_at
 		FROM monitors m
 		LEFT JOIN monitor_state s ON s.monitor_id=m.id
@@ -125,15 +142,15 @@ func (s *monitoringStore) ListDueMonitors(ctx context.Context, now time.Time) ([
 	var res []Monitor
 	for rows.Next() {
 		var m Monitor
-		var headersRaw, allowedRaw, tagsRaw string
+		var headersRaw, allowedRaw, tagsRaw, optionsRaw string
 		var isActive, isPaused, autoIncident, autoTaskOnDown, ignoreTLS, notifyTLS int
-		var groupID sql.NullInt64
+		var groupID, parentID sql.NullInt64
 		var sla sql.NullFloat64
 		var lastChecked sql.NullTime
 		if err := rows.Scan(
 			&m.ID, &m.Name, &m.Type, &m.URL, &m.Host, &m.Port, &m.Method, &m.RequestBody, &m.RequestBodyType, &headersRaw,
 			&m.IntervalSec, &m.TimeoutSec, &m.Retries, &m.RetryIntervalSec, &allowedRaw, &ignoreTLS, &notifyTLS, &isActive, &isPaused,
-			&tagsRaw, &groupID, &sla, &autoIncident, &autoTaskOnDown, &m.IncidentSeverity, &m.IncidentTypeID, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
+			&tagsRaw, &groupID, &parentID, &sla, &autoIncident, &autoTaskOnDown, &m.IncidentSeverity, &m.IncidentTypeID, &m.PushTokenHash, &m.PushGraceSec, &optionsRaw, &m.CredentialsEnc, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
 			&lastChecked,
 		); err != nil {
 			return nil, err
@@ -153,9 +170,16 @@ func (s *monitoringStore) ListDueMonitors(ctx context.Context, now time.Time) ([
 		if tagsRaw != "" {
 			_ = json.Unmarshal([]byte(tagsRaw), &m.Tags)
 		}
+		if optionsRaw != "" {
+			_ = json.Unmarshal([]byte(optionsRaw), &m.Options)
+		}
+		m.HasCredentials = len(m.CredentialsEnc) > 0
 		if groupID.Valid {
 			m.GroupID = &groupID.Int64
 		}
+		if parentID.Valid {
+			m.ParentID = &parentID.Int64
+		}
 		if sla.Valid {
 			val := sla.Float64
 			m.SLATargetPct = &val
@@ -188,6 +212,7 @@ func (s *monitoringStore) SetMonitorPaused(ctx context.Context, id int64, paused
 	}
 	status := "paused"
 	maintenanceActive := 0
+	unreachable := false
 	lastResult := "down"
 	var lastChecked *time.Time
 	var lastUp *time.Time
@@ -215,6 +240,7 @@ func (s *monitoringStore) SetMonitorPaused(ctx context.Context, id int64, paused
 		avg24h = current.AvgLatency24h
 		tlsDaysLeft = current.TLSDaysLeft
 		tlsNotAfter = current.TLSNotAfter
+		unreachable = current.Unreachable
 	}
 	if !paused {
 		status = strings.ToLower(strings.TrimSpace(lastResult))
@@ -225,6 +251,9 @@ func (s *monitoringStore) SetMonitorPaused(ctx context.Context, id int64, paused
 				status = "down"
 			}
 		}
+		if status == "down" && unreachable {
+			status = "unreachable"
+		}
 		mon, err := s.GetMonitor(ctx, id)
 		if err != nil {
 			return err
@@ -245,6 +274,7 @@ func (s *monitoringStore) SetMonitorPaused(ctx context.Context, id int64, paused
 		Status:            status,
 		LastResultStatus:  lastResult,
 		MaintenanceActive: maintenanceActive == 1,
+		Unreachable:       unreachable,
 		LastCheckedAt:     lastChecked,
 		LastUpAt:          lastUp,
 		LastDownAt:        lastDown,
@@ -263,14 +293,14 @@ func scanMonitor(row interface {
 	Scan(dest ...any) error
 }) (*Monitor, error) {
 	var m Monitor
-	var headersRaw, allowedRaw, tagsRaw string
+	var headersRaw, allowedRaw, tagsRaw, optionsRaw string
 	var isActive, isPaused, autoIncident, autoTaskOnDown, ignoreTLS, notifyTLS int
-	var groupID sql.NullInt64
+	var groupID, parentID sql.NullInt64
 	var sla sql.NullFloat64
 	if err := row.Scan(
 		&m.ID, &m.Name, &m.Type, &m.URL, &m.Host, &m.Port, &m.Method, &m.RequestBody, &m.RequestBodyType, &headersRaw,
 		&m.IntervalSec, &m.TimeoutSec, &m.Retries, &m.RetryIntervalSec, &allowedRaw, &ignoreTLS, &notifyTLS, &isActive, &isPaused,
-		&tagsRaw, &groupID, &sla, &autoIncident, &autoTaskOnDown, &m.IncidentSeverity, &m.IncidentTypeID, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		&tagsRaw, &groupID, &parentID, &sla, &autoIncident, &autoTaskOnDown, &m.IncidentSeverity, &m.IncidentTypeID, &m.PushTokenHash, &m.PushGraceSec, &optionsRaw, &m.CredentialsEnc, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -291,9 +321,16 @@ func scanMonitor(row interface {
 	if tagsRaw != "" {
 		_ = json.Unmarshal([]byte(tagsRaw), &m.Tags)
 	}
+	if optionsRaw != "" {
+		_ = json.Unmarshal([]byte(optionsRaw), &m.Options)
+	}
+	m.HasCredentials = len(m.CredentialsEnc) > 0
 	if groupID.Valid {
 		m.GroupID = &groupID.Int64
 	}
+	if parentID.Valid {
+		m.ParentID = &parentID.Int64
+	}
 	if sla.Valid {
 		val := sla.Float64
 		m.SLATargetPct = &val
@@ -303,9 +340,9 @@ func scanMonitor(row interface {
 
 func scanMonitorSummary(rows *sql.Rows) (MonitorSummary, error) {
 	var m MonitorSummary
-	var headersRaw, allowedRaw, tagsRaw string
+	var headersRaw, allowedRaw, tagsRaw, optionsRaw string
 	var isActive, isPaused, autoIncident, autoTaskOnDown, ignoreTLS, notifyTLS int
-	var groupID sql.NullInt64
+	var groupID, parentID sql.NullInt64
 	var sla sql.NullFloat64
 	var status sql.NullString
 	var lastChecked, lastUp, lastDown sql.NullTime
@@ -313,7 +350,7 @@ func scanMonitorSummary(rows *sql.Rows) (MonitorSummary, error) {
 	if err := rows.Scan(
 		&m.ID, &m.Name, &m.Type, &m.URL, &m.Host, &m.Port, &m.Method, &m.RequestBody, &m.RequestBodyType, &headersRaw,
 		&m.IntervalSec, &m.TimeoutSec, &m.Retries, &m.RetryIntervalSec, &allowedRaw, &ignoreTLS, &notifyTLS, &isActive, &isPaused,
-		&tagsRaw, &groupID, &sla, &autoIncident, &autoTaskOnDown, &m.IncidentSeverity, &m.IncidentTypeID, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
+		&tagsRaw, &groupID, &parentID, &sla, &autoIncident, &autoTaskOnDown, &m.IncidentSeverity, &m.IncidentTypeID, &m.PushTokenHash, &m.PushGraceSec, &optionsRaw, &m.CredentialsEnc, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt,
 		&status, &lastChecked, &lastUp, &lastDown, &lastLatency, &lastStatus, &m.LastError); err != nil {
 		return m, err
 	}
@@ -332,9 +369,16 @@ func scanMonitorSummary(rows *sql.Rows) (MonitorSummary, error) {
 	if tagsRaw != "" {
 		_ = json.Unmarshal([]byte(tagsRaw), &m.Tags)
 	}
+	if optionsRaw != "" {
+		_ = json.Unmarshal([]byte(optionsRaw), &m.Options)
+	}
+	m.HasCredentials = len(m.CredentialsEnc) > 0
 	if groupID.Valid {
 		m.GroupID = &groupID.Int64
 	}
+	if parentID.Valid {
+		m.ParentID = &parentID.Int64
+	}
 	if sla.Valid {
 		val := sla.Float64
 		m.SLATargetPct = &val
@@ -407,3 +451,11 @@ func normalizeStatusRanges(in []string) []string {
 	}
 	return out
 }
+
+func monitorOptionsToJSON(opts MonitorOptions) string {
+	raw, err := json.Marshal(opts)
+	if err != nil {
+		return "{}"
+	}
+	return string(raw)
+}
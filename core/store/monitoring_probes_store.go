@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+const probeColumns = `id, name, location, monitor_id, enrol_token_hash, enrol_expires_at, secret_enc, version, remote_addr, enrolled_at, last_seen_at, created_by, created_at, updated_at`
+
+func (s *monitoringStore) ListProbes(ctx context.Context) ([]MonitoringProbe, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+probeColumns+` FROM monitoring_probes ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []MonitoringProbe
+	for rows.Next() {
+		item, err := scanProbe(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *item)
+	}
+	return res, rows.Err()
+}
+
+func (s *monitoringStore) GetProbe(ctx context.Context, id int64) (*MonitoringProbe, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+probeColumns+` FROM monitoring_probes WHERE id=?`, id)
+	return scanProbe(row)
+}
+
+func (s *monitoringStore) GetProbeByEnrolTokenHash(ctx context.Context, hash string) (*MonitoringProbe, error) {
+	hash = strings.TrimSpace(hash)
+	if hash == "" {
+		return nil, nil
+	}
+	row := s.db.QueryRowContext(ctx, `SELECT `+probeColumns+` FROM monitoring_probes WHERE enrol_token_hash=?`, hash)
+	return scanProbe(row)
+}
+
+func (s *monitoringStore) CreateProbe(ctx context.Context, p *MonitoringProbe) (int64, error) {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO monitoring_probes(name, location, monitor_id, enrol_token_hash, enrol_expires_at, secret_enc, version, remote_addr, enrolled_at, last_seen_at, created_by, created_at, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		strings.TrimSpace(p.Name), strings.TrimSpace(p.Location), nullableID(p.MonitorID), p.EnrolTokenHash, nullTime(p.EnrolExpiresAt),
+		p.SecretEnc, p.Version, p.RemoteAddr, nullTime(p.EnrolledAt), nullTime(p.LastSeenAt), p.CreatedBy, now, now)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	return id, nil
+}
+
+func (s *monitoringStore) UpdateProbe(ctx context.Context, p *MonitoringProbe) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE monitoring_probes
+		SET name=?, location=?, monitor_id=?, enrol_token_hash=?, enrol_expires_at=?, secret_enc=?, version=?, remote_addr=?, enrolled_at=?, last_seen_at=?, updated_at=?
+		WHERE id=?`,
+		strings.TrimSpace(p.Name), strings.TrimSpace(p.Location), nullableID(p.MonitorID), p.EnrolTokenHash, nullTime(p.EnrolExpiresAt),
+		p.SecretEnc, p.Version, p.RemoteAddr, nullTime(p.EnrolledAt), nullTime(p.LastSeenAt), time.Now().UTC(), p.ID)
+	return err
+}
+
+// TouchProbe records a heartbeat without rewriting the enrolment and secret columns,
+// so a request that overlaps with a token rotation cannot restore the revoked secret.
+func (s *monitoringStore) TouchProbe(ctx context.Context, id int64, seenAt time.Time, remoteAddr, version string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE monitoring_probes
+		SET last_seen_at=?, remote_addr=?, version=?
+		WHERE id=?`,
+		seenAt, remoteAddr, version, id)
+	return err
+}
+
+// EnrollProbe stores the secret and enrolment time of p only while tokenHash is still unused,
+// so two requests racing with the same token cannot both enrol. It reports whether this call won.
+func (s *monitoringStore) EnrollProbe(ctx context.Context, p *MonitoringProbe, tokenHash string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE monitoring_probes
+		SET enrol_token_hash='', enrol_expires_at=NULL, secret_enc=?, enrolled_at=?, updated_at=?
+		WHERE id=? AND enrol_token_hash=? AND enrolled_at IS NULL`,
+		p.SecretEnc, nullTime(p.EnrolledAt), time.Now().UTC(), p.ID, tokenHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (s *monitoringStore) DeleteProbe(ctx context.Context, id int64) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM monitor_probe_results WHERE probe_id=?`, id); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `DELETE FROM monitoring_probes WHERE id=?`, id)
+	return err
+}
+
+// ListProbeMonitors returns the monitors assigned to the probe, paused and inactive ones included.
+func (s *monitoringStore) ListProbeMonitors(ctx context.Context, probeID int64) ([]Monitor, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, type, url, host, port, method, request_body, request_body_type, headers_json, interval_sec, timeout_sec, retries, retry_interval_sec, allowed_status_json, ignore_tls_errors, notify_tls_expiring, is_active, is_paused, tags_json, group_id, parent_id, sla_target_pct, auto_incident, auto_task_on_down, incident_severity, incident_type_id, push_token_hash, push_grace_sec, options_json, credentials_enc, created_by, created_at, updated_at
+		FROM monitors WHERE options_json LIKE ?
+		ORDER BY id`, `%"probe_ids"%`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []Monitor
+	for rows.Next() {
+		m, err := scanMonitor(rows)
+		if err != nil {
+			return nil, err
+		}
+		if m.Options.Probes == nil {
+			continue
+		}
+		for _, id := range m.Options.Probes.ProbeIDs {
+			if id == probeID {
+				res = append(res, *m)
+				break
+			}
+		}
+	}
+	return res, rows.Err()
+}
+
+func (s *monitoringStore) UpsertProbeResult(ctx context.Context, item *MonitorProbeResult) error {
+	if item == nil {
+		return nil
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE monitor_probe_results SET ok=?, status_code=?, latency_ms=?, error=?, checked_at=?, payload_json=?
+		WHERE monitor_id=? AND probe_id=?`,
+		boolToInt(item.OK), item.StatusCode, item.LatencyMs, item.Error, item.CheckedAt, item.Payload, item.MonitorID, item.ProbeID)
+	if err != nil {
+		return err
+	}
+	affected, _ := res.RowsAffected()
+	if affected > 0 {
+		return nil
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO monitor_probe_results(monitor_id, probe_id, ok, status_code, latency_ms, error, checked_at, payload_json)
+		VALUES(?,?,?,?,?,?,?,?)`,
+		item.MonitorID, item.ProbeID, boolToInt(item.OK), item.StatusCode, item.LatencyMs, item.Error, item.CheckedAt, item.Payload)
+	return err
+}
+
+func (s *monitoringStore) ListProbeResults(ctx context.Context, monitorID int64) ([]MonitorProbeResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT monitor_id, probe_id, ok, status_code, latency_ms, error, checked_at, payload_json
+		FROM monitor_probe_results WHERE monitor_id=? ORDER BY probe_id`, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []MonitorProbeResult
+	for rows.Next() {
+		var item MonitorProbeResult
+		var ok int
+		var statusCode sql.NullInt64
+		if err := rows.Scan(&item.MonitorID, &item.ProbeID, &ok, &statusCode, &item.LatencyMs, &item.Error, &item.CheckedAt, &item.Payload); err != nil {
+			return nil, err
+		}
+		item.OK = ok == 1
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			item.StatusCode = &code
+		}
+		res = append(res, item)
+	}
+	return res, rows.Err()
+}
+
+func scanProbe(row interface {
+	Scan(dest ...any) error
+}) (*MonitoringProbe, error) {
+	var p MonitoringProbe
+	var monitorID sql.NullInt64
+	var enrolExpires, enrolledAt, lastSeen sql.NullTime
+	if err := row.Scan(&p.ID, &p.Name, &p.Location, &monitorID, &p.EnrolTokenHash, &enrolExpires, &p.SecretEnc, &p.Version,
+		&p.RemoteAddr, &enrolledAt, &lastSeen, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if monitorID.Valid {
+		p.MonitorID = &monitorID.Int64
+	}
+	if enrolExpires.Valid {
+		p.EnrolExpiresAt = &enrolExpires.Time
+	}
+	if enrolledAt.Valid {
+		p.EnrolledAt = &enrolledAt.Time
+	}
+	if lastSeen.Valid {
+		p.LastSeenAt = &lastSeen.Time
+	}
+	p.Enrolled = len(p.SecretEnc) > 0
+	return &p, nil
+}
@@ -0,0 +1,339 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+const rollupDay = 24 * time.Hour
+
+func rollupTable(resolution string) (string, error) {
+	switch resolution {
+	case RollupHourly:
+		return "monitor_metrics_hourly", nil
+	case RollupDaily:
+		return "monitor_metrics_daily", nil
+	default:
+		return "", errors.New("unknown rollup resolution")
+	}
+}
+
+// updateRollups adds one check to the hourly and daily buckets holding it. Counters, sum, min and max are
+// incremented in place by a single upsert per bucket, so concurrent writers never lose a check. The p95 needs
+// every latency of the bucket and is filled in once the bucket is closed, see FinalizeMetricRollups.
+func (s *monitoringStore) updateRollups(ctx context.Context, metric *MonitorMetric) error {
+	hour := metric.TS.UTC().Truncate(time.Hour)
+	okCount, latencySum := 0, int64(0)
+	var latency *int
+	if metric.OK {
+		val := metric.LatencyMs
+		okCount, latencySum, latency = 1, int64(val), &val
+	}
+	for _, bucket := range []struct {
+		table string
+		start time.Time
+	}{
+		{"monitor_metrics_hourly", hour},
+		{"monitor_metrics_daily", hour.Truncate(rollupDay)},
+	} {
+		t := bucket.table
+		if _, err := s.db.ExecContext(ctx, `
+			INSERT INTO `+t+`(monitor_id, bucket_start, total_count, ok_count, latency_sum_ms, latency_min_ms, latency_max_ms)
+			VALUES(?,?,1,?,?,?,?)
+			ON CONFLICT (monitor_id, bucket_start) DO UPDATE SET
+				total_count=`+t+`.total_count+1,
+				ok_count=`+t+`.ok_count+excluded.ok_count,
+				latency_sum_ms=`+t+`.latency_sum_ms+excluded.latency_sum_ms,
+				latency_min_ms=CASE WHEN `+t+`.latency_min_ms IS NULL OR excluded.latency_min_ms<`+t+`.latency_min_ms THEN excluded.latency_min_ms ELSE `+t+`.latency_min_ms END,
+				latency_max_ms=CASE WHEN `+t+`.latency_max_ms IS NULL OR excluded.latency_max_ms>`+t+`.latency_max_ms THEN excluded.latency_max_ms ELSE `+t+`.latency_max_ms END`,
+			metric.MonitorID, bucket.start, okCount, latencySum, latency, latency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinalizeMetricRollups fills the p95 of the buckets closed before until that have successful checks but no
+// percentile yet. Hours are computed from their raw points, days from their hours; since bounds the scan to
+// the raw retention, so every bucket is read once.
+func (s *monitoringStore) FinalizeMetricRollups(ctx context.Context, since, until time.Time) error {
+	since, until = since.UTC(), until.UTC()
+	hours, err := s.openPercentiles(ctx, "monitor_metrics_hourly", since, until.Truncate(time.Hour))
+	if err != nil {
+		return err
+	}
+	for _, item := range hours {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT latency_ms FROM monitor_metrics
+			WHERE monitor_id=? AND ts>=? AND ts<? AND ok=1 ORDER BY latency_ms ASC`,
+			item.MonitorID, item.BucketStart, item.BucketStart.Add(time.Hour))
+		if err != nil {
+			return err
+		}
+		var latencies []int
+		for rows.Next() {
+			var latency int
+			if err := rows.Scan(&latency); err != nil {
+				rows.Close()
+				return err
+			}
+			latencies = append(latencies, latency)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(latencies) == 0 {
+			continue
+		}
+		p95 := latencies[int(math.Ceil(0.95*float64(len(latencies))))-1]
+		if err := s.setRollupP95(ctx, "monitor_metrics_hourly", item.MonitorID, item.BucketStart, p95); err != nil {
+			return err
+		}
+	}
+	days, err := s.openPercentiles(ctx, "monitor_metrics_daily", since.Truncate(rollupDay), until.Truncate(rollupDay))
+	if err != nil {
+		return err
+	}
+	for _, item := range days {
+		hours, err := s.ListMetricRollups(ctx, item.MonitorID, RollupHourly, item.BucketStart, item.BucketStart.Add(rollupDay))
+		if err != nil {
+			return err
+		}
+		merged := mergeRollups(item.MonitorID, item.BucketStart, hours)
+		if merged.LatencyP95Ms == nil {
+			continue
+		}
+		if err := s.setRollupP95(ctx, "monitor_metrics_daily", item.MonitorID, item.BucketStart, *merged.LatencyP95Ms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *monitoringStore) openPercentiles(ctx context.Context, table string, since, until time.Time) ([]MonitorMetricRollup, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT monitor_id, bucket_start FROM `+table+`
+		WHERE latency_p95_ms IS NULL AND ok_count>0 AND bucket_start>=? AND bucket_start<?`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []MonitorMetricRollup
+	for rows.Next() {
+		var item MonitorMetricRollup
+		if err := rows.Scan(&item.MonitorID, &item.BucketStart); err != nil {
+			return nil, err
+		}
+		item.BucketStart = item.BucketStart.UTC()
+		res = append(res, item)
+	}
+	return res, rows.Err()
+}
+
+// ListUnsettledSLARollups returns the hourly buckets in [since, until) whose SLA counts are not set yet.
+func (s *monitoringStore) ListUnsettledSLARollups(ctx context.Context, since, until time.Time) ([]MonitorMetricRollup, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT monitor_id, bucket_start FROM monitor_metrics_hourly
+		WHERE sla_total_count IS NULL AND bucket_start>=? AND bucket_start<?
+		ORDER BY monitor_id ASC, bucket_start ASC`, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []MonitorMetricRollup
+	for rows.Next() {
+		var item MonitorMetricRollup
+		if err := rows.Scan(&item.MonitorID, &item.BucketStart); err != nil {
+			return nil, err
+		}
+		item.BucketStart = item.BucketStart.UTC()
+		res = append(res, item)
+	}
+	return res, rows.Err()
+}
+
+func (s *monitoringStore) SetRollupSLACounts(ctx context.Context, monitorID int64, bucket time.Time, okCount, totalCount int) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE monitor_metrics_hourly SET sla_ok_count=?, sla_total_count=? WHERE monitor_id=? AND bucket_start=?`,
+		okCount, totalCount, monitorID, bucket.UTC())
+	return err
+}
+
+func (s *monitoringStore) setRollupP95(ctx context.Context, table string, monitorID int64, bucket time.Time, p95 int) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE `+table+` SET latency_p95_ms=? WHERE monitor_id=? AND bucket_start=?`, p95, monitorID, bucket)
+	return err
+}
+
+// mergeRollups folds finer buckets into one. The merged p95 is the percentile of the bucket p95 values
+// weighted by their successful checks: raw points are not kept long enough to compute it exactly.
+func mergeRollups(monitorID int64, bucket time.Time, items []MonitorMetricRollup) MonitorMetricRollup {
+	out := MonitorMetricRollup{MonitorID: monitorID, BucketStart: bucket}
+	var weighted []MonitorMetricRollup
+	for _, item := range items {
+		out.TotalCount += item.TotalCount
+		out.OKCount += item.OKCount
+		out.LatencySumMs += item.LatencySumMs
+		if item.LatencyMinMs != nil && (out.LatencyMinMs == nil || *item.LatencyMinMs < *out.LatencyMinMs) {
+			val := *item.LatencyMinMs
+			out.LatencyMinMs = &val
+		}
+		if item.LatencyMaxMs != nil && (out.LatencyMaxMs == nil || *item.LatencyMaxMs > *out.LatencyMaxMs) {
+			val := *item.LatencyMaxMs
+			out.LatencyMaxMs = &val
+		}
+		if item.LatencyP95Ms != nil && item.OKCount > 0 {
+			weighted = append(weighted, item)
+		}
+	}
+	sort.Slice(weighted, func(i, j int) bool { return *weighted[i].LatencyP95Ms < *weighted[j].LatencyP95Ms })
+	total := 0
+	for _, item := range weighted {
+		total += item.OKCount
+	}
+	seen := 0
+	for _, item := range weighted {
+		seen += item.OKCount
+		if float64(seen) >= 0.95*float64(total) {
+			val := *item.LatencyP95Ms
+			out.LatencyP95Ms = &val
+			break
+		}
+	}
+	if out.OKCount > 0 {
+		out.LatencyAvgMs = float64(out.LatencySumMs) / float64(out.OKCount)
+	}
+	return out
+}
+
+func (s *monitoringStore) ListMetricRollups(ctx context.Context, monitorID int64, resolution string, since, until time.Time) ([]MonitorMetricRollup, error) {
+	table, err := rollupTable(resolution)
+	if err != nil {
+		return nil, err
+	}
+	slaCols := "NULL, NULL"
+	if resolution == RollupHourly {
+		slaCols = "sla_ok_count, sla_total_count"
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT monitor_id, bucket_start, total_count, ok_count, latency_sum_ms, latency_min_ms, latency_p95_ms, latency_max_ms, `+slaCols+`
+		FROM `+table+` WHERE monitor_id=? AND bucket_start>=? AND bucket_start<? ORDER BY bucket_start ASC`,
+		monitorID, since.UTC(), until.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []MonitorMetricRollup
+	for rows.Next() {
+		var item MonitorMetricRollup
+		var minVal, p95, maxVal, slaOK, slaTotal sql.NullInt64
+		if err := rows.Scan(&item.MonitorID, &item.BucketStart, &item.TotalCount, &item.OKCount, &item.LatencySumMs, &minVal, &p95, &maxVal, &slaOK, &slaTotal); err != nil {
+			return nil, err
+		}
+		item.BucketStart = item.BucketStart.UTC()
+		item.LatencyMinMs = nullIntPtr(minVal)
+		item.LatencyP95Ms = nullIntPtr(p95)
+		item.LatencyMaxMs = nullIntPtr(maxVal)
+		item.SLAOKCount = nullIntPtr(slaOK)
+		item.SLATotalCount = nullIntPtr(slaTotal)
+		if item.OKCount > 0 {
+			item.LatencyAvgMs = float64(item.LatencySumMs) / float64(item.OKCount)
+		}
+		res = append(res, item)
+	}
+	return res, rows.Err()
+}
+
+func (s *monitoringStore) DeleteMetricRollupsBefore(ctx context.Context, resolution string, before time.Time) (int64, error) {
+	table, err := rollupTable(resolution)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE bucket_start < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	affected, _ := res.RowsAffected()
+	return affected, nil
+}
+
+// metricSpan is a slice of a summary window read from one table; an empty table means raw points.
+type metricSpan struct {
+	table    string
+	from, to time.Time
+}
+
+// metricSpans covers [since, until) with whole days from the daily rollup, whole hours from the hourly one
+// and the leftover minutes at both ends from raw points.
+func metricSpans(since, until time.Time) []metricSpan {
+	since, until = since.UTC(), until.UTC()
+	if !since.Before(until) {
+		return nil
+	}
+	raw := func(from, to time.Time) metricSpan {
+		return metricSpan{from: from, to: to}
+	}
+	rollup := func(table string, from, to time.Time) metricSpan {
+		return metricSpan{table: table, from: from, to: to}
+	}
+	hourStart, hourEnd := ceilTime(since, time.Hour), until.Truncate(time.Hour)
+	if !hourStart.Before(hourEnd) {
+		return []metricSpan{raw(since, until)}
+	}
+	var spans []metricSpan
+	if since.Before(hourStart) {
+		spans = append(spans, raw(since, hourStart))
+	}
+	dayStart, dayEnd := ceilTime(hourStart, rollupDay), hourEnd.Truncate(rollupDay)
+	if dayStart.Before(dayEnd) {
+		if hourStart.Before(dayStart) {
+			spans = append(spans, rollup("monitor_metrics_hourly", hourStart, dayStart))
+		}
+		spans = append(spans, rollup("monitor_metrics_daily", dayStart, dayEnd))
+		if dayEnd.Before(hourEnd) {
+			spans = append(spans, rollup("monitor_metrics_hourly", dayEnd, hourEnd))
+		}
+	} else {
+		spans = append(spans, rollup("monitor_metrics_hourly", hourStart, hourEnd))
+	}
+	if hourEnd.Before(until) {
+		spans = append(spans, raw(hourEnd, until))
+	}
+	return spans
+}
+
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	out := t.Truncate(d)
+	if out.Before(t) {
+		out = out.Add(d)
+	}
+	return out
+}
+
+// metricsSummary adds up the checks in [since, until) from the rollups, reading raw points only for the
+// partial hours at the edges.
+func (s *monitoringStore) metricsSummary(ctx context.Context, monitorID int64, since, until time.Time) (int, int, int64, error) {
+	okTotal, total := 0, 0
+	var latencySum int64
+	for _, span := range metricSpans(since, until) {
+		query := `SELECT SUM(total_count), SUM(ok_count), SUM(latency_sum_ms) FROM ` + span.table + `
+			WHERE monitor_id=? AND bucket_start>=? AND bucket_start<?`
+		if span.table == "" {
+			query = `SELECT COUNT(*), SUM(CASE WHEN ok=1 THEN 1 ELSE 0 END), SUM(CASE WHEN ok=1 THEN latency_ms ELSE 0 END)
+				FROM monitor_metrics WHERE monitor_id=? AND ts>=? AND ts<?`
+		}
+		row := s.db.QueryRowContext(ctx, query, monitorID, span.from, span.to)
+		var spanTotal, spanOK, spanLatency sql.NullInt64
+		if err := row.Scan(&spanTotal, &spanOK, &spanLatency); err != nil {
+			return 0, 0, 0, err
+		}
+		total += int(spanTotal.Int64)
+		okTotal += int(spanOK.Int64)
+		latencySum += spanLatency.Int64
+	}
+	return okTotal, total, latencySum, nil
+}
@@ -145,6 +145,34 @@ func (s *monitoringStore) ListSLAPeriodResults(ctx context.Context, filter Monit
 	return out, rows.Err()
 }
 
+// ListLatestSLAPeriodResults returns the most recent closed period of each monitor and period type.
+func (s *monitoringStore) ListLatestSLAPeriodResults(ctx context.Context) ([]MonitorSLAPeriodResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.monitor_id, r.period_type, r.period_start, r.period_end,
+			r.uptime_pct, r.coverage_pct, r.target_pct, r.status, r.incident_created, r.created_at, r.updated_at
+		FROM monitor_sla_period_results r
+		WHERE r.period_end = (
+			SELECT MAX(l.period_end) FROM monitor_sla_period_results l
+			WHERE l.monitor_id=r.monitor_id AND l.period_type=r.period_type
+		)
+		ORDER BY r.monitor_id ASC, r.period_type ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []MonitorSLAPeriodResult
+	for rows.Next() {
+		item, err := scanMonitorSLAPeriodResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			out = append(out, *item)
+		}
+	}
+	return out, rows.Err()
+}
+
 func (s *monitoringStore) MarkSLAPeriodIncidentCreated(ctx context.Context, id int64) error {
 	_, err := s.db.ExecContext(ctx, `
 		UPDATE monitor_sla_period_results
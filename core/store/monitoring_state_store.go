@@ -3,13 +3,14 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"time"
 )
 
 func (s *monitoringStore) GetMonitorState(ctx context.Context, id int64) (*MonitorState, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT monitor_id, status, last_result_status, maintenance_active, last_checked_at, last_up_at, last_down_at, last_latency_ms, last_status_code, last_error, uptime_24h, uptime_30d, avg_latency_24h, tls_days_left, tls_not_after
+		SELECT monitor_id, status, last_result_status, maintenance_active, unreachable, last_checked_at, last_up_at, last_down_at, last_latency_ms, last_status_code, last_error, uptime_24h, uptime_30d, avg_latency_24h, tls_days_left, tls_not_after, server_version, server_role, details_json
 		FROM monitor_state WHERE monitor_id=?`, id)
 	return scanMonitorState(row)
 }
@@ -23,7 +24,7 @@ func (s *monitoringStore) ListMonitorStates(ctx context.Context, ids []int64) ([
 		args = append(args, id)
 	}
 	query := `
-		SELECT monitor_id, status, last_result_status, maintenance_active, last_checked_at, last_up_at, last_down_at, last_latency_ms, last_status_code, last_error, uptime_24h, uptime_30d, avg_latency_24h, tls_days_left, tls_not_after
+		SELECT monitor_id, status, last_result_status, maintenance_active, unreachable, last_checked_at, last_up_at, last_down_at, last_latency_ms, last_status_code, last_error, uptime_24h, uptime_30d, avg_latency_24h, tls_days_left, tls_not_after, server_version, server_role, details_json
 		FROM monitor_state WHERE monitor_id IN (` + placeholders(len(ids)) + `)`
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -45,13 +46,14 @@ func (s *monitoringStore) ListMonitorStates(ctx context.Context, ids []int64) ([
 
 func (s *monitoringStore) UpsertMonitorState(ctx context.Context, st *MonitorState) error {
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO monitor_state(monitor_id, status, last_result_status, maintenance_active, last_checked_at, last_up_at, last_down_at, last_latency_ms, last_status_code, last_error, uptime_24h, uptime_30d, avg_latency_24h, tls_days_left, tls_not_after)
-		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+		INSERT INTO monitor_state(monitor_id, status, last_result_status, maintenance_active, unreachable, last_checked_at, last_up_at, last_down_at, last_latency_ms, last_status_code, last_error, uptime_24h, uptime_30d, avg_latency_24h, tls_days_left, tls_not_after, server_version, server_role, details_json)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 		ON CONFLICT (monitor_id)
 		DO UPDATE SET
 			status=excluded.status,
 			last_result_status=excluded.last_result_status,
 			maintenance_active=excluded.maintenance_active,
+			unreachable=excluded.unreachable,
 			last_checked_at=excluded.last_checked_at,
 			last_up_at=excluded.last_up_at,
 			last_down_at=excluded.last_down_at,
@@ -62,8 +64,11 @@ func (s *monitoringStore) UpsertMonitorState(ctx context.Context, st *MonitorSta
 			uptime_30d=excluded.uptime_30d,
 			avg_latency_24h=excluded.avg_latency_24h,
 			tls_days_left=excluded.tls_days_left,
-			tls_not_after=excluded.tls_not_after`,
-		st.MonitorID, st.Status, st.LastResultStatus, boolToInt(st.MaintenanceActive), st.LastCheckedAt, st.LastUpAt, st.LastDownAt, st.LastLatencyMs, st.LastStatusCode, st.LastError, st.Uptime24h, st.Uptime30d, st.AvgLatency24h, st.TLSDaysLeft, st.TLSNotAfter)
+			tls_not_after=excluded.tls_not_after,
+			server_version=excluded.server_version,
+			server_role=excluded.server_role,
+			details_json=excluded.details_json`,
+		st.MonitorID, st.Status, st.LastResultStatus, boolToInt(st.MaintenanceActive), boolToInt(st.Unreachable), st.LastCheckedAt, st.LastUpAt, st.LastDownAt, st.LastLatencyMs, st.LastStatusCode, st.LastError, st.Uptime24h, st.Uptime30d, st.AvgLatency24h, st.TLSDaysLeft, st.TLSNotAfter, st.ServerVersion, st.ServerRole, monitorDetailsToJSON(st.Details))
 	return err
 }
 
@@ -82,20 +87,34 @@ func (s *monitoringStore) MarkMonitorDueNow(ctx context.Context, monitorID int64
 
 func (s *monitoringStore) AddMetric(ctx context.Context, metric *MonitorMetric) (int64, error) {
 	res, err := s.db.ExecContext(ctx, `
-		INSERT INTO monitor_metrics(monitor_id, ts, latency_ms, ok, status_code, error)
-		VALUES(?,?,?,?,?,?)`,
-		metric.MonitorID, metric.TS, metric.LatencyMs, boolToInt(metric.OK), metric.StatusCode, metric.Error)
+		INSERT INTO monitor_metrics(monitor_id, ts, latency_ms, ok, status_code, error, packets_sent, packets_received, packet_loss_pct, rtt_min_ms, rtt_avg_ms, rtt_max_ms, jitter_ms, values_json)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		metric.MonitorID, metric.TS, metric.LatencyMs, boolToInt(metric.OK), metric.StatusCode, metric.Error,
+		metric.PacketsSent, metric.PacketsReceived, metric.PacketLossPct, metric.RTTMinMs, metric.RTTAvgMs, metric.RTTMaxMs, metric.JitterMs,
+		metricValuesToJSON(metric.Values))
 	if err != nil {
 		return 0, err
 	}
 	id, _ := res.LastInsertId()
+	if err := s.updateRollups(ctx, metric); err != nil {
+		return id, err
+	}
 	return id, nil
 }
 
 func (s *monitoringStore) ListMetrics(ctx context.Context, monitorID int64, since time.Time) ([]MonitorMetric, error) {
+	return s.listMetrics(ctx, `monitor_id=? AND ts>=?`, monitorID, since)
+}
+
+func (s *monitoringStore) ListMetricsBetween(ctx context.Context, monitorID int64, since, until time.Time) ([]MonitorMetric, error) {
+	return s.listMetrics(ctx, `monitor_id=? AND ts>=? AND ts<?`, monitorID, since, until)
+}
+
+func (s *monitoringStore) listMetrics(ctx context.Context, where string, args ...any) ([]MonitorMetric, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, monitor_id, ts, latency_ms, ok, status_code, error
-		FROM monitor_metrics WHERE monitor_id=? AND ts>=? ORDER BY ts ASC`, monitorID, since)
+		SELECT id, monitor_id, ts, latency_ms, ok, status_code, error,
+			packets_sent, packets_received, packet_loss_pct, rtt_min_ms, rtt_avg_ms, rtt_max_ms, jitter_ms, values_json
+		FROM monitor_metrics WHERE `+where+` ORDER BY ts ASC`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,9 +125,23 @@ func (s *monitoringStore) ListMetrics(ctx context.Context, monitorID int64, sinc
 		var okInt int
 		var status sql.NullInt64
 		var errText sql.NullString
-		if err := rows.Scan(&m.ID, &m.MonitorID, &m.TS, &m.LatencyMs, &okInt, &status, &errText); err != nil {
+		var sent, received sql.NullInt64
+		var loss, rttMin, rttAvg, rttMax, jitter sql.NullFloat64
+		var values sql.NullString
+		if err := rows.Scan(&m.ID, &m.MonitorID, &m.TS, &m.LatencyMs, &okInt, &status, &errText,
+			&sent, &received, &loss, &rttMin, &rttAvg, &rttMax, &jitter, &values); err != nil {
 			return nil, err
 		}
+		m.PacketsSent = nullIntPtr(sent)
+		m.PacketsReceived = nullIntPtr(received)
+		m.PacketLossPct = nullFloatPtr(loss)
+		m.RTTMinMs = nullFloatPtr(rttMin)
+		m.RTTAvgMs = nullFloatPtr(rttAvg)
+		m.RTTMaxMs = nullFloatPtr(rttMax)
+		m.JitterMs = nullFloatPtr(jitter)
+		if values.Valid && values.String != "" {
+			_ = json.Unmarshal([]byte(values.String), &m.Values)
+		}
 		m.OK = okInt == 1
 		if status.Valid {
 			val := int(status.Int64)
@@ -153,48 +186,23 @@ func (s *monitoringStore) AddEvent(ctx context.Context, event *MonitorEvent) (in
 	return id, nil
 }
 
+// MetricsSummary counts the checks since the given time; the current hour is read from its rollup bucket.
 func (s *monitoringStore) MetricsSummary(ctx context.Context, monitorID int64, since time.Time) (int, int, float64, error) {
-	row := s.db.QueryRowContext(ctx, `
-		SELECT COUNT(*), SUM(CASE WHEN ok=1 THEN 1 ELSE 0 END), AVG(CASE WHEN ok=1 THEN latency_ms END)
-		FROM monitor_metrics WHERE monitor_id=? AND ts>=?`, monitorID, since)
-	var total, okCount sql.NullInt64
-	var avg sql.NullFloat64
-	if err := row.Scan(&total, &okCount, &avg); err != nil {
+	until := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	okVal, totalVal, latencySum, err := s.metricsSummary(ctx, monitorID, since, until)
+	if err != nil {
 		return 0, 0, 0, err
 	}
-	if !total.Valid {
-		return 0, 0, 0, nil
-	}
-	totalVal := int(total.Int64)
-	okVal := 0
-	if okCount.Valid {
-		okVal = int(okCount.Int64)
-	}
 	avgVal := 0.0
-	if avg.Valid {
-		avgVal = avg.Float64
+	if okVal > 0 {
+		avgVal = float64(latencySum) / float64(okVal)
 	}
 	return okVal, totalVal, avgVal, nil
 }
 
 func (s *monitoringStore) MetricsSummaryBetween(ctx context.Context, monitorID int64, since, until time.Time) (int, int, error) {
-	row := s.db.QueryRowContext(ctx, `
-		SELECT COUNT(*), SUM(CASE WHEN ok=1 THEN 1 ELSE 0 END)
-		FROM monitor_metrics
-		WHERE monitor_id=? AND ts>=? AND ts<?`, monitorID, since, until)
-	var total, okCount sql.NullInt64
-	if err := row.Scan(&total, &okCount); err != nil {
-		return 0, 0, err
-	}
-	totalVal := 0
-	okVal := 0
-	if total.Valid {
-		totalVal = int(total.Int64)
-	}
-	if okCount.Valid {
-		okVal = int(okCount.Int64)
-	}
-	return okVal, totalVal, nil
+	okVal, totalVal, _, err := s.metricsSummary(ctx, monitorID, since, until)
+	return okVal, totalVal, err
 }
 
 func (s *monitoringStore) DeleteMetricsBefore(ctx context.Context, before time.Time) (int64, error) {
@@ -211,6 +219,11 @@ func (s *monitoringStore) DeleteMonitorMetrics(ctx context.Context, monitorID in
 	if err != nil {
 		return 0, err
 	}
+	for _, table := range []string{"monitor_metrics_hourly", "monitor_metrics_daily"} {
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE monitor_id=?`, monitorID); err != nil {
+			return 0, err
+		}
+	}
 	affected, _ := res.RowsAffected()
 	return affected, nil
 }
@@ -230,12 +243,13 @@ func scanMonitorState(row interface {
 	var st MonitorState
 	var lastChecked, lastUp, lastDown sql.NullTime
 	var lastLatency, lastStatus sql.NullInt64
-	var maintenanceInt sql.NullInt64
+	var maintenanceInt, unreachableInt sql.NullInt64
 	var tlsDays sql.NullInt64
 	var tlsNotAfter sql.NullTime
+	var detailsRaw string
 	if err := row.Scan(
-		&st.MonitorID, &st.Status, &st.LastResultStatus, &maintenanceInt, &lastChecked, &lastUp, &lastDown, &lastLatency, &lastStatus, &st.LastError,
-		&st.Uptime24h, &st.Uptime30d, &st.AvgLatency24h, &tlsDays, &tlsNotAfter); err != nil {
+		&st.MonitorID, &st.Status, &st.LastResultStatus, &maintenanceInt, &unreachableInt, &lastChecked, &lastUp, &lastDown, &lastLatency, &lastStatus, &st.LastError,
+		&st.Uptime24h, &st.Uptime30d, &st.AvgLatency24h, &tlsDays, &tlsNotAfter, &st.ServerVersion, &st.ServerRole, &detailsRaw); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -244,6 +258,9 @@ func scanMonitorState(row interface {
 	if maintenanceInt.Valid {
 		st.MaintenanceActive = maintenanceInt.Int64 == 1
 	}
+	if unreachableInt.Valid {
+		st.Unreachable = unreachableInt.Int64 == 1
+	}
 	if lastChecked.Valid {
 		st.LastCheckedAt = &lastChecked.Time
 	}
@@ -268,5 +285,50 @@ func scanMonitorState(row interface {
 	if tlsNotAfter.Valid {
 		st.TLSNotAfter = &tlsNotAfter.Time
 	}
+	if detailsRaw != "" && detailsRaw != "{}" {
+		var details MonitorDetails
+		if err := json.Unmarshal([]byte(detailsRaw), &details); err == nil {
+			st.Details = &details
+		}
+	}
 	return &st, nil
 }
+
+func monitorDetailsToJSON(details *MonitorDetails) string {
+	if details == nil {
+		return "{}"
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return "{}"
+	}
+	return string(raw)
+}
+
+func metricValuesToJSON(values map[string]float64) *string {
+	if len(values) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	out := string(raw)
+	return &out
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	val := int(v.Int64)
+	return &val
+}
+
+func nullFloatPtr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	val := v.Float64
+	return &val
+}
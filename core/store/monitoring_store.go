@@ -11,6 +11,7 @@ type MonitoringStore interface {
 	UpdateMonitor(ctx context.Context, m *Monitor) error
 	DeleteMonitor(ctx context.Context, id int64) error
 	GetMonitor(ctx context.Context, id int64) (*Monitor, error)
+	GetMonitorByPushTokenHash(ctx context.Context, hash string) (*Monitor, error)
 	ListMonitors(ctx context.Context, filter MonitorFilter) ([]MonitorSummary, error)
 	ListDueMonitors(ctx context.Context, now time.Time) ([]Monitor, error)
 	SetMonitorPaused(ctx context.Context, id int64, paused bool) error
@@ -21,12 +22,18 @@ type MonitoringStore interface {
 	MarkMonitorDueNow(ctx context.Context, monitorID int64) error
 	AddMetric(ctx context.Context, metric *MonitorMetric) (int64, error)
 	ListMetrics(ctx context.Context, monitorID int64, since time.Time) ([]MonitorMetric, error)
+	ListMetricsBetween(ctx context.Context, monitorID int64, since, until time.Time) ([]MonitorMetric, error)
+	ListMetricRollups(ctx context.Context, monitorID int64, resolution string, since, until time.Time) ([]MonitorMetricRollup, error)
+	FinalizeMetricRollups(ctx context.Context, since, until time.Time) error
+	ListUnsettledSLARollups(ctx context.Context, since, until time.Time) ([]MonitorMetricRollup, error)
+	SetRollupSLACounts(ctx context.Context, monitorID int64, bucket time.Time, okCount, totalCount int) error
 	ListEvents(ctx context.Context, monitorID int64, since time.Time) ([]MonitorEvent, error)
 	ListEventsFeed(ctx context.Context, filter EventFilter) ([]MonitorEvent, error)
 	AddEvent(ctx context.Context, event *MonitorEvent) (int64, error)
 	MetricsSummary(ctx context.Context, monitorID int64, since time.Time) (int, int, float64, error)
 	MetricsSummaryBetween(ctx context.Context, monitorID int64, since, until time.Time) (int, int, error)
 	DeleteMetricsBefore(ctx context.Context, before time.Time) (int64, error)
+	DeleteMetricRollupsBefore(ctx context.Context, resolution string, before time.Time) (int64, error)
 	DeleteMonitorMetrics(ctx context.Context, monitorID int64) (int64, error)
 	DeleteMonitorEvents(ctx context.Context, monitorID int64) (int64, error)
 
@@ -34,6 +41,23 @@ type MonitoringStore interface {
 	UpsertTLS(ctx context.Context, tls *MonitorTLS) error
 	ListCerts(ctx context.Context, filter CertFilter) ([]MonitorCertSummary, error)
 
+	GetContent(ctx context.Context, monitorID int64) (*MonitorContent, error)
+	CreateContent(ctx context.Context, content *MonitorContent) error
+	RecordContentCheck(ctx context.Context, monitorID int64, hash string, body []byte, checkedAt time.Time) error
+	AcceptContentBaseline(ctx context.Context, monitorID int64, hash, acceptedBy string, acceptedAt time.Time) (bool, error)
+
+	ListProbes(ctx context.Context) ([]MonitoringProbe, error)
+	GetProbe(ctx context.Context, id int64) (*MonitoringProbe, error)
+	GetProbeByEnrolTokenHash(ctx context.Context, hash string) (*MonitoringProbe, error)
+	CreateProbe(ctx context.Context, p *MonitoringProbe) (int64, error)
+	UpdateProbe(ctx context.Context, p *MonitoringProbe) error
+	TouchProbe(ctx context.Context, id int64, seenAt time.Time, remoteAddr, version string) error
+	EnrollProbe(ctx context.Context, p *MonitoringProbe, tokenHash string) (bool, error)
+	DeleteProbe(ctx context.Context, id int64) error
+	ListProbeMonitors(ctx context.Context, probeID int64) ([]Monitor, error)
+	UpsertProbeResult(ctx context.Context, item *MonitorProbeResult) error
+	ListProbeResults(ctx context.Context, monitorID int64) ([]MonitorProbeResult, error)
+
 	ListMaintenance(ctx context.Context, filter MaintenanceFilter) ([]MonitorMaintenance, error)
 	GetMaintenance(ctx context.Context, id int64) (*MonitorMaintenance, error)
 	CreateMaintenance(ctx context.Context, m *MonitorMaintenance) (int64, error)
@@ -42,6 +66,9 @@ type MonitoringStore interface {
 	DeleteMaintenance(ctx context.Context, id int64) error
 	ActiveMaintenanceFor(ctx context.Context, monitorID int64, tags []string, now time.Time) ([]MonitorMaintenance, error)
 	MaintenanceWindowsFor(ctx context.Context, monitorID int64, tags []string, since, until time.Time) ([]MaintenanceWindow, error)
+	OpenUnreachableWindow(ctx context.Context, monitorID int64, parentID *int64, at time.Time) error
+	CloseUnreachableWindow(ctx context.Context, monitorID int64, at time.Time) error
+	UnreachableWindowsFor(ctx context.Context, monitorID int64, since, until time.Time) ([]MaintenanceWindow, error)
 
 	GetSettings(ctx context.Context) (*MonitorSettings, error)
 	UpdateSettings(ctx context.Context, settings *MonitorSettings) error
@@ -67,6 +94,7 @@ type MonitoringStore interface {
 	ListMonitorSLAPolicies(ctx context.Context, monitorIDs []int64) ([]MonitorSLAPolicy, error)
 	UpsertSLAPeriodResult(ctx context.Context, item *MonitorSLAPeriodResult) (*MonitorSLAPeriodResult, error)
 	ListSLAPeriodResults(ctx context.Context, filter MonitorSLAPeriodResultListFilter) ([]MonitorSLAPeriodResult, error)
+	ListLatestSLAPeriodResults(ctx context.Context) ([]MonitorSLAPeriodResult, error)
 	MarkSLAPeriodIncidentCreated(ctx context.Context, id int64) error
 	SyncSLAPeriodTarget(ctx context.Context, monitorID int64, targetPct float64, minCoveragePct float64) error
 }
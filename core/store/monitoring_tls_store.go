@@ -10,12 +10,12 @@ import (
 
 func (s *monitoringStore) GetTLS(ctx context.Context, monitorID int64) (*MonitorTLS, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT monitor_id, checked_at, not_after, not_before, common_name, issuer, san_json, fingerprint_sha256, last_error
+		SELECT monitor_id, checked_at, not_after, not_before, common_name, issuer, san_json, fingerprint_sha256, last_error, grade, security_json
 		FROM monitor_tls WHERE monitor_id=?`, monitorID)
 	var tls MonitorTLS
-	var sanRaw string
+	var sanRaw, securityRaw string
 	var lastErr sql.NullString
-	if err := row.Scan(&tls.MonitorID, &tls.CheckedAt, &tls.NotAfter, &tls.NotBefore, &tls.CommonName, &tls.Issuer, &sanRaw, &tls.FingerprintSHA256, &lastErr); err != nil {
+	if err := row.Scan(&tls.MonitorID, &tls.CheckedAt, &tls.NotAfter, &tls.NotBefore, &tls.CommonName, &tls.Issuer, &sanRaw, &tls.FingerprintSHA256, &lastErr, &tls.Grade, &securityRaw); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -24,6 +24,12 @@ func (s *monitoringStore) GetTLS(ctx context.Context, monitorID int64) (*Monitor
 	if sanRaw != "" {
 		_ = json.Unmarshal([]byte(sanRaw), &tls.SANs)
 	}
+	if securityRaw != "" {
+		var security TLSSecurity
+		if err := json.Unmarshal([]byte(securityRaw), &security); err == nil {
+			tls.Security = &security
+		}
+	}
 	if lastErr.Valid {
 		val := lastErr.String
 		tls.LastError = &val
@@ -36,11 +42,16 @@ func (s *monitoringStore) UpsertTLS(ctx context.Context, tls *MonitorTLS) error
 		return nil
 	}
 	sanJSON, _ := json.Marshal(tls.SANs)
+	securityJSON := ""
+	if tls.Security != nil {
+		raw, _ := json.Marshal(tls.Security)
+		securityJSON = string(raw)
+	}
 	res, err := s.db.ExecContext(ctx, `
 		UPDATE monitor_tls
-		SET checked_at=?, not_after=?, not_before=?, common_name=?, issuer=?, san_json=?, fingerprint_sha256=?, last_error=?
+		SET checked_at=?, not_after=?, not_before=?, common_name=?, issuer=?, san_json=?, fingerprint_sha256=?, last_error=?, grade=?, security_json=?
 		WHERE monitor_id=?`,
-		tls.CheckedAt, tls.NotAfter, tls.NotBefore, tls.CommonName, tls.Issuer, string(sanJSON), tls.FingerprintSHA256, tls.LastError, tls.MonitorID)
+		tls.CheckedAt, tls.NotAfter, tls.NotBefore, tls.CommonName, tls.Issuer, string(sanJSON), tls.FingerprintSHA256, tls.LastError, tls.Grade, securityJSON, tls.MonitorID)
 	if err != nil {
 		return err
 	}
@@ -49,19 +60,26 @@ func (s *monitoringStore) UpsertTLS(ctx context.Context, tls *MonitorTLS) error
 		return nil
 	}
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO monitor_tls(monitor_id, checked_at, not_after, not_before, common_name, issuer, san_json, fingerprint_sha256, last_error)
-		VALUES(?,?,?,?,?,?,?,?,?)`,
-		tls.MonitorID, tls.CheckedAt, tls.NotAfter, tls.NotBefore, tls.CommonName, tls.Issuer, string(sanJSON), tls.FingerprintSHA256, tls.LastError)
+		INSERT INTO monitor_tls(monitor_id, checked_at, not_after, not_before, common_name, issuer, san_json, fingerprint_sha256, last_error, grade, security_json)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?)`,
+		tls.MonitorID, tls.CheckedAt, tls.NotAfter, tls.NotBefore, tls.CommonName, tls.Issuer, string(sanJSON), tls.FingerprintSHA256, tls.LastError, tls.Grade, securityJSON)
 	return err
 }
 
+// certMonitorsWhere selects HTTPS monitors and mail, LDAP and tls monitors that captured a certificate.
+const certMonitorsWhere = `((LOWER(m.type) IN ('http','http_keyword','http_json','http_transaction','prometheus') AND LOWER(m.url) LIKE 'https:%')
+			OR (LOWER(m.type) IN ('smtp','imap','pop3','ldap','tls') AND EXISTS (SELECT 1 FROM monitor_tls x WHERE x.monitor_id=m.id)))`
+
+// certTargetColumn shows host:port for monitors without a URL.
+const certTargetColumn = `CASE WHEN m.url <> '' THEN m.url ELSE LOWER(m.type) || '://' || m.host || ':' || CAST(m.port AS TEXT) END`
+
 func (s *monitoringStore) ListCerts(ctx context.Context, filter CertFilter) ([]MonitorCertSummary, error) {
 	query := `
-		SELECT m.id, m.name, m.url, m.tags_json, COALESCE(s.status, ''), t.checked_at, t.not_after, t.not_before, t.common_name, t.issuer, t.last_error
+		SELECT m.id, m.name, ` + certTargetColumn + `, m.tags_json, COALESCE(s.status, ''), t.checked_at, t.not_after, t.not_before, t.common_name, t.issuer, t.last_error, t.grade
 		FROM monitors m
 		LEFT JOIN monitor_state s ON s.monitor_id=m.id
 		LEFT JOIN monitor_tls t ON t.monitor_id=m.id
-		WHERE LOWER(m.type) IN ('http','http_keyword','http_json') AND LOWER(m.url) LIKE 'https:%'`
+		WHERE ` + certMonitorsWhere
 	var clauses []string
 	var args []any
 	if len(filter.Tags) > 0 {
@@ -81,10 +99,10 @@ func (s *monitoringStore) ListCerts(ctx context.Context, filter CertFilter) ([]M
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		fallbackQuery := `
-			SELECT m.id, m.name, m.url, '', COALESCE(s.status, ''), NULL, NULL, NULL, '', '', NULL
+			SELECT m.id, m.name, ` + certTargetColumn + `, '', COALESCE(s.status, ''), NULL, NULL, NULL, '', '', NULL, NULL
 			FROM monitors m
 			LEFT JOIN monitor_state s ON s.monitor_id=m.id
-			WHERE LOWER(m.type) IN ('http','http_keyword','http_json') AND LOWER(m.url) LIKE 'https:%'`
+			WHERE ` + certMonitorsWhere
 		fallbackClauses := clauses
 		fallbackArgs := args
 		if strings.Contains(strings.ToLower(err.Error()), "tags_json") {
@@ -109,10 +127,10 @@ func (s *monitoringStore) ListCerts(ctx context.Context, filter CertFilter) ([]M
 					fallbackArgs = append(fallbackArgs, strings.ToLower(st))
 				}
 				fallbackQuery = `
-					SELECT m.id, m.name, m.url, '', COALESCE(s.status, ''), NULL, NULL, NULL, '', '', NULL
+					SELECT m.id, m.name, ` + certTargetColumn + `, '', COALESCE(s.status, ''), NULL, NULL, NULL, '', '', NULL, NULL
 					FROM monitors m
 					LEFT JOIN monitor_state s ON s.monitor_id=m.id
-					WHERE LOWER(m.type) IN ('http','http_keyword','http_json') AND LOWER(m.url) LIKE 'https:%'`
+					WHERE ` + certMonitorsWhere
 				if len(fallbackClauses) > 0 {
 					fallbackQuery += " AND " + strings.Join(fallbackClauses, " AND ")
 				}
@@ -131,8 +149,8 @@ func (s *monitoringStore) ListCerts(ctx context.Context, filter CertFilter) ([]M
 		var item MonitorCertSummary
 		var tagsRaw sql.NullString
 		var checkedAt, notAfter, notBefore sql.NullTime
-		var lastErr sql.NullString
-		if err := rows.Scan(&item.MonitorID, &item.Name, &item.URL, &tagsRaw, &item.Status, &checkedAt, &notAfter, &notBefore, &item.CommonName, &item.Issuer, &lastErr); err != nil {
+		var lastErr, grade sql.NullString
+		if err := rows.Scan(&item.MonitorID, &item.Name, &item.URL, &tagsRaw, &item.Status, &checkedAt, &notAfter, &notBefore, &item.CommonName, &item.Issuer, &lastErr, &grade); err != nil {
 			return nil, err
 		}
 		if tagsRaw.Valid && tagsRaw.String != "" {
@@ -157,6 +175,7 @@ func (s *monitoringStore) ListCerts(ctx context.Context, filter CertFilter) ([]M
 		if lastErr.Valid {
 			item.LastError = lastErr.String
 		}
+		item.Grade = grade.String
 		res = append(res, item)
 	}
 	return res, rows.Err()
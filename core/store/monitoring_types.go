@@ -24,14 +24,22 @@ type Monitor struct {
 	IsPaused          bool              `json:"is_paused"`
 	Tags              []string          `json:"tags"`
 	GroupID           *int64            `json:"group_id,omitempty"`
-	SLATargetPct      *float64          `json:"sla_target_pct,omitempty"`
-	AutoIncident      bool              `json:"auto_incident"`
-	AutoTaskOnDown    bool              `json:"auto_task_on_down"`
-	IncidentSeverity  string            `json:"incident_severity,omitempty"`
-	IncidentTypeID    string            `json:"incident_type_id,omitempty"`
-	CreatedBy         int64             `json:"created_by"`
-	CreatedAt         time.Time         `json:"created_at"`
-	UpdatedAt         time.Time         `json:"updated_at"`
+	// ParentID is the monitor this one depends on; while the parent is down a failure is reported as unreachable.
+	ParentID         *int64              `json:"parent_id,omitempty"`
+	SLATargetPct     *float64            `json:"sla_target_pct,omitempty"`
+	AutoIncident     bool                `json:"auto_incident"`
+	AutoTaskOnDown   bool                `json:"auto_task_on_down"`
+	IncidentSeverity string              `json:"incident_severity,omitempty"`
+	IncidentTypeID   string              `json:"incident_type_id,omitempty"`
+	PushTokenHash    string              `json:"-"`
+	PushGraceSec     int                 `json:"push_grace_sec,omitempty"`
+	Options          MonitorOptions      `json:"options"`
+	CredentialsEnc   []byte              `json:"-"`
+	HasCredentials   bool                `json:"has_credentials"`
+	Credentials      *MonitorCredentials `json:"-"`
+	CreatedBy        int64               `json:"created_by"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
 }
 
 type MonitorSummary struct {
@@ -46,31 +54,447 @@ type MonitorSummary struct {
 }
 
 type MonitorState struct {
-	MonitorID         int64      `json:"monitor_id"`
-	Status            string     `json:"status"`
-	LastResultStatus  string     `json:"last_result_status,omitempty"`
-	MaintenanceActive bool       `json:"maintenance_active"`
-	LastCheckedAt     *time.Time `json:"last_checked_at,omitempty"`
-	LastUpAt          *time.Time `json:"last_up_at,omitempty"`
-	LastDownAt        *time.Time `json:"last_down_at,omitempty"`
-	LastLatencyMs     *int       `json:"last_latency_ms,omitempty"`
-	LastStatusCode    *int       `json:"last_status_code,omitempty"`
-	LastError         string     `json:"last_error,omitempty"`
-	Uptime24h         float64    `json:"uptime_24h"`
-	Uptime30d         float64    `json:"uptime_30d"`
-	AvgLatency24h     float64    `json:"avg_latency_24h"`
-	TLSDaysLeft       *int       `json:"tls_days_left,omitempty"`
-	TLSNotAfter       *time.Time `json:"tls_not_after,omitempty"`
+	MonitorID         int64  `json:"monitor_id"`
+	Status            string `json:"status"`
+	LastResultStatus  string `json:"last_result_status,omitempty"`
+	MaintenanceActive bool   `json:"maintenance_active"`
+	// Unreachable marks a failing monitor whose parent was down at the time of the check.
+	Unreachable    bool            `json:"unreachable"`
+	LastCheckedAt  *time.Time      `json:"last_checked_at,omitempty"`
+	LastUpAt       *time.Time      `json:"last_up_at,omitempty"`
+	LastDownAt     *time.Time      `json:"last_down_at,omitempty"`
+	LastLatencyMs  *int            `json:"last_latency_ms,omitempty"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	Uptime24h      float64         `json:"uptime_24h"`
+	Uptime30d      float64         `json:"uptime_30d"`
+	AvgLatency24h  float64         `json:"avg_latency_24h"`
+	TLSDaysLeft    *int            `json:"tls_days_left,omitempty"`
+	TLSNotAfter    *time.Time      `json:"tls_not_after,omitempty"`
+	ServerVersion  string          `json:"server_version,omitempty"`
+	ServerRole     string          `json:"server_role,omitempty"`
+	Details        *MonitorDetails `json:"details,omitempty"`
 }
 
 type MonitorMetric struct {
-	ID         int64     `json:"id"`
-	MonitorID  int64     `json:"monitor_id"`
-	TS         time.Time `json:"ts"`
-	LatencyMs  int       `json:"latency_ms"`
-	OK         bool      `json:"ok"`
-	StatusCode *int      `json:"status_code,omitempty"`
-	Error      *string   `json:"error,omitempty"`
+	ID              int64     `json:"id"`
+	MonitorID       int64     `json:"monitor_id"`
+	TS              time.Time `json:"ts"`
+	LatencyMs       int       `json:"latency_ms"`
+	OK              bool      `json:"ok"`
+	StatusCode      *int      `json:"status_code,omitempty"`
+	Error           *string   `json:"error,omitempty"`
+	PacketsSent     *int      `json:"packets_sent,omitempty"`
+	PacketsReceived *int      `json:"packets_received,omitempty"`
+	PacketLossPct   *float64  `json:"packet_loss_pct,omitempty"`
+	RTTMinMs        *float64  `json:"rtt_min_ms,omitempty"`
+	RTTAvgMs        *float64  `json:"rtt_avg_ms,omitempty"`
+	RTTMaxMs        *float64  `json:"rtt_max_ms,omitempty"`
+	JitterMs        *float64  `json:"jitter_ms,omitempty"`
+	// Values are numbers extracted by the check (e.g. JSON assertion paths), keyed by their source.
+	Values map[string]float64 `json:"values,omitempty"`
+}
+
+const (
+	RollupHourly = "hourly"
+	RollupDaily  = "daily"
+)
+
+// MonitorMetricRollup aggregates the checks of a monitor over one hour or one day (UTC).
+// Latency figures cover successful checks only, like the raw summaries.
+type MonitorMetricRollup struct {
+	MonitorID    int64     `json:"monitor_id"`
+	BucketStart  time.Time `json:"bucket_start"`
+	TotalCount   int       `json:"total_count"`
+	OKCount      int       `json:"ok_count"`
+	LatencySumMs int64     `json:"-"`
+	LatencyAvgMs float64   `json:"latency_avg_ms"`
+	LatencyMinMs *int      `json:"latency_min_ms,omitempty"`
+	LatencyP95Ms *int      `json:"latency_p95_ms,omitempty"`
+	LatencyMaxMs *int      `json:"latency_max_ms,omitempty"`
+	// SLA counts leave out the checks inside maintenance and unreachable windows. Only hourly buckets carry
+	// them, from the moment the hour is settled after it closes.
+	SLAOKCount    *int `json:"-"`
+	SLATotalCount *int `json:"-"`
+}
+
+// MonitorOptions holds type-specific monitor settings stored in monitors.options_json.
+type MonitorOptions struct {
+	Ping     *PingOptions     `json:"ping,omitempty"`
+	Database *DatabaseOptions `json:"database,omitempty"`
+	Redis    *RedisOptions    `json:"redis,omitempty"`
+	MongoDB  *MongoDBOptions  `json:"mongodb,omitempty"`
+	Radius   *RadiusOptions   `json:"radius,omitempty"`
+	MQTT     *MQTTOptions     `json:"mqtt,omitempty"`
+	Kafka    *KafkaOptions    `json:"kafka,omitempty"`
+	Docker   *DockerOptions   `json:"docker,omitempty"`
+	GRPC     *GRPCOptions     `json:"grpc,omitempty"`
+	Game     *GameOptions     `json:"game,omitempty"`
+	DNS      *DNSOptions      `json:"dns,omitempty"`
+	JSON     *JSONOptions     `json:"json,omitempty"`
+	HTTP     *HTTPOptions     `json:"http,omitempty"`
+	TLS      *TLSOptions      `json:"tls,omitempty"`
+	// Transaction holds the steps of http_transaction monitors.
+	Transaction *TransactionOptions `json:"transaction,omitempty"`
+	Mail        *MailOptions        `json:"mail,omitempty"`
+	SSH         *SSHOptions         `json:"ssh,omitempty"`
+	LDAP        *LDAPOptions        `json:"ldap,omitempty"`
+	TLSScan     *TLSScanOptions     `json:"tls_scan,omitempty"`
+	Content     *ContentOptions     `json:"content,omitempty"`
+	Headers     *HeadersOptions     `json:"security_headers,omitempty"`
+	Probes      *ProbeOptions       `json:"probes,omitempty"`
+	Prometheus  *PrometheusOptions  `json:"prometheus,omitempty"`
+}
+
+// MonitorCredentials is stored encrypted in monitors.credentials_enc.
+type MonitorCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Secret is a protocol-level shared secret, e.g. the RADIUS client secret or the OAuth2 client secret.
+	Secret string `json:"secret,omitempty"`
+	// Token is sent as the HTTP bearer token.
+	Token string `json:"token,omitempty"`
+	// ClientCert and ClientKey are PEM encoded and presented on TLS connections that request a client certificate.
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+	// PrivateKey is a PEM encoded SSH key; Password is its passphrase when the key is encrypted.
+	PrivateKey string `json:"private_key,omitempty"`
+}
+
+type PingOptions struct {
+	Count           int      `json:"count,omitempty"`
+	IntervalMs      int      `json:"interval_ms,omitempty"`
+	PacketSize      int      `json:"packet_size,omitempty"`
+	MaxLossPct      *float64 `json:"max_loss_pct,omitempty"`
+	MaxRTTMs        *float64 `json:"max_rtt_ms,omitempty"`
+	DegradedLossPct *float64 `json:"degraded_loss_pct,omitempty"`
+	DegradedRTTMs   *float64 `json:"degraded_rtt_ms,omitempty"`
+}
+
+type DatabaseOptions struct {
+	Database       string `json:"database,omitempty"`
+	Query          string `json:"query,omitempty"`
+	ExpectedResult string `json:"expected_result,omitempty"`
+	// TLSMode is one of disable, prefer (default) or require.
+	TLSMode string `json:"tls_mode,omitempty"`
+}
+
+type RedisOptions struct {
+	TLS bool `json:"tls,omitempty"`
+	// ExpectedRole is master or replica; any other reported role marks the monitor down.
+	ExpectedRole string `json:"expected_role,omitempty"`
+}
+
+type MongoDBOptions struct {
+	TLS        bool   `json:"tls,omitempty"`
+	AuthSource string `json:"auth_source,omitempty"`
+	Database   string `json:"database,omitempty"`
+	// ExpectedRole is primary, secondary, arbiter, standalone or mongos.
+	ExpectedRole string `json:"expected_role,omitempty"`
+}
+
+type RadiusOptions struct {
+	// AuthMethod is pap (default) or chap.
+	AuthMethod string `json:"auth_method,omitempty"`
+	// ExpectedResult is accept (default) or reject, for probes with a deliberately invalid user.
+	ExpectedResult string `json:"expected_result,omitempty"`
+	NASIdentifier  string `json:"nas_identifier,omitempty"`
+}
+
+type MQTTOptions struct {
+	// Version is 3.1.1 (default) or 5.
+	Version  string `json:"version,omitempty"`
+	TLS      bool   `json:"tls,omitempty"`
+	ClientID string `json:"client_id,omitempty"`
+	// Topic enables a publish/subscribe round trip whose duration becomes the check latency.
+	Topic string `json:"topic,omitempty"`
+}
+
+type KafkaOptions struct {
+	TLS bool `json:"tls,omitempty"`
+	// SASLMechanism is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512; empty disables SASL.
+	SASLMechanism string `json:"sasl_mechanism,omitempty"`
+	Topic         string `json:"topic,omitempty"`
+	Partition     int32  `json:"partition,omitempty"`
+	// Produce writes a canary record to Topic/Partition through the partition leader.
+	Produce bool `json:"produce,omitempty"`
+}
+
+type DockerOptions struct {
+	// Socket is a unix socket path; when empty the Engine API is reached over TCP at Host:Port.
+	Socket string `json:"socket,omitempty"`
+	TLS    bool   `json:"tls,omitempty"`
+	// Container is a name or ID. LabelSelector (key or key=value) checks every matching container instead.
+	Container     string `json:"container,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+}
+
+type GRPCOptions struct {
+	// Service is passed to grpc.health.v1.Health/Check; empty checks the server as a whole.
+	Service string `json:"service,omitempty"`
+	// Method ("package.Service/Method") switches to a unary call resolved via server reflection.
+	// Request is its JSON-encoded input; the monitor keyword is searched in the JSON-encoded reply.
+	Method  string `json:"method,omitempty"`
+	Request string `json:"request,omitempty"`
+}
+
+type GameOptions struct {
+	// Protocol is the query protocol of gamedig monitors: source, minecraft or quake3. Steam monitors always use source.
+	Protocol string `json:"protocol,omitempty"`
+	// The monitor goes down after MinPlayersChecks consecutive checks with fewer than MinPlayers players (default 1).
+	MinPlayers       int `json:"min_players,omitempty"`
+	MinPlayersChecks int `json:"min_players_checks,omitempty"`
+}
+
+// DNSOptions configure DNS monitors; the record type is taken from Monitor.Method.
+type DNSOptions struct {
+	// Resolver is host[:port] of the server to query; empty uses the system resolver.
+	Resolver string `json:"resolver,omitempty"`
+	// Transport is udp (default, falls back to tcp on truncation), tcp or dot.
+	Transport string `json:"transport,omitempty"`
+	// Expected is the exact record set, compared without regard to order.
+	Expected []string `json:"expected,omitempty"`
+	// Drift records the NS, MX and A sets of the name and reports changes between checks.
+	Drift         bool `json:"drift,omitempty"`
+	DriftIncident bool `json:"drift_incident,omitempty"`
+}
+
+// JSONOptions configure http_json monitors.
+type JSONOptions struct {
+	Assertions []JSONAssertion `json:"assertions,omitempty"`
+}
+
+// JSONAssertion checks one value of the response selected by a JSONPath such as $.db.lag or $.items[0].name.
+type JSONAssertion struct {
+	Path string `json:"path"`
+	// Op is one of ==, !=, <, >, contains, regex, exists.
+	Op string `json:"op"`
+	// Value is a JSON literal ("ok", 30, true, null) or a bare string; a pattern for regex.
+	Value string `json:"value,omitempty"`
+}
+
+// PrometheusOptions configure prometheus monitors, which scrape a Prometheus or OpenMetrics text endpoint.
+type PrometheusOptions struct {
+	// Expression selects and compares a value, e.g. queue_depth{queue="alerts"} > 1000 or
+	// max(up{job=~"api.*"}) == 0; the monitor is down while it holds. Matching series are folded with
+	// sum, max or min; without an aggregation exactly one series has to match.
+	Expression string `json:"expression"`
+}
+
+// HTTPOptions configure authentication and proxying of HTTP monitors; secrets live in MonitorCredentials.
+type HTTPOptions struct {
+	// Auth is none, basic (username/password), bearer (token) or oauth2 (client credentials, secret as client secret).
+	Auth   string         `json:"auth,omitempty"`
+	OAuth2 *OAuth2Options `json:"oauth2,omitempty"`
+	// Proxy is an http, https or socks5 proxy URL used instead of the environment proxy.
+	Proxy string `json:"proxy,omitempty"`
+}
+
+// OAuth2Options describe the client-credentials token request.
+type OAuth2Options struct {
+	TokenURL string   `json:"token_url"`
+	ClientID string   `json:"client_id"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+// MailOptions configure smtp, imap and pop3 monitors; AUTH/LOGIN uses the monitor credentials.
+type MailOptions struct {
+	// TLS is starttls (default), tls for implicit TLS (ports 465/993/995) or none.
+	TLS string `json:"tls,omitempty"`
+	// Helo is the EHLO name sent by smtp monitors.
+	Helo string `json:"helo,omitempty"`
+	// MailFrom and RcptTo run a test envelope on smtp monitors; the transaction is reset before DATA.
+	MailFrom string `json:"mail_from,omitempty"`
+	RcptTo   string `json:"rcpt_to,omitempty"`
+}
+
+// SSHOptions configure ssh monitors. Without a private key only the transport handshake is checked.
+type SSHOptions struct {
+	// HostKeyFingerprint pins the server host key as "SHA256:<base64>", the format printed by ssh-keygen -l.
+	HostKeyFingerprint string `json:"host_key_fingerprint,omitempty"`
+	// AllowWeakAlgorithms reports weak algorithms in the details instead of failing the check.
+	AllowWeakAlgorithms bool `json:"allow_weak_algorithms,omitempty"`
+	// HostKeyIncident opens an incident when the host key changes.
+	HostKeyIncident bool `json:"host_key_incident,omitempty"`
+	// Command runs after key authentication and must exit with status 0.
+	Command string `json:"command,omitempty"`
+}
+
+// LDAPOptions configure ldap monitors; the simple bind uses the monitor credentials, anonymous without them.
+type LDAPOptions struct {
+	// TLS is starttls (default), tls for LDAPS (port 636) or none.
+	TLS string `json:"tls,omitempty"`
+	// BaseDN enables the search; Filter defaults to (objectClass=*) and Scope to sub.
+	BaseDN     string `json:"base_dn,omitempty"`
+	Filter     string `json:"filter,omitempty"`
+	Scope      string `json:"scope,omitempty"`
+	MinEntries int    `json:"min_entries,omitempty"`
+}
+
+// ContentOptions enable defacement detection on http monitors: the normalised body is compared with an accepted baseline.
+type ContentOptions struct {
+	Enabled bool `json:"enabled"`
+	// IgnorePatterns are regular expressions removed from the body before hashing (timestamps, CSRF tokens, counters).
+	IgnorePatterns []string `json:"ignore_patterns,omitempty"`
+	// IgnoreRegions drop everything between a start and an end marker, markers included.
+	IgnoreRegions []ContentRegion `json:"ignore_regions,omitempty"`
+	// Incident opens an incident when the content changes.
+	Incident bool `json:"incident,omitempty"`
+}
+
+type ContentRegion struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// HeadersOptions enable the security headers and cookie policy of http monitors.
+type HeadersOptions struct {
+	Enabled bool `json:"enabled"`
+	// MinHSTSMaxAge is the shortest accepted Strict-Transport-Security max-age in seconds; 180 days when zero.
+	MinHSTSMaxAge int `json:"min_hsts_max_age,omitempty"`
+	// MinScore fails the check when the policy score drops below it.
+	MinScore int `json:"min_score,omitempty"`
+	// ControlID links a control that gets a violation when the policy regresses.
+	ControlID int64 `json:"control_id,omitempty"`
+}
+
+// ProbeOptions run the monitor from remote probe agents; the state follows the quorum of their results.
+type ProbeOptions struct {
+	ProbeIDs []int64 `json:"probe_ids"`
+	// Quorum is the number of failing locations that takes the monitor down; a majority when zero.
+	Quorum int `json:"quorum,omitempty"`
+	// IncludeLocal also runs the check from the SCC server and counts it as one more location.
+	IncludeLocal bool `json:"include_local,omitempty"`
+}
+
+// TLSScanOptions configure tls monitors, which grade the TLS setup of any host:port.
+type TLSScanOptions struct {
+	// ServerName overrides the SNI and the name the certificate is checked against (defaults to the host).
+	ServerName string `json:"server_name,omitempty"`
+	// MinGrade fails the check when the endpoint grades lower (A+, A, B, C, F).
+	MinGrade string `json:"min_grade,omitempty"`
+	// AllowLegacy reports TLS 1.0/1.1 without failing the check.
+	AllowLegacy bool `json:"allow_legacy,omitempty"`
+}
+
+// TransactionOptions configure http_transaction monitors: the steps run in order and share variables and cookies.
+type TransactionOptions struct {
+	Steps []TransactionStep `json:"steps"`
+}
+
+// TransactionStep is one request of a transaction. URL, header values and Body may reference
+// variables extracted by earlier steps as {{name}}; a relative URL is resolved against the monitor URL.
+type TransactionStep struct {
+	Name    string            `json:"name,omitempty"`
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// AllowedStatus defaults to the allowed statuses of the monitor.
+	AllowedStatus []string             `json:"allowed_status,omitempty"`
+	Keyword       string               `json:"keyword,omitempty"`
+	Assertions    []JSONAssertion      `json:"assertions,omitempty"`
+	Extract       []TransactionExtract `json:"extract,omitempty"`
+}
+
+// TransactionExtract stores a value of the step response as a variable for later steps.
+type TransactionExtract struct {
+	Name string `json:"name"`
+	// From is json (Expr is a JSONPath), regex (first capture group of the body), header or cookie (Expr is the name).
+	From string `json:"from"`
+	Expr string `json:"expr"`
+}
+
+// TLSOptions apply to every TLS connection a monitor opens.
+type TLSOptions struct {
+	// CACert is a PEM bundle trusted in addition to the system roots.
+	CACert string `json:"ca_cert,omitempty"`
+}
+
+// MonitorDetails holds type-specific results of the last check, stored in monitor_state.details_json.
+type MonitorDetails struct {
+	Containers  []ContainerDetails  `json:"containers,omitempty"`
+	Game        *GameDetails        `json:"game,omitempty"`
+	DNS         *DNSDetails         `json:"dns,omitempty"`
+	Transaction *TransactionDetails `json:"transaction,omitempty"`
+	SSH         *SSHDetails         `json:"ssh,omitempty"`
+	Headers     *HeadersDetails     `json:"security_headers,omitempty"`
+	Probes      []ProbeLocation     `json:"probes,omitempty"`
+}
+
+type ContainerDetails struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Image        string     `json:"image"`
+	State        string     `json:"state"`
+	Health       string     `json:"health,omitempty"`
+	RestartCount int        `json:"restart_count"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	UptimeSec    int64      `json:"uptime_sec,omitempty"`
+}
+
+type GameDetails struct {
+	Name        string   `json:"name"`
+	Map         string   `json:"map,omitempty"`
+	Game        string   `json:"game,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Players     int      `json:"players"`
+	MaxPlayers  int      `json:"max_players"`
+	Bots        int      `json:"bots,omitempty"`
+	PlayerNames []string `json:"player_names,omitempty"`
+	// LowPlayerChecks counts consecutive checks below GameOptions.MinPlayers.
+	LowPlayerChecks int `json:"low_player_checks,omitempty"`
+}
+
+type DNSDetails struct {
+	Records []string `json:"records"`
+	// Baseline holds the last NS, MX and A sets seen in drift mode, keyed by record type.
+	Baseline map[string][]string `json:"baseline,omitempty"`
+}
+
+type TransactionDetails struct {
+	Steps []TransactionStepResult `json:"steps"`
+	// FailedStep is the name of the step that failed the check, empty when every step passed.
+	FailedStep string `json:"failed_step,omitempty"`
+}
+
+// SSHDetails hold what the server offered in its key exchange init.
+type SSHDetails struct {
+	Banner             string   `json:"banner"`
+	HostKeyType        string   `json:"host_key_type"`
+	HostKeyFingerprint string   `json:"host_key_fingerprint"`
+	KeyExchanges       []string `json:"kex"`
+	HostKeyAlgorithms  []string `json:"host_key_algorithms"`
+	Ciphers            []string `json:"ciphers"`
+	MACs               []string `json:"macs"`
+	WeakAlgorithms     []string `json:"weak_algorithms,omitempty"`
+}
+
+// HeadersDetails hold the score and the violated rules of the security headers policy.
+type HeadersDetails struct {
+	Score      int      `json:"score"`
+	Violations []string `json:"violations"`
+}
+
+// ProbeLocation is the last result of one location of a monitor run by probes; ProbeID 0 is the SCC server.
+type ProbeLocation struct {
+	ProbeID   int64      `json:"probe_id"`
+	Name      string     `json:"name"`
+	Location  string     `json:"location,omitempty"`
+	OK        bool       `json:"ok"`
+	LatencyMs int        `json:"latency_ms"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	// Stale marks a missing result or one older than the monitor allows; it does not count towards the quorum.
+	Stale bool `json:"stale,omitempty"`
+}
+
+type TransactionStepResult struct {
+	Name       string `json:"name"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMs  int    `json:"latency_ms"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
 }
 
 type MonitorEvent struct {
@@ -91,6 +515,75 @@ type MonitorTLS struct {
 	SANs              []string  `json:"sans"`
 	FingerprintSHA256 string    `json:"fingerprint_sha256"`
 	LastError         *string   `json:"last_error,omitempty"`
+	// Grade and Security are filled by tls monitors only.
+	Grade    string       `json:"grade,omitempty"`
+	Security *TLSSecurity `json:"security,omitempty"`
+}
+
+// TLSSecurity is the scan result of a tls monitor, stored in monitor_tls.security_json.
+type TLSSecurity struct {
+	Protocols    []string       `json:"protocols"`
+	CipherSuites []string       `json:"cipher_suites,omitempty"`
+	Chain        []TLSChainCert `json:"chain"`
+	Issues       []string       `json:"issues,omitempty"`
+}
+
+// TLSChainCert describes one certificate as presented by the server.
+type TLSChainCert struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	NotAfter           time.Time `json:"not_after"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	KeyType            string    `json:"key_type"`
+	KeyBits            int       `json:"key_bits"`
+}
+
+// MonitorContent is the accepted page baseline of a content-watching http monitor and the content seen last.
+// Bodies are normalised and gzip-compressed.
+type MonitorContent struct {
+	MonitorID    int64      `json:"monitor_id"`
+	BaselineHash string     `json:"baseline_hash"`
+	Baseline     []byte     `json:"-"`
+	BaselineAt   time.Time  `json:"baseline_at"`
+	AcceptedBy   string     `json:"accepted_by,omitempty"`
+	CurrentHash  string     `json:"current_hash"`
+	Current      []byte     `json:"-"`
+	CheckedAt    time.Time  `json:"checked_at"`
+	ChangedAt    *time.Time `json:"changed_at,omitempty"`
+}
+
+// MonitoringProbe is a remote agent that runs the checks of the monitors assigned to it.
+// It enrols once with a one-time token and signs its requests with the secret issued on enrolment.
+type MonitoringProbe struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	// MonitorID is the push monitor that receives the heartbeat of the probe.
+	MonitorID      *int64     `json:"monitor_id,omitempty"`
+	EnrolTokenHash string     `json:"-"`
+	EnrolExpiresAt *time.Time `json:"enrol_expires_at,omitempty"`
+	SecretEnc      []byte     `json:"-"`
+	Enrolled       bool       `json:"enrolled"`
+	Version        string     `json:"version,omitempty"`
+	RemoteAddr     string     `json:"remote_addr,omitempty"`
+	EnrolledAt     *time.Time `json:"enrolled_at,omitempty"`
+	LastSeenAt     *time.Time `json:"last_seen_at,omitempty"`
+	CreatedBy      int64      `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// MonitorProbeResult is the last result a location reported for a monitor.
+type MonitorProbeResult struct {
+	MonitorID  int64     `json:"monitor_id"`
+	ProbeID    int64     `json:"probe_id"`
+	OK         bool      `json:"ok"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	LatencyMs  int       `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+	// Payload is the JSON check output (details, TLS, values) the location reported with the result.
+	Payload string `json:"-"`
 }
 
 type MonitorCertSummary struct {
@@ -107,6 +600,7 @@ type MonitorCertSummary struct {
 	DaysLeft     *int       `json:"days_left,omitempty"`
 	ExpiringSoon bool       `json:"expiring_soon"`
 	LastError    string     `json:"last_error,omitempty"`
+	Grade        string     `json:"grade,omitempty"`
 }
 
 type MonitorMaintenance struct {
@@ -157,6 +651,8 @@ type MonitorSettings struct {
 	DefaultIntervalSec      int       `json:"default_interval_sec"`
 	EngineEnabled           bool      `json:"engine_enabled"`
 	AllowPrivateNetworks    bool      `json:"allow_private_networks"`
+	AllowedNetworks         []string  `json:"allowed_networks"`
+	BlockedNetworks         []string  `json:"blocked_networks"`
 	TLSRefreshHours         int       `json:"tls_refresh_hours"`
 	TLSExpiringDays         int       `json:"tls_expiring_days"`
 	NotifySuppressMinutes   int       `json:"notify_suppress_minutes"`
@@ -169,6 +665,7 @@ type MonitorSettings struct {
 	DefaultRetries          int       `json:"default_retries"`
 	DefaultRetryIntervalSec int       `json:"default_retry_interval_sec"`
 	DefaultSLATargetPct     float64   `json:"default_sla_target_pct"`
+	RollupRetentionDays     int       `json:"rollup_retention_days"`
 	UpdatedAt               time.Time `json:"updated_at"`
 }
 
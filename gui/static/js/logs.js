@@ -753,10 +753,13 @@
       'monitoring.notification.channel.apply_all': 'Мониторинг: канал применен ко всем мониторам',
       'monitoring.notification.bindings.update': 'Мониторинг: привязки уведомлений обновлены',
       'monitoring.monitor.push': 'Мониторинг: push-событие',
+      'monitoring.monitor.push_token.rotate': 'Мониторинг: перевыпуск push-токена',
       'monitoring.monitor.events.delete': 'Мониторинг: очистка событий монитора',
       'monitoring.monitor.metrics.delete': 'Мониторинг: очистка метрик монитора',
       'monitoring.certs.notify_test': 'Мониторинг: тест сертификатов',
       'monitoring.certs.notify_test.failed': 'Мониторинг: тест сертификатов завершился ошибкой',
+      'monitoring.dns.drift': 'Мониторинг: изменение DNS записей',
+      'monitoring.dns.incident.auto_create': 'Мониторинг: инцидент по изменению DNS',
       'monitoring.incident.auto_create': 'Мониторинг: авто-создание инцидента',
       'monitoring.incident.auto_close': 'Мониторинг: авто-закрытие инцидента',
       'backups.list': 'Бэкапы: список',
@@ -1028,10 +1031,13 @@
       'monitoring.notification.channel.apply_all': 'Monitoring: channel applied to all monitors',
       'monitoring.notification.bindings.update': 'Monitoring: notification bindings updated',
       'monitoring.monitor.push': 'Monitoring: push event',
+      'monitoring.monitor.push_token.rotate': 'Monitoring: push token rotated',
       'monitoring.monitor.events.delete': 'Monitoring: monitor events cleared',
       'monitoring.monitor.metrics.delete': 'Monitoring: monitor metrics cleared',
       'monitoring.certs.notify_test': 'Monitoring: certificates test',
       'monitoring.certs.notify_test.failed': 'Monitoring: certificates test failed',
+      'monitoring.dns.drift': 'Monitoring: DNS records changed',
+      'monitoring.dns.incident.auto_create': 'Monitoring: DNS change incident created',
       'monitoring.incident.auto_create': 'Monitoring: incident auto-created',
       'monitoring.incident.auto_close': 'Monitoring: incident auto-closed',
       'backups.list': 'Backups: list',
@@ -66,6 +66,7 @@
       <div>${MonitoringPage.t('monitoring.certs.daysLeft')}</div>
       <div>${MonitoringPage.t('monitoring.certs.issuer')}</div>
       <div>${MonitoringPage.t('monitoring.certs.commonName')}</div>
+      <div>${MonitoringPage.t('monitoring.certs.grade')}</div>
       <div>${MonitoringPage.t('monitoring.certs.checkedAt')}</div>
       <div>${MonitoringPage.t('monitoring.filter.status')}</div>
     `;
@@ -92,6 +93,7 @@
         <div class="${item.expiring_soon ? 'warning' : ''}">${daysLeft}</div>
         <div>${item.issuer || '-'}</div>
         <div>${item.common_name || '-'}</div>
+        <div>${item.grade || '-'}</div>
         <div>${MonitoringPage.formatDate(item.checked_at)}</div>
         <div>${statusLabel}</div>
       `;
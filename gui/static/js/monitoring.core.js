@@ -210,6 +210,12 @@ const MonitoringPage = (() => {
       const code = msg.replace('status_', '');
       return `HTTP ${code}`;
     }
+    const sep = msg.indexOf(': ');
+    if (sep > 0 && msg.startsWith('monitoring.error.')) {
+      const key = msg.slice(0, sep);
+      const label = t(key);
+      if (label !== key) return `${label}: ${msg.slice(sep + 2)}`;
+    }
     const translated = t(msg);
     return translated === msg ? msg : translated;
   }
@@ -29,6 +29,7 @@
     els.pause = document.getElementById('monitor-pause-toggle');
     els.edit = document.getElementById('monitor-edit');
     els.clone = document.getElementById('monitor-clone');
+    els.acceptContent = document.getElementById('monitor-content-accept');
     els.remove = document.getElementById('monitor-delete');
     els.eventsRange = document.getElementById('monitor-events-range');
     els.clearStats = document.getElementById('monitor-events-clear');
@@ -88,6 +89,7 @@
     if (els.pause) els.pause.addEventListener('click', handlePause);
     if (els.edit) els.edit.addEventListener('click', () => MonitoringPage.openMonitorModal?.(MonitoringPage.selectedMonitor()));
     if (els.clone) els.clone.addEventListener('click', handleClone);
+    if (els.acceptContent) els.acceptContent.addEventListener('click', handleAcceptContent);
     if (els.remove) els.remove.addEventListener('click', handleDelete);
     document.addEventListener('visibilitychange', () => {
       if (document.hidden) return;
@@ -235,6 +237,70 @@
         : MonitoringPage.t('monitoring.sla.violated');
       els.stats.appendChild(statCard(label, value));
     }
+    if (state?.server_version) {
+      els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.serverVersion'), state.server_version));
+    }
+    if (state?.server_role) {
+      els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.serverRole'), state.server_role));
+    }
+    (state?.details?.containers || []).forEach(c => {
+      const parts = [c.image, c.health ? `${c.state} / ${c.health}` : c.state];
+      parts.push(`${MonitoringPage.t('monitoring.stats.restarts')}: ${c.restart_count || 0}`);
+      if (c.uptime_sec) {
+        parts.push(`${MonitoringPage.t('monitoring.stats.containerUptime')}: ${formatSeconds(c.uptime_sec)}`);
+      }
+      els.stats.appendChild(textStatCard(c.name || c.id, parts.filter(Boolean).join(' · ')));
+    });
+    const game = state?.details?.game;
+    if (game) {
+      els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.gameServer'), [game.name, game.map].filter(Boolean).join(' · ')));
+      els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.players'), `${game.players || 0} / ${game.max_players || 0}`));
+    }
+    const ssh = state?.details?.ssh;
+    if (ssh) {
+      els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.hostKey'), [ssh.host_key_type, ssh.host_key_fingerprint].filter(Boolean).join(' · ')));
+      if ((ssh.weak_algorithms || []).length) {
+        els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.weakAlgorithms'), ssh.weak_algorithms.join(', ')));
+      }
+    }
+    const headers = state?.details?.security_headers;
+    if (headers) {
+      els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.headersScore'), `${headers.score} / 100`));
+      if ((headers.violations || []).length) {
+        els.stats.appendChild(textStatCard(MonitoringPage.t('monitoring.stats.headersViolations'), headers.violations.join(', ')));
+      }
+    }
+    (state?.details?.probes || []).forEach(loc => {
+      const name = loc.probe_id ? (loc.name || `#${loc.probe_id}`) : MonitoringPage.t('monitoring.stats.probeLocal');
+      const label = loc.location ? `${name} (${loc.location})` : name;
+      const parts = [];
+      if (loc.stale) {
+        parts.push(MonitoringPage.t('monitoring.stats.probeStale'));
+      } else {
+        parts.push(MonitoringPage.t(loc.ok ? 'monitoring.status.up' : 'monitoring.status.down'));
+        if (loc.ok) parts.push(MonitoringPage.formatLatency(loc.latency_ms));
+      }
+      if (loc.error && !loc.ok) parts.push(MonitoringPage.sanitizeErrorMessage(loc.error));
+      els.stats.appendChild(textStatCard(`${MonitoringPage.t('monitoring.stats.probe')}: ${label}`, parts.filter(Boolean).join(' · ')));
+    });
+    const transaction = state?.details?.transaction;
+    (transaction?.steps || []).forEach(step => {
+      const parts = [step.status_code ? `${step.status_code}` : '', MonitoringPage.formatLatency(step.latency_ms)];
+      if (step.error) parts.push(MonitoringPage.sanitizeErrorMessage(step.error));
+      const label = step.name === transaction.failed_step
+        ? `${MonitoringPage.t('monitoring.stats.failedStep')}: ${step.name}`
+        : step.name;
+      els.stats.appendChild(textStatCard(label, parts.filter(Boolean).join(' · ')));
+    });
+  }
+
+  function formatSeconds(total) {
+    const days = Math.floor(total / 86400);
+    const hours = Math.floor((total % 86400) / 3600);
+    const minutes = Math.floor((total % 3600) / 60);
+    if (days) return `${days}d ${hours}h`;
+    if (hours) return `${hours}h ${minutes}m`;
+    return `${minutes}m`;
   }
 
   function pointsFromMetrics(metrics, scaleX) {
@@ -249,6 +315,7 @@
         latency: m.latency_ms || 0,
         statusCode: m.status_code ?? m.statusCode ?? null,
         error: m.error || '',
+        values: m.values || null,
       };
     });
   }
@@ -619,6 +686,7 @@
       `${MonitoringPage.t('monitoring.tooltip.status')}: ${statusText}`,
       `${MonitoringPage.t('monitoring.tooltip.code')}: ${codeText}`,
       `${MonitoringPage.t('monitoring.tooltip.error')}: ${errText}`,
+      ...Object.entries(pt.values || {}).map(([path, val]) => `${path}: ${val}`),
     ].join('\n');
   }
 
@@ -732,7 +800,15 @@
     return card;
   }
 
+  // textStatCard renders values reported by the monitored server as plain text.
+  function textStatCard(label, value) {
+    const card = statCard(label, '');
+    card.querySelector('.value').textContent = value || '-';
+    return card;
+  }
+
   function updateActionLabels(mon) {
+    if (els.acceptContent) els.acceptContent.hidden = !mon.options?.content?.enabled;
     if (!els.pause) return;
     const paused = !!mon.is_paused;
     els.pause.textContent = paused
@@ -742,7 +818,7 @@
 
   function toggleActionAccess() {
     const canManage = MonitoringPage.hasPermission('monitoring.manage');
-    [els.pause, els.edit, els.clone, els.remove].forEach(btn => {
+    [els.pause, els.edit, els.clone, els.acceptContent, els.remove].forEach(btn => {
       if (!btn) return;
       btn.disabled = !canManage;
       btn.classList.toggle('disabled', !canManage);
@@ -785,6 +861,23 @@
     }
   }
 
+  async function handleAcceptContent() {
+    const mon = MonitoringPage.selectedMonitor();
+    if (!mon) return;
+    try {
+      clearDetailAlert();
+      const content = await Api.get(`/api/monitoring/monitors/${mon.id}/content`);
+      const diff = (content?.diff || '').slice(0, 2000);
+      const confirmed = window.confirm(`${MonitoringPage.t('monitoring.confirmAcceptContent')}${diff ? `\n\n${diff}` : ''}`);
+      if (!confirmed) return;
+      await Api.post(`/api/monitoring/monitors/${mon.id}/content/accept`, { hash: content?.current_hash || '' });
+      showDetailAlert(MonitoringPage.t('monitoring.contentAccepted'), true);
+    } catch (err) {
+      console.error('accept content', err);
+      showDetailAlert(MonitoringPage.sanitizeErrorMessage(err.message || err));
+    }
+  }
+
   async function handleDelete() {
     const mon = MonitoringPage.selectedMonitor();
     if (!mon) return;
@@ -814,8 +907,10 @@
   function statusClass(status) {
     const val = (status || '').toLowerCase();
     if (val === 'up') return 'up';
-    if (val === 'paused') return 'paused';
+    if (val === 'paused' || val === 'unreachable' || val === 'unreachable_start' || val === 'unreachable_end') return 'paused';
     if (val === 'maintenance' || val === 'maintenance_start' || val === 'maintenance_end') return 'maintenance';
+    if (val === 'degraded' || val === 'role_changed') return 'degraded';
+    if (val === 'dns_drift' || val === 'host_key_changed' || val === 'tls_grade_dropped' || val === 'content_changed' || val === 'headers_regressed') return 'down';
     return 'down';
   }
 
@@ -823,7 +918,15 @@
     const val = (status || '').toLowerCase();
     if (val === 'maintenance_start') return MonitoringPage.t('monitoring.event.maintenanceStart');
     if (val === 'maintenance_end') return MonitoringPage.t('monitoring.event.maintenanceEnd');
+    if (val === 'unreachable_start') return MonitoringPage.t('monitoring.event.unreachableStart');
+    if (val === 'unreachable_end') return MonitoringPage.t('monitoring.event.unreachableEnd');
     if (val === 'tls_expiring') return MonitoringPage.t('monitoring.event.tlsExpiring');
+    if (val === 'role_changed') return MonitoringPage.t('monitoring.event.roleChanged');
+    if (val === 'dns_drift') return MonitoringPage.t('monitoring.event.dnsDrift');
+    if (val === 'host_key_changed') return MonitoringPage.t('monitoring.event.hostKeyChanged');
+    if (val === 'tls_grade_dropped') return MonitoringPage.t('monitoring.event.tlsGradeDropped');
+    if (val === 'content_changed') return MonitoringPage.t('monitoring.event.contentChanged');
+    if (val === 'headers_regressed') return MonitoringPage.t('monitoring.event.headersRegressed');
     const key = `monitoring.status.${val}`;
     return MonitoringPage.t(key);
   }
@@ -134,8 +134,10 @@
   function statusClass(status) {
     const val = (status || '').toLowerCase();
     if (val === 'up') return 'up';
-    if (val === 'paused') return 'paused';
+    if (val === 'paused' || val === 'unreachable_start' || val === 'unreachable_end') return 'paused';
     if (val === 'maintenance_start' || val === 'maintenance_end') return 'maintenance';
+    if (val === 'degraded' || val === 'role_changed') return 'degraded';
+    if (val === 'dns_drift' || val === 'host_key_changed' || val === 'tls_grade_dropped' || val === 'content_changed' || val === 'headers_regressed') return 'down';
     return 'down';
   }
 
@@ -143,7 +145,15 @@
     const val = (status || '').toLowerCase();
     if (val === 'maintenance_start') return MonitoringPage.t('monitoring.event.maintenanceStart');
     if (val === 'maintenance_end') return MonitoringPage.t('monitoring.event.maintenanceEnd');
+    if (val === 'unreachable_start') return MonitoringPage.t('monitoring.event.unreachableStart');
+    if (val === 'unreachable_end') return MonitoringPage.t('monitoring.event.unreachableEnd');
     if (val === 'tls_expiring') return MonitoringPage.t('monitoring.event.tlsExpiring');
+    if (val === 'role_changed') return MonitoringPage.t('monitoring.event.roleChanged');
+    if (val === 'dns_drift') return MonitoringPage.t('monitoring.event.dnsDrift');
+    if (val === 'host_key_changed') return MonitoringPage.t('monitoring.event.hostKeyChanged');
+    if (val === 'tls_grade_dropped') return MonitoringPage.t('monitoring.event.tlsGradeDropped');
+    if (val === 'content_changed') return MonitoringPage.t('monitoring.event.contentChanged');
+    if (val === 'headers_regressed') return MonitoringPage.t('monitoring.event.headersRegressed');
     return MonitoringPage.t(`monitoring.status.${val}`);
   }
 
@@ -361,8 +361,9 @@
   function statusClass(status) {
     const v = (status || '').toLowerCase();
     if (v === 'up') return 'up';
-    if (v === 'paused') return 'paused';
+    if (v === 'paused' || v === 'unreachable') return 'paused';
     if (v === 'maintenance') return 'maintenance';
+    if (v === 'degraded') return 'degraded';
     return 'down';
   }
   function escapeHtml(str) {
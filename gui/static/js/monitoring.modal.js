@@ -1,8 +1,8 @@
 (() => {
   const els = {};
   const modalState = { editingId: null, submitting: false };
-  const URL_TYPES = new Set(['http', 'http_keyword', 'http_json', 'postgres', 'grpc_keyword']);
-  const HOST_PORT_TYPES = new Set(['tcp', 'ping', 'dns', 'docker', 'steam', 'gamedig', 'mqtt', 'kafka_producer', 'mssql', 'mysql', 'mongodb', 'radius', 'redis', 'tailscale_ping']);
+  const URL_TYPES = new Set(['http', 'http_keyword', 'http_json', 'http_transaction', 'postgres', 'grpc_keyword', 'prometheus']);
+  const HOST_PORT_TYPES = new Set(['tcp', 'ping', 'dns', 'docker', 'steam', 'gamedig', 'mqtt', 'kafka_producer', 'mssql', 'mysql', 'mongodb', 'radius', 'redis', 'tailscale_ping', 'smtp', 'imap', 'pop3', 'ssh', 'ldap', 'tls']);
   const HTTP_TYPES = new Set(['http', 'http_keyword', 'http_json']);
 
   function bindModal() {
@@ -27,6 +27,7 @@
     els.bodyType = document.getElementById('monitor-body-type');
     els.tags = document.getElementById('monitor-tags');
     els.tagsHint = document.querySelector('[data-tag-hint="monitor-tags"]');
+    els.parent = document.getElementById('monitor-parent');
     els.autoIncident = document.getElementById('monitor-auto-incident');
     els.autoIncidentRow = document.getElementById('monitor-auto-incident-row');
     els.autoTaskOnDown = document.getElementById('monitor-auto-task-on-down');
@@ -69,6 +70,7 @@
     els.form?.reset();
     setSubmitState(false);
     fillTagOptions(monitor?.tags || []);
+    fillParentOptions(monitor);
     if (monitor) {
       els.title.textContent = MonitoringPage.t('monitoring.modal.editTitle');
       els.type.value = monitor.type || 'http';
@@ -212,6 +214,9 @@
       headers: headers,
       tags: getSelectedOptions(els.tags),
     };
+    if (els.parent) {
+      payload.parent_id = parseInt(els.parent.value, 10) || 0;
+    }
     if (els.autoIncident && MonitoringPage.hasPermission('monitoring.incidents.link')) {
       payload.auto_incident = !!els.autoIncident.checked;
       payload.incident_severity = els.incidentSeverity?.value || 'low';
@@ -273,6 +278,24 @@
     }
   }
 
+  function fillParentOptions(monitor) {
+    if (!els.parent) return;
+    els.parent.innerHTML = '';
+    const none = document.createElement('option');
+    none.value = '';
+    none.textContent = MonitoringPage.t('monitoring.field.parentNone');
+    els.parent.appendChild(none);
+    // The server rejects parents that would close a loop; only the monitor itself is left out here.
+    (MonitoringPage.state.monitors || []).forEach(item => {
+      if (monitor && item.id === monitor.id) return;
+      const opt = document.createElement('option');
+      opt.value = String(item.id);
+      opt.textContent = item.name || `#${item.id}`;
+      els.parent.appendChild(opt);
+    });
+    els.parent.value = monitor?.parent_id ? String(monitor.parent_id) : '';
+  }
+
   function toggleTypeFields(type) {
     const kind = (type || '').toLowerCase();
     const isHTTP = HTTP_TYPES.has(kind);
@@ -281,6 +304,10 @@
     const hasHTTPRequest = isHTTP;
     const isPush = kind === 'push';
     const isGRPC = kind === 'grpc_keyword';
+    // Transaction steps are configured through the API; the form keeps the base URL, statuses and headers.
+    const isTransaction = kind === 'http_transaction';
+    // The threshold expression of prometheus monitors is set through the API as well.
+    const isPrometheus = kind === 'prometheus';
     const bodyTypeField = els.bodyType ? els.bodyType.closest('.form-field') : null;
     const bodyLabel = document.querySelector('#monitor-body-field label');
 
@@ -288,8 +315,8 @@
     document.getElementById('monitor-host-field').hidden = !usesHostPort;
     document.getElementById('monitor-port-field').hidden = !usesHostPort || kind === 'dns' || kind === 'tailscale_ping';
     document.getElementById('monitor-method-field').hidden = !(hasHTTPRequest || isGRPC);
-    document.getElementById('monitor-status-field').hidden = !hasHTTPRequest;
-    document.getElementById('monitor-headers-field').hidden = !(hasHTTPRequest || isGRPC);
+    document.getElementById('monitor-status-field').hidden = !(hasHTTPRequest || isTransaction || isPrometheus);
+    document.getElementById('monitor-headers-field').hidden = !(hasHTTPRequest || isGRPC || isTransaction || isPrometheus);
     if (bodyTypeField) bodyTypeField.hidden = !hasHTTPRequest || kind === 'http_keyword' || isPush || isGRPC;
     document.getElementById('monitor-body-field').hidden = !(hasHTTPRequest || kind === 'dns' || isPush);
 
@@ -299,9 +326,13 @@
     } else if (isPush) {
       if (bodyLabel) bodyLabel.textContent = MonitoringPage.t('monitoring.field.pushToken');
       if (els.bodyType) els.bodyType.value = 'none';
-      if (els.body && !els.body.value.trim()) {
+      // Only the token hash is stored: on edit an empty value keeps the current token.
+      if (els.body && !els.body.value.trim() && !modalState.editingId) {
         els.body.value = randomPushToken();
       }
+      if (els.body) {
+        els.body.placeholder = modalState.editingId ? MonitoringPage.t('monitoring.field.pushTokenKeep') : '';
+      }
     } else if (kind === 'dns') {
       if (bodyLabel) bodyLabel.textContent = MonitoringPage.t('monitoring.field.dnsExpected');
     } else if (isGRPC) {
@@ -310,8 +341,8 @@
     } else if (kind === 'http_json' || kind === 'http') {
       if (bodyLabel) bodyLabel.textContent = MonitoringPage.t('monitoring.field.body');
     }
-    if (els.notifyTLS) els.notifyTLS.closest('.form-field').hidden = !isHTTP;
-    if (els.ignoreTLS) els.ignoreTLS.closest('.form-field').hidden = !isHTTP;
+    if (els.notifyTLS) els.notifyTLS.closest('.form-field').hidden = !(isHTTP || isTransaction || isPrometheus);
+    if (els.ignoreTLS) els.ignoreTLS.closest('.form-field').hidden = !(isHTTP || isTransaction || isPrometheus);
   }
 
   function adaptTargetFieldsForType(nextType) {
@@ -7,6 +7,7 @@
     els.defaultsForm = document.getElementById('monitoring-defaults-form');
     els.save = document.getElementById('monitoring-settings-save');
     els.retention = document.getElementById('monitoring-retention');
+    els.rollupRetention = document.getElementById('monitoring-rollup-retention');
     els.maxConcurrent = document.getElementById('monitoring-max-concurrent');
     els.defaultTimeout = document.getElementById('monitoring-default-timeout');
     els.defaultInterval = document.getElementById('monitoring-default-interval');
@@ -15,6 +16,8 @@
     els.defaultSla = document.getElementById('monitoring-default-sla');
     els.engineEnabled = document.getElementById('monitoring-engine-enabled');
     els.allowPrivate = document.getElementById('monitoring-allow-private');
+    els.allowedNetworks = document.getElementById('monitoring-allowed-networks');
+    els.blockedNetworks = document.getElementById('monitoring-blocked-networks');
     els.tlsRefresh = document.getElementById('monitoring-tls-refresh');
     els.tlsExpiring = document.getElementById('monitoring-tls-expiring');
     els.notifySuppress = document.getElementById('monitoring-notify-suppress');
@@ -51,7 +54,8 @@
 
   function renderSettings(settings) {
     if (!settings) return;
-    if (els.retention) els.retention.value = settings.retention_days || 30;
+    if (els.retention) els.retention.value = settings.retention_days || 7;
+    if (els.rollupRetention) els.rollupRetention.value = settings.rollup_retention_days || 730;
     if (els.maxConcurrent) els.maxConcurrent.value = settings.max_concurrent_checks || 10;
     if (els.defaultTimeout) els.defaultTimeout.value = settings.default_timeout_sec || 5;
     if (els.defaultInterval) els.defaultInterval.value = settings.default_interval_sec || 60;
@@ -60,6 +64,8 @@
     if (els.defaultSla) els.defaultSla.value = settings.default_sla_target_pct || 90;
     if (els.engineEnabled) els.engineEnabled.checked = !!settings.engine_enabled;
     if (els.allowPrivate) els.allowPrivate.checked = !!settings.allow_private_networks;
+    if (els.allowedNetworks) els.allowedNetworks.value = (settings.allowed_networks || []).join('\n');
+    if (els.blockedNetworks) els.blockedNetworks.value = (settings.blocked_networks || []).join('\n');
     if (els.tlsRefresh) els.tlsRefresh.value = settings.tls_refresh_hours || 24;
     if (els.tlsExpiring) els.tlsExpiring.value = settings.tls_expiring_days || 30;
     if (els.notifySuppress) els.notifySuppress.value = settings.notify_suppress_minutes || 5;
@@ -75,6 +81,7 @@
     MonitoringPage.hideAlert(els.alert);
     const payload = {
       retention_days: parseInt(els.retention.value, 10) || 0,
+      rollup_retention_days: parseInt(els.rollupRetention?.value, 10) || 0,
       max_concurrent_checks: parseInt(els.maxConcurrent.value, 10) || 0,
       default_timeout_sec: parseInt(els.defaultTimeout.value, 10) || 0,
       default_interval_sec: parseInt(els.defaultInterval.value, 10) || 0,
@@ -83,6 +90,8 @@
       default_sla_target_pct: parseFloat(els.defaultSla?.value) || 0,
       engine_enabled: !!els.engineEnabled.checked,
       allow_private_networks: !!els.allowPrivate.checked,
+      allowed_networks: splitNetworks(els.allowedNetworks?.value),
+      blocked_networks: splitNetworks(els.blockedNetworks?.value),
       tls_refresh_hours: parseInt(els.tlsRefresh.value, 10) || 0,
       tls_expiring_days: parseInt(els.tlsExpiring.value, 10) || 0,
       notify_suppress_minutes: parseInt(els.notifySuppress.value, 10) || 0,
@@ -102,6 +111,10 @@
     }
   }
 
+  function splitNetworks(raw) {
+    return (raw || '').split(/[\n,]/).map(v => v.trim()).filter(Boolean);
+  }
+
   if (typeof MonitoringPage !== 'undefined') {
     MonitoringPage.bindSettings = bindSettings;
   }
@@ -836,3 +836,24 @@ func TestIncidentActivityEventAt(t *testing.T) {
 		t.Fatalf("event_at mismatch got %v want %v", got, eventAt)
 	}
 }
+
+func TestIncidentCountOpenBySeverity(t *testing.T) {
+	ctx, cfg, user, is, _, _, _, _, cleanup := setupIncidents(t)
+	defer cleanup()
+	createIncident(t, ctx, is, cfg, user)
+	closed := createIncident(t, ctx, is, cfg, user)
+	deleted := createIncident(t, ctx, is, cfg, user)
+	if _, err := is.CloseIncident(ctx, closed.ID, user.ID); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := is.SoftDeleteIncident(ctx, deleted.ID, user.ID); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	counts, err := is.CountOpenIncidentsBySeverity(ctx)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if counts["medium"] != 1 || len(counts) != 1 {
+		t.Fatalf("expected one open medium incident, got %v", counts)
+	}
+}
@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"berkut-scc/core/monitoring"
+	"berkut-scc/core/store"
+	"berkut-scc/core/utils"
+)
+
+func TestMonitoringDependencyUnreachable(t *testing.T) {
+	ms, is, _, enc, cleanup := setupMonitoringDeps(t)
+	defer cleanup()
+	ctx := context.Background()
+	settings, _ := ms.GetSettings(ctx)
+	settings.AllowPrivateNetworks = true
+	settings.EngineEnabled = true
+	settings.NotifySuppressMinutes = 0
+	if err := ms.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("settings update: %v", err)
+	}
+	addTelegramChannel(t, ms, enc)
+	var parentCode int32 = 500
+	parentSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&parentCode)))
+	}))
+	defer parentSrv.Close()
+	childSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer childSrv.Close()
+	parentID, err := ms.CreateMonitor(ctx, &store.Monitor{
+		Name: "Uplink", Type: "http", URL: parentSrv.URL, Method: "GET", AllowedStatus: []string{"200-299"},
+		IntervalSec: 60, TimeoutSec: 2, IsActive: true, CreatedBy: 1,
+	})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	childID, err := ms.CreateMonitor(ctx, &store.Monitor{
+		Name: "Behind uplink", Type: "http", URL: childSrv.URL, Method: "GET", AllowedStatus: []string{"200-299"},
+		IntervalSec: 60, TimeoutSec: 2, IsActive: true, CreatedBy: 1, ParentID: &parentID,
+		AutoIncident: true, IncidentSeverity: "high",
+	})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	sender := &mockTelegramSender{}
+	engine := monitoring.NewEngineWithDeps(ms, is, nil, "INC-{seq}", enc, sender, utils.NewLogger())
+	since := time.Now().UTC().Add(-time.Minute)
+
+	if err := engine.CheckNow(ctx, parentID); err != nil {
+		t.Fatalf("check parent: %v", err)
+	}
+	parentState, _ := ms.GetMonitorState(ctx, parentID)
+	if parentState == nil || parentState.Status != "down" {
+		t.Fatalf("expected the parent to be down, got %+v", parentState)
+	}
+	if err := engine.CheckNow(ctx, childID); err != nil {
+		t.Fatalf("check child: %v", err)
+	}
+	childState, _ := ms.GetMonitorState(ctx, childID)
+	if childState == nil || childState.Status != "unreachable" || !childState.Unreachable {
+		t.Fatalf("expected an unreachable child, got %+v", childState)
+	}
+	if len(sender.sent) != 1 || containsText(sender.sent[0].Text, "Behind uplink") {
+		t.Fatalf("expected only the parent DOWN notification, got %+v", sender.sent)
+	}
+	if inc, _ := is.FindOpenIncidentBySource(ctx, "monitoring", childID); inc != nil {
+		t.Fatalf("expected no incident for an unreachable child")
+	}
+	if err := engine.CheckNow(ctx, childID); err != nil {
+		t.Fatalf("check child again: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected no notification while unreachable, got %d", len(sender.sent))
+	}
+
+	atomic.StoreInt32(&parentCode, 200)
+	if err := engine.CheckNow(ctx, parentID); err != nil {
+		t.Fatalf("check parent: %v", err)
+	}
+	if err := engine.CheckNow(ctx, childID); err != nil {
+		t.Fatalf("check child after recovery: %v", err)
+	}
+	childState, _ = ms.GetMonitorState(ctx, childID)
+	if childState.Status != "down" || childState.Unreachable {
+		t.Fatalf("expected the child outage to start once the parent is back, got %+v", childState)
+	}
+	if inc, _ := is.FindOpenIncidentBySource(ctx, "monitoring", childID); inc == nil {
+		t.Fatalf("expected an incident for the child outage")
+	}
+	if !containsText(sender.sent[len(sender.sent)-1].Text, "Behind uplink") {
+		t.Fatalf("expected a child DOWN notification")
+	}
+
+	events, _ := ms.ListEvents(ctx, childID, since)
+	var kinds []string
+	for _, ev := range events {
+		if ev.EventType == store.EventUnreachableStart || ev.EventType == store.EventUnreachableEnd {
+			kinds = append(kinds, ev.EventType)
+			if ev.EventType == store.EventUnreachableStart && ev.Message != "Uplink" {
+				t.Fatalf("expected the parent name in the event, got %q", ev.Message)
+			}
+		}
+	}
+	if len(kinds) != 2 {
+		t.Fatalf("expected unreachable start and end events, got %v", kinds)
+	}
+	until := time.Now().UTC().Add(time.Minute)
+	windows, err := ms.UnreachableWindowsFor(ctx, childID, since, until)
+	if err != nil || len(windows) != 1 || !windows[0].End.After(windows[0].Start) {
+		t.Fatalf("expected one closed unreachable window, got %+v (%v)", windows, err)
+	}
+
+	// Clearing the event log keeps the unreachable periods, so an evaluated SLA does not change.
+	child, _ := ms.GetMonitor(ctx, childID)
+	policy := store.MonitorSLAPolicy{MonitorID: childID}
+	before, err := engine.EvaluateMonitorSLAWindow(ctx, *child, policy, *settings, since, until)
+	if err != nil {
+		t.Fatalf("sla: %v", err)
+	}
+	if _, err := ms.DeleteMonitorEvents(ctx, childID); err != nil {
+		t.Fatalf("delete events: %v", err)
+	}
+	if cleared, err := ms.UnreachableWindowsFor(ctx, childID, since, until); err != nil || len(cleared) != 1 || !cleared[0].Start.Equal(windows[0].Start) {
+		t.Fatalf("expected the unreachable window to outlive the events, got %+v (%v)", cleared, err)
+	}
+	after, err := engine.EvaluateMonitorSLAWindow(ctx, *child, policy, *settings, since, until)
+	if err != nil || after != before {
+		t.Fatalf("expected the SLA not to change once events are cleared, got %+v before %+v (%v)", after, before, err)
+	}
+}
+
+func TestMonitoringDependencyKeepsNotifiedOutage(t *testing.T) {
+	ms, is, _, enc, cleanup := setupMonitoringDeps(t)
+	defer cleanup()
+	ctx := context.Background()
+	settings, _ := ms.GetSettings(ctx)
+	settings.AllowPrivateNetworks = true
+	settings.EngineEnabled = true
+	settings.NotifySuppressMinutes = 0
+	if err := ms.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("settings update: %v", err)
+	}
+	addTelegramChannel(t, ms, enc)
+	var parentCode int32 = 500
+	// The background parent check is held until the child check is done, so the two never write at once.
+	gate := make(chan struct{})
+	parentSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-gate
+		w.WriteHeader(int(atomic.LoadInt32(&parentCode)))
+	}))
+	defer parentSrv.Close()
+	release := sync.OnceFunc(func() { close(gate) })
+	defer release()
+	childSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer childSrv.Close()
+	parentID, err := ms.CreateMonitor(ctx, &store.Monitor{
+		Name: "Uplink", Type: "http", URL: parentSrv.URL, Method: "GET", AllowedStatus: []string{"200-299"},
+		IntervalSec: 60, TimeoutSec: 2, IsActive: true, CreatedBy: 1,
+	})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	childID, err := ms.CreateMonitor(ctx, &store.Monitor{
+		Name: "Behind uplink", Type: "http", URL: childSrv.URL, Method: "GET", AllowedStatus: []string{"200-299"},
+		IntervalSec: 60, TimeoutSec: 2, IsActive: true, CreatedBy: 1, ParentID: &parentID,
+		AutoIncident: true, IncidentSeverity: "high",
+	})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	sender := &mockTelegramSender{}
+	engine := monitoring.NewEngineWithDeps(ms, is, nil, "INC-{seq}", enc, sender, utils.NewLogger())
+	childDowns := func() int {
+		n := 0
+		for _, msg := range sender.sent {
+			if containsText(msg.Text, "Behind uplink") {
+				n++
+			}
+		}
+		return n
+	}
+
+	// A parent result from hours ago says nothing about the parent now: the child is down on its own
+	// and the parent is checked in the background.
+	old := time.Now().UTC().Add(-2 * time.Hour)
+	if err := ms.UpsertMonitorState(ctx, &store.MonitorState{MonitorID: parentID, Status: "down", LastResultStatus: "down", LastCheckedAt: &old}); err != nil {
+		t.Fatalf("parent state: %v", err)
+	}
+	if err := engine.CheckNow(ctx, childID); err != nil {
+		t.Fatalf("check child: %v", err)
+	}
+	childState, _ := ms.GetMonitorState(ctx, childID)
+	if childState == nil || childState.Status != "down" || childState.Unreachable || childDowns() != 1 {
+		t.Fatalf("expected a notified child outage, got %+v (%d notifications)", childState, childDowns())
+	}
+	release()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		parentState, _ := ms.GetMonitorState(ctx, parentID)
+		if parentState != nil && parentState.LastCheckedAt != nil && parentState.LastCheckedAt.After(old) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the parent to be checked in the background, got %+v", parentState)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := engine.CheckNow(ctx, childID); err != nil {
+		t.Fatalf("check child again: %v", err)
+	}
+	if childState, _ = ms.GetMonitorState(ctx, childID); childState.Status != "unreachable" {
+		t.Fatalf("expected the child to be unreachable behind the fresh parent result, got %+v", childState)
+	}
+
+	atomic.StoreInt32(&parentCode, 200)
+	if err := engine.CheckNow(ctx, parentID); err != nil {
+		t.Fatalf("check parent: %v", err)
+	}
+	if err := engine.CheckNow(ctx, childID); err != nil {
+		t.Fatalf("check child after parent recovery: %v", err)
+	}
+	if childState, _ = ms.GetMonitorState(ctx, childID); childState.Status != "down" {
+		t.Fatalf("expected the child to be down again, got %+v", childState)
+	}
+	if childDowns() != 1 {
+		t.Fatalf("expected the outage notified before the unreachable period not to be notified again, got %d", childDowns())
+	}
+	st, _ := ms.GetNotificationState(ctx, childID)
+	if st == nil || st.DownStartedAt == nil || st.DownSequence < 2 {
+		t.Fatalf("expected the outage to continue across the unreachable period, got %+v", st)
+	}
+}
@@ -512,3 +512,129 @@ func TestMonitoringAutoTLSIncidentBySettings(t *testing.T) {
 func containsText(haystack, needle string) bool {
 	return needle != "" && strings.Contains(haystack, needle)
 }
+
+func TestMonitoringPushMissedHeartbeat(t *testing.T) {
+	ms, is, _, enc, cleanup := setupMonitoringDeps(t)
+	defer cleanup()
+	settings, _ := ms.GetSettings(context.Background())
+	settings.EngineEnabled = true
+	settings.NotifySuppressMinutes = 0
+	if err := ms.UpdateSettings(context.Background(), settings); err != nil {
+		t.Fatalf("settings update: %v", err)
+	}
+	addTelegramChannel(t, ms, enc)
+	mon := &store.Monitor{
+		Name:          "Nightly backup",
+		Type:          "push",
+		PushTokenHash: monitoring.HashPushToken("push-token"),
+		IntervalSec:   60,
+		TimeoutSec:    2,
+		IsActive:      true,
+		CreatedBy:     1,
+	}
+	id, err := ms.CreateMonitor(context.Background(), mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	mon.ID = id
+	sender := &mockTelegramSender{}
+	engine := monitoring.NewEngineWithDeps(ms, is, nil, "INC-{seq}", enc, sender, utils.NewLogger())
+	if err := engine.RecordPush(context.Background(), *mon, monitoring.CheckResult{OK: true}); err != nil {
+		t.Fatalf("record push: %v", err)
+	}
+	st, _ := ms.GetMonitorState(context.Background(), id)
+	if st == nil || st.Status != "up" {
+		t.Fatalf("expected up after heartbeat, got %+v", st)
+	}
+	stale := time.Now().UTC().Add(-2 * time.Hour)
+	st.LastCheckedAt = &stale
+	if err := ms.UpsertMonitorState(context.Background(), st); err != nil {
+		t.Fatalf("upsert state: %v", err)
+	}
+
+	engine.Start()
+	defer engine.Stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		st, _ = ms.GetMonitorState(context.Background(), id)
+		if st != nil && st.Status == "down" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if st == nil || st.Status != "down" || st.LastError != "monitoring.error.pushMissed" {
+		t.Fatalf("expected missed heartbeat to mark monitor down, got %+v", st)
+	}
+}
+
+type blockingTelegramSender struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingTelegramSender) Send(ctx context.Context, msg monitoring.TelegramMessage) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-b.release
+	return nil
+}
+
+func TestMonitoringPushNotBlockedBySlowNotification(t *testing.T) {
+	ms, is, _, enc, cleanup := setupMonitoringDeps(t)
+	defer cleanup()
+	ctx := context.Background()
+	settings, _ := ms.GetSettings(ctx)
+	settings.EngineEnabled = true
+	settings.NotifySuppressMinutes = 0
+	if err := ms.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("settings update: %v", err)
+	}
+	addTelegramChannel(t, ms, enc)
+	var monitors []store.Monitor
+	for _, name := range []string{"Backup", "Export"} {
+		mon := store.Monitor{Name: name, Type: "push", PushTokenHash: monitoring.HashPushToken(name), IntervalSec: 60, TimeoutSec: 2, IsActive: true, CreatedBy: 1}
+		id, err := ms.CreateMonitor(ctx, &mon)
+		if err != nil {
+			t.Fatalf("create monitor: %v", err)
+		}
+		mon.ID = id
+		monitors = append(monitors, mon)
+	}
+	sender := &blockingTelegramSender{started: make(chan struct{}, 1), release: make(chan struct{})}
+	engine := monitoring.NewEngineWithDeps(ms, is, nil, "INC-{seq}", enc, sender, utils.NewLogger())
+	slow := make(chan error, 1)
+	go func() {
+		slow <- engine.RecordPush(ctx, monitors[0], monitoring.CheckResult{OK: false})
+	}()
+	select {
+	case <-sender.started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a DOWN notification to be sent")
+	}
+	// The notification of the first monitor is still in flight.
+	done := make(chan error, 2)
+	go func() {
+		done <- engine.RecordPush(ctx, monitors[1], monitoring.CheckResult{OK: true})
+		done <- engine.RecordPush(ctx, monitors[0], monitoring.CheckResult{OK: false})
+	}()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("record push: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			close(sender.release)
+			t.Fatalf("expected heartbeats to be recorded while a notification is being sent")
+		}
+	}
+	close(sender.release)
+	if err := <-slow; err != nil {
+		t.Fatalf("record push: %v", err)
+	}
+	if st, _ := ms.GetMonitorState(ctx, monitors[1].ID); st == nil || st.Status != "up" {
+		t.Fatalf("expected the second monitor to be up, got %+v", st)
+	}
+}
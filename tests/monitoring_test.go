@@ -199,6 +199,441 @@ func TestMonitoringMetricsRetention(t *testing.T) {
 	}
 }
 
+func TestMonitoringMetricValues(t *testing.T) {
+	storeSvc, cleanup := setupMonitoringStore(t)
+	defer cleanup()
+	ctx := context.Background()
+	mon := &store.Monitor{
+		Name:          "Values",
+		Type:          "http_json",
+		URL:           "https://example.com/health",
+		Method:        "GET",
+		AllowedStatus: []string{"200-299"},
+		IntervalSec:   60,
+		TimeoutSec:    2,
+		IsActive:      true,
+	}
+	id, err := storeSvc.CreateMonitor(ctx, mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	ts := time.Now().UTC().Add(-time.Hour)
+	if _, err := storeSvc.AddMetric(ctx, &store.MonitorMetric{MonitorID: id, TS: ts, LatencyMs: 90, OK: true, Values: map[string]float64{"$.db.lag": 3}}); err != nil {
+		t.Fatalf("add metric: %v", err)
+	}
+	if _, err := storeSvc.AddMetric(ctx, &store.MonitorMetric{MonitorID: id, TS: ts.Add(time.Minute), LatencyMs: 80, OK: true}); err != nil {
+		t.Fatalf("add metric: %v", err)
+	}
+	items, err := storeSvc.ListMetrics(ctx, id, ts.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("list metrics: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(items))
+	}
+	if items[0].Values["$.db.lag"] != 3 {
+		t.Fatalf("expected metric values to be stored, got %v", items[0].Values)
+	}
+	if len(items[1].Values) != 0 {
+		t.Fatalf("expected no values for a metric without them, got %v", items[1].Values)
+	}
+}
+
+func TestMonitoringMetricRollups(t *testing.T) {
+	storeSvc, cleanup := setupMonitoringStore(t)
+	defer cleanup()
+	ctx := context.Background()
+	mon := &store.Monitor{Name: "Rollups", Type: "tcp", Host: "example.com", Port: 443, IntervalSec: 60, TimeoutSec: 2, IsActive: true}
+	id, err := storeSvc.CreateMonitor(ctx, mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	mon.ID = id
+	day := time.Now().UTC().Truncate(24 * time.Hour).Add(-3 * 24 * time.Hour)
+	base := day.Add(10 * time.Hour)
+	points := []store.MonitorMetric{
+		{MonitorID: id, TS: base.Add(5 * time.Minute), LatencyMs: 100, OK: true},
+		{MonitorID: id, TS: base.Add(20 * time.Minute), LatencyMs: 300, OK: true},
+		{MonitorID: id, TS: base.Add(40 * time.Minute), LatencyMs: 5000, OK: false},
+		{MonitorID: id, TS: base.Add(70 * time.Minute), LatencyMs: 200, OK: true},
+	}
+	for i := range points {
+		if _, err := storeSvc.AddMetric(ctx, &points[i]); err != nil {
+			t.Fatalf("add metric: %v", err)
+		}
+	}
+	hourly, err := storeSvc.ListMetricRollups(ctx, id, store.RollupHourly, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("hourly rollups: %v", err)
+	}
+	if len(hourly) != 2 || hourly[0].TotalCount != 3 || hourly[0].LatencyP95Ms != nil {
+		t.Fatalf("expected counters right away and the p95 only for closed buckets, got %+v", hourly)
+	}
+	if err := storeSvc.FinalizeMetricRollups(ctx, day, time.Now().UTC()); err != nil {
+		t.Fatalf("finalize rollups: %v", err)
+	}
+	hourly, _ = storeSvc.ListMetricRollups(ctx, id, store.RollupHourly, day, day.Add(24*time.Hour))
+	if len(hourly) != 2 || hourly[0].TotalCount != 3 || hourly[0].OKCount != 2 || hourly[0].LatencyAvgMs != 200 ||
+		*hourly[0].LatencyMinMs != 100 || *hourly[0].LatencyP95Ms != 300 || *hourly[0].LatencyMaxMs != 300 {
+		t.Fatalf("unexpected hourly rollups %+v", hourly)
+	}
+	daily, err := storeSvc.ListMetricRollups(ctx, id, store.RollupDaily, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("daily rollups: %v", err)
+	}
+	if len(daily) != 1 || daily[0].TotalCount != 4 || daily[0].OKCount != 3 || *daily[0].LatencyMinMs != 100 || *daily[0].LatencyMaxMs != 300 ||
+		daily[0].LatencyP95Ms == nil || *daily[0].LatencyP95Ms != 300 {
+		t.Fatalf("unexpected daily rollups %+v", daily)
+	}
+
+	// Raw points go away after their short retention; summaries and SLA keep working from the rollups.
+	if _, err := storeSvc.DeleteMetricsBefore(ctx, time.Now().UTC()); err != nil {
+		t.Fatalf("retention delete: %v", err)
+	}
+	okCount, total, avg, err := storeSvc.MetricsSummary(ctx, id, day)
+	if err != nil || okCount != 3 || total != 4 || avg != 200 {
+		t.Fatalf("unexpected summary ok=%d total=%d avg=%v err=%v", okCount, total, avg, err)
+	}
+	okCount, total, err = storeSvc.MetricsSummaryBetween(ctx, id, day.Add(-24*time.Hour), day.Add(11*time.Hour))
+	if err != nil || okCount != 2 || total != 3 {
+		t.Fatalf("unexpected windowed summary ok=%d total=%d err=%v", okCount, total, err)
+	}
+	engine := monitoring.NewEngine(storeSvc, utils.NewLogger())
+	settings := store.MonitorSettings{DefaultIntervalSec: 60, DefaultSLATargetPct: 70}
+	eval, err := engine.EvaluateMonitorSLAWindow(ctx, *mon, store.MonitorSLAPolicy{MonitorID: id}, settings, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("sla: %v", err)
+	}
+	if eval.UptimePct != 75 || eval.Status != "ok" {
+		t.Fatalf("unexpected sla evaluation %+v", eval)
+	}
+
+	if _, err := storeSvc.DeleteMetricRollupsBefore(ctx, store.RollupHourly, time.Now().UTC()); err != nil {
+		t.Fatalf("rollup retention: %v", err)
+	}
+	if okCount, total, _, _ = storeSvc.MetricsSummary(ctx, id, day); total != 4 || okCount != 3 {
+		t.Fatalf("expected whole days to be served by the daily rollup, got ok=%d total=%d", okCount, total)
+	}
+	if _, err := storeSvc.DeleteMonitorMetrics(ctx, id); err != nil {
+		t.Fatalf("delete metrics: %v", err)
+	}
+	if daily, _ = storeSvc.ListMetricRollups(ctx, id, store.RollupDaily, day, day.Add(24*time.Hour)); len(daily) != 0 {
+		t.Fatalf("expected clearing metrics to drop the rollups, got %+v", daily)
+	}
+}
+
+func TestMonitoringSettingsNetworkPolicy(t *testing.T) {
+	storeSvc, cleanup := setupMonitoringStore(t)
+	defer cleanup()
+	settings, err := storeSvc.GetSettings(context.Background())
+	if err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	settings.AllowedNetworks = []string{"10.20.0.0/16"}
+	settings.BlockedNetworks = []string{"169.254.169.254/32", "fd00::/8"}
+	if err := storeSvc.UpdateSettings(context.Background(), settings); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	got, err := storeSvc.GetSettings(context.Background())
+	if err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	if len(got.AllowedNetworks) != 1 || got.AllowedNetworks[0] != "10.20.0.0/16" || len(got.BlockedNetworks) != 2 {
+		t.Fatalf("unexpected network policy: allowed=%v blocked=%v", got.AllowedNetworks, got.BlockedNetworks)
+	}
+}
+
+func TestMonitoringCertsIncludeMailMonitors(t *testing.T) {
+	storeSvc, cleanup := setupMonitoringStore(t)
+	defer cleanup()
+	mon := &store.Monitor{
+		Name:        "MX",
+		Type:        "smtp",
+		Host:        "mx.example.com",
+		Port:        25,
+		IntervalSec: 60,
+		TimeoutSec:  5,
+		IsActive:    true,
+	}
+	id, err := storeSvc.CreateMonitor(context.Background(), mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	certs, err := storeSvc.ListCerts(context.Background(), store.CertFilter{})
+	if err != nil {
+		t.Fatalf("list certs: %v", err)
+	}
+	if len(certs) != 0 {
+		t.Fatalf("expected mail monitor without certificate to be hidden, got %d", len(certs))
+	}
+	notAfter := time.Now().UTC().Add(20 * 24 * time.Hour)
+	if err := storeSvc.UpsertTLS(context.Background(), &store.MonitorTLS{MonitorID: id, CheckedAt: time.Now().UTC(), NotAfter: notAfter, CommonName: "mx.example.com"}); err != nil {
+		t.Fatalf("upsert tls: %v", err)
+	}
+	certs, err = storeSvc.ListCerts(context.Background(), store.CertFilter{})
+	if err != nil {
+		t.Fatalf("list certs: %v", err)
+	}
+	if len(certs) != 1 || certs[0].URL != "smtp://mx.example.com:25" || certs[0].CommonName != "mx.example.com" {
+		t.Fatalf("unexpected certs: %+v", certs)
+	}
+}
+
+func TestMonitoringTLSSecurityStored(t *testing.T) {
+	storeSvc, cleanup := setupMonitoringStore(t)
+	defer cleanup()
+	id, err := storeSvc.CreateMonitor(context.Background(), &store.Monitor{
+		Name:        "LDAPS",
+		Type:        "tls",
+		Host:        "dc.example.com",
+		Port:        636,
+		IntervalSec: 300,
+		TimeoutSec:  5,
+		IsActive:    true,
+	})
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	record := &store.MonitorTLS{
+		MonitorID: id,
+		CheckedAt: time.Now().UTC(),
+		NotAfter:  time.Now().UTC().Add(90 * 24 * time.Hour),
+		Grade:     "B",
+		Security: &store.TLSSecurity{
+			Protocols: []string{"TLS 1.1", "TLS 1.2"},
+			Chain:     []store.TLSChainCert{{Subject: "dc.example.com", Issuer: "Corp CA", KeyType: "RSA", KeyBits: 2048}},
+			Issues:    []string{"legacy_protocol"},
+		},
+	}
+	if err := storeSvc.UpsertTLS(context.Background(), record); err != nil {
+		t.Fatalf("upsert tls: %v", err)
+	}
+	got, err := storeSvc.GetTLS(context.Background(), id)
+	if err != nil || got == nil || got.Grade != "B" || got.Security == nil || len(got.Security.Chain) != 1 || got.Security.Issues[0] != "legacy_protocol" {
+		t.Fatalf("unexpected tls record %+v: %v", got, err)
+	}
+	certs, err := storeSvc.ListCerts(context.Background(), store.CertFilter{})
+	if err != nil || len(certs) != 1 || certs[0].Grade != "B" || certs[0].URL != "tls://dc.example.com:636" {
+		t.Fatalf("unexpected certs %+v: %v", certs, err)
+	}
+}
+
+func TestMonitoringContentChangeEvents(t *testing.T) {
+	storeSvc, cleanup := setupMonitoringStore(t)
+	defer cleanup()
+	ctx := context.Background()
+	settings, err := storeSvc.GetSettings(ctx)
+	if err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	settings.AllowPrivateNetworks = true
+	settings.EngineEnabled = true
+	if err := storeSvc.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("settings update: %v", err)
+	}
+
+	var defaced int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&defaced) == 1 {
+			_, _ = w.Write([]byte("<h1>Hacked</h1>"))
+			return
+		}
+		_, _ = w.Write([]byte("<h1>Welcome</h1>"))
+	}))
+	defer srv.Close()
+
+	mon := &store.Monitor{
+		Name:          "Content",
+		Type:          "http",
+		URL:           srv.URL,
+		Method:        "GET",
+		AllowedStatus: []string{"200-299"},
+		IntervalSec:   60,
+		TimeoutSec:    2,
+		IsActive:      true,
+	}
+	mon.Options.Content = &store.ContentOptions{Enabled: true}
+	id, err := storeSvc.CreateMonitor(ctx, mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	engine := monitoring.NewEngine(storeSvc, utils.NewLogger())
+	contentEvents := func() []store.MonitorEvent {
+		events, _ := storeSvc.ListEvents(ctx, id, time.Now().UTC().Add(-24*time.Hour))
+		var out []store.MonitorEvent
+		for _, ev := range events {
+			if ev.EventType == "content_changed" {
+				out = append(out, ev)
+			}
+		}
+		return out
+	}
+
+	if err := engine.CheckNow(ctx, id); err != nil {
+		t.Fatalf("check now 1: %v", err)
+	}
+	content, err := storeSvc.GetContent(ctx, id)
+	if err != nil || content == nil || content.BaselineHash == "" || content.CurrentHash != content.BaselineHash {
+		t.Fatalf("expected baseline after first check, got %+v (%v)", content, err)
+	}
+	atomic.StoreInt32(&defaced, 1)
+	for i := 0; i < 2; i++ {
+		if err := engine.CheckNow(ctx, id); err != nil {
+			t.Fatalf("check now: %v", err)
+		}
+	}
+	events := contentEvents()
+	if len(events) != 1 || events[0].Message != "--- baseline\n+++ current\n@@ -1,1 +1,1 @@\n-<h1>Welcome</h1>\n+<h1>Hacked</h1>\n" {
+		t.Fatalf("expected one content_changed event with a diff, got %+v", events)
+	}
+	content, _ = storeSvc.GetContent(ctx, id)
+	if content.ChangedAt == nil || content.CurrentHash == content.BaselineHash {
+		t.Fatalf("expected changed content, got %+v", content)
+	}
+
+	if ok, err := storeSvc.AcceptContentBaseline(ctx, id, content.CurrentHash, "admin", time.Now().UTC()); err != nil || !ok {
+		t.Fatalf("accept baseline: %v %v", ok, err)
+	}
+	if err := engine.CheckNow(ctx, id); err != nil {
+		t.Fatalf("check now: %v", err)
+	}
+	if events := contentEvents(); len(events) != 1 {
+		t.Fatalf("expected no event after accepting the baseline, got %d", len(events))
+	}
+}
+
+func TestMonitoringContentCheckKeepsAcceptedBaseline(t *testing.T) {
+	storeSvc, cleanup := setupMonitoringStore(t)
+	defer cleanup()
+	ctx := context.Background()
+	id, err := storeSvc.CreateMonitor(ctx, &store.Monitor{Name: "Content", Type: "http", URL: "http://example.com", Method: "GET", IntervalSec: 60, TimeoutSec: 2})
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := storeSvc.CreateContent(ctx, &store.MonitorContent{
+		MonitorID: id, BaselineHash: "a", Baseline: []byte("a"), BaselineAt: now, CurrentHash: "a", Current: []byte("a"), CheckedAt: now,
+	}); err != nil {
+		t.Fatalf("create content: %v", err)
+	}
+	if err := storeSvc.RecordContentCheck(ctx, id, "b", []byte("b"), now); err != nil {
+		t.Fatalf("record check: %v", err)
+	}
+	if ok, err := storeSvc.AcceptContentBaseline(ctx, id, "a", "admin", now); err != nil || ok {
+		t.Fatalf("expected accepting a stale hash to fail, got %v %v", ok, err)
+	}
+	if ok, err := storeSvc.AcceptContentBaseline(ctx, id, "b", "admin", now); err != nil || !ok {
+		t.Fatalf("accept baseline: %v %v", ok, err)
+	}
+	// A check that started before the accept stores what it saw without reverting the baseline.
+	if err := storeSvc.RecordContentCheck(ctx, id, "b", []byte("b"), now.Add(time.Second)); err != nil {
+		t.Fatalf("record check: %v", err)
+	}
+	content, _ := storeSvc.GetContent(ctx, id)
+	if content == nil || content.BaselineHash != "b" || content.AcceptedBy != "admin" || content.ChangedAt != nil {
+		t.Fatalf("expected the accepted baseline to be kept, got %+v", content)
+	}
+	if err := storeSvc.RecordContentCheck(ctx, id, "c", []byte("c"), now.Add(2*time.Second)); err != nil {
+		t.Fatalf("record check: %v", err)
+	}
+	content, _ = storeSvc.GetContent(ctx, id)
+	if content.BaselineHash != "b" || content.CurrentHash != "c" || content.ChangedAt == nil {
+		t.Fatalf("expected a new change against the accepted baseline, got %+v", content)
+	}
+}
+
+func TestMonitoringHeadersRegressionCreatesControlViolation(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.AppConfig{DBPath: filepath.Join(t.TempDir(), "monitoring.db")}
+	logger := utils.NewLogger()
+	db, err := store.NewDB(cfg, logger)
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	defer db.Close()
+	if err := store.ApplyMigrations(ctx, db, logger); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	storeSvc := store.NewMonitoringStore(db)
+	controls := store.NewControlsStore(db)
+	settings, err := storeSvc.GetSettings(ctx)
+	if err != nil {
+		t.Fatalf("settings: %v", err)
+	}
+	settings.AllowPrivateNetworks = true
+	settings.EngineEnabled = true
+	if err := storeSvc.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("settings update: %v", err)
+	}
+	controlID, err := controls.CreateControl(ctx, &store.Control{
+		Code:            "CTRL-WEB-001",
+		Title:           "Web security headers",
+		ControlType:     "technical",
+		Domain:          "infra",
+		ReviewFrequency: "annual",
+		Status:          "implemented",
+		RiskLevel:       "medium",
+		CreatedBy:       1,
+		IsActive:        true,
+	})
+	if err != nil {
+		t.Fatalf("create control: %v", err)
+	}
+
+	var dropCSP int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&dropCSP) == 0 {
+			w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		}
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+	}))
+	defer srv.Close()
+	mon := &store.Monitor{
+		Name:          "Headers",
+		Type:          "http",
+		URL:           srv.URL,
+		Method:        "GET",
+		AllowedStatus: []string{"200-299"},
+		IntervalSec:   60,
+		TimeoutSec:    2,
+		IsActive:      true,
+	}
+	mon.Options.Headers = &store.HeadersOptions{Enabled: true, ControlID: controlID}
+	id, err := storeSvc.CreateMonitor(ctx, mon)
+	if err != nil {
+		t.Fatalf("create monitor: %v", err)
+	}
+	engine := monitoring.NewEngine(storeSvc, logger)
+	engine.SetControlsStore(controls)
+
+	if err := engine.CheckNow(ctx, id); err != nil {
+		t.Fatalf("check now 1: %v", err)
+	}
+	state, _ := storeSvc.GetMonitorState(ctx, id)
+	if state == nil || state.Details == nil || state.Details.Headers == nil || state.Details.Headers.Score != 75 {
+		t.Fatalf("expected headers details in state, got %+v", state)
+	}
+	atomic.StoreInt32(&dropCSP, 1)
+	if err := engine.CheckNow(ctx, id); err != nil {
+		t.Fatalf("check now 2: %v", err)
+	}
+	events, _ := storeSvc.ListEvents(ctx, id, time.Now().UTC().Add(-24*time.Hour))
+	found := false
+	for _, ev := range events {
+		if ev.EventType == "headers_regressed" && ev.Message == "75 -> 55: csp_missing" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected headers_regressed event, got %+v", events)
+	}
+	violations, err := controls.ListControlViolations(ctx, controlID)
+	if err != nil || len(violations) != 1 || !violations[0].IsAuto {
+		t.Fatalf("expected one auto control violation, got %+v (%v)", violations, err)
+	}
+}
+
 func TestMonitoringPermissions(t *testing.T) {
 	policy := rbac.NewPolicy(rbac.DefaultRoles())
 	if !policy.Allowed([]string{"admin"}, "monitoring.view") {